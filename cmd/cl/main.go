@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// cl is a terminal client for a Central Logs server
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "tail":
+		if err := runTail(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "-h", "--help", "help":
+		printUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+}
+
+func printUsage() {
+	fmt.Println("cl - Central Logs command line client")
+	fmt.Println("\nUsage:")
+	fmt.Println("  cl tail [flags]    Print recent logs, or stream new ones with --follow")
+	fmt.Println("\nRun 'cl tail -h' for the tail flags.")
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"central-logs/internal/models"
+	"central-logs/internal/utils"
+
+	"github.com/fasthttp/websocket"
+)
+
+const (
+	initialReconnectDelay = time.Second
+	maxReconnectDelay     = 30 * time.Second
+	pingInterval          = 30 * time.Second
+)
+
+// errUnauthorized stops reconnecting, since retrying with the same token
+// cannot succeed
+var errUnauthorized = errors.New("the server rejected the token (expired or invalid)")
+
+type tailOptions struct {
+	baseURL  string
+	token    string
+	project  string
+	minLevel models.LogLevel // Empty shows every level
+	source   string
+	follow   bool
+	limit    int
+	color    bool
+	out      io.Writer
+}
+
+// matches applies the client-side level and source filters
+func (o tailOptions) matches(log *models.Log) bool {
+	if o.minLevel != "" && models.ParseLogLevel(strings.ToUpper(string(log.Level))).Priority() < o.minLevel.Priority() {
+		return false
+	}
+	if o.source != "" && !strings.EqualFold(log.Source, o.source) {
+		return false
+	}
+	return true
+}
+
+// formatLog renders one log as a single terminal line
+func formatLog(log *models.Log, color bool) string {
+	ts := log.Timestamp
+	if ts.IsZero() {
+		ts = log.CreatedAt
+	}
+
+	project := log.ProjectName
+	if project == "" {
+		project = log.ProjectID
+	}
+
+	var b strings.Builder
+	if color {
+		b.WriteString(utils.ColorGray)
+	}
+	b.WriteString(ts.Local().Format("2006-01-02 15:04:05"))
+	if color {
+		b.WriteString(utils.ColorReset)
+	}
+
+	level := fmt.Sprintf("%-8s", log.Level)
+	if color {
+		level = utils.LevelColor(string(log.Level)) + level + utils.ColorReset
+	}
+	b.WriteString(" " + level)
+
+	if project != "" {
+		b.WriteString(" [" + project + "]")
+	}
+	if log.Source != "" {
+		b.WriteString(" " + log.Source + ":")
+	}
+	b.WriteString(" " + log.Message)
+	return b.String()
+}
+
+// parseMinLevel accepts a level name in any case; an empty string means all levels
+func parseMinLevel(s string) (models.LogLevel, error) {
+	if s == "" {
+		return "", nil
+	}
+	upper := strings.ToUpper(s)
+	if upper == "WARNING" {
+		return models.LogLevelWarn, nil
+	}
+	for _, level := range models.AllLogLevels {
+		if string(level) == upper {
+			return level, nil
+		}
+	}
+	return "", fmt.Errorf("unknown level %q (use DEBUG, INFO, WARN, ERROR or CRITICAL)", s)
+}
+
+func runTail(args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	baseURL := fs.String("url", envOr("CL_URL", "http://localhost:3000"), "Server URL (env CL_URL)")
+	token := fs.String("token", os.Getenv("CL_TOKEN"), "JWT from POST /api/auth/login (env CL_TOKEN)")
+	project := fs.String("project", "", "Only show logs from this project ID")
+	level := fs.String("level", "", "Only show logs at or above this level")
+	source := fs.String("source", "", "Only show logs from this source")
+	follow := fs.Bool("follow", false, "Keep the connection open and print new logs as they arrive")
+	fs.BoolVar(follow, "f", false, "Shorthand for --follow")
+	limit := fs.Int("limit", 50, "Number of recent logs to print first (0 with --follow skips them)")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	fs.Usage = func() {
+		fmt.Println("Usage: cl tail [flags]")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  cl tail --project PROJECT_ID --level ERROR")
+		fmt.Println("  cl tail --follow --source payment-service")
+	}
+	fs.Parse(args)
+
+	if *token == "" {
+		return errors.New("a token is required: pass --token or set CL_TOKEN")
+	}
+	if *limit < 0 || (!*follow && *limit == 0) {
+		return errors.New("--limit must be positive")
+	}
+	minLevel, err := parseMinLevel(*level)
+	if err != nil {
+		return err
+	}
+
+	opts := tailOptions{
+		baseURL:  strings.TrimSuffix(*baseURL, "/"),
+		token:    *token,
+		project:  *project,
+		minLevel: minLevel,
+		source:   *source,
+		follow:   *follow,
+		limit:    *limit,
+		color:    !*noColor && os.Getenv("NO_COLOR") == "",
+		out:      os.Stdout,
+	}
+
+	if opts.limit > 0 {
+		logs, err := fetchRecent(opts)
+		if err != nil {
+			return err
+		}
+		for _, log := range logs {
+			if opts.matches(log) {
+				fmt.Fprintln(opts.out, formatLog(log, opts.color))
+			}
+		}
+	}
+
+	if !opts.follow {
+		return nil
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	stop := make(chan struct{})
+	go func() {
+		<-sigChan
+		close(stop)
+	}()
+	return streamLogs(opts, stop)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// fetchRecent loads the latest logs through the REST API, oldest first
+func fetchRecent(opts tailOptions) ([]*models.Log, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(opts.limit))
+	if opts.project != "" {
+		query.Set("project_id", opts.project)
+	}
+	if opts.source != "" {
+		query.Set("source", opts.source)
+	}
+	if opts.minLevel != "" {
+		var levels []string
+		for _, level := range models.LevelsAtOrAbove(opts.minLevel) {
+			levels = append(levels, string(level))
+		}
+		query.Set("levels", strings.Join(levels, ","))
+	}
+
+	req, err := http.NewRequest("GET", opts.baseURL+"/api/admin/logs?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+opts.token)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		var result struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return nil, fmt.Errorf("listing logs failed: %d %s", resp.StatusCode, result.Error)
+	}
+
+	var result struct {
+		Logs []*models.Log `json:"logs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	sort.SliceStable(result.Logs, func(i, j int) bool {
+		return result.Logs[i].Timestamp.Before(result.Logs[j].Timestamp)
+	})
+	return result.Logs, nil
+}
+
+// streamURL builds the WebSocket URL for the server's live log feed
+func streamURL(baseURL, projectID string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/ws/logs"
+	if projectID != "" {
+		u.RawQuery = url.Values{"project_id": {projectID}}.Encode()
+	}
+	return u.String(), nil
+}
+
+// streamLogs prints live logs until stop is closed, reconnecting with backoff
+// whenever the connection drops
+func streamLogs(opts tailOptions, stop <-chan struct{}) error {
+	wsURL, err := streamURL(opts.baseURL, opts.project)
+	if err != nil {
+		return err
+	}
+
+	delay := initialReconnectDelay
+	for {
+		connected, err := streamOnce(wsURL, opts, stop)
+		if err == nil || errors.Is(err, errUnauthorized) {
+			return err
+		}
+		if connected {
+			delay = initialReconnectDelay
+		}
+
+		fmt.Fprintf(os.Stderr, "Connection lost (%v), reconnecting in %v\n", err, delay)
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// streamOnce holds one WebSocket connection open. It returns nil once stop is
+// closed and otherwise reports whether the connection was established.
+func streamOnce(wsURL string, opts tailOptions, stop <-chan struct{}) (bool, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+opts.token)
+
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return false, errUnauthorized
+		}
+		return false, err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-stop:
+				// Unblocks ReadMessage below
+				conn.Close()
+				return
+			case <-ticker.C:
+				conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-stop:
+				return true, nil
+			default:
+			}
+			return true, err
+		}
+
+		var msg struct {
+			Type string      `json:"type"`
+			Data *models.Log `json:"data"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "log" || msg.Data == nil {
+			continue
+		}
+		if opts.matches(msg.Data) {
+			fmt.Fprintln(opts.out, formatLog(msg.Data, opts.color))
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+
+	"github.com/fasthttp/websocket"
+)
+
+func TestTailOptions_Matches(t *testing.T) {
+	opts := tailOptions{minLevel: models.LogLevelError, source: "api"}
+
+	tests := []struct {
+		level  models.LogLevel
+		source string
+		want   bool
+	}{
+		{models.LogLevelError, "api", true},
+		{models.LogLevelCritical, "API", true},
+		{models.LogLevelWarn, "api", false},
+		{models.LogLevelError, "worker", false},
+	}
+	for _, tt := range tests {
+		log := &models.Log{Level: tt.level, Source: tt.source}
+		if got := opts.matches(log); got != tt.want {
+			t.Errorf("matches(%s, %s) = %v, want %v", tt.level, tt.source, got, tt.want)
+		}
+	}
+
+	if !(tailOptions{}).matches(&models.Log{Level: models.LogLevelDebug}) {
+		t.Error("Expected no filters to match every log")
+	}
+}
+
+func TestParseMinLevel(t *testing.T) {
+	if level, err := parseMinLevel("warning"); err != nil || level != models.LogLevelWarn {
+		t.Errorf("parseMinLevel(warning) = %q, %v", level, err)
+	}
+	if level, err := parseMinLevel(""); err != nil || level != "" {
+		t.Errorf("parseMinLevel(\"\") = %q, %v", level, err)
+	}
+	if _, err := parseMinLevel("loud"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestFormatLog(t *testing.T) {
+	log := &models.Log{
+		Level:       models.LogLevelError,
+		Message:     "payment failed",
+		Source:      "checkout",
+		ProjectName: "shop",
+		CreatedAt:   time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local),
+	}
+
+	if got, want := formatLog(log, false), "2024-01-02 15:04:05 ERROR    [shop] checkout: payment failed"; got != want {
+		t.Errorf("formatLog() = %q, want %q", got, want)
+	}
+	if got := formatLog(log, true); !strings.Contains(got, "\033[31mERROR") {
+		t.Errorf("Expected a colored level, got %q", got)
+	}
+}
+
+func TestStreamURL(t *testing.T) {
+	tests := []struct {
+		base, project, want string
+	}{
+		{"http://localhost:3000", "", "ws://localhost:3000/ws/logs"},
+		{"https://logs.example.com/", "p1", "wss://logs.example.com/ws/logs?project_id=p1"},
+	}
+	for _, tt := range tests {
+		got, err := streamURL(tt.base, tt.project)
+		if err != nil || got != tt.want {
+			t.Errorf("streamURL(%q, %q) = %q, %v, want %q", tt.base, tt.project, got, err, tt.want)
+		}
+	}
+	if _, err := streamURL("ftp://example.com", ""); err == nil {
+		t.Error("Expected an error for an unsupported scheme")
+	}
+}
+
+// lineWriter hands each printed line to a channel
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- strings.TrimSpace(string(p))
+	return len(p), nil
+}
+
+func TestStreamLogs_ReconnectsAfterDrop(t *testing.T) {
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Each connection delivers one log and then drops
+		n := connections.Add(1)
+		messages := []string{
+			`{"type":"log","data":{"level":"DEBUG","message":"filtered out","created_at":"2024-01-02T15:04:05Z"}}`,
+			`{"type":"log","data":{"level":"ERROR","message":"first","created_at":"2024-01-02T15:04:05Z"}}`,
+		}
+		if n > 1 {
+			messages = []string{`{"type":"log","data":{"level":"CRITICAL","message":"second","created_at":"2024-01-02T15:04:06Z"}}`}
+		}
+		for _, msg := range messages {
+			conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+	}))
+	defer server.Close()
+
+	lines := make(lineWriter, 10)
+	opts := tailOptions{baseURL: server.URL, token: "secret", minLevel: models.LogLevelError, out: lines}
+	stop := make(chan struct{})
+	result := make(chan error, 1)
+	go func() { result <- streamLogs(opts, stop) }()
+
+	for _, want := range []string{"first", "second"} {
+		select {
+		case line := <-lines:
+			if !strings.HasSuffix(line, want) {
+				t.Errorf("Expected a line ending in %q, got %q", want, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %q", want)
+		}
+	}
+
+	close(stop)
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("streamLogs returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamLogs did not stop")
+	}
+}
+
+func TestStreamLogs_StopsOnUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	opts := tailOptions{baseURL: server.URL, token: "expired", out: make(lineWriter, 1)}
+	if err := streamLogs(opts, make(chan struct{})); err != errUnauthorized {
+		t.Errorf("Expected errUnauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"central-logs/internal/utils"
+)
+
+// maxBatchSize is the most logs the server accepts in one batch request
+const maxBatchSize = 100
+
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+)
+
+type batchOptions struct {
+	size        int
+	concurrency int
+	quiet       bool
+}
+
+type batchStats struct {
+	sent        atomic.Int64 // Logs the server accepted
+	failed      atomic.Int64 // Logs in batches that were not accepted
+	rateLimited atomic.Int64 // 429 responses that were retried
+}
+
+// batchURL turns the single-log endpoint into the batch endpoint
+func batchURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if strings.HasSuffix(apiURL, "/batch") {
+		return apiURL
+	}
+	return apiURL + "/batch"
+}
+
+// runBatch posts generated logs in batches from several goroutines until stop
+// is closed, then reports throughput
+func runBatch(apiURL, apiKey string, opts batchOptions, stop <-chan struct{}) {
+	url := batchURL(apiURL)
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var stats batchStats
+	var wg sync.WaitGroup
+	startTime := time.Now()
+
+	for i := 0; i < opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				batch := make([]LogEntry, opts.size)
+				for j := range batch {
+					batch[j] = generateEntry()
+				}
+				postBatch(client, url, apiKey, batch, &stats, opts.quiet, stop)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	elapsed := time.Since(startTime)
+	sent := stats.sent.Load()
+	fmt.Printf("\n\nSent %d logs in %v (%.1f logs/sec), %d failed, %d rate-limited retries\n",
+		sent, elapsed.Round(time.Millisecond), float64(sent)/elapsed.Seconds(),
+		stats.failed.Load(), stats.rateLimited.Load())
+}
+
+// postBatch sends one batch, retrying with backoff while the server answers 429
+func postBatch(client *http.Client, url, apiKey string, batch []LogEntry, stats *batchStats, quiet bool, stop <-chan struct{}) {
+	body, err := json.Marshal(map[string]interface{}{"logs": batch})
+	if err != nil {
+		stats.failed.Add(int64(len(batch)))
+		return
+	}
+
+	backoff := initialBackoff
+	for {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			stats.failed.Add(int64(len(batch)))
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", apiKey)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if !quiet {
+				fmt.Printf("Error sending batch: %v\n", err)
+			}
+			stats.failed.Add(int64(len(batch)))
+			return
+		}
+
+		var result struct {
+			Received int    `json:"received"`
+			Error    string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusAccepted:
+			stats.sent.Add(int64(result.Received))
+			stats.failed.Add(int64(len(batch) - result.Received))
+			if !quiet {
+				fmt.Printf("[%s] batch of %d accepted\n", time.Now().Format("15:04:05"), result.Received)
+			}
+			return
+
+		case resp.StatusCode == http.StatusTooManyRequests:
+			stats.rateLimited.Add(1)
+			wait := retryAfter(resp.Header.Get("Retry-After"), backoff)
+			if !quiet {
+				fmt.Printf("[%s] rate limited, retrying in %v\n", time.Now().Format("15:04:05"), wait)
+			}
+			select {
+			case <-stop:
+				stats.failed.Add(int64(len(batch)))
+				return
+			case <-time.After(wait):
+			}
+			backoff = min(backoff*2, maxBackoff)
+
+		default:
+			if !quiet {
+				fmt.Printf("[%s] %sFAILED%s  %d %s\n", time.Now().Format("15:04:05"), utils.ColorRed, utils.ColorReset, resp.StatusCode, result.Error)
+			}
+			stats.failed.Add(int64(len(batch)))
+			return
+		}
+	}
+}
+
+// retryAfter honours a Retry-After header in seconds, falling back to backoff
+func retryAfter(header string, backoff time.Duration) time.Duration {
+	if secs, err := strconv.Atoi(header); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return backoff
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatchURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:3000/api/v1/logs":        "http://localhost:3000/api/v1/logs/batch",
+		"http://localhost:3000/api/v1/logs/":       "http://localhost:3000/api/v1/logs/batch",
+		"http://localhost:3000/api/v1/logs/batch":  "http://localhost:3000/api/v1/logs/batch",
+		"http://localhost:3000/api/v1/logs/batch/": "http://localhost:3000/api/v1/logs/batch",
+	}
+	for in, want := range tests {
+		if got := batchURL(in); got != want {
+			t.Errorf("batchURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPostBatch_RetriesAfterRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Rate limit exceeded"})
+			return
+		}
+
+		var req struct {
+			Logs []LogEntry `json:"logs"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int{"received": len(req.Logs)})
+	}))
+	defer server.Close()
+
+	batch := []LogEntry{generateEntry(), generateEntry(), generateEntry()}
+	var stats batchStats
+	postBatch(server.Client(), server.URL, "key", batch, &stats, true, make(chan struct{}))
+
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", calls.Load())
+	}
+	if stats.sent.Load() != 3 || stats.failed.Load() != 0 || stats.rateLimited.Load() != 1 {
+		t.Errorf("Unexpected stats: sent=%d failed=%d rateLimited=%d",
+			stats.sent.Load(), stats.failed.Load(), stats.rateLimited.Load())
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"central-logs/internal/utils"
 )
 
 type LogEntry struct {
@@ -22,11 +24,13 @@ type LogEntry struct {
 	Timestamp string                 `json:"timestamp,omitempty"`
 }
 
-// Weighted levels - higher weight = more frequent
-var levelWeights = []struct {
+type levelWeight struct {
 	level  string
 	weight int
-}{
+}
+
+// Weighted levels - higher weight = more frequent
+var levelWeights = []levelWeight{
 	{"DEBUG", 30},
 	{"INFO", 40},
 	{"WARN", 15},
@@ -117,6 +121,12 @@ func main() {
 	minInterval := flag.Duration("min", 5*time.Second, "Minimum interval between logs")
 	maxInterval := flag.Duration("max", 30*time.Second, "Maximum interval between logs")
 	quiet := flag.Bool("q", false, "Quiet mode - minimal output")
+	replayFile := flag.String("replay", "", "Replay a newline-delimited JSON or plain-text log file instead of generating logs")
+	preserveTimestamps := flag.Bool("preserve-timestamps", false, "With -replay, send each line's original timestamp")
+	speed := flag.Float64("speed", 1, "With -replay, playback speed multiplier (e.g. 1440 replays a day in a minute, 0 = no delay)")
+	batchSize := flag.Int("batch", 0, fmt.Sprintf("Send generated logs to the batch endpoint in batches of this size (max %d), as fast as possible", maxBatchSize))
+	concurrency := flag.Int("concurrency", 1, "With -batch, number of goroutines sending batches")
+	scenarioFile := flag.String("config", "", "JSON file overriding level weights, message templates and sources")
 
 	flag.Parse()
 
@@ -136,15 +146,73 @@ func main() {
 		fmt.Println("")
 		fmt.Println("  # Quiet mode")
 		fmt.Println("  loggen -key YOUR_API_KEY -duration 1h -q")
+		fmt.Println("")
+		fmt.Println("  # Replay a day of logs in one minute, keeping original timestamps")
+		fmt.Println("  loggen -key YOUR_API_KEY -replay app.log -speed 1440 -preserve-timestamps")
+		fmt.Println("")
+		fmt.Println("  # Load test the batch endpoint for 1 minute with 8 senders")
+		fmt.Println("  loggen -key YOUR_API_KEY -batch 100 -concurrency 8 -duration 1m -q")
+		fmt.Println("")
+		fmt.Println("  # Simulate an incident with a custom level mix and messages")
+		fmt.Println("  loggen -key YOUR_API_KEY -config incident.json")
 		os.Exit(1)
 	}
 
+	if *scenarioFile != "" {
+		if err := loadScenario(*scenarioFile); err != nil {
+			fmt.Printf("Invalid scenario %s: %v\n", *scenarioFile, err)
+			os.Exit(1)
+		}
+	}
+
+	if *batchSize > maxBatchSize {
+		fmt.Printf("-batch is limited to %d logs by the server, using %d\n", maxBatchSize, maxBatchSize)
+		*batchSize = maxBatchSize
+	}
+	if *concurrency < 1 {
+		*concurrency = 1
+	}
+
 	rand.Seed(time.Now().UnixNano())
 
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	if *replayFile != "" {
+		err := runReplay(*apiURL, *apiKey, *replayFile, replayOptions{
+			preserveTimestamps: *preserveTimestamps,
+			speed:              *speed,
+			quiet:              *quiet,
+		}, sigChan)
+		if err != nil {
+			fmt.Printf("Replay failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *batchSize > 0 {
+		stop := make(chan struct{})
+		go func() {
+			if *duration > 0 {
+				select {
+				case <-sigChan:
+				case <-time.After(*duration):
+				}
+			} else {
+				<-sigChan
+			}
+			close(stop)
+		}()
+
+		if !*quiet {
+			fmt.Printf("Sending batches of %d from %d goroutines to %s. Press Ctrl+C to stop.\n", *batchSize, *concurrency, batchURL(*apiURL))
+		}
+		runBatch(*apiURL, *apiKey, batchOptions{size: *batchSize, concurrency: *concurrency, quiet: *quiet}, stop)
+		return
+	}
+
 	var endTime time.Time
 	if *duration > 0 {
 		endTime = time.Now().Add(*duration)
@@ -186,6 +254,11 @@ func main() {
 }
 
 func sendLog(apiURL, apiKey string, quiet bool) {
+	postLog(apiURL, apiKey, generateEntry(), quiet)
+}
+
+// generateEntry builds a random log with a weighted level, message and source
+func generateEntry() LogEntry {
 	// Pick weighted random level
 	level := pickWeightedLevel()
 
@@ -195,7 +268,7 @@ func sendLog(apiURL, apiKey string, quiet bool) {
 	// Pick random source
 	source := sources[rand.Intn(len(sources))]
 
-	log := LogEntry{
+	return LogEntry{
 		Level:     level,
 		Message:   message,
 		Source:    source,
@@ -207,13 +280,16 @@ func sendLog(apiURL, apiKey string, quiet bool) {
 			"pid":        rand.Intn(65535),
 		},
 	}
+}
 
+// postLog sends one log to the API and reports whether it was accepted
+func postLog(apiURL, apiKey string, log LogEntry, quiet bool) bool {
 	body, err := json.Marshal(log)
 	if err != nil {
 		if !quiet {
 			fmt.Printf("Error marshaling log: %v\n", err)
 		}
-		return
+		return false
 	}
 
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(body))
@@ -221,7 +297,7 @@ func sendLog(apiURL, apiKey string, quiet bool) {
 		if !quiet {
 			fmt.Printf("Error creating request: %v\n", err)
 		}
-		return
+		return false
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -233,23 +309,28 @@ func sendLog(apiURL, apiKey string, quiet bool) {
 		if !quiet {
 			fmt.Printf("Error sending request: %v\n", err)
 		}
-		return
+		return false
 	}
 	defer resp.Body.Close()
 
+	// 202 when the server buffers ingestion
+	accepted := resp.StatusCode == 201 || resp.StatusCode == 202
+
 	if !quiet {
 		var result map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&result)
 
-		levelColor := getLevelColor(level)
+		levelColor := utils.LevelColor(log.Level)
 		timestamp := time.Now().Format("15:04:05")
 
-		if resp.StatusCode == 201 {
-			fmt.Printf("[%s] %s%-8s%s %s\n", timestamp, levelColor, level, "\033[0m", message)
+		if accepted {
+			fmt.Printf("[%s] %s%-8s%s %s\n", timestamp, levelColor, log.Level, utils.ColorReset, log.Message)
 		} else {
-			fmt.Printf("[%s] %sFAILED%s  %v\n", timestamp, "\033[31m", "\033[0m", result["error"])
+			fmt.Printf("[%s] %sFAILED%s  %v\n", timestamp, utils.ColorRed, utils.ColorReset, result["error"])
 		}
 	}
+
+	return accepted
 }
 
 func pickWeightedLevel() string {
@@ -275,7 +356,7 @@ func generateMessage(level string) string {
 	// Count %d placeholders and replace with random values
 	count := strings.Count(template, "%d")
 	if count == 0 {
-		return template
+		return strings.ReplaceAll(template, "%%", "%")
 	}
 
 	args := make([]interface{}, count)
@@ -286,23 +367,6 @@ func generateMessage(level string) string {
 	return fmt.Sprintf(template, args...)
 }
 
-func getLevelColor(level string) string {
-	switch level {
-	case "DEBUG":
-		return "\033[36m" // Cyan
-	case "INFO":
-		return "\033[32m" // Green
-	case "WARN":
-		return "\033[33m" // Yellow
-	case "ERROR":
-		return "\033[31m" // Red
-	case "CRITICAL":
-		return "\033[35m" // Magenta
-	default:
-		return "\033[0m"
-	}
-}
-
 func getHostname() string {
 	hostname, err := os.Hostname()
 	if err != nil {
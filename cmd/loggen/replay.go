@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys checked, in order, when reading a JSON log line
+var (
+	jsonMessageKeys   = []string{"message", "msg"}
+	jsonLevelKeys     = []string{"level", "severity", "lvl"}
+	jsonTimestampKeys = []string{"timestamp", "time", "ts", "@timestamp"}
+	jsonSourceKeys    = []string{"source", "logger", "service"}
+)
+
+// Layouts tried for timestamps in plain-text lines and JSON string fields
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05,000",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+}
+
+// replayLine is one parsed line of a replay file. Time is zero when the
+// line carried no recognizable timestamp.
+type replayLine struct {
+	Entry LogEntry
+	Time  time.Time
+}
+
+// parseReplayLine reads a newline-delimited JSON log or a plain-text line such
+// as "2024-01-02 15:04:05 [ERROR] message" or "WARN: message"
+func parseReplayLine(line string) (replayLine, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return replayLine{}, errors.New("empty line")
+	}
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLine(line)
+	}
+	return parseTextLine(line), nil
+}
+
+func parseJSONLine(line string) (replayLine, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return replayLine{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	message, _ := takeField(fields, jsonMessageKeys).(string)
+	if message == "" {
+		return replayLine{}, errors.New("no message field")
+	}
+
+	var parsed replayLine
+	parsed.Entry.Message = message
+	if level, ok := takeField(fields, jsonLevelKeys).(string); ok {
+		parsed.Entry.Level = normalizeLevel(level)
+	} else {
+		parsed.Entry.Level = "INFO"
+	}
+	if source, ok := takeField(fields, jsonSourceKeys).(string); ok {
+		parsed.Entry.Source = source
+	}
+	if ts := takeField(fields, jsonTimestampKeys); ts != nil {
+		parsed.Time = parseTimestampValue(ts)
+	}
+
+	// Whatever is left travels as metadata
+	if len(fields) > 0 {
+		parsed.Entry.Metadata = fields
+	}
+	return parsed, nil
+}
+
+// takeField removes and returns the first of keys present in fields
+func takeField(fields map[string]interface{}, keys []string) interface{} {
+	for _, key := range keys {
+		if v, ok := fields[key]; ok {
+			delete(fields, key)
+			return v
+		}
+	}
+	return nil
+}
+
+// parseTimestampValue accepts a formatted time or Unix seconds/milliseconds
+func parseTimestampValue(v interface{}) time.Time {
+	switch val := v.(type) {
+	case string:
+		if t, ok := parseTimestamp(val); ok {
+			return t
+		}
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return unixTime(f)
+		}
+	case float64:
+		return unixTime(val)
+	}
+	return time.Time{}
+}
+
+func unixTime(f float64) time.Time {
+	// Anything this large is milliseconds rather than seconds
+	if f > 1e12 {
+		return time.UnixMilli(int64(f))
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+func parseTimestamp(s string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseTextLine(line string) replayLine {
+	var parsed replayLine
+	rest := line
+
+	// Leading timestamp: a "date time" pair or a single RFC3339 token
+	fields := strings.Fields(rest)
+	if len(fields) >= 2 {
+		if t, ok := parseTimestamp(fields[0] + " " + fields[1]); ok {
+			parsed.Time = t
+			rest = strings.TrimSpace(rest[len(fields[0]):])
+			rest = strings.TrimSpace(rest[len(fields[1]):])
+		}
+	}
+	if parsed.Time.IsZero() && len(fields) >= 1 {
+		if t, ok := parseTimestamp(fields[0]); ok {
+			parsed.Time = t
+			rest = strings.TrimSpace(rest[len(fields[0]):])
+		}
+	}
+
+	// Level token such as "ERROR", "[warn]" or "INFO:"
+	parsed.Entry.Level = "INFO"
+	token, after, _ := strings.Cut(rest, " ")
+	if level, ok := levelToken(token); ok {
+		parsed.Entry.Level = level
+		rest = strings.TrimSpace(after)
+	}
+
+	parsed.Entry.Message = rest
+	if parsed.Entry.Message == "" {
+		parsed.Entry.Message = line
+	}
+	return parsed
+}
+
+// levelToken recognizes a level word, optionally bracketed or followed by a colon
+func levelToken(token string) (string, bool) {
+	token = strings.TrimSuffix(token, ":")
+	token = strings.TrimSuffix(strings.TrimPrefix(token, "["), "]")
+	switch strings.ToUpper(token) {
+	case "TRACE", "DEBUG", "INFO", "WARN", "WARNING", "ERR", "ERROR", "CRITICAL", "FATAL", "PANIC":
+		return normalizeLevel(token), true
+	}
+	return "", false
+}
+
+// normalizeLevel maps common level spellings onto the server's levels.
+// Unknown levels become INFO, matching how the server treats them.
+func normalizeLevel(level string) string {
+	switch strings.ToUpper(level) {
+	case "TRACE", "DEBUG":
+		return "DEBUG"
+	case "INFO":
+		return "INFO"
+	case "WARN", "WARNING":
+		return "WARN"
+	case "ERR", "ERROR":
+		return "ERROR"
+	case "CRITICAL", "FATAL", "PANIC":
+		return "CRITICAL"
+	default:
+		return "INFO"
+	}
+}
+
+type replayOptions struct {
+	preserveTimestamps bool
+	speed              float64 // Playback multiplier; 0 sends as fast as possible
+	quiet              bool
+}
+
+// runReplay ships every line of path to the ingestion API, pausing between
+// lines by the gap between their original timestamps divided by speed
+func runReplay(apiURL, apiKey, path string, opts replayOptions, stop <-chan os.Signal) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var sent, failed, skipped, lineNo int
+	var lastTime time.Time
+	startTime := time.Now()
+
+	for scanner.Scan() {
+		lineNo++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		parsed, err := parseReplayLine(scanner.Text())
+		if err != nil {
+			skipped++
+			if !opts.quiet {
+				fmt.Printf("Skipping line %d: %v\n", lineNo, err)
+			}
+			continue
+		}
+
+		if !parsed.Time.IsZero() {
+			if !lastTime.IsZero() && opts.speed > 0 && parsed.Time.After(lastTime) {
+				gap := time.Duration(float64(parsed.Time.Sub(lastTime)) / opts.speed)
+				select {
+				case <-stop:
+					printReplaySummary(sent, failed, skipped, startTime)
+					return nil
+				case <-time.After(gap):
+				}
+			}
+			lastTime = parsed.Time
+		}
+
+		select {
+		case <-stop:
+			printReplaySummary(sent, failed, skipped, startTime)
+			return nil
+		default:
+		}
+
+		entry := parsed.Entry
+		if opts.preserveTimestamps && !parsed.Time.IsZero() {
+			entry.Timestamp = parsed.Time.Format(time.RFC3339)
+		} else {
+			entry.Timestamp = time.Now().Format(time.RFC3339)
+		}
+
+		if postLog(apiURL, apiKey, entry, opts.quiet) {
+			sent++
+		} else {
+			failed++
+		}
+	}
+
+	printReplaySummary(sent, failed, skipped, startTime)
+	return scanner.Err()
+}
+
+func printReplaySummary(sent, failed, skipped int, startTime time.Time) {
+	fmt.Printf("\nReplay finished in %v: %d sent, %d failed, %d skipped\n",
+		time.Since(startTime).Round(time.Second), sent, failed, skipped)
+}
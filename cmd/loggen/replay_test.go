@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReplayLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		wantLevel   string
+		wantMessage string
+		wantSource  string
+		wantTime    bool
+		wantErr     bool
+	}{
+		{
+			name:        "json",
+			line:        `{"level":"error","msg":"db down","time":"2024-01-02T15:04:05Z","service":"api","host":"web-1"}`,
+			wantLevel:   "ERROR",
+			wantMessage: "db down",
+			wantSource:  "api",
+			wantTime:    true,
+		},
+		{
+			name:        "json with unix milliseconds",
+			line:        `{"severity":"warning","message":"slow","ts":1704207845000}`,
+			wantLevel:   "WARN",
+			wantMessage: "slow",
+			wantTime:    true,
+		},
+		{
+			name:    "json without message",
+			line:    `{"level":"info"}`,
+			wantErr: true,
+		},
+		{
+			name:    "broken json",
+			line:    `{"level":`,
+			wantErr: true,
+		},
+		{
+			name:        "text with date, time and bracketed level",
+			line:        "2024-01-02 15:04:05 [FATAL] out of memory",
+			wantLevel:   "CRITICAL",
+			wantMessage: "out of memory",
+			wantTime:    true,
+		},
+		{
+			name:        "text with RFC3339 time",
+			line:        "2024-01-02T15:04:05Z WARN: disk at 91%",
+			wantLevel:   "WARN",
+			wantMessage: "disk at 91%",
+			wantTime:    true,
+		},
+		{
+			name:        "bare text",
+			line:        "something happened",
+			wantLevel:   "INFO",
+			wantMessage: "something happened",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseReplayLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", parsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReplayLine failed: %v", err)
+			}
+
+			if parsed.Entry.Level != tt.wantLevel {
+				t.Errorf("Level = %q, want %q", parsed.Entry.Level, tt.wantLevel)
+			}
+			if parsed.Entry.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", parsed.Entry.Message, tt.wantMessage)
+			}
+			if parsed.Entry.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", parsed.Entry.Source, tt.wantSource)
+			}
+			if parsed.Time.IsZero() == tt.wantTime {
+				t.Errorf("Time = %v, want a timestamp: %v", parsed.Time, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestParseReplayLine_KeepsExtraJSONFieldsAsMetadata(t *testing.T) {
+	parsed, err := parseReplayLine(`{"message":"hi","time":"2024-01-02T15:04:05Z","user":"alice"}`)
+	if err != nil {
+		t.Fatalf("parseReplayLine failed: %v", err)
+	}
+
+	if len(parsed.Entry.Metadata) != 1 || parsed.Entry.Metadata["user"] != "alice" {
+		t.Errorf("Expected only user in metadata, got %v", parsed.Entry.Metadata)
+	}
+	if !parsed.Time.Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected time %v", parsed.Time)
+	}
+}
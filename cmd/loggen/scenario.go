@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// levelOrder is the order levels are weighted in, matching levelWeights
+var levelOrder = []string{"DEBUG", "INFO", "WARN", "ERROR", "CRITICAL"}
+
+// scenarioConfig overrides the built-in level mix, messages and sources.
+// Omitted sections keep their defaults.
+//
+//	{
+//	  "levels":   {"ERROR": 80, "CRITICAL": 15, "INFO": 5},
+//	  "messages": {"ERROR": ["Checkout failed for order #%d"]},
+//	  "sources":  ["checkout", "payments"]
+//	}
+type scenarioConfig struct {
+	Levels   map[string]int      `json:"levels"`
+	Messages map[string][]string `json:"messages"`
+	Sources  []string            `json:"sources"`
+}
+
+// loadScenario reads a scenario file and applies it over the defaults
+func loadScenario(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var cfg scenarioConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("invalid scenario JSON: %w", err)
+	}
+
+	return cfg.apply()
+}
+
+// apply validates the scenario and replaces the generator's level weights,
+// messages and sources. Nothing changes if validation fails.
+func (cfg scenarioConfig) apply() error {
+	weights := levelWeights
+	if cfg.Levels != nil {
+		byLevel := make(map[string]int, len(cfg.Levels))
+		for level, weight := range cfg.Levels {
+			upper := strings.ToUpper(level)
+			if !isKnownLevel(upper) {
+				return fmt.Errorf("unknown level %q (use %s)", level, strings.Join(levelOrder, ", "))
+			}
+			if weight < 0 {
+				return fmt.Errorf("weight for %s must not be negative", upper)
+			}
+			byLevel[upper] = weight
+		}
+
+		weights = nil
+		total := 0
+		for _, level := range levelOrder {
+			if w := byLevel[level]; w > 0 {
+				weights = append(weights, levelWeight{level, w})
+				total += w
+			}
+		}
+		if total == 0 {
+			return fmt.Errorf("at least one level needs a positive weight")
+		}
+	}
+
+	messages := make(map[string][]string, len(sampleMessages))
+	for level, templates := range sampleMessages {
+		messages[level] = templates
+	}
+	for level, templates := range cfg.Messages {
+		upper := strings.ToUpper(level)
+		if !isKnownLevel(upper) {
+			return fmt.Errorf("messages for unknown level %q", level)
+		}
+		for _, template := range templates {
+			if err := validateTemplate(template); err != nil {
+				return fmt.Errorf("%s message %q: %w", upper, template, err)
+			}
+		}
+		messages[upper] = templates
+	}
+	for _, lw := range weights {
+		if len(messages[lw.level]) == 0 {
+			return fmt.Errorf("level %s has a weight but no messages", lw.level)
+		}
+	}
+
+	srcs := sources
+	if cfg.Sources != nil {
+		srcs = nil
+		for _, source := range cfg.Sources {
+			if strings.TrimSpace(source) == "" {
+				return fmt.Errorf("sources must not be empty strings")
+			}
+			srcs = append(srcs, source)
+		}
+		if len(srcs) == 0 {
+			return fmt.Errorf("sources must list at least one source")
+		}
+	}
+
+	levelWeights = weights
+	sampleMessages = messages
+	sources = srcs
+	return nil
+}
+
+func isKnownLevel(level string) bool {
+	for _, l := range levelOrder {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTemplate allows only %d placeholders, which generateMessage fills
+// with random numbers, and %% for a literal percent sign
+func validateTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("message is empty")
+	}
+	rest := strings.NewReplacer("%%", "", "%d", "").Replace(template)
+	if strings.Contains(rest, "%") {
+		return fmt.Errorf("only %%d placeholders are supported")
+	}
+	return nil
+}
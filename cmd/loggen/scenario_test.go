@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withDefaults restores the built-in generator settings after a test
+func withDefaults(t *testing.T) {
+	weights, messages, srcs := levelWeights, sampleMessages, sources
+	t.Cleanup(func() {
+		levelWeights, sampleMessages, sources = weights, messages, srcs
+	})
+}
+
+func TestScenarioConfig_Apply(t *testing.T) {
+	withDefaults(t)
+	builtinInfo := sampleMessages["INFO"]
+
+	cfg := scenarioConfig{
+		Levels:   map[string]int{"error": 80, "INFO": 20},
+		Messages: map[string][]string{"ERROR": {"Checkout failed for order #%d"}},
+		Sources:  []string{"checkout"},
+	}
+	if err := cfg.apply(); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+
+	want := []levelWeight{{"INFO", 20}, {"ERROR", 80}}
+	if !reflect.DeepEqual(levelWeights, want) {
+		t.Errorf("levelWeights = %v, want %v", levelWeights, want)
+	}
+	if !reflect.DeepEqual(sampleMessages["ERROR"], []string{"Checkout failed for order #%d"}) {
+		t.Errorf("ERROR messages not replaced: %v", sampleMessages["ERROR"])
+	}
+	if !reflect.DeepEqual(sampleMessages["INFO"], builtinInfo) {
+		t.Error("INFO messages should keep the built-in templates")
+	}
+	if !reflect.DeepEqual(sources, []string{"checkout"}) {
+		t.Errorf("sources = %v", sources)
+	}
+
+	for i := 0; i < 50; i++ {
+		if level := pickWeightedLevel(); level != "INFO" && level != "ERROR" {
+			t.Fatalf("Picked level %s outside the scenario", level)
+		}
+	}
+}
+
+func TestScenarioConfig_Invalid(t *testing.T) {
+	tests := map[string]scenarioConfig{
+		"unknown level":       {Levels: map[string]int{"LOUD": 1}},
+		"negative weight":     {Levels: map[string]int{"INFO": -1}},
+		"all weights zero":    {Levels: map[string]int{"INFO": 0}},
+		"unsupported verb":    {Messages: map[string][]string{"INFO": {"user %s logged in"}}},
+		"empty message":       {Messages: map[string][]string{"INFO": {" "}}},
+		"weight without text": {Levels: map[string]int{"INFO": 1}, Messages: map[string][]string{"INFO": {}}},
+		"empty source list":   {Sources: []string{}},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			withDefaults(t)
+			before := levelWeights
+
+			if err := cfg.apply(); err == nil {
+				t.Fatal("Expected a validation error")
+			}
+			if !reflect.DeepEqual(levelWeights, before) {
+				t.Error("A rejected scenario must leave the defaults in place")
+			}
+		})
+	}
+}
+
+func TestLoadScenario_Example(t *testing.T) {
+	withDefaults(t)
+
+	if err := loadScenario(filepath.Join("scenarios", "incident.json")); err != nil {
+		t.Fatalf("Example scenario is invalid: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "broken.json")
+	os.WriteFile(path, []byte(`{"levels":`), 0644)
+	if err := loadScenario(path); err == nil {
+		t.Error("Expected an error for malformed JSON")
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,11 +14,16 @@ import (
 	"central-logs/internal/database"
 	"central-logs/internal/database/migrations"
 	"central-logs/internal/handlers"
+	"central-logs/internal/logging"
 	"central-logs/internal/mcp"
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
+	"central-logs/internal/openapi"
 	"central-logs/internal/queue"
+	"central-logs/internal/services/geoip"
+	"central-logs/internal/services/mail"
 	"central-logs/internal/services/notification"
+	"central-logs/internal/services/redaction"
 	"central-logs/internal/utils"
 	"central-logs/internal/websocket"
 	"central-logs/internal/worker"
@@ -25,7 +32,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
@@ -36,22 +42,43 @@ var (
 	GitCommit = "unknown"
 )
 
+const configPath = "config.yaml"
+
 func main() {
 	// Load config
-	cfg, err := config.Load("config.yaml")
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+	logging.Setup(cfg.GetLogFormat(), os.Stderr)
+	cfgHolder := config.NewHolder(cfg)
 
 	// Initialize database
-	db, err := database.New(cfg.Database.Path)
+	db, err := database.Open(cfg.GetDatabaseDriver(), cfg.GetDatabaseDSN(), database.SQLiteOptions{
+		WAL:           cfg.Database.WAL,
+		BusyTimeoutMS: cfg.Database.BusyTimeoutMS,
+		Synchronous:   cfg.Database.Synchronous,
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
+	registry := migrations.ForDriver(db.Dialect.Name())
+
+	// Maintenance subcommands run against the database and exit
+	if len(os.Args) > 1 {
+		if err := runCommand(db, registry, os.Args[1]); err != nil {
+			log.Fatalf("%s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// Run migrations with Laravel-style tracking
-	if err := db.MigrateWithRegistry(migrations.GetAll()); err != nil {
+	if err := db.MigrateWithRegistry(registry); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
@@ -62,8 +89,11 @@ func main() {
 		log.Printf("Realtime features and rate limiting will be disabled")
 		redisClient = nil
 	}
+	var redisHealthChecker *queue.HealthChecker
 	if redisClient != nil {
 		defer redisClient.Close()
+		redisHealthChecker = queue.NewHealthChecker(redisClient, cfg.GetRedisHealthCheckInterval())
+		redisHealthChecker.Start()
 	}
 
 	// Initialize repositories
@@ -71,28 +101,48 @@ func main() {
 	projectRepo := models.NewProjectRepository(db.DB)
 	userProjectRepo := models.NewUserProjectRepository(db.DB)
 	logRepo := models.NewLogRepository(db.DB)
+	logRepo.SetDialect(db.Dialect)
 	channelRepo := models.NewChannelRepository(db.DB)
 	subscriptionRepo := models.NewPushSubscriptionRepository(db.DB)
 	mcpTokenRepo := models.NewMCPTokenRepository(db.DB)
 	mcpActivityRepo := models.NewMCPActivityLogRepository(db.DB)
+	alertRuleRepo := models.NewAlertRuleRepository(db.DB)
+	failedNotificationRepo := models.NewFailedNotificationRepository(db.DB)
+	apiKeyUsageRepo := models.NewAPIKeyUsageRepository(db.DB)
+	auditLogRepo := models.NewAuditLogRepository(db.DB)
+	revokedTokenRepo := models.NewRevokedTokenRepository(db.DB)
+	channelDeliveryRepo := models.NewChannelDeliveryRepository(db.DB)
+	pinnedProjectRepo := models.NewPinnedProjectRepository(db.DB)
 
 	// Create initial admin user if no users exist
 	if err := createInitialAdmin(userRepo, cfg); err != nil {
 		log.Printf("Warning: Failed to create initial admin: %v", err)
 	}
 
+	utils.SetPasswordPolicy(utils.PasswordPolicy{
+		MinLength:        cfg.GetPasswordMinLength(),
+		RequireMixedCase: cfg.Password.RequireMixedCase,
+		RequireDigit:     cfg.Password.RequireDigit,
+		RequireSymbol:    cfg.Password.RequireSymbol,
+	})
+	middleware.SetObscureNotFound(cfg.Security.ObscureNotFound)
+
 	// Initialize JWT manager
 	jwtManager := utils.NewJWTManager(cfg.JWT.Secret, cfg.GetJWTExpiry())
+	jwtManager.SetIssuer(cfg.JWT.Issuer, cfg.JWT.Audience)
 
 	// Initialize middlewares
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+	authMiddleware.SetRevokedTokens(revokedTokenRepo)
 	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+	apiKeyUsageTracker := middleware.NewAPIKeyUsageTracker(apiKeyUsageRepo, redisClient)
+	apiKeyMiddleware.SetUsageTracker(apiKeyUsageTracker)
 	rbacMiddleware := middleware.NewRBACMiddleware(userProjectRepo)
 
 	var rateLimitMiddleware *middleware.RateLimitMiddleware
 	if redisClient != nil {
-		rateLimiter := queue.NewRateLimiter(redisClient.Client())
-		rateLimitMiddleware = middleware.NewRateLimitMiddleware(rateLimiter, cfg.RateLimit.API.RequestsPerMinute)
+		rateLimiter := redisClient.RateLimiter()
+		rateLimitMiddleware = middleware.NewRateLimitMiddleware(rateLimiter, cfg.RateLimit.API.RequestsPerMinute, cfg.GetAPIRateBurst())
 	}
 
 	// Initialize services
@@ -100,60 +150,192 @@ func main() {
 
 	// Initialize WebSocket hub
 	wsHub := websocket.NewHub()
+	wsHub.SetSlowClientPolicy(cfg.GetWebSocketSlowClientPolicy(), cfg.GetWebSocketSendBuffer())
 	go wsHub.Run()
 
 	wsHandler := websocket.NewHandler(wsHub, jwtManager, userRepo)
+	wsHandler.SetRevokedTokens(revokedTokenRepo)
 
 	// Initialize MCP server state
 	mcpEnabled := false
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userRepo, jwtManager)
+	authHandler.SetRevokedTokens(revokedTokenRepo)
 	twoFactorHandler := handlers.NewTwoFactorHandler(userRepo, jwtManager, "Central Logs")
 	userHandler := handlers.NewUserHandler(userRepo)
 	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
 	memberHandler := handlers.NewMemberHandler(userRepo, userProjectRepo)
+	auditRecorder := handlers.NewAuditRecorder(auditLogRepo)
+	twoFactorHandler.SetAuditRecorder(auditRecorder)
+	twoFactorHandler.SetTOTPSkew(cfg.Auth.TOTP.Skew)
+	var twoFactorLimiter *queue.RateLimiter
+	if redisClient != nil {
+		twoFactorLimiter = redisClient.RateLimiter()
+	}
+	twoFactorHandler.SetAttemptLimit(twoFactorLimiter, cfg.Auth.TOTP.MaxAttempts, cfg.GetTOTPAttemptWindow())
+	userHandler.SetAuditRecorder(auditRecorder)
+	userHandler.SetImpersonation(jwtManager, cfg.GetImpersonationExpiry())
+	projectHandler.SetAuditRecorder(auditRecorder)
+	projectHandler.SetMaxBodyLimit(cfg.GetBodyLimit())
+	projectHandler.SetPinnedProjects(pinnedProjectRepo)
+	projectHandler.SetChannelRepository(channelRepo)
+	projectHandler.SetRetentionFloor(func() config.RetentionFloor {
+		return cfgHolder.Get().Retention.Floor
+	})
+	memberHandler.SetAuditRecorder(auditRecorder)
+	auditHandler := handlers.NewAuditHandler(auditLogRepo)
 	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, redisClient, pushService, wsHub)
-	channelHandler := handlers.NewChannelHandler(channelRepo)
+	fanoutPool := worker.NewTaskPool(cfg.GetFanoutWorkers(), cfg.GetFanoutQueueSize())
+	logHandler.SetFanoutPool(fanoutPool)
+	projectWebhook := worker.NewProjectWebhook(fanoutPool, worker.RetryPolicy{
+		MaxAttempts: cfg.GetNotificationMaxAttempts(),
+		BackoffBase: cfg.GetNotificationRetryBackoff(),
+	}, cfg.GetNotificationHTTPTimeout())
+	logHandler.SetProjectWebhook(projectWebhook)
+	logHandler.SetIngestionLimits(handlers.IngestionLimits{
+		MaxMessageBytes:  cfg.GetIngestionMaxMessageBytes(),
+		MaxMetadataBytes: cfg.GetIngestionMaxMetadataBytes(),
+		MaxMetadataDepth: cfg.GetIngestionMaxMetadataDepth(),
+		MaxMetadataKeys:  cfg.GetIngestionMaxMetadataKeys(),
+		Truncate:         cfg.IngestionTruncates(),
+
+		MaxMetadataValueBytes: cfg.Ingestion.MaxMetadataValueBytes,
+		MetadataValuePolicy:   cfg.GetIngestionMetadataValuePolicy(),
+		MessageTruncateLength: cfg.Ingestion.MessageTruncateLength,
+	})
+	maxFuture, maxPast := cfg.GetIngestionTimestampWindow()
+	logHandler.SetTimestampWindow(handlers.TimestampWindow{
+		MaxFuture: maxFuture,
+		MaxPast:   maxPast,
+		Clamp:     cfg.IngestionClampsTimestamps(),
+	})
+	redactionRules, err := redaction.Compile(cfg.Ingestion.Redaction.Detectors, cfg.Ingestion.Redaction.Patterns)
+	if err != nil {
+		log.Fatalf("Invalid redaction rules: %v", err)
+	}
+	logHandler.SetRedactor(handlers.NewRedactor(redactionRules))
+	if cfg.Enrichment.GeoIP.Enabled {
+		resolver, err := geoip.NewResolver(cfg.Enrichment.GeoIP.DatabasePath, cfg.Enrichment.GeoIP.ASNDatabasePath)
+		if err != nil {
+			log.Printf("Warning: Failed to open GeoIP database: %v", err)
+			log.Printf("GeoIP enrichment will be disabled")
+		} else {
+			logHandler.SetGeoIPEnricher(handlers.NewGeoIPEnricher(resolver, cfg.GetGeoIPField()))
+		}
+	}
+	channelHandler := handlers.NewChannelHandler(channelRepo, cfg)
+	channelHandler.SetDeliveryRepository(channelDeliveryRepo)
+	alertRuleHandler := handlers.NewAlertRuleHandler(alertRuleRepo, channelRepo)
+	apiKeyUsageHandler := handlers.NewAPIKeyUsageHandler(projectRepo, apiKeyUsageRepo, apiKeyUsageTracker)
+	archiveHandler := handlers.NewArchiveHandler(logRepo, projectRepo)
+	notificationFailureHandler := handlers.NewNotificationFailureHandler(failedNotificationRepo, channelRepo, userProjectRepo, redisClient)
 	statsHandler := handlers.NewStatsHandler(logRepo, projectRepo, userProjectRepo, userRepo)
+	statsHandler.SetCache(handlers.NewStatsCache(redisClient, cfg.GetStatsCacheTTL()))
+	statsHandler.SetLocation(cfg.GetStatsLocation())
 	pushHandler := handlers.NewPushHandler(subscriptionRepo, cfg)
 	versionHandler := handlers.NewVersionHandler(Version)
 	telegramHandler := handlers.NewTelegramHandler(cfg)
 	mcpTokenHandler := handlers.NewMCPTokenHandler(mcpTokenRepo, mcpActivityRepo, projectRepo)
 	mcpSettingsHandler := handlers.NewMCPSettingsHandler(&mcpEnabled)
+	systemHandler := handlers.NewSystemHandler(db, registry)
 
 	// Initialize MCP server
+	queryBounds := models.LogQueryBounds{
+		DefaultRange: cfg.GetQueryDefaultRange(),
+		MaxLimit:     cfg.GetQueryMaxLimit(),
+	}
+	logHandler.SetQueryBounds(queryBounds)
+
 	mcpServer := mcp.NewMCPServer(mcpTokenRepo, mcpActivityRepo, logRepo, projectRepo, userRepo)
+	mcpServer.SetQueryBounds(queryBounds)
+	mcpServer.SetStatsLocation(cfg.GetStatsLocation())
+	mcpServer.SetAuditLogRepository(auditLogRepo)
 
 	// Initialize notification workers (if Redis is available)
+	notifier := worker.NewNotifier(channelRepo, cfgHolder)
+	notifier.SetDeliveryRecorder(channelDeliveryRepo)
+	notifier.SetProjectRepository(projectRepo)
+	var mailer mail.Mailer
+	if cfg.SMTP.Host != "" {
+		mailer = mail.NewSMTPMailer(cfg.SMTP)
+		notifier.SetMailer(mailer)
+	}
+	channelHandler.SetNotifier(notifier)
+	channelHandler.SetQueue(redisClient)
 	var notificationConsumer *worker.NotificationConsumer
 	if redisClient != nil {
-		notifier := worker.NewNotifier(channelRepo, cfg)
-		notificationConsumer = worker.NewNotificationConsumer(redisClient, notifier, channelRepo, logRepo)
+		retryPolicy := worker.RetryPolicy{
+			MaxAttempts: cfg.GetNotificationMaxAttempts(),
+			BackoffBase: cfg.GetNotificationRetryBackoff(),
+		}
+		notificationConsumer = worker.NewNotificationConsumer(redisClient, notifier, channelRepo, logRepo, failedNotificationRepo, retryPolicy)
 		notificationConsumer.Start(3) // Start 3 worker goroutines
 		log.Println("Notification workers started")
 	} else {
 		log.Println("Redis not available - notification workers disabled")
 	}
 
+	// Initialize alert rule evaluator
+	var alertEvaluator *worker.AlertEvaluator
+	if cfg.Alerts.Enabled {
+		alertEvaluator = worker.NewAlertEvaluator(alertRuleRepo, logRepo, channelRepo, redisClient, cfg.GetAlertsInterval())
+		alertEvaluator.Start()
+	}
+
+	// Email digests need a mail server
+	var emailDigestScheduler *worker.EmailDigestScheduler
+	if mailer != nil {
+		emailDigestScheduler = worker.NewEmailDigestScheduler(channelRepo, logRepo, projectRepo, channelDeliveryRepo, mailer)
+		emailDigestScheduler.Start()
+	}
+
+	// Initialize async ingestion buffer (opt-in; writes are synchronous by default)
+	var logBuffer *worker.LogBuffer
+	if cfg.Ingestion.AsyncBuffer.Enabled {
+		logBuffer = worker.NewLogBuffer(logRepo, cfg.GetIngestionFlushSize(), cfg.GetIngestionFlushInterval(), cfg.GetIngestionMaxPending())
+		logHandler.SetLogBuffer(logBuffer)
+		logBuffer.Start()
+	}
+	systemHandler.SetIngestion(logHandler, logBuffer)
+	systemHandler.SetRedis(redisClient)
+
+	// Scheduled VACUUM/ANALYZE; the manual endpoint works even when it is off
+	var maintenanceSchedule *utils.CronSchedule
+	if cfg.Database.Maintenance.Enabled {
+		maintenanceSchedule, _ = utils.ParseCron(cfg.Database.Maintenance.Schedule) // Checked by Validate
+	}
+	dbMaintenance := worker.NewDBMaintenance(db, maintenanceSchedule)
+	dbMaintenance.Start()
+	systemHandler.SetMaintenance(dbMaintenance)
+
+	// Admin-toggled maintenance mode turns ingestion away with 503
+	maintenanceMode := middleware.NewMaintenanceMode(models.NewSystemSettingRepository(db.DB))
+	systemHandler.SetMaintenanceMode(maintenanceMode)
+	systemHandler.SetAuditRecorder(auditRecorder)
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
+		BodyLimit: cfg.GetBodyLimit(),
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
 				code = e.Code
 			}
+			if code == fiber.StatusRequestEntityTooLarge {
+				err = fmt.Errorf("Request body is over the %d byte server limit", cfg.GetBodyLimit())
+			}
 			return c.Status(code).JSON(fiber.Map{
-				"error": err.Error(),
+				"error":      err.Error(),
+				"request_id": middleware.GetRequestID(c),
 			})
 		},
 	})
 
 	// Global middlewares
+	app.Use(middleware.RequestID())
+	app.Use(middleware.AccessLog(os.Stdout))
 	app.Use(recover.New())
-	app.Use(logger.New(logger.Config{
-		Format: "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path}\n",
-	}))
 
 	// Security headers middleware
 	app.Use(middleware.SecurityHeaders())
@@ -162,11 +344,15 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.Server.AllowOrigins,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-API-Key",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-API-Key,X-Request-ID",
+		ExposeHeaders:    "X-Request-ID",
 		AllowCredentials: false,
 		MaxAge:           3600,
 	}))
 
+	// Readiness probe (public)
+	app.Get("/ready", systemHandler.Ready)
+
 	// API routes
 	api := app.Group("/api")
 
@@ -182,6 +368,17 @@ func main() {
 	// Check for updates endpoint (public)
 	api.Get("/version/check", versionHandler.CheckUpdate)
 
+	// Log level ordering and display hints (public)
+	api.Get("/meta/levels", handlers.NewMetaHandler().ListLevels)
+
+	// API documentation (public)
+	openapiHandler, err := openapi.NewHandler(Version)
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI spec: %v", err)
+	}
+	api.Get("/openapi.json", openapiHandler.Spec)
+	api.Get("/docs", openapiHandler.Docs)
+
 	// Auth routes (public)
 	auth := api.Group("/auth")
 	auth.Post("/login", authHandler.Login)
@@ -189,18 +386,21 @@ func main() {
 
 	// Auth routes (protected)
 	authProtected := auth.Group("", authMiddleware.RequireAuth())
+	authProtected.Post("/logout", authHandler.Logout)
 	authProtected.Get("/me", authHandler.Me)
-	authProtected.Put("/me", authHandler.UpdateProfile)
-	authProtected.Put("/change-password", authHandler.ChangePassword)
+	authProtected.Put("/me", authMiddleware.BlockImpersonation(), authHandler.UpdateProfile)
+	authProtected.Put("/change-password", authMiddleware.BlockImpersonation(), authHandler.ChangePassword)
 
 	// Public log ingestion API (API key auth)
 	v1 := api.Group("/v1")
-	logIngestion := v1.Group("/logs", apiKeyMiddleware.RequireAPIKey())
+	logIngestion := v1.Group("/logs", maintenanceMode.RejectIngestion(), apiKeyMiddleware.RequireAPIKey(), middleware.IngestionBodyLimit(cfg.GetIngestionMaxBodyBytes()))
 	if rateLimitMiddleware != nil {
 		logIngestion.Use(rateLimitMiddleware.RateLimitByProject())
 	}
 	logIngestion.Post("", logHandler.CreateLog)
 	logIngestion.Post("/batch", logHandler.CreateBatchLogs)
+	logIngestion.Post("/text", logHandler.CreateTextLogs)
+	logIngestion.Post("/validate", logHandler.ValidateLog)
 
 	// Admin API (JWT auth)
 	admin := api.Group("/admin", authMiddleware.RequireAuth())
@@ -208,28 +408,45 @@ func main() {
 	// 2FA routes (protected, all authenticated users)
 	twoFactor := admin.Group("/2fa")
 	twoFactor.Get("/status", twoFactorHandler.GetStatus)
-	twoFactor.Post("/setup", twoFactorHandler.Setup)
-	twoFactor.Post("/verify", twoFactorHandler.Verify)
-	twoFactor.Post("/disable", twoFactorHandler.Disable)
-	twoFactor.Post("/backup-codes", twoFactorHandler.RegenerateBackupCodes)
+	twoFactor.Post("/setup", authMiddleware.BlockImpersonation(), twoFactorHandler.Setup)
+	twoFactor.Post("/verify", authMiddleware.BlockImpersonation(), twoFactorHandler.Verify)
+	twoFactor.Post("/disable", authMiddleware.BlockImpersonation(), twoFactorHandler.Disable)
+	twoFactor.Post("/backup-codes", authMiddleware.BlockImpersonation(), twoFactorHandler.RegenerateBackupCodes)
 
 	// Users (admin only)
 	users := admin.Group("/users", authMiddleware.RequireAdmin())
 	users.Get("", userHandler.ListUsers)
 	users.Post("", userHandler.CreateUser)
+	users.Post("/import", userHandler.ImportUsers)
 	users.Get("/:id", userHandler.GetUser)
 	users.Put("/:id", userHandler.UpdateUser)
 	users.Delete("/:id", userHandler.DeleteUser)
+	users.Post("/:id/restore", userHandler.RestoreUser)
+	users.Post("/:id/revoke-sessions", userHandler.RevokeSessions)
 	users.Put("/:id/reset-password", userHandler.ResetPassword)
+	users.Post("/:id/impersonate", userHandler.Impersonate)
 
 	// Projects
 	projects := admin.Group("/projects")
 	projects.Get("", projectHandler.ListProjects)
 	projects.Post("", projectHandler.CreateProject)
+	projects.Get("/groups", projectHandler.ListGroups)
 	projects.Get("/:id", rbacMiddleware.RequireProjectAccess(), projectHandler.GetProject)
 	projects.Put("/:id", rbacMiddleware.RequireOwner(), projectHandler.UpdateProject)
-	projects.Delete("/:id", rbacMiddleware.RequireOwner(), projectHandler.DeleteProject)
-	projects.Post("/:id/rotate-key", rbacMiddleware.RequireOwner(), projectHandler.RotateAPIKey)
+	projects.Delete("/:id", authMiddleware.BlockImpersonation(), rbacMiddleware.RequireOwner(), projectHandler.DeleteProject)
+	projects.Post("/:id/restore", rbacMiddleware.RequireDeletedProjectOwner(), projectHandler.RestoreProject)
+	projects.Post("/:id/clone", rbacMiddleware.RequireOwner(), projectHandler.CloneProject)
+	projects.Post("/:id/rotate-key", authMiddleware.BlockImpersonation(), rbacMiddleware.RequireOwner(), projectHandler.RotateAPIKey)
+	projects.Post("/:id/rotate-signing-secret", authMiddleware.BlockImpersonation(), rbacMiddleware.RequireOwner(), projectHandler.RotateSigningSecret)
+	projects.Delete("/:id/signing-secret", authMiddleware.BlockImpersonation(), rbacMiddleware.RequireOwner(), projectHandler.DisableSigning)
+	projects.Post("/:id/pause-ingestion", rbacMiddleware.RequireOwner(), projectHandler.PauseIngestion)
+	projects.Post("/:id/resume-ingestion", rbacMiddleware.RequireOwner(), projectHandler.ResumeIngestion)
+	projects.Post("/:id/pin", rbacMiddleware.RequireProjectAccess(), projectHandler.PinProject)
+	projects.Delete("/:id/pin", rbacMiddleware.RequireProjectAccess(), projectHandler.UnpinProject)
+	projects.Get("/:id/keys/usage", rbacMiddleware.RequireOwner(), apiKeyUsageHandler.GetKeyUsage)
+	projects.Get("/:id/sources", rbacMiddleware.RequireProjectAccess(), logHandler.ListProjectSources)
+	projects.Get("/:id/metadata-keys", rbacMiddleware.RequireProjectAccess(), logHandler.ListProjectMetadataKeys)
+	projects.Get("/:id/archive", rbacMiddleware.RequireOwner(), archiveHandler.DownloadArchive)
 
 	// Project members
 	projects.Get("/:id/members", rbacMiddleware.RequireProjectAccess(), memberHandler.ListMembers)
@@ -239,19 +456,37 @@ func main() {
 
 	// Project channels
 	projects.Get("/:id/channels", rbacMiddleware.RequireProjectAccess(), channelHandler.ListChannels)
+	projects.Get("/:id/channels/health", rbacMiddleware.RequireProjectAccess(), channelHandler.ChannelHealth)
 	projects.Post("/:id/channels", rbacMiddleware.RequireOwnerOrMember(), channelHandler.CreateChannel)
 
+	// Project alert rules
+	projects.Get("/:id/alert-rules", rbacMiddleware.RequireOwnerOrMember(), alertRuleHandler.ListAlertRules)
+	projects.Post("/:id/alert-rules", rbacMiddleware.RequireOwnerOrMember(), alertRuleHandler.CreateAlertRule)
+	projects.Get("/:id/alert-rules/:ruleId", rbacMiddleware.RequireOwnerOrMember(), alertRuleHandler.GetAlertRule)
+	projects.Put("/:id/alert-rules/:ruleId", rbacMiddleware.RequireOwnerOrMember(), alertRuleHandler.UpdateAlertRule)
+	projects.Delete("/:id/alert-rules/:ruleId", rbacMiddleware.RequireOwnerOrMember(), alertRuleHandler.DeleteAlertRule)
+
 	// Channels
 	channels := admin.Group("/channels")
+	channels.Post("/validate", channelHandler.ValidateChannel)
 	channels.Get("/:id", channelHandler.GetChannel)
 	channels.Put("/:id", channelHandler.UpdateChannel)
 	channels.Delete("/:id", channelHandler.DeleteChannel)
 	channels.Post("/:id/test", channelHandler.TestChannel)
+	channels.Get("/:id/test/:jobId", channelHandler.GetTestStatus)
+	channels.Get("/:id/failures", notificationFailureHandler.ListFailures)
+	channels.Post("/:id/failures/:failureId/replay", notificationFailureHandler.ReplayFailure)
 
 	// Logs
 	logs := admin.Group("/logs")
 	logs.Get("", logHandler.ListLogs)
+	logs.Get("/search", authMiddleware.RequireAdmin(), logHandler.SearchLogs)
+	logs.Get("/recent-errors", logHandler.ListRecentErrors)
+	logs.Get("/count", logHandler.CountLogs)
+	logs.Post("/ack", logHandler.AckLogs)
 	logs.Get("/:id", logHandler.GetLog)
+	logs.Post("/:id/notify", logHandler.ResendNotifications)
+	admin.Get("/sources", logHandler.ListSources)
 
 	// Stats
 	stats := admin.Group("/stats")
@@ -263,6 +498,18 @@ func main() {
 	telegram.Post("/chats", telegramHandler.GetRecentChats)
 	telegram.Post("/test", telegramHandler.TestBotToken)
 
+	// System maintenance (admin only)
+	system := admin.Group("/system", authMiddleware.RequireAdmin())
+	system.Get("/migrations", systemHandler.ListMigrations)
+	system.Get("/ingestion", systemHandler.IngestionStatus)
+	system.Get("/redis", systemHandler.RedisStatus)
+	system.Post("/vacuum", systemHandler.Vacuum)
+	system.Get("/db-stats", systemHandler.DBStats)
+	system.Post("/maintenance", systemHandler.SetMaintenanceModeState)
+
+	// Audit trail (admin only)
+	admin.Get("/audit", authMiddleware.RequireAdmin(), auditHandler.ListAuditLogs)
+
 	// MCP routes (admin only)
 	mcpManagement := admin.Group("/mcp", authMiddleware.RequireAdmin())
 	mcpManagement.Get("/status", mcpSettingsHandler.GetMCPStatus)
@@ -289,7 +536,7 @@ func main() {
 	push := api.Group("/push")
 	push.Get("/vapid-key", pushHandler.GetVAPIDPublicKey) // Public - get VAPID key
 	pushProtected := push.Group("", authMiddleware.RequireAuth())
-	pushProtected.Post("/subscribe", pushHandler.Subscribe)
+	pushProtected.Post("/subscribe", authMiddleware.BlockImpersonation(), pushHandler.Subscribe)
 	pushProtected.Post("/unsubscribe", pushHandler.Unsubscribe)
 	pushProtected.Get("/subscriptions", pushHandler.ListSubscriptions)
 	pushProtected.Post("/test", pushHandler.TestNotification)
@@ -317,6 +564,35 @@ func main() {
 		})
 	}
 
+	// Reload runtime-adjustable settings on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		for range hup {
+			result, err := cfgHolder.Reload(configPath)
+			if err != nil {
+				log.Printf("Config reload failed, keeping current settings: %v", err)
+				continue
+			}
+
+			if rateLimitMiddleware != nil {
+				reloaded := cfgHolder.Get()
+				rateLimitMiddleware.SetLimit(reloaded.RateLimit.API.RequestsPerMinute, reloaded.GetAPIRateBurst())
+			}
+
+			if len(result.Changed) == 0 {
+				log.Println("Config reloaded: no runtime settings changed")
+			}
+			for _, change := range result.Changed {
+				log.Printf("Config reloaded: %s", change)
+			}
+			if len(result.RestartRequired) > 0 {
+				log.Printf("Config reload ignored changes to %v; restart to apply them", result.RestartRequired)
+			}
+		}
+	}()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -330,14 +606,76 @@ func main() {
 			notificationConsumer.Stop()
 		}
 
+		// Stop alert evaluator
+		if alertEvaluator != nil {
+			alertEvaluator.Stop()
+		}
+		if emailDigestScheduler != nil {
+			emailDigestScheduler.Stop()
+		}
+		dbMaintenance.Stop()
+		if redisHealthChecker != nil {
+			redisHealthChecker.Stop()
+		}
+
+		// Cancel channel requests still waiting on a slow remote
+		notifier.Stop()
+		projectWebhook.Stop()
+
 		app.Shutdown()
 	}()
 
 	// Start server
 	addr := fmt.Sprintf("0.0.0.0:%d", cfg.Server.Port)
-	log.Printf("Starting server on %s (env: %s)", addr, cfg.Server.Env)
-	if err := app.Listen(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if cfg.Server.TLS.Enabled {
+		// app.ListenTLS loads the certificate once; a listener with
+		// GetCertificate picks up renewals
+		certs, err := utils.NewCertReloader(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+		log.Printf("Starting server on %s with TLS (env: %s)", addr, cfg.Server.Env)
+		if err := app.Listener(tls.NewListener(ln, certs.TLSConfig())); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	} else {
+		log.Printf("Starting server on %s (env: %s)", addr, cfg.Server.Env)
+		if err := app.Listen(addr); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}
+
+	// Listen returns once in-flight requests have drained, so nothing else
+	// can be queued; write what is left before the database is closed
+	if logBuffer != nil {
+		logBuffer.Stop()
+	}
+
+	// Let broadcasts and notification sends for logs already accepted finish
+	// before Redis and the database are closed
+	if remaining := logHandler.Drain(cfg.GetShutdownTimeout()); remaining > 0 {
+		log.Printf("Shutdown timed out with %d log broadcasts or notifications still in flight", remaining)
+	}
+	if remaining := apiKeyUsageTracker.Drain(cfg.GetShutdownTimeout()); remaining > 0 {
+		log.Printf("Shutdown timed out with %d API key usage records unwritten", remaining)
+	}
+}
+
+// runCommand executes a CLI maintenance subcommand
+func runCommand(db *database.DB, registry []database.Migration, command string) error {
+	switch command {
+	case "migrate":
+		return db.MigrateWithRegistry(registry)
+	case "migrate:rollback":
+		return db.RollbackWithRegistry(registry)
+	case "migrate:status":
+		return db.MigrationStatusWithRegistry(registry)
+	default:
+		return fmt.Errorf("unknown command %q (available: migrate, migrate:rollback, migrate:status)", command)
 	}
 }
 
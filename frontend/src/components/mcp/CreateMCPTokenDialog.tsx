@@ -1,5 +1,5 @@
 import { useState, useEffect } from 'react';
-import { api, type Project } from '@/lib/api';
+import { api, type MCPTokenScope, type Project } from '@/lib/api';
 import { Button } from '@/components/ui/button';
 import {
   Dialog,
@@ -48,6 +48,7 @@ export function CreateMCPTokenDialog({
   const [name, setName] = useState('');
   const [projectAccess, setProjectAccess] = useState<'all' | 'specific'>('all');
   const [selectedProjects, setSelectedProjects] = useState<string[]>([]);
+  const [scope, setScope] = useState<MCPTokenScope>('read');
   const [expiresIn, setExpiresIn] = useState<string>('never');
   const [isActive, setIsActive] = useState(true);
   const [projects, setProjects] = useState<Project[]>([]);
@@ -64,6 +65,7 @@ export function CreateMCPTokenDialog({
       setName('');
       setProjectAccess('all');
       setSelectedProjects([]);
+      setScope('read');
       setExpiresIn('never');
       setIsActive(true);
       setCreatedToken(null);
@@ -109,6 +111,7 @@ export function CreateMCPTokenDialog({
       const response = await api.createMCPToken({
         name: name.trim(),
         granted_projects: projectAccess === 'all' ? ['*'] : selectedProjects,
+        scope,
         expires_in_days: expiresIn === 'never' ? null : parseInt(expiresIn),
       });
 
@@ -272,6 +275,19 @@ export function CreateMCPTokenDialog({
             </div>
           )}
 
+          <div className="space-y-2">
+            <Label htmlFor="scope">Scope</Label>
+            <Select value={scope} onValueChange={(value) => setScope(value as MCPTokenScope)}>
+              <SelectTrigger id="scope">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="read">Read only</SelectItem>
+                <SelectItem value="write">Read and write (can acknowledge logs)</SelectItem>
+              </SelectContent>
+            </Select>
+          </div>
+
           <div className="space-y-2">
             <Label htmlFor="expires-in">Token Expiration</Label>
             <Select value={expiresIn} onValueChange={setExpiresIn}>
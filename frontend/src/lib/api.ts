@@ -87,6 +87,10 @@ class ApiClient {
   }
 
   async logout() {
+    if (this.token) {
+      // Best effort: revoke the token server-side, but always sign out locally
+      await this.request('/auth/logout', { method: 'POST' }, true).catch(() => {});
+    }
     this.setToken(null);
   }
 
@@ -135,7 +139,7 @@ class ApiClient {
 
   // Users (admin routes)
   async getUsers(): Promise<User[]> {
-    const result = await this.request<{ users: User[] }>('/admin/users');
+    const result = await this.request<{ users: User[] }>('/admin/users?limit=200');
     return result.users ?? [];
   }
 
@@ -157,6 +161,10 @@ class ApiClient {
     await this.request(`/admin/users/${id}`, { method: 'DELETE' });
   }
 
+  async revokeUserSessions(id: string): Promise<void> {
+    await this.request(`/admin/users/${id}/revoke-sessions`, { method: 'POST' });
+  }
+
   // Projects (admin routes)
   async getProjects(): Promise<Project[]> {
     const result = await this.request<{ projects: Project[] }>('/admin/projects');
@@ -386,6 +394,7 @@ export interface User {
   role: 'ADMIN' | 'USER';
   is_active: boolean;
   two_factor_enabled: boolean;
+  last_login_at?: string | null;
   created_at: string;
   updated_at: string;
 }
@@ -459,12 +468,15 @@ export interface UpdateProjectRequest {
   icon_value?: string;
 }
 
+// Non-owners receive only name, role and a masked identifier.
 export interface ProjectMember {
-  id: string;
-  user_id: string;
-  project_id: string;
+  id?: string;
+  user_id?: string;
+  project_id?: string;
   role: string;
-  created_at: string;
+  created_at?: string;
+  name?: string;
+  identifier?: string;
   user?: {
     id: string;
     email: string;
@@ -505,7 +517,7 @@ export interface LogsResponse {
 export interface Channel {
   id: string;
   project_id: string;
-  type: 'PUSH' | 'TELEGRAM' | 'DISCORD';
+  type: 'PUSH' | 'TELEGRAM' | 'DISCORD' | 'APPRISE' | 'TEAMS' | 'EMAIL' | 'PAGERDUTY';
   name: string;
   config: Record<string, unknown>;
   min_level: 'DEBUG' | 'INFO' | 'WARN' | 'ERROR' | 'CRITICAL';
@@ -587,6 +599,7 @@ export interface MCPToken {
   name: string;
   token_prefix: string;
   granted_projects: string; // "*" or JSON array of project IDs
+  scope: MCPTokenScope;
   expires_at?: string;
   is_active: boolean;
   created_by: string;
@@ -595,9 +608,12 @@ export interface MCPToken {
   updated_at: string;
 }
 
+export type MCPTokenScope = 'read' | 'write';
+
 export interface CreateMCPTokenRequest {
   name: string;
   granted_projects: string[]; // Array of project IDs or ["*"]
+  scope?: MCPTokenScope; // Defaults to read
   expires_in_days?: number | null; // null or undefined for permanent
 }
 
@@ -608,6 +624,7 @@ export interface CreateMCPTokenResponse {
 export interface UpdateMCPTokenRequest {
   name?: string;
   granted_projects?: string[]; // Array of project IDs or ["*"]
+  scope?: MCPTokenScope;
   expires_in_days?: number | null; // null or undefined for permanent
   is_active?: boolean;
 }
@@ -693,17 +693,17 @@ export function ProjectDetailPage() {
                   </TableHeader>
                   <TableBody>
                     {members.map((member) => (
-                      <TableRow key={member.user_id}>
+                      <TableRow key={member.user_id ?? member.identifier}>
                         <TableCell>
                           <div>
-                            <p className="font-medium">{member.user?.name || 'Unknown'}</p>
-                            <p className="text-sm text-muted-foreground">{member.user?.email || ''}</p>
+                            <p className="font-medium">{member.user?.name || member.name || 'Unknown'}</p>
+                            <p className="text-sm text-muted-foreground">{member.user?.email || member.identifier || ''}</p>
                           </div>
                         </TableCell>
                         <TableCell>
                           <Select
                             value={member.role}
-                            onValueChange={(value) => handleUpdateMemberRole(member.user_id, value)}
+                            onValueChange={(value) => member.user_id && handleUpdateMemberRole(member.user_id, value)}
                           >
                             <SelectTrigger className="w-32">
                               <SelectValue />
@@ -716,14 +716,14 @@ export function ProjectDetailPage() {
                           </Select>
                         </TableCell>
                         <TableCell className="text-muted-foreground">
-                          {new Date(member.created_at).toLocaleDateString()}
+                          {member.created_at ? new Date(member.created_at).toLocaleDateString() : '-'}
                         </TableCell>
                         <TableCell>
-                          {member.role !== 'OWNER' && member.user_id !== currentUser?.id && (
+                          {member.user_id && member.role !== 'OWNER' && member.user_id !== currentUser?.id && (
                             <Button
                               variant="ghost"
                               size="icon"
-                              onClick={() => openRemoveMemberDialog(member.user_id, member.user?.name || 'this user')}
+                              onClick={() => openRemoveMemberDialog(member.user_id!, member.user?.name || 'this user')}
                             >
                               <Trash2 className="h-4 w-4 text-destructive" />
                             </Button>
@@ -968,7 +968,7 @@ export function ProjectDetailPage() {
                 <div>
                   <p className="font-medium">Delete this project</p>
                   <p className="text-sm text-muted-foreground">
-                    This will permanently delete the project and all its logs
+                    This hides the project and its logs and disables its API key
                   </p>
                 </div>
                 <Button variant="destructive" onClick={() => setDeleteProjectDialogOpen(true)}>
@@ -1188,7 +1188,7 @@ export function ProjectDetailPage() {
         open={deleteProjectDialogOpen}
         onOpenChange={setDeleteProjectDialogOpen}
         title="Delete Project"
-        description={`Delete project "${project?.name}"? This will delete all associated logs.`}
+        description={`Delete project "${project?.name}"? Its logs are kept but hidden until the project is restored.`}
         confirmText="Delete"
         variant="destructive"
         loading={confirmLoading}
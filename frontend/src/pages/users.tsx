@@ -1,5 +1,5 @@
 import { useEffect, useState } from 'react';
-import { Plus, MoreVertical, Trash2, Edit, Shield } from 'lucide-react';
+import { Plus, MoreVertical, Trash2, Edit, Shield, LogOut } from 'lucide-react';
 import { api, type User } from '@/lib/api';
 import { useAuth } from '@/contexts/auth-context';
 import { Button } from '@/components/ui/button';
@@ -121,6 +121,19 @@ export function UsersPage() {
     setDeleteDialogOpen(true);
   };
 
+  const handleRevokeSessions = async (user: User) => {
+    try {
+      await api.revokeUserSessions(user.id);
+      toast({ title: `Signed ${user.username} out of all sessions` });
+    } catch (err) {
+      toast({
+        title: 'Failed to revoke sessions',
+        description: err instanceof Error ? err.message : 'Unknown error',
+        variant: 'destructive',
+      });
+    }
+  };
+
   const handleDeleteUser = async () => {
     if (!userToDelete) return;
     setDeleteLoading(true);
@@ -171,6 +184,7 @@ export function UsersPage() {
                 <TableHead>Name</TableHead>
                 <TableHead>Role</TableHead>
                 <TableHead>Created</TableHead>
+                <TableHead>Last Login</TableHead>
                 <TableHead className="w-12"></TableHead>
               </TableRow>
             </TableHeader>
@@ -193,6 +207,9 @@ export function UsersPage() {
                   <TableCell className="text-muted-foreground">
                     {new Date(user.created_at).toLocaleDateString()}
                   </TableCell>
+                  <TableCell className="text-muted-foreground">
+                    {user.last_login_at ? new Date(user.last_login_at).toLocaleString() : 'Never'}
+                  </TableCell>
                   <TableCell>
                     <DropdownMenu>
                       <DropdownMenuTrigger asChild>
@@ -210,6 +227,10 @@ export function UsersPage() {
                           <Edit className="mr-2 h-4 w-4" />
                           Edit
                         </DropdownMenuItem>
+                        <DropdownMenuItem onClick={() => handleRevokeSessions(user)}>
+                          <LogOut className="mr-2 h-4 w-4" />
+                          Revoke Sessions
+                        </DropdownMenuItem>
                         <DropdownMenuSeparator />
                         <DropdownMenuItem
                           className="text-destructive"
@@ -328,7 +349,7 @@ export function UsersPage() {
         open={deleteDialogOpen}
         onOpenChange={setDeleteDialogOpen}
         title="Delete User"
-        description={`Delete user "${userToDelete?.name}"? This action cannot be undone.`}
+        description={`Delete user "${userToDelete?.name}"? They will no longer be able to sign in.`}
         confirmText="Delete"
         variant="destructive"
         loading={deleteLoading}
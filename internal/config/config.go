@@ -8,35 +8,98 @@ import (
 )
 
 type Config struct {
-	Server    ServerConfig    `yaml:"server"`
-	Database  DatabaseConfig  `yaml:"database"`
-	Redis     RedisConfig     `yaml:"redis"`
-	JWT       JWTConfig       `yaml:"jwt"`
-	VAPID     VAPIDConfig     `yaml:"vapid"`
-	Telegram  TelegramConfig  `yaml:"telegram"`
-	Admin     AdminConfig     `yaml:"admin"`
-	Retention RetentionConfig `yaml:"retention"`
-	RateLimit RateLimitConfig `yaml:"rate_limit"`
-	WebSocket WebSocketConfig `yaml:"websocket"`
+	Server        ServerConfig        `yaml:"server"`
+	Database      DatabaseConfig      `yaml:"database"`
+	Redis         RedisConfig         `yaml:"redis"`
+	JWT           JWTConfig           `yaml:"jwt"`
+	Auth          AuthConfig          `yaml:"auth"`
+	VAPID         VAPIDConfig         `yaml:"vapid"`
+	Telegram      TelegramConfig      `yaml:"telegram"`
+	Apprise       AppriseConfig       `yaml:"apprise"`
+	PagerDuty     PagerDutyConfig     `yaml:"pagerduty"`
+	SMTP          SMTPConfig          `yaml:"smtp"`
+	Admin         AdminConfig         `yaml:"admin"`
+	Password      PasswordConfig      `yaml:"password_policy"`
+	Security      SecurityConfig      `yaml:"security"`
+	Retention     RetentionConfig     `yaml:"retention"`
+	RateLimit     RateLimitConfig     `yaml:"rate_limit"`
+	WebSocket     WebSocketConfig     `yaml:"websocket"`
+	Alerts        AlertsConfig        `yaml:"alerts"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Ingestion     IngestionConfig     `yaml:"ingestion"`
+	Stats         StatsConfig         `yaml:"stats"`
+	Query         QueryConfig         `yaml:"query"`
+	Enrichment    EnrichmentConfig    `yaml:"enrichment"`
 }
 
 type ServerConfig struct {
 	Port         int    `yaml:"port"`
 	Env          string `yaml:"env"`
 	AllowOrigins string `yaml:"allow_origins"` // Comma-separated CORS origins
+	// How long shutdown waits for background broadcasts and notification
+	// sends started by ingestion to finish
+	ShutdownTimeout string `yaml:"shutdown_timeout"`
+	// Largest request body accepted on any route, in bytes. Per-project
+	// ingestion limits cannot go above it.
+	BodyLimit int `yaml:"body_limit"`
+	// Operational log format: text (default) or json, one object per line
+	LogFormat string    `yaml:"log_format"`
+	TLS       TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig serves HTTPS directly, for deployments without a reverse proxy.
+// The files are watched, so a renewed certificate is picked up without a
+// restart.
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"cert_file"` // PEM certificate, with any intermediates after it
+	KeyFile  string `yaml:"key_file"`  // PEM private key
 }
 
 type DatabaseConfig struct {
-	Path string `yaml:"path"`
+	Driver        string            `yaml:"driver"`          // sqlite (default) or postgres
+	Path          string            `yaml:"path"`            // SQLite database file
+	URL           string            `yaml:"url"`             // Connection URL for non-SQLite drivers
+	WAL           bool              `yaml:"wal"`             // SQLite write-ahead logging
+	BusyTimeoutMS int               `yaml:"busy_timeout_ms"` // How long SQLite waits on a locked database
+	Synchronous   string            `yaml:"synchronous"`     // SQLite synchronous pragma: OFF, NORMAL, FULL or EXTRA
+	Maintenance   MaintenanceConfig `yaml:"maintenance"`
+}
+
+// MaintenanceConfig schedules compacting the database (VACUUM and ANALYZE)
+type MaintenanceConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Schedule string `yaml:"schedule"` // Cron expression in server local time
 }
 
 type RedisConfig struct {
-	URL string `yaml:"url"`
+	URL                 string `yaml:"url"`
+	HealthCheckInterval string `yaml:"health_check_interval"` // How often Redis is pinged after startup
 }
 
 type JWTConfig struct {
-	Secret string `yaml:"secret"`
-	Expiry string `yaml:"expiry"`
+	Secret   string `yaml:"secret"`
+	Expiry   string `yaml:"expiry"`
+	Issuer   string `yaml:"issuer"`   // iss claim; empty means not set or checked
+	Audience string `yaml:"audience"` // aud claim; empty means not set or checked
+	// Lifetime of the token an admin gets when impersonating a user
+	ImpersonationExpiry string `yaml:"impersonation_expiry"`
+}
+
+// AuthConfig tunes sign-in checks other than the password
+type AuthConfig struct {
+	TOTP TOTPConfig `yaml:"totp"`
+}
+
+type TOTPConfig struct {
+	// 30-second steps either side of the server's clock in which a code is
+	// still accepted, for authenticators whose clock drifts; 0 accepts only
+	// the current step
+	Skew int `yaml:"skew"`
+	// Codes a user may try per attempt_window, counting sign-in and the 2FA
+	// settings together, before being locked out until it ends; 0 for no limit
+	MaxAttempts   int    `yaml:"max_attempts"`
+	AttemptWindow string `yaml:"attempt_window"`
 }
 
 type VAPIDConfig struct {
@@ -50,17 +113,54 @@ type TelegramConfig struct {
 	Enabled  bool   `yaml:"enabled"`
 }
 
+// AppriseConfig points APPRISE channels at an Apprise API server
+// (https://github.com/caronc/apprise-api). Channels can override it.
+type AppriseConfig struct {
+	ServerURL string `yaml:"server_url"`
+}
+
+// PagerDutyConfig sets where PAGERDUTY channels send events. EU accounts use
+// https://events.eu.pagerduty.com/v2/enqueue.
+type PagerDutyConfig struct {
+	EventsURL string `yaml:"events_url"`
+}
+
+// SMTPConfig is the mail server used for EMAIL channels. Email is disabled
+// while Host is empty.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
 type AdminConfig struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 }
 
+// PasswordConfig is the complexity policy for passwords set through the API
+type PasswordConfig struct {
+	MinLength        int  `yaml:"min_length"`
+	RequireMixedCase bool `yaml:"require_mixed_case"` // At least one upper and one lower case letter
+	RequireDigit     bool `yaml:"require_digit"`
+	RequireSymbol    bool `yaml:"require_symbol"` // Punctuation or symbol character
+}
+
+type SecurityConfig struct {
+	// Answer non-admins with 404 rather than 403 when they may not see a
+	// project, log or channel, so its ID can't be confirmed to exist
+	ObscureNotFound bool `yaml:"obscure_not_found"`
+}
+
 type RetentionConfig struct {
 	Enabled             bool                      `yaml:"enabled"`
 	Default             RetentionPolicy           `yaml:"default"`
 	Levels              map[string]RetentionPolicy `yaml:"levels"`
 	Cleanup             CleanupConfig             `yaml:"cleanup"`
 	NotificationHistory RetentionPolicy           `yaml:"notification_history"`
+	Floor               RetentionFloor            `yaml:"floor"`
 }
 
 type RetentionPolicy struct {
@@ -68,6 +168,14 @@ type RetentionPolicy struct {
 	MaxCount int    `yaml:"max_count"`
 }
 
+// RetentionFloor is the least a log retention policy may keep, so a typo
+// like max_age: 1s can't delete nearly everything. A project can go below it
+// only by forcing the change.
+type RetentionFloor struct {
+	MinAge   string `yaml:"min_age"`   // Shortest allowed max_age; empty means no floor
+	MinCount int    `yaml:"min_count"` // Smallest allowed max_count other than 0 (no limit)
+}
+
 type CleanupConfig struct {
 	Enabled   bool   `yaml:"enabled"`
 	Schedule  string `yaml:"schedule"`
@@ -80,13 +188,17 @@ type RateLimitConfig struct {
 }
 
 type APIRateLimit struct {
-	RequestsPerMinute int `yaml:"requests_per_minute"`
+	RequestsPerMinute int `yaml:"requests_per_minute"` // Sustained rate per project
+	Burst             int `yaml:"burst"`               // Requests a project can send at once after a quiet spell; 0 matches requests_per_minute
 }
 
 type ChannelRateLimit struct {
-	Telegram ChannelLimit `yaml:"telegram"`
-	Discord  ChannelLimit `yaml:"discord"`
-	Push     ChannelLimit `yaml:"push"`
+	Telegram  ChannelLimit `yaml:"telegram"`
+	Discord   ChannelLimit `yaml:"discord"`
+	Push      ChannelLimit `yaml:"push"`
+	Apprise   ChannelLimit `yaml:"apprise"`
+	Teams     ChannelLimit `yaml:"teams"`
+	PagerDuty ChannelLimit `yaml:"pagerduty"`
 }
 
 type ChannelLimit struct {
@@ -100,6 +212,96 @@ type WebSocketConfig struct {
 	MaxMessageSize  int    `yaml:"max_message_size"`
 	ReadBufferSize  int    `yaml:"read_buffer_size"`
 	WriteBufferSize int    `yaml:"write_buffer_size"`
+
+	// Broadcasts a client can have waiting before it counts as slow
+	SendBuffer int `yaml:"send_buffer"`
+	// What happens to a slow client's messages: drop skips them, disconnect
+	// closes the connection so the client can reconnect
+	SlowClientPolicy string `yaml:"slow_client_policy"`
+}
+
+type AlertsConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Interval string `yaml:"interval"` // How often alert rules are evaluated
+}
+
+type NotificationsConfig struct {
+	MaxAttempts  int    `yaml:"max_attempts"`  // Delivery attempts before a job is dead-lettered
+	RetryBackoff string `yaml:"retry_backoff"` // Base delay, doubled after each failed attempt
+	HTTPTimeout  string `yaml:"http_timeout"`  // Longest a single request to a notification service may take
+}
+
+type IngestionConfig struct {
+	AsyncBuffer      AsyncBufferConfig `yaml:"async_buffer"`
+	Fanout           FanoutConfig      `yaml:"fanout"`
+	MaxBodyBytes     int               `yaml:"max_body_bytes"`     // Largest ingestion request body, unless the project raises it
+	MaxMessageBytes  int               `yaml:"max_message_bytes"`  // Longest accepted log message
+	MaxMetadataBytes int               `yaml:"max_metadata_bytes"` // Largest accepted metadata, as JSON
+	MaxMetadataDepth int               `yaml:"max_metadata_depth"` // Deepest accepted metadata nesting
+	MaxMetadataKeys  int               `yaml:"max_metadata_keys"`  // Most top-level metadata keys per log
+	OversizePolicy   string            `yaml:"oversize_policy"`    // reject (400) or truncate
+	Redaction        RedactionConfig   `yaml:"redaction"`
+
+	// Longest string value anywhere in metadata; 0 for no limit
+	MaxMetadataValueBytes int `yaml:"max_metadata_value_bytes"`
+	// reject, truncate or drop (the key); empty follows oversize_policy
+	MetadataValuePolicy string `yaml:"metadata_value_policy"`
+	// Messages longer than this many characters are cut to it and marked in
+	// metadata; 0 for no truncation
+	MessageTruncateLength int `yaml:"message_truncate_length"`
+
+	Timestamp TimestampWindowConfig `yaml:"timestamp"`
+}
+
+// TimestampWindowConfig bounds how far a log's client-sent timestamp may be
+// from the server's clock. Empty bounds accept any timestamp.
+type TimestampWindowConfig struct {
+	MaxFuture string `yaml:"max_future"` // e.g. 1h
+	MaxPast   string `yaml:"max_past"`   // e.g. 30d
+	Policy    string `yaml:"policy"`     // reject (400) or clamp to the bound
+}
+
+type AsyncBufferConfig struct {
+	Enabled       bool   `yaml:"enabled"`        // Queue logs in memory and write them in batches
+	FlushSize     int    `yaml:"flush_size"`     // Pending logs that trigger an immediate flush
+	FlushInterval string `yaml:"flush_interval"` // Longest time a log waits before it is written
+	MaxPending    int    `yaml:"max_pending"`    // Logs held while the database is failing; beyond this ingestion answers 503
+}
+
+// FanoutConfig sizes the worker pool that broadcasts stored logs and sends
+// their notifications after the response
+type FanoutConfig struct {
+	Workers   int `yaml:"workers"`    // Most goroutines running fan-out at once
+	QueueSize int `yaml:"queue_size"` // Waiting tasks before new ones are dropped
+}
+
+// RedactionConfig lists the rules that scrub messages and string metadata
+// values before they are stored. Projects may replace them with their own.
+type RedactionConfig struct {
+	Detectors []string `yaml:"detectors"` // Built-in detectors: email, credit_card, ssn
+	Patterns  []string `yaml:"patterns"`  // Custom regular expressions
+}
+
+type StatsConfig struct {
+	CacheTTL string `yaml:"cache_ttl"` // How long dashboard overview stats are reused
+	Timezone string `yaml:"timezone"`  // IANA zone whose midnight starts "today" in stats, e.g. Asia/Jakarta
+}
+
+// QueryConfig bounds how much log listing endpoints read per request
+type QueryConfig struct {
+	DefaultRange string `yaml:"default_range"` // Lookback used when a query gives no time range, e.g. 7d; 0 turns it off
+	MaxLimit     int    `yaml:"max_limit"`     // Largest page size honored; bigger limits are clamped
+}
+
+type EnrichmentConfig struct {
+	GeoIP GeoIPConfig `yaml:"geoip"`
+}
+
+type GeoIPConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	DatabasePath    string `yaml:"database_path"`     // MaxMind Country or City .mmdb file
+	ASNDatabasePath string `yaml:"asn_database_path"` // Optional MaxMind ASN .mmdb file
+	IPField         string `yaml:"ip_field"`          // Metadata field holding the client IP, e.g. request.ip
 }
 
 func (c *Config) GetJWTExpiry() time.Duration {
@@ -110,6 +312,24 @@ func (c *Config) GetJWTExpiry() time.Duration {
 	return d
 }
 
+// GetTOTPAttemptWindow returns the window auth.totp.max_attempts applies to
+func (c *Config) GetTOTPAttemptWindow() time.Duration {
+	d, err := time.ParseDuration(c.Auth.TOTP.AttemptWindow)
+	if err != nil || d <= 0 {
+		return 15 * time.Minute
+	}
+	return d
+}
+
+// GetImpersonationExpiry returns how long an impersonation token lasts
+func (c *Config) GetImpersonationExpiry() time.Duration {
+	d, err := time.ParseDuration(c.JWT.ImpersonationExpiry)
+	if err != nil || d <= 0 {
+		return 15 * time.Minute
+	}
+	return d
+}
+
 func (c *Config) GetWebSocketPingInterval() time.Duration {
 	d, err := time.ParseDuration(c.WebSocket.PingInterval)
 	if err != nil {
@@ -126,22 +346,327 @@ func (c *Config) GetWebSocketPongTimeout() time.Duration {
 	return d
 }
 
+func (c *Config) GetAlertsInterval() time.Duration {
+	d, err := time.ParseDuration(c.Alerts.Interval)
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+func (c *Config) GetNotificationHTTPTimeout() time.Duration {
+	d, err := time.ParseDuration(c.Notifications.HTTPTimeout)
+	if err != nil || d <= 0 {
+		return 10 * time.Second
+	}
+	return d
+}
+
+func (c *Config) GetNotificationRetryBackoff() time.Duration {
+	d, err := time.ParseDuration(c.Notifications.RetryBackoff)
+	if err != nil || d <= 0 {
+		return 5 * time.Second
+	}
+	return d
+}
+
+func (c *Config) GetNotificationMaxAttempts() int {
+	if c.Notifications.MaxAttempts <= 0 {
+		return 5
+	}
+	return c.Notifications.MaxAttempts
+}
+
+// GetChannelRateLimit returns the messages per minute allowed for a channel
+// type (e.g. "TELEGRAM"), or 0 when the type is not limited
+func (c *Config) GetChannelRateLimit(channelType string) int {
+	switch channelType {
+	case "TELEGRAM":
+		return c.RateLimit.Channels.Telegram.MessagesPerMinute
+	case "DISCORD":
+		return c.RateLimit.Channels.Discord.MessagesPerMinute
+	case "PUSH":
+		return c.RateLimit.Channels.Push.MessagesPerMinute
+	case "APPRISE":
+		return c.RateLimit.Channels.Apprise.MessagesPerMinute
+	case "TEAMS":
+		return c.RateLimit.Channels.Teams.MessagesPerMinute
+	case "PAGERDUTY":
+		return c.RateLimit.Channels.PagerDuty.MessagesPerMinute
+	}
+	return 0
+}
+
+// GetPagerDutyEventsURL returns the Events API v2 endpoint, defaulting to
+// PagerDuty's US service region
+func (c *Config) GetPagerDutyEventsURL() string {
+	if c.PagerDuty.EventsURL == "" {
+		return "https://events.pagerduty.com/v2/enqueue"
+	}
+	return c.PagerDuty.EventsURL
+}
+
+func (c *Config) GetIngestionFlushSize() int {
+	if c.Ingestion.AsyncBuffer.FlushSize <= 0 {
+		return 500
+	}
+	return c.Ingestion.AsyncBuffer.FlushSize
+}
+
+func (c *Config) GetIngestionMaxPending() int {
+	if c.Ingestion.AsyncBuffer.MaxPending <= 0 {
+		return 100000
+	}
+	return c.Ingestion.AsyncBuffer.MaxPending
+}
+
+func (c *Config) GetFanoutWorkers() int {
+	if c.Ingestion.Fanout.Workers <= 0 {
+		return 16
+	}
+	return c.Ingestion.Fanout.Workers
+}
+
+func (c *Config) GetFanoutQueueSize() int {
+	if c.Ingestion.Fanout.QueueSize <= 0 {
+		return 10000
+	}
+	return c.Ingestion.Fanout.QueueSize
+}
+
+func (c *Config) GetBodyLimit() int {
+	if c.Server.BodyLimit <= 0 {
+		return 4 * 1024 * 1024
+	}
+	return c.Server.BodyLimit
+}
+
+// GetLogFormat returns the server's operational log format, defaulting to text
+func (c *Config) GetLogFormat() string {
+	if c.Server.LogFormat == "" {
+		return "text"
+	}
+	return c.Server.LogFormat
+}
+
+func (c *Config) GetWebSocketSendBuffer() int {
+	if c.WebSocket.SendBuffer <= 0 {
+		return 256
+	}
+	return c.WebSocket.SendBuffer
+}
+
+func (c *Config) GetWebSocketSlowClientPolicy() string {
+	if c.WebSocket.SlowClientPolicy == "" {
+		return "drop"
+	}
+	return c.WebSocket.SlowClientPolicy
+}
+
+func (c *Config) GetRedisHealthCheckInterval() time.Duration {
+	d, err := time.ParseDuration(c.Redis.HealthCheckInterval)
+	if err != nil || d <= 0 {
+		return 5 * time.Second
+	}
+	return d
+}
+
+func (c *Config) GetShutdownTimeout() time.Duration {
+	d, err := time.ParseDuration(c.Server.ShutdownTimeout)
+	if err != nil || d <= 0 {
+		return 10 * time.Second
+	}
+	return d
+}
+
+func (c *Config) GetIngestionFlushInterval() time.Duration {
+	d, err := time.ParseDuration(c.Ingestion.AsyncBuffer.FlushInterval)
+	if err != nil || d <= 0 {
+		return time.Second
+	}
+	return d
+}
+
+// GetAPIRateBurst returns the default ingestion burst per project
+func (c *Config) GetAPIRateBurst() int {
+	if c.RateLimit.API.Burst <= 0 {
+		return c.RateLimit.API.RequestsPerMinute
+	}
+	return c.RateLimit.API.Burst
+}
+
+func (c *Config) GetStatsCacheTTL() time.Duration {
+	d, err := time.ParseDuration(c.Stats.CacheTTL)
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+// GetStatsLocation returns the zone used for "today" in stats, UTC if unset
+// or unknown
+func (c *Config) GetStatsLocation() *time.Location {
+	loc, err := time.LoadLocation(c.Stats.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// GetQueryDefaultRange returns the lookback applied to log queries without a
+// time range. Zero means queries are not bounded by default.
+func (c *Config) GetQueryDefaultRange() time.Duration {
+	d, err := ParseRetentionDuration(c.Query.DefaultRange)
+	if err != nil || d < 0 {
+		return 7 * 24 * time.Hour
+	}
+	return d
+}
+
+func (c *Config) GetPasswordMinLength() int {
+	if c.Password.MinLength <= 0 {
+		return 8
+	}
+	return c.Password.MinLength
+}
+
+func (c *Config) GetQueryMaxLimit() int {
+	if c.Query.MaxLimit <= 0 {
+		return 1000
+	}
+	return c.Query.MaxLimit
+}
+
+func (c *Config) GetIngestionMaxBodyBytes() int {
+	if c.Ingestion.MaxBodyBytes <= 0 {
+		return 1024 * 1024
+	}
+	return c.Ingestion.MaxBodyBytes
+}
+
+func (c *Config) GetIngestionMaxMessageBytes() int {
+	if c.Ingestion.MaxMessageBytes <= 0 {
+		return 64 * 1024
+	}
+	return c.Ingestion.MaxMessageBytes
+}
+
+func (c *Config) GetIngestionMaxMetadataBytes() int {
+	if c.Ingestion.MaxMetadataBytes <= 0 {
+		return 64 * 1024
+	}
+	return c.Ingestion.MaxMetadataBytes
+}
+
+func (c *Config) GetIngestionMaxMetadataDepth() int {
+	if c.Ingestion.MaxMetadataDepth <= 0 {
+		return 10
+	}
+	return c.Ingestion.MaxMetadataDepth
+}
+
+func (c *Config) GetIngestionMaxMetadataKeys() int {
+	if c.Ingestion.MaxMetadataKeys <= 0 {
+		return 100
+	}
+	return c.Ingestion.MaxMetadataKeys
+}
+
+// GetIngestionTimestampWindow returns how far in the future and past a log's
+// timestamp may be; zero leaves that side unbounded
+func (c *Config) GetIngestionTimestampWindow() (maxFuture, maxPast time.Duration) {
+	parse := func(s string) time.Duration {
+		d, err := ParseRetentionDuration(s)
+		if err != nil || d < 0 {
+			return 0
+		}
+		return d
+	}
+	return parse(c.Ingestion.Timestamp.MaxFuture), parse(c.Ingestion.Timestamp.MaxPast)
+}
+
+// IngestionClampsTimestamps reports whether timestamps outside the window are
+// moved to its edge rather than rejected
+func (c *Config) IngestionClampsTimestamps() bool {
+	return c.Ingestion.Timestamp.Policy != "reject"
+}
+
+func (c *Config) GetGeoIPField() string {
+	if c.Enrichment.GeoIP.IPField == "" {
+		return "ip"
+	}
+	return c.Enrichment.GeoIP.IPField
+}
+
+// GetIngestionMetadataValuePolicy returns what happens to a metadata value over
+// max_metadata_value_bytes, following oversize_policy unless set
+func (c *Config) GetIngestionMetadataValuePolicy() string {
+	switch {
+	case c.Ingestion.MetadataValuePolicy != "":
+		return c.Ingestion.MetadataValuePolicy
+	case c.IngestionTruncates():
+		return "truncate"
+	default:
+		return "reject"
+	}
+}
+
+// IngestionTruncates reports whether oversized logs are truncated rather than rejected
+func (c *Config) IngestionTruncates() bool {
+	return c.Ingestion.OversizePolicy == "truncate"
+}
+
+// GetDatabaseDriver returns the storage driver, defaulting to sqlite
+func (c *Config) GetDatabaseDriver() string {
+	if c.Database.Driver == "" {
+		return "sqlite"
+	}
+	return c.Database.Driver
+}
+
+// GetDatabaseDSN returns the file path for SQLite or the connection URL otherwise
+func (c *Config) GetDatabaseDSN() string {
+	if c.GetDatabaseDriver() == "sqlite" {
+		return c.Database.Path
+	}
+	return c.Database.URL
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         3000,
-			Env:          "development",
-			AllowOrigins: "*", // Allow all origins in dev, override for production
+			Port:            3000,
+			Env:             "development",
+			AllowOrigins:    "*", // Allow all origins in dev, override for production
+			ShutdownTimeout: "10s",
+			BodyLimit:       4 * 1024 * 1024,
 		},
 		Database: DatabaseConfig{
-			Path: "./data/central-logs.db",
+			Driver:        "sqlite",
+			Path:          "./data/central-logs.db",
+			WAL:           true,
+			BusyTimeoutMS: 5000,
+			Synchronous:   "NORMAL",
+			Maintenance: MaintenanceConfig{
+				Enabled:  true,
+				Schedule: "0 3 * * *", // After retention cleanup, while traffic is low
+			},
 		},
 		Redis: RedisConfig{
-			URL: "redis://localhost:6379",
+			URL:                 "redis://localhost:6379",
+			HealthCheckInterval: "5s",
 		},
 		JWT: JWTConfig{
-			Secret: "change-this-secret-key",
-			Expiry: "24h",
+			Secret:              "change-this-secret-key",
+			Expiry:              "24h",
+			ImpersonationExpiry: "15m",
+		},
+		Auth: AuthConfig{
+			TOTP: TOTPConfig{
+				Skew:          1,
+				MaxAttempts:   5,
+				AttemptWindow: "15m",
+			},
 		},
 		VAPID: VAPIDConfig{
 			Subject: "mailto:admin@example.com",
@@ -149,10 +674,16 @@ func DefaultConfig() *Config {
 		Telegram: TelegramConfig{
 			Enabled: false,
 		},
+		SMTP: SMTPConfig{
+			Port: 587,
+		},
 		Admin: AdminConfig{
 			Username: "admin",
 			Password: "changeme123",
 		},
+		Password: PasswordConfig{
+			MinLength: 8,
+		},
 		Retention: RetentionConfig{
 			Enabled: true,
 			Default: RetentionPolicy{
@@ -174,6 +705,10 @@ func DefaultConfig() *Config {
 			NotificationHistory: RetentionPolicy{
 				MaxAge: "7d",
 			},
+			Floor: RetentionFloor{
+				MinAge:   "1d",
+				MinCount: 100,
+			},
 		},
 		RateLimit: RateLimitConfig{
 			API: APIRateLimit{
@@ -183,15 +718,63 @@ func DefaultConfig() *Config {
 				Telegram: ChannelLimit{MessagesPerMinute: 20},
 				Discord:  ChannelLimit{MessagesPerMinute: 30},
 				Push:     ChannelLimit{MessagesPerMinute: 60},
+				Apprise:  ChannelLimit{MessagesPerMinute: 30},
+				Teams:    ChannelLimit{MessagesPerMinute: 30},
+				// PagerDuty throttles each routing key at 120 events per minute
+				PagerDuty: ChannelLimit{MessagesPerMinute: 60},
 			},
 		},
 		WebSocket: WebSocketConfig{
-			Enabled:         true,
-			PingInterval:    "30s",
-			PongTimeout:     "10s",
-			MaxMessageSize:  512,
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
+			Enabled:          true,
+			PingInterval:     "30s",
+			PongTimeout:      "10s",
+			MaxMessageSize:   512,
+			ReadBufferSize:   1024,
+			WriteBufferSize:  1024,
+			SendBuffer:       256,
+			SlowClientPolicy: "drop",
+		},
+		Alerts: AlertsConfig{
+			Enabled:  true,
+			Interval: "30s",
+		},
+		Notifications: NotificationsConfig{
+			MaxAttempts:  5,
+			RetryBackoff: "5s",
+			HTTPTimeout:  "10s",
+		},
+		Ingestion: IngestionConfig{
+			AsyncBuffer: AsyncBufferConfig{
+				Enabled:       false,
+				FlushSize:     500,
+				FlushInterval: "1s",
+				MaxPending:    100000,
+			},
+			Fanout: FanoutConfig{
+				Workers:   16,
+				QueueSize: 10000,
+			},
+			MaxBodyBytes:     1024 * 1024,
+			MaxMessageBytes:  64 * 1024,
+			MaxMetadataBytes: 64 * 1024,
+			MaxMetadataDepth: 10,
+			MaxMetadataKeys:  100,
+			OversizePolicy:   "reject",
+		},
+		Stats: StatsConfig{
+			CacheTTL: "30s",
+			Timezone: "UTC",
+		},
+		Query: QueryConfig{
+			DefaultRange: "7d",
+			MaxLimit:     1000,
+		},
+		Enrichment: EnrichmentConfig{
+			GeoIP: GeoIPConfig{
+				Enabled:      false,
+				DatabasePath: "./data/GeoLite2-City.mmdb",
+				IPField:      "ip",
+			},
 		},
 	}
 }
@@ -216,7 +799,9 @@ func Load(path string) (*Config, error) {
 	}
 
 	// Override with environment variables (supports both SERVER_PORT and CL_SERVER_PORT formats)
-	cfg.loadFromEnvNew()
+	if err := cfg.loadFromEnvNew(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
@@ -237,6 +822,6 @@ func Save(path string, cfg *Config) error {
 
 // loadFromEnv is deprecated, use loadFromEnvNew instead
 // Kept for backward compatibility
-func (c *Config) loadFromEnv() {
-	c.loadFromEnvNew()
+func (c *Config) loadFromEnv() error {
+	return c.loadFromEnvNew()
 }
@@ -20,16 +20,33 @@ var envMappings = []EnvMapping{
 	// Server Config
 	{"SERVER_PORT", "server.port", "int"},
 	{"SERVER_ENV", "server.env", "string"},
+	{"SERVER_SHUTDOWN_TIMEOUT", "server.shutdown_timeout", "string"},
+	{"SERVER_BODY_LIMIT", "server.body_limit", "int"},
+	{"SERVER_LOG_FORMAT", "server.log_format", "string"},
+	{"SERVER_TLS_ENABLED", "server.tls.enabled", "bool"},
+	{"SERVER_TLS_CERT_FILE", "server.tls.cert_file", "string"},
+	{"SERVER_TLS_KEY_FILE", "server.tls.key_file", "string"},
 
 	// Database Config
+	{"DATABASE_DRIVER", "database.driver", "string"},
 	{"DATABASE_PATH", "database.path", "string"},
+	{"DATABASE_URL", "database.url", "string"},
+	{"DATABASE_WAL", "database.wal", "bool"},
+	{"DATABASE_BUSY_TIMEOUT_MS", "database.busy_timeout_ms", "int"},
+	{"DATABASE_SYNCHRONOUS", "database.synchronous", "string"},
+	{"DATABASE_MAINTENANCE_ENABLED", "database.maintenance.enabled", "bool"},
+	{"DATABASE_MAINTENANCE_SCHEDULE", "database.maintenance.schedule", "string"},
 
 	// Redis Config
 	{"REDIS_URL", "redis.url", "string"},
+	{"REDIS_HEALTH_CHECK_INTERVAL", "redis.health_check_interval", "string"},
 
 	// JWT Config
 	{"JWT_SECRET", "jwt.secret", "string"},
 	{"JWT_EXPIRY", "jwt.expiry", "string"},
+	{"JWT_ISSUER", "jwt.issuer", "string"},
+	{"JWT_AUDIENCE", "jwt.audience", "string"},
+	{"JWT_IMPERSONATION_EXPIRY", "jwt.impersonation_expiry", "string"},
 
 	// VAPID Config
 	{"VAPID_PUBLIC_KEY", "vapid.public_key", "string"},
@@ -39,16 +56,41 @@ var envMappings = []EnvMapping{
 	// Telegram Config
 	{"TELEGRAM_BOT_TOKEN", "telegram.bot_token", "string"},
 	{"TELEGRAM_ENABLED", "telegram.enabled", "bool"},
+	{"APPRISE_SERVER_URL", "apprise.server_url", "string"},
+	{"PAGERDUTY_EVENTS_URL", "pagerduty.events_url", "string"},
+	{"SMTP_HOST", "smtp.host", "string"},
+	{"SMTP_PORT", "smtp.port", "int"},
+	{"SMTP_USERNAME", "smtp.username", "string"},
+	{"SMTP_PASSWORD", "smtp.password", "string"},
+	{"SMTP_FROM", "smtp.from", "string"},
 
 	// Admin Config
 	{"ADMIN_USERNAME", "admin.username", "string"},
 	{"ADMIN_PASSWORD", "admin.password", "string"},
 
+	// Password Policy Config
+	{"PASSWORD_POLICY_MIN_LENGTH", "password_policy.min_length", "int"},
+	{"PASSWORD_POLICY_REQUIRE_MIXED_CASE", "password_policy.require_mixed_case", "bool"},
+	{"PASSWORD_POLICY_REQUIRE_DIGIT", "password_policy.require_digit", "bool"},
+	{"PASSWORD_POLICY_REQUIRE_SYMBOL", "password_policy.require_symbol", "bool"},
+
+	// Auth Config
+	{"AUTH_TOTP_SKEW", "auth.totp.skew", "int"},
+	{"AUTH_TOTP_MAX_ATTEMPTS", "auth.totp.max_attempts", "int"},
+	{"AUTH_TOTP_ATTEMPT_WINDOW", "auth.totp.attempt_window", "string"},
+
+	// Security Config
+	{"SECURITY_OBSCURE_NOT_FOUND", "security.obscure_not_found", "bool"},
+
 	// Rate Limit Config
 	{"RATE_LIMIT_API_REQUESTS_PER_MINUTE", "rate_limit.api.requests_per_minute", "int"},
+	{"RATE_LIMIT_API_BURST", "rate_limit.api.burst", "int"},
 	{"RATE_LIMIT_TELEGRAM_MESSAGES_PER_MINUTE", "rate_limit.channels.telegram.messages_per_minute", "int"},
 	{"RATE_LIMIT_DISCORD_MESSAGES_PER_MINUTE", "rate_limit.channels.discord.messages_per_minute", "int"},
 	{"RATE_LIMIT_PUSH_MESSAGES_PER_MINUTE", "rate_limit.channels.push.messages_per_minute", "int"},
+	{"RATE_LIMIT_APPRISE_MESSAGES_PER_MINUTE", "rate_limit.channels.apprise.messages_per_minute", "int"},
+	{"RATE_LIMIT_TEAMS_MESSAGES_PER_MINUTE", "rate_limit.channels.teams.messages_per_minute", "int"},
+	{"RATE_LIMIT_PAGERDUTY_MESSAGES_PER_MINUTE", "rate_limit.channels.pagerduty.messages_per_minute", "int"},
 
 	// WebSocket Config
 	{"WEBSOCKET_ENABLED", "websocket.enabled", "bool"},
@@ -57,6 +99,8 @@ var envMappings = []EnvMapping{
 	{"WEBSOCKET_MAX_MESSAGE_SIZE", "websocket.max_message_size", "int"},
 	{"WEBSOCKET_READ_BUFFER_SIZE", "websocket.read_buffer_size", "int"},
 	{"WEBSOCKET_WRITE_BUFFER_SIZE", "websocket.write_buffer_size", "int"},
+	{"WEBSOCKET_SEND_BUFFER", "websocket.send_buffer", "int"},
+	{"WEBSOCKET_SLOW_CLIENT_POLICY", "websocket.slow_client_policy", "string"},
 
 	// Retention Config
 	{"RETENTION_ENABLED", "retention.enabled", "bool"},
@@ -65,6 +109,53 @@ var envMappings = []EnvMapping{
 	{"RETENTION_CLEANUP_ENABLED", "retention.cleanup.enabled", "bool"},
 	{"RETENTION_CLEANUP_SCHEDULE", "retention.cleanup.schedule", "string"},
 	{"RETENTION_CLEANUP_BATCH_SIZE", "retention.cleanup.batch_size", "int"},
+	{"RETENTION_FLOOR_MIN_AGE", "retention.floor.min_age", "string"},
+	{"RETENTION_FLOOR_MIN_COUNT", "retention.floor.min_count", "int"},
+
+	// Alerts Config
+	{"ALERTS_ENABLED", "alerts.enabled", "bool"},
+	{"ALERTS_INTERVAL", "alerts.interval", "string"},
+
+	// Notifications Config
+	{"NOTIFICATIONS_MAX_ATTEMPTS", "notifications.max_attempts", "int"},
+	{"NOTIFICATIONS_RETRY_BACKOFF", "notifications.retry_backoff", "string"},
+	{"NOTIFICATIONS_HTTP_TIMEOUT", "notifications.http_timeout", "string"},
+
+	// Ingestion Config
+	{"INGESTION_ASYNC_BUFFER_ENABLED", "ingestion.async_buffer.enabled", "bool"},
+	{"INGESTION_ASYNC_BUFFER_FLUSH_SIZE", "ingestion.async_buffer.flush_size", "int"},
+	{"INGESTION_ASYNC_BUFFER_FLUSH_INTERVAL", "ingestion.async_buffer.flush_interval", "string"},
+	{"INGESTION_ASYNC_BUFFER_MAX_PENDING", "ingestion.async_buffer.max_pending", "int"},
+	{"INGESTION_FANOUT_WORKERS", "ingestion.fanout.workers", "int"},
+	{"INGESTION_FANOUT_QUEUE_SIZE", "ingestion.fanout.queue_size", "int"},
+	{"INGESTION_MAX_BODY_BYTES", "ingestion.max_body_bytes", "int"},
+	{"INGESTION_MAX_MESSAGE_BYTES", "ingestion.max_message_bytes", "int"},
+	{"INGESTION_MAX_METADATA_BYTES", "ingestion.max_metadata_bytes", "int"},
+	{"INGESTION_MAX_METADATA_DEPTH", "ingestion.max_metadata_depth", "int"},
+	{"INGESTION_MAX_METADATA_KEYS", "ingestion.max_metadata_keys", "int"},
+	{"INGESTION_OVERSIZE_POLICY", "ingestion.oversize_policy", "string"},
+	{"INGESTION_MAX_METADATA_VALUE_BYTES", "ingestion.max_metadata_value_bytes", "int"},
+	{"INGESTION_METADATA_VALUE_POLICY", "ingestion.metadata_value_policy", "string"},
+	{"INGESTION_MESSAGE_TRUNCATE_LENGTH", "ingestion.message_truncate_length", "int"},
+	{"INGESTION_TIMESTAMP_MAX_FUTURE", "ingestion.timestamp.max_future", "string"},
+	{"INGESTION_TIMESTAMP_MAX_PAST", "ingestion.timestamp.max_past", "string"},
+	{"INGESTION_TIMESTAMP_POLICY", "ingestion.timestamp.policy", "string"},
+	{"INGESTION_REDACTION_DETECTORS", "ingestion.redaction.detectors", "string"},
+	{"INGESTION_REDACTION_PATTERNS", "ingestion.redaction.patterns", "string"},
+
+	// Stats Config
+	{"STATS_CACHE_TTL", "stats.cache_ttl", "string"},
+	{"STATS_TIMEZONE", "stats.timezone", "string"},
+
+	// Query Config
+	{"QUERY_DEFAULT_RANGE", "query.default_range", "string"},
+	{"QUERY_MAX_LIMIT", "query.max_limit", "int"},
+
+	// Enrichment Config
+	{"ENRICHMENT_GEOIP_ENABLED", "enrichment.geoip.enabled", "bool"},
+	{"ENRICHMENT_GEOIP_DATABASE_PATH", "enrichment.geoip.database_path", "string"},
+	{"ENRICHMENT_GEOIP_ASN_DATABASE_PATH", "enrichment.geoip.asn_database_path", "string"},
+	{"ENRICHMENT_GEOIP_IP_FIELD", "enrichment.geoip.ip_field", "string"},
 }
 
 // getEnvValue gets environment variable value with fallback to CL_ prefix
@@ -82,11 +173,39 @@ func getEnvValue(key string) string {
 	return ""
 }
 
+// getEnvFileValue reads a value from the file named by KEY_FILE (or CL_KEY_FILE),
+// the convention used for Docker and Kubernetes secrets. Trailing newlines are
+// trimmed. The second return is false when neither variable is set.
+func getEnvFileValue(key string) (string, bool, error) {
+	path := getEnvValue(key + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", true, fmt.Errorf("%s_FILE is set but %s could not be read: %w", key, path, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
 // loadFromEnvNew loads config from environment variables using the mapping table
 // This replaces the old manual loadFromEnv method
-func (c *Config) loadFromEnvNew() {
+// String values can also come from a file via the _FILE suffix; a plain
+// variable takes precedence over its _FILE form.
+func (c *Config) loadFromEnvNew() error {
 	for _, mapping := range envMappings {
 		value := getEnvValue(mapping.EnvKey)
+		if value == "" && mapping.Type == "string" {
+			fileValue, ok, err := getEnvFileValue(mapping.EnvKey)
+			if err != nil {
+				return err
+			}
+			if ok {
+				value = fileValue
+			}
+		}
 		if value == "" {
 			continue
 		}
@@ -97,6 +216,7 @@ func (c *Config) loadFromEnvNew() {
 			fmt.Printf("Warning: Failed to set %s from env: %v\n", mapping.ConfigPath, err)
 		}
 	}
+	return nil
 }
 
 // setConfigValue sets a config value using dot-notation path
@@ -117,14 +237,38 @@ func (c *Config) setConfigValue(path, value, valueType string) error {
 		return c.setVAPIDValue(parts[1:], value, valueType)
 	case "telegram":
 		return c.setTelegramValue(parts[1:], value, valueType)
+	case "apprise":
+		return c.setAppriseValue(parts[1:], value, valueType)
+	case "pagerduty":
+		return c.setPagerDutyValue(parts[1:], value, valueType)
+	case "smtp":
+		return c.setSMTPValue(parts[1:], value, valueType)
 	case "admin":
 		return c.setAdminValue(parts[1:], value, valueType)
+	case "password_policy":
+		return c.setPasswordPolicyValue(parts[1:], value, valueType)
+	case "auth":
+		return c.setAuthValue(parts[1:], value, valueType)
+	case "security":
+		return c.setSecurityValue(parts[1:], value, valueType)
 	case "rate_limit":
 		return c.setRateLimitValue(parts[1:], value, valueType)
 	case "websocket":
 		return c.setWebSocketValue(parts[1:], value, valueType)
 	case "retention":
 		return c.setRetentionValue(parts[1:], value, valueType)
+	case "alerts":
+		return c.setAlertsValue(parts[1:], value, valueType)
+	case "notifications":
+		return c.setNotificationsValue(parts[1:], value, valueType)
+	case "ingestion":
+		return c.setIngestionValue(parts[1:], value, valueType)
+	case "stats":
+		return c.setStatsValue(parts[1:], value, valueType)
+	case "query":
+		return c.setQueryValue(parts[1:], value, valueType)
+	case "enrichment":
+		return c.setEnrichmentValue(parts[1:], value, valueType)
 	default:
 		return fmt.Errorf("unknown config section: %s", parts[0])
 	}
@@ -140,6 +284,34 @@ func (c *Config) setServerValue(path []string, value, valueType string) error {
 		c.Server.Port = port
 	case "env":
 		c.Server.Env = value
+	case "shutdown_timeout":
+		c.Server.ShutdownTimeout = value
+	case "body_limit":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Server.BodyLimit = n
+	case "log_format":
+		c.Server.LogFormat = value
+	case "tls":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid server path: %v", path)
+		}
+		switch path[1] {
+		case "enabled":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			c.Server.TLS.Enabled = enabled
+		case "cert_file":
+			c.Server.TLS.CertFile = value
+		case "key_file":
+			c.Server.TLS.KeyFile = value
+		default:
+			return fmt.Errorf("unknown server.tls field: %s", path[1])
+		}
 	default:
 		return fmt.Errorf("unknown server field: %s", path[0])
 	}
@@ -148,8 +320,40 @@ func (c *Config) setServerValue(path []string, value, valueType string) error {
 
 func (c *Config) setDatabaseValue(path []string, value, valueType string) error {
 	switch path[0] {
+	case "driver":
+		c.Database.Driver = value
 	case "path":
 		c.Database.Path = value
+	case "url":
+		c.Database.URL = value
+	case "wal":
+		wal, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Database.WAL = wal
+	case "busy_timeout_ms":
+		timeout, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Database.BusyTimeoutMS = timeout
+	case "synchronous":
+		c.Database.Synchronous = value
+	case "maintenance":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid database.maintenance path: %v", path)
+		}
+		switch path[1] {
+		case "enabled":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			c.Database.Maintenance.Enabled = enabled
+		case "schedule":
+			c.Database.Maintenance.Schedule = value
+		}
 	default:
 		return fmt.Errorf("unknown database field: %s", path[0])
 	}
@@ -160,6 +364,8 @@ func (c *Config) setRedisValue(path []string, value, valueType string) error {
 	switch path[0] {
 	case "url":
 		c.Redis.URL = value
+	case "health_check_interval":
+		c.Redis.HealthCheckInterval = value
 	default:
 		return fmt.Errorf("unknown redis field: %s", path[0])
 	}
@@ -172,6 +378,12 @@ func (c *Config) setJWTValue(path []string, value, valueType string) error {
 		c.JWT.Secret = value
 	case "expiry":
 		c.JWT.Expiry = value
+	case "issuer":
+		c.JWT.Issuer = value
+	case "audience":
+		c.JWT.Audience = value
+	case "impersonation_expiry":
+		c.JWT.ImpersonationExpiry = value
 	default:
 		return fmt.Errorf("unknown jwt field: %s", path[0])
 	}
@@ -208,6 +420,48 @@ func (c *Config) setTelegramValue(path []string, value, valueType string) error
 	return nil
 }
 
+func (c *Config) setSMTPValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "host":
+		c.SMTP.Host = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.SMTP.Port = port
+	case "username":
+		c.SMTP.Username = value
+	case "password":
+		c.SMTP.Password = value
+	case "from":
+		c.SMTP.From = value
+	default:
+		return fmt.Errorf("unknown smtp field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setAppriseValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "server_url":
+		c.Apprise.ServerURL = value
+	default:
+		return fmt.Errorf("unknown apprise field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setPagerDutyValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "events_url":
+		c.PagerDuty.EventsURL = value
+	default:
+		return fmt.Errorf("unknown pagerduty field: %s", path[0])
+	}
+	return nil
+}
+
 func (c *Config) setAdminValue(path []string, value, valueType string) error {
 	switch path[0] {
 	case "username":
@@ -220,6 +474,77 @@ func (c *Config) setAdminValue(path []string, value, valueType string) error {
 	return nil
 }
 
+func (c *Config) setPasswordPolicyValue(path []string, value, valueType string) error {
+	if path[0] == "min_length" {
+		length, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Password.MinLength = length
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	switch path[0] {
+	case "require_mixed_case":
+		c.Password.RequireMixedCase = enabled
+	case "require_digit":
+		c.Password.RequireDigit = enabled
+	case "require_symbol":
+		c.Password.RequireSymbol = enabled
+	default:
+		return fmt.Errorf("unknown password_policy field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setAuthValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "totp":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid auth path: %v", path)
+		}
+		switch path[1] {
+		case "skew":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			c.Auth.TOTP.Skew = n
+		case "max_attempts":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			c.Auth.TOTP.MaxAttempts = n
+		case "attempt_window":
+			c.Auth.TOTP.AttemptWindow = value
+		default:
+			return fmt.Errorf("unknown auth.totp field: %s", path[1])
+		}
+	default:
+		return fmt.Errorf("unknown auth field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setSecurityValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "obscure_not_found":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Security.ObscureNotFound = enabled
+	default:
+		return fmt.Errorf("unknown security field: %s", path[0])
+	}
+	return nil
+}
+
 func (c *Config) setRateLimitValue(path []string, value, valueType string) error {
 	if len(path) < 2 {
 		return fmt.Errorf("invalid rate_limit path: %v", path)
@@ -232,8 +557,11 @@ func (c *Config) setRateLimitValue(path []string, value, valueType string) error
 
 	switch path[0] {
 	case "api":
-		if path[1] == "requests_per_minute" {
+		switch path[1] {
+		case "requests_per_minute":
 			c.RateLimit.API.RequestsPerMinute = intVal
+		case "burst":
+			c.RateLimit.API.Burst = intVal
 		}
 	case "channels":
 		if len(path) < 3 {
@@ -246,6 +574,12 @@ func (c *Config) setRateLimitValue(path []string, value, valueType string) error
 			c.RateLimit.Channels.Discord.MessagesPerMinute = intVal
 		case "push":
 			c.RateLimit.Channels.Push.MessagesPerMinute = intVal
+		case "apprise":
+			c.RateLimit.Channels.Apprise.MessagesPerMinute = intVal
+		case "teams":
+			c.RateLimit.Channels.Teams.MessagesPerMinute = intVal
+		case "pagerduty":
+			c.RateLimit.Channels.PagerDuty.MessagesPerMinute = intVal
 		}
 	}
 	return nil
@@ -281,6 +615,14 @@ func (c *Config) setWebSocketValue(path []string, value, valueType string) error
 			return err
 		}
 		c.WebSocket.WriteBufferSize = size
+	case "send_buffer":
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.WebSocket.SendBuffer = size
+	case "slow_client_policy":
+		c.WebSocket.SlowClientPolicy = value
 	default:
 		return fmt.Errorf("unknown websocket field: %s", path[0])
 	}
@@ -329,6 +671,252 @@ func (c *Config) setRetentionValue(path []string, value, valueType string) error
 			}
 			c.Retention.Cleanup.BatchSize = size
 		}
+	case "floor":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid retention.floor path: %v", path)
+		}
+		switch path[1] {
+		case "min_age":
+			c.Retention.Floor.MinAge = value
+		case "min_count":
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			c.Retention.Floor.MinCount = count
+		}
+	}
+	return nil
+}
+
+func (c *Config) setAlertsValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "enabled":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Alerts.Enabled = enabled
+	case "interval":
+		c.Alerts.Interval = value
+	default:
+		return fmt.Errorf("unknown alerts field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setNotificationsValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "max_attempts":
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Notifications.MaxAttempts = attempts
+	case "retry_backoff":
+		c.Notifications.RetryBackoff = value
+	case "http_timeout":
+		c.Notifications.HTTPTimeout = value
+	default:
+		return fmt.Errorf("unknown notifications field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setIngestionValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "async_buffer":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid ingestion path: %v", path)
+		}
+		return c.setAsyncBufferValue(path[1], value)
+	case "fanout":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid ingestion path: %v", path)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		switch path[1] {
+		case "workers":
+			c.Ingestion.Fanout.Workers = n
+		case "queue_size":
+			c.Ingestion.Fanout.QueueSize = n
+		default:
+			return fmt.Errorf("unknown ingestion.fanout field: %s", path[1])
+		}
+	case "max_body_bytes":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.MaxBodyBytes = n
+	case "max_message_bytes":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.MaxMessageBytes = n
+	case "max_metadata_bytes":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.MaxMetadataBytes = n
+	case "max_metadata_depth":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.MaxMetadataDepth = n
+	case "max_metadata_keys":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.MaxMetadataKeys = n
+	case "oversize_policy":
+		c.Ingestion.OversizePolicy = value
+	case "max_metadata_value_bytes":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.MaxMetadataValueBytes = n
+	case "metadata_value_policy":
+		c.Ingestion.MetadataValuePolicy = value
+	case "message_truncate_length":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.MessageTruncateLength = n
+	case "redaction":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid ingestion path: %v", path)
+		}
+		return c.setRedactionValue(path[1], value)
+	case "timestamp":
+		if len(path) < 2 {
+			return fmt.Errorf("invalid ingestion path: %v", path)
+		}
+		switch path[1] {
+		case "max_future":
+			c.Ingestion.Timestamp.MaxFuture = value
+		case "max_past":
+			c.Ingestion.Timestamp.MaxPast = value
+		case "policy":
+			c.Ingestion.Timestamp.Policy = value
+		default:
+			return fmt.Errorf("unknown ingestion.timestamp field: %s", path[1])
+		}
+	default:
+		return fmt.Errorf("unknown ingestion field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setAsyncBufferValue(field, value string) error {
+	switch field {
+	case "enabled":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.AsyncBuffer.Enabled = enabled
+	case "flush_size":
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.AsyncBuffer.FlushSize = size
+	case "flush_interval":
+		c.Ingestion.AsyncBuffer.FlushInterval = value
+	case "max_pending":
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Ingestion.AsyncBuffer.MaxPending = size
+	default:
+		return fmt.Errorf("unknown ingestion.async_buffer field: %s", field)
+	}
+	return nil
+}
+
+// setRedactionValue takes detectors as a comma-separated list and patterns one
+// per line, since a regular expression may itself contain commas
+func (c *Config) setRedactionValue(field, value string) error {
+	switch field {
+	case "detectors":
+		c.Ingestion.Redaction.Detectors = splitList(value, ",")
+	case "patterns":
+		c.Ingestion.Redaction.Patterns = splitList(value, "\n")
+	default:
+		return fmt.Errorf("unknown ingestion.redaction field: %s", field)
+	}
+	return nil
+}
+
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c *Config) setStatsValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "cache_ttl":
+		c.Stats.CacheTTL = value
+	case "timezone":
+		c.Stats.Timezone = value
+	default:
+		return fmt.Errorf("unknown stats field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setQueryValue(path []string, value, valueType string) error {
+	switch path[0] {
+	case "default_range":
+		c.Query.DefaultRange = value
+	case "max_limit":
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Query.MaxLimit = limit
+	default:
+		return fmt.Errorf("unknown query field: %s", path[0])
+	}
+	return nil
+}
+
+func (c *Config) setEnrichmentValue(path []string, value, valueType string) error {
+	if path[0] != "geoip" || len(path) < 2 {
+		return fmt.Errorf("invalid enrichment path: %v", path)
+	}
+
+	switch path[1] {
+	case "enabled":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Enrichment.GeoIP.Enabled = enabled
+	case "database_path":
+		c.Enrichment.GeoIP.DatabasePath = value
+	case "asn_database_path":
+		c.Enrichment.GeoIP.ASNDatabasePath = value
+	case "ip_field":
+		c.Enrichment.GeoIP.IPField = value
+	default:
+		return fmt.Errorf("unknown enrichment.geoip field: %s", path[1])
 	}
 	return nil
 }
@@ -342,6 +930,9 @@ func PrintEnvHelp() {
 	fmt.Println("  1. Direct:     VARIABLE_NAME (e.g., SERVER_PORT)")
 	fmt.Println("  2. Prefixed:   CL_VARIABLE_NAME (e.g., CL_SERVER_PORT)")
 	fmt.Println()
+	fmt.Println("String variables can also be read from a file by appending _FILE")
+	fmt.Println("(e.g., JWT_SECRET_FILE=/run/secrets/jwt_secret).")
+	fmt.Println()
 
 	currentSection := ""
 	for _, mapping := range envMappings {
@@ -2,7 +2,10 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestEnvOverride(t *testing.T) {
@@ -14,6 +17,8 @@ func TestEnvOverride(t *testing.T) {
 		"DATABASE_PATH", "CL_DATABASE_PATH",
 		"REDIS_URL", "CL_REDIS_URL",
 		"JWT_SECRET", "CL_JWT_SECRET",
+		"JWT_AUDIENCE", "CL_JWT_AUDIENCE",
+		"JWT_IMPERSONATION_EXPIRY", "CL_JWT_IMPERSONATION_EXPIRY",
 		"VAPID_PUBLIC_KEY", "CL_VAPID_PUBLIC_KEY",
 	}
 	for _, key := range envKeys {
@@ -55,12 +60,66 @@ func TestEnvOverride(t *testing.T) {
 			envValue: "production",
 			check:    func(c *Config) bool { return c.Server.Env == "production" },
 		},
+		{
+			name:     "Server shutdown timeout",
+			envKey:   "SERVER_SHUTDOWN_TIMEOUT",
+			envValue: "30s",
+			check:    func(c *Config) bool { return c.GetShutdownTimeout() == 30*time.Second },
+		},
+		{
+			name:     "Server body limit",
+			envKey:   "SERVER_BODY_LIMIT",
+			envValue: "8388608",
+			check:    func(c *Config) bool { return c.GetBodyLimit() == 8388608 },
+		},
+		{
+			name:     "Server log format",
+			envKey:   "SERVER_LOG_FORMAT",
+			envValue: "json",
+			check:    func(c *Config) bool { return c.GetLogFormat() == "json" },
+		},
+		{
+			name:     "Server TLS enabled",
+			envKey:   "SERVER_TLS_ENABLED",
+			envValue: "true",
+			check:    func(c *Config) bool { return c.Server.TLS.Enabled },
+		},
+		{
+			name:     "Server TLS cert file",
+			envKey:   "SERVER_TLS_CERT_FILE",
+			envValue: "/etc/ssl/cert.pem",
+			check:    func(c *Config) bool { return c.Server.TLS.CertFile == "/etc/ssl/cert.pem" },
+		},
+		{
+			name:     "Server TLS key file",
+			envKey:   "SERVER_TLS_KEY_FILE",
+			envValue: "/etc/ssl/key.pem",
+			check:    func(c *Config) bool { return c.Server.TLS.KeyFile == "/etc/ssl/key.pem" },
+		},
+		{
+			name:     "Redis health check interval",
+			envKey:   "REDIS_HEALTH_CHECK_INTERVAL",
+			envValue: "10s",
+			check:    func(c *Config) bool { return c.GetRedisHealthCheckInterval() == 10*time.Second },
+		},
+		{
+			name:     "Stats time zone",
+			envKey:   "STATS_TIMEZONE",
+			envValue: "Asia/Jakarta",
+			check:    func(c *Config) bool { return c.GetStatsLocation().String() == "Asia/Jakarta" },
+		},
 		{
 			name:     "DATABASE_PATH without prefix",
 			envKey:   "DATABASE_PATH",
 			envValue: "/custom/path/db.sqlite",
 			check:    func(c *Config) bool { return c.Database.Path == "/custom/path/db.sqlite" },
 		},
+		{
+			name:     "Database maintenance schedule",
+			envKey:   "DATABASE_MAINTENANCE_SCHEDULE",
+			envValue: "30 4 * * 0",
+			check:    func(c *Config) bool { return c.Database.Maintenance.Schedule == "30 4 * * 0" },
+		},
 		{
 			name:     "REDIS_URL with CL_ prefix",
 			envKey:   "CL_REDIS_URL",
@@ -73,6 +132,18 @@ func TestEnvOverride(t *testing.T) {
 			envValue: "super-secret-key",
 			check:    func(c *Config) bool { return c.JWT.Secret == "super-secret-key" },
 		},
+		{
+			name:     "JWT_AUDIENCE without prefix",
+			envKey:   "JWT_AUDIENCE",
+			envValue: "central-logs-api",
+			check:    func(c *Config) bool { return c.JWT.Audience == "central-logs-api" },
+		},
+		{
+			name:     "JWT_IMPERSONATION_EXPIRY without prefix",
+			envKey:   "JWT_IMPERSONATION_EXPIRY",
+			envValue: "5m",
+			check:    func(c *Config) bool { return c.GetImpersonationExpiry() == 5*time.Minute },
+		},
 		{
 			name:     "VAPID_PUBLIC_KEY without prefix",
 			envKey:   "VAPID_PUBLIC_KEY",
@@ -91,6 +162,12 @@ func TestEnvOverride(t *testing.T) {
 			envValue: "1024",
 			check:    func(c *Config) bool { return c.WebSocket.MaxMessageSize == 1024 },
 		},
+		{
+			name:     "WEBSOCKET_SLOW_CLIENT_POLICY string",
+			envKey:   "WEBSOCKET_SLOW_CLIENT_POLICY",
+			envValue: "disconnect",
+			check:    func(c *Config) bool { return c.WebSocket.SlowClientPolicy == "disconnect" },
+		},
 	}
 
 	for _, tt := range tests {
@@ -207,12 +284,60 @@ func TestComplexNestedPaths(t *testing.T) {
 			envValue: "5000",
 			check:    func(c *Config) bool { return c.RateLimit.API.RequestsPerMinute == 5000 },
 		},
+		{
+			name:     "Rate limit API burst",
+			envKey:   "RATE_LIMIT_API_BURST",
+			envValue: "200",
+			check:    func(c *Config) bool { return c.GetAPIRateBurst() == 200 },
+		},
 		{
 			name:     "Rate limit Telegram",
 			envKey:   "RATE_LIMIT_TELEGRAM_MESSAGES_PER_MINUTE",
 			envValue: "100",
 			check:    func(c *Config) bool { return c.RateLimit.Channels.Telegram.MessagesPerMinute == 100 },
 		},
+		{
+			name:     "Rate limit Apprise",
+			envKey:   "RATE_LIMIT_APPRISE_MESSAGES_PER_MINUTE",
+			envValue: "45",
+			check:    func(c *Config) bool { return c.GetChannelRateLimit("APPRISE") == 45 },
+		},
+		{
+			name:     "Rate limit Teams",
+			envKey:   "RATE_LIMIT_TEAMS_MESSAGES_PER_MINUTE",
+			envValue: "20",
+			check:    func(c *Config) bool { return c.GetChannelRateLimit("TEAMS") == 20 },
+		},
+		{
+			name:     "Rate limit PagerDuty",
+			envKey:   "RATE_LIMIT_PAGERDUTY_MESSAGES_PER_MINUTE",
+			envValue: "100",
+			check:    func(c *Config) bool { return c.GetChannelRateLimit("PAGERDUTY") == 100 },
+		},
+		{
+			name:     "PagerDuty events URL",
+			envKey:   "PAGERDUTY_EVENTS_URL",
+			envValue: "https://events.eu.pagerduty.com/v2/enqueue",
+			check:    func(c *Config) bool { return c.PagerDuty.EventsURL == "https://events.eu.pagerduty.com/v2/enqueue" },
+		},
+		{
+			name:     "Ingestion fanout workers",
+			envKey:   "INGESTION_FANOUT_WORKERS",
+			envValue: "32",
+			check:    func(c *Config) bool { return c.GetFanoutWorkers() == 32 },
+		},
+		{
+			name:     "Notification HTTP timeout",
+			envKey:   "NOTIFICATIONS_HTTP_TIMEOUT",
+			envValue: "3s",
+			check:    func(c *Config) bool { return c.GetNotificationHTTPTimeout() == 3*time.Second },
+		},
+		{
+			name:     "Apprise server URL",
+			envKey:   "APPRISE_SERVER_URL",
+			envValue: "http://apprise:8000",
+			check:    func(c *Config) bool { return c.Apprise.ServerURL == "http://apprise:8000" },
+		},
 		{
 			name:     "Retention default max age",
 			envKey:   "RETENTION_DEFAULT_MAX_AGE",
@@ -225,6 +350,87 @@ func TestComplexNestedPaths(t *testing.T) {
 			envValue: "false",
 			check:    func(c *Config) bool { return c.Retention.Cleanup.Enabled == false },
 		},
+		{
+			name:     "Retention floor min count",
+			envKey:   "RETENTION_FLOOR_MIN_COUNT",
+			envValue: "1000",
+			check:    func(c *Config) bool { return c.Retention.Floor.MinCount == 1000 },
+		},
+		{
+			name:     "Ingestion max metadata keys",
+			envKey:   "INGESTION_MAX_METADATA_KEYS",
+			envValue: "25",
+			check:    func(c *Config) bool { return c.GetIngestionMaxMetadataKeys() == 25 },
+		},
+		{
+			name:     "Auth TOTP skew",
+			envKey:   "AUTH_TOTP_SKEW",
+			envValue: "2",
+			check:    func(c *Config) bool { return c.Auth.TOTP.Skew == 2 },
+		},
+		{
+			name:     "Auth TOTP max attempts",
+			envKey:   "AUTH_TOTP_MAX_ATTEMPTS",
+			envValue: "10",
+			check:    func(c *Config) bool { return c.Auth.TOTP.MaxAttempts == 10 },
+		},
+		{
+			name:     "Auth TOTP attempt window",
+			envKey:   "AUTH_TOTP_ATTEMPT_WINDOW",
+			envValue: "30m",
+			check:    func(c *Config) bool { return c.GetTOTPAttemptWindow() == 30*time.Minute },
+		},
+		{
+			name:     "Security obscure not found",
+			envKey:   "SECURITY_OBSCURE_NOT_FOUND",
+			envValue: "true",
+			check:    func(c *Config) bool { return c.Security.ObscureNotFound },
+		},
+		{
+			name:     "Ingestion max metadata value bytes",
+			envKey:   "INGESTION_MAX_METADATA_VALUE_BYTES",
+			envValue: "4096",
+			check:    func(c *Config) bool { return c.Ingestion.MaxMetadataValueBytes == 4096 },
+		},
+		{
+			name:     "Ingestion message truncate length",
+			envKey:   "INGESTION_MESSAGE_TRUNCATE_LENGTH",
+			envValue: "2000",
+			check:    func(c *Config) bool { return c.Ingestion.MessageTruncateLength == 2000 },
+		},
+		{
+			name:     "Ingestion metadata value policy",
+			envKey:   "INGESTION_METADATA_VALUE_POLICY",
+			envValue: "drop",
+			check:    func(c *Config) bool { return c.GetIngestionMetadataValuePolicy() == "drop" },
+		},
+		{
+			name:     "Ingestion timestamp max past",
+			envKey:   "INGESTION_TIMESTAMP_MAX_PAST",
+			envValue: "30d",
+			check: func(c *Config) bool {
+				_, maxPast := c.GetIngestionTimestampWindow()
+				return maxPast == 30*24*time.Hour
+			},
+		},
+		{
+			name:     "Redaction detectors",
+			envKey:   "INGESTION_REDACTION_DETECTORS",
+			envValue: "email, credit_card",
+			check: func(c *Config) bool {
+				d := c.Ingestion.Redaction.Detectors
+				return len(d) == 2 && d[0] == "email" && d[1] == "credit_card"
+			},
+		},
+		{
+			name:     "Redaction patterns one per line",
+			envKey:   "INGESTION_REDACTION_PATTERNS",
+			envValue: "tok_[a-z]{3,8}\nsecret=\\S+\n",
+			check: func(c *Config) bool {
+				p := c.Ingestion.Redaction.Patterns
+				return len(p) == 2 && p[0] == "tok_[a-z]{3,8}" && p[1] == `secret=\S+`
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -241,3 +447,78 @@ func TestComplexNestedPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvFileValue(t *testing.T) {
+	dir := t.TempDir()
+
+	secretPath := filepath.Join(dir, "jwt_secret")
+	if err := os.WriteFile(secretPath, []byte("from-file-secret\n\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+	tokenPath := filepath.Join(dir, "bot_token")
+	if err := os.WriteFile(tokenPath, []byte("123:abc\r\n"), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	t.Run("reads and trims file values", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "")
+		t.Setenv("JWT_SECRET_FILE", secretPath)
+		t.Setenv("CL_TELEGRAM_BOT_TOKEN_FILE", tokenPath)
+
+		cfg := DefaultConfig()
+		if err := cfg.loadFromEnvNew(); err != nil {
+			t.Fatalf("loadFromEnvNew failed: %v", err)
+		}
+		if cfg.JWT.Secret != "from-file-secret" {
+			t.Errorf("Expected JWT secret from file, got %q", cfg.JWT.Secret)
+		}
+		if cfg.Telegram.BotToken != "123:abc" {
+			t.Errorf("Expected bot token from CL_ prefixed file, got %q", cfg.Telegram.BotToken)
+		}
+	})
+
+	t.Run("plain variable takes precedence", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "from-env")
+		t.Setenv("JWT_SECRET_FILE", secretPath)
+
+		cfg := DefaultConfig()
+		if err := cfg.loadFromEnvNew(); err != nil {
+			t.Fatalf("loadFromEnvNew failed: %v", err)
+		}
+		if cfg.JWT.Secret != "from-env" {
+			t.Errorf("Expected JWT_SECRET to win over JWT_SECRET_FILE, got %q", cfg.JWT.Secret)
+		}
+	})
+
+	t.Run("unreadable file is an error", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing")
+		t.Setenv("JWT_SECRET", "")
+		t.Setenv("JWT_SECRET_FILE", missing)
+
+		cfg := DefaultConfig()
+		err := cfg.loadFromEnvNew()
+		if err == nil {
+			t.Fatal("Expected error for unreadable secret file")
+		}
+		if !strings.Contains(err.Error(), "JWT_SECRET_FILE") || !strings.Contains(err.Error(), missing) {
+			t.Errorf("Expected error to name the variable and path, got: %v", err)
+		}
+	})
+
+	t.Run("non-string mappings ignore _FILE", func(t *testing.T) {
+		portPath := filepath.Join(dir, "port")
+		if err := os.WriteFile(portPath, []byte("9999"), 0600); err != nil {
+			t.Fatalf("Failed to write port file: %v", err)
+		}
+		t.Setenv("SERVER_PORT", "")
+		t.Setenv("SERVER_PORT_FILE", portPath)
+
+		cfg := DefaultConfig()
+		if err := cfg.loadFromEnvNew(); err != nil {
+			t.Fatalf("loadFromEnvNew failed: %v", err)
+		}
+		if cfg.Server.Port != 3000 {
+			t.Errorf("Expected default port, got %d", cfg.Server.Port)
+		}
+	})
+}
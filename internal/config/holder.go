@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Holder keeps the active config and swaps it atomically on reload. Readers
+// that need to pick up reloaded values should call Get each time instead of
+// keeping the *Config around.
+type Holder struct {
+	cfg    atomic.Pointer[Config]
+	reload sync.Mutex
+}
+
+// ReloadResult describes what a reload changed
+type ReloadResult struct {
+	Changed         []string // "path: old -> new" for every applied setting
+	RestartRequired []string // Sections edited on disk that only apply on restart
+}
+
+// NewHolder creates a holder for the config loaded at startup
+func NewHolder(cfg *Config) *Holder {
+	h := &Holder{}
+	h.cfg.Store(cfg)
+	return h
+}
+
+// Get returns the active config. It must be treated as read-only.
+func (h *Holder) Get() *Config {
+	return h.cfg.Load()
+}
+
+// Reload re-reads the config file and applies the settings that are read at
+// runtime: rate limits and the retention floor. Everything else, including
+// the rest of retention, keeps its startup value. An invalid file leaves the
+// active config untouched.
+func (h *Holder) Reload(path string) (*ReloadResult, error) {
+	h.reload.Lock()
+	defer h.reload.Unlock()
+
+	next, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	current := h.Get()
+	updated := *current
+	updated.RateLimit = next.RateLimit
+	updated.Retention.Floor = next.Retention.Floor
+
+	result := &ReloadResult{
+		Changed:         diffReloadable(current, &updated),
+		RestartRequired: diffStructural(current, next),
+	}
+
+	h.cfg.Store(&updated)
+	return result, nil
+}
+
+// diffReloadable lists every runtime-reloadable setting that differs
+func diffReloadable(old, new *Config) []string {
+	var changes []string
+	compare := func(path string, a, b interface{}) {
+		if a != b {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", path, a, b))
+		}
+	}
+
+	compare("rate_limit.api.requests_per_minute", old.RateLimit.API.RequestsPerMinute, new.RateLimit.API.RequestsPerMinute)
+	compare("rate_limit.api.burst", old.RateLimit.API.Burst, new.RateLimit.API.Burst)
+	compare("rate_limit.channels.telegram.messages_per_minute", old.RateLimit.Channels.Telegram.MessagesPerMinute, new.RateLimit.Channels.Telegram.MessagesPerMinute)
+	compare("rate_limit.channels.discord.messages_per_minute", old.RateLimit.Channels.Discord.MessagesPerMinute, new.RateLimit.Channels.Discord.MessagesPerMinute)
+	compare("rate_limit.channels.push.messages_per_minute", old.RateLimit.Channels.Push.MessagesPerMinute, new.RateLimit.Channels.Push.MessagesPerMinute)
+	compare("rate_limit.channels.apprise.messages_per_minute", old.RateLimit.Channels.Apprise.MessagesPerMinute, new.RateLimit.Channels.Apprise.MessagesPerMinute)
+	compare("rate_limit.channels.teams.messages_per_minute", old.RateLimit.Channels.Teams.MessagesPerMinute, new.RateLimit.Channels.Teams.MessagesPerMinute)
+	compare("rate_limit.channels.pagerduty.messages_per_minute", old.RateLimit.Channels.PagerDuty.MessagesPerMinute, new.RateLimit.Channels.PagerDuty.MessagesPerMinute)
+
+	compare("retention.floor.min_age", old.Retention.Floor.MinAge, new.Retention.Floor.MinAge)
+	compare("retention.floor.min_count", old.Retention.Floor.MinCount, new.Retention.Floor.MinCount)
+
+	return changes
+}
+
+// diffStructural lists the top-level sections whose values on disk differ
+// from the running config in settings a reload does not apply. Retention is
+// listed when anything other than its floor changed.
+func diffStructural(running, onDisk *Config) []string {
+	var sections []string
+	a, b := reflect.ValueOf(running).Elem(), reflect.ValueOf(onDisk).Elem()
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("yaml")
+		if name == "rate_limit" {
+			continue
+		}
+		if name == "retention" {
+			rest := onDisk.Retention
+			rest.Floor = running.Retention.Floor
+			if !reflect.DeepEqual(running.Retention, rest) {
+				sections = append(sections, name)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			sections = append(sections, name)
+		}
+	}
+	return sections
+}
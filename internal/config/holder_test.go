@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path string, cfg *Config) {
+	t.Helper()
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestHolder_ReloadAppliesRuntimeSettingsOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, DefaultConfig())
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	holder := NewHolder(cfg)
+
+	edited := DefaultConfig()
+	edited.RateLimit.API.RequestsPerMinute = 250
+	edited.RateLimit.Channels.Apprise.MessagesPerMinute = 10
+	edited.Retention.Floor.MinAge = "3d"
+	edited.Retention.Cleanup.Schedule = "0 4 * * *"
+	edited.Retention.Levels["debug"] = RetentionPolicy{MaxAge: "3d", MaxCount: 10000}
+	edited.Server.Port = 9090
+	writeConfigFile(t, path, edited)
+
+	result, err := holder.Reload(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	active := holder.Get()
+	if active.RateLimit.API.RequestsPerMinute != 250 {
+		t.Errorf("Expected rate limit 250, got %d", active.RateLimit.API.RequestsPerMinute)
+	}
+	if active.GetChannelRateLimit("APPRISE") != 10 {
+		t.Errorf("Expected Apprise limit 10, got %d", active.GetChannelRateLimit("APPRISE"))
+	}
+	if active.Retention.Floor.MinAge != "3d" {
+		t.Errorf("Expected new retention floor, got %q", active.Retention.Floor.MinAge)
+	}
+	// Nothing reads the rest of retention after startup, so it is not applied
+	if active.Retention.Cleanup.Schedule != "0 2 * * *" || active.Retention.Levels["debug"].MaxAge != "7d" {
+		t.Errorf("Retention outside the floor must not change on reload, got %+v", active.Retention)
+	}
+	if active.Server.Port != 3000 {
+		t.Errorf("Server port must not change on reload, got %d", active.Server.Port)
+	}
+	if cfg.RateLimit.API.RequestsPerMinute != 1000 {
+		t.Error("Reload must not mutate the previously active config")
+	}
+
+	want := []string{
+		"rate_limit.api.requests_per_minute: 1000 -> 250",
+		"rate_limit.channels.apprise.messages_per_minute: 30 -> 10",
+		"retention.floor.min_age: 1d -> 3d",
+	}
+	joined := strings.Join(result.Changed, "\n")
+	for _, w := range want {
+		if !strings.Contains(joined, w) {
+			t.Errorf("Expected change %q, got:\n%s", w, joined)
+		}
+	}
+	if len(result.Changed) != len(want) {
+		t.Errorf("Expected %d changes, got %d:\n%s", len(want), len(result.Changed), joined)
+	}
+
+	if strings.Join(result.RestartRequired, ",") != "server,retention" {
+		t.Errorf("Expected server and retention to need a restart, got %v", result.RestartRequired)
+	}
+}
+
+func TestHolder_ReloadRejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, DefaultConfig())
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	holder := NewHolder(cfg)
+
+	if err := os.WriteFile(path, []byte("rate_limit:\n  api:\n    requests_per_minute: 5\njwt:\n  expiry: forever\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := holder.Reload(path); err == nil {
+		t.Fatal("Expected reload of an invalid config to fail")
+	}
+	if holder.Get() != cfg {
+		t.Error("A failed reload must keep the active config")
+	}
+}
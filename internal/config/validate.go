@@ -0,0 +1,294 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"central-logs/internal/services/redaction"
+	"central-logs/internal/utils"
+)
+
+// ValidationError lists every problem found in a config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// ParseRetentionDuration parses a retention age such as "30d", "12h" or "90m".
+// Days are not understood by time.ParseDuration, so a "d" suffix is handled here.
+func ParseRetentionDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// ErrBelowRetentionFloor marks a retention policy that keeps less than the
+// configured floor
+var ErrBelowRetentionFloor = errors.New("below the retention floor")
+
+// Check reports a problem with a log retention policy, naming it by path
+// (e.g. "retention_config.levels.debug"). A malformed value is always an
+// error; keeping less than the floor is an error wrapping
+// ErrBelowRetentionFloor, which callers may choose to override.
+func (f RetentionFloor) Check(path, maxAge string, maxCount int) error {
+	if maxAge != "" {
+		age, err := ParseRetentionDuration(maxAge)
+		if err != nil || age <= 0 {
+			return fmt.Errorf("%s.max_age %q is not a valid duration (e.g. 30d, 12h)", path, maxAge)
+		}
+		if f.MinAge != "" {
+			if minAge, err := ParseRetentionDuration(f.MinAge); err == nil && age < minAge {
+				return fmt.Errorf("%s.max_age %s is %w of %s", path, maxAge, ErrBelowRetentionFloor, f.MinAge)
+			}
+		}
+	}
+	if maxCount < 0 {
+		return fmt.Errorf("%s.max_count must not be negative, got %d", path, maxCount)
+	}
+	if maxCount > 0 && maxCount < f.MinCount {
+		return fmt.Errorf("%s.max_count %d is %w of %d logs", path, maxCount, ErrBelowRetentionFloor, f.MinCount)
+	}
+	return nil
+}
+
+// Validate checks the config for values that would otherwise fail at runtime
+// or silently fall back to a default. All problems are reported together.
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		addf("server.port must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	switch c.Server.LogFormat {
+	case "", "text", "json":
+	default:
+		addf("server.log_format must be text or json, got %q", c.Server.LogFormat)
+	}
+	if c.Server.TLS.Enabled {
+		for _, f := range []struct{ path, file string }{
+			{"server.tls.cert_file", c.Server.TLS.CertFile},
+			{"server.tls.key_file", c.Server.TLS.KeyFile},
+		} {
+			if strings.TrimSpace(f.file) == "" {
+				addf("%s is required when TLS is enabled", f.path)
+			} else if info, err := os.Stat(f.file); err != nil {
+				addf("%s: %v", f.path, err)
+			} else if info.IsDir() {
+				addf("%s %q is a directory", f.path, f.file)
+			}
+		}
+	}
+
+	if strings.TrimSpace(c.JWT.Secret) == "" {
+		addf("jwt.secret is required")
+	}
+	if d, err := time.ParseDuration(c.JWT.Expiry); err != nil {
+		addf("jwt.expiry %q is not a valid duration (e.g. 24h)", c.JWT.Expiry)
+	} else if d <= 0 {
+		addf("jwt.expiry must be positive, got %q", c.JWT.Expiry)
+	}
+
+	checkRetention := func(path string, policy RetentionPolicy) {
+		if policy.MaxAge != "" {
+			if d, err := ParseRetentionDuration(policy.MaxAge); err != nil || d <= 0 {
+				addf("%s.max_age %q is not a valid duration (e.g. 30d, 12h)", path, policy.MaxAge)
+			}
+		}
+		if policy.MaxCount < 0 {
+			addf("%s.max_count must not be negative, got %d", path, policy.MaxCount)
+		}
+	}
+	floorValid := true
+	if c.Retention.Floor.MinAge != "" {
+		if d, err := ParseRetentionDuration(c.Retention.Floor.MinAge); err != nil || d <= 0 {
+			addf("retention.floor.min_age %q is not a valid duration (e.g. 1d, 12h)", c.Retention.Floor.MinAge)
+			floorValid = false
+		}
+	}
+	if c.Retention.Floor.MinCount < 0 {
+		addf("retention.floor.min_count must not be negative, got %d", c.Retention.Floor.MinCount)
+		floorValid = false
+	}
+	// Server-wide log policies must respect the floor too; lowering the
+	// floor is the way to allow less
+	checkLogRetention := func(path string, policy RetentionPolicy) {
+		checkRetention(path, policy)
+		if !floorValid {
+			return
+		}
+		if err := c.Retention.Floor.Check(path, policy.MaxAge, policy.MaxCount); errors.Is(err, ErrBelowRetentionFloor) {
+			addf("%v", err)
+		}
+	}
+	checkLogRetention("retention.default", c.Retention.Default)
+	levels := make([]string, 0, len(c.Retention.Levels))
+	for level := range c.Retention.Levels {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	for _, level := range levels {
+		checkLogRetention("retention.levels."+level, c.Retention.Levels[level])
+	}
+	checkRetention("retention.notification_history", c.Retention.NotificationHistory)
+
+	// Optional durations whose getters fall back to a default on bad input
+	durations := []struct {
+		path  string
+		value string
+	}{
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"jwt.impersonation_expiry", c.JWT.ImpersonationExpiry},
+		{"auth.totp.attempt_window", c.Auth.TOTP.AttemptWindow},
+		{"redis.health_check_interval", c.Redis.HealthCheckInterval},
+		{"websocket.ping_interval", c.WebSocket.PingInterval},
+		{"websocket.pong_timeout", c.WebSocket.PongTimeout},
+		{"alerts.interval", c.Alerts.Interval},
+		{"notifications.retry_backoff", c.Notifications.RetryBackoff},
+		{"notifications.http_timeout", c.Notifications.HTTPTimeout},
+		{"ingestion.async_buffer.flush_interval", c.Ingestion.AsyncBuffer.FlushInterval},
+		{"stats.cache_ttl", c.Stats.CacheTTL},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d.value); err != nil || parsed <= 0 {
+			addf("%s %q is not a valid duration", d.path, d.value)
+		}
+	}
+
+	if c.Database.Maintenance.Enabled {
+		if _, err := utils.ParseCron(c.Database.Maintenance.Schedule); err != nil {
+			addf("database.maintenance.schedule %q is not a valid cron expression: %v", c.Database.Maintenance.Schedule, err)
+		}
+	}
+
+	if _, err := time.LoadLocation(c.Stats.Timezone); err != nil {
+		addf("stats.timezone %q is not a known time zone (e.g. UTC, Asia/Jakarta)", c.Stats.Timezone)
+	}
+
+	if c.Auth.TOTP.Skew < 0 || c.Auth.TOTP.Skew > 10 {
+		addf("auth.totp.skew must be between 0 and 10, got %d", c.Auth.TOTP.Skew)
+	}
+	if c.Auth.TOTP.MaxAttempts < 0 {
+		addf("auth.totp.max_attempts must not be negative, got %d", c.Auth.TOTP.MaxAttempts)
+	}
+
+	if c.Password.MinLength < 0 {
+		addf("password_policy.min_length must not be negative, got %d", c.Password.MinLength)
+	}
+
+	if c.Query.DefaultRange != "" {
+		if d, err := ParseRetentionDuration(c.Query.DefaultRange); err != nil || d < 0 {
+			addf("query.default_range %q is not a valid duration (e.g. 7d, 24h, or 0 for none)", c.Query.DefaultRange)
+		}
+	}
+	if c.Query.MaxLimit < 0 {
+		addf("query.max_limit must not be negative, got %d", c.Query.MaxLimit)
+	}
+
+	ingestionLimits := []struct {
+		path  string
+		value int
+	}{
+		{"server.body_limit", c.Server.BodyLimit},
+		{"ingestion.max_body_bytes", c.Ingestion.MaxBodyBytes},
+		{"ingestion.max_message_bytes", c.Ingestion.MaxMessageBytes},
+		{"ingestion.max_metadata_bytes", c.Ingestion.MaxMetadataBytes},
+		{"ingestion.max_metadata_depth", c.Ingestion.MaxMetadataDepth},
+		{"ingestion.max_metadata_keys", c.Ingestion.MaxMetadataKeys},
+		{"ingestion.max_metadata_value_bytes", c.Ingestion.MaxMetadataValueBytes},
+		{"ingestion.message_truncate_length", c.Ingestion.MessageTruncateLength},
+		{"ingestion.fanout.workers", c.Ingestion.Fanout.Workers},
+		{"ingestion.fanout.queue_size", c.Ingestion.Fanout.QueueSize},
+		{"rate_limit.api.burst", c.RateLimit.API.Burst},
+	}
+	for _, l := range ingestionLimits {
+		if l.value < 0 {
+			addf("%s must not be negative, got %d", l.path, l.value)
+		}
+	}
+	if c.GetIngestionMaxBodyBytes() > c.GetBodyLimit() {
+		addf("ingestion.max_body_bytes (%d) must not exceed server.body_limit (%d)", c.GetIngestionMaxBodyBytes(), c.GetBodyLimit())
+	}
+	switch c.Ingestion.OversizePolicy {
+	case "", "reject", "truncate":
+	default:
+		addf("ingestion.oversize_policy must be reject or truncate, got %q", c.Ingestion.OversizePolicy)
+	}
+	switch c.Ingestion.MetadataValuePolicy {
+	case "", "reject", "truncate", "drop":
+	default:
+		addf("ingestion.metadata_value_policy must be reject, truncate or drop, got %q", c.Ingestion.MetadataValuePolicy)
+	}
+	for _, bound := range []struct{ path, value string }{
+		{"ingestion.timestamp.max_future", c.Ingestion.Timestamp.MaxFuture},
+		{"ingestion.timestamp.max_past", c.Ingestion.Timestamp.MaxPast},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		if d, err := ParseRetentionDuration(bound.value); err != nil || d < 0 {
+			addf("%s %q is not a valid duration (e.g. 1h, 30d)", bound.path, bound.value)
+		}
+	}
+	switch c.Ingestion.Timestamp.Policy {
+	case "", "reject", "clamp":
+	default:
+		addf("ingestion.timestamp.policy must be reject or clamp, got %q", c.Ingestion.Timestamp.Policy)
+	}
+
+	if c.WebSocket.SendBuffer < 0 {
+		addf("websocket.send_buffer must not be negative, got %d", c.WebSocket.SendBuffer)
+	}
+	switch c.WebSocket.SlowClientPolicy {
+	case "", "drop", "disconnect":
+	default:
+		addf("websocket.slow_client_policy must be drop or disconnect, got %q", c.WebSocket.SlowClientPolicy)
+	}
+
+	if _, err := redaction.Compile(c.Ingestion.Redaction.Detectors, c.Ingestion.Redaction.Patterns); err != nil {
+		addf("ingestion.redaction: %v", err)
+	}
+
+	if c.Enrichment.GeoIP.Enabled && c.Enrichment.GeoIP.DatabasePath == "" && c.Enrichment.GeoIP.ASNDatabasePath == "" {
+		addf("enrichment.geoip needs database_path or asn_database_path when enabled")
+	}
+
+	if c.PagerDuty.EventsURL != "" {
+		if u, err := url.Parse(c.PagerDuty.EventsURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			addf("pagerduty.events_url must be an http(s) URL, got %q", c.PagerDuty.EventsURL)
+		}
+	}
+
+	if c.SMTP.Host != "" {
+		if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+			addf("smtp.port must be between 1 and 65535, got %d", c.SMTP.Port)
+		}
+		if c.SMTP.From == "" && c.SMTP.Username == "" {
+			addf("smtp.from is required when smtp.host is set and there is no smtp.username")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate_DefaultConfig(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("Default config should be valid, got: %v", err)
+	}
+}
+
+func TestValidate_InvalidConfigs(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{
+			name:   "missing JWT secret",
+			modify: func(c *Config) { c.JWT.Secret = "  " },
+			want:   []string{"jwt.secret is required"},
+		},
+		{
+			name:   "malformed JWT expiry",
+			modify: func(c *Config) { c.JWT.Expiry = "one day" },
+			want:   []string{`jwt.expiry "one day"`},
+		},
+		{
+			name:   "negative JWT expiry",
+			modify: func(c *Config) { c.JWT.Expiry = "-1h" },
+			want:   []string{"jwt.expiry must be positive"},
+		},
+		{
+			name:   "malformed impersonation expiry",
+			modify: func(c *Config) { c.JWT.ImpersonationExpiry = "soon" },
+			want:   []string{`jwt.impersonation_expiry "soon"`},
+		},
+		{
+			name:   "port out of range",
+			modify: func(c *Config) { c.Server.Port = 70000 },
+			want:   []string{"server.port must be between 1 and 65535, got 70000"},
+		},
+		{
+			name: "TLS without files",
+			modify: func(c *Config) {
+				c.Server.TLS = TLSConfig{Enabled: true, KeyFile: "/nonexistent/key.pem"}
+			},
+			want: []string{
+				"server.tls.cert_file is required when TLS is enabled",
+				"server.tls.key_file: stat /nonexistent/key.pem: no such file or directory",
+			},
+		},
+		{
+			name:   "unknown log format",
+			modify: func(c *Config) { c.Server.LogFormat = "logfmt" },
+			want:   []string{`server.log_format must be text or json, got "logfmt"`},
+		},
+		{
+			name: "bad websocket backpressure",
+			modify: func(c *Config) {
+				c.WebSocket.SendBuffer = -1
+				c.WebSocket.SlowClientPolicy = "block"
+			},
+			want: []string{
+				"websocket.send_buffer must not be negative, got -1",
+				`websocket.slow_client_policy must be drop or disconnect, got "block"`,
+			},
+		},
+		{
+			name: "bad retention durations",
+			modify: func(c *Config) {
+				c.Retention.Default.MaxAge = "thirty days"
+				c.Retention.Levels["debug"] = RetentionPolicy{MaxAge: "7x"}
+				c.Retention.NotificationHistory.MaxCount = -1
+			},
+			want: []string{
+				`retention.default.max_age "thirty days"`,
+				`retention.levels.debug.max_age "7x"`,
+				"retention.notification_history.max_count must not be negative",
+			},
+		},
+		{
+			name: "retention below the floor",
+			modify: func(c *Config) {
+				c.Retention.Default.MaxAge = "1s"
+				c.Retention.Levels["debug"] = RetentionPolicy{MaxAge: "7d", MaxCount: 5}
+			},
+			want: []string{
+				"retention.default.max_age 1s is below the retention floor of 1d",
+				"retention.levels.debug.max_count 5 is below the retention floor of 100 logs",
+			},
+		},
+		{
+			name:   "malformed retention floor",
+			modify: func(c *Config) { c.Retention.Floor.MinAge = "a while" },
+			want:   []string{`retention.floor.min_age "a while"`},
+		},
+		{
+			name:   "pagerduty events url",
+			modify: func(c *Config) { c.PagerDuty.EventsURL = "events.pagerduty.com" },
+			want:   []string{`pagerduty.events_url must be an http(s) URL, got "events.pagerduty.com"`},
+		},
+		{
+			name:   "smtp without sender",
+			modify: func(c *Config) { c.SMTP.Host = "smtp.example.com"; c.SMTP.Port = 0 },
+			want: []string{
+				"smtp.port must be between 1 and 65535, got 0",
+				"smtp.from is required",
+			},
+		},
+		{
+			name:   "bad alerts interval",
+			modify: func(c *Config) { c.Alerts.Interval = "often" },
+			want:   []string{`alerts.interval "often"`},
+		},
+		{
+			name:   "unknown oversize policy",
+			modify: func(c *Config) { c.Ingestion.OversizePolicy = "drop" },
+			want:   []string{`ingestion.oversize_policy must be reject or truncate, got "drop"`},
+		},
+		{
+			name:   "negative message truncate length",
+			modify: func(c *Config) { c.Ingestion.MessageTruncateLength = -1 },
+			want:   []string{"ingestion.message_truncate_length must not be negative, got -1"},
+		},
+		{
+			name: "bad timestamp window",
+			modify: func(c *Config) {
+				c.Ingestion.Timestamp.MaxFuture = "soon"
+				c.Ingestion.Timestamp.MaxPast = "30d"
+				c.Ingestion.Timestamp.Policy = "ignore"
+			},
+			want: []string{
+				`ingestion.timestamp.max_future "soon" is not a valid duration`,
+				`ingestion.timestamp.policy must be reject or clamp, got "ignore"`,
+			},
+		},
+		{
+			name:   "unknown metadata value policy",
+			modify: func(c *Config) { c.Ingestion.MetadataValuePolicy = "compress" },
+			want:   []string{`ingestion.metadata_value_policy must be reject, truncate or drop, got "compress"`},
+		},
+		{
+			name: "ingestion body limit above the server's",
+			modify: func(c *Config) {
+				c.Server.BodyLimit = 1024
+				c.Ingestion.MaxBodyBytes = 2048
+			},
+			want: []string{"ingestion.max_body_bytes (2048) must not exceed server.body_limit (1024)"},
+		},
+		{
+			name: "bad redaction rules",
+			modify: func(c *Config) {
+				c.Ingestion.Redaction.Detectors = []string{"passport"}
+			},
+			want: []string{`ingestion.redaction: unknown redaction detector "passport"`},
+		},
+		{
+			name:   "bad maintenance schedule",
+			modify: func(c *Config) { c.Database.Maintenance.Schedule = "every night" },
+			want:   []string{`database.maintenance.schedule "every night" is not a valid cron expression`},
+		},
+		{
+			name:   "unknown stats time zone",
+			modify: func(c *Config) { c.Stats.Timezone = "Mars/Olympus" },
+			want:   []string{`stats.timezone "Mars/Olympus" is not a known time zone`},
+		},
+		{
+			name:   "TOTP skew out of range",
+			modify: func(c *Config) { c.Auth.TOTP.Skew = 30 },
+			want:   []string{"auth.totp.skew must be between 0 and 10, got 30"},
+		},
+		{
+			name: "bad TOTP attempt limit",
+			modify: func(c *Config) {
+				c.Auth.TOTP.MaxAttempts = -1
+				c.Auth.TOTP.AttemptWindow = "a while"
+			},
+			want: []string{
+				"auth.totp.max_attempts must not be negative, got -1",
+				`auth.totp.attempt_window "a while" is not a valid duration`,
+			},
+		},
+		{
+			name:   "negative password length",
+			modify: func(c *Config) { c.Password.MinLength = -1 },
+			want:   []string{"password_policy.min_length must not be negative, got -1"},
+		},
+		{
+			name: "bad query bounds",
+			modify: func(c *Config) {
+				c.Query.DefaultRange = "a week"
+				c.Query.MaxLimit = -5
+			},
+			want: []string{
+				`query.default_range "a week"`,
+				"query.max_limit must not be negative, got -5",
+			},
+		},
+		{
+			name: "geoip enabled without a database",
+			modify: func(c *Config) {
+				c.Enrichment.GeoIP.Enabled = true
+				c.Enrichment.GeoIP.DatabasePath = ""
+			},
+			want: []string{"enrichment.geoip needs database_path or asn_database_path when enabled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected *ValidationError, got %T", err)
+			}
+			if len(verr.Problems) != len(tt.want) {
+				t.Errorf("Expected %d problems, got %d: %v", len(tt.want), len(verr.Problems), verr.Problems)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to mention %q, got:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.JWT.Secret = ""
+	cfg.JWT.Expiry = "soon"
+	cfg.Server.Port = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	if got := strings.Count(err.Error(), "\n  - "); got != 3 {
+		t.Errorf("Expected 3 listed problems, got %d:\n%v", got, err)
+	}
+}
+
+func TestParseRetentionDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"d", 0, true},
+		{"-3d", 0, true},
+		{"week", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRetentionDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRetentionDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRetentionDuration(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
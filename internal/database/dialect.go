@@ -0,0 +1,143 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Supported storage drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Dialect captures the SQL differences between storage backends. Repositories
+// are written with "?" placeholders; backends that need another style get
+// their queries rebound by the connection (see rebind.go).
+type Dialect interface {
+	// Name returns the driver name, e.g. "sqlite"
+	Name() string
+	// Rebind rewrites "?" placeholders into the backend's style
+	Rebind(query string) string
+	// JSONExtract returns an expression reading a top-level text key from a JSON column
+	JSONExtract(column, key string) string
+	// JSONKeys returns a FROM item named alias whose key column lists the
+	// top-level keys of a JSON object column; other values yield no rows
+	JSONKeys(column, alias string) string
+	// Upsert returns the conflict clause updating updateColumns when conflictColumns collide
+	Upsert(conflictColumns, updateColumns []string) string
+	// MigrationsTableDDL returns the statement creating the migration tracking table
+	MigrationsTableDDL() string
+}
+
+// DialectFor returns the dialect for a driver name
+func DialectFor(driver string) (Dialect, error) {
+	switch driver {
+	case "", DriverSQLite:
+		return sqliteDialect{}, nil
+	case DriverPostgres:
+		return postgresDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return DriverSQLite }
+
+func (sqliteDialect) Rebind(query string) string { return query }
+
+func (sqliteDialect) JSONExtract(column, key string) string {
+	return fmt.Sprintf("json_extract(%s, '$.%s')", column, key)
+}
+
+// JSONKeys guards json_each, which fails on malformed JSON and lists array
+// indexes as keys
+func (sqliteDialect) JSONKeys(column, alias string) string {
+	return fmt.Sprintf("json_each(CASE WHEN json_valid(%[1]s) AND json_type(%[1]s) = 'object' THEN %[1]s END) AS %[2]s", column, alias)
+}
+
+func (sqliteDialect) Upsert(conflictColumns, updateColumns []string) string {
+	return upsertClause(conflictColumns, updateColumns)
+}
+
+func (sqliteDialect) MigrationsTableDDL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			migration TEXT NOT NULL UNIQUE,
+			batch INTEGER NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return DriverPostgres }
+
+// Rebind numbers placeholders as $1, $2, ... leaving "?" inside quoted
+// strings and identifiers untouched
+func (postgresDialect) Rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func (postgresDialect) JSONExtract(column, key string) string {
+	return fmt.Sprintf("(%s::jsonb ->> '%s')", column, key)
+}
+
+func (postgresDialect) JSONKeys(column, alias string) string {
+	return fmt.Sprintf("jsonb_object_keys(CASE WHEN jsonb_typeof(%[1]s::jsonb) = 'object' THEN %[1]s::jsonb END) AS %[2]s(key)", column, alias)
+}
+
+func (postgresDialect) Upsert(conflictColumns, updateColumns []string) string {
+	return upsertClause(conflictColumns, updateColumns)
+}
+
+func (postgresDialect) MigrationsTableDDL() string {
+	return `
+		CREATE TABLE IF NOT EXISTS migrations (
+			id SERIAL PRIMARY KEY,
+			migration TEXT NOT NULL UNIQUE,
+			batch INTEGER NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+}
+
+// upsertClause builds the ON CONFLICT ... DO UPDATE syntax both backends share
+func upsertClause(conflictColumns, updateColumns []string) string {
+	sets := make([]string, len(updateColumns))
+	for i, col := range updateColumns {
+		sets[i] = col + " = excluded." + col
+	}
+	return "ON CONFLICT(" + strings.Join(conflictColumns, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", ")
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestPostgresDialect_Rebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT * FROM logs WHERE id = ?", "SELECT * FROM logs WHERE id = $1"},
+		{"UPDATE t SET a = ?, b = ? WHERE c IN (?,?)", "UPDATE t SET a = $1, b = $2 WHERE c IN ($3,$4)"},
+		{"SELECT '?' AS q, \"col?\" FROM t WHERE x = ?", "SELECT '?' AS q, \"col?\" FROM t WHERE x = $1"},
+	}
+
+	d := postgresDialect{}
+	for _, tt := range tests {
+		if got := d.Rebind(tt.query); got != tt.want {
+			t.Errorf("Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDialectFor(t *testing.T) {
+	for driver, want := range map[string]string{"": DriverSQLite, "sqlite": DriverSQLite, "postgres": DriverPostgres} {
+		d, err := DialectFor(driver)
+		if err != nil {
+			t.Fatalf("DialectFor(%q) failed: %v", driver, err)
+		}
+		if d.Name() != want {
+			t.Errorf("DialectFor(%q) = %s, want %s", driver, d.Name(), want)
+		}
+	}
+
+	if _, err := DialectFor("oracle"); err == nil {
+		t.Error("Expected error for unsupported driver")
+	}
+}
+
+func TestUpsertClause(t *testing.T) {
+	got := postgresDialect{}.Upsert([]string{"project_id", "key_prefix"}, []string{"last_used_at"})
+	want := "ON CONFLICT(project_id, key_prefix) DO UPDATE SET last_used_at = excluded.last_used_at"
+	if got != want {
+		t.Errorf("Upsert = %q, want %q", got, want)
+	}
+}
+
+func TestPostgresDialect_JSONKeys(t *testing.T) {
+	got := postgresDialect{}.JSONKeys("s.metadata", "k")
+	want := "jsonb_object_keys(CASE WHEN jsonb_typeof(s.metadata::jsonb) = 'object' THEN s.metadata::jsonb END) AS k(key)"
+	if got != want {
+		t.Errorf("JSONKeys = %q, want %q", got, want)
+	}
+}
+
+// recordingDialect remembers every rebound statement
+type recordingDialect struct {
+	postgresDialect
+	rebound []string
+}
+
+func (d *recordingDialect) Rebind(query string) string {
+	query = d.postgresDialect.Rebind(query)
+	d.rebound = append(d.rebound, query)
+	return query
+}
+
+// SQLite also understands $N placeholders, so it can stand in for Postgres to
+// check that statements are rebound before reaching the driver
+func TestRebindConnector(t *testing.T) {
+	probe, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open probe: %v", err)
+	}
+	drv := probe.Driver()
+	probe.Close()
+
+	dialect := &recordingDialect{}
+	db := sql.OpenDB(&rebindConnector{driver: drv, dsn: ":memory:", dialect: dialect})
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE kv (k TEXT PRIMARY KEY, v TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO kv (k, v) VALUES (?, ?)", "a", "1"); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE kv SET v = ? WHERE k = ?", "2", "a"); err != nil {
+		t.Fatalf("Failed to update in tx: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	var v string
+	if err := db.QueryRow("SELECT v FROM kv WHERE k = ?", "a").Scan(&v); err != nil {
+		t.Fatalf("Failed to query: %v", err)
+	}
+	if v != "2" {
+		t.Errorf("Expected 2, got %s", v)
+	}
+
+	want := "UPDATE kv SET v = $1 WHERE k = $2"
+	found := false
+	for _, q := range dialect.rebound {
+		if q == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected %q to be rebound, saw %v", want, dialect.rebound)
+	}
+}
+
+func TestMigrator_UsesDialectDDL(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if err := NewMigratorForDialect(db, sqliteDialect{}).Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var name string
+	if err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'migrations'").Scan(&name); err != nil {
+		t.Fatalf("Expected migrations table: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Maintenance methods
+const (
+	MaintenanceVacuum            = "vacuum"             // SQLite: rebuild the whole file
+	MaintenanceIncrementalVacuum = "incremental_vacuum" // SQLite with auto_vacuum=INCREMENTAL: release free pages only
+	MaintenanceVacuumAnalyze     = "vacuum_analyze"     // Postgres
+)
+
+// MaintenanceResult reports what a maintenance run did
+type MaintenanceResult struct {
+	Method         string    `json:"method"`
+	SizeBefore     int64     `json:"size_before_bytes"`
+	SizeAfter      int64     `json:"size_after_bytes"`
+	BytesReclaimed int64     `json:"bytes_reclaimed"`
+	StartedAt      time.Time `json:"started_at"`
+	DurationMS     int64     `json:"duration_ms"`
+}
+
+// Maintain compacts the database and refreshes the query planner's
+// statistics. Large deletes leave SQLite's file at its old size and its pages
+// scattered, which VACUUM undoes; it rewrites the whole file and blocks
+// writers while it runs, so it belongs at a quiet time.
+func (db *DB) Maintain(ctx context.Context) (*MaintenanceResult, error) {
+	result := &MaintenanceResult{StartedAt: time.Now()}
+
+	var err error
+	if db.Dialect != nil && db.Dialect.Name() == DriverPostgres {
+		err = db.maintainPostgres(ctx, result)
+	} else {
+		err = db.maintainSQLite(ctx, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.BytesReclaimed = result.SizeBefore - result.SizeAfter
+	if result.BytesReclaimed < 0 {
+		result.BytesReclaimed = 0 // Logs written during the run can outgrow what was freed
+	}
+	result.DurationMS = time.Since(result.StartedAt).Milliseconds()
+	return result, nil
+}
+
+func (db *DB) maintainSQLite(ctx context.Context, result *MaintenanceResult) error {
+	var err error
+	if result.SizeBefore, err = db.sqliteSize(ctx); err != nil {
+		return err
+	}
+
+	// auto_vacuum is 2 for INCREMENTAL, where freed pages can be released
+	// without rewriting the file
+	var autoVacuum int
+	if err := db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&autoVacuum); err != nil {
+		return fmt.Errorf("failed to read auto_vacuum: %w", err)
+	}
+	result.Method = MaintenanceVacuum
+	statement := "VACUUM"
+	if autoVacuum == 2 {
+		result.Method = MaintenanceIncrementalVacuum
+		statement = "PRAGMA incremental_vacuum"
+	}
+	if _, err := db.ExecContext(ctx, statement); err != nil {
+		return fmt.Errorf("%s failed: %w", result.Method, err)
+	}
+	if _, err := db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return fmt.Errorf("analyze failed: %w", err)
+	}
+
+	// VACUUM goes through the WAL; fold it back so the file itself shrinks.
+	// This is a no-op outside WAL mode.
+	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("wal checkpoint failed: %w", err)
+	}
+
+	result.SizeAfter, err = db.sqliteSize(ctx)
+	return err
+}
+
+// sqliteSize returns the size of the database's pages, which is the file
+// size once the WAL is checkpointed
+func (db *DB) sqliteSize(ctx context.Context) (int64, error) {
+	var pageCount, pageSize int64
+	if err := db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, fmt.Errorf("failed to read page_count: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page_size: %w", err)
+	}
+	return pageCount * pageSize, nil
+}
+
+func (db *DB) maintainPostgres(ctx context.Context, result *MaintenanceResult) error {
+	const sizeQuery = "SELECT pg_database_size(current_database())"
+
+	result.Method = MaintenanceVacuumAnalyze
+	if err := db.QueryRowContext(ctx, sizeQuery).Scan(&result.SizeBefore); err != nil {
+		return fmt.Errorf("failed to read database size: %w", err)
+	}
+	// Plain VACUUM only marks space reusable, so the size rarely drops; FULL
+	// would return it but locks every table for the duration
+	if _, err := db.ExecContext(ctx, "VACUUM ANALYZE"); err != nil {
+		return fmt.Errorf("vacuum analyze failed: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, sizeQuery).Scan(&result.SizeAfter); err != nil {
+		return fmt.Errorf("failed to read database size: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package database_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"central-logs/internal/database"
+)
+
+func TestMaintain_ReclaimsDeletedRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vacuum.db")
+	db, err := database.NewWithOptions(path, database.DefaultSQLiteOptions())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE logs (id INTEGER PRIMARY KEY, message TEXT NOT NULL)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	message := strings.Repeat("x", 1000)
+	for i := 0; i < 2000; i++ {
+		if _, err := db.Exec("INSERT INTO logs (message) VALUES (?)", message); err != nil {
+			t.Fatalf("Failed to insert: %v", err)
+		}
+	}
+	if _, err := db.Exec("DELETE FROM logs WHERE id > 100"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+
+	result, err := db.Maintain(context.Background())
+	if err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+	if result.Method != database.MaintenanceVacuum {
+		t.Errorf("Expected a full vacuum, got %s", result.Method)
+	}
+	if result.BytesReclaimed <= 0 || result.SizeAfter >= result.SizeBefore {
+		t.Errorf("Expected the deleted rows' space back, got %+v", result)
+	}
+
+	// The checkpoint leaves the file itself at the compacted size
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat database: %v", err)
+	}
+	if info.Size() != result.SizeAfter {
+		t.Errorf("Expected a %d byte file, got %d", result.SizeAfter, info.Size())
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM logs").Scan(&count); err != nil || count != 100 {
+		t.Errorf("Expected the remaining 100 rows to survive, got %d (%v)", count, err)
+	}
+}
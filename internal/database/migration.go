@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"log"
 	"sort"
 	"time"
 )
@@ -25,16 +26,31 @@ type MigrationRecord struct {
 	CreatedAt time.Time
 }
 
+// MigrationState describes a registered migration and whether it has run
+type MigrationState struct {
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	Batch     int        `json:"batch,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
 // Migrator handles running and rolling back migrations
 type Migrator struct {
 	db         *sql.DB
+	dialect    Dialect
 	migrations []Migration
 }
 
-// NewMigrator creates a new migrator instance
+// NewMigrator creates a new migrator instance for a SQLite database
 func NewMigrator(db *sql.DB) *Migrator {
+	return NewMigratorForDialect(db, sqliteDialect{})
+}
+
+// NewMigratorForDialect creates a migrator for a database of the given dialect
+func NewMigratorForDialect(db *sql.DB, dialect Dialect) *Migrator {
 	return &Migrator{
 		db:         db,
+		dialect:    dialect,
 		migrations: []Migration{},
 	}
 }
@@ -46,15 +62,7 @@ func (m *Migrator) Register(migration Migration) {
 
 // createMigrationsTable creates the migrations tracking table if it doesn't exist
 func (m *Migrator) createMigrationsTable() error {
-	query := `
-		CREATE TABLE IF NOT EXISTS migrations (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			migration TEXT NOT NULL UNIQUE,
-			batch INTEGER NOT NULL,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	_, err := m.db.Exec(query)
+	_, err := m.db.Exec(m.dialect.MigrationsTableDDL())
 	return err
 }
 
@@ -152,7 +160,7 @@ func (m *Migrator) Run() error {
 		}
 
 		pendingCount++
-		fmt.Printf("Migrating: %s\n", migration.Name())
+		log.Printf("Migrating: %s", migration.Name())
 
 		// Start transaction
 		tx, err := m.db.Begin()
@@ -177,11 +185,11 @@ func (m *Migrator) Run() error {
 			return fmt.Errorf("failed to commit migration %s: %w", migration.Name(), err)
 		}
 
-		fmt.Printf("Migrated:  %s\n", migration.Name())
+		log.Printf("Migrated:  %s", migration.Name())
 	}
 
 	if pendingCount == 0 {
-		fmt.Println("Nothing to migrate.")
+		log.Println("Nothing to migrate.")
 	}
 
 	return nil
@@ -217,20 +225,26 @@ func (m *Migrator) Rollback() error {
 		migrationMap[migration.Name()] = migration
 	}
 
-	// Rollback migrations
+	// Resolve the whole batch before touching the schema
+	toRollback := make([]Migration, 0, len(migrationNames))
 	for _, name := range migrationNames {
 		migration, ok := migrationMap[name]
 		if !ok {
 			return fmt.Errorf("migration %s not found in registered migrations", name)
 		}
+		toRollback = append(toRollback, migration)
+	}
 
-		fmt.Printf("Rolling back: %s\n", name)
+	// The batch is reverted in a single transaction so a failing Down
+	// leaves both the schema and the tracking table as they were
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin rollback of batch %d: %w", batch, err)
+	}
 
-		// Start transaction
-		tx, err := m.db.Begin()
-		if err != nil {
-			return fmt.Errorf("failed to begin transaction for %s: %w", name, err)
-		}
+	for _, migration := range toRollback {
+		name := migration.Name()
+		fmt.Printf("Rolling back: %s\n", name)
 
 		// Run rollback
 		if err := migration.Down(tx); err != nil {
@@ -243,13 +257,14 @@ func (m *Migrator) Rollback() error {
 			tx.Rollback()
 			return fmt.Errorf("failed to remove migration record %s: %w", name, err)
 		}
+	}
 
-		// Commit transaction
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit rollback %s: %w", name, err)
-		}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollback of batch %d: %w", batch, err)
+	}
 
-		fmt.Printf("Rolled back:  %s\n", name)
+	for _, migration := range toRollback {
+		fmt.Printf("Rolled back:  %s\n", migration.Name())
 	}
 
 	return nil
@@ -286,3 +301,47 @@ func (m *Migrator) Status() error {
 
 	return nil
 }
+
+// States returns every registered migration in order, with the batch and
+// time it was applied if it has run
+func (m *Migrator) States() ([]MigrationState, error) {
+	if err := m.createMigrationsTable(); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	rows, err := m.db.Query("SELECT migration, batch, created_at FROM migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]MigrationRecord)
+	for rows.Next() {
+		var record MigrationRecord
+		if err := rows.Scan(&record.Migration, &record.Batch, &record.CreatedAt); err != nil {
+			return nil, err
+		}
+		applied[record.Migration] = record
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(m.migrations, func(i, j int) bool {
+		return m.migrations[i].Name() < m.migrations[j].Name()
+	})
+
+	states := make([]MigrationState, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		state := MigrationState{Name: migration.Name()}
+		if record, ok := applied[migration.Name()]; ok {
+			appliedAt := record.CreatedAt
+			state.Applied = true
+			state.Batch = record.Batch
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
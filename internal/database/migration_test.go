@@ -0,0 +1,142 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// testMigration creates a table on Up and drops it on Down
+type testMigration struct {
+	name    string
+	table   string
+	downErr error
+}
+
+func (m *testMigration) Name() string { return m.name }
+
+func (m *testMigration) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE TABLE " + m.table + " (id INTEGER PRIMARY KEY)")
+	return err
+}
+
+func (m *testMigration) Down(tx *sql.Tx) error {
+	if _, err := tx.Exec("DROP TABLE " + m.table); err != nil {
+		return err
+	}
+	return m.downErr
+}
+
+func openMigrationTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to check table %s: %v", table, err)
+	}
+	return count == 1
+}
+
+func TestMigrator_RunThenRollback(t *testing.T) {
+	db := openMigrationTestDB(t)
+	defer db.Close()
+
+	first := []Migration{
+		&testMigration{name: "20250101000001_create_a", table: "a"},
+		&testMigration{name: "20250101000002_create_b", table: "b"},
+	}
+	second := &testMigration{name: "20250101000003_create_c", table: "c"}
+
+	// Batch 1
+	migrator := NewMigrator(db)
+	for _, m := range first {
+		migrator.Register(m)
+	}
+	if err := migrator.Run(); err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+
+	// Batch 2
+	migrator.Register(second)
+	if err := migrator.Run(); err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+
+	states, err := migrator.States()
+	if err != nil {
+		t.Fatalf("States failed: %v", err)
+	}
+	if len(states) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(states))
+	}
+	for i, wantBatch := range []int{1, 1, 2} {
+		if !states[i].Applied || states[i].AppliedAt == nil {
+			t.Errorf("Expected %s to be applied with a timestamp", states[i].Name)
+		}
+		if states[i].Batch != wantBatch {
+			t.Errorf("Expected %s in batch %d, got %d", states[i].Name, wantBatch, states[i].Batch)
+		}
+	}
+
+	// Rolling back only reverts the last batch
+	if err := migrator.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if tableExists(t, db, "c") {
+		t.Error("Expected table c to be dropped")
+	}
+	if !tableExists(t, db, "a") || !tableExists(t, db, "b") {
+		t.Error("Expected batch 1 tables to remain")
+	}
+
+	states, err = migrator.States()
+	if err != nil {
+		t.Fatalf("States failed: %v", err)
+	}
+	if states[2].Applied || states[2].AppliedAt != nil {
+		t.Errorf("Expected %s to be pending after rollback", states[2].Name)
+	}
+	if !states[0].Applied || !states[1].Applied {
+		t.Error("Expected batch 1 to stay applied")
+	}
+}
+
+func TestMigrator_RollbackIsAtomic(t *testing.T) {
+	db := openMigrationTestDB(t)
+	defer db.Close()
+
+	migrator := NewMigrator(db)
+	// Rolled back last (newest first), after b has already been dropped
+	migrator.Register(&testMigration{name: "20250101000001_create_a", table: "a", downErr: errors.New("boom")})
+	migrator.Register(&testMigration{name: "20250101000002_create_b", table: "b"})
+
+	if err := migrator.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if err := migrator.Rollback(); err == nil {
+		t.Fatal("Expected rollback to fail")
+	}
+
+	if !tableExists(t, db, "a") || !tableExists(t, db, "b") {
+		t.Error("A failed rollback should leave every table in place")
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&count); err != nil {
+		t.Fatalf("Failed to count migration records: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected both migration records to remain, got %d", count)
+	}
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import "database/sql"
+
+type CreateAlertRulesTable struct{}
+
+func (m *CreateAlertRulesTable) Name() string {
+	return "20250201000001_create_alert_rules_table"
+}
+
+func (m *CreateAlertRulesTable) Up(tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS alert_rules (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			min_level TEXT NOT NULL DEFAULT 'ERROR',
+			source TEXT,
+			threshold INTEGER NOT NULL,
+			window_seconds INTEGER NOT NULL,
+			channel_id TEXT,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			last_fired_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+			FOREIGN KEY (channel_id) REFERENCES channels(id) ON DELETE SET NULL
+		)
+	`
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX IF NOT EXISTS idx_alert_rules_project_id ON alert_rules(project_id)")
+	return err
+}
+
+func (m *CreateAlertRulesTable) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS alert_rules")
+	return err
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import "database/sql"
+
+type CreateFailedNotificationsTable struct{}
+
+func (m *CreateFailedNotificationsTable) Name() string {
+	return "20250201000002_create_failed_notifications_table"
+}
+
+func (m *CreateFailedNotificationsTable) Up(tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS failed_notifications (
+			id TEXT PRIMARY KEY,
+			channel_id TEXT NOT NULL,
+			project_id TEXT NOT NULL,
+			log_id TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			replayed_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (channel_id) REFERENCES channels(id) ON DELETE CASCADE
+		)
+	`
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX IF NOT EXISTS idx_failed_notifications_channel_id ON failed_notifications(channel_id, created_at)")
+	return err
+}
+
+func (m *CreateFailedNotificationsTable) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS failed_notifications")
+	return err
+}
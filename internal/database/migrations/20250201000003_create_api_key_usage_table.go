@@ -0,0 +1,28 @@
+package migrations
+
+import "database/sql"
+
+type CreateAPIKeyUsageTable struct{}
+
+func (m *CreateAPIKeyUsageTable) Name() string {
+	return "20250201000003_create_api_key_usage_table"
+}
+
+func (m *CreateAPIKeyUsageTable) Up(tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS api_key_usage (
+			project_id TEXT NOT NULL,
+			key_prefix TEXT NOT NULL,
+			last_used_at DATETIME NOT NULL,
+			PRIMARY KEY (project_id, key_prefix),
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		)
+	`
+	_, err := tx.Exec(query)
+	return err
+}
+
+func (m *CreateAPIKeyUsageTable) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS api_key_usage")
+	return err
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddRequestIDToMCPActivityLogs struct{}
+
+func (m *AddRequestIDToMCPActivityLogs) Name() string {
+	return "20250201000004_add_request_id_to_mcp_activity_logs"
+}
+
+func (m *AddRequestIDToMCPActivityLogs) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE mcp_activity_logs ADD COLUMN request_id TEXT")
+	return err
+}
+
+func (m *AddRequestIDToMCPActivityLogs) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE mcp_activity_logs DROP COLUMN request_id")
+	return err
+}
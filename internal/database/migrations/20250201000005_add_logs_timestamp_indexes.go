@@ -0,0 +1,39 @@
+package migrations
+
+import "database/sql"
+
+type AddLogsTimestampIndexes struct{}
+
+func (m *AddLogsTimestampIndexes) Name() string {
+	return "20250201000005_add_logs_timestamp_indexes"
+}
+
+func (m *AddLogsTimestampIndexes) Up(tx *sql.Tx) error {
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_logs_timestamp ON logs(timestamp)`,
+		`CREATE INDEX IF NOT EXISTS idx_logs_project_timestamp ON logs(project_id, timestamp)`,
+	}
+
+	for _, idx := range indexes {
+		if _, err := tx.Exec(idx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *AddLogsTimestampIndexes) Down(tx *sql.Tx) error {
+	indexes := []string{
+		`DROP INDEX IF EXISTS idx_logs_timestamp`,
+		`DROP INDEX IF EXISTS idx_logs_project_timestamp`,
+	}
+
+	for _, idx := range indexes {
+		if _, err := tx.Exec(idx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddRedactionConfigToProjects struct{}
+
+func (m *AddRedactionConfigToProjects) Name() string {
+	return "20250201000006_add_redaction_config_to_projects"
+}
+
+func (m *AddRedactionConfigToProjects) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN redaction_config TEXT")
+	return err
+}
+
+func (m *AddRedactionConfigToProjects) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN redaction_config")
+	return err
+}
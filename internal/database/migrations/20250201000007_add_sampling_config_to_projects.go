@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddSamplingConfigToProjects struct{}
+
+func (m *AddSamplingConfigToProjects) Name() string {
+	return "20250201000007_add_sampling_config_to_projects"
+}
+
+func (m *AddSamplingConfigToProjects) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN sampling_config TEXT")
+	return err
+}
+
+func (m *AddSamplingConfigToProjects) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN sampling_config")
+	return err
+}
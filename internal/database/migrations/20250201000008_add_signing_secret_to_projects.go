@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddSigningSecretToProjects struct{}
+
+func (m *AddSigningSecretToProjects) Name() string {
+	return "20250201000008_add_signing_secret_to_projects"
+}
+
+func (m *AddSigningSecretToProjects) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN signing_secret TEXT")
+	return err
+}
+
+func (m *AddSigningSecretToProjects) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN signing_secret")
+	return err
+}
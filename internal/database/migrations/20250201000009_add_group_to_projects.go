@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddGroupToProjects struct{}
+
+func (m *AddGroupToProjects) Name() string {
+	return "20250201000009_add_group_to_projects"
+}
+
+func (m *AddGroupToProjects) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN group_name TEXT")
+	return err
+}
+
+func (m *AddGroupToProjects) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN group_name")
+	return err
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import "database/sql"
+
+type CreateAuditLogsTable struct{}
+
+func (m *CreateAuditLogsTable) Name() string {
+	return "20250201000010_create_audit_logs_table"
+}
+
+func (m *CreateAuditLogsTable) Up(tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS audit_logs (
+			id TEXT PRIMARY KEY,
+			actor_id TEXT NOT NULL,
+			actor_name TEXT NOT NULL,
+			action TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			details TEXT,
+			ip_address TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+
+	indexes := []string{
+		"CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id, created_at)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action, created_at)",
+	}
+	for _, idx := range indexes {
+		if _, err := tx.Exec(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *CreateAuditLogsTable) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS audit_logs")
+	return err
+}
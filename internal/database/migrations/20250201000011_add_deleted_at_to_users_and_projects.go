@@ -0,0 +1,25 @@
+package migrations
+
+import "database/sql"
+
+type AddDeletedAtToUsersAndProjects struct{}
+
+func (m *AddDeletedAtToUsersAndProjects) Name() string {
+	return "20250201000011_add_deleted_at_to_users_and_projects"
+}
+
+func (m *AddDeletedAtToUsersAndProjects) Up(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE users ADD COLUMN deleted_at DATETIME"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN deleted_at DATETIME")
+	return err
+}
+
+func (m *AddDeletedAtToUsersAndProjects) Down(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE projects DROP COLUMN deleted_at"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE users DROP COLUMN deleted_at")
+	return err
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddLastLoginAtToUsers struct{}
+
+func (m *AddLastLoginAtToUsers) Name() string {
+	return "20250201000012_add_last_login_at_to_users"
+}
+
+func (m *AddLastLoginAtToUsers) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE users ADD COLUMN last_login_at DATETIME")
+	return err
+}
+
+func (m *AddLastLoginAtToUsers) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE users DROP COLUMN last_login_at")
+	return err
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import "database/sql"
+
+type AddTokenRevocation struct{}
+
+func (m *AddTokenRevocation) Name() string {
+	return "20250201000013_add_token_revocation"
+}
+
+func (m *AddTokenRevocation) Up(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	query := `
+		CREATE TABLE IF NOT EXISTS revoked_tokens (
+			jti TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at)")
+	return err
+}
+
+func (m *AddTokenRevocation) Down(tx *sql.Tx) error {
+	if _, err := tx.Exec("DROP TABLE IF EXISTS revoked_tokens"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE users DROP COLUMN token_version")
+	return err
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import "database/sql"
+
+type CreateChannelDeliveriesTable struct{}
+
+func (m *CreateChannelDeliveriesTable) Name() string {
+	return "20250201000014_create_channel_deliveries_table"
+}
+
+func (m *CreateChannelDeliveriesTable) Up(tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS channel_deliveries (
+			id TEXT PRIMARY KEY,
+			channel_id TEXT NOT NULL,
+			success BOOLEAN NOT NULL,
+			error TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (channel_id) REFERENCES channels(id) ON DELETE CASCADE
+		)
+	`
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_channel_deliveries_channel_id ON channel_deliveries(channel_id, created_at)")
+	return err
+}
+
+func (m *CreateChannelDeliveriesTable) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS channel_deliveries")
+	return err
+}
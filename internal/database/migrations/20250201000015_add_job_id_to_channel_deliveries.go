@@ -0,0 +1,25 @@
+package migrations
+
+import "database/sql"
+
+type AddJobIDToChannelDeliveries struct{}
+
+func (m *AddJobIDToChannelDeliveries) Name() string {
+	return "20250201000015_add_job_id_to_channel_deliveries"
+}
+
+func (m *AddJobIDToChannelDeliveries) Up(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE channel_deliveries ADD COLUMN job_id TEXT"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_channel_deliveries_job_id ON channel_deliveries(job_id)")
+	return err
+}
+
+func (m *AddJobIDToChannelDeliveries) Down(tx *sql.Tx) error {
+	if _, err := tx.Exec("DROP INDEX IF EXISTS idx_channel_deliveries_job_id"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE channel_deliveries DROP COLUMN job_id")
+	return err
+}
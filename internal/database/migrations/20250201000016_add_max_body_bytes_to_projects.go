@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddMaxBodyBytesToProjects struct{}
+
+func (m *AddMaxBodyBytesToProjects) Name() string {
+	return "20250201000016_add_max_body_bytes_to_projects"
+}
+
+func (m *AddMaxBodyBytesToProjects) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN max_body_bytes INTEGER")
+	return err
+}
+
+func (m *AddMaxBodyBytesToProjects) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN max_body_bytes")
+	return err
+}
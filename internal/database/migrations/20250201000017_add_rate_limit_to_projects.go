@@ -0,0 +1,25 @@
+package migrations
+
+import "database/sql"
+
+type AddRateLimitToProjects struct{}
+
+func (m *AddRateLimitToProjects) Name() string {
+	return "20250201000017_add_rate_limit_to_projects"
+}
+
+func (m *AddRateLimitToProjects) Up(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE projects ADD COLUMN rate_limit_per_minute INTEGER"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN rate_limit_burst INTEGER")
+	return err
+}
+
+func (m *AddRateLimitToProjects) Down(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE projects DROP COLUMN rate_limit_burst"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN rate_limit_per_minute")
+	return err
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddIngestionPausedToProjects struct{}
+
+func (m *AddIngestionPausedToProjects) Name() string {
+	return "20250201000018_add_ingestion_paused_to_projects"
+}
+
+func (m *AddIngestionPausedToProjects) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN ingestion_paused INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+func (m *AddIngestionPausedToProjects) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN ingestion_paused")
+	return err
+}
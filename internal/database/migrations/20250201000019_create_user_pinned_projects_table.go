@@ -0,0 +1,28 @@
+package migrations
+
+import "database/sql"
+
+type CreateUserPinnedProjectsTable struct{}
+
+func (m *CreateUserPinnedProjectsTable) Name() string {
+	return "20250201000019_create_user_pinned_projects_table"
+}
+
+func (m *CreateUserPinnedProjectsTable) Up(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS user_pinned_projects (
+			user_id TEXT NOT NULL,
+			project_id TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, project_id),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		)
+	`)
+	return err
+}
+
+func (m *CreateUserPinnedProjectsTable) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS user_pinned_projects")
+	return err
+}
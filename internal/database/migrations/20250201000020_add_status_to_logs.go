@@ -0,0 +1,39 @@
+package migrations
+
+import "database/sql"
+
+type AddStatusToLogs struct{}
+
+func (m *AddStatusToLogs) Name() string {
+	return "20250201000020_add_status_to_logs"
+}
+
+func (m *AddStatusToLogs) Up(tx *sql.Tx) error {
+	statements := []string{
+		"ALTER TABLE logs ADD COLUMN status TEXT NOT NULL DEFAULT 'new'",
+		"ALTER TABLE logs ADD COLUMN acknowledged_by TEXT",
+		"ALTER TABLE logs ADD COLUMN acknowledged_at DATETIME",
+		"CREATE INDEX IF NOT EXISTS idx_logs_project_status ON logs(project_id, status)",
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *AddStatusToLogs) Down(tx *sql.Tx) error {
+	statements := []string{
+		"DROP INDEX IF EXISTS idx_logs_project_status",
+		"ALTER TABLE logs DROP COLUMN acknowledged_at",
+		"ALTER TABLE logs DROP COLUMN acknowledged_by",
+		"ALTER TABLE logs DROP COLUMN status",
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
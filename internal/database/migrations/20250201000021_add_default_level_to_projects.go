@@ -0,0 +1,25 @@
+package migrations
+
+import "database/sql"
+
+type AddDefaultLevelToProjects struct{}
+
+func (m *AddDefaultLevelToProjects) Name() string {
+	return "20250201000021_add_default_level_to_projects"
+}
+
+func (m *AddDefaultLevelToProjects) Up(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE projects ADD COLUMN default_level TEXT"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN require_level INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+func (m *AddDefaultLevelToProjects) Down(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE projects DROP COLUMN require_level"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN default_level")
+	return err
+}
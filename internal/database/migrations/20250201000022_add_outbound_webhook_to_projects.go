@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddOutboundWebhookToProjects struct{}
+
+func (m *AddOutboundWebhookToProjects) Name() string {
+	return "20250201000022_add_outbound_webhook_to_projects"
+}
+
+func (m *AddOutboundWebhookToProjects) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects ADD COLUMN outbound_webhook TEXT")
+	return err
+}
+
+func (m *AddOutboundWebhookToProjects) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE projects DROP COLUMN outbound_webhook")
+	return err
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import "database/sql"
+
+type CreateSystemSettingsTable struct{}
+
+func (m *CreateSystemSettingsTable) Name() string {
+	return "20250201000023_create_system_settings_table"
+}
+
+func (m *CreateSystemSettingsTable) Up(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS system_settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+func (m *CreateSystemSettingsTable) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP TABLE IF EXISTS system_settings")
+	return err
+}
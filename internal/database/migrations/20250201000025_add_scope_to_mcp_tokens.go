@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+type AddScopeToMCPTokens struct{}
+
+func (m *AddScopeToMCPTokens) Name() string {
+	return "20250201000025_add_scope_to_mcp_tokens"
+}
+
+func (m *AddScopeToMCPTokens) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE mcp_tokens ADD COLUMN scope TEXT NOT NULL DEFAULT 'read'")
+	return err
+}
+
+func (m *AddScopeToMCPTokens) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE mcp_tokens DROP COLUMN scope")
+	return err
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import "database/sql"
+
+type AddMetadataConditionToAlertRules struct{}
+
+func (m *AddMetadataConditionToAlertRules) Name() string {
+	return "20250201000026_add_metadata_condition_to_alert_rules"
+}
+
+func (m *AddMetadataConditionToAlertRules) Up(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE alert_rules ADD COLUMN metadata_key TEXT"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE alert_rules ADD COLUMN metadata_value TEXT")
+	return err
+}
+
+func (m *AddMetadataConditionToAlertRules) Down(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE alert_rules DROP COLUMN metadata_value"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE alert_rules DROP COLUMN metadata_key")
+	return err
+}
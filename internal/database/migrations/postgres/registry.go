@@ -0,0 +1,504 @@
+// Package postgres mirrors the SQLite migrations for the Postgres backend.
+// Each migration has the same name as its SQLite counterpart so the
+// migrations table reads the same on both; registry_test.go enforces this.
+package postgres
+
+import (
+	"database/sql"
+
+	"central-logs/internal/database"
+)
+
+// sqlMigration runs a fixed list of statements in each direction
+type sqlMigration struct {
+	name string
+	up   []string
+	down []string
+}
+
+func (m *sqlMigration) Name() string { return m.name }
+
+func (m *sqlMigration) Up(tx *sql.Tx) error { return execAll(tx, m.up) }
+
+func (m *sqlMigration) Down(tx *sql.Tx) error { return execAll(tx, m.down) }
+
+func execAll(tx *sql.Tx, statements []string) error {
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAll returns all Postgres migrations in order
+func GetAll() []database.Migration {
+	migrations := make([]database.Migration, len(schema))
+	for i := range schema {
+		migrations[i] = &schema[i]
+	}
+	return migrations
+}
+
+var schema = []sqlMigration{
+	{
+		name: "20240101000001_create_users_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS users (
+				id TEXT PRIMARY KEY,
+				username TEXT UNIQUE NOT NULL,
+				email TEXT,
+				password TEXT NOT NULL,
+				name TEXT NOT NULL,
+				role TEXT NOT NULL DEFAULT 'USER',
+				is_active BOOLEAN NOT NULL DEFAULT TRUE,
+				two_factor_secret TEXT DEFAULT '',
+				two_factor_enabled BOOLEAN DEFAULT FALSE,
+				backup_codes TEXT DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS users"},
+	},
+	{
+		name: "20240101000002_create_projects_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS projects (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				description TEXT,
+				api_key TEXT NOT NULL,
+				api_key_prefix TEXT NOT NULL,
+				is_active BOOLEAN NOT NULL DEFAULT TRUE,
+				retention_config TEXT,
+				icon_type TEXT DEFAULT 'initials',
+				icon_value TEXT DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS projects"},
+	},
+	{
+		name: "20240101000003_create_user_projects_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS user_projects (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+				role TEXT NOT NULL DEFAULT 'MEMBER',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE(user_id, project_id)
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS user_projects"},
+	},
+	{
+		name: "20240101000004_create_logs_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS logs (
+				id TEXT PRIMARY KEY,
+				project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+				level TEXT NOT NULL,
+				message TEXT NOT NULL,
+				metadata TEXT,
+				source TEXT,
+				timestamp TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS logs"},
+	},
+	{
+		name: "20240101000005_create_channels_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS channels (
+				id TEXT PRIMARY KEY,
+				project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+				type TEXT NOT NULL,
+				name TEXT NOT NULL,
+				config TEXT NOT NULL,
+				min_level TEXT NOT NULL DEFAULT 'ERROR',
+				is_active BOOLEAN NOT NULL DEFAULT TRUE,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS channels"},
+	},
+	{
+		name: "20240101000006_create_push_subscriptions_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS push_subscriptions (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				project_id TEXT REFERENCES projects(id) ON DELETE CASCADE,
+				endpoint TEXT NOT NULL UNIQUE,
+				p256dh TEXT NOT NULL,
+				auth TEXT NOT NULL,
+				user_agent TEXT,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS push_subscriptions"},
+	},
+	{
+		name: "20240101000007_create_notification_history_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS notification_history (
+				id TEXT PRIMARY KEY,
+				log_id TEXT NOT NULL REFERENCES logs(id) ON DELETE CASCADE,
+				channel_id TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+				status TEXT NOT NULL DEFAULT 'PENDING',
+				error_message TEXT,
+				sent_at TIMESTAMPTZ
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS notification_history"},
+	},
+	{
+		name: "20240101000008_create_indexes",
+		up: []string{
+			`CREATE INDEX IF NOT EXISTS idx_logs_project_id ON logs(project_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_level ON logs(level)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_created_at ON logs(created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_project_level ON logs(project_id, level)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_project_created ON logs(project_id, created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_user_projects_user_id ON user_projects(user_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_user_projects_project_id ON user_projects(project_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_channels_project_id ON channels(project_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_notification_history_log_id ON notification_history(log_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_notification_history_status ON notification_history(status)`,
+		},
+		down: []string{
+			`DROP INDEX IF EXISTS idx_logs_project_id`,
+			`DROP INDEX IF EXISTS idx_logs_level`,
+			`DROP INDEX IF EXISTS idx_logs_created_at`,
+			`DROP INDEX IF EXISTS idx_logs_project_level`,
+			`DROP INDEX IF EXISTS idx_logs_project_created`,
+			`DROP INDEX IF EXISTS idx_user_projects_user_id`,
+			`DROP INDEX IF EXISTS idx_user_projects_project_id`,
+			`DROP INDEX IF EXISTS idx_channels_project_id`,
+			`DROP INDEX IF EXISTS idx_notification_history_log_id`,
+			`DROP INDEX IF EXISTS idx_notification_history_status`,
+		},
+	},
+	{
+		name: "20250120000001_create_mcp_tokens_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS mcp_tokens (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				token_hash TEXT NOT NULL,
+				token_prefix TEXT NOT NULL,
+				granted_projects TEXT,
+				expires_at TIMESTAMPTZ,
+				is_active BOOLEAN NOT NULL DEFAULT TRUE,
+				created_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				last_used_at TIMESTAMPTZ,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_mcp_tokens_token_hash ON mcp_tokens(token_hash)`,
+			`CREATE INDEX IF NOT EXISTS idx_mcp_tokens_created_by ON mcp_tokens(created_by)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS mcp_tokens"},
+	},
+	{
+		name: "20250120000002_create_mcp_activity_logs_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS mcp_activity_logs (
+				id TEXT PRIMARY KEY,
+				token_id TEXT NOT NULL REFERENCES mcp_tokens(id) ON DELETE CASCADE,
+				tool_name TEXT NOT NULL,
+				project_ids TEXT,
+				request_params TEXT,
+				success BOOLEAN NOT NULL,
+				error_message TEXT,
+				duration_ms INTEGER,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_mcp_activity_logs_token_id ON mcp_activity_logs(token_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_mcp_activity_logs_created_at ON mcp_activity_logs(created_at)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS mcp_activity_logs"},
+	},
+	{
+		name: "20250201000001_create_alert_rules_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS alert_rules (
+				id TEXT PRIMARY KEY,
+				project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+				name TEXT NOT NULL,
+				min_level TEXT NOT NULL DEFAULT 'ERROR',
+				source TEXT,
+				threshold INTEGER NOT NULL,
+				window_seconds INTEGER NOT NULL,
+				channel_id TEXT REFERENCES channels(id) ON DELETE SET NULL,
+				is_active BOOLEAN NOT NULL DEFAULT TRUE,
+				last_fired_at TIMESTAMPTZ,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_alert_rules_project_id ON alert_rules(project_id)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS alert_rules"},
+	},
+	{
+		name: "20250201000002_create_failed_notifications_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS failed_notifications (
+				id TEXT PRIMARY KEY,
+				channel_id TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+				project_id TEXT NOT NULL,
+				log_id TEXT NOT NULL,
+				payload TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				last_error TEXT,
+				replayed_at TIMESTAMPTZ,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_failed_notifications_channel_id ON failed_notifications(channel_id, created_at)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS failed_notifications"},
+	},
+	{
+		name: "20250201000003_create_api_key_usage_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS api_key_usage (
+				project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+				key_prefix TEXT NOT NULL,
+				last_used_at TIMESTAMPTZ NOT NULL,
+				PRIMARY KEY (project_id, key_prefix)
+			)
+		`},
+		down: []string{"DROP TABLE IF EXISTS api_key_usage"},
+	},
+	{
+		name: "20250201000004_add_request_id_to_mcp_activity_logs",
+		up:   []string{"ALTER TABLE mcp_activity_logs ADD COLUMN IF NOT EXISTS request_id TEXT"},
+		down: []string{"ALTER TABLE mcp_activity_logs DROP COLUMN IF EXISTS request_id"},
+	},
+	{
+		name: "20250201000005_add_logs_timestamp_indexes",
+		up: []string{
+			`CREATE INDEX IF NOT EXISTS idx_logs_timestamp ON logs(timestamp)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_project_timestamp ON logs(project_id, timestamp)`,
+		},
+		down: []string{
+			`DROP INDEX IF EXISTS idx_logs_timestamp`,
+			`DROP INDEX IF EXISTS idx_logs_project_timestamp`,
+		},
+	},
+	{
+		name: "20250201000006_add_redaction_config_to_projects",
+		up:   []string{"ALTER TABLE projects ADD COLUMN IF NOT EXISTS redaction_config TEXT"},
+		down: []string{"ALTER TABLE projects DROP COLUMN IF EXISTS redaction_config"},
+	},
+	{
+		name: "20250201000007_add_sampling_config_to_projects",
+		up:   []string{"ALTER TABLE projects ADD COLUMN IF NOT EXISTS sampling_config TEXT"},
+		down: []string{"ALTER TABLE projects DROP COLUMN IF EXISTS sampling_config"},
+	},
+	{
+		name: "20250201000008_add_signing_secret_to_projects",
+		up:   []string{"ALTER TABLE projects ADD COLUMN IF NOT EXISTS signing_secret TEXT"},
+		down: []string{"ALTER TABLE projects DROP COLUMN IF EXISTS signing_secret"},
+	},
+	{
+		name: "20250201000009_add_group_to_projects",
+		up:   []string{"ALTER TABLE projects ADD COLUMN IF NOT EXISTS group_name TEXT"},
+		down: []string{"ALTER TABLE projects DROP COLUMN IF EXISTS group_name"},
+	},
+	{
+		name: "20250201000010_create_audit_logs_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS audit_logs (
+				id TEXT PRIMARY KEY,
+				actor_id TEXT NOT NULL,
+				actor_name TEXT NOT NULL,
+				action TEXT NOT NULL,
+				target_type TEXT NOT NULL,
+				target_id TEXT NOT NULL,
+				details TEXT,
+				ip_address TEXT,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id, created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action, created_at)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS audit_logs"},
+	},
+	{
+		name: "20250201000011_add_deleted_at_to_users_and_projects",
+		up: []string{
+			"ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ",
+			"ALTER TABLE projects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ",
+		},
+		down: []string{
+			"ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at",
+			"ALTER TABLE users DROP COLUMN IF EXISTS deleted_at",
+		},
+	},
+	{
+		name: "20250201000012_add_last_login_at_to_users",
+		up: []string{
+			"ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ",
+		},
+		down: []string{
+			"ALTER TABLE users DROP COLUMN IF EXISTS last_login_at",
+		},
+	},
+	{
+		name: "20250201000013_add_token_revocation",
+		up: []string{
+			"ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0",
+			`CREATE TABLE IF NOT EXISTS revoked_tokens (
+				jti TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				expires_at TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at)`,
+		},
+		down: []string{
+			"DROP TABLE IF EXISTS revoked_tokens",
+			"ALTER TABLE users DROP COLUMN IF EXISTS token_version",
+		},
+	},
+	{
+		name: "20250201000014_create_channel_deliveries_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS channel_deliveries (
+				id TEXT PRIMARY KEY,
+				channel_id TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+				success BOOLEAN NOT NULL,
+				error TEXT,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_channel_deliveries_channel_id ON channel_deliveries(channel_id, created_at)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS channel_deliveries"},
+	},
+	{
+		name: "20250201000015_add_job_id_to_channel_deliveries",
+		up: []string{
+			"ALTER TABLE channel_deliveries ADD COLUMN IF NOT EXISTS job_id TEXT",
+			"CREATE INDEX IF NOT EXISTS idx_channel_deliveries_job_id ON channel_deliveries(job_id)",
+		},
+		down: []string{
+			"DROP INDEX IF EXISTS idx_channel_deliveries_job_id",
+			"ALTER TABLE channel_deliveries DROP COLUMN IF EXISTS job_id",
+		},
+	},
+	{
+		name: "20250201000016_add_max_body_bytes_to_projects",
+		up:   []string{"ALTER TABLE projects ADD COLUMN IF NOT EXISTS max_body_bytes INTEGER"},
+		down: []string{"ALTER TABLE projects DROP COLUMN IF EXISTS max_body_bytes"},
+	},
+	{
+		name: "20250201000017_add_rate_limit_to_projects",
+		up: []string{
+			"ALTER TABLE projects ADD COLUMN IF NOT EXISTS rate_limit_per_minute INTEGER",
+			"ALTER TABLE projects ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER",
+		},
+		down: []string{
+			"ALTER TABLE projects DROP COLUMN IF EXISTS rate_limit_burst",
+			"ALTER TABLE projects DROP COLUMN IF EXISTS rate_limit_per_minute",
+		},
+	},
+	{
+		name: "20250201000018_add_ingestion_paused_to_projects",
+		up:   []string{"ALTER TABLE projects ADD COLUMN IF NOT EXISTS ingestion_paused BOOLEAN NOT NULL DEFAULT FALSE"},
+		down: []string{"ALTER TABLE projects DROP COLUMN IF EXISTS ingestion_paused"},
+	},
+	{
+		name: "20250201000019_create_user_pinned_projects_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS user_pinned_projects (
+				user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, project_id)
+			)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS user_pinned_projects"},
+	},
+	{
+		name: "20250201000020_add_status_to_logs",
+		up: []string{
+			"ALTER TABLE logs ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'new'",
+			"ALTER TABLE logs ADD COLUMN IF NOT EXISTS acknowledged_by TEXT",
+			"ALTER TABLE logs ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ",
+			"CREATE INDEX IF NOT EXISTS idx_logs_project_status ON logs(project_id, status)",
+		},
+		down: []string{
+			"DROP INDEX IF EXISTS idx_logs_project_status",
+			"ALTER TABLE logs DROP COLUMN IF EXISTS acknowledged_at",
+			"ALTER TABLE logs DROP COLUMN IF EXISTS acknowledged_by",
+			"ALTER TABLE logs DROP COLUMN IF EXISTS status",
+		},
+	},
+	{
+		name: "20250201000021_add_default_level_to_projects",
+		up: []string{
+			"ALTER TABLE projects ADD COLUMN IF NOT EXISTS default_level TEXT",
+			"ALTER TABLE projects ADD COLUMN IF NOT EXISTS require_level BOOLEAN NOT NULL DEFAULT FALSE",
+		},
+		down: []string{
+			"ALTER TABLE projects DROP COLUMN IF EXISTS require_level",
+			"ALTER TABLE projects DROP COLUMN IF EXISTS default_level",
+		},
+	},
+	{
+		name: "20250201000022_add_outbound_webhook_to_projects",
+		up:   []string{"ALTER TABLE projects ADD COLUMN IF NOT EXISTS outbound_webhook TEXT"},
+		down: []string{"ALTER TABLE projects DROP COLUMN IF EXISTS outbound_webhook"},
+	},
+	{
+		name: "20250201000023_create_system_settings_table",
+		up: []string{`
+			CREATE TABLE IF NOT EXISTS system_settings (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+		},
+		down: []string{"DROP TABLE IF EXISTS system_settings"},
+	},
+	{
+		name: "20250201000024_add_logs_level_composite_indexes",
+		up: []string{
+			`CREATE INDEX IF NOT EXISTS idx_logs_project_level_timestamp ON logs(project_id, level, timestamp)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_project_level_created ON logs(project_id, level, created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_level_timestamp ON logs(level, timestamp)`,
+			`DROP INDEX IF EXISTS idx_logs_project_level`,
+			`DROP INDEX IF EXISTS idx_logs_level`,
+		},
+		down: []string{
+			`CREATE INDEX IF NOT EXISTS idx_logs_level ON logs(level)`,
+			`CREATE INDEX IF NOT EXISTS idx_logs_project_level ON logs(project_id, level)`,
+			`DROP INDEX IF EXISTS idx_logs_level_timestamp`,
+			`DROP INDEX IF EXISTS idx_logs_project_level_created`,
+			`DROP INDEX IF EXISTS idx_logs_project_level_timestamp`,
+		},
+	},
+	{
+		name: "20250201000025_add_scope_to_mcp_tokens",
+		up:   []string{"ALTER TABLE mcp_tokens ADD COLUMN IF NOT EXISTS scope TEXT NOT NULL DEFAULT 'read'"},
+		down: []string{"ALTER TABLE mcp_tokens DROP COLUMN IF EXISTS scope"},
+	},
+	{
+		name: "20250201000026_add_metadata_condition_to_alert_rules",
+		up: []string{
+			"ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS metadata_key TEXT",
+			"ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS metadata_value TEXT",
+		},
+		down: []string{
+			"ALTER TABLE alert_rules DROP COLUMN IF EXISTS metadata_value",
+			"ALTER TABLE alert_rules DROP COLUMN IF EXISTS metadata_key",
+		},
+	},
+}
@@ -0,0 +1,23 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"central-logs/internal/database/migrations"
+	"central-logs/internal/database/migrations/postgres"
+)
+
+// Every SQLite migration needs a Postgres twin with the same name
+func TestGetAll_MirrorsSQLiteMigrations(t *testing.T) {
+	sqlite := migrations.GetAll()
+	pg := postgres.GetAll()
+
+	if len(pg) != len(sqlite) {
+		t.Fatalf("Expected %d Postgres migrations, got %d", len(sqlite), len(pg))
+	}
+	for i := range sqlite {
+		if pg[i].Name() != sqlite[i].Name() {
+			t.Errorf("Migration %d: expected %s, got %s", i, sqlite[i].Name(), pg[i].Name())
+		}
+	}
+}
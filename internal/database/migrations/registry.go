@@ -1,6 +1,17 @@
 package migrations
 
-import "central-logs/internal/database"
+import (
+	"central-logs/internal/database"
+	"central-logs/internal/database/migrations/postgres"
+)
+
+// ForDriver returns the migration set for a storage driver
+func ForDriver(driver string) []database.Migration {
+	if driver == database.DriverPostgres {
+		return postgres.GetAll()
+	}
+	return GetAll()
+}
 
 // GetAll returns all migrations in order
 func GetAll() []database.Migration {
@@ -15,5 +26,31 @@ func GetAll() []database.Migration {
 		&CreateIndexes{},
 		&CreateMCPTokensTable{},
 		&CreateMCPActivityLogsTable{},
+		&CreateAlertRulesTable{},
+		&CreateFailedNotificationsTable{},
+		&CreateAPIKeyUsageTable{},
+		&AddRequestIDToMCPActivityLogs{},
+		&AddLogsTimestampIndexes{},
+		&AddRedactionConfigToProjects{},
+		&AddSamplingConfigToProjects{},
+		&AddSigningSecretToProjects{},
+		&AddGroupToProjects{},
+		&CreateAuditLogsTable{},
+		&AddDeletedAtToUsersAndProjects{},
+		&AddLastLoginAtToUsers{},
+		&AddTokenRevocation{},
+		&CreateChannelDeliveriesTable{},
+		&AddJobIDToChannelDeliveries{},
+		&AddMaxBodyBytesToProjects{},
+		&AddRateLimitToProjects{},
+		&AddIngestionPausedToProjects{},
+		&CreateUserPinnedProjectsTable{},
+		&AddStatusToLogs{},
+		&AddDefaultLevelToProjects{},
+		&AddOutboundWebhookToProjects{},
+		&CreateSystemSettingsTable{},
+		&AddLogsLevelCompositeIndexes{},
+		&AddScopeToMCPTokens{},
+		&AddMetadataConditionToAlertRules{},
 	}
 }
@@ -0,0 +1,13 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// pgx registers itself as "pgx"; Open looks drivers up by dialect name, so
+// make it available as "postgres" too
+func init() {
+	sql.Register(DriverPostgres, stdlib.GetDefaultDriver())
+}
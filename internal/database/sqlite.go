@@ -2,8 +2,12 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -12,16 +16,90 @@ import (
 
 type DB struct {
 	*sql.DB
+	Dialect Dialect
+}
+
+// SQLiteOptions tunes SQLite for concurrent ingestion
+type SQLiteOptions struct {
+	WAL           bool   // Use write-ahead logging so readers don't block the writer
+	BusyTimeoutMS int    // How long to wait for a lock before failing with "database is locked"
+	Synchronous   string // synchronous pragma; NORMAL is safe with WAL
+}
+
+// DefaultSQLiteOptions returns the settings used when none are configured
+func DefaultSQLiteOptions() SQLiteOptions {
+	return SQLiteOptions{WAL: true, BusyTimeoutMS: 5000, Synchronous: "NORMAL"}
+}
+
+// dsn builds the go-sqlite3 connection string for a database file
+func (o SQLiteOptions) dsn(dbPath string) (string, error) {
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	if o.WAL {
+		params.Set("_journal_mode", "WAL")
+	}
+	if o.BusyTimeoutMS > 0 {
+		params.Set("_busy_timeout", strconv.Itoa(o.BusyTimeoutMS))
+	}
+	if o.Synchronous != "" {
+		switch strings.ToUpper(o.Synchronous) {
+		case "OFF", "NORMAL", "FULL", "EXTRA":
+			params.Set("_synchronous", strings.ToUpper(o.Synchronous))
+		default:
+			return "", fmt.Errorf("invalid sqlite synchronous mode %q", o.Synchronous)
+		}
+	}
+	return dbPath + "?" + params.Encode(), nil
+}
+
+// Open connects to the configured storage backend. SQLite takes a file path;
+// other drivers take a connection URL and use the database/sql driver
+// registered under their name (see postgres.go).
+func Open(driverName, dsn string, sqliteOpts SQLiteOptions) (*DB, error) {
+	dialect, err := DialectFor(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect.Name() == DriverSQLite {
+		return NewWithOptions(dsn, sqliteOpts)
+	}
+
+	// sql.Open only validates that the driver is registered
+	probe, err := sql.Open(dialect.Name(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s driver is not available in this build: %w", dialect.Name(), err)
+	}
+	drv := probe.Driver()
+	probe.Close()
+
+	db := sql.OpenDB(&rebindConnector{driver: drv, dsn: dsn, dialect: dialect})
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{DB: db, Dialect: dialect}, nil
 }
 
 func New(dbPath string) (*DB, error) {
+	return NewWithOptions(dbPath, DefaultSQLiteOptions())
+}
+
+// NewWithOptions opens a SQLite database file with the given tuning
+func NewWithOptions(dbPath string, opts SQLiteOptions) (*DB, error) {
 	// Ensure directory exists
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on&_journal_mode=WAL")
+	dsn, err := opts.dsn(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -31,10 +109,24 @@ func New(dbPath string) (*DB, error) {
 	db.SetMaxIdleConns(1)
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	return &DB{db}, nil
+	// The journal mode pragma is silently ignored on some filesystems, so check it
+	if opts.WAL {
+		var mode string
+		if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+			db.Close()
+			return nil, err
+		}
+		if !strings.EqualFold(mode, "wal") {
+			db.Close()
+			return nil, fmt.Errorf("sqlite WAL mode was requested but journal_mode is %q", mode)
+		}
+	}
+
+	return &DB{DB: db, Dialect: sqliteDialect{}}, nil
 }
 
 // Migrate runs all pending migrations using the new migration system
@@ -44,7 +136,7 @@ func (db *DB) Migrate() error {
 
 // MigrateWithRegistry runs migrations with custom registry (for testing)
 func (db *DB) MigrateWithRegistry(migrations []Migration) error {
-	migrator := NewMigrator(db.DB)
+	migrator := NewMigratorForDialect(db.DB, db.Dialect)
 
 	// If no custom migrations provided, this will be handled by the caller
 	// that imports the migrations package
@@ -64,7 +156,7 @@ func (db *DB) Rollback() error {
 
 // RollbackWithRegistry rolls back migrations with custom registry
 func (db *DB) RollbackWithRegistry(migrations []Migration) error {
-	migrator := NewMigrator(db.DB)
+	migrator := NewMigratorForDialect(db.DB, db.Dialect)
 
 	if migrations != nil {
 		for _, m := range migrations {
@@ -82,7 +174,7 @@ func (db *DB) MigrationStatus() error {
 
 // MigrationStatusWithRegistry shows migration status with custom registry
 func (db *DB) MigrationStatusWithRegistry(migrations []Migration) error {
-	migrator := NewMigrator(db.DB)
+	migrator := NewMigratorForDialect(db.DB, db.Dialect)
 
 	if migrations != nil {
 		for _, m := range migrations {
@@ -93,6 +185,17 @@ func (db *DB) MigrationStatusWithRegistry(migrations []Migration) error {
 	return migrator.Status()
 }
 
+// MigrationStatesWithRegistry lists the given migrations with their applied state
+func (db *DB) MigrationStatesWithRegistry(migrations []Migration) ([]MigrationState, error) {
+	migrator := NewMigratorForDialect(db.DB, db.Dialect)
+
+	for _, m := range migrations {
+		migrator.Register(m)
+	}
+
+	return migrator.States()
+}
+
 func (db *DB) Close() error {
 	return db.DB.Close()
 }
@@ -0,0 +1,104 @@
+package database_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"central-logs/internal/database"
+	"central-logs/internal/database/migrations"
+	"central-logs/internal/models"
+)
+
+func TestNewWithOptions_EnablesWAL(t *testing.T) {
+	db, err := database.NewWithOptions(filepath.Join(t.TempDir(), "wal.db"), database.DefaultSQLiteOptions())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("Failed to read journal_mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("Expected journal_mode wal, got %s", mode)
+	}
+
+	var timeout int
+	if err := db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("Failed to read busy_timeout: %v", err)
+	}
+	if timeout != 5000 {
+		t.Errorf("Expected busy_timeout 5000, got %d", timeout)
+	}
+}
+
+func TestNewWithOptions_RejectsUnknownSynchronous(t *testing.T) {
+	opts := database.DefaultSQLiteOptions()
+	opts.Synchronous = "SOMETIMES"
+
+	if _, err := database.NewWithOptions(filepath.Join(t.TempDir(), "bad.db"), opts); err == nil {
+		t.Error("Expected error for invalid synchronous mode")
+	}
+}
+
+func TestConcurrentLogCreation_NoLockErrors(t *testing.T) {
+	db, err := database.NewWithOptions(filepath.Join(t.TempDir(), "concurrent.db"), database.DefaultSQLiteOptions())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.MigrateWithRegistry(migrations.GetAll()); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	project := &models.Project{Name: "Load"}
+	if _, err := models.NewProjectRepository(db.DB).Create(project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	logRepo := models.NewLogRepository(db.DB)
+
+	const writers = 100
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*2)
+	for i := 0; i < writers; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			errs <- logRepo.Create(&models.Log{
+				ProjectID: project.ID,
+				Level:     models.LogLevelInfo,
+				Message:   fmt.Sprintf("concurrent %d", i),
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, _, err := logRepo.List(&models.LogFilter{ProjectIDs: []string{project.ID}, Limit: 10})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			if strings.Contains(err.Error(), "database is locked") {
+				t.Fatalf("Got lock error under concurrency: %v", err)
+			}
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	count, err := logRepo.CountByProject(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to count logs: %v", err)
+	}
+	if count != writers {
+		t.Errorf("Expected %d logs, got %d", writers, count)
+	}
+}
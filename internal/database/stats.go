@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TableStats is one table's row count
+type TableStats struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// IndexStats describes one index on a table
+type IndexStats struct {
+	Name       string   `json:"name"`
+	Unique     bool     `json:"unique"`
+	Columns    []string `json:"columns,omitempty"`    // SQLite
+	Definition string   `json:"definition,omitempty"` // Postgres
+}
+
+// Stats is a snapshot of the database's size and layout for diagnostics
+type Stats struct {
+	Driver    string       `json:"driver"`
+	SizeBytes int64        `json:"size_bytes"`
+	WALBytes  int64        `json:"wal_bytes"` // SQLite's -wal file; 0 when there is none
+	Tables    []TableStats `json:"tables"`
+	Indexes   []IndexStats `json:"indexes"` // On the table Stats was asked about
+}
+
+// Stats counts the rows of each table in tables and lists the indexes on
+// indexTable. Counting is a full scan on SQLite, so this is for occasional
+// diagnostics rather than dashboards.
+func (db *DB) Stats(ctx context.Context, tables []string, indexTable string) (*Stats, error) {
+	stats := &Stats{Driver: DriverSQLite, Tables: make([]TableStats, 0, len(tables))}
+	postgres := db.Dialect != nil && db.Dialect.Name() == DriverPostgres
+	if postgres {
+		stats.Driver = DriverPostgres
+	}
+
+	// Table names come from the caller, never from a request
+	for _, table := range tables {
+		var rows int64
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&rows); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		stats.Tables = append(stats.Tables, TableStats{Name: table, Rows: rows})
+	}
+
+	var err error
+	if postgres {
+		err = db.postgresStats(ctx, stats, indexTable)
+	} else {
+		err = db.sqliteStats(ctx, stats, indexTable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (db *DB) sqliteStats(ctx context.Context, stats *Stats, indexTable string) error {
+	// The main database's file; empty for an in-memory database
+	var seq int
+	var name, file string
+	if err := db.QueryRowContext(ctx, "PRAGMA database_list").Scan(&seq, &name, &file); err != nil {
+		return fmt.Errorf("failed to read database_list: %w", err)
+	}
+	if file != "" {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		stats.SizeBytes = info.Size()
+		if wal, err := os.Stat(file + "-wal"); err == nil {
+			stats.WALBytes = wal.Size()
+		}
+	} else {
+		size, err := db.sqliteSize(ctx)
+		if err != nil {
+			return err
+		}
+		stats.SizeBytes = size
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA index_list(%q)", indexTable))
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			seq             int
+			index           IndexStats
+			origin, partial string
+		)
+		if err := rows.Scan(&seq, &index.Name, &index.Unique, &origin, &partial); err != nil {
+			return err
+		}
+		stats.Indexes = append(stats.Indexes, index)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close() // One connection: free it before querying each index
+
+	for i := range stats.Indexes {
+		columns, err := db.sqliteIndexColumns(ctx, stats.Indexes[i].Name)
+		if err != nil {
+			return err
+		}
+		stats.Indexes[i].Columns = columns
+	}
+	return nil
+}
+
+func (db *DB) sqliteIndexColumns(ctx context.Context, index string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA index_info(%q)", index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %w", index, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var seqno, cid int
+		var column *string // NULL for an expression
+		if err := rows.Scan(&seqno, &cid, &column); err != nil {
+			return nil, err
+		}
+		if column == nil {
+			columns = append(columns, "<expression>")
+		} else {
+			columns = append(columns, *column)
+		}
+	}
+	return columns, rows.Err()
+}
+
+func (db *DB) postgresStats(ctx context.Context, stats *Stats, indexTable string) error {
+	if err := db.QueryRowContext(ctx, "SELECT pg_database_size(current_database())").Scan(&stats.SizeBytes); err != nil {
+		return fmt.Errorf("failed to read database size: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT indexname, indexdef FROM pg_indexes WHERE tablename = ? ORDER BY indexname", indexTable)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var index IndexStats
+		if err := rows.Scan(&index.Name, &index.Definition); err != nil {
+			return err
+		}
+		index.Unique = strings.HasPrefix(index.Definition, "CREATE UNIQUE")
+		stats.Indexes = append(stats.Indexes, index)
+	}
+	return rows.Err()
+}
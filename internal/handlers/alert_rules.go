@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AlertRuleHandler struct {
+	alertRuleRepo *models.AlertRuleRepository
+	channelRepo   *models.ChannelRepository
+}
+
+func NewAlertRuleHandler(alertRuleRepo *models.AlertRuleRepository, channelRepo *models.ChannelRepository) *AlertRuleHandler {
+	return &AlertRuleHandler{
+		alertRuleRepo: alertRuleRepo,
+		channelRepo:   channelRepo,
+	}
+}
+
+type AlertRuleRequest struct {
+	Name          string          `json:"name"`
+	MinLevel      models.LogLevel `json:"min_level"`
+	Source        *string         `json:"source"`
+	MetadataKey   *string         `json:"metadata_key"`
+	MetadataValue *string         `json:"metadata_value"`
+	Threshold     int             `json:"threshold"`
+	WindowSeconds int             `json:"window_seconds"`
+	ChannelID     *string         `json:"channel_id"`
+	IsActive      *bool           `json:"is_active"`
+}
+
+// ListAlertRules handles GET /api/admin/projects/:id/alert-rules
+func (h *AlertRuleHandler) ListAlertRules(c *fiber.Ctx) error {
+	projectID := c.Params("id")
+
+	rules, err := h.alertRuleRepo.GetByProjectID(projectID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list alert rules",
+		})
+	}
+
+	if rules == nil {
+		rules = []*models.AlertRule{}
+	}
+
+	return c.JSON(fiber.Map{
+		"alert_rules": rules,
+	})
+}
+
+// CreateAlertRule handles POST /api/admin/projects/:id/alert-rules
+func (h *AlertRuleHandler) CreateAlertRule(c *fiber.Ctx) error {
+	projectID := c.Params("id")
+
+	var req AlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.MinLevel == "" {
+		req.MinLevel = models.LogLevelError
+	}
+
+	rule := &models.AlertRule{
+		ProjectID:     projectID,
+		Name:          req.Name,
+		MinLevel:      req.MinLevel,
+		Threshold:     req.Threshold,
+		WindowSeconds: req.WindowSeconds,
+		IsActive:      true,
+	}
+	if req.Source != nil {
+		rule.Source = *req.Source
+	}
+	if req.MetadataKey != nil {
+		rule.MetadataKey = *req.MetadataKey
+	}
+	if req.MetadataValue != nil {
+		rule.MetadataValue = *req.MetadataValue
+	}
+	if req.ChannelID != nil {
+		rule.ChannelID = *req.ChannelID
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if msg := h.validate(rule); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	if err := h.alertRuleRepo.Create(rule); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create alert rule",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// GetAlertRule handles GET /api/admin/projects/:id/alert-rules/:ruleId
+func (h *AlertRuleHandler) GetAlertRule(c *fiber.Ctx) error {
+	rule, err := h.findRule(c)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return nil
+	}
+
+	return c.JSON(rule)
+}
+
+// UpdateAlertRule handles PUT /api/admin/projects/:id/alert-rules/:ruleId
+func (h *AlertRuleHandler) UpdateAlertRule(c *fiber.Ctx) error {
+	rule, err := h.findRule(c)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return nil
+	}
+
+	var req AlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Name != "" {
+		rule.Name = req.Name
+	}
+	if req.MinLevel != "" {
+		rule.MinLevel = req.MinLevel
+	}
+	if req.Source != nil {
+		rule.Source = *req.Source
+	}
+	if req.MetadataKey != nil {
+		rule.MetadataKey = *req.MetadataKey
+	}
+	if req.MetadataValue != nil {
+		rule.MetadataValue = *req.MetadataValue
+	}
+	if req.Threshold != 0 {
+		rule.Threshold = req.Threshold
+	}
+	if req.WindowSeconds != 0 {
+		rule.WindowSeconds = req.WindowSeconds
+	}
+	if req.ChannelID != nil {
+		rule.ChannelID = *req.ChannelID
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if msg := h.validate(rule); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	if err := h.alertRuleRepo.Update(rule); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update alert rule",
+		})
+	}
+
+	return c.JSON(rule)
+}
+
+// DeleteAlertRule handles DELETE /api/admin/projects/:id/alert-rules/:ruleId
+func (h *AlertRuleHandler) DeleteAlertRule(c *fiber.Ctx) error {
+	rule, err := h.findRule(c)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return nil
+	}
+
+	if err := h.alertRuleRepo.Delete(rule.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete alert rule",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Alert rule deleted",
+	})
+}
+
+// findRule loads the rule from the route and writes an error response when it
+// is missing or belongs to another project. A nil rule means a response was sent.
+func (h *AlertRuleHandler) findRule(c *fiber.Ctx) (*models.AlertRule, error) {
+	rule, err := h.alertRuleRepo.GetByID(c.Params("ruleId"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get alert rule",
+		})
+	}
+
+	if rule == nil || rule.ProjectID != c.Params("id") {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Alert rule not found",
+		})
+	}
+
+	return rule, nil
+}
+
+func (h *AlertRuleHandler) validate(rule *models.AlertRule) string {
+	if rule.Name == "" {
+		return "Name is required"
+	}
+	if rule.MinLevel.Priority() < 0 {
+		return "Invalid min_level"
+	}
+	if rule.Threshold <= 0 {
+		return "Threshold must be greater than 0"
+	}
+	if rule.WindowSeconds <= 0 {
+		return "window_seconds must be greater than 0"
+	}
+	if rule.MetadataKey != "" && !models.IsValidMetadataKey(rule.MetadataKey) {
+		return "metadata_key may only contain letters, digits, '_' and '-' (up to 64)"
+	}
+	if rule.MetadataKey == "" && rule.MetadataValue != "" {
+		return "metadata_value requires a metadata_key"
+	}
+
+	if rule.ChannelID != "" {
+		channel, err := h.channelRepo.GetByID(rule.ChannelID)
+		if err != nil || channel == nil || channel.ProjectID != rule.ProjectID {
+			return "Channel not found in this project"
+		}
+	}
+
+	return ""
+}
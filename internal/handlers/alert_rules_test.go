@@ -0,0 +1,240 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"central-logs/internal/handlers"
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+	"central-logs/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// alertRuleTestApp serves the alert rule routes behind the same auth and role
+// checks as the server, with an owner, member, viewer and outsider of project
+type alertRuleTestApp struct {
+	app      *fiber.App
+	tokens   map[string]string
+	project  *models.Project
+	other    *models.Project
+	ruleRepo *models.AlertRuleRepository
+	channels *models.ChannelRepository
+}
+
+func setupAlertRuleTestApp(t *testing.T) *alertRuleTestApp {
+	db := setupLogTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS alert_rules (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			min_level TEXT NOT NULL DEFAULT 'ERROR',
+			source TEXT,
+			metadata_key TEXT,
+			metadata_value TEXT,
+			threshold INTEGER NOT NULL,
+			window_seconds INTEGER NOT NULL,
+			channel_id TEXT,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			last_fired_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		t.Fatalf("Failed to create alert_rules table: %v", err)
+	}
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+	rbacMiddleware := middleware.NewRBACMiddleware(userProjectRepo)
+
+	a := &alertRuleTestApp{
+		tokens:   make(map[string]string),
+		project:  &models.Project{Name: "Payments", IsActive: true},
+		other:    &models.Project{Name: "Other", IsActive: true},
+		ruleRepo: models.NewAlertRuleRepository(db),
+		channels: models.NewChannelRepository(db),
+	}
+	projectRepo.Create(a.project)
+	projectRepo.Create(a.other)
+
+	roles := map[string]models.ProjectRole{
+		"owner":  models.ProjectRoleOwner,
+		"member": models.ProjectRoleMember,
+		"viewer": models.ProjectRoleViewer,
+	}
+	for _, name := range []string{"owner", "member", "viewer", "outsider"} {
+		user := &models.User{Username: name, Email: name + "@example.com", Password: "password123", Name: name, Role: models.RoleUser, IsActive: true}
+		userRepo.Create(user)
+		if role, ok := roles[name]; ok {
+			userProjectRepo.Create(&models.UserProject{UserID: user.ID, ProjectID: a.project.ID, Role: role})
+		}
+		a.tokens[name], _ = jwtManager.Generate(user.ID, user.Email, string(user.Role))
+	}
+
+	handler := handlers.NewAlertRuleHandler(a.ruleRepo, a.channels)
+	a.app = fiber.New()
+	a.app.Use(authMiddleware.RequireAuth())
+	a.app.Get("/projects/:id/alert-rules", rbacMiddleware.RequireOwnerOrMember(), handler.ListAlertRules)
+	a.app.Post("/projects/:id/alert-rules", rbacMiddleware.RequireOwnerOrMember(), handler.CreateAlertRule)
+	a.app.Get("/projects/:id/alert-rules/:ruleId", rbacMiddleware.RequireOwnerOrMember(), handler.GetAlertRule)
+	a.app.Put("/projects/:id/alert-rules/:ruleId", rbacMiddleware.RequireOwnerOrMember(), handler.UpdateAlertRule)
+	a.app.Delete("/projects/:id/alert-rules/:ruleId", rbacMiddleware.RequireOwnerOrMember(), handler.DeleteAlertRule)
+	return a
+}
+
+func (a *alertRuleTestApp) do(t *testing.T, user, method, path string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Authorization", "Bearer "+a.tokens[user])
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	return resp.StatusCode, response
+}
+
+func TestAlertRuleHandler_Validation(t *testing.T) {
+	a := setupAlertRuleTestApp(t)
+	rulesPath := "/projects/" + a.project.ID + "/alert-rules"
+
+	foreign := &models.Channel{ProjectID: a.other.ID, Type: models.ChannelTypeDiscord, Name: "Elsewhere",
+		Config: map[string]interface{}{"webhook_url": "https://discord.test/hook"}, MinLevel: models.LogLevelError, IsActive: true}
+	a.channels.Create(foreign)
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{"name": "Errors", "threshold": 5, "window_seconds": 60}
+	}
+	with := func(key string, value interface{}) map[string]interface{} {
+		body := valid()
+		if value == nil {
+			delete(body, key)
+		} else {
+			body[key] = value
+		}
+		return body
+	}
+
+	tests := []struct {
+		name      string
+		body      map[string]interface{}
+		wantError string
+	}{
+		{"missing name", with("name", nil), "Name is required"},
+		{"unknown level", with("min_level", "LOUD"), "Invalid min_level"},
+		{"zero threshold", with("threshold", 0), "Threshold must be greater than 0"},
+		{"zero window", with("window_seconds", 0), "window_seconds must be greater than 0"},
+		{"metadata key with SQL", with("metadata_key", "region') OR 1=1 --"), "metadata_key may only contain letters, digits, '_' and '-' (up to 64)"},
+		{"metadata value without key", with("metadata_value", "eu-west"), "metadata_value requires a metadata_key"},
+		{"channel of another project", with("channel_id", foreign.ID), "Channel not found in this project"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := a.do(t, "owner", http.MethodPost, rulesPath, tt.body)
+			if status != http.StatusBadRequest || response["error"] != tt.wantError {
+				t.Errorf("Expected 400 %q, got %d %v", tt.wantError, status, response["error"])
+			}
+		})
+	}
+
+	body := with("metadata_key", "region")
+	body["metadata_value"] = "eu-west"
+	status, created := a.do(t, "owner", http.MethodPost, rulesPath, body)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %v", status, created)
+	}
+	if created["min_level"] != "ERROR" || created["metadata_key"] != "region" || created["is_active"] != true {
+		t.Errorf("Expected defaults and the metadata condition, got %v", created)
+	}
+	rulePath := rulesPath + "/" + created["id"].(string)
+
+	// Updates are validated the same way
+	if status, response := a.do(t, "owner", http.MethodPut, rulePath, map[string]interface{}{"metadata_key": ""}); status != http.StatusBadRequest ||
+		response["error"] != "metadata_value requires a metadata_key" {
+		t.Errorf("Expected clearing the key alone to be refused, got %d %v", status, response["error"])
+	}
+	if status, response := a.do(t, "owner", http.MethodPut, rulePath, map[string]interface{}{"threshold": 10, "is_active": false}); status != http.StatusOK ||
+		response["threshold"] != float64(10) || response["is_active"] != false {
+		t.Errorf("Expected the update to apply, got %d %v", status, response)
+	}
+
+	// A rule is only reachable through its own project
+	foreignRule := &models.AlertRule{ProjectID: a.other.ID, Name: "Elsewhere", MinLevel: models.LogLevelError, Threshold: 1, WindowSeconds: 60, IsActive: true}
+	a.ruleRepo.Create(foreignRule)
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		if status, _ := a.do(t, "owner", method, rulesPath+"/"+foreignRule.ID, map[string]interface{}{"threshold": 2}); status != http.StatusNotFound {
+			t.Errorf("%s: expected another project's rule to be 404, got %d", method, status)
+		}
+	}
+	if stored, _ := a.ruleRepo.GetByID(foreignRule.ID); stored == nil || stored.Threshold != 1 {
+		t.Errorf("Expected another project's rule to be untouched, got %+v", stored)
+	}
+	if status, _ := a.do(t, "owner", http.MethodGet, rulesPath+"/missing", nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown rule, got %d", status)
+	}
+}
+
+func TestAlertRuleHandler_RoleGating(t *testing.T) {
+	a := setupAlertRuleTestApp(t)
+	rulesPath := "/projects/" + a.project.ID + "/alert-rules"
+
+	rule := &models.AlertRule{ProjectID: a.project.ID, Name: "Errors", MinLevel: models.LogLevelError, Threshold: 5, WindowSeconds: 60, IsActive: true}
+	a.ruleRepo.Create(rule)
+	rulePath := rulesPath + "/" + rule.ID
+	newRule := map[string]interface{}{"name": "More errors", "threshold": 5, "window_seconds": 60}
+
+	tests := []struct {
+		user       string
+		method     string
+		path       string
+		body       interface{}
+		obscure    bool
+		wantStatus int
+	}{
+		{"owner", http.MethodGet, rulesPath, nil, false, http.StatusOK},
+		{"owner", http.MethodPost, rulesPath, newRule, false, http.StatusCreated},
+		{"member", http.MethodGet, rulePath, nil, false, http.StatusOK},
+		{"member", http.MethodPut, rulePath, map[string]interface{}{"threshold": 7}, false, http.StatusOK},
+		{"viewer", http.MethodGet, rulesPath, nil, false, http.StatusForbidden},
+		{"viewer", http.MethodPost, rulesPath, newRule, false, http.StatusForbidden},
+		{"viewer", http.MethodPut, rulePath, map[string]interface{}{"threshold": 1}, false, http.StatusForbidden},
+		{"viewer", http.MethodDelete, rulePath, nil, false, http.StatusForbidden},
+		// A viewer knows the project exists, so obscuring does not hide it
+		{"viewer", http.MethodDelete, rulePath, nil, true, http.StatusForbidden},
+		{"outsider", http.MethodGet, rulesPath, nil, false, http.StatusForbidden},
+		{"outsider", http.MethodGet, rulesPath, nil, true, http.StatusNotFound},
+		{"member", http.MethodDelete, rulePath, nil, false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		middleware.SetObscureNotFound(tt.obscure)
+		status, response := a.do(t, tt.user, tt.method, tt.path, tt.body)
+		middleware.SetObscureNotFound(false)
+		if status != tt.wantStatus {
+			t.Errorf("%s %s %s (obscure=%v): expected %d, got %d %v", tt.user, tt.method, tt.path, tt.obscure, tt.wantStatus, status, response)
+		}
+	}
+
+	if got, _ := a.ruleRepo.GetByID(rule.ID); got != nil {
+		t.Error("Expected the member's delete to remove the rule")
+	}
+}
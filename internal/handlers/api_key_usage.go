@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"time"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyUsageHandler struct {
+	projectRepo  *models.ProjectRepository
+	usageRepo    *models.APIKeyUsageRepository
+	usageTracker *middleware.APIKeyUsageTracker
+}
+
+func NewAPIKeyUsageHandler(projectRepo *models.ProjectRepository, usageRepo *models.APIKeyUsageRepository, usageTracker *middleware.APIKeyUsageTracker) *APIKeyUsageHandler {
+	return &APIKeyUsageHandler{
+		projectRepo:  projectRepo,
+		usageRepo:    usageRepo,
+		usageTracker: usageTracker,
+	}
+}
+
+type APIKeyUsageResponse struct {
+	KeyPrefix   string     `json:"key_prefix"`
+	Current     bool       `json:"current"`
+	NeverUsed   bool       `json:"never_used"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	Requests24h *int64     `json:"requests_24h"` // null when request counting is unavailable
+}
+
+// GetKeyUsage handles GET /api/admin/projects/:id/keys/usage
+func (h *APIKeyUsageHandler) GetKeyUsage(c *fiber.Ctx) error {
+	project, err := h.projectRepo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+	if project == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	usages, err := h.usageRepo.GetByProjectID(project.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get key usage",
+		})
+	}
+
+	// The current key is always listed, even if it has never been used
+	keys := []*APIKeyUsageResponse{{KeyPrefix: project.APIKeyPrefix, Current: true, NeverUsed: true}}
+	for _, u := range usages {
+		lastUsed := u.LastUsedAt
+		if u.KeyPrefix == project.APIKeyPrefix {
+			keys[0].LastUsedAt = &lastUsed
+			keys[0].NeverUsed = false
+			continue
+		}
+		keys = append(keys, &APIKeyUsageResponse{KeyPrefix: u.KeyPrefix, LastUsedAt: &lastUsed})
+	}
+
+	if h.usageTracker != nil {
+		for _, k := range keys {
+			if count, ok := h.usageTracker.Requests24h(project.ID, k.KeyPrefix); ok {
+				k.Requests24h = &count
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"keys": keys,
+	})
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	ArchiveFormatJSONL = "jsonl.gz"
+	ArchiveFormatTar   = "tar.gz"
+
+	// archiveBatchSize is how many logs are read from the database at a time
+	archiveBatchSize = 1000
+	// archivePartBytes caps each logs file inside a tar.gz. Tar needs a file's
+	// size before its contents, so a part is held in memory until it is full.
+	archivePartBytes = 4 << 20
+)
+
+type ArchiveHandler struct {
+	logRepo     *models.LogRepository
+	projectRepo *models.ProjectRepository
+}
+
+func NewArchiveHandler(logRepo *models.LogRepository, projectRepo *models.ProjectRepository) *ArchiveHandler {
+	return &ArchiveHandler{
+		logRepo:     logRepo,
+		projectRepo: projectRepo,
+	}
+}
+
+// ArchiveFilter records which logs an archive was asked for
+type ArchiveFilter struct {
+	Start     *time.Time `json:"start,omitempty"` // Unset means from the first log
+	End       time.Time  `json:"end"`
+	TimeField string     `json:"time_field"`
+}
+
+// ArchiveManifest describes an archive's contents. It is the last line of a
+// jsonl.gz archive, under a "manifest" key, and manifest.json in a tar.gz.
+type ArchiveManifest struct {
+	ProjectID     string         `json:"project_id"`
+	ProjectName   string         `json:"project_name"`
+	Format        string         `json:"format"`
+	Filter        ArchiveFilter  `json:"filter"`
+	Count         int            `json:"count"`
+	CountsByLevel map[string]int `json:"counts_by_level"`
+	Files         []string       `json:"files,omitempty"` // Log files in a tar.gz, in order
+	GeneratedAt   time.Time      `json:"generated_at"`
+}
+
+// DownloadArchive handles GET /api/admin/projects/:id/archive. It streams the
+// project's logs between start and end, oldest first, as gzipped JSON lines
+// or a tar.gz of JSON lines files. If reading fails partway the gzip stream
+// is left unterminated, so a truncated download fails to decompress rather
+// than passing for a complete one.
+func (h *ArchiveHandler) DownloadArchive(c *fiber.Ctx) error {
+	format := c.Query("format", ArchiveFormatJSONL)
+	if format != ArchiveFormatJSONL && format != ArchiveFormatTar {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("format must be %q or %q", ArchiveFormatJSONL, ArchiveFormatTar),
+		})
+	}
+
+	filter := ArchiveFilter{End: time.Now().UTC(), TimeField: models.LogTimeFieldTimestamp}
+	if start := c.Query("start"); start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "start must be an RFC3339 time",
+			})
+		}
+		filter.Start = &t
+	}
+	if end := c.Query("end"); end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "end must be an RFC3339 time",
+			})
+		}
+		filter.End = t
+	}
+	if filter.Start != nil && filter.End.Before(*filter.Start) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "end must not be before start",
+		})
+	}
+
+	project, err := h.projectRepo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+	if project == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	manifest := &ArchiveManifest{
+		ProjectID:     project.ID,
+		ProjectName:   project.Name,
+		Format:        format,
+		Filter:        filter,
+		CountsByLevel: make(map[string]int),
+	}
+	logFilter := &models.LogFilter{
+		ProjectIDs: []string{project.ID},
+		StartTime:  filter.Start,
+		EndTime:    &filter.End,
+		TimeField:  filter.TimeField,
+	}
+
+	filename := fmt.Sprintf("%s-logs-%s.%s", project.ID, filter.End.Format("20060102-150405"), format)
+	c.Set(fiber.HeaderContentType, "application/gzip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// The handler returns before the body is written, so nothing below may
+	// touch c
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.writeArchive(w, logFilter, manifest); err != nil {
+			log.Printf("Failed to write archive for project %s: %v", manifest.ProjectID, err)
+			w.Flush()
+		}
+	})
+	return nil
+}
+
+// writeArchive streams the matching logs to w, then the manifest
+func (h *ArchiveHandler) writeArchive(w *bufio.Writer, filter *models.LogFilter, manifest *ArchiveManifest) error {
+	gz := gzip.NewWriter(w)
+	var archive archiveWriter = &jsonlArchive{enc: json.NewEncoder(gz)}
+	if manifest.Format == ArchiveFormatTar {
+		archive = &tarArchive{tw: tar.NewWriter(gz)}
+	}
+
+	err := h.logRepo.Walk(filter, archiveBatchSize, func(entry *models.Log) error {
+		if err := archive.add(entry); err != nil {
+			return err
+		}
+		manifest.Count++
+		manifest.CountsByLevel[string(entry.Level)]++
+		if manifest.Count%archiveBatchSize == 0 {
+			// Hand what is compressed so far to the client
+			return w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	manifest.GeneratedAt = time.Now().UTC()
+	if err := archive.finish(manifest); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// archiveWriter lays logs and the manifest out in one archive format
+type archiveWriter interface {
+	add(entry *models.Log) error
+	finish(manifest *ArchiveManifest) error
+}
+
+// jsonlArchive writes one log per line and the manifest as the last line
+type jsonlArchive struct {
+	enc *json.Encoder
+}
+
+func (a *jsonlArchive) add(entry *models.Log) error {
+	return a.enc.Encode(entry)
+}
+
+func (a *jsonlArchive) finish(manifest *ArchiveManifest) error {
+	return a.enc.Encode(struct {
+		Manifest *ArchiveManifest `json:"manifest"`
+	}{manifest})
+}
+
+// tarArchive splits logs across logs/part-NNNNN.jsonl files of up to
+// archivePartBytes each and ends with manifest.json
+type tarArchive struct {
+	tw    *tar.Writer
+	part  bytes.Buffer
+	files []string
+}
+
+func (a *tarArchive) add(entry *models.Log) error {
+	if err := json.NewEncoder(&a.part).Encode(entry); err != nil {
+		return err
+	}
+	if a.part.Len() >= archivePartBytes {
+		return a.flushPart()
+	}
+	return nil
+}
+
+func (a *tarArchive) flushPart() error {
+	name := fmt.Sprintf("logs/part-%05d.jsonl", len(a.files)+1)
+	if err := a.writeFile(name, a.part.Bytes()); err != nil {
+		return err
+	}
+	a.files = append(a.files, name)
+	a.part.Reset()
+	return nil
+}
+
+func (a *tarArchive) writeFile(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := a.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := a.tw.Write(data)
+	return err
+}
+
+func (a *tarArchive) finish(manifest *ArchiveManifest) error {
+	if a.part.Len() > 0 {
+		if err := a.flushPart(); err != nil {
+			return err
+		}
+	}
+	manifest.Files = a.files
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := a.writeFile("manifest.json", data); err != nil {
+		return err
+	}
+	return a.tw.Close()
+}
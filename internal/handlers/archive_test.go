@@ -0,0 +1,190 @@
+package handlers_test
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"central-logs/internal/handlers"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestArchiveHandler_DownloadArchive(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	logRepo := models.NewLogRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+
+	project := &models.Project{Name: "Shop", IsActive: true}
+	projectRepo.Create(project)
+	other := &models.Project{Name: "Other", IsActive: true}
+	projectRepo.Create(other)
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var logs []*models.Log
+	for i := 0; i < 5; i++ {
+		level := models.LogLevelInfo
+		if i%2 == 1 {
+			level = models.LogLevelError
+		}
+		logs = append(logs, &models.Log{
+			ProjectID: project.ID,
+			Level:     level,
+			Message:   fmt.Sprintf("event %d", i),
+			Metadata:  map[string]interface{}{"order": float64(i)},
+			Timestamp: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	// Outside the range and in another project
+	logs = append(logs,
+		&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "too late", Timestamp: base.Add(48 * time.Hour)},
+		&models.Log{ProjectID: other.ID, Level: models.LogLevelInfo, Message: "other project", Timestamp: base},
+	)
+	if err := logRepo.CreateBatch(logs); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/projects/:id/archive", handlers.NewArchiveHandler(logRepo, projectRepo).DownloadArchive)
+
+	download := func(query string) (int, []byte) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/projects/"+project.ID+"/archive"+query, nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, body
+	}
+
+	rangeQuery := "?start=2025-03-01T00:00:00Z&end=2025-03-02T00:00:00Z"
+
+	checkManifest := func(t *testing.T, manifest *handlers.ArchiveManifest, format string) {
+		if manifest.Count != 5 || manifest.Format != format || manifest.ProjectID != project.ID {
+			t.Errorf("Unexpected manifest: %+v", manifest)
+		}
+		if manifest.CountsByLevel["INFO"] != 3 || manifest.CountsByLevel["ERROR"] != 2 {
+			t.Errorf("Unexpected level counts: %v", manifest.CountsByLevel)
+		}
+		if manifest.Filter.Start == nil || !manifest.Filter.Start.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) ||
+			!manifest.Filter.End.Equal(time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected the requested range in the manifest, got %+v", manifest.Filter)
+		}
+	}
+
+	checkLogs := func(t *testing.T, got []*models.Log) {
+		if len(got) != 5 {
+			t.Fatalf("Expected 5 logs, got %d", len(got))
+		}
+		for i, l := range got {
+			want := logs[i]
+			if l.ID != want.ID || l.Message != want.Message || l.Level != want.Level ||
+				!l.Timestamp.Equal(want.Timestamp) || l.Metadata["order"] != float64(i) {
+				t.Errorf("Log %d did not round-trip: got %+v, want %+v", i, l, want)
+			}
+		}
+	}
+
+	t.Run("jsonl.gz", func(t *testing.T) {
+		status, body := download(rangeQuery)
+		if status != fiber.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", status, body)
+		}
+		gz, err := gzip.NewReader(strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("Expected a gzip body: %v", err)
+		}
+
+		var got []*models.Log
+		var manifest *handlers.ArchiveManifest
+		scanner := bufio.NewScanner(gz)
+		for scanner.Scan() {
+			var line struct {
+				Manifest *handlers.ArchiveManifest `json:"manifest"`
+			}
+			json.Unmarshal(scanner.Bytes(), &line)
+			if line.Manifest != nil {
+				manifest = line.Manifest
+				continue
+			}
+			if manifest != nil {
+				t.Fatal("Expected the manifest to be the last line")
+			}
+			var l models.Log
+			if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+				t.Fatalf("Failed to decode log line: %v", err)
+			}
+			got = append(got, &l)
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatalf("Failed to read archive: %v", err)
+		}
+		if manifest == nil {
+			t.Fatal("Expected a manifest line")
+		}
+		checkLogs(t, got)
+		checkManifest(t, manifest, handlers.ArchiveFormatJSONL)
+	})
+
+	t.Run("tar.gz", func(t *testing.T) {
+		status, body := download(rangeQuery + "&format=tar.gz")
+		if status != fiber.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", status, body)
+		}
+		gz, err := gzip.NewReader(strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("Expected a gzip body: %v", err)
+		}
+
+		var got []*models.Log
+		var manifest handlers.ArchiveManifest
+		var files []string
+		tr := tar.NewReader(gz)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Failed to read tar: %v", err)
+			}
+			dec := json.NewDecoder(tr)
+			if header.Name == "manifest.json" {
+				if err := dec.Decode(&manifest); err != nil {
+					t.Fatalf("Failed to decode manifest: %v", err)
+				}
+				continue
+			}
+			files = append(files, header.Name)
+			for dec.More() {
+				var l models.Log
+				if err := dec.Decode(&l); err != nil {
+					t.Fatalf("Failed to decode log: %v", err)
+				}
+				got = append(got, &l)
+			}
+		}
+		checkLogs(t, got)
+		checkManifest(t, &manifest, handlers.ArchiveFormatTar)
+		if len(manifest.Files) != 1 || len(files) != 1 || manifest.Files[0] != files[0] {
+			t.Errorf("Expected the manifest to list the log files %v, got %v", files, manifest.Files)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, query := range []string{"?format=zip", "?start=yesterday", "?start=2025-03-02T00:00:00Z&end=2025-03-01T00:00:00Z"} {
+			if status, _ := download(query); status != fiber.StatusBadRequest {
+				t.Errorf("Expected 400 for %s, got %d", query, status)
+			}
+		}
+	})
+}
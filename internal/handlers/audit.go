@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditRecorder writes administrative actions to the audit trail. Recording
+// is best-effort: a failed write is logged and never fails the request that
+// triggered it. A nil recorder records nothing.
+type AuditRecorder struct {
+	repo *models.AuditLogRepository
+}
+
+func NewAuditRecorder(repo *models.AuditLogRepository) *AuditRecorder {
+	return &AuditRecorder{repo: repo}
+}
+
+// Record stores an action taken by the authenticated user on the given target.
+// The actor is the impersonated user; an impersonating admin is named in the
+// details.
+func (r *AuditRecorder) Record(c *fiber.Ctx, action, targetType, targetID, details string) {
+	if r == nil || r.repo == nil {
+		return
+	}
+
+	entry := &models.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    details,
+		IPAddress:  c.IP(),
+	}
+	if user := middleware.GetUser(c); user != nil {
+		entry.ActorID = user.ID
+		entry.ActorName = user.Username
+	}
+	if admin := middleware.GetImpersonator(c); admin != nil {
+		entry.Details = strings.TrimSpace(entry.Details + " impersonated_by=" + admin.Username)
+	}
+
+	if err := r.repo.Create(entry); err != nil {
+		log.Printf("[Audit] Failed to record %s on %s %s: %v", action, targetType, targetID, err)
+	}
+}
+
+type AuditHandler struct {
+	auditRepo *models.AuditLogRepository
+}
+
+func NewAuditHandler(auditRepo *models.AuditLogRepository) *AuditHandler {
+	return &AuditHandler{auditRepo: auditRepo}
+}
+
+// ListAuditLogs handles GET /api/admin/audit (Admin only)
+func (h *AuditHandler) ListAuditLogs(c *fiber.Ctx) error {
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	entries, total, err := h.auditRepo.List(models.AuditLogFilter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list audit logs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"audit_logs": entries,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
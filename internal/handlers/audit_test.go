@@ -0,0 +1,172 @@
+package handlers_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"central-logs/internal/handlers"
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+	"central-logs/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func createAuditLogsTable(t *testing.T, db *sql.DB) {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_logs (
+			id TEXT PRIMARY KEY,
+			actor_id TEXT NOT NULL,
+			actor_name TEXT NOT NULL,
+			action TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			details TEXT,
+			ip_address TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create audit_logs table: %v", err)
+	}
+}
+
+func TestAudit_RecordsAdminActions(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+	createAuditLogsTable(t, db)
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	auditRepo := models.NewAuditLogRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	recorder := handlers.NewAuditRecorder(auditRepo)
+	userHandler := handlers.NewUserHandler(userRepo)
+	userHandler.SetAuditRecorder(recorder)
+	memberHandler := handlers.NewMemberHandler(userRepo, userProjectRepo)
+	memberHandler.SetAuditRecorder(recorder)
+	auditHandler := handlers.NewAuditHandler(auditRepo)
+
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Password: "password123", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	victim := &models.User{Username: "victim", Email: "victim@example.com", Password: "password123", Name: "Victim", Role: models.RoleUser, IsActive: true}
+	member := &models.User{Username: "member", Email: "member@example.com", Password: "password123", Name: "Member", Role: models.RoleUser, IsActive: true}
+	userRepo.Create(admin)
+	userRepo.Create(victim)
+	userRepo.Create(member)
+
+	project := &models.Project{Name: "Audited", IsActive: true}
+	projectRepo.Create(project)
+	userProjectRepo.Create(&models.UserProject{UserID: member.ID, ProjectID: project.ID, Role: models.ProjectRoleViewer})
+
+	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Delete("/users/:id", userHandler.DeleteUser)
+	app.Put("/projects/:id/members/:uid", memberHandler.UpdateMember)
+	app.Get("/audit", auditHandler.ListAuditLogs)
+
+	do := func(method, path string, body []byte) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	if resp := do(http.MethodDelete, "/users/"+victim.ID, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected delete to succeed, got %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodPut, "/projects/"+project.ID+"/members/"+member.ID, []byte(`{"role":"OWNER"}`)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected role change to succeed, got %d", resp.StatusCode)
+	}
+
+	entries, total, err := auditRepo.List(models.AuditLogFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to list audit logs: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d", total)
+	}
+	for _, e := range entries {
+		if e.ActorID != admin.ID || e.ActorName != "admin" {
+			t.Errorf("Expected admin as actor, got %s/%s", e.ActorID, e.ActorName)
+		}
+	}
+
+	var response struct {
+		AuditLogs []models.AuditLog `json:"audit_logs"`
+		Total     int               `json:"total"`
+	}
+	resp := do(http.MethodGet, "/audit?actor=admin&action="+models.AuditMemberRoleChange, nil)
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	if response.Total != 1 || len(response.AuditLogs) != 1 {
+		t.Fatalf("Expected 1 role change entry, got %d: %s", response.Total, body)
+	}
+	entry := response.AuditLogs[0]
+	if entry.TargetType != "project" || entry.TargetID != project.ID {
+		t.Errorf("Expected project target %s, got %s %s", project.ID, entry.TargetType, entry.TargetID)
+	}
+	if want := "user=" + member.ID + " role=VIEWER->OWNER"; entry.Details != want {
+		t.Errorf("Expected details %q, got %q", want, entry.Details)
+	}
+
+	response.AuditLogs, response.Total = nil, 0
+	resp = do(http.MethodGet, "/audit?action="+models.AuditUserDelete, nil)
+	body, _ = io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+	if response.Total != 1 || response.AuditLogs[0].TargetID != victim.ID {
+		t.Errorf("Expected the deleted user as target, got %s", body)
+	}
+}
+
+func TestAudit_RecordingFailureDoesNotBlockAction(t *testing.T) {
+	// No audit_logs table, so every write fails
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	userHandler := handlers.NewUserHandler(userRepo)
+	userHandler.SetAuditRecorder(handlers.NewAuditRecorder(models.NewAuditLogRepository(db)))
+
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Password: "password123", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	userRepo.Create(admin)
+	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Post("/users", userHandler.CreateUser)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(`{"username":"new","password":"password123","name":"New"}`)))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", resp.StatusCode)
+	}
+	if created, _ := userRepo.GetByUsername("new"); created == nil {
+		t.Error("Expected user to be created despite audit failure")
+	}
+}
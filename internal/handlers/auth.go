@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"log"
+	"strings"
+
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
 	"central-logs/internal/utils"
@@ -9,8 +12,9 @@ import (
 )
 
 type AuthHandler struct {
-	userRepo   *models.UserRepository
-	jwtManager *utils.JWTManager
+	userRepo      *models.UserRepository
+	jwtManager    *utils.JWTManager
+	revokedTokens *models.RevokedTokenRepository
 }
 
 func NewAuthHandler(userRepo *models.UserRepository, jwtManager *utils.JWTManager) *AuthHandler {
@@ -20,8 +24,13 @@ func NewAuthHandler(userRepo *models.UserRepository, jwtManager *utils.JWTManage
 	}
 }
 
+// SetRevokedTokens enables Logout to denylist the caller's token
+func (h *AuthHandler) SetRevokedTokens(revokedTokens *models.RevokedTokenRepository) {
+	h.revokedTokens = revokedTokens
+}
+
 type LoginRequest struct {
-	Username string `json:"username"`
+	Username string `json:"username"` // Username or the account's email
 	Password string `json:"password"`
 }
 
@@ -47,6 +56,9 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 
 	user, err := h.userRepo.GetByUsername(req.Username)
+	if err == nil && user == nil && strings.Contains(req.Username, "@") {
+		user, err = h.userRepo.GetByEmail(req.Username)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to authenticate",
@@ -81,12 +93,13 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	token, err := h.jwtManager.Generate(user.ID, user.Username, string(user.Role))
+	token, err := h.jwtManager.GenerateForVersion(user.ID, user.Username, string(user.Role), user.TokenVersion)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
+	recordLogin(h.userRepo, user.ID)
 
 	return c.JSON(LoginResponse{
 		Token: token,
@@ -94,6 +107,39 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	})
 }
 
+// recordLogin stamps the user's last login in the background. A failed
+// write is only logged; it must never hold up or fail the login.
+func recordLogin(userRepo *models.UserRepository, userID string) {
+	go func() {
+		if err := userRepo.UpdateLastLogin(userID); err != nil {
+			log.Printf("[Auth] Failed to record last login for user %s: %v", userID, err)
+		}
+	}()
+}
+
+// Logout handles POST /api/auth/logout. The token used for the request is
+// revoked so it stops working even though it has not expired yet.
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	if h.revokedTokens != nil && claims.ID != "" && claims.ExpiresAt != nil {
+		if err := h.revokedTokens.Revoke(claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke token",
+			})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out successfully",
+	})
+}
+
 func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
 	if user == nil {
@@ -128,8 +174,9 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 		user.Name = req.Name
 	}
 
+	req.Email = models.NormalizeEmail(req.Email)
 	if req.Email != "" {
-		// Check if email is already in use by another user
+		// Check if email is already in use by another user, ignoring case
 		existingUser, _ := h.userRepo.GetByEmail(req.Email)
 		if existingUser != nil && existingUser.ID != user.ID {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
@@ -174,9 +221,9 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	if len(req.NewPassword) < 8 {
+	if err := utils.ValidatePassword(req.NewPassword); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "New password must be at least 8 characters",
+			"error": err.Error(),
 		})
 	}
 
@@ -86,6 +86,91 @@ func TestAuthHandler_Login_Success(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_RecordsLastLogin(t *testing.T) {
+	db := setupAuthTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authHandler := handlers.NewAuthHandler(userRepo, jwtManager)
+
+	user := &models.User{Username: "testuser", Email: "test@example.com", Password: "password123", Name: "Test User", Role: models.RoleUser, IsActive: true}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	app := fiber.New()
+	app.Post("/login", authHandler.Login)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader([]byte(`{"username":"testuser","password":"password123"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	// The timestamp is written in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		found, _ := userRepo.GetByID(user.ID)
+		if found != nil && found.LastLoginAt != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected last login to be recorded after a successful login")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAuthHandler_Login_EmailIgnoresCase(t *testing.T) {
+	db := setupAuthTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authHandler := handlers.NewAuthHandler(userRepo, jwtManager)
+
+	userRepo.Create(&models.User{
+		Username: "testuser",
+		Email:    "Test@Example.com",
+		Password: "password123",
+		Name:     "Test User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	})
+
+	app := fiber.New()
+	app.Post("/login", authHandler.Login)
+
+	bodyBytes, _ := json.Marshal(map[string]string{
+		"username": "TEST@example.COM",
+		"password": "password123",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var response handlers.LoginResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	if response.Token == "" || response.User == nil || response.User.Username != "testuser" {
+		t.Errorf("Expected testuser to be logged in, got %s", body)
+	}
+}
+
 func TestAuthHandler_Login_InvalidBody(t *testing.T) {
 	db := setupAuthTestDB(t)
 	defer db.Close()
@@ -484,6 +569,40 @@ func TestAuthHandler_UpdateProfile_EmailAlreadyInUse(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_UpdateProfile_EmailInUseDifferentCase(t *testing.T) {
+	db := setupAuthTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authHandler := handlers.NewAuthHandler(userRepo, jwtManager)
+
+	user1 := &models.User{Username: "user1", Email: "user1@example.com", Password: "password123", Name: "User 1", Role: models.RoleUser, IsActive: true}
+	user2 := &models.User{Username: "user2", Email: "user2@example.com", Password: "password123", Name: "User 2", Role: models.RoleUser, IsActive: true}
+	userRepo.Create(user1)
+	userRepo.Create(user2)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", user1)
+		return c.Next()
+	})
+	app.Put("/profile", authHandler.UpdateProfile)
+
+	bodyBytes, _ := json.Marshal(map[string]string{"email": "User2@Example.com"})
+	req := httptest.NewRequest(http.MethodPut, "/profile", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", resp.StatusCode)
+	}
+}
+
 func TestAuthHandler_ChangePassword_Success(t *testing.T) {
 	db := setupAuthTestDB(t)
 	defer db.Close()
@@ -723,3 +842,93 @@ func TestAuthHandler_ChangePassword_WrongCurrentPassword(t *testing.T) {
 		t.Errorf("Expected status 401, got %d", resp.StatusCode)
 	}
 }
+
+func TestAuth_RevokedTokensStopWorking(t *testing.T) {
+	db := setupAuthTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	revokedTokens := models.NewRevokedTokenRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+	authMiddleware.SetRevokedTokens(revokedTokens)
+	authHandler := handlers.NewAuthHandler(userRepo, jwtManager)
+	authHandler.SetRevokedTokens(revokedTokens)
+	userHandler := handlers.NewUserHandler(userRepo)
+
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Password: "password123", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	user := &models.User{Username: "testuser", Email: "test@example.com", Password: "password123", Name: "Test User", Role: models.RoleUser, IsActive: true}
+	userRepo.Create(admin)
+	userRepo.Create(user)
+	adminToken, _ := jwtManager.Generate(admin.ID, admin.Username, string(admin.Role))
+
+	app := fiber.New()
+	app.Post("/login", authHandler.Login)
+	protected := app.Group("", authMiddleware.RequireAuth())
+	protected.Get("/me", authHandler.Me)
+	protected.Post("/logout", authHandler.Logout)
+	protected.Post("/users/:id/revoke-sessions", userHandler.RevokeSessions)
+
+	login := func() string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader([]byte(`{"username":"testuser","password":"password123"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var response handlers.LoginResponse
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		if response.Token == "" {
+			t.Fatalf("Expected login to return a token, got %s", body)
+		}
+		return response.Token
+	}
+	call := func(method, path, token string) int {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	first, second := login(), login()
+	if first == second {
+		t.Fatal("Expected every token to get its own jti")
+	}
+
+	// Logging out revokes only the token used
+	if status := call(http.MethodPost, "/logout", first); status != http.StatusOK {
+		t.Fatalf("Expected logout to succeed, got %d", status)
+	}
+	if status := call(http.MethodGet, "/me", first); status != http.StatusUnauthorized {
+		t.Errorf("Expected logged-out token to be rejected, got %d", status)
+	}
+	if status := call(http.MethodGet, "/me", second); status != http.StatusOK {
+		t.Errorf("Expected other session to keep working, got %d", status)
+	}
+
+	// Revoking sessions kills every token issued so far
+	if status := call(http.MethodPost, "/users/"+user.ID+"/revoke-sessions", adminToken); status != http.StatusOK {
+		t.Fatalf("Expected revoke-sessions to succeed, got %d", status)
+	}
+	if status := call(http.MethodGet, "/me", second); status != http.StatusUnauthorized {
+		t.Errorf("Expected revoked session to be rejected, got %d", status)
+	}
+	if status := call(http.MethodGet, "/me", adminToken); status != http.StatusOK {
+		t.Errorf("Expected other users' sessions to be unaffected, got %d", status)
+	}
+
+	// A fresh login picks up the new token version
+	if status := call(http.MethodGet, "/me", login()); status != http.StatusOK {
+		t.Errorf("Expected a new login to work after revocation, got %d", status)
+	}
+
+	if status := call(http.MethodPost, "/users/missing/revoke-sessions", adminToken); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown user, got %d", status)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"time"
+
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// channelHealthWindow is the period the recent attempt counts and success
+// rate are computed over
+const channelHealthWindow = 24 * time.Hour
+
+// ChannelHealthEntry is a channel, with its credentials redacted, alongside
+// how its recent deliveries went
+type ChannelHealthEntry struct {
+	ID       string                 `json:"id"`
+	Type     models.ChannelType     `json:"type"`
+	Name     string                 `json:"name"`
+	Config   map[string]interface{} `json:"config"`
+	MinLevel models.LogLevel        `json:"min_level"`
+	IsActive bool                   `json:"is_active"`
+	Health   *models.ChannelHealth  `json:"health"`
+}
+
+// ChannelHealth handles GET /api/admin/projects/:id/channels/health
+func (h *ChannelHandler) ChannelHealth(c *fiber.Ctx) error {
+	if h.deliveryRepo == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Delivery tracking is not available",
+		})
+	}
+
+	channels, err := h.channelRepo.GetByProjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list channels",
+		})
+	}
+
+	since := time.Now().Add(-channelHealthWindow)
+	entries := make([]ChannelHealthEntry, 0, len(channels))
+	for _, channel := range channels {
+		health, err := h.deliveryRepo.Health(channel.ID, since)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get channel health",
+			})
+		}
+		entries = append(entries, ChannelHealthEntry{
+			ID:       channel.ID,
+			Type:     channel.Type,
+			Name:     channel.Name,
+			Config:   redactChannelConfig(channel.Config),
+			MinLevel: channel.MinLevel,
+			IsActive: channel.IsActive,
+			Health:   health,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"channels":     entries,
+		"window_hours": int(channelHealthWindow.Hours()),
+	})
+}
+
+// redactChannelConfig returns a copy of the config with credentials masked
+func redactChannelConfig(cfg map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(cfg))
+	for k, v := range cfg {
+		redacted[k] = v
+	}
+	for _, key := range models.ChannelSecretKeys {
+		switch v := redacted[key].(type) {
+		case string:
+			if v != "" {
+				redacted[key] = "[REDACTED]"
+			}
+		case []interface{}:
+			masked := make([]interface{}, len(v))
+			for i := range v {
+				masked[i] = "[REDACTED]"
+			}
+			redacted[key] = masked
+		}
+	}
+	return redacted
+}
@@ -1,19 +1,241 @@
 package handlers
 
 import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"time"
+
+	"central-logs/internal/config"
 	"central-logs/internal/models"
+	"central-logs/internal/queue"
+	"central-logs/internal/worker"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type ChannelHandler struct {
-	channelRepo *models.ChannelRepository
+	channelRepo  *models.ChannelRepository
+	deliveryRepo *models.ChannelDeliveryRepository
+	notifier     *worker.Notifier
+	redisClient  *queue.RedisClient
+	config       *config.Config
 }
 
-func NewChannelHandler(channelRepo *models.ChannelRepository) *ChannelHandler {
+func NewChannelHandler(channelRepo *models.ChannelRepository, cfg *config.Config) *ChannelHandler {
 	return &ChannelHandler{
 		channelRepo: channelRepo,
+		config:      cfg,
+	}
+}
+
+// SetDeliveryRepository enables the channel health endpoint
+func (h *ChannelHandler) SetDeliveryRepository(deliveryRepo *models.ChannelDeliveryRepository) {
+	h.deliveryRepo = deliveryRepo
+}
+
+// SetNotifier lets TestChannel actually send its sample notification
+func (h *ChannelHandler) SetNotifier(notifier *worker.Notifier) {
+	h.notifier = notifier
+}
+
+// SetQueue enables queued channel tests, which go through the worker
+func (h *ChannelHandler) SetQueue(redisClient *queue.RedisClient) {
+	h.redisClient = redisClient
+}
+
+// ChannelFieldError describes a single problem with a channel definition
+type ChannelFieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"` // "missing" or "invalid"
+	Detail string `json:"detail"`
+}
+
+type ValidateChannelRequest struct {
+	Type     models.ChannelType     `json:"type"`
+	Config   map[string]interface{} `json:"config"`
+	MinLevel models.LogLevel        `json:"min_level"`
+}
+
+// ValidateChannel handles POST /api/admin/channels/validate
+// It checks a channel definition without persisting or sending anything.
+func (h *ChannelHandler) ValidateChannel(c *fiber.Ctx) error {
+	var req ValidateChannelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	fieldErrors := h.validateChannelConfig(req.Type, req.Config, req.MinLevel)
+	if fieldErrors == nil {
+		fieldErrors = []ChannelFieldError{}
+	}
+
+	return c.JSON(fiber.Map{
+		"valid":  len(fieldErrors) == 0,
+		"errors": fieldErrors,
+	})
+}
+
+// validateChannelConfig checks the type-specific required fields of a channel.
+// An empty min level is allowed since callers apply their own default.
+func (h *ChannelHandler) validateChannelConfig(channelType models.ChannelType, cfg map[string]interface{}, minLevel models.LogLevel) []ChannelFieldError {
+	var errs []ChannelFieldError
+
+	if minLevel != "" && minLevel.Priority() < 0 {
+		errs = append(errs, ChannelFieldError{Field: "min_level", Reason: "invalid", Detail: "Must be one of DEBUG, INFO, WARN, ERROR, CRITICAL"})
+	}
+
+	switch channelType {
+	case models.ChannelTypeTelegram:
+		// bot_token is optional when a global bot token is configured
+		if configString(cfg, "bot_token") == "" && (h.config == nil || h.config.Telegram.BotToken == "") {
+			errs = append(errs, ChannelFieldError{Field: "config.bot_token", Reason: "missing", Detail: "Telegram requires bot_token when no global bot token is configured"})
+		}
+		if configString(cfg, "chat_id") == "" {
+			errs = append(errs, ChannelFieldError{Field: "config.chat_id", Reason: "missing", Detail: "Telegram requires chat_id"})
+		}
+	case models.ChannelTypeDiscord:
+		webhookURL := configString(cfg, "webhook_url")
+		if webhookURL == "" {
+			errs = append(errs, ChannelFieldError{Field: "config.webhook_url", Reason: "missing", Detail: "Discord requires webhook_url"})
+		} else if !isHTTPURL(webhookURL) {
+			errs = append(errs, ChannelFieldError{Field: "config.webhook_url", Reason: "invalid", Detail: "webhook_url must be an http(s) URL"})
+		}
+	case models.ChannelTypePush:
+		if h.config == nil || h.config.VAPID.PublicKey == "" || h.config.VAPID.PrivateKey == "" {
+			errs = append(errs, ChannelFieldError{Field: "vapid", Reason: "missing", Detail: "Push notifications require VAPID keys in the server config"})
+		}
+	case models.ChannelTypeApprise:
+		errs = append(errs, h.validateAppriseConfig(cfg)...)
+	case models.ChannelTypeTeams:
+		webhookURL := configString(cfg, "webhook_url")
+		if webhookURL == "" {
+			errs = append(errs, ChannelFieldError{Field: "config.webhook_url", Reason: "missing", Detail: "Teams requires webhook_url"})
+		} else if u, err := url.Parse(webhookURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, ChannelFieldError{Field: "config.webhook_url", Reason: "invalid", Detail: "webhook_url must be an https URL"})
+		}
+	case models.ChannelTypeEmail:
+		errs = append(errs, h.validateEmailConfig(cfg)...)
+	case models.ChannelTypePagerDuty:
+		routingKey := configString(cfg, "routing_key")
+		if routingKey == "" {
+			errs = append(errs, ChannelFieldError{Field: "config.routing_key", Reason: "missing", Detail: "PagerDuty requires the routing_key of an Events API v2 integration"})
+		} else if len(routingKey) != 32 {
+			errs = append(errs, ChannelFieldError{Field: "config.routing_key", Reason: "invalid", Detail: "routing_key must be the 32-character integration key"})
+		}
+	default:
+		errs = append(errs, ChannelFieldError{Field: "type", Reason: "invalid", Detail: "Must be PUSH, TELEGRAM, DISCORD, APPRISE, TEAMS, EMAIL, or PAGERDUTY"})
+	}
+
+	if raw, ok := cfg["source_levels"]; ok && raw != nil {
+		overrides, isMap := raw.(map[string]interface{})
+		if !isMap {
+			errs = append(errs, ChannelFieldError{Field: "config.source_levels", Reason: "invalid", Detail: "source_levels must be an object mapping source to level"})
+		}
+		sources := make([]string, 0, len(overrides))
+		for source := range overrides {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			if s, _ := overrides[source].(string); models.LogLevel(s).Priority() < 0 {
+				errs = append(errs, ChannelFieldError{Field: "config.source_levels." + source, Reason: "invalid", Detail: "Must be one of DEBUG, INFO, WARN, ERROR, CRITICAL"})
+			}
+		}
+	}
+
+	if raw, ok := cfg["quiet_hours"]; ok && raw != nil {
+		if _, isMap := raw.(map[string]interface{}); !isMap {
+			errs = append(errs, ChannelFieldError{Field: "config.quiet_hours", Reason: "invalid", Detail: "quiet_hours must be an object"})
+		} else {
+			quiet := (&models.Channel{Config: cfg}).GetQuietHours()
+			if quiet == nil {
+				errs = append(errs, ChannelFieldError{Field: "config.quiet_hours", Reason: "invalid", Detail: "quiet_hours has fields of the wrong type"})
+			} else if field, err := quiet.Validate(); err != nil {
+				errs = append(errs, ChannelFieldError{Field: "config.quiet_hours." + field, Reason: "invalid", Detail: err.Error()})
+			}
+		}
+	}
+
+	if digest, ok := cfg["digest"]; ok && digest != nil {
+		digestCfg, isMap := digest.(map[string]interface{})
+		if !isMap {
+			errs = append(errs, ChannelFieldError{Field: "config.digest", Reason: "invalid", Detail: "digest must be an object"})
+		} else if window, ok := digestCfg["window_seconds"].(float64); ok && window <= 0 {
+			errs = append(errs, ChannelFieldError{Field: "config.digest.window_seconds", Reason: "invalid", Detail: "window_seconds must be greater than 0"})
+		}
+	}
+
+	return errs
+}
+
+// validateAppriseConfig checks the server and target URLs of an Apprise
+// channel. Targets only need a scheme; Apprise itself knows which it supports.
+func (h *ChannelHandler) validateAppriseConfig(cfg map[string]interface{}) []ChannelFieldError {
+	var errs []ChannelFieldError
+
+	serverURL := configString(cfg, "server_url")
+	if serverURL == "" {
+		if h.config == nil || h.config.Apprise.ServerURL == "" {
+			errs = append(errs, ChannelFieldError{Field: "config.server_url", Reason: "missing", Detail: "Apprise requires server_url when no global Apprise server is configured"})
+		}
+	} else if !isHTTPURL(serverURL) {
+		errs = append(errs, ChannelFieldError{Field: "config.server_url", Reason: "invalid", Detail: "server_url must be an http(s) URL"})
+	}
+
+	targets, isList := cfg["urls"].([]interface{})
+	if !isList || len(targets) == 0 {
+		return append(errs, ChannelFieldError{Field: "config.urls", Reason: "missing", Detail: "Apprise requires a non-empty list of target urls"})
+	}
+	for i, target := range targets {
+		s, _ := target.(string)
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			errs = append(errs, ChannelFieldError{Field: fmt.Sprintf("config.urls.%d", i), Reason: "invalid", Detail: "Must be an Apprise URL such as slack://... or mailto://..."})
+		}
+	}
+	return errs
+}
+
+// validateEmailConfig checks the recipients and digest schedule of an email
+// channel, and that the server can send mail at all
+func (h *ChannelHandler) validateEmailConfig(cfg map[string]interface{}) []ChannelFieldError {
+	var errs []ChannelFieldError
+
+	if h.config == nil || h.config.SMTP.Host == "" {
+		errs = append(errs, ChannelFieldError{Field: "smtp", Reason: "missing", Detail: "Email channels require an SMTP server in the server config"})
+	}
+
+	digest := (&models.Channel{Config: cfg}).GetEmailDigestConfig()
+	if digest == nil {
+		return append(errs, ChannelFieldError{Field: "config", Reason: "invalid", Detail: "recipients must be a list of addresses and schedule fields must be strings"})
+	}
+	if field, err := digest.Validate(); err != nil {
+		errs = append(errs, ChannelFieldError{Field: "config." + field, Reason: "invalid", Detail: err.Error()})
+	}
+	for i, recipient := range digest.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			errs = append(errs, ChannelFieldError{Field: fmt.Sprintf("config.recipients.%d", i), Reason: "invalid", Detail: "Must be an email address"})
+		}
+	}
+	return errs
+}
+
+// configString returns a string config value, or "" when absent or not a string
+func configString(cfg map[string]interface{}, key string) string {
+	s, _ := cfg[key].(string)
+	return s
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
 	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
 // ListChannels handles GET /api/admin/projects/:id/channels
@@ -56,32 +278,19 @@ func (h *ChannelHandler) CreateChannel(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.Type != models.ChannelTypePush && req.Type != models.ChannelTypeTelegram && req.Type != models.ChannelTypeDiscord {
+	if fieldErrors := h.validateChannelConfig(req.Type, req.Config, req.MinLevel); len(fieldErrors) > 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid channel type. Must be PUSH, TELEGRAM, or DISCORD",
+			"error":  "Invalid channel configuration",
+			"fields": fieldErrors,
 		})
 	}
 
-	// Validate config based on type
-	if req.Type == models.ChannelTypeTelegram {
-		// Only chat_id is required - bot_token is optional (uses global config if empty)
-		if req.Config["chat_id"] == nil || req.Config["chat_id"] == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Telegram requires chat_id",
-			})
-		}
-	}
-
-	if req.Type == models.ChannelTypeDiscord {
-		if req.Config["webhook_url"] == nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Discord requires webhook_url",
-			})
-		}
-	}
-
 	if req.MinLevel == "" {
 		req.MinLevel = models.LogLevelError
+		// Paging someone is reserved for the worst by default
+		if req.Type == models.ChannelTypePagerDuty {
+			req.MinLevel = models.LogLevelCritical
+		}
 	}
 
 	channel := &models.Channel{
@@ -153,6 +362,19 @@ func (h *ChannelHandler) UpdateChannel(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.Config != nil || req.MinLevel != "" {
+		cfg := channel.Config
+		if req.Config != nil {
+			cfg = req.Config
+		}
+		if fieldErrors := h.validateChannelConfig(channel.Type, cfg, req.MinLevel); len(fieldErrors) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":  "Invalid channel configuration",
+				"fields": fieldErrors,
+			})
+		}
+	}
+
 	if req.Name != "" {
 		channel.Name = req.Name
 	}
@@ -190,7 +412,11 @@ func (h *ChannelHandler) DeleteChannel(c *fiber.Ctx) error {
 	})
 }
 
-// TestChannel handles POST /api/admin/channels/:id/test
+// TestChannel handles POST /api/admin/channels/:id/test. By default the
+// sample is sent right away and remote_status reports the HTTP status the
+// service answered with (0 for channel types that make no request). With
+// ?mode=queued it is enqueued like a real notification and delivered by the
+// worker; poll GetTestStatus with the returned job_id for the outcome.
 func (h *ChannelHandler) TestChannel(c *fiber.Ctx) error {
 	channelID := c.Params("id")
 
@@ -207,10 +433,95 @@ func (h *ChannelHandler) TestChannel(c *fiber.Ctx) error {
 		})
 	}
 
-	// TODO: Implement actual test notification sending
-	// For now, just return success
+	job := &queue.NotificationJob{
+		JobID:     uuid.New().String(),
+		Test:      true,
+		ChannelID: channel.ID,
+		ProjectID: channel.ProjectID,
+		Level:     string(models.LogLevelInfo),
+		Message:   "This is a test notification from Central Logs",
+		Source:    "central-logs",
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	switch c.Query("mode", "direct") {
+	case "direct":
+		if h.notifier == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Notification sending is not available",
+			})
+		}
+		remoteStatus, err := h.notifier.SendStatus(channel, worker.SampleLogEntry(job))
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"error":         "Test notification failed",
+				"detail":        h.notifier.RedactError(channel, err).Error(),
+				"remote_status": remoteStatus,
+			})
+		}
+		return c.JSON(fiber.Map{
+			"message":       "Test notification sent",
+			"channel":       channel.Name,
+			"remote_status": remoteStatus,
+		})
+	case "queued":
+		if h.redisClient == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Notification queue is not available",
+			})
+		}
+		if err := h.redisClient.EnqueueNotification(c.Context(), job); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to queue test notification",
+			})
+		}
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "Test notification queued",
+			"channel": channel.Name,
+			"job_id":  job.JobID,
+		})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid mode. Must be direct or queued",
+		})
+	}
+}
+
+// GetTestStatus handles GET /api/admin/channels/:id/test/:jobId. The status
+// is "pending" until the worker has attempted the job, then "delivered" or
+// "failed" with the (redacted) error.
+func (h *ChannelHandler) GetTestStatus(c *fiber.Ctx) error {
+	if h.deliveryRepo == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Delivery tracking is not available",
+		})
+	}
+
+	jobID := c.Params("jobId")
+	delivery, err := h.deliveryRepo.GetLatestByJobID(jobID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get delivery status",
+		})
+	}
+
+	if delivery == nil {
+		return c.JSON(fiber.Map{"job_id": jobID, "status": "pending"})
+	}
+	if delivery.ChannelID != c.Params("id") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Job not found for this channel",
+		})
+	}
+
+	status := "delivered"
+	if !delivery.Success {
+		status = "failed"
+	}
 	return c.JSON(fiber.Map{
-		"message": "Test notification sent",
-		"channel": channel.Name,
+		"job_id":       jobID,
+		"status":       status,
+		"error":        delivery.Error,
+		"attempted_at": delivery.CreatedAt,
 	})
 }
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ackBatchSize is how many logs each UPDATE of a bulk acknowledgement touches
+const ackBatchSize = 500
+
+// AckLogsRequest selects the logs to acknowledge with the same filters as
+// the log listing. At least one of them must be set.
+type AckLogsRequest struct {
+	ProjectIDs []string   `json:"project_ids,omitempty"`
+	Levels     []string   `json:"levels,omitempty"`
+	Source     string     `json:"source,omitempty"`
+	Search     string     `json:"search,omitempty"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	EndTime    *time.Time `json:"end_time,omitempty"`
+	TimeField  string     `json:"time_field,omitempty"`
+}
+
+type AckLogsResponse struct {
+	Acknowledged int64 `json:"acknowledged"`
+}
+
+// AckLogs handles POST /api/admin/logs/ack. It marks every matching log in
+// the projects the user can access as acknowledged by them; logs already
+// acknowledged keep who acknowledged them first.
+func (h *LogHandler) AckLogs(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req AckLogsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if !models.IsValidLogTimeField(req.TimeField) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("time_field must be %q or %q", models.LogTimeFieldTimestamp, models.LogTimeFieldCreatedAt),
+		})
+	}
+	// An empty filter would acknowledge every log there is
+	if len(req.ProjectIDs) == 0 && len(req.Levels) == 0 && req.Source == "" && req.Search == "" &&
+		req.StartTime == nil && req.EndTime == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "At least one filter is required",
+		})
+	}
+
+	projectIDs := req.ProjectIDs
+	if !user.IsAdmin() {
+		accessible, err := h.userProjectRepo.GetUserProjectIDs(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get projects",
+			})
+		}
+		allowed := make(map[string]bool, len(accessible))
+		for _, id := range accessible {
+			allowed[id] = true
+		}
+		for _, id := range req.ProjectIDs {
+			if !allowed[id] {
+				return middleware.DenyAccess(c, "Access denied to this project", "Project not found")
+			}
+		}
+		if len(projectIDs) == 0 {
+			projectIDs = accessible
+		}
+		// An empty ProjectIDs would match every project
+		if len(projectIDs) == 0 {
+			return c.JSON(AckLogsResponse{})
+		}
+	}
+
+	filter := &models.LogFilter{
+		ProjectIDs: projectIDs,
+		Source:     req.Source,
+		Search:     req.Search,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		TimeField:  req.TimeField,
+	}
+	// Unknown levels parse as INFO, which here would acknowledge the wrong logs
+	for _, raw := range req.Levels {
+		upper := strings.ToUpper(raw)
+		level := models.ParseLogLevel(upper)
+		if string(level) != upper && upper != "WARNING" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Unknown level %q", raw),
+			})
+		}
+		filter.Levels = append(filter.Levels, level)
+	}
+
+	acknowledged, err := h.logRepo.UpdateStatusByFilter(filter, models.LogStatusAcknowledged, user.ID, ackBatchSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to acknowledge logs",
+		})
+	}
+
+	return c.JSON(AckLogsResponse{Acknowledged: acknowledged})
+}
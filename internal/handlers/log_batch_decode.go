@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxBatchLogs caps the entries accepted in one batch request
+const maxBatchLogs = 100
+
+// errTooManyLogs is returned by decodeBatch once a batch goes over maxBatchLogs
+var errTooManyLogs = fmt.Errorf("maximum %d logs per batch", maxBatchLogs)
+
+// decodeBatch reads a BatchLogRequest body one entry at a time, passing each
+// element of "logs" to fn as soon as it is decoded so the array is never
+// held in memory as a whole. It returns how many entries it read. Decoding
+// stops with errTooManyLogs at the first entry past maxBatchLogs, without
+// reading the rest of the body.
+func decodeBatch(r io.Reader, fn func(index int, entry CreateLogRequest)) (int, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return count, err
+		}
+		// Match field names the way json.Unmarshal does
+		if key, _ := token.(string); !strings.EqualFold(key, "logs") {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return count, err
+			}
+			continue
+		}
+
+		token, err = dec.Token()
+		if err != nil {
+			return count, err
+		}
+		if token == nil {
+			continue // "logs": null is an empty batch
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return count, errors.New(`"logs" must be an array`)
+		}
+
+		for dec.More() {
+			if count == maxBatchLogs {
+				return count, errTooManyLogs
+			}
+			var entry CreateLogRequest
+			if err := dec.Decode(&entry); err != nil {
+				return count, err
+			}
+			fn(count, entry)
+			count++
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return count, err
+		}
+	}
+
+	return count, expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token and checks it is the delimiter want
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %q in batch body", want)
+	}
+	return nil
+}
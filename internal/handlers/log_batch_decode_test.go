@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBatch(t *testing.T) {
+	body := `{"source":"ignored","Logs":[{"level":"INFO","message":"one"},{"message":"two","metadata":{"k":[1,2]}}],"extra":{"a":1}}`
+
+	var messages []string
+	count, err := decodeBatch(strings.NewReader(body), func(i int, entry CreateLogRequest) {
+		messages = append(messages, fmt.Sprintf("%d:%s", i, entry.Message))
+	})
+	if err != nil {
+		t.Fatalf("decodeBatch failed: %v", err)
+	}
+	if count != 2 || strings.Join(messages, ",") != "0:one,1:two" {
+		t.Errorf("Expected two entries in order, got %d %v", count, messages)
+	}
+
+	for _, body := range []string{`{}`, `{"logs":null}`, `{"logs":[]}`} {
+		if count, err := decodeBatch(strings.NewReader(body), func(int, CreateLogRequest) {}); err != nil || count != 0 {
+			t.Errorf("decodeBatch(%s) = %d, %v; want an empty batch", body, count, err)
+		}
+	}
+
+	for _, body := range []string{``, `[]`, `{"logs":{}}`, `{"logs":[{"message":1}]}`, `{"logs":[{}`} {
+		if _, err := decodeBatch(strings.NewReader(body), func(int, CreateLogRequest) {}); err == nil {
+			t.Errorf("decodeBatch(%q) should fail", body)
+		}
+	}
+}
+
+func TestDecodeBatch_StopsPastLimit(t *testing.T) {
+	// The body is cut off after the entry past the limit; decoding must stop
+	// before it would notice
+	body := `{"logs":[` + strings.Repeat(`{"message":"m"},`, maxBatchLogs+1) + `{"mess`
+
+	calls := 0
+	_, err := decodeBatch(strings.NewReader(body), func(int, CreateLogRequest) { calls++ })
+	if !errors.Is(err, errTooManyLogs) {
+		t.Fatalf("Expected errTooManyLogs, got %v", err)
+	}
+	if calls != maxBatchLogs {
+		t.Errorf("Expected %d entries before stopping, got %d", maxBatchLogs, calls)
+	}
+}
+
+// benchmarkBatchBody is a batch of n entries with moderately large metadata
+func benchmarkBatchBody(b *testing.B, n int) []byte {
+	entries := make([]map[string]interface{}, n)
+	for i := range entries {
+		entries[i] = map[string]interface{}{
+			"level":   "INFO",
+			"message": strings.Repeat("request handled ", 16),
+			"metadata": map[string]interface{}{
+				"headers": map[string]interface{}{"user-agent": strings.Repeat("x", 256), "accept": "*/*"},
+				"tags":    []interface{}{"a", "b", "c", "d"},
+				"status":  200,
+			},
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"logs": entries})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return body
+}
+
+var benchmarkBatchSizes = []int{maxBatchLogs, 10000}
+
+// BenchmarkBatchDecode_Unmarshal is how CreateBatchLogs parsed bodies before
+// decodeBatch: the whole array is decoded before its length is checked
+func BenchmarkBatchDecode_Unmarshal(b *testing.B) {
+	for _, n := range benchmarkBatchSizes {
+		body := benchmarkBatchBody(b, n)
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var req BatchLogRequest
+				if err := json.Unmarshal(body, &req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBatchDecode_Streaming(b *testing.B) {
+	for _, n := range benchmarkBatchSizes {
+		body := benchmarkBatchBody(b, n)
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var kept []CreateLogRequest
+				_, err := decodeBatch(bytes.NewReader(body), func(_ int, entry CreateLogRequest) {
+					kept = append(kept, entry)
+				})
+				if err != nil && !errors.Is(err, errTooManyLogs) {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"log"
+	"net"
+	"strings"
+
+	"central-logs/internal/services/geoip"
+)
+
+// geoMetadataKey is where GeoIP results are stored in log metadata
+const geoMetadataKey = "geo"
+
+// GeoIPEnricher adds a "geo" object to log metadata for the client IP found
+// in a configured metadata field
+type GeoIPEnricher struct {
+	resolver geoip.Resolver
+	ipField  string // Metadata key holding the IP; dots reach into nested objects
+}
+
+// NewGeoIPEnricher creates an enricher reading the IP from ipField
+func NewGeoIPEnricher(resolver geoip.Resolver, ipField string) *GeoIPEnricher {
+	return &GeoIPEnricher{
+		resolver: resolver,
+		ipField:  ipField,
+	}
+}
+
+// enrich adds geo fields to metadata. Logs without a usable IP, and lookups
+// that fail or find nothing, are left as they are.
+func (e *GeoIPEnricher) enrich(metadata map[string]interface{}) {
+	if e == nil || metadata == nil {
+		return
+	}
+	if _, exists := metadata[geoMetadataKey]; exists {
+		return
+	}
+
+	ip := parseClientIP(lookupMetadataField(metadata, e.ipField))
+	if ip == nil {
+		return
+	}
+
+	loc, err := e.resolver.Lookup(ip)
+	if err != nil {
+		log.Printf("[GeoIP] Lookup failed for %s: %v", ip, err)
+		return
+	}
+	if loc.Empty() {
+		return
+	}
+
+	geo := make(map[string]interface{})
+	if loc.Country != "" {
+		geo["country"] = loc.Country
+	}
+	if loc.CountryName != "" {
+		geo["country_name"] = loc.CountryName
+	}
+	if loc.City != "" {
+		geo["city"] = loc.City
+	}
+	if loc.ASN != 0 {
+		geo["asn"] = loc.ASN
+	}
+	if loc.ASOrg != "" {
+		geo["as_org"] = loc.ASOrg
+	}
+	metadata[geoMetadataKey] = geo
+}
+
+// lookupMetadataField follows a dotted path such as "request.client_ip"
+func lookupMetadataField(metadata map[string]interface{}, path string) string {
+	var current interface{} = metadata
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = m[key]
+	}
+	s, _ := current.(string)
+	return s
+}
+
+// parseClientIP accepts a bare address, host:port, or an X-Forwarded-For
+// style list, in which case the first (client) address is used
+func parseClientIP(value string) net.IP {
+	value, _, _ = strings.Cut(value, ",")
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if ip := net.ParseIP(strings.Trim(value, "[]")); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		return net.ParseIP(host)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"central-logs/internal/models"
+)
+
+// logFields are the fields ?fields= can pick from a listed log, by their JSON
+// names in models.Log
+var logFields = map[string]func(*models.Log) interface{}{
+	"id":              func(l *models.Log) interface{} { return l.ID },
+	"project_id":      func(l *models.Log) interface{} { return l.ProjectID },
+	"project_name":    func(l *models.Log) interface{} { return l.ProjectName },
+	"level":           func(l *models.Log) interface{} { return l.Level },
+	"message":         func(l *models.Log) interface{} { return l.Message },
+	"metadata":        func(l *models.Log) interface{} { return l.Metadata },
+	"source":          func(l *models.Log) interface{} { return l.Source },
+	"timestamp":       func(l *models.Log) interface{} { return l.Timestamp },
+	"created_at":      func(l *models.Log) interface{} { return l.CreatedAt },
+	"status":          func(l *models.Log) interface{} { return l.Status },
+	"acknowledged_by": func(l *models.Log) interface{} { return l.AcknowledgedBy },
+	"acknowledged_at": func(l *models.Log) interface{} { return l.AcknowledgedAt },
+}
+
+// parseLogFields reads a comma-separated ?fields= list. An empty list means
+// the full log.
+func parseLogFields(raw string) ([]string, error) {
+	fields := splitAndTrim(raw, ",")
+	seen := make(map[string]bool, len(fields))
+	unique := fields[:0]
+	for _, field := range fields {
+		if _, ok := logFields[field]; !ok {
+			known := make([]string, 0, len(logFields))
+			for name := range logFields {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown field %q; fields can be %s", field, strings.Join(known, ", "))
+		}
+		if !seen[field] {
+			seen[field] = true
+			unique = append(unique, field)
+		}
+	}
+	return unique, nil
+}
+
+// hasField reports whether fields, as returned by parseLogFields, include
+// name; an empty list includes everything
+func hasField(fields []string, name string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectLogFields returns each log reduced to fields. Requested fields are
+// always present, even when empty.
+func selectLogFields(logs []*models.Log, fields []string) []map[string]interface{} {
+	selected := make([]map[string]interface{}, len(logs))
+	for i, log := range logs {
+		entry := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			entry[field] = logFields[field](log)
+		}
+		selected[i] = entry
+	}
+	return selected
+}
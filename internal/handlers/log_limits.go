@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// IngestionLimits bounds the size of incoming logs. Zero fields are unlimited.
+type IngestionLimits struct {
+	MaxMessageBytes  int
+	MaxMetadataBytes int
+	MaxMetadataDepth int
+	MaxMetadataKeys  int // Top-level keys
+	// Longest string anywhere in metadata, such as an SDK's embedded
+	// base64 blob, and what to do with longer ones: one of the
+	// MetadataValue policies, or empty to follow Truncate
+	MaxMetadataValueBytes int
+	MetadataValuePolicy   string
+	// Truncate keeps oversized logs, cutting the message and pruning or
+	// dropping metadata, instead of rejecting them
+	Truncate bool
+	// MessageTruncateLength cuts longer messages to this many characters,
+	// whatever Truncate says, and marks the log as cut in its metadata
+	MessageTruncateLength int
+}
+
+// Policies for metadata values over MaxMetadataValueBytes
+const (
+	MetadataValueReject   = "reject"   // Reject the log
+	MetadataValueTruncate = "truncate" // Cut the value to the limit
+	MetadataValueDrop     = "drop"     // Remove the key; array elements become null
+)
+
+// limitViolation names the limit a log broke, for the 400 response
+type limitViolation struct {
+	Limit   string `json:"limit"`
+	Message string `json:"error"`
+}
+
+// Metadata markers of a message cut to MessageTruncateLength
+const (
+	truncatedField      = "_truncated"
+	originalLengthField = "_original_length" // In characters
+)
+
+// apply checks req against the limits. With Truncate set, req is trimmed to
+// fit and nil is returned; otherwise the first violated limit is reported.
+func (l IngestionLimits) apply(req *CreateLogRequest) *limitViolation {
+	// len is an upper bound of the character count, so most messages skip
+	// counting
+	originalLength := 0
+	if l.MessageTruncateLength > 0 && len(req.Message) > l.MessageTruncateLength {
+		if n := utf8.RuneCountInString(req.Message); n > l.MessageTruncateLength {
+			originalLength = n
+			req.Message = truncateRunes(req.Message, l.MessageTruncateLength)
+		}
+	}
+
+	if violation := l.check(req); violation != nil {
+		return violation
+	}
+
+	// Marked last so the markers don't count against the metadata limits
+	if originalLength > 0 {
+		if req.Metadata == nil {
+			req.Metadata = make(map[string]interface{})
+		}
+		req.Metadata[truncatedField] = true
+		req.Metadata[originalLengthField] = originalLength
+	}
+	return nil
+}
+
+func (l IngestionLimits) check(req *CreateLogRequest) *limitViolation {
+	if l.MaxMessageBytes > 0 && len(req.Message) > l.MaxMessageBytes {
+		if !l.Truncate {
+			return &limitViolation{
+				Limit:   "max_message_bytes",
+				Message: fmt.Sprintf("Message exceeds %d bytes", l.MaxMessageBytes),
+			}
+		}
+		req.Message = truncateUTF8(req.Message, l.MaxMessageBytes)
+	}
+
+	if req.Metadata == nil {
+		return nil
+	}
+
+	if l.MaxMetadataDepth > 0 && metadataDepth(req.Metadata) > l.MaxMetadataDepth {
+		if !l.Truncate {
+			return &limitViolation{
+				Limit:   "max_metadata_depth",
+				Message: fmt.Sprintf("Metadata is nested deeper than %d levels", l.MaxMetadataDepth),
+			}
+		}
+		req.Metadata = pruneMetadata(req.Metadata, l.MaxMetadataDepth).(map[string]interface{})
+	}
+
+	if l.MaxMetadataKeys > 0 && len(req.Metadata) > l.MaxMetadataKeys {
+		if !l.Truncate {
+			return &limitViolation{
+				Limit:   "max_metadata_keys",
+				Message: fmt.Sprintf("Metadata has %d keys, more than %d", len(req.Metadata), l.MaxMetadataKeys),
+			}
+		}
+		req.Metadata = dropMetadataKeys(req.Metadata, l.MaxMetadataKeys)
+	}
+
+	if l.MaxMetadataValueBytes > 0 {
+		if violation := l.applyValueLimit(req); violation != nil {
+			return violation
+		}
+	}
+
+	if l.MaxMetadataBytes > 0 {
+		encoded, err := json.Marshal(req.Metadata)
+		if err == nil && len(encoded) > l.MaxMetadataBytes {
+			if !l.Truncate {
+				return &limitViolation{
+					Limit:   "max_metadata_bytes",
+					Message: fmt.Sprintf("Metadata exceeds %d bytes", l.MaxMetadataBytes),
+				}
+			}
+			// There is no meaningful way to cut JSON to a byte size, so drop it
+			req.Metadata = map[string]interface{}{truncatedField: true}
+		}
+	}
+
+	return nil
+}
+
+// droppedKeysField lists, in a log's metadata, the keys truncation removed
+const droppedKeysField = "_dropped_keys"
+
+// oversizedValuesField lists, in a log's metadata, the values cut or removed
+// for exceeding MaxMetadataValueBytes
+const oversizedValuesField = "_oversized_values"
+
+// oversizedValue is a metadata string over MaxMetadataValueBytes and what was
+// done with it
+type oversizedValue struct {
+	Key    string `json:"key"` // Path such as "request.body" or "files[2]"
+	Bytes  int    `json:"bytes"`
+	Base64 bool   `json:"base64,omitempty"`
+	Action string `json:"action"` // truncated or dropped
+}
+
+func (l IngestionLimits) valuePolicy() string {
+	switch {
+	case l.MetadataValuePolicy != "":
+		return l.MetadataValuePolicy
+	case l.Truncate:
+		return MetadataValueTruncate
+	default:
+		return MetadataValueReject
+	}
+}
+
+// applyValueLimit applies the value policy to every oversized string in
+// req's metadata, recording what it did under oversizedValuesField
+func (l IngestionLimits) applyValueLimit(req *CreateLogRequest) *limitViolation {
+	policy := l.valuePolicy()
+	var found []oversizedValue
+	scanMetadataValues(req.Metadata, "", l.MaxMetadataValueBytes, policy, &found)
+	if len(found) == 0 {
+		return nil
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Key < found[j].Key })
+
+	if policy == MetadataValueReject {
+		first := found[0]
+		message := fmt.Sprintf("Metadata value %q is %d bytes, more than %d", first.Key, first.Bytes, l.MaxMetadataValueBytes)
+		if first.Base64 {
+			message += " (looks like base64 data)"
+		}
+		return &limitViolation{Limit: "max_metadata_value_bytes", Message: message}
+	}
+	req.Metadata[oversizedValuesField] = found
+	return nil
+}
+
+// scanMetadataValues finds strings in v longer than max bytes, appending them
+// to found. Unless policy is reject they are cut or removed in place; keep is
+// false when v itself is to be removed from its parent.
+func scanMetadataValues(v interface{}, path string, max int, policy string, found *[]oversizedValue) (value interface{}, keep bool) {
+	switch val := v.(type) {
+	case string:
+		if len(val) <= max {
+			return val, true
+		}
+		report := oversizedValue{Key: path, Bytes: len(val), Base64: looksBase64(val)}
+		switch policy {
+		case MetadataValueDrop:
+			report.Action = "dropped"
+			*found = append(*found, report)
+			return nil, false
+		case MetadataValueTruncate:
+			report.Action = "truncated"
+			*found = append(*found, report)
+			return truncateUTF8(val, max), true
+		default:
+			*found = append(*found, report)
+			return val, true
+		}
+	case map[string]interface{}:
+		for k, child := range val {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			if child, keep := scanMetadataValues(child, childPath, max, policy, found); keep {
+				val[k] = child
+			} else {
+				delete(val, k)
+			}
+		}
+		return val, true
+	case []interface{}:
+		for i, child := range val {
+			// Removing an element would shift the rest, so it becomes null
+			val[i], _ = scanMetadataValues(child, fmt.Sprintf("%s[%d]", path, i), max, policy, found)
+		}
+		return val, true
+	default:
+		return v, true
+	}
+}
+
+// looksBase64 reports whether s is made only of base64 characters, in the
+// standard or URL-safe alphabet with optional padding, or is a base64 data URL
+func looksBase64(s string) bool {
+	if strings.HasPrefix(s, "data:") {
+		if _, data, ok := strings.Cut(s, ";base64,"); ok {
+			s = data
+		}
+	}
+	trimmed := strings.TrimRight(s, "=")
+	if trimmed == "" || len(s)-len(trimmed) > 2 {
+		return false
+	}
+	for i := 0; i < len(trimmed); i++ {
+		c := trimmed[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '+', c == '/', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// dropMetadataKeys keeps the first n keys of metadata in sorted order and
+// records the others under droppedKeysField. JSON objects arrive unordered,
+// so sorting is what makes the same payload always keep the same keys.
+func dropMetadataKeys(metadata map[string]interface{}, n int) map[string]interface{} {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kept := make(map[string]interface{}, n+1)
+	for _, k := range keys[:n] {
+		kept[k] = metadata[k]
+	}
+	kept[droppedKeysField] = keys[n:]
+	return kept
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// truncateRunes cuts s to its first n characters
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// metadataDepth returns how many levels of objects and arrays v nests;
+// a flat object is depth 1
+func metadataDepth(v interface{}) int {
+	deepest := 0
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, child := range val {
+			deepest = max(deepest, metadataDepth(child))
+		}
+	case []interface{}:
+		for _, child := range val {
+			deepest = max(deepest, metadataDepth(child))
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
+
+// pruneMetadata replaces objects and arrays nested below depth levels with a
+// placeholder string
+func pruneMetadata(v interface{}, depth int) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if depth <= 0 {
+			return "[truncated]"
+		}
+		pruned := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			pruned[k] = pruneMetadata(child, depth-1)
+		}
+		return pruned
+	case []interface{}:
+		if depth <= 0 {
+			return "[truncated]"
+		}
+		pruned := make([]interface{}, len(val))
+		for i, child := range val {
+			pruned[i] = pruneMetadata(child, depth-1)
+		}
+		return pruned
+	default:
+		return v
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ResendNotificationsRequest optionally limits a re-send to one channel
+type ResendNotificationsRequest struct {
+	ChannelID string `json:"channel_id,omitempty"`
+}
+
+// ResendNotificationsResponse lists the channels a log was queued for again
+// and why the others were left out
+type ResendNotificationsResponse struct {
+	Queued  []QueuedNotification  `json:"queued"`
+	Skipped []SkippedNotification `json:"skipped"`
+}
+
+type QueuedNotification struct {
+	ChannelID   string `json:"channel_id"`
+	ChannelName string `json:"channel_name"`
+	JobID       string `json:"job_id"`
+}
+
+type SkippedNotification struct {
+	ChannelID   string `json:"channel_id"`
+	ChannelName string `json:"channel_name"`
+	Reason      string `json:"reason"`
+}
+
+// newNotificationJob builds the queued notification of log for a channel
+func newNotificationJob(log *models.Log, channelID string) *queue.NotificationJob {
+	return &queue.NotificationJob{
+		LogID:     log.ID,
+		ChannelID: channelID,
+		ProjectID: log.ProjectID,
+		Level:     string(log.Level),
+		Message:   log.Message,
+		Source:    log.Source,
+		Timestamp: log.Timestamp.Format(time.RFC3339),
+	}
+}
+
+// notifyChannels picks the channels a log is queued for, and says why each of
+// the others is left out. Ingestion and re-sends both go through it so they
+// always agree. Push notifications go to devices directly at ingestion rather
+// than through the queue, so they are never picked.
+func notifyChannels(log *models.Log, channels []*models.Channel) ([]*models.Channel, []SkippedNotification) {
+	var notify []*models.Channel
+	var skipped []SkippedNotification
+	skip := func(channel *models.Channel, reason string) {
+		skipped = append(skipped, SkippedNotification{ChannelID: channel.ID, ChannelName: channel.Name, Reason: reason})
+	}
+
+	for _, channel := range channels {
+		switch {
+		case !channel.IsActive:
+			skip(channel, "channel is inactive")
+		case channel.Type == models.ChannelTypePush:
+			skip(channel, "push notifications are not re-sent")
+		case channel.Type == models.ChannelTypeEmail:
+			skip(channel, "email channels send scheduled digests")
+		case !channel.ShouldNotify(log.Level, log.Source):
+			skip(channel, "log is below the channel's minimum level")
+		default:
+			notify = append(notify, channel)
+		}
+	}
+	return notify, skipped
+}
+
+// ResendNotifications handles POST /api/admin/logs/:id/notify. It queues the
+// log again for the project's active channels that would notify of it, or for
+// just channel_id, e.g. after fixing a channel's config. Project owners and
+// members may re-send.
+func (h *LogHandler) ResendNotifications(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req ResendNotificationsRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	logEntry, err := h.logRepo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get log",
+		})
+	}
+	if logEntry == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Log not found",
+		})
+	}
+
+	if !user.IsAdmin() {
+		hasAccess, _ := h.userProjectRepo.HasAccess(user.ID, logEntry.ProjectID)
+		if !hasAccess {
+			return middleware.DenyAccess(c, "Access denied", "Log not found")
+		}
+		canSend, _ := h.userProjectRepo.HasRole(user.ID, logEntry.ProjectID, models.ProjectRoleOwner, models.ProjectRoleMember)
+		if !canSend {
+			return middleware.DenyAccess(c, "Insufficient permissions", "Log not found")
+		}
+	}
+
+	var channels []*models.Channel
+	if req.ChannelID != "" {
+		channel, err := h.channelRepo.GetByID(req.ChannelID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get channel",
+			})
+		}
+		if channel == nil || channel.ProjectID != logEntry.ProjectID {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Channel not found",
+			})
+		}
+		channels = []*models.Channel{channel}
+	} else {
+		channels, err = h.channelRepo.GetActiveByProjectID(logEntry.ProjectID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get channels",
+			})
+		}
+	}
+
+	if h.redisClient == nil || !h.redisClient.Available() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Notification queue is not available",
+		})
+	}
+
+	notify, skipped := notifyChannels(logEntry, channels)
+	resp := ResendNotificationsResponse{
+		Queued:  make([]QueuedNotification, 0, len(notify)),
+		Skipped: skipped,
+	}
+	if resp.Skipped == nil {
+		resp.Skipped = []SkippedNotification{}
+	}
+
+	for _, channel := range notify {
+		job := newNotificationJob(logEntry, channel.ID)
+		if err := h.redisClient.EnqueueNotification(context.Background(), job); err != nil {
+			log.Printf("Failed to re-queue log %s for channel %s: %v", logEntry.ID, channel.ID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":  "Failed to queue notification",
+				"queued": resp.Queued,
+			})
+		}
+		resp.Queued = append(resp.Queued, QueuedNotification{ChannelID: channel.ID, ChannelName: channel.Name, JobID: job.JobID})
+	}
+
+	return c.JSON(resp)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+)
+
+func TestNotifyChannels(t *testing.T) {
+	logEntry := &models.Log{
+		ID:        "log-1",
+		ProjectID: "proj-1",
+		Level:     models.LogLevelError,
+		Message:   "Payment failed",
+		Source:    "billing",
+		Timestamp: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	channel := func(id string, typ models.ChannelType, minLevel models.LogLevel, active bool) *models.Channel {
+		return &models.Channel{ID: id, Name: id, ProjectID: "proj-1", Type: typ, MinLevel: minLevel, IsActive: active, Config: map[string]interface{}{}}
+	}
+	quietBilling := channel("teams-billing", models.ChannelTypeTeams, models.LogLevelWarn, true)
+	quietBilling.Config["source_levels"] = map[string]interface{}{"billing": "CRITICAL"}
+
+	notify, skipped := notifyChannels(logEntry, []*models.Channel{
+		channel("telegram", models.ChannelTypeTelegram, models.LogLevelWarn, true),
+		channel("discord-critical", models.ChannelTypeDiscord, models.LogLevelCritical, true),
+		quietBilling,
+		channel("pagerduty", models.ChannelTypePagerDuty, models.LogLevelError, true),
+		channel("telegram-off", models.ChannelTypeTelegram, models.LogLevelDebug, false),
+		channel("push", models.ChannelTypePush, models.LogLevelDebug, true),
+		channel("email", models.ChannelTypeEmail, models.LogLevelDebug, true),
+	})
+
+	if len(notify) != 2 || notify[0].ID != "telegram" || notify[1].ID != "pagerduty" {
+		t.Fatalf("Expected telegram and pagerduty, got %+v", notify)
+	}
+	job := newNotificationJob(logEntry, notify[0].ID)
+	if job.LogID != "log-1" || job.ProjectID != "proj-1" || job.Level != "ERROR" || job.Message != "Payment failed" ||
+		job.Source != "billing" || job.Timestamp != "2026-03-02T10:00:00Z" {
+		t.Errorf("Expected the job to carry the log, got %+v", job)
+	}
+
+	want := map[string]string{
+		"discord-critical": "log is below the channel's minimum level",
+		"teams-billing":    "log is below the channel's minimum level",
+		"telegram-off":     "channel is inactive",
+		"push":             "push notifications are not re-sent",
+		"email":            "email channels send scheduled digests",
+	}
+	if len(skipped) != len(want) {
+		t.Fatalf("Expected %d skipped channels, got %+v", len(want), skipped)
+	}
+	for _, s := range skipped {
+		if want[s.ChannelID] != s.Reason {
+			t.Errorf("Channel %s: expected reason %q, got %q", s.ChannelID, want[s.ChannelID], s.Reason)
+		}
+	}
+}
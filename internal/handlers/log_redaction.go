@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"central-logs/internal/models"
+	"central-logs/internal/services/redaction"
+)
+
+// Redactor scrubs sensitive values from logs before they are stored. Projects
+// with their own redaction config use it in place of the server-wide rules.
+type Redactor struct {
+	global *redaction.Rules
+
+	mu       sync.Mutex
+	projects map[string]projectRedaction
+}
+
+// projectRedaction caches a project's compiled rules with the config they
+// were built from, so edits to the project are picked up
+type projectRedaction struct {
+	key   string
+	rules *redaction.Rules
+}
+
+// NewRedactor creates a redactor applying global to projects without an override
+func NewRedactor(global *redaction.Rules) *Redactor {
+	return &Redactor{
+		global:   global,
+		projects: make(map[string]projectRedaction),
+	}
+}
+
+// rulesFor returns the rules that apply to project
+func (r *Redactor) rulesFor(project *models.Project) *redaction.Rules {
+	cfg := project.RedactionConfig
+	if cfg.IsEmpty() {
+		return r.global
+	}
+
+	key := strings.Join(cfg.Detectors, "\x00") + "\x01" + strings.Join(cfg.Patterns, "\x00")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.projects[project.ID]; ok && cached.key == key {
+		return cached.rules
+	}
+
+	rules, err := redaction.Compile(cfg.Detectors, cfg.Patterns)
+	if err != nil {
+		// Configs are validated when saved, so this only happens if the rules
+		// changed under us; the server-wide rules are the safest fallback
+		log.Printf("[Redaction] Invalid rules for project %s, using server defaults: %v", project.ID, err)
+		rules = r.global
+	}
+	r.projects[project.ID] = projectRedaction{key: key, rules: rules}
+	return rules
+}
+
+// apply redacts the message and string metadata values of req
+func (r *Redactor) apply(req *CreateLogRequest, project *models.Project) {
+	if r == nil {
+		return
+	}
+	rules := r.rulesFor(project)
+	req.Message = rules.String(req.Message)
+	rules.Metadata(req.Metadata)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"math/rand"
+	"sync"
+
+	"central-logs/internal/models"
+)
+
+// Sampler decides which logs a project's sampling config drops
+type Sampler struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewSampler creates a sampler drawing from src; tests pass a fixed seed
+func NewSampler(src rand.Source) *Sampler {
+	return &Sampler{rng: rand.New(src)}
+}
+
+// keep reports whether a log at level should be stored
+func (s *Sampler) keep(project *models.Project, level models.LogLevel) bool {
+	rate := project.SamplingConfig.Rate(level)
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64() < rate
+}
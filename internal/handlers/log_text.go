@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxTextLogLines caps the non-empty lines accepted in one plain-text request
+const maxTextLogLines = 1000
+
+// CreateTextLogs handles POST /api/v1/logs/text for scripts that just send a
+// file: each non-empty line of the body becomes one log at ?level= (default
+// the project's default level) with the optional ?source=. Lines are stored like a batch; a rejected
+// line's index is its line number in the body, counting from 0.
+func (h *LogHandler) CreateTextLogs(c *fiber.Ctx) error {
+	project := middleware.GetProject(c)
+	if project == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid API key",
+		})
+	}
+
+	level, ok := ingestLevel(project, "")
+	if raw := c.Query("level"); raw != "" {
+		upper := strings.ToUpper(raw)
+		level = models.ParseLogLevel(upper)
+		if string(level) != upper && upper != "WARNING" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Unknown level %q", raw),
+			})
+		}
+	} else if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errLevelRequired,
+		})
+	}
+	source := c.Query("source")
+
+	batch := &logBatch{h: h, project: project}
+	body := c.Body()
+	lines := 0
+	for i := 0; len(body) > 0; i++ {
+		var line []byte
+		line, body, _ = bytes.Cut(body, []byte("\n"))
+
+		// Keep leading indentation, which stack traces rely on
+		message := strings.TrimRight(string(line), " \t\r")
+		if strings.TrimSpace(message) == "" {
+			continue
+		}
+		lines++
+		if lines > maxTextLogLines {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Maximum %d lines per request", maxTextLogLines),
+			})
+		}
+
+		batch.add(i, CreateLogRequest{
+			Level:   string(level),
+			Message: message,
+			Source:  source,
+		})
+	}
+
+	if lines == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No log lines provided",
+		})
+	}
+
+	return h.storeBatch(c, batch)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampWindow bounds how far a log's client-sent timestamp may be from
+// the server's clock, so a client with a skewed clock can't file logs years
+// away from where time-range queries look. Zero bounds are open.
+type TimestampWindow struct {
+	MaxFuture time.Duration
+	MaxPast   time.Duration
+	// Clamp moves a timestamp outside the window to its nearest edge,
+	// keeping the original in metadata, instead of rejecting the log
+	Clamp bool
+}
+
+// originalTimestampField is the metadata key holding a timestamp that was
+// clamped into the window
+const originalTimestampField = "_original_timestamp"
+
+// apply checks timestamp against the window around now. A clamped timestamp
+// is returned with its original recorded in req's metadata.
+func (w TimestampWindow) apply(req *CreateLogRequest, timestamp, now time.Time) (time.Time, *limitViolation) {
+	var edge time.Time
+	var limit, direction string
+	var bound time.Duration
+	switch {
+	case w.MaxFuture > 0 && timestamp.After(now.Add(w.MaxFuture)):
+		edge, limit, direction, bound = now.Add(w.MaxFuture), "max_timestamp_future", "in the future", w.MaxFuture
+	case w.MaxPast > 0 && timestamp.Before(now.Add(-w.MaxPast)):
+		edge, limit, direction, bound = now.Add(-w.MaxPast), "max_timestamp_past", "in the past", w.MaxPast
+	default:
+		return timestamp, nil
+	}
+
+	if !w.Clamp {
+		return timestamp, &limitViolation{
+			Limit:   limit,
+			Message: fmt.Sprintf("Timestamp %s is more than %s %s", timestamp.Format(time.RFC3339), bound, direction),
+		}
+	}
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]interface{})
+	}
+	req.Metadata[originalTimestampField] = timestamp.Format(time.RFC3339Nano)
+	return edge, nil
+}
@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
 	"strconv"
+	"strings"
 	"time"
 
 	"central-logs/internal/middleware"
@@ -10,6 +16,7 @@ import (
 	"central-logs/internal/queue"
 	"central-logs/internal/services/notification"
 	"central-logs/internal/websocket"
+	"central-logs/internal/worker"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -21,6 +28,15 @@ type LogHandler struct {
 	redisClient     *queue.RedisClient
 	pushService     *notification.PushService
 	wsHub           *websocket.Hub
+	logBuffer       *worker.LogBuffer
+	limits          IngestionLimits
+	timestamps      TimestampWindow
+	geoIP           *GeoIPEnricher
+	redactor        *Redactor
+	sampler         *Sampler
+	queryBounds     models.LogQueryBounds
+	fanout          *worker.TaskPool // Runs broadcasts and notification sends after the response
+	webhooks        *worker.ProjectWebhook
 }
 
 func NewLogHandler(
@@ -38,9 +54,81 @@ func NewLogHandler(
 		redisClient:     redisClient,
 		pushService:     pushService,
 		wsHub:           wsHub,
+		sampler:         NewSampler(rand.NewSource(time.Now().UnixNano())),
+		fanout:          worker.NewTaskPool(16, 10000),
 	}
 }
 
+// SetLogBuffer switches ingestion to the async buffer. Logs are acknowledged
+// with 202 once queued, and broadcast/notified after the buffer writes them.
+func (h *LogHandler) SetLogBuffer(buffer *worker.LogBuffer) {
+	h.logBuffer = buffer
+	buffer.OnFlush(func(batch []worker.BufferedLog) {
+		h.dispatch(len(batch), func() {
+			ctx := context.Background()
+			for _, entry := range batch {
+				h.publishLog(ctx, entry.Log, entry.Project)
+			}
+		})
+	})
+}
+
+// SetFanoutPool replaces the pool that runs post-ingestion broadcasts and
+// notification sends
+func (h *LogHandler) SetFanoutPool(pool *worker.TaskPool) {
+	h.fanout = pool
+}
+
+// SetProjectWebhook enables projects' outbound webhooks, fired from the
+// fan-out after each log is stored
+func (h *LogHandler) SetProjectWebhook(webhooks *worker.ProjectWebhook) {
+	h.webhooks = webhooks
+}
+
+// FanoutStats reports the load on the post-ingestion pool
+func (h *LogHandler) FanoutStats() worker.TaskPoolStats {
+	return h.fanout.Stats()
+}
+
+// WebSocketStats reports live streaming's clients and undelivered messages,
+// reporting false when streaming is not wired up
+func (h *LogHandler) WebSocketStats() (websocket.HubStats, bool) {
+	if h.wsHub == nil {
+		return websocket.HubStats{}, false
+	}
+	return h.wsHub.Stats(), true
+}
+
+// SetIngestionLimits bounds message and metadata size on ingestion
+func (h *LogHandler) SetIngestionLimits(limits IngestionLimits) {
+	h.limits = limits
+}
+
+// SetTimestampWindow bounds how far from now ingested timestamps may be
+func (h *LogHandler) SetTimestampWindow(window TimestampWindow) {
+	h.timestamps = window
+}
+
+// SetGeoIPEnricher turns on GeoIP enrichment of incoming log metadata
+func (h *LogHandler) SetGeoIPEnricher(enricher *GeoIPEnricher) {
+	h.geoIP = enricher
+}
+
+// SetRedactor scrubs sensitive values from logs before they are stored
+func (h *LogHandler) SetRedactor(redactor *Redactor) {
+	h.redactor = redactor
+}
+
+// SetQueryBounds sets the default time range and page size cap for log listings
+func (h *LogHandler) SetQueryBounds(bounds models.LogQueryBounds) {
+	h.queryBounds = bounds
+}
+
+// SetSampler replaces the random source used for per-project sampling
+func (h *LogHandler) SetSampler(sampler *Sampler) {
+	h.sampler = sampler
+}
+
 type CreateLogRequest struct {
 	Level     string                 `json:"level"`
 	Message   string                 `json:"message"`
@@ -65,6 +153,7 @@ func (h *LogHandler) CreateLog(c *fiber.Ctx) error {
 
 	var req CreateLogRequest
 	if err := c.BodyParser(&req); err != nil {
+		middleware.SetRequestError(c, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
@@ -76,64 +165,211 @@ func (h *LogHandler) CreateLog(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse timestamp
-	var timestamp time.Time
-	if req.Timestamp != "" {
-		var err error
-		timestamp, err = time.Parse(time.RFC3339, req.Timestamp)
-		if err != nil {
-			timestamp = time.Now()
-		}
-	} else {
-		timestamp = time.Now()
+	level, ok := ingestLevel(project, req.Level)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errLevelRequired,
+		})
+	}
+	if !h.sampler.keep(project, level) {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"status": "sampled",
+		})
+	}
+
+	h.redactor.apply(&req, project)
+	if violation := h.limits.apply(&req); violation != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(violation)
+	}
+	h.geoIP.enrich(req.Metadata)
+
+	timestamp, _ := parseTimestamp(req.Timestamp)
+	timestamp, violation := h.timestamps.apply(&req, timestamp, time.Now().UTC())
+	if violation != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(violation)
 	}
 
 	log := &models.Log{
 		ProjectID: project.ID,
-		Level:     models.ParseLogLevel(req.Level),
+		Level:     level,
 		Message:   req.Message,
 		Metadata:  req.Metadata,
 		Source:    req.Source,
 		Timestamp: timestamp,
 	}
 
+	if h.logBuffer != nil {
+		id, err := h.logBuffer.Add(log, project)
+		if err != nil {
+			return bufferFull(c, err)
+		}
+		return c.Status(fiber.StatusAccepted).JSON(CreateLogResponse{
+			ID:     id,
+			Status: "queued",
+		})
+	}
+
 	if err := h.logRepo.Create(log); err != nil {
+		middleware.SetRequestError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create log",
 		})
 	}
 
-	// Prepare log data for broadcasting
-	logData := map[string]interface{}{
-		"id":           log.ID,
-		"project_id":   log.ProjectID,
-		"project_name": project.Name,
-		"level":        log.Level,
-		"message":      log.Message,
-		"metadata":     log.Metadata,
-		"source":       log.Source,
-		"created_at":   log.CreatedAt.Format(time.RFC3339),
+	h.dispatch(1, func() { h.publishLog(context.Background(), log, project) })
+
+	return c.Status(fiber.StatusCreated).JSON(CreateLogResponse{
+		ID:     log.ID,
+		Status: "received",
+	})
+}
+
+// errLevelRequired is the error for a log sent without a level to a project
+// with RequireLevel set
+const errLevelRequired = "Level is required"
+
+// ingestLevel returns the level for a log sent with raw as its level. A
+// missing level gets the project's default, INFO unless it set one; ok is
+// false when the project requires a level and none was sent. Unknown levels
+// still parse as INFO.
+func ingestLevel(project *models.Project, raw string) (level models.LogLevel, ok bool) {
+	switch {
+	case strings.TrimSpace(raw) != "":
+		return models.ParseLogLevel(raw), true
+	case project.RequireLevel:
+		return "", false
+	case project.DefaultLevel != "":
+		return project.DefaultLevel, true
+	default:
+		return models.LogLevelInfo, true
 	}
+}
 
-	// Broadcast to WebSocket clients
-	if h.wsHub != nil {
-		go h.wsHub.BroadcastLog(logData, project.ID)
+// bufferFullRetryAfter is the Retry-After, in seconds, sent when the async
+// buffer is refusing logs
+const bufferFullRetryAfter = 5
+
+// bufferFull answers a request the async buffer had no room for; the client
+// should retry once the backlog has been written
+func bufferFull(c *fiber.Ctx, err error) error {
+	middleware.SetRequestError(c, err)
+	c.Set("Retry-After", strconv.Itoa(bufferFullRetryAfter))
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"error":       "Ingestion buffer is full",
+		"retry_after": bufferFullRetryAfter,
+	})
+}
+
+// parseTimestamp returns the event time a client sent, or the current time
+// when it sent none. ok is false when the value was present but not RFC 3339
+// and was replaced by the current time. The time is in UTC whatever offset the
+// client sent, so stored timestamps sort and compare in time order.
+func parseTimestamp(value string) (timestamp time.Time, ok bool) {
+	if value == "" {
+		return time.Now().UTC(), true
 	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Now().UTC(), false
+	}
+	return timestamp.UTC(), true
+}
 
-	// Publish to Redis for realtime streaming (if Redis is available)
-	if h.redisClient != nil {
-		go func() {
-			ctx := context.Background()
-			h.redisClient.PublishLog(ctx, project.ID, logData)
-		}()
+// ValidateLogResponse is the log CreateLog would have stored for a request,
+// with notes on anything the server changed or guessed
+type ValidateLogResponse struct {
+	Log      *models.Log `json:"log"`
+	Warnings []string    `json:"warnings"`
+}
+
+// ValidateLog handles POST /api/v1/logs/validate. It runs a request through
+// the same parsing, redaction, limits and enrichment as CreateLog and returns
+// the result without storing, broadcasting or notifying anything.
+func (h *LogHandler) ValidateLog(c *fiber.Ctx) error {
+	project := middleware.GetProject(c)
+	if project == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid API key",
+		})
 	}
 
-	// Queue notifications for channels
-	go h.queueNotifications(log, project)
+	var req CreateLogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
 
-	return c.Status(fiber.StatusCreated).JSON(CreateLogResponse{
-		ID:     log.ID,
-		Status: "received",
+	if req.Message == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Message is required",
+		})
+	}
+
+	warnings := []string{}
+
+	level, ok := ingestLevel(project, req.Level)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errLevelRequired,
+		})
+	}
+	switch {
+	case strings.TrimSpace(req.Level) == "":
+		warnings = append(warnings, fmt.Sprintf("level missing, defaulted to %s", level))
+	case string(level) != req.Level && !(req.Level == "WARNING" && level == models.LogLevelWarn):
+		warnings = append(warnings, fmt.Sprintf("unknown level %q coerced to INFO (levels are case-sensitive)", req.Level))
+	}
+	if rate := project.SamplingConfig.Rate(level); rate < 1 {
+		warnings = append(warnings, fmt.Sprintf("project samples %s logs, only %.3g%% are stored", level, rate*100))
+	}
+
+	h.redactor.apply(&req, project)
+	messageLength := len(req.Message)
+	if violation := h.limits.apply(&req); violation != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(violation)
+	}
+	// A client's own _original_length would decode as float64, never int
+	if originalLength, ok := req.Metadata[originalLengthField].(int); ok {
+		warnings = append(warnings, fmt.Sprintf("message truncated from %d to %d characters", originalLength, h.limits.MessageTruncateLength))
+	} else if len(req.Message) < messageLength {
+		warnings = append(warnings, fmt.Sprintf("message truncated to %d bytes", len(req.Message)))
+	}
+	// A client's own _dropped_keys would decode as []interface{}, never []string
+	if dropped, ok := req.Metadata[droppedKeysField].([]string); ok {
+		warnings = append(warnings, fmt.Sprintf("metadata cut to %d keys, dropped: %s", h.limits.MaxMetadataKeys, strings.Join(dropped, ", ")))
+	}
+	if oversized, ok := req.Metadata[oversizedValuesField].([]oversizedValue); ok {
+		for _, value := range oversized {
+			warnings = append(warnings, fmt.Sprintf("metadata value %q (%d bytes) %s, limit is %d bytes", value.Key, value.Bytes, value.Action, h.limits.MaxMetadataValueBytes))
+		}
+	}
+	h.geoIP.enrich(req.Metadata)
+
+	timestamp, ok := parseTimestamp(req.Timestamp)
+	if !ok {
+		warnings = append(warnings, fmt.Sprintf("timestamp %q unparseable (expected RFC 3339), used now", req.Timestamp))
+	}
+	sent := timestamp
+	timestamp, violation := h.timestamps.apply(&req, timestamp, time.Now().UTC())
+	if violation != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(violation)
+	}
+	if !timestamp.Equal(sent) {
+		warnings = append(warnings, fmt.Sprintf("timestamp %s outside the accepted window, clamped to %s", sent.Format(time.RFC3339), timestamp.Format(time.RFC3339)))
+	}
+
+	return c.JSON(ValidateLogResponse{
+		Log: &models.Log{
+			ProjectID:   project.ID,
+			Level:       level,
+			Message:     req.Message,
+			Metadata:    req.Metadata,
+			Source:      req.Source,
+			Timestamp:   timestamp,
+			ProjectName: project.Name,
+		},
+		Warnings: warnings,
 	})
 }
 
@@ -142,11 +378,31 @@ type BatchLogRequest struct {
 }
 
 type BatchLogResponse struct {
-	Received int      `json:"received"`
-	IDs      []string `json:"ids"`
+	Received int                `json:"received"`
+	IDs      []string           `json:"ids"`
+	Sampled  int                `json:"sampled,omitempty"` // Entries dropped by the project's sampling config
+	Rejected []BatchLogRejected `json:"rejected,omitempty"`
+	// With ?best_effort=true, entries that could not be stored; unlike
+	// rejected ones they may be sent again
+	Failed []BatchLogFailed `json:"failed,omitempty"`
+}
+
+// BatchLogRejected is a batch entry that broke an ingestion limit
+type BatchLogRejected struct {
+	Index int    `json:"index"`
+	Limit string `json:"limit"`
+	Error string `json:"error"`
+}
+
+// BatchLogFailed is a batch entry the database failed to store
+type BatchLogFailed struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
 }
 
-// CreateBatchLogs handles POST /api/v1/logs/batch
+// CreateBatchLogs handles POST /api/v1/logs/batch. By default the batch is
+// stored all or nothing; with ?best_effort=true each entry is stored on its
+// own and those that fail are listed under failed.
 func (h *LogHandler) CreateBatchLogs(c *fiber.Ctx) error {
 	project := middleware.GetProject(c)
 	if project == nil {
@@ -155,86 +411,177 @@ func (h *LogHandler) CreateBatchLogs(c *fiber.Ctx) error {
 		})
 	}
 
-	var req BatchLogRequest
-	if err := c.BodyParser(&req); err != nil {
+	// Entries are processed as they are decoded rather than parsed into a
+	// BatchLogRequest first, which keeps memory flat for large batches
+	batch := &logBatch{h: h, project: project}
+	count, err := decodeBatch(bytes.NewReader(c.Body()), batch.add)
+	if errors.Is(err, errTooManyLogs) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Maximum %d logs per batch", maxBatchLogs),
+		})
+	}
+	if err != nil {
+		middleware.SetRequestError(c, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	if len(req.Logs) == 0 {
+	if count == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "No logs provided",
 		})
 	}
 
-	if len(req.Logs) > 100 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Maximum 100 logs per batch",
+	return h.storeBatch(c, batch)
+}
+
+// logBatch runs batch entries through sampling, redaction, limits and
+// enrichment, collecting the logs to store
+type logBatch struct {
+	h        *LogHandler
+	project  *models.Project
+	logs     []*models.Log
+	indexes  []int // Entry index of each of logs
+	rejected []BatchLogRejected
+	sampled  int
+}
+
+// add takes entry i of the batch; entries without a message are skipped
+func (b *logBatch) add(i int, r CreateLogRequest) {
+	if r.Message == "" {
+		return
+	}
+
+	level, ok := ingestLevel(b.project, r.Level)
+	if !ok {
+		b.rejected = append(b.rejected, BatchLogRejected{
+			Index: i,
+			Limit: "level_required",
+			Error: errLevelRequired,
 		})
+		return
+	}
+	if !b.h.sampler.keep(b.project, level) {
+		b.sampled++
+		return
 	}
 
-	logs := make([]*models.Log, 0, len(req.Logs))
-	for _, r := range req.Logs {
-		if r.Message == "" {
-			continue
-		}
+	b.h.redactor.apply(&r, b.project)
+	if violation := b.h.limits.apply(&r); violation != nil {
+		b.rejected = append(b.rejected, BatchLogRejected{
+			Index: i,
+			Limit: violation.Limit,
+			Error: violation.Message,
+		})
+		return
+	}
+	b.h.geoIP.enrich(r.Metadata)
 
-		var timestamp time.Time
-		if r.Timestamp != "" {
-			var err error
-			timestamp, err = time.Parse(time.RFC3339, r.Timestamp)
-			if err != nil {
-				timestamp = time.Now()
-			}
-		} else {
-			timestamp = time.Now()
-		}
+	timestamp, _ := parseTimestamp(r.Timestamp)
+	timestamp, violation := b.h.timestamps.apply(&r, timestamp, time.Now().UTC())
+	if violation != nil {
+		b.rejected = append(b.rejected, BatchLogRejected{
+			Index: i,
+			Limit: violation.Limit,
+			Error: violation.Message,
+		})
+		return
+	}
 
-		logs = append(logs, &models.Log{
-			ProjectID: project.ID,
-			Level:     models.ParseLogLevel(r.Level),
-			Message:   r.Message,
-			Metadata:  r.Metadata,
-			Source:    r.Source,
-			Timestamp: timestamp,
+	b.logs = append(b.logs, &models.Log{
+		ProjectID: b.project.ID,
+		Level:     level,
+		Message:   r.Message,
+		Metadata:  r.Metadata,
+		Source:    r.Source,
+		Timestamp: timestamp,
+	})
+	b.indexes = append(b.indexes, i)
+}
+
+// storeBatch stores, or queues, a batch's logs and fans them out, responding
+// with what was received, sampled and rejected
+func (h *LogHandler) storeBatch(c *fiber.Ctx, batch *logBatch) error {
+	project, logs, rejected, sampled := batch.project, batch.logs, batch.rejected, batch.sampled
+
+	if len(logs) == 0 && len(rejected) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(BatchLogResponse{
+			IDs:      []string{},
+			Sampled:  sampled,
+			Rejected: rejected,
+		})
+	}
+
+	if len(logs) == 0 && sampled > 0 {
+		return c.Status(fiber.StatusAccepted).JSON(BatchLogResponse{
+			IDs:     []string{},
+			Sampled: sampled,
+		})
+	}
+
+	if h.logBuffer != nil {
+		ids, err := h.logBuffer.AddAll(logs, project)
+		if err != nil {
+			return bufferFull(c, err)
+		}
+		return c.Status(fiber.StatusAccepted).JSON(BatchLogResponse{
+			Received: len(logs),
+			IDs:      ids,
+			Sampled:  sampled,
+			Rejected: rejected,
 		})
 	}
 
-	if err := h.logRepo.CreateBatch(logs); err != nil {
+	var failed []BatchLogFailed
+	if c.QueryBool("best_effort") {
+		insertErrors, err := h.logRepo.CreateBatchBestEffort(logs)
+		if err != nil {
+			middleware.SetRequestError(c, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create logs",
+			})
+		}
+		if len(insertErrors) > 0 {
+			middleware.SetRequestError(c, insertErrors[0].Err)
+			skipped := make(map[int]bool, len(insertErrors))
+			for _, insertErr := range insertErrors {
+				skipped[insertErr.Index] = true
+				failed = append(failed, BatchLogFailed{
+					Index: batch.indexes[insertErr.Index],
+					Error: "Failed to store log",
+				})
+			}
+			stored := make([]*models.Log, 0, len(logs)-len(insertErrors))
+			for i, log := range logs {
+				if !skipped[i] {
+					stored = append(stored, log)
+				}
+			}
+			logs = stored
+		}
+		if len(logs) == 0 {
+			return c.Status(fiber.StatusInternalServerError).JSON(BatchLogResponse{
+				IDs:      []string{},
+				Sampled:  sampled,
+				Rejected: rejected,
+				Failed:   failed,
+			})
+		}
+	} else if err := h.logRepo.CreateBatch(logs); err != nil {
+		middleware.SetRequestError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create logs",
 		})
 	}
 
 	// Broadcast to WebSocket, publish to Redis and queue notifications
-	go func() {
+	h.dispatch(len(logs), func() {
 		ctx := context.Background()
 		for _, log := range logs {
-			logData := map[string]interface{}{
-				"id":           log.ID,
-				"project_id":   log.ProjectID,
-				"project_name": project.Name,
-				"level":        log.Level,
-				"message":      log.Message,
-				"metadata":     log.Metadata,
-				"source":       log.Source,
-				"created_at":   log.CreatedAt.Format(time.RFC3339),
-			}
-
-			// Broadcast to WebSocket clients
-			if h.wsHub != nil {
-				h.wsHub.BroadcastLog(logData, project.ID)
-			}
-
-			// Publish to Redis
-			if h.redisClient != nil {
-				h.redisClient.PublishLog(ctx, project.ID, logData)
-			}
-
-			h.queueNotifications(log, project)
+			h.publishLog(ctx, log, project)
 		}
-	}()
+	})
 
 	ids := make([]string, len(logs))
 	for i, log := range logs {
@@ -244,23 +591,75 @@ func (h *LogHandler) CreateBatchLogs(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(BatchLogResponse{
 		Received: len(logs),
 		IDs:      ids,
+		Sampled:  sampled,
+		Rejected: rejected,
+		Failed:   failed,
 	})
 }
 
+// dispatch hands the fan-out for count stored logs to the pool. When the
+// pool is saturated the logs stay stored but are not broadcast or notified.
+func (h *LogHandler) dispatch(count int, fn func()) {
+	if h.fanout.Submit(fn) {
+		return
+	}
+	if dropped := h.fanout.Stats().Dropped; dropped == 1 || dropped%1000 == 0 {
+		log.Printf("[Ingestion] Fanout queue full, skipped broadcast and notifications for %d logs (%d tasks dropped so far)", count, dropped)
+	}
+}
+
+// Drain stops the fanout pool and waits up to timeout for the broadcasts and
+// notification sends already queued, returning how many were left. Call it
+// once the server no longer accepts requests and the async buffer has been
+// flushed; fan-out for logs ingested later is dropped.
+func (h *LogHandler) Drain(timeout time.Duration) int {
+	return h.fanout.Shutdown(timeout)
+}
+
+// publishLog fans a stored log out to WebSocket clients, Redis subscribers,
+// notification channels and the project's outbound webhook
+func (h *LogHandler) publishLog(ctx context.Context, log *models.Log, project *models.Project) {
+	logData := map[string]interface{}{
+		"id":           log.ID,
+		"project_id":   log.ProjectID,
+		"project_name": project.Name,
+		"level":        log.Level,
+		"message":      log.Message,
+		"metadata":     log.Metadata,
+		"source":       log.Source,
+		"created_at":   log.CreatedAt.Format(time.RFC3339),
+	}
+
+	// Broadcast to WebSocket clients
+	if h.wsHub != nil {
+		h.wsHub.BroadcastLog(logData, project.ID)
+	}
+
+	// Publish to Redis for realtime streaming (if Redis is available)
+	if h.redisClient != nil && h.redisClient.Available() {
+		h.redisClient.PublishLog(ctx, project.ID, logData)
+	}
+
+	h.queueNotifications(log, project)
+
+	if h.webhooks != nil {
+		h.webhooks.Fire(project, log)
+	}
+}
+
 func (h *LogHandler) queueNotifications(log *models.Log, project *models.Project) {
 	// Send push notifications to all devices
 	// Service worker will check visibility and skip if page is visible (WebSocket toast handles it)
+	// Already on a fanout worker, so this runs inline
 	if h.pushService != nil {
-		go func() {
-			if err := h.pushService.SendLogNotification(log, project.Name); err != nil {
-				// Log error but don't fail the request
-				_ = err
-			}
-		}()
+		if err := h.pushService.SendLogNotification(log, project.Name); err != nil {
+			// Log error but don't fail the request
+			_ = err
+		}
 	}
 
 	// Queue other notifications via Redis (Telegram, Discord, etc.)
-	if h.redisClient == nil {
+	if h.redisClient == nil || !h.redisClient.Available() {
 		return // Redis not available, skip other notifications
 	}
 
@@ -269,24 +668,11 @@ func (h *LogHandler) queueNotifications(log *models.Log, project *models.Project
 		return
 	}
 
+	// PUSH channels are handled above, so they are never picked
 	ctx := context.Background()
-	for _, channel := range channels {
-		// Skip PUSH channels as they're handled above
-		if channel.Type == models.ChannelTypePush {
-			continue
-		}
-		if channel.ShouldNotify(log.Level) {
-			job := &queue.NotificationJob{
-				LogID:     log.ID,
-				ChannelID: channel.ID,
-				ProjectID: project.ID,
-				Level:     string(log.Level),
-				Message:   log.Message,
-				Source:    log.Source,
-				Timestamp: log.Timestamp.Format(time.RFC3339),
-			}
-			h.redisClient.EnqueueNotification(ctx, job)
-		}
+	notify, _ := notifyChannels(log, channels)
+	for _, channel := range notify {
+		h.redisClient.EnqueueNotification(ctx, newNotificationJob(log, channel.ID))
 	}
 }
 
@@ -326,9 +712,7 @@ func (h *LogHandler) ListLogs(c *fiber.Ctx) error {
 			if found {
 				projectIDs = []string{projectID}
 			} else {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "Access denied to this project",
-				})
+				return middleware.DenyAccess(c, "Access denied to this project", "Project not found")
 			}
 		}
 	}
@@ -338,6 +722,106 @@ func (h *LogHandler) ListLogs(c *fiber.Ctx) error {
 		ProjectIDs: projectIDs,
 	}
 
+	if err := parseLogFilterQuery(c, filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	h.queryBounds.Apply(filter)
+
+	// ?fields= trims each log to the listed fields; metadata, the costly
+	// part, is only read when asked for
+	fields, err := parseLogFields(c.Query("fields"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	filter.SkipMetadata = !hasField(fields, "metadata")
+
+	logs, total, err := h.logRepo.List(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list logs",
+		})
+	}
+
+	var result interface{} = logs
+	if len(fields) > 0 {
+		result = selectLogFields(logs, fields)
+	}
+	return c.JSON(fiber.Map{
+		"logs":   result,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// SearchLogs handles GET /api/admin/logs/search (admin only). It accepts the
+// same filters as ListLogs across all projects and returns facet counts for
+// the whole result set alongside the first page of logs.
+func (h *LogHandler) SearchLogs(c *fiber.Ctx) error {
+	filter := &models.LogFilter{}
+	if projectID := c.Query("project_id"); projectID != "" {
+		filter.ProjectIDs = []string{projectID}
+	}
+	if err := parseLogFilterQuery(c, filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	h.queryBounds.Apply(filter)
+
+	logs, total, err := h.logRepo.List(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search logs",
+		})
+	}
+
+	byProject, err := h.logRepo.FacetByProject(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute project facets",
+		})
+	}
+
+	byLevel, err := h.logRepo.FacetByLevel(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute level facets",
+		})
+	}
+
+	bySource, err := h.logRepo.FacetBySource(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute source facets",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"logs":   logs,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+		"facets": fiber.Map{
+			"projects": byProject,
+			"levels":   byLevel,
+			"sources":  bySource,
+		},
+	})
+}
+
+// parseLogFilterQuery applies the shared log query parameters to filter
+func parseLogFilterQuery(c *fiber.Ctx, filter *models.LogFilter) error {
+	timeField := c.Query("time_field")
+	if !models.IsValidLogTimeField(timeField) {
+		return fmt.Errorf("time_field must be %q or %q", models.LogTimeFieldTimestamp, models.LogTimeFieldCreatedAt)
+	}
+	filter.TimeField = timeField
+
 	if levels := c.Query("levels"); levels != "" {
 		for _, l := range splitAndTrim(levels, ",") {
 			filter.Levels = append(filter.Levels, models.ParseLogLevel(l))
@@ -352,6 +836,13 @@ func (h *LogHandler) ListLogs(c *fiber.Ctx) error {
 		filter.Search = search
 	}
 
+	if status := models.LogStatus(c.Query("status")); status != "" {
+		if !models.IsValidLogStatus(status) {
+			return fmt.Errorf("status must be %q, %q or %q", models.LogStatusNew, models.LogStatusAcknowledged, models.LogStatusResolved)
+		}
+		filter.Status = status
+	}
+
 	if start := c.Query("start_time"); start != "" {
 		if t, err := time.Parse(time.RFC3339, start); err == nil {
 			filter.StartTime = &t
@@ -376,19 +867,7 @@ func (h *LogHandler) ListLogs(c *fiber.Ctx) error {
 		}
 	}
 
-	logs, total, err := h.logRepo.List(filter)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list logs",
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"logs":   logs,
-		"total":  total,
-		"limit":  filter.Limit,
-		"offset": filter.Offset,
-	})
+	return nil
 }
 
 // GetLog handles GET /api/admin/logs/:id
@@ -418,15 +897,216 @@ func (h *LogHandler) GetLog(c *fiber.Ctx) error {
 	if !user.IsAdmin() {
 		hasAccess, _ := h.userProjectRepo.HasAccess(user.ID, log.ProjectID)
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Access denied",
-			})
+			return middleware.DenyAccess(c, "Access denied", "Log not found")
 		}
 	}
 
 	return c.JSON(log)
 }
 
+// ListProjectSources handles GET /api/admin/projects/:id/sources
+func (h *LogHandler) ListProjectSources(c *fiber.Ctx) error {
+	sources, err := h.logRepo.DistinctSources(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list sources",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sources": sources,
+	})
+}
+
+// Bounds of ?sample= on the metadata keys listing
+const (
+	defaultMetadataKeySample = 1000
+	maxMetadataKeySample     = 10000
+)
+
+// MetadataKeysResponse is the metadata keys of a project's sampled logs.
+// Sampled below SampleSize means every log of the project was examined.
+type MetadataKeysResponse struct {
+	*models.MetadataKeySample
+	SampleSize int `json:"sample_size"`
+}
+
+// ListProjectMetadataKeys handles GET /api/admin/projects/:id/metadata-keys.
+// It lists the top-level metadata keys of the project's newest logs, up to
+// ?sample= of them, with how often each appears.
+func (h *LogHandler) ListProjectMetadataKeys(c *fiber.Ctx) error {
+	sampleSize := defaultMetadataKeySample
+	if raw := c.Query("sample"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxMetadataKeySample {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("sample must be between 1 and %d", maxMetadataKeySample),
+			})
+		}
+		sampleSize = n
+	}
+
+	result, err := h.logRepo.MetadataKeys(c.Params("id"), sampleSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list metadata keys",
+		})
+	}
+
+	return c.JSON(MetadataKeysResponse{
+		MetadataKeySample: result,
+		SampleSize:        sampleSize,
+	})
+}
+
+// CountLogs handles GET /api/admin/logs/count. It takes the same filters as
+// ListLogs and returns only the number of matches.
+func (h *LogHandler) CountLogs(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	projectID := c.Query("project_id")
+	var projectIDs []string
+	if user.IsAdmin() {
+		if projectID != "" {
+			projectIDs = []string{projectID}
+		}
+	} else {
+		accessible, err := h.userProjectRepo.GetUserProjectIDs(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get projects",
+			})
+		}
+		for _, id := range accessible {
+			if projectID == "" || id == projectID {
+				projectIDs = append(projectIDs, id)
+			}
+		}
+		if projectID != "" && len(projectIDs) == 0 {
+			return middleware.DenyAccess(c, "Access denied to this project", "Project not found")
+		}
+		// An empty ProjectIDs would match every project
+		if len(projectIDs) == 0 {
+			return c.JSON(fiber.Map{
+				"total": 0,
+			})
+		}
+	}
+
+	filter := &models.LogFilter{
+		ProjectIDs: projectIDs,
+	}
+
+	if err := parseLogFilterQuery(c, filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	h.queryBounds.Apply(filter)
+
+	total, err := h.logRepo.Count(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count logs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"total": total,
+	})
+}
+
+// ListRecentErrors handles GET /api/admin/logs/recent-errors. It returns the
+// newest ERROR and CRITICAL logs across the projects the user can see, for
+// dashboard widgets.
+func (h *LogHandler) ListRecentErrors(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	projectID := c.Query("project_id")
+	var projectIDs []string
+	if user.IsAdmin() {
+		if projectID != "" {
+			projectIDs = []string{projectID}
+		}
+	} else {
+		accessible, err := h.userProjectRepo.GetUserProjectIDs(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get projects",
+			})
+		}
+		projectIDs = []string{}
+		for _, id := range accessible {
+			if projectID == "" || id == projectID {
+				projectIDs = append(projectIDs, id)
+			}
+		}
+		if projectID != "" && len(projectIDs) == 0 {
+			return middleware.DenyAccess(c, "Access denied to this project", "Project not found")
+		}
+	}
+
+	logs, err := h.logRepo.GetRecentByMinLevel(projectIDs, models.LogLevelError, c.QueryInt("limit", 20))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list recent errors",
+		})
+	}
+	if logs == nil {
+		logs = []*models.Log{}
+	}
+
+	return c.JSON(fiber.Map{
+		"logs": logs,
+	})
+}
+
+// ListSources handles GET /api/admin/sources. Admins get sources across all
+// projects; other users across the projects they belong to.
+func (h *LogHandler) ListSources(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var projectIDs []string
+	if !user.IsAdmin() {
+		var err error
+		projectIDs, err = h.userProjectRepo.GetUserProjectIDs(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get projects",
+			})
+		}
+		if projectIDs == nil {
+			projectIDs = []string{}
+		}
+	}
+
+	sources, err := h.logRepo.DistinctSourcesForProjects(projectIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list sources",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sources": sources,
+	})
+}
+
 func splitAndTrim(s, sep string) []string {
 	if s == "" {
 		return nil
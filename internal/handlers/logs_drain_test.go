@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"central-logs/internal/config"
+	"central-logs/internal/handlers"
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+	"central-logs/internal/services/notification"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/gofiber/fiber/v2"
+)
+
+// newPushSubscription returns a subscription with real browser-style keys,
+// which the push library needs to encrypt the payload
+func newPushSubscription(t *testing.T, projectID, endpoint string) *models.PushSubscription {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate subscription key: %v", err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+
+	return &models.PushSubscription{
+		UserID:    "user-1",
+		ProjectID: &projectID,
+		Endpoint:  endpoint,
+		P256dh:    base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:      base64.RawURLEncoding.EncodeToString(auth),
+	}
+}
+
+func TestLogHandler_DrainWaitsForNotifications(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE push_subscriptions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			project_id TEXT,
+			endpoint TEXT NOT NULL UNIQUE,
+			p256dh TEXT NOT NULL,
+			auth TEXT NOT NULL,
+			user_agent TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		t.Fatalf("Failed to create push_subscriptions table: %v", err)
+	}
+
+	// A slow push service, so the send is still running when the request returns
+	release := make(chan struct{})
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	var delivered atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		delivered.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	defer unblock()
+
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.VAPID.PublicKey = publicKey
+	cfg.VAPID.PrivateKey = privateKey
+
+	projectRepo := models.NewProjectRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	subscriptionRepo := models.NewPushSubscriptionRepository(db)
+
+	project := &models.Project{Name: "Shop", IsActive: true}
+	apiKey, _ := projectRepo.Create(project)
+	channelRepo.Create(&models.Channel{ProjectID: project.ID, Type: models.ChannelTypePush, Name: "Browser",
+		Config: map[string]interface{}{}, MinLevel: models.LogLevelError, IsActive: true})
+	if err := subscriptionRepo.Create(newPushSubscription(t, project.ID, server.URL+"/push/"+strings.Repeat("x", 48))); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+
+	pushService := notification.NewPushService(subscriptionRepo, channelRepo, cfg)
+	logHandler := handlers.NewLogHandler(models.NewLogRepository(db), channelRepo, models.NewUserProjectRepository(db), nil, pushService, nil)
+
+	app := fiber.New()
+	app.Use(middleware.NewAPIKeyMiddleware(projectRepo).RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+
+	req := httptest.NewRequest(http.MethodPost, "/logs", bytes.NewReader([]byte(`{"level":"ERROR","message":"payment failed"}`)))
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the log to be created, got %v %v", resp, err)
+	}
+
+	if remaining := logHandler.Drain(50 * time.Millisecond); remaining == 0 {
+		t.Fatal("Expected the push send to still be in flight")
+	}
+
+	// Shutdown begins while the push service is still answering
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		unblock()
+	}()
+	if remaining := logHandler.Drain(5 * time.Second); remaining != 0 {
+		t.Fatalf("Expected every send to finish within the timeout, %d still running", remaining)
+	}
+	if delivered.Load() != 1 {
+		t.Errorf("Expected the notification to be delivered before Drain returned, got %d deliveries", delivered.Load())
+	}
+}
@@ -3,16 +3,24 @@ package handlers_test
 import (
 	"bytes"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"central-logs/internal/handlers"
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
+	"central-logs/internal/services/geoip"
+	"central-logs/internal/services/redaction"
 	"central-logs/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -37,6 +45,9 @@ func setupLogTestDB(t *testing.T) *sql.DB {
 			two_factor_secret TEXT DEFAULT '',
 			two_factor_enabled INTEGER DEFAULT 0,
 			backup_codes TEXT DEFAULT '',
+			deleted_at DATETIME,
+			last_login_at DATETIME,
+			token_version INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -54,8 +65,20 @@ func setupLogTestDB(t *testing.T) *sql.DB {
 			api_key_prefix TEXT NOT NULL,
 			is_active INTEGER NOT NULL DEFAULT 1,
 			retention_config TEXT,
+			redaction_config TEXT,
+			sampling_config TEXT,
+			signing_secret TEXT,
+			max_body_bytes INTEGER,
+			rate_limit_per_minute INTEGER,
+			rate_limit_burst INTEGER,
+			ingestion_paused INTEGER NOT NULL DEFAULT 0,
+			default_level TEXT,
+			require_level INTEGER NOT NULL DEFAULT 0,
+			outbound_webhook TEXT,
+			group_name TEXT,
 			icon_type TEXT DEFAULT 'initials',
 			icon_value TEXT DEFAULT '',
+			deleted_at DATETIME,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -89,6 +112,9 @@ func setupLogTestDB(t *testing.T) *sql.DB {
 			source TEXT,
 			timestamp DATETIME NOT NULL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			status TEXT NOT NULL DEFAULT 'new',
+			acknowledged_by TEXT,
+			acknowledged_at DATETIME,
 			FOREIGN KEY (project_id) REFERENCES projects(id)
 		)
 	`)
@@ -190,6 +216,90 @@ func TestLogHandler_CreateLog_Success(t *testing.T) {
 	}
 }
 
+func TestLogHandler_ValidateLog(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs/validate", logHandler.ValidateLog)
+
+	validate := func(reqBody map[string]interface{}) (int, handlers.ValidateLogResponse) {
+		bodyBytes, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/logs/validate", bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var response handlers.ValidateLogResponse
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return resp.StatusCode, response
+	}
+
+	status, response := validate(map[string]interface{}{
+		"level":     "WARN",
+		"message":   "disk almost full",
+		"source":    "worker",
+		"timestamp": "2024-05-01T10:00:00Z",
+		"metadata":  map[string]interface{}{"disk": "/dev/sda1"},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if len(response.Warnings) != 0 {
+		t.Errorf("Expected no warnings for a clean payload, got %v", response.Warnings)
+	}
+	if response.Log.Level != models.LogLevelWarn || response.Log.Source != "worker" || response.Log.Metadata["disk"] != "/dev/sda1" {
+		t.Errorf("Unexpected normalized log %+v", response.Log)
+	}
+	if !response.Log.Timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the client timestamp, got %s", response.Log.Timestamp)
+	}
+
+	status, response = validate(map[string]interface{}{
+		"level":     "error",
+		"message":   "lowercase level",
+		"timestamp": "yesterday",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if response.Log.Level != models.LogLevelInfo {
+		t.Errorf("Expected level coerced to INFO, got %s", response.Log.Level)
+	}
+	if len(response.Warnings) != 2 {
+		t.Errorf("Expected level and timestamp warnings, got %v", response.Warnings)
+	}
+
+	if status, _ := validate(map[string]interface{}{"level": "INFO"}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a message, got %d", status)
+	}
+
+	// Nothing was stored
+	if count, _ := logRepo.CountByProject(project.ID); count != 0 {
+		t.Errorf("Expected no logs to be stored, got %d", count)
+	}
+}
+
 func TestLogHandler_CreateLog_InvalidAPIKey(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
@@ -507,6 +617,99 @@ func TestLogHandler_CreateBatchLogs_TooManyLogs(t *testing.T) {
 	}
 }
 
+func TestLogHandler_CreateTextLogs(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, models.NewChannelRepository(db), models.NewUserProjectRepository(db), nil, nil, nil)
+
+	project := &models.Project{Name: "Shell Scripts", IsActive: true}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs/text", logHandler.CreateTextLogs)
+
+	post := func(query, body string) (*http.Response, handlers.BatchLogResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/logs/text"+query, strings.NewReader(body))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "text/plain")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var response handlers.BatchLogResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp, response
+	}
+	stored := func(ids []string) []*models.Log {
+		var logs []*models.Log
+		for _, id := range ids {
+			log, err := logRepo.GetByID(id)
+			if err != nil || log == nil {
+				t.Fatalf("Expected log %s to be stored: %v", id, err)
+			}
+			logs = append(logs, log)
+		}
+		return logs
+	}
+
+	t.Run("OneLogPerLine", func(t *testing.T) {
+		resp, response := post("", "backup started\r\n\n   \n  at step 2 \nbackup done")
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+		if response.Received != 3 {
+			t.Fatalf("Expected 3 logs, skipping blank lines, got %d", response.Received)
+		}
+		logs := stored(response.IDs)
+		want := []string{"backup started", "  at step 2", "backup done"}
+		for i, log := range logs {
+			if log.Message != want[i] || log.Level != models.LogLevelInfo || log.Source != "" {
+				t.Errorf("Expected INFO %q without a source, got %s %q source %q", want[i], log.Level, log.Message, log.Source)
+			}
+		}
+	})
+
+	t.Run("LevelAndSourceOverrides", func(t *testing.T) {
+		resp, response := post("?level=warning&source=backup.sh", "disk 91% full\ndisk 95% full\n")
+		if resp.StatusCode != http.StatusCreated || response.Received != 2 {
+			t.Fatalf("Expected 2 logs created, got status %d and %d logs", resp.StatusCode, response.Received)
+		}
+		for _, log := range stored(response.IDs) {
+			if log.Level != models.LogLevelWarn || log.Source != "backup.sh" {
+				t.Errorf("Expected WARN from backup.sh, got %s from %q", log.Level, log.Source)
+			}
+		}
+	})
+
+	t.Run("UnknownLevel", func(t *testing.T) {
+		if resp, _ := post("?level=loud", "hello"); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an unknown level, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("EmptyBody", func(t *testing.T) {
+		if resp, _ := post("", "\n \n"); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for a body without lines, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("TooManyLines", func(t *testing.T) {
+		before, _ := logRepo.CountByProject(project.ID)
+		if resp, _ := post("", strings.Repeat("line\n", 1001)); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for 1001 lines, got %d", resp.StatusCode)
+		}
+		if after, _ := logRepo.CountByProject(project.ID); after != before {
+			t.Errorf("Expected nothing stored from a rejected request, got %d new logs", after-before)
+		}
+	})
+}
+
 func TestLogHandler_CreateBatchLogs_SkipsInvalidEntries(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
@@ -637,6 +840,245 @@ func TestLogHandler_ListLogs_Admin(t *testing.T) {
 	}
 }
 
+func TestLogHandler_ListLogs_Fields(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+	logHandler := handlers.NewLogHandler(logRepo, models.NewChannelRepository(db), models.NewUserProjectRepository(db), nil, nil, nil)
+
+	admin := &models.User{Email: "admin@example.com", Password: "password123", Name: "Admin User", Role: models.RoleAdmin, IsActive: true}
+	userRepo.Create(admin)
+	project := &models.Project{Name: "Test Project", IsActive: true}
+	projectRepo.Create(project)
+	logRepo.Create(&models.Log{
+		ProjectID: project.ID,
+		Level:     models.LogLevelError,
+		Message:   "Payment failed",
+		Source:    "billing",
+		Metadata:  map[string]interface{}{"order_id": "ord-1"},
+		Timestamp: time.Now(),
+	})
+	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/logs", logHandler.ListLogs)
+
+	list := func(query string) (int, []map[string]interface{}, string) {
+		req := httptest.NewRequest(http.MethodGet, "/logs"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var response struct {
+			Logs  []map[string]interface{} `json:"logs"`
+			Error string                   `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response.Logs, response.Error
+	}
+
+	status, logs, _ := list("?fields=id,metadata,message")
+	if status != http.StatusOK || len(logs) != 1 {
+		t.Fatalf("Expected 1 log, got %d %v", status, logs)
+	}
+	if len(logs[0]) != 3 || logs[0]["message"] != "Payment failed" || logs[0]["metadata"] == nil {
+		t.Errorf("Expected only id, metadata and message, got %v", logs[0])
+	}
+
+	status, _, errMsg := list("?fields=id,password")
+	if status != http.StatusBadRequest || !strings.Contains(errMsg, `unknown field "password"`) {
+		t.Errorf("Expected 400 naming the unknown field, got %d %q", status, errMsg)
+	}
+
+	// With metadata that can't be decoded, only listings that ask for it fail
+	db.Exec(`UPDATE logs SET metadata = 'not json'`)
+	status, logs, _ = list("?fields=id,level,message,timestamp")
+	if status != http.StatusOK || len(logs) != 1 {
+		t.Fatalf("Expected the metadata to be skipped, got %d %v", status, logs)
+	}
+	for _, omitted := range []string{"metadata", "source", "project_id", "created_at"} {
+		if _, ok := logs[0][omitted]; ok {
+			t.Errorf("Expected %s to be left out, got %v", omitted, logs[0])
+		}
+	}
+	if logs[0]["level"] != "ERROR" || logs[0]["timestamp"] == nil || logs[0]["id"] == nil {
+		t.Errorf("Expected the requested fields, got %v", logs[0])
+	}
+	if status, _, _ := list(""); status != http.StatusInternalServerError {
+		t.Errorf("Expected the full listing to read the metadata, got %d", status)
+	}
+}
+
+func TestLogHandler_ListLogs_QueryBounds(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetQueryBounds(models.LogQueryBounds{DefaultRange: 24 * time.Hour, MaxLimit: 2})
+
+	admin := &models.User{
+		Email:    "admin@example.com",
+		Password: "password123",
+		Name:     "Admin User",
+		Role:     models.RoleAdmin,
+		IsActive: true,
+	}
+	userRepo.Create(admin)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	projectRepo.Create(project)
+
+	// Three recent logs and one outside the default range
+	old := time.Now().Add(-72 * time.Hour)
+	for _, ts := range []time.Time{time.Now(), time.Now(), time.Now(), old} {
+		logRepo.Create(&models.Log{
+			ProjectID: project.ID,
+			Level:     models.LogLevelInfo,
+			Message:   "Test message",
+			Timestamp: ts,
+		})
+	}
+
+	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/logs", logHandler.ListLogs)
+
+	list := func(query string) (total, limit, returned int) {
+		req := httptest.NewRequest(http.MethodGet, "/logs"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var response struct {
+			Logs  []models.Log `json:"logs"`
+			Total int          `json:"total"`
+			Limit int          `json:"limit"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return response.Total, response.Limit, len(response.Logs)
+	}
+
+	total, limit, returned := list("?limit=100")
+	if total != 3 {
+		t.Errorf("Expected the default range to leave 3 logs, got %d", total)
+	}
+	if limit != 2 || returned != 2 {
+		t.Errorf("Expected limit clamped to 2, got limit %d with %d logs", limit, returned)
+	}
+
+	start := old.Add(-time.Hour).UTC().Format(time.RFC3339)
+	if total, _, _ := list("?start_time=" + start); total != 4 {
+		t.Errorf("Expected an explicit start_time to replace the default range, got %d logs", total)
+	}
+}
+
+func TestLogHandler_MixedTimestampOffsets(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	admin := &models.User{Email: "admin@example.com", Password: "password123", Name: "Admin User", Role: models.RoleAdmin, IsActive: true}
+	userRepo.Create(admin)
+	project := &models.Project{Name: "Test Project", IsActive: true}
+	apiKey, _ := projectRepo.Create(project)
+	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Post("/logs", apiKeyMiddleware.RequireAPIKey(), logHandler.CreateLog)
+	app.Post("/logs/batch", apiKeyMiddleware.RequireAPIKey(), logHandler.CreateBatchLogs)
+	app.Get("/logs", authMiddleware.RequireAuth(), logHandler.ListLogs)
+
+	send := func(path string, body interface{}) {
+		bodyBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+	}
+
+	// As text the +07:00 time reads hours later than the others, though it
+	// is the earliest event
+	jakarta := time.FixedZone("WIB", 7*3600)
+	newYork := time.FixedZone("EST", -5*3600)
+	base := time.Now().UTC().Truncate(time.Second).Add(-2 * time.Hour)
+	earliest, middle, latest := base, base.Add(30*time.Minute), base.Add(time.Hour)
+
+	send("/logs/batch", map[string]interface{}{"logs": []map[string]interface{}{
+		{"level": "INFO", "message": "earliest", "timestamp": earliest.In(jakarta).Format(time.RFC3339)},
+		{"level": "INFO", "message": "latest", "timestamp": latest.In(newYork).Format(time.RFC3339)},
+	}})
+	send("/logs", map[string]interface{}{"level": "INFO", "message": "middle", "timestamp": middle.Format(time.RFC3339)})
+
+	start := base.Add(15 * time.Minute).In(jakarta).Format(time.RFC3339)
+	req := httptest.NewRequest(http.MethodGet, "/logs?start_time="+url.QueryEscape(start), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var response struct {
+		Logs []models.Log `json:"logs"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	if len(response.Logs) != 2 {
+		t.Fatalf("Expected the 2 logs after the start time, got %d", len(response.Logs))
+	}
+	if response.Logs[0].Message != "latest" || response.Logs[1].Message != "middle" {
+		t.Errorf("Expected latest then middle, got %s then %s", response.Logs[0].Message, response.Logs[1].Message)
+	}
+	if !response.Logs[0].Timestamp.Equal(latest) {
+		t.Errorf("Expected timestamp %s, got %s", latest, response.Logs[0].Timestamp)
+	}
+	if _, offset := response.Logs[0].Timestamp.Zone(); offset != 0 {
+		t.Errorf("Expected the timestamp to be stored in UTC, got offset %d", offset)
+	}
+}
+
 func TestLogHandler_ListLogs_RegularUser(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
@@ -731,7 +1173,7 @@ func TestLogHandler_ListLogs_RegularUser(t *testing.T) {
 	}
 }
 
-func TestLogHandler_ListLogs_WithFilters(t *testing.T) {
+func TestLogHandler_AckLogs(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
 
@@ -745,71 +1187,279 @@ func TestLogHandler_ListLogs_WithFilters(t *testing.T) {
 
 	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
 
-	admin := &models.User{
-		Email:    "admin@example.com",
+	user := &models.User{
+		Email:    "oncall@example.com",
 		Password: "password123",
-		Name:     "Admin User",
-		Role:     models.RoleAdmin,
-		IsActive: true,
-	}
-	userRepo.Create(admin)
-
-	project := &models.Project{
-		Name:     "Test Project",
+		Name:     "On Call",
+		Role:     models.RoleUser,
 		IsActive: true,
 	}
-	projectRepo.Create(project)
+	userRepo.Create(user)
 
-	// Create logs with different levels
-	levels := []models.LogLevel{
-		models.LogLevelError,
-		models.LogLevelError,
-		models.LogLevelWarn,
-		models.LogLevelInfo,
-		models.LogLevelDebug,
-	}
+	project1 := &models.Project{Name: "Project 1", IsActive: true}
+	projectRepo.Create(project1)
+	project2 := &models.Project{Name: "Project 2", IsActive: true}
+	projectRepo.Create(project2)
 
-	for _, level := range levels {
+	userProjectRepo.Create(&models.UserProject{
+		UserID:    user.ID,
+		ProjectID: project1.ID,
+		Role:      models.ProjectRoleMember,
+	})
+
+	var created []*models.Log
+	for _, l := range []struct {
+		projectID string
+		level     models.LogLevel
+	}{
+		{project1.ID, models.LogLevelInfo},
+		{project1.ID, models.LogLevelError},
+		{project1.ID, models.LogLevelError},
+		{project1.ID, models.LogLevelError},
+		{project2.ID, models.LogLevelError},
+	} {
 		log := &models.Log{
-			ProjectID: project.ID,
-			Level:     level,
-			Message:   "Test message",
+			ProjectID: l.projectID,
+			Level:     l.level,
+			Message:   "burst",
 			Timestamp: time.Now(),
 		}
 		logRepo.Create(log)
+		created = append(created, log)
 	}
 
-	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
-
 	app := fiber.New()
 	app.Use(authMiddleware.RequireAuth())
-	app.Get("/logs", logHandler.ListLogs)
+	app.Post("/logs/ack", logHandler.AckLogs)
 
-	// Filter by ERROR level
-	req := httptest.NewRequest(http.MethodGet, "/logs?levels=ERROR", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+	ack := func(body string) (int, int64) {
+		req := httptest.NewRequest(http.MethodPost, "/logs/ack", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
 
-	resp, err := app.Test(req)
-	if err != nil {
-		t.Fatalf("Failed to make request: %v", err)
+		var response handlers.AckLogsResponse
+		respBody, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(respBody, &response)
+		return resp.StatusCode, response.Acknowledged
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	// Without project_ids the user's own projects are used, so project 2's
+	// error is left alone
+	status, acknowledged := ack(`{"levels": ["error"]}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if acknowledged != 3 {
+		t.Errorf("Expected 3 logs acknowledged, got %d", acknowledged)
 	}
 
-	var response map[string]interface{}
-	body, _ := io.ReadAll(resp.Body)
-	json.Unmarshal(body, &response)
+	for i, log := range created {
+		found, _ := logRepo.GetByID(log.ID)
+		wantAcked := log.ProjectID == project1.ID && log.Level == models.LogLevelError
+		if acked := found.Status == models.LogStatusAcknowledged; acked != wantAcked {
+			t.Errorf("Log %d: expected acknowledged=%v, got status %s", i, wantAcked, found.Status)
+		}
+		if wantAcked && found.AcknowledgedBy != user.ID {
+			t.Errorf("Log %d: expected acknowledged by %s, got %q", i, user.ID, found.AcknowledgedBy)
+		}
+	}
 
-	logs := response["logs"].([]interface{})
-	// Should only return ERROR logs
-	if len(logs) != 2 {
-		t.Errorf("Expected 2 ERROR logs, got %d", len(logs))
+	if _, acknowledged := ack(`{"project_ids": ["` + project1.ID + `"], "levels": ["ERROR"]}`); acknowledged != 0 {
+		t.Errorf("Expected already acknowledged logs to be skipped, got %d", acknowledged)
 	}
-}
 
-func TestLogHandler_GetLog_Success(t *testing.T) {
+	if status, _ := ack(`{"project_ids": ["` + project2.ID + `"]}`); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 for another project, got %d", status)
+	}
+	if status, _ := ack(`{}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty filter, got %d", status)
+	}
+	if status, _ := ack(`{"levels": ["FATAL"]}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown level, got %d", status)
+	}
+}
+
+func TestLogHandler_CountLogs_RegularUser(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	user := &models.User{
+		Email:    "user@example.com",
+		Password: "password123",
+		Name:     "Regular User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	userRepo.Create(user)
+	loner := &models.User{
+		Username: "loner",
+		Email:    "loner@example.com",
+		Password: "password123",
+		Name:     "No Projects",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	userRepo.Create(loner)
+
+	project1 := &models.Project{Name: "Project 1", IsActive: true}
+	projectRepo.Create(project1)
+	project2 := &models.Project{Name: "Project 2", IsActive: true}
+	projectRepo.Create(project2)
+
+	userProjectRepo.Create(&models.UserProject{
+		UserID:    user.ID,
+		ProjectID: project1.ID,
+		Role:      models.ProjectRoleMember,
+	})
+
+	for _, l := range []struct {
+		projectID string
+		level     models.LogLevel
+	}{
+		{project1.ID, models.LogLevelInfo},
+		{project1.ID, models.LogLevelError},
+		{project1.ID, models.LogLevelError},
+		{project2.ID, models.LogLevelError},
+	} {
+		logRepo.Create(&models.Log{
+			ProjectID: l.projectID,
+			Level:     l.level,
+			Message:   "log",
+			Timestamp: time.Now(),
+		})
+	}
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/logs/count", logHandler.CountLogs)
+
+	count := func(u *models.User, query string) (int, int) {
+		token, _ := jwtManager.Generate(u.ID, u.Email, string(u.Role))
+		req := httptest.NewRequest(http.MethodGet, "/logs/count"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var response struct {
+			Total int `json:"total"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return resp.StatusCode, response.Total
+	}
+
+	if status, total := count(user, ""); status != http.StatusOK || total != 3 {
+		t.Errorf("Expected 3 logs in the user's project, got %d (status %d)", total, status)
+	}
+	if _, total := count(user, "?levels=ERROR"); total != 2 {
+		t.Errorf("Expected 2 ERROR logs, got %d", total)
+	}
+	if status, _ := count(user, "?project_id="+project2.ID); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 for another project, got %d", status)
+	}
+	if status, total := count(loner, ""); status != http.StatusOK || total != 0 {
+		t.Errorf("Expected 0 logs for a user without projects, got %d (status %d)", total, status)
+	}
+}
+
+func TestLogHandler_ListLogs_WithFilters(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	admin := &models.User{
+		Email:    "admin@example.com",
+		Password: "password123",
+		Name:     "Admin User",
+		Role:     models.RoleAdmin,
+		IsActive: true,
+	}
+	userRepo.Create(admin)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	projectRepo.Create(project)
+
+	// Create logs with different levels
+	levels := []models.LogLevel{
+		models.LogLevelError,
+		models.LogLevelError,
+		models.LogLevelWarn,
+		models.LogLevelInfo,
+		models.LogLevelDebug,
+	}
+
+	for _, level := range levels {
+		log := &models.Log{
+			ProjectID: project.ID,
+			Level:     level,
+			Message:   "Test message",
+			Timestamp: time.Now(),
+		}
+		logRepo.Create(log)
+	}
+
+	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/logs", logHandler.ListLogs)
+
+	// Filter by ERROR level
+	req := httptest.NewRequest(http.MethodGet, "/logs?levels=ERROR", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var response map[string]interface{}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	logs := response["logs"].([]interface{})
+	// Should only return ERROR logs
+	if len(logs) != 2 {
+		t.Errorf("Expected 2 ERROR logs, got %d", len(logs))
+	}
+}
+
+func TestLogHandler_GetLog_Success(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
 
@@ -981,3 +1631,1392 @@ func TestLogHandler_GetLog_AccessDenied(t *testing.T) {
 		t.Errorf("Expected status 403, got %d", resp.StatusCode)
 	}
 }
+
+func TestLogHandler_GetLog_SoftDeletedProject(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	member := &models.User{Username: "member", Email: "member@example.com", Password: "password123", Name: "Member", Role: models.RoleUser, IsActive: true}
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Password: "password123", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	userRepo.Create(member)
+	userRepo.Create(admin)
+
+	project := &models.Project{Name: "Test Project", IsActive: true}
+	projectRepo.Create(project)
+	userProjectRepo.Create(&models.UserProject{UserID: member.ID, ProjectID: project.ID, Role: models.ProjectRoleMember})
+
+	log := &models.Log{ProjectID: project.ID, Level: models.LogLevelError, Message: "Test error", Timestamp: time.Now()}
+	logRepo.Create(log)
+	projectRepo.SoftDelete(project.ID)
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/logs/:id", logHandler.GetLog)
+	app.Post("/logs/:id/notify", logHandler.ResendNotifications)
+
+	for _, user := range []*models.User{member, admin} {
+		token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			path := "/logs/" + log.ID
+			if method == http.MethodPost {
+				path += "/notify"
+			}
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("%s %s as %s: expected status 404, got %d", method, path, user.Username, resp.StatusCode)
+			}
+		}
+	}
+
+	if updated, _ := logRepo.UpdateStatus(log.ID, models.LogStatusResolved, ""); updated {
+		t.Error("Expected a log of a soft-deleted project not to be acknowledged")
+	}
+
+	projectRepo.Restore(project.ID)
+	if got, _ := logRepo.GetByID(log.ID); got == nil {
+		t.Error("Expected the log to be found again after a restore")
+	}
+}
+
+func TestLogHandler_ResendNotifications_Access(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	// Without Redis, requests that pass every check end at 503
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	newUser := func(username string) (*models.User, string) {
+		user := &models.User{Username: username, Email: username + "@example.com", Password: "password123", Name: username, Role: models.RoleUser, IsActive: true}
+		userRepo.Create(user)
+		token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+		return user, token
+	}
+	member, memberToken := newUser("member")
+	viewer, viewerToken := newUser("viewer")
+	_, outsiderToken := newUser("outsider")
+
+	project := &models.Project{Name: "Test Project", IsActive: true}
+	projectRepo.Create(project)
+	otherProject := &models.Project{Name: "Other Project", IsActive: true}
+	projectRepo.Create(otherProject)
+	userProjectRepo.Create(&models.UserProject{UserID: member.ID, ProjectID: project.ID, Role: models.ProjectRoleMember})
+	userProjectRepo.Create(&models.UserProject{UserID: viewer.ID, ProjectID: project.ID, Role: models.ProjectRoleViewer})
+
+	foreign := &models.Channel{ProjectID: otherProject.ID, Type: models.ChannelTypeDiscord, Name: "Elsewhere",
+		Config: map[string]interface{}{"webhook_url": "https://discord.test/hook"}, MinLevel: models.LogLevelError, IsActive: true}
+	channelRepo.Create(foreign)
+
+	log := &models.Log{ProjectID: project.ID, Level: models.LogLevelError, Message: "Test error", Timestamp: time.Now()}
+	logRepo.Create(log)
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Post("/logs/:id/notify", logHandler.ResendNotifications)
+
+	tests := []struct {
+		name       string
+		token      string
+		logID      string
+		body       string
+		obscure    bool
+		wantStatus int
+	}{
+		{"outsider", outsiderToken, log.ID, "", false, http.StatusForbidden},
+		{"viewer", viewerToken, log.ID, "", false, http.StatusForbidden},
+		{"outsider obscured", outsiderToken, log.ID, "", true, http.StatusNotFound},
+		{"viewer obscured", viewerToken, log.ID, "", true, http.StatusNotFound},
+		{"unknown log", memberToken, "missing", "", false, http.StatusNotFound},
+		{"channel of another project", memberToken, log.ID, `{"channel_id":"` + foreign.ID + `"}`, false, http.StatusNotFound},
+		{"member without a queue", memberToken, log.ID, "", false, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware.SetObscureNotFound(tt.obscure)
+			defer middleware.SetObscureNotFound(false)
+
+			req := httptest.NewRequest(http.MethodPost, "/logs/"+tt.logID+"/notify", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestLogHandler_ListSources_RegularUser(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	user := &models.User{
+		Email:    "user@example.com",
+		Password: "password123",
+		Name:     "Regular User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	userRepo.Create(user)
+
+	project1 := &models.Project{Name: "Project 1", IsActive: true}
+	projectRepo.Create(project1)
+	project2 := &models.Project{Name: "Project 2", IsActive: true}
+	projectRepo.Create(project2)
+
+	userProjectRepo.Create(&models.UserProject{
+		UserID:    user.ID,
+		ProjectID: project1.ID,
+		Role:      models.ProjectRoleMember,
+	})
+
+	for _, l := range []struct{ projectID, source string }{
+		{project1.ID, "worker"},
+		{project1.ID, "api"},
+		{project1.ID, "api"},
+		{project2.ID, "billing"},
+	} {
+		logRepo.Create(&models.Log{
+			ProjectID: l.projectID,
+			Level:     models.LogLevelInfo,
+			Message:   "log",
+			Source:    l.source,
+			Timestamp: time.Now(),
+		})
+	}
+
+	token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/sources", logHandler.ListSources)
+
+	req := httptest.NewRequest(http.MethodGet, "/sources", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Sources []models.FacetCount `json:"sources"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	// Only project1's sources, most common first
+	if len(response.Sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %v", response.Sources)
+	}
+	if response.Sources[0].Value != "api" || response.Sources[1].Value != "worker" {
+		t.Errorf("Expected [api worker], got %v", response.Sources)
+	}
+}
+
+func TestLogHandler_ListRecentErrors_RegularUser(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	user := &models.User{
+		Email:    "user@example.com",
+		Password: "password123",
+		Name:     "Regular User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	userRepo.Create(user)
+
+	project1 := &models.Project{Name: "Project 1", IsActive: true}
+	projectRepo.Create(project1)
+	project2 := &models.Project{Name: "Project 2", IsActive: true}
+	projectRepo.Create(project2)
+
+	userProjectRepo.Create(&models.UserProject{
+		UserID:    user.ID,
+		ProjectID: project1.ID,
+		Role:      models.ProjectRoleMember,
+	})
+
+	now := time.Now()
+	for i, l := range []struct {
+		projectID string
+		level     models.LogLevel
+	}{
+		{project1.ID, models.LogLevelError},
+		{project1.ID, models.LogLevelWarn},
+		{project1.ID, models.LogLevelCritical},
+		{project2.ID, models.LogLevelError},
+	} {
+		logRepo.Create(&models.Log{
+			ProjectID: l.projectID,
+			Level:     l.level,
+			Message:   string(l.level),
+			Timestamp: now.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/logs/recent-errors", logHandler.ListRecentErrors)
+
+	req := httptest.NewRequest(http.MethodGet, "/logs/recent-errors", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Logs []models.Log `json:"logs"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	// Only project1's ERROR and CRITICAL logs, newest first
+	if len(response.Logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %v", response.Logs)
+	}
+	if response.Logs[0].Level != models.LogLevelCritical || response.Logs[1].Level != models.LogLevelError {
+		t.Errorf("Expected [CRITICAL ERROR], got [%s %s]", response.Logs[0].Level, response.Logs[1].Level)
+	}
+	if response.Logs[0].ProjectName != "Project 1" {
+		t.Errorf("Expected project name to be included, got %q", response.Logs[0].ProjectName)
+	}
+
+	// Asking for a project the user cannot see is refused
+	req = httptest.NewRequest(http.MethodGet, "/logs/recent-errors?project_id="+project2.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestLogHandler_CreateLog_OverLimits(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetIngestionLimits(handlers.IngestionLimits{
+		MaxMessageBytes:  16,
+		MaxMetadataBytes: 64,
+		MaxMetadataDepth: 2,
+		MaxMetadataKeys:  3,
+	})
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+
+	tests := []struct {
+		name      string
+		body      map[string]interface{}
+		wantLimit string
+	}{
+		{
+			name:      "message too long",
+			body:      map[string]interface{}{"message": "this message is far too long"},
+			wantLimit: "max_message_bytes",
+		},
+		{
+			name: "metadata too deep",
+			body: map[string]interface{}{
+				"message":  "short",
+				"metadata": map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}}},
+			},
+			wantLimit: "max_metadata_depth",
+		},
+		{
+			name: "metadata too large",
+			body: map[string]interface{}{
+				"message":  "short",
+				"metadata": map[string]interface{}{"blob": strings.Repeat("x", 100)},
+			},
+			wantLimit: "max_metadata_bytes",
+		},
+		{
+			name: "too many metadata keys",
+			body: map[string]interface{}{
+				"message":  "short",
+				"metadata": map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4},
+			},
+			wantLimit: "max_metadata_keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/logs", bytes.NewReader(bodyBytes))
+			req.Header.Set("X-API-Key", apiKey)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", resp.StatusCode)
+			}
+
+			var response map[string]interface{}
+			body, _ := io.ReadAll(resp.Body)
+			json.Unmarshal(body, &response)
+
+			if response["limit"] != tt.wantLimit {
+				t.Errorf("Expected limit %q, got %v", tt.wantLimit, response["limit"])
+			}
+		})
+	}
+
+	if count, _ := logRepo.CountByProject(project.ID); count != 0 {
+		t.Errorf("Expected no logs stored, got %d", count)
+	}
+}
+
+func TestLogHandler_CreateLog_TruncatesOverLimits(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetIngestionLimits(handlers.IngestionLimits{
+		MaxMessageBytes:  2,
+		MaxMetadataDepth: 1,
+		Truncate:         true,
+	})
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+
+	bodyBytes, _ := json.Marshal(map[string]interface{}{
+		"message":  "héllo world",
+		"metadata": map[string]interface{}{"user": "alice", "request": map[string]interface{}{"path": "/"}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/logs", bytes.NewReader(bodyBytes))
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	var response handlers.CreateLogResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	log, _ := logRepo.GetByID(response.ID)
+	if log == nil {
+		t.Fatal("Expected the truncated log to be stored")
+	}
+	// "é" spans bytes 1-2, so cutting at two bytes must not split it
+	if log.Message != "h" {
+		t.Errorf("Expected message truncated to %q, got %q", "h", log.Message)
+	}
+	if log.Metadata["user"] != "alice" || log.Metadata["request"] != "[truncated]" {
+		t.Errorf("Expected nested metadata pruned, got %v", log.Metadata)
+	}
+}
+
+func TestLogHandler_MessageTruncateLength(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	// Below the byte limit, so only the character cut applies
+	logHandler.SetIngestionLimits(handlers.IngestionLimits{MaxMessageBytes: 64, MaxMetadataKeys: 1, MessageTruncateLength: 5})
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	post := func(path string, payload interface{}) []byte {
+		bodyBytes, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return body
+	}
+
+	var single handlers.CreateLogResponse
+	json.Unmarshal(post("/logs", map[string]interface{}{
+		"message":  "héllo wörld",
+		"metadata": map[string]interface{}{"user": "alice"},
+	}), &single)
+	log, _ := logRepo.GetByID(single.ID)
+	if log == nil {
+		t.Fatal("Expected the truncated log to be stored")
+	}
+	// Counted in characters, so the two-byte "é" counts once
+	if log.Message != "héllo" {
+		t.Errorf("Expected message truncated to %q, got %q", "héllo", log.Message)
+	}
+	// The markers don't count against max_metadata_keys
+	if log.Metadata["_truncated"] != true || log.Metadata["_original_length"] != float64(11) || log.Metadata["user"] != "alice" {
+		t.Errorf("Expected truncation markers next to the metadata, got %v", log.Metadata)
+	}
+
+	var batch handlers.BatchLogResponse
+	json.Unmarshal(post("/logs/batch", map[string]interface{}{
+		"logs": []map[string]interface{}{
+			{"message": "short"},
+			{"message": "a much longer message"},
+		},
+	}), &batch)
+	if len(batch.IDs) != 2 {
+		t.Fatalf("Expected both entries stored, got %+v", batch)
+	}
+	kept, _ := logRepo.GetByID(batch.IDs[0])
+	if kept.Message != "short" || kept.Metadata["_truncated"] != nil {
+		t.Errorf("Expected a message at the limit kept whole, got %q with %v", kept.Message, kept.Metadata)
+	}
+	cut, _ := logRepo.GetByID(batch.IDs[1])
+	if cut.Message != "a muc" || cut.Metadata["_truncated"] != true || cut.Metadata["_original_length"] != float64(21) {
+		t.Errorf("Expected the long entry cut and marked, got %q with %v", cut.Message, cut.Metadata)
+	}
+}
+
+func TestLogHandler_CreateBatchLogs_TruncatesMetadataKeys(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetIngestionLimits(handlers.IngestionLimits{MaxMetadataKeys: 2, Truncate: true})
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	bodyBytes, _ := json.Marshal(map[string]interface{}{"logs": []map[string]interface{}{
+		{"message": "wide", "metadata": map[string]interface{}{"zone": "a", "user": "alice", "host": "web-1", "region": "eu"}},
+		{"message": "narrow", "metadata": map[string]interface{}{"user": "bob"}},
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/logs/batch", bytes.NewReader(bodyBytes))
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	var response handlers.BatchLogResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+	if response.Received != 2 || len(response.Rejected) != 0 {
+		t.Fatalf("Expected both entries stored, got %+v", response)
+	}
+
+	wide, _ := logRepo.GetByID(response.IDs[0])
+	// The first keys in sorted order stay; the rest are named in _dropped_keys
+	if len(wide.Metadata) != 3 || wide.Metadata["host"] != "web-1" || wide.Metadata["region"] != "eu" {
+		t.Errorf("Expected host and region kept, got %v", wide.Metadata)
+	}
+	dropped, _ := json.Marshal(wide.Metadata["_dropped_keys"])
+	if string(dropped) != `["user","zone"]` {
+		t.Errorf("Expected user and zone reported as dropped, got %s", dropped)
+	}
+
+	narrow, _ := logRepo.GetByID(response.IDs[1])
+	if _, ok := narrow.Metadata["_dropped_keys"]; ok || narrow.Metadata["user"] != "bob" {
+		t.Errorf("Expected metadata under the limit untouched, got %v", narrow.Metadata)
+	}
+}
+
+func TestLogHandler_CreateBatchLogs_BestEffort(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	// A row the database refuses, wherever it is in the batch
+	if _, err := db.Exec(`
+		CREATE TRIGGER reject_poison BEFORE INSERT ON logs
+		WHEN NEW.message = 'poison'
+		BEGIN SELECT RAISE(ABORT, 'poisoned row'); END
+	`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	post := func(target string, messages ...string) (int, handlers.BatchLogResponse) {
+		entries := make([]map[string]interface{}, len(messages))
+		for i, message := range messages {
+			entries[i] = map[string]interface{}{"message": message}
+		}
+		bodyBytes, _ := json.Marshal(map[string]interface{}{"logs": entries})
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var response handlers.BatchLogResponse
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return resp.StatusCode, response
+	}
+
+	// By default one bad row loses the whole batch
+	if status, _ := post("/logs/batch", "first", "poison", "third"); status != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", status)
+	}
+	if count, _ := logRepo.CountByProject(project.ID); count != 0 {
+		t.Fatalf("Expected nothing stored, got %d logs", count)
+	}
+
+	// The empty entry is skipped, so the failed index must still point at "poison"
+	status, response := post("/logs/batch?best_effort=true", "first", "", "poison", "fourth")
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+	if response.Received != 2 || len(response.IDs) != 2 {
+		t.Errorf("Expected 2 logs stored, got %+v", response)
+	}
+	if len(response.Failed) != 1 || response.Failed[0].Index != 2 {
+		t.Errorf("Expected entry 2 reported as failed, got %+v", response.Failed)
+	}
+	for _, id := range response.IDs {
+		if stored, _ := logRepo.GetByID(id); stored == nil || stored.Message == "poison" {
+			t.Errorf("Expected %s to be a stored good log, got %+v", id, stored)
+		}
+	}
+
+	// When every entry fails the request fails, still listing them
+	status, response = post("/logs/batch?best_effort=true", "poison")
+	if status != http.StatusInternalServerError || len(response.Failed) != 1 || response.Failed[0].Index != 0 {
+		t.Errorf("Expected 500 listing entry 0, got %d %+v", status, response)
+	}
+}
+
+func TestLogHandler_CreateLog_OversizedMetadataValue(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	// An SDK attaching a screenshot: well over the limit and plainly base64
+	screenshot := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 64))
+	newBody := func() map[string]interface{} {
+		return map[string]interface{}{
+			"message": "upload failed",
+			"metadata": map[string]interface{}{
+				"user":        "alice",
+				"attachments": []interface{}{"small", map[string]interface{}{"data": screenshot}},
+			},
+		}
+	}
+
+	tests := []struct {
+		policy     string
+		wantStatus int
+		wantAction string
+		check      func(t *testing.T, metadata map[string]interface{})
+	}{
+		{
+			policy:     handlers.MetadataValueReject,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			policy:     handlers.MetadataValueTruncate,
+			wantStatus: http.StatusCreated,
+			wantAction: "truncated",
+			check: func(t *testing.T, metadata map[string]interface{}) {
+				attachments := metadata["attachments"].([]interface{})
+				if data := attachments[1].(map[string]interface{})["data"]; data != screenshot[:32] {
+					t.Errorf("Expected the value cut to 32 bytes, got %v", data)
+				}
+			},
+		},
+		{
+			policy:     handlers.MetadataValueDrop,
+			wantStatus: http.StatusCreated,
+			wantAction: "dropped",
+			check: func(t *testing.T, metadata map[string]interface{}) {
+				attachments := metadata["attachments"].([]interface{})
+				if _, ok := attachments[1].(map[string]interface{})["data"]; ok {
+					t.Errorf("Expected the key dropped, got %v", attachments[1])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+			logHandler.SetIngestionLimits(handlers.IngestionLimits{MaxMetadataValueBytes: 32, MetadataValuePolicy: tt.policy})
+
+			app := fiber.New()
+			app.Use(apiKeyMiddleware.RequireAPIKey())
+			app.Post("/logs", logHandler.CreateLog)
+
+			bodyBytes, _ := json.Marshal(newBody())
+			req := httptest.NewRequest(http.MethodPost, "/logs", bytes.NewReader(bodyBytes))
+			req.Header.Set("X-API-Key", apiKey)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			var response map[string]interface{}
+			body, _ := io.ReadAll(resp.Body)
+			json.Unmarshal(body, &response)
+
+			if tt.check == nil {
+				if response["limit"] != "max_metadata_value_bytes" {
+					t.Errorf("Expected limit max_metadata_value_bytes, got %v", response["limit"])
+				}
+				if msg, _ := response["error"].(string); !strings.Contains(msg, `"attachments[1].data"`) || !strings.Contains(msg, "base64") {
+					t.Errorf("Expected the error to name the base64 value, got %q", msg)
+				}
+				return
+			}
+
+			log, _ := logRepo.GetByID(response["id"].(string))
+			if log.Metadata["user"] != "alice" {
+				t.Errorf("Expected values under the limit untouched, got %v", log.Metadata)
+			}
+			tt.check(t, log.Metadata)
+
+			report, _ := log.Metadata["_oversized_values"].([]interface{})
+			if len(report) != 1 {
+				t.Fatalf("Expected one oversized value reported, got %v", log.Metadata["_oversized_values"])
+			}
+			entry := report[0].(map[string]interface{})
+			if entry["key"] != "attachments[1].data" || entry["bytes"] != float64(len(screenshot)) ||
+				entry["base64"] != true || entry["action"] != tt.wantAction {
+				t.Errorf("Expected attachments[1].data reported as base64 and %s, got %v", tt.wantAction, entry)
+			}
+		})
+	}
+}
+
+func TestLogHandler_CreateLog_TimestampWindow(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	project := &models.Project{Name: "Test Project", IsActive: true}
+	apiKey, _ := projectRepo.Create(project)
+
+	now := time.Now().UTC()
+	future := now.Add(3 * 365 * 24 * time.Hour).Truncate(time.Second)
+	past := now.Add(-2 * 365 * 24 * time.Hour).Truncate(time.Second)
+	recent := now.Add(-time.Hour).Truncate(time.Second)
+	window := handlers.TimestampWindow{MaxFuture: time.Hour, MaxPast: 30 * 24 * time.Hour}
+	clamp := window
+	clamp.Clamp = true
+
+	tests := []struct {
+		name       string
+		window     handlers.TimestampWindow
+		timestamp  time.Time
+		wantStatus int
+		wantLimit  string
+		wantStored func(time.Time) bool
+		wantClamp  bool
+	}{
+		{"no window keeps the future", handlers.TimestampWindow{}, future, http.StatusCreated, "",
+			func(ts time.Time) bool { return ts.Equal(future) }, false},
+		{"inside the window", window, recent, http.StatusCreated, "",
+			func(ts time.Time) bool { return ts.Equal(recent) }, false},
+		{"reject future", window, future, http.StatusBadRequest, "max_timestamp_future", nil, false},
+		{"reject past", window, past, http.StatusBadRequest, "max_timestamp_past", nil, false},
+		{"clamp future", clamp, future, http.StatusCreated, "",
+			func(ts time.Time) bool { return ts.Sub(now) > 59*time.Minute && ts.Sub(now) < 61*time.Minute }, true},
+		{"clamp past", clamp, past, http.StatusCreated, "",
+			func(ts time.Time) bool {
+				d := now.Sub(ts) - 30*24*time.Hour
+				return d > -time.Minute && d < time.Minute
+			}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logHandler := handlers.NewLogHandler(logRepo, models.NewChannelRepository(db), models.NewUserProjectRepository(db), nil, nil, nil)
+			logHandler.SetTimestampWindow(tt.window)
+
+			app := fiber.New()
+			app.Use(apiKeyMiddleware.RequireAPIKey())
+			app.Post("/logs", logHandler.CreateLog)
+
+			bodyBytes, _ := json.Marshal(map[string]interface{}{
+				"message":   "clock skew",
+				"timestamp": tt.timestamp.Format(time.RFC3339),
+			})
+			req := httptest.NewRequest(http.MethodPost, "/logs", bytes.NewReader(bodyBytes))
+			req.Header.Set("X-API-Key", apiKey)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			var response map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&response)
+
+			if tt.wantLimit != "" {
+				if response["limit"] != tt.wantLimit {
+					t.Errorf("Expected limit %s, got %v", tt.wantLimit, response)
+				}
+				return
+			}
+
+			log, _ := logRepo.GetByID(response["id"].(string))
+			if !tt.wantStored(log.Timestamp) {
+				t.Errorf("Unexpected stored timestamp %s", log.Timestamp)
+			}
+			original, clamped := log.Metadata["_original_timestamp"]
+			if clamped != tt.wantClamp {
+				t.Fatalf("Expected clamped=%v, got metadata %v", tt.wantClamp, log.Metadata)
+			}
+			if clamped && original != tt.timestamp.Format(time.RFC3339) {
+				t.Errorf("Expected the original timestamp kept, got %v", original)
+			}
+		})
+	}
+
+	t.Run("batch rejects only the skewed entry", func(t *testing.T) {
+		logHandler := handlers.NewLogHandler(logRepo, models.NewChannelRepository(db), models.NewUserProjectRepository(db), nil, nil, nil)
+		logHandler.SetTimestampWindow(window)
+
+		app := fiber.New()
+		app.Use(apiKeyMiddleware.RequireAPIKey())
+		app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+		bodyBytes, _ := json.Marshal(map[string]interface{}{"logs": []map[string]interface{}{
+			{"message": "on time", "timestamp": recent.Format(time.RFC3339)},
+			{"message": "from the future", "timestamp": future.Format(time.RFC3339)},
+		}})
+		req := httptest.NewRequest(http.MethodPost, "/logs/batch", bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var response handlers.BatchLogResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		if resp.StatusCode != http.StatusCreated || response.Received != 1 || len(response.Rejected) != 1 {
+			t.Fatalf("Expected one log stored and one rejected, got %d %+v", resp.StatusCode, response)
+		}
+		if rejected := response.Rejected[0]; rejected.Index != 1 || rejected.Limit != "max_timestamp_future" {
+			t.Errorf("Expected entry 1 rejected for max_timestamp_future, got %+v", rejected)
+		}
+	})
+}
+
+func TestLogHandler_CreateLog_DefaultLevel(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+
+	project := &models.Project{
+		Name:         "Test Project",
+		IsActive:     true,
+		DefaultLevel: models.LogLevelWarn,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	post := func(path, body string) (int, []byte) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, respBody
+	}
+	storedLevel := func(body []byte) models.LogLevel {
+		var response handlers.CreateLogResponse
+		json.Unmarshal(body, &response)
+		log, _ := logRepo.GetByID(response.ID)
+		if log == nil {
+			t.Fatalf("Expected a stored log, got %s", body)
+		}
+		return log.Level
+	}
+
+	// The project's default replaces INFO for logs without a level
+	status, body := post("/logs", `{"message": "no level"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+	if level := storedLevel(body); level != models.LogLevelWarn {
+		t.Errorf("Expected the project default WARN, got %s", level)
+	}
+	_, body = post("/logs", `{"message": "explicit", "level": "DEBUG"}`)
+	if level := storedLevel(body); level != models.LogLevelDebug {
+		t.Errorf("Expected an explicit level to be kept, got %s", level)
+	}
+
+	// In strict mode a missing level is an error instead
+	project.RequireLevel = true
+	projectRepo.Update(project)
+
+	if status, _ := post("/logs", `{"message": "no level", "level": " "}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a missing level, got %d", status)
+	}
+	status, body = post("/logs", `{"message": "explicit", "level": "ERROR"}`)
+	if status != http.StatusCreated || storedLevel(body) != models.LogLevelError {
+		t.Errorf("Expected a log with a level to still be stored, got %d", status)
+	}
+
+	status, body = post("/logs/batch", `{"logs": [{"message": "with", "level": "INFO"}, {"message": "without"}]}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+	var batch handlers.BatchLogResponse
+	json.Unmarshal(body, &batch)
+	if batch.Received != 1 || len(batch.Rejected) != 1 || batch.Rejected[0].Index != 1 || batch.Rejected[0].Limit != "level_required" {
+		t.Errorf("Expected only the entry without a level rejected, got %+v", batch)
+	}
+}
+
+func TestLogHandler_CreateBatchLogs_RejectsOverLimitEntries(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetIngestionLimits(handlers.IngestionLimits{MaxMessageBytes: 16})
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	send := func(messages ...string) (*http.Response, handlers.BatchLogResponse) {
+		entries := make([]map[string]interface{}, len(messages))
+		for i, m := range messages {
+			entries[i] = map[string]interface{}{"level": "INFO", "message": m}
+		}
+		bodyBytes, _ := json.Marshal(map[string]interface{}{"logs": entries})
+		req := httptest.NewRequest(http.MethodPost, "/logs/batch", bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+
+		var response handlers.BatchLogResponse
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return resp, response
+	}
+
+	resp, response := send("ok", "this message is far too long", "also ok")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+	if response.Received != 2 {
+		t.Errorf("Expected 2 logs received, got %d", response.Received)
+	}
+	if len(response.Rejected) != 1 || response.Rejected[0].Index != 1 || response.Rejected[0].Limit != "max_message_bytes" {
+		t.Errorf("Expected entry 1 rejected for max_message_bytes, got %+v", response.Rejected)
+	}
+
+	// Nothing left to store once every entry is rejected
+	resp, response = send("this message is far too long")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+	if len(response.Rejected) != 1 {
+		t.Errorf("Expected 1 rejected entry, got %+v", response.Rejected)
+	}
+}
+
+// stubResolver answers GeoIP lookups from a fixed table
+type stubResolver struct {
+	locations map[string]*geoip.Location
+	err       error
+}
+
+func (r *stubResolver) Lookup(ip net.IP) (*geoip.Location, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.locations[ip.String()], nil
+}
+
+func TestLogHandler_GeoIPEnrichment(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	resolver := &stubResolver{locations: map[string]*geoip.Location{
+		"203.0.113.7": {Country: "GB", CountryName: "United Kingdom", City: "London", ASN: 64500, ASOrg: "Example Transit"},
+	}}
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetGeoIPEnricher(handlers.NewGeoIPEnricher(resolver, "request.client_ip"))
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	post := func(path string, payload interface{}) []byte {
+		bodyBytes, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return body
+	}
+	withIP := func(ip string) map[string]interface{} {
+		return map[string]interface{}{"request": map[string]interface{}{"client_ip": ip}}
+	}
+
+	var created handlers.CreateLogResponse
+	json.Unmarshal(post("/logs", map[string]interface{}{
+		"message":  "login failed",
+		"metadata": withIP("203.0.113.7:52100"),
+	}), &created)
+
+	log, _ := logRepo.GetByID(created.ID)
+	geo, ok := log.Metadata["geo"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected geo metadata, got %v", log.Metadata)
+	}
+	if geo["country"] != "GB" || geo["city"] != "London" || geo["asn"] != float64(64500) || geo["as_org"] != "Example Transit" {
+		t.Errorf("Unexpected geo metadata %v", geo)
+	}
+
+	// Unknown, private and missing addresses pass through untouched
+	var batch handlers.BatchLogResponse
+	json.Unmarshal(post("/logs/batch", map[string]interface{}{
+		"logs": []map[string]interface{}{
+			{"message": "known", "metadata": withIP("203.0.113.7, 10.0.0.1")},
+			{"message": "unknown", "metadata": withIP("198.51.100.1")},
+			{"message": "no ip", "metadata": map[string]interface{}{"user": "alice"}},
+		},
+	}), &batch)
+
+	if len(batch.IDs) != 3 {
+		t.Fatalf("Expected 3 logs, got %+v", batch)
+	}
+	for i, id := range batch.IDs {
+		log, _ := logRepo.GetByID(id)
+		_, hasGeo := log.Metadata["geo"]
+		if hasGeo != (i == 0) {
+			t.Errorf("Log %q: geo present = %v, metadata %v", log.Message, hasGeo, log.Metadata)
+		}
+	}
+
+	// A failing lookup stores the log without geo fields
+	resolver.err = errors.New("database unavailable")
+	json.Unmarshal(post("/logs", map[string]interface{}{
+		"message":  "lookup fails",
+		"metadata": withIP("203.0.113.7"),
+	}), &created)
+
+	log, _ = logRepo.GetByID(created.ID)
+	if log == nil {
+		t.Fatal("Expected the log to be stored when the lookup fails")
+	}
+	if _, hasGeo := log.Metadata["geo"]; hasGeo {
+		t.Errorf("Expected no geo metadata after a failed lookup, got %v", log.Metadata)
+	}
+}
+
+func TestLogHandler_RedactsBeforeStorage(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	global, err := redaction.Compile([]string{"email", "credit_card"}, nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetRedactor(handlers.NewRedactor(global))
+
+	project := &models.Project{
+		Name:     "Default Rules",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	override := &models.Project{
+		Name:            "Own Rules",
+		IsActive:        true,
+		RedactionConfig: &models.RedactionConfig{Patterns: []string{`acct-\d+`}},
+	}
+	overrideKey, _ := projectRepo.Create(override)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	post := func(key, path string, payload interface{}) {
+		bodyBytes, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", key)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+		}
+	}
+
+	post(apiKey, "/logs", map[string]interface{}{
+		"message": "charge to 4111 1111 1111 1111 failed for bob@example.com",
+		"metadata": map[string]interface{}{
+			"customer": map[string]interface{}{"email": "bob@example.com"},
+			"cards":    []interface{}{"5500-0000-0000-0004"},
+		},
+	})
+	post(apiKey, "/logs/batch", map[string]interface{}{
+		"logs": []map[string]interface{}{
+			{"message": "reset link sent to carol@example.org"},
+		},
+	})
+	post(overrideKey, "/logs", map[string]interface{}{
+		"message":  "acct-12345 updated by dave@example.com",
+		"metadata": map[string]interface{}{"account": "acct-999"},
+	})
+
+	// Read the raw rows so nothing between the database and the test could mask a leak
+	rows, err := db.Query("SELECT message, COALESCE(metadata, '') FROM logs")
+	if err != nil {
+		t.Fatalf("Failed to query logs: %v", err)
+	}
+	defer rows.Close()
+
+	var stored []string
+	for rows.Next() {
+		var message, metadata string
+		rows.Scan(&message, &metadata)
+		stored = append(stored, message+" "+metadata)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("Expected 3 stored logs, got %d", len(stored))
+	}
+
+	all := strings.Join(stored, "\n")
+	for _, secret := range []string{"4111 1111 1111 1111", "5500-0000-0000-0004", "bob@example.com", "carol@example.org", "acct-12345", "acct-999"} {
+		if strings.Contains(all, secret) {
+			t.Errorf("Sensitive value %q reached the database:\n%s", secret, all)
+		}
+	}
+	// The override replaces the server-wide rules, so emails pass for that project
+	if !strings.Contains(all, "[REDACTED] updated by dave@example.com") {
+		t.Errorf("Expected the project's own rules to apply, got:\n%s", all)
+	}
+}
+
+func TestLogHandler_SamplesByLevel(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	const seed = 42
+	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
+	logHandler.SetSampler(handlers.NewSampler(rand.NewSource(seed)))
+
+	project := &models.Project{
+		Name:     "Chatty Service",
+		IsActive: true,
+		SamplingConfig: &models.SamplingConfig{Levels: map[string]float64{
+			"DEBUG": 0.1,
+			"INFO":  0,
+			"ERROR": 1,
+		}},
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", logHandler.CreateLog)
+	app.Post("/logs/batch", logHandler.CreateBatchLogs)
+
+	post := func(path string, payload interface{}) (int, []byte) {
+		bodyBytes, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, body
+	}
+
+	// A level sampled at 0 is never stored
+	status, body := post("/logs", map[string]interface{}{"level": "INFO", "message": "heartbeat"})
+	if status != http.StatusAccepted || !strings.Contains(string(body), `"status":"sampled"`) {
+		t.Errorf("Expected 202 sampled, got %d %s", status, body)
+	}
+
+	// Only DEBUG entries draw from the RNG, so the same seed predicts which survive
+	expected := rand.New(rand.NewSource(seed))
+	wantDebug := 0
+	entries := make([]map[string]interface{}, 0, 100)
+	for i := 0; i < 90; i++ {
+		entries = append(entries, map[string]interface{}{"level": "DEBUG", "message": "cache hit"})
+		if expected.Float64() < 0.1 {
+			wantDebug++
+		}
+	}
+	for i := 0; i < 10; i++ {
+		entries = append(entries, map[string]interface{}{"level": "ERROR", "message": "payment failed"})
+	}
+
+	status, body = post("/logs/batch", map[string]interface{}{"logs": entries})
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d %s", status, body)
+	}
+	var batch handlers.BatchLogResponse
+	json.Unmarshal(body, &batch)
+
+	if batch.Received != wantDebug+10 || batch.Sampled != 90-wantDebug {
+		t.Errorf("Expected %d received and %d sampled, got %+v", wantDebug+10, 90-wantDebug, batch)
+	}
+	if wantDebug == 0 || wantDebug > 30 {
+		t.Errorf("Seed %d kept %d of 90 DEBUG logs; expected roughly 10%%", seed, wantDebug)
+	}
+
+	var debugCount, errorCount, infoCount int
+	db.QueryRow("SELECT COUNT(*) FROM logs WHERE level = 'DEBUG'").Scan(&debugCount)
+	db.QueryRow("SELECT COUNT(*) FROM logs WHERE level = 'ERROR'").Scan(&errorCount)
+	db.QueryRow("SELECT COUNT(*) FROM logs WHERE level = 'INFO'").Scan(&infoCount)
+	if debugCount != wantDebug || errorCount != 10 || infoCount != 0 {
+		t.Errorf("Stored DEBUG=%d ERROR=%d INFO=%d, want %d, 10, 0", debugCount, errorCount, infoCount, wantDebug)
+	}
+
+	// A batch that is entirely sampled out is still accepted
+	status, body = post("/logs/batch", map[string]interface{}{
+		"logs": []map[string]interface{}{{"level": "INFO", "message": "heartbeat"}},
+	})
+	if status != http.StatusAccepted || !strings.Contains(string(body), `"sampled":1`) {
+		t.Errorf("Expected 202 with one sampled entry, got %d %s", status, body)
+	}
+}
@@ -43,9 +43,10 @@ func (h *MCPTokenHandler) ListTokens(c *fiber.Ctx) error {
 }
 
 type CreateTokenRequest struct {
-	Name            string   `json:"name"`
-	GrantedProjects []string `json:"granted_projects"` // Array of project IDs or ["*"] for all
-	ExpiresInDays   *int     `json:"expires_in_days"`  // Optional, null for permanent
+	Name            string          `json:"name"`
+	GrantedProjects []string        `json:"granted_projects"` // Array of project IDs or ["*"] for all
+	Scope           models.MCPScope `json:"scope"`            // read (default) or write
+	ExpiresInDays   *int            `json:"expires_in_days"`  // Optional, null for permanent
 }
 
 type CreateTokenResponse struct {
@@ -83,6 +84,15 @@ func (h *MCPTokenHandler) CreateToken(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.Scope == "" {
+		req.Scope = models.MCPScopeRead
+	}
+	if !models.IsValidMCPScope(req.Scope) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Scope must be read or write",
+		})
+	}
+
 	// Build granted_projects JSON
 	var grantedProjectsJSON string
 	if len(req.GrantedProjects) == 1 && req.GrantedProjects[0] == "*" {
@@ -124,6 +134,7 @@ func (h *MCPTokenHandler) CreateToken(c *fiber.Ctx) error {
 	token := &models.MCPToken{
 		Name:            req.Name,
 		GrantedProjects: grantedProjectsJSON,
+		Scope:           req.Scope,
 		ExpiresAt:       expiresAt,
 		IsActive:        true,
 		CreatedBy:       user.ID,
@@ -163,10 +174,11 @@ func (h *MCPTokenHandler) GetToken(c *fiber.Ctx) error {
 }
 
 type UpdateTokenRequest struct {
-	Name            *string  `json:"name"`
-	GrantedProjects []string `json:"granted_projects"`
-	ExpiresInDays   *int     `json:"expires_in_days"` // null to keep current, 0 for permanent, >0 for days from now
-	IsActive        *bool    `json:"is_active"`
+	Name            *string          `json:"name"`
+	GrantedProjects []string         `json:"granted_projects"`
+	Scope           *models.MCPScope `json:"scope"`
+	ExpiresInDays   *int             `json:"expires_in_days"` // null to keep current, 0 for permanent, >0 for days from now
+	IsActive        *bool            `json:"is_active"`
 }
 
 // UpdateToken handles PUT /api/admin/mcp/tokens/:id
@@ -234,6 +246,15 @@ func (h *MCPTokenHandler) UpdateToken(c *fiber.Ctx) error {
 		token.GrantedProjects = grantedProjectsJSON
 	}
 
+	if req.Scope != nil {
+		if !models.IsValidMCPScope(*req.Scope) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Scope must be read or write",
+			})
+		}
+		token.Scope = *req.Scope
+	}
+
 	if req.ExpiresInDays != nil {
 		if *req.ExpiresInDays == 0 {
 			// 0 means permanent (null)
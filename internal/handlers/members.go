@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"fmt"
+
+	"central-logs/internal/middleware"
 	"central-logs/internal/models"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,6 +12,7 @@ import (
 type MemberHandler struct {
 	userRepo        *models.UserRepository
 	userProjectRepo *models.UserProjectRepository
+	audit           *AuditRecorder
 }
 
 func NewMemberHandler(
@@ -21,6 +25,11 @@ func NewMemberHandler(
 	}
 }
 
+// SetAuditRecorder records membership changes to the audit trail
+func (h *MemberHandler) SetAuditRecorder(audit *AuditRecorder) {
+	h.audit = audit
+}
+
 // ListMembers handles GET /api/admin/projects/:id/members
 func (h *MemberHandler) ListMembers(c *fiber.Ctx) error {
 	projectID := c.Params("id")
@@ -32,8 +41,31 @@ func (h *MemberHandler) ListMembers(c *fiber.Ctx) error {
 		})
 	}
 
+	// Admins are treated as owners; other viewers see a redacted list
+	var viewerRole models.ProjectRole
+	if user := middleware.GetUser(c); user != nil {
+		if user.Role == models.RoleAdmin {
+			viewerRole = models.ProjectRoleOwner
+		} else {
+			membership, err := h.userProjectRepo.GetByUserAndProject(user.ID, projectID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to list members",
+				})
+			}
+			if membership != nil {
+				viewerRole = membership.Role
+			}
+		}
+	}
+
+	views := make([]interface{}, 0, len(members))
+	for _, m := range members {
+		views = append(views, m.ToMemberView(viewerRole))
+	}
+
 	return c.JSON(fiber.Map{
-		"members": members,
+		"members": views,
 	})
 }
 
@@ -106,6 +138,8 @@ func (h *MemberHandler) AddMember(c *fiber.Ctx) error {
 		})
 	}
 
+	h.audit.Record(c, models.AuditMemberAdd, "project", projectID, fmt.Sprintf("user=%s role=%s", user.ID, req.Role))
+
 	userProject.User = user
 	return c.Status(fiber.StatusCreated).JSON(userProject)
 }
@@ -147,6 +181,8 @@ func (h *MemberHandler) UpdateMember(c *fiber.Ctx) error {
 		})
 	}
 
+	h.audit.Record(c, models.AuditMemberRoleChange, "project", projectID, fmt.Sprintf("user=%s role=%s->%s", userID, existing.Role, req.Role))
+
 	return c.JSON(fiber.Map{
 		"message": "Member updated",
 		"role":    req.Role,
@@ -172,6 +208,8 @@ func (h *MemberHandler) RemoveMember(c *fiber.Ctx) error {
 		})
 	}
 
+	h.audit.Record(c, models.AuditMemberRemove, "project", projectID, "user="+userID)
+
 	return c.JSON(fiber.Map{
 		"message": "Member removed",
 	})
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"central-logs/internal/handlers"
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+	"central-logs/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMemberHandler_ListMembers_FieldVisibility(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	memberHandler := handlers.NewMemberHandler(userRepo, userProjectRepo)
+
+	project := &models.Project{Name: "Shared", IsActive: true}
+	projectRepo.Create(project)
+
+	owner := &models.User{Username: "owner", Email: "owner@example.com", Password: "password123", Name: "Owner", Role: models.RoleUser, IsActive: true}
+	member := &models.User{Username: "member", Email: "member@example.com", Password: "password123", Name: "Member", Role: models.RoleUser, IsActive: true}
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Password: "password123", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	userRepo.Create(owner)
+	userRepo.Create(member)
+	userRepo.Create(admin)
+
+	userProjectRepo.Create(&models.UserProject{UserID: owner.ID, ProjectID: project.ID, Role: models.ProjectRoleOwner})
+	userProjectRepo.Create(&models.UserProject{UserID: member.ID, ProjectID: project.ID, Role: models.ProjectRoleMember})
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/projects/:id/members", memberHandler.ListMembers)
+
+	list := func(user *models.User) []map[string]interface{} {
+		t.Helper()
+		token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+		req := httptest.NewRequest(http.MethodGet, "/projects/"+project.ID+"/members", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var response struct {
+			Members []map[string]interface{} `json:"members"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		if len(response.Members) != 2 {
+			t.Fatalf("Expected 2 members, got %d", len(response.Members))
+		}
+		return response.Members
+	}
+
+	t.Run("member sees redacted shape", func(t *testing.T) {
+		for _, m := range list(member) {
+			for _, key := range []string{"user", "user_id", "email", "two_factor_enabled"} {
+				if _, ok := m[key]; ok {
+					t.Errorf("Expected %q to be hidden, got %v", key, m)
+				}
+			}
+			if m["name"] == "" || m["role"] == "" {
+				t.Errorf("Expected name and role, got %v", m)
+			}
+		}
+
+		m := list(member)[0]
+		if m["name"] != "Owner" || m["identifier"] != "o***@example.com" {
+			t.Errorf("Expected masked owner entry, got %v", m)
+		}
+	})
+
+	for _, viewer := range []*models.User{owner, admin} {
+		t.Run(viewer.Username+" sees full details", func(t *testing.T) {
+			for _, m := range list(viewer) {
+				user, ok := m["user"].(map[string]interface{})
+				if !ok {
+					t.Fatalf("Expected nested user, got %v", m)
+				}
+				if user["email"] == "" || user["email"] == nil {
+					t.Errorf("Expected email to be visible, got %v", user)
+				}
+			}
+		})
+	}
+}
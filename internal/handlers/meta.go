@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MetaHandler serves the server's reference data, so the dashboard and CLI
+// tools don't have to hardcode it
+type MetaHandler struct{}
+
+func NewMetaHandler() *MetaHandler {
+	return &MetaHandler{}
+}
+
+type LevelsResponse struct {
+	Levels []models.LevelInfo `json:"levels"` // Ascending priority
+}
+
+// ListLevels handles GET /api/meta/levels
+func (h *MetaHandler) ListLevels(c *fiber.Ctx) error {
+	return c.JSON(LevelsResponse{Levels: models.AllLevelInfo()})
+}
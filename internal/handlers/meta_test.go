@@ -0,0 +1,45 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"central-logs/internal/handlers"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMetaHandler_ListLevels(t *testing.T) {
+	app := fiber.New()
+	app.Get("/meta/levels", handlers.NewMetaHandler().ListLevels)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/meta/levels", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result handlers.LevelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(result.Levels) != len(models.AllLogLevels) {
+		t.Fatalf("Expected %d levels, got %d", len(models.AllLogLevels), len(result.Levels))
+	}
+	for i, info := range result.Levels {
+		if info.Priority != info.Level.Priority() {
+			t.Errorf("%s: priority %d does not match Priority() %d", info.Level, info.Priority, info.Level.Priority())
+		}
+		if i > 0 && info.Level.Priority() <= result.Levels[i-1].Level.Priority() {
+			t.Errorf("Expected ascending priority, got %s after %s", info.Level, result.Levels[i-1].Level)
+		}
+		if info.Label == "" || info.Color == "" || info.TextColor == "" {
+			t.Errorf("%s: expected a label and colors, got %+v", info.Level, info)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationFailureHandler struct {
+	failedRepo      *models.FailedNotificationRepository
+	channelRepo     *models.ChannelRepository
+	userProjectRepo *models.UserProjectRepository
+	redisClient     *queue.RedisClient
+}
+
+func NewNotificationFailureHandler(
+	failedRepo *models.FailedNotificationRepository,
+	channelRepo *models.ChannelRepository,
+	userProjectRepo *models.UserProjectRepository,
+	redisClient *queue.RedisClient,
+) *NotificationFailureHandler {
+	return &NotificationFailureHandler{
+		failedRepo:      failedRepo,
+		channelRepo:     channelRepo,
+		userProjectRepo: userProjectRepo,
+		redisClient:     redisClient,
+	}
+}
+
+// ListFailures handles GET /api/admin/channels/:id/failures
+func (h *NotificationFailureHandler) ListFailures(c *fiber.Ctx) error {
+	channel, err := h.authorizedChannel(c)
+	if channel == nil {
+		return err
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	failures, total, err := h.failedRepo.GetByChannelID(channel.ID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list failed notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"failures": failures,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// ReplayFailure handles POST /api/admin/channels/:id/failures/:failureId/replay
+func (h *NotificationFailureHandler) ReplayFailure(c *fiber.Ctx) error {
+	channel, err := h.authorizedChannel(c)
+	if channel == nil {
+		return err
+	}
+
+	if h.redisClient == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Notification queue is not available",
+		})
+	}
+
+	failure, err := h.failedRepo.GetByID(c.Params("failureId"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get failed notification",
+		})
+	}
+	if failure == nil || failure.ChannelID != channel.ID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Failed notification not found",
+		})
+	}
+
+	var job queue.NotificationJob
+	if err := json.Unmarshal(failure.Payload, &job); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Stored job is corrupted",
+		})
+	}
+
+	// A replay starts with a fresh retry budget
+	job.Attempts = 0
+	job.LastError = ""
+
+	if err := h.redisClient.EnqueueNotification(context.Background(), &job); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to enqueue notification",
+		})
+	}
+
+	if err := h.failedRepo.MarkReplayed(failure.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to mark notification as replayed",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Notification re-queued",
+	})
+}
+
+// authorizedChannel loads the channel from the route and checks that the user is
+// an admin or an owner/member of its project. A nil channel means a response was sent.
+func (h *NotificationFailureHandler) authorizedChannel(c *fiber.Ctx) (*models.Channel, error) {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	channel, err := h.channelRepo.GetByID(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get channel",
+		})
+	}
+	if channel == nil {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Channel not found",
+		})
+	}
+
+	if !user.IsAdmin() {
+		hasRole, err := h.userProjectRepo.HasRole(user.ID, channel.ProjectID, models.ProjectRoleOwner, models.ProjectRoleMember)
+		if err != nil {
+			return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check role",
+			})
+		}
+		if !hasRole {
+			if middleware.ObscuresNotFound() {
+				if member, err := h.userProjectRepo.HasAccess(user.ID, channel.ProjectID); err == nil && !member {
+					return nil, middleware.DenyAccess(c, "Insufficient permissions", "Channel not found")
+				}
+			}
+			return nil, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient permissions",
+			})
+		}
+	}
+
+	return channel, nil
+}
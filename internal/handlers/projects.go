@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"central-logs/internal/config"
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
+	"central-logs/internal/services/redaction"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,6 +18,11 @@ type ProjectHandler struct {
 	projectRepo     *models.ProjectRepository
 	userProjectRepo *models.UserProjectRepository
 	logRepo         *models.LogRepository
+	pinnedRepo      *models.PinnedProjectRepository
+	channelRepo     *models.ChannelRepository
+	audit           *AuditRecorder
+	maxBodyLimit    int // Ceiling for a project's max_body_bytes; 0 means none
+	retentionFloor  func() config.RetentionFloor
 }
 
 func NewProjectHandler(
@@ -25,11 +37,86 @@ func NewProjectHandler(
 	}
 }
 
+// SetAuditRecorder records project deletion and credential changes to the audit trail
+func (h *ProjectHandler) SetAuditRecorder(audit *AuditRecorder) {
+	h.audit = audit
+}
+
+// SetPinnedProjects lets users pin projects and marks pinned ones in the list
+func (h *ProjectHandler) SetPinnedProjects(pinnedRepo *models.PinnedProjectRepository) {
+	h.pinnedRepo = pinnedRepo
+}
+
+// SetChannelRepository lets cloned projects take a copy of the source's channels
+func (h *ProjectHandler) SetChannelRepository(channelRepo *models.ChannelRepository) {
+	h.channelRepo = channelRepo
+}
+
+// SetMaxBodyLimit caps the ingestion body limit a project may be given. The
+// server refuses larger bodies before any project is looked up.
+func (h *ProjectHandler) SetMaxBodyLimit(limit int) {
+	h.maxBodyLimit = limit
+}
+
+// SetRetentionFloor sets where the least a project's retention may keep is
+// read from, on every update so a config reload applies
+func (h *ProjectHandler) SetRetentionFloor(floor func() config.RetentionFloor) {
+	h.retentionFloor = floor
+}
+
+// checkRetention rejects a malformed retention config, or one that keeps
+// less than the floor unless force is set
+func (h *ProjectHandler) checkRetention(rc *models.RetentionConfig, force bool) error {
+	var floor config.RetentionFloor
+	if h.retentionFloor != nil {
+		floor = h.retentionFloor()
+	}
+	check := func(path, maxAge string, maxCount int) error {
+		err := floor.Check(path, maxAge, maxCount)
+		if errors.Is(err, config.ErrBelowRetentionFloor) {
+			if force {
+				return nil
+			}
+			return fmt.Errorf(`%w; send "force": true to apply it anyway`, err)
+		}
+		return err
+	}
+
+	if err := check("retention_config", rc.MaxAge, rc.MaxCount); err != nil {
+		return err
+	}
+	levels := make([]string, 0, len(rc.Levels))
+	for level := range rc.Levels {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	for _, level := range levels {
+		policy := rc.Levels[level]
+		if err := check("retention_config.levels."+level, policy.MaxAge, policy.MaxCount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type CreateProjectRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	IconType    string `json:"icon_type"`
 	IconValue   string `json:"icon_value"`
+	Group       string `json:"group"`
+}
+
+// maxGroupLength caps project group names
+const maxGroupLength = 64
+
+// normalizeGroup trims a group name and checks its length
+func normalizeGroup(group string) (string, error) {
+	group = strings.TrimSpace(group)
+	if len(group) > maxGroupLength {
+		return "", fmt.Errorf("group must be at most %d characters", maxGroupLength)
+	}
+	return group, nil
 }
 
 type CreateProjectResponse struct {
@@ -37,7 +124,14 @@ type CreateProjectResponse struct {
 	APIKey  string          `json:"api_key"`
 }
 
-// ListProjects handles GET /api/admin/projects
+// ProjectListItem is a project as listed to a user, with that user's pin
+type ProjectListItem struct {
+	*models.Project
+	Pinned bool `json:"pinned"`
+}
+
+// ListProjects handles GET /api/admin/projects. Pass group to only list the
+// projects in that group, and pinned=true for only the ones the user pinned.
 func (h *ProjectHandler) ListProjects(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
 	if user == nil {
@@ -61,8 +155,128 @@ func (h *ProjectHandler) ListProjects(c *fiber.Ctx) error {
 		})
 	}
 
+	if group := strings.TrimSpace(c.Query("group")); group != "" {
+		filtered := []*models.Project{}
+		for _, project := range projects {
+			if project.Group == group {
+				filtered = append(filtered, project)
+			}
+		}
+		projects = filtered
+	}
+
+	pinned := map[string]bool{}
+	if h.pinnedRepo != nil {
+		pinned, err = h.pinnedRepo.GetProjectIDs(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to list projects",
+			})
+		}
+	}
+	pinnedOnly := c.QueryBool("pinned")
+
+	items := make([]ProjectListItem, 0, len(projects))
+	for _, project := range projects {
+		if pinnedOnly && !pinned[project.ID] {
+			continue
+		}
+		items = append(items, ProjectListItem{Project: project, Pinned: pinned[project.ID]})
+	}
+
+	return c.JSON(fiber.Map{
+		"projects": items,
+	})
+}
+
+// PinProject handles POST /api/admin/projects/:id/pin
+func (h *ProjectHandler) PinProject(c *fiber.Ctx) error {
+	return h.setPinned(c, true)
+}
+
+// UnpinProject handles DELETE /api/admin/projects/:id/pin
+func (h *ProjectHandler) UnpinProject(c *fiber.Ctx) error {
+	return h.setPinned(c, false)
+}
+
+// setPinned pins or unpins a project for the current user. Both are
+// idempotent; access to the project is checked by the route.
+func (h *ProjectHandler) setPinned(c *fiber.Ctx, pin bool) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+	if h.pinnedRepo == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Pinning projects is not available",
+		})
+	}
+
+	projectID := c.Params("id")
+	project, err := h.projectRepo.GetByID(projectID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+	if project == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	if pin {
+		err = h.pinnedRepo.Pin(user.ID, projectID)
+	} else {
+		_, err = h.pinnedRepo.Unpin(user.ID, projectID)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update pin",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"project_id": projectID,
+		"pinned":     pin,
+	})
+}
+
+// ListGroups handles GET /api/admin/projects/groups. Regular users only see
+// groups among the projects they belong to.
+func (h *ProjectHandler) ListGroups(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var projectIDs []string
+	if !user.IsAdmin() {
+		var err error
+		projectIDs, err = h.userProjectRepo.GetUserProjectIDs(user.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get projects",
+			})
+		}
+		if projectIDs == nil {
+			projectIDs = []string{}
+		}
+	}
+
+	groups, err := h.projectRepo.CountByGroup(projectIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list groups",
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"projects": projects,
+		"groups": groups,
 	})
 }
 
@@ -88,6 +302,13 @@ func (h *ProjectHandler) CreateProject(c *fiber.Ctx) error {
 		})
 	}
 
+	group, err := normalizeGroup(req.Group)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Default to initials if no icon type specified
 	iconType := req.IconType
 	iconValue := req.IconValue
@@ -100,6 +321,7 @@ func (h *ProjectHandler) CreateProject(c *fiber.Ctx) error {
 		Description: req.Description,
 		IconType:    iconType,
 		IconValue:   iconValue,
+		Group:       group,
 		IsActive:    true,
 	}
 
@@ -130,6 +352,136 @@ func (h *ProjectHandler) CreateProject(c *fiber.Ctx) error {
 	})
 }
 
+// CloneProjectRequest names the copy; it defaults to the source's name
+// followed by "(copy)"
+type CloneProjectRequest struct {
+	Name string `json:"name"`
+}
+
+// CloneProjectResponse is the copy with its new API key and channels
+type CloneProjectResponse struct {
+	Project  *models.Project   `json:"project"`
+	APIKey   string            `json:"api_key"`
+	Channels []*models.Channel `json:"channels"`
+}
+
+// CloneProject handles POST /api/admin/projects/:id/clone. The new project
+// gets the source's settings and notification channels, a fresh API key and
+// the requester as its only owner. Logs and members stay behind, and so does
+// the signing secret, which the new owner enables for themselves.
+func (h *ProjectHandler) CloneProject(c *fiber.Ctx) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req CloneProjectRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	source, err := h.projectRepo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+	if source == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	var channels []*models.Channel
+	if h.channelRepo != nil {
+		channels, err = h.channelRepo.GetByProjectID(source.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get channels",
+			})
+		}
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+	project := &models.Project{
+		Name:               name,
+		Description:        source.Description,
+		IconType:           source.IconType,
+		IconValue:          source.IconValue,
+		Group:              source.Group,
+		IsActive:           true,
+		RetentionConfig:    source.RetentionConfig,
+		RedactionConfig:    source.RedactionConfig,
+		SamplingConfig:     source.SamplingConfig,
+		OutboundWebhook:    source.OutboundWebhook,
+		MaxBodyBytes:       source.MaxBodyBytes,
+		RateLimitPerMinute: source.RateLimitPerMinute,
+		RateLimitBurst:     source.RateLimitBurst,
+		DefaultLevel:       source.DefaultLevel,
+		RequireLevel:       source.RequireLevel,
+	}
+
+	apiKey, err := h.projectRepo.Create(project)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create project",
+		})
+	}
+
+	// Deleting the project takes the channels and membership added so far
+	// with it
+	rollback := func(message string) error {
+		h.projectRepo.Delete(project.ID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+
+	if err := h.userProjectRepo.Create(&models.UserProject{
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Role:      models.ProjectRoleOwner,
+	}); err != nil {
+		return rollback("Failed to assign owner")
+	}
+
+	copies := make([]*models.Channel, 0, len(channels))
+	for _, channel := range channels {
+		clone := &models.Channel{
+			ProjectID: project.ID,
+			Type:      channel.Type,
+			Name:      channel.Name,
+			Config:    channel.Config,
+			MinLevel:  channel.MinLevel,
+			IsActive:  channel.IsActive,
+		}
+		if err := h.channelRepo.Create(clone); err != nil {
+			return rollback("Failed to copy channels")
+		}
+		// The copies hold the same credentials; show them like the health view does
+		shown := *clone
+		shown.Config = redactChannelConfig(clone.Config)
+		copies = append(copies, &shown)
+	}
+
+	h.audit.Record(c, models.AuditProjectClone, "project", project.ID, "from="+source.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(CloneProjectResponse{
+		Project:  project,
+		APIKey:   apiKey,
+		Channels: copies,
+	})
+}
+
 // GetProject handles GET /api/admin/projects/:id
 func (h *ProjectHandler) GetProject(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
@@ -157,9 +509,7 @@ func (h *ProjectHandler) GetProject(c *fiber.Ctx) error {
 	if !user.IsAdmin() {
 		hasAccess, _ := h.userProjectRepo.HasAccess(user.ID, projectID)
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Access denied",
-			})
+			return middleware.DenyAccess(c, "Access denied", "Project not found")
 		}
 	}
 
@@ -177,8 +527,29 @@ type UpdateProjectRequest struct {
 	Description     string                  `json:"description"`
 	IconType        string                  `json:"icon_type"`
 	IconValue       string                  `json:"icon_value"`
+	Group           *string                 `json:"group"` // An empty string removes the project from its group
 	IsActive        *bool                   `json:"is_active"`
 	RetentionConfig *models.RetentionConfig `json:"retention_config"`
+	// Applies a retention_config that keeps less than the server's
+	// retention floor
+	Force bool `json:"force"`
+	// An object without detectors or patterns clears the override
+	RedactionConfig *models.RedactionConfig `json:"redaction_config"`
+	// Rates per level; an object without levels turns sampling off
+	SamplingConfig *models.SamplingConfig `json:"sampling_config"`
+	// Ingestion body limit in bytes for this project; 0 restores the
+	// server default. Admins only.
+	MaxBodyBytes *int `json:"max_body_bytes"`
+	// Ingestion rate for this project; 0 restores the server default.
+	// Admins only.
+	RateLimitPerMinute *int `json:"rate_limit_per_minute"`
+	RateLimitBurst     *int `json:"rate_limit_burst"`
+	// Level for logs sent without one; an empty string restores INFO
+	DefaultLevel *string `json:"default_level"`
+	// Reject logs sent without a level instead of defaulting them
+	RequireLevel *bool `json:"require_level"`
+	// Posts accepted logs to a URL; an empty url turns it off
+	OutboundWebhook *models.OutboundWebhook `json:"outbound_webhook"`
 }
 
 // UpdateProject handles PUT /api/admin/projects/:id
@@ -215,12 +586,119 @@ func (h *ProjectHandler) UpdateProject(c *fiber.Ctx) error {
 	}
 	// Allow empty icon_value (for initials mode)
 	project.IconValue = req.IconValue
+	if req.Group != nil {
+		group, err := normalizeGroup(*req.Group)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		project.Group = group
+	}
 	if req.IsActive != nil {
 		project.IsActive = *req.IsActive
 	}
 	if req.RetentionConfig != nil {
+		if err := h.checkRetention(req.RetentionConfig, req.Force); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		project.RetentionConfig = req.RetentionConfig
 	}
+	if req.RedactionConfig != nil {
+		if req.RedactionConfig.IsEmpty() {
+			project.RedactionConfig = nil
+		} else {
+			if _, err := redaction.Compile(req.RedactionConfig.Detectors, req.RedactionConfig.Patterns); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			project.RedactionConfig = req.RedactionConfig
+		}
+	}
+	if req.SamplingConfig != nil {
+		if err := req.SamplingConfig.Normalize(); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if len(req.SamplingConfig.Levels) == 0 {
+			project.SamplingConfig = nil
+		} else {
+			project.SamplingConfig = req.SamplingConfig
+		}
+	}
+
+	if req.MaxBodyBytes != nil && *req.MaxBodyBytes != project.MaxBodyBytes {
+		if user := middleware.GetUser(c); user == nil || !user.IsAdmin() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only admins can change a project's body limit",
+			})
+		}
+		if *req.MaxBodyBytes < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "max_body_bytes must not be negative",
+			})
+		}
+		if h.maxBodyLimit > 0 && *req.MaxBodyBytes > h.maxBodyLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("max_body_bytes must not exceed the server limit of %d bytes", h.maxBodyLimit),
+			})
+		}
+		project.MaxBodyBytes = *req.MaxBodyBytes
+	}
+
+	rateLimits := []struct {
+		name    string
+		value   *int
+		current *int
+	}{
+		{"rate_limit_per_minute", req.RateLimitPerMinute, &project.RateLimitPerMinute},
+		{"rate_limit_burst", req.RateLimitBurst, &project.RateLimitBurst},
+	}
+	for _, limit := range rateLimits {
+		if limit.value == nil || *limit.value == *limit.current {
+			continue
+		}
+		if user := middleware.GetUser(c); user == nil || !user.IsAdmin() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only admins can change a project's rate limit",
+			})
+		}
+		if *limit.value < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": limit.name + " must not be negative",
+			})
+		}
+		*limit.current = *limit.value
+	}
+
+	if req.DefaultLevel != nil {
+		level := models.LogLevel(strings.ToUpper(strings.TrimSpace(*req.DefaultLevel)))
+		if level != "" && level.Priority() < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Unknown default_level %q", *req.DefaultLevel),
+			})
+		}
+		project.DefaultLevel = level
+	}
+	if req.RequireLevel != nil {
+		project.RequireLevel = *req.RequireLevel
+	}
+	if req.OutboundWebhook != nil {
+		if req.OutboundWebhook.IsEmpty() {
+			project.OutboundWebhook = nil
+		} else {
+			if err := req.OutboundWebhook.Normalize(); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			project.OutboundWebhook = req.OutboundWebhook
+		}
+	}
 
 	if err := h.projectRepo.Update(project); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -231,21 +709,80 @@ func (h *ProjectHandler) UpdateProject(c *fiber.Ctx) error {
 	return c.JSON(project)
 }
 
-// DeleteProject handles DELETE /api/admin/projects/:id
+// DeleteProject handles DELETE /api/admin/projects/:id. The project is
+// soft-deleted: it disappears along with its logs and its API key stops
+// working, but a restore brings it all back. Admins can pass purge=true to
+// delete the project and its logs for good.
 func (h *ProjectHandler) DeleteProject(c *fiber.Ctx) error {
 	projectID := c.Params("id")
 
-	if err := h.projectRepo.Delete(projectID); err != nil {
+	if c.QueryBool("purge") {
+		if user := middleware.GetUser(c); user == nil || !user.IsAdmin() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only admins can purge projects",
+			})
+		}
+
+		if err := h.projectRepo.Delete(projectID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to delete project",
+			})
+		}
+
+		h.audit.Record(c, models.AuditProjectDelete, "project", projectID, "purge")
+
+		return c.JSON(fiber.Map{
+			"message": "Project purged",
+		})
+	}
+
+	deleted, err := h.projectRepo.SoftDelete(projectID)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete project",
 		})
 	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	h.audit.Record(c, models.AuditProjectDelete, "project", projectID, "")
 
 	return c.JSON(fiber.Map{
 		"message": "Project deleted",
 	})
 }
 
+// RestoreProject handles POST /api/admin/projects/:id/restore
+func (h *ProjectHandler) RestoreProject(c *fiber.Ctx) error {
+	projectID := c.Params("id")
+
+	restored, err := h.projectRepo.Restore(projectID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to restore project",
+		})
+	}
+	if !restored {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Deleted project not found",
+		})
+	}
+
+	h.audit.Record(c, models.AuditProjectRestore, "project", projectID, "")
+
+	project, err := h.projectRepo.GetByID(projectID)
+	if err != nil || project == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+
+	return c.JSON(project)
+}
+
 // RotateAPIKey handles POST /api/admin/projects/:id/rotate-key
 func (h *ProjectHandler) RotateAPIKey(c *fiber.Ctx) error {
 	projectID := c.Params("id")
@@ -270,6 +807,8 @@ func (h *ProjectHandler) RotateAPIKey(c *fiber.Ctx) error {
 		})
 	}
 
+	h.audit.Record(c, models.AuditProjectRotateKey, "project", projectID, "")
+
 	// Get updated project
 	project, _ = h.projectRepo.GetByID(projectID)
 
@@ -278,3 +817,110 @@ func (h *ProjectHandler) RotateAPIKey(c *fiber.Ctx) error {
 		"api_key_prefix": project.APIKeyPrefix,
 	})
 }
+
+// RotateSigningSecret handles POST /api/admin/projects/:id/rotate-signing-secret.
+// It enables signed ingestion for the project if it was off; the previous
+// secret stops working immediately.
+func (h *ProjectHandler) RotateSigningSecret(c *fiber.Ctx) error {
+	projectID := c.Params("id")
+
+	project, err := h.projectRepo.GetByID(projectID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+
+	if project == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	secret, err := h.projectRepo.RotateSigningSecret(projectID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to rotate signing secret",
+		})
+	}
+
+	h.audit.Record(c, models.AuditProjectRotateSecret, "project", projectID, "")
+
+	return c.JSON(fiber.Map{
+		"signing_secret":  secret,
+		"signing_enabled": true,
+	})
+}
+
+// DisableSigning handles DELETE /api/admin/projects/:id/signing-secret
+func (h *ProjectHandler) DisableSigning(c *fiber.Ctx) error {
+	projectID := c.Params("id")
+
+	project, err := h.projectRepo.GetByID(projectID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+
+	if project == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	if err := h.projectRepo.ClearSigningSecret(projectID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to disable request signing",
+		})
+	}
+
+	h.audit.Record(c, models.AuditProjectDisableSign, "project", projectID, "")
+
+	return c.JSON(fiber.Map{
+		"message": "Request signing disabled",
+	})
+}
+
+// PauseIngestion handles POST /api/admin/projects/:id/pause-ingestion. The
+// project's API key keeps its value but ingestion requests get 403 until
+// ingestion is resumed; reading the project and its logs is unaffected.
+func (h *ProjectHandler) PauseIngestion(c *fiber.Ctx) error {
+	return h.setIngestionPaused(c, true)
+}
+
+// ResumeIngestion handles POST /api/admin/projects/:id/resume-ingestion
+func (h *ProjectHandler) ResumeIngestion(c *fiber.Ctx) error {
+	return h.setIngestionPaused(c, false)
+}
+
+func (h *ProjectHandler) setIngestionPaused(c *fiber.Ctx, paused bool) error {
+	projectID := c.Params("id")
+
+	found, err := h.projectRepo.SetIngestionPaused(projectID, paused)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update project",
+		})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Project not found",
+		})
+	}
+
+	action := models.AuditProjectResumeIngest
+	if paused {
+		action = models.AuditProjectPauseIngest
+	}
+	h.audit.Record(c, action, "project", projectID, "")
+
+	project, err := h.projectRepo.GetByID(projectID)
+	if err != nil || project == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get project",
+		})
+	}
+
+	return c.JSON(project)
+}
@@ -7,9 +7,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"central-logs/internal/config"
 	"central-logs/internal/handlers"
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
@@ -37,6 +39,9 @@ func setupProjectTestDB(t *testing.T) *sql.DB {
 			two_factor_secret TEXT DEFAULT '',
 			two_factor_enabled INTEGER DEFAULT 0,
 			backup_codes TEXT DEFAULT '',
+			deleted_at DATETIME,
+			last_login_at DATETIME,
+			token_version INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -54,8 +59,20 @@ func setupProjectTestDB(t *testing.T) *sql.DB {
 			api_key_prefix TEXT NOT NULL,
 			is_active INTEGER NOT NULL DEFAULT 1,
 			retention_config TEXT,
+			redaction_config TEXT,
+			sampling_config TEXT,
+			signing_secret TEXT,
+			max_body_bytes INTEGER,
+			rate_limit_per_minute INTEGER,
+			rate_limit_burst INTEGER,
+			ingestion_paused INTEGER NOT NULL DEFAULT 0,
+			default_level TEXT,
+			require_level INTEGER NOT NULL DEFAULT 0,
+			outbound_webhook TEXT,
+			group_name TEXT,
 			icon_type TEXT DEFAULT 'initials',
 			icon_value TEXT DEFAULT '',
+			deleted_at DATETIME,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -89,6 +106,9 @@ func setupProjectTestDB(t *testing.T) *sql.DB {
 			source TEXT,
 			timestamp DATETIME NOT NULL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			status TEXT NOT NULL DEFAULT 'new',
+			acknowledged_by TEXT,
+			acknowledged_at DATETIME,
 			FOREIGN KEY (project_id) REFERENCES projects(id)
 		)
 	`)
@@ -96,6 +116,20 @@ func setupProjectTestDB(t *testing.T) *sql.DB {
 		t.Fatalf("Failed to create logs table: %v", err)
 	}
 
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_pinned_projects (
+			user_id TEXT NOT NULL,
+			project_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, project_id),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create user_pinned_projects table: %v", err)
+	}
+
 	return db
 }
 
@@ -234,6 +268,90 @@ func TestProjectHandler_ListProjects_RegularUser(t *testing.T) {
 	}
 }
 
+func TestProjectHandler_Groups_RegularUser(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	user := &models.User{
+		Username: "user",
+		Email:    "user@example.com",
+		Password: "password123",
+		Name:     "Regular User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	userRepo.Create(user)
+
+	// The user belongs to api and web; billing's group must not leak
+	for _, p := range []struct {
+		name, group string
+		member      bool
+	}{
+		{"api", "backend", true},
+		{"billing", "backend", false},
+		{"web", "frontend", true},
+		{"scratch", "", true},
+	} {
+		project := &models.Project{Name: p.name, Group: p.group, IsActive: true}
+		projectRepo.Create(project)
+		if p.member {
+			userProjectRepo.Create(&models.UserProject{
+				UserID:    user.ID,
+				ProjectID: project.ID,
+				Role:      models.ProjectRoleMember,
+			})
+		}
+	}
+
+	token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/projects", projectHandler.ListProjects)
+	app.Get("/projects/groups", projectHandler.ListGroups)
+
+	get := func(path string, out interface{}) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d", path, resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, out)
+	}
+
+	var groups struct {
+		Groups []models.ProjectGroupCount `json:"groups"`
+	}
+	get("/projects/groups", &groups)
+	want := []models.ProjectGroupCount{{Group: "backend", Count: 1}, {Group: "frontend", Count: 1}}
+	if len(groups.Groups) != len(want) || groups.Groups[0] != want[0] || groups.Groups[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, groups.Groups)
+	}
+
+	var listed struct {
+		Projects []models.Project `json:"projects"`
+	}
+	get("/projects?group=backend", &listed)
+	if len(listed.Projects) != 1 || listed.Projects[0].Name != "api" {
+		t.Errorf("Expected only api in backend, got %+v", listed.Projects)
+	}
+}
+
 func TestProjectHandler_CreateProject_Success(t *testing.T) {
 	db := setupProjectTestDB(t)
 	defer db.Close()
@@ -398,6 +516,143 @@ func TestProjectHandler_CreateProject_MissingName(t *testing.T) {
 	}
 }
 
+func TestProjectHandler_CloneProject(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+	if _, err := db.Exec(`
+		CREATE TABLE channels (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			config TEXT NOT NULL,
+			min_level TEXT NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		t.Fatalf("Failed to create channels table: %v", err)
+	}
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+	projectHandler.SetChannelRepository(channelRepo)
+
+	owner := &models.User{Username: "owner", Email: "owner@example.com", Password: "password123", Name: "Owner", Role: models.RoleUser, IsActive: true}
+	userRepo.Create(owner)
+	member := &models.User{Username: "member", Email: "member@example.com", Password: "password123", Name: "Member", Role: models.RoleUser, IsActive: true}
+	userRepo.Create(member)
+
+	source := &models.Project{
+		Name:            "Checkout",
+		Group:           "payments",
+		IsActive:        true,
+		RetentionConfig: &models.RetentionConfig{MaxAge: "14d"},
+		DefaultLevel:    models.LogLevelWarn,
+		MaxBodyBytes:    2 << 20,
+	}
+	sourceKey, _ := projectRepo.Create(source)
+	projectRepo.RotateSigningSecret(source.ID)
+	userProjectRepo.Create(&models.UserProject{UserID: owner.ID, ProjectID: source.ID, Role: models.ProjectRoleOwner})
+	userProjectRepo.Create(&models.UserProject{UserID: member.ID, ProjectID: source.ID, Role: models.ProjectRoleMember})
+	logRepo.Create(&models.Log{ProjectID: source.ID, Level: models.LogLevelError, Message: "stays behind", Timestamp: time.Now()})
+
+	telegram := &models.Channel{ProjectID: source.ID, Type: models.ChannelTypeTelegram, Name: "On-call",
+		Config: map[string]interface{}{"bot_token": "123:secret", "chat_id": "42"}, MinLevel: models.LogLevelError, IsActive: true}
+	discord := &models.Channel{ProjectID: source.ID, Type: models.ChannelTypeDiscord, Name: "Muted",
+		Config: map[string]interface{}{"webhook_url": "https://discord.test/hook"}, MinLevel: models.LogLevelWarn, IsActive: false}
+	channelRepo.Create(telegram)
+	channelRepo.Create(discord)
+
+	token, _ := jwtManager.Generate(owner.ID, owner.Email, string(owner.Role))
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Post("/projects/:id/clone", projectHandler.CloneProject)
+
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+source.ID+"/clone", strings.NewReader(`{"name":"Checkout staging"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+	var response handlers.CloneProjectResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	if response.Project == nil || response.Project.ID == source.ID || response.Project.Name != "Checkout staging" {
+		t.Fatalf("Expected a new project named Checkout staging, got %+v", response.Project)
+	}
+	if response.APIKey == "" || response.APIKey == sourceKey {
+		t.Error("Expected the copy to get its own API key")
+	}
+	if found, _ := projectRepo.GetByAPIKey(response.APIKey); found == nil || found.ID != response.Project.ID {
+		t.Error("Expected the new API key to belong to the copy")
+	}
+
+	clone, _ := projectRepo.GetByID(response.Project.ID)
+	if clone.Group != "payments" || clone.RetentionConfig == nil || clone.RetentionConfig.MaxAge != "14d" ||
+		clone.DefaultLevel != models.LogLevelWarn || clone.MaxBodyBytes != 2<<20 {
+		t.Errorf("Expected the settings copied, got %+v", clone)
+	}
+	if clone.SigningEnabled {
+		t.Error("Expected the signing secret not to be copied")
+	}
+
+	channels, _ := channelRepo.GetByProjectID(clone.ID)
+	if len(channels) != 2 {
+		t.Fatalf("Expected both channels copied, got %d", len(channels))
+	}
+	byName := map[string]*models.Channel{}
+	for _, channel := range channels {
+		if channel.ID == telegram.ID || channel.ID == discord.ID {
+			t.Errorf("Expected new channel IDs, got %s", channel.ID)
+		}
+		byName[channel.Name] = channel
+	}
+	if c := byName["On-call"]; c == nil || c.Config["bot_token"] != "123:secret" || c.MinLevel != models.LogLevelError || !c.IsActive {
+		t.Errorf("Expected the Telegram channel copied as is, got %+v", c)
+	}
+	if c := byName["Muted"]; c == nil || c.IsActive {
+		t.Errorf("Expected the inactive channel to stay inactive, got %+v", c)
+	}
+	for _, channel := range response.Channels {
+		if channel.Config["bot_token"] == "123:secret" || channel.Config["webhook_url"] == "https://discord.test/hook" {
+			t.Errorf("Expected credentials masked in the response, got %v", channel.Config)
+		}
+	}
+	if sourceChannels, _ := channelRepo.GetByProjectID(source.ID); len(sourceChannels) != 2 {
+		t.Errorf("Expected the source to keep its channels, got %d", len(sourceChannels))
+	}
+
+	if role, _ := userProjectRepo.GetByUserAndProject(owner.ID, clone.ID); role == nil || role.Role != models.ProjectRoleOwner {
+		t.Error("Expected the requester to own the copy")
+	}
+	if members, _ := userProjectRepo.GetProjectMembers(clone.ID); len(members) != 1 {
+		t.Errorf("Expected only the requester as member, got %d", len(members))
+	}
+	if _, total, _ := logRepo.List(&models.LogFilter{ProjectIDs: []string{clone.ID}}); total != 0 {
+		t.Errorf("Expected no logs copied, got %d", total)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/projects/missing/clone", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ = app.Test(req)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown project, got %d", resp.StatusCode)
+	}
+}
+
 func TestProjectHandler_GetProject_Success(t *testing.T) {
 	db := setupProjectTestDB(t)
 	defer db.Close()
@@ -553,6 +808,81 @@ func TestProjectHandler_GetProject_AccessDenied(t *testing.T) {
 	}
 }
 
+func TestProjectHandler_GetProject_ObscureNotFound(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	user := &models.User{
+		Username: "testuser",
+		Email:    "user@example.com",
+		Password: "password123",
+		Name:     "Test User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	userRepo.Create(user)
+	admin := &models.User{
+		Username: "admin",
+		Email:    "admin@example.com",
+		Password: "password123",
+		Name:     "Admin",
+		Role:     models.RoleAdmin,
+		IsActive: true,
+	}
+	userRepo.Create(admin)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	projectRepo.Create(project)
+
+	userToken, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+	adminToken, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/projects/:id", projectHandler.GetProject)
+
+	get := func(token, projectID string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	middleware.SetObscureNotFound(true)
+	defer middleware.SetObscureNotFound(false)
+
+	// A project the user can't see is indistinguishable from a missing one
+	missingStatus, missingBody := get(userToken, "nonexistent-id")
+	deniedStatus, deniedBody := get(userToken, project.ID)
+	if missingStatus != http.StatusNotFound || deniedStatus != http.StatusNotFound || missingBody != deniedBody {
+		t.Errorf("Expected identical 404s, got %d %s and %d %s", missingStatus, missingBody, deniedStatus, deniedBody)
+	}
+
+	// Admins still see the precise status
+	if status, _ := get(adminToken, project.ID); status != http.StatusOK {
+		t.Errorf("Expected admin to get 200, got %d", status)
+	}
+	if status, _ := get(adminToken, "nonexistent-id"); status != http.StatusNotFound {
+		t.Errorf("Expected admin to get 404 for a missing project, got %d", status)
+	}
+}
+
 func TestProjectHandler_UpdateProject_Success(t *testing.T) {
 	db := setupProjectTestDB(t)
 	defer db.Close()
@@ -609,7 +939,7 @@ func TestProjectHandler_UpdateProject_Success(t *testing.T) {
 	}
 }
 
-func TestProjectHandler_UpdateProject_NotFound(t *testing.T) {
+func TestProjectHandler_UpdateProject_RedactionConfig(t *testing.T) {
 	db := setupProjectTestDB(t)
 	defer db.Close()
 
@@ -619,27 +949,53 @@ func TestProjectHandler_UpdateProject_NotFound(t *testing.T) {
 
 	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
 
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	projectRepo.Create(project)
+
 	app := fiber.New()
 	app.Put("/projects/:id", projectHandler.UpdateProject)
 
-	reqBody := map[string]string{
-		"name": "Updated Project",
+	update := func(redactionConfig map[string]interface{}) int {
+		bodyBytes, _ := json.Marshal(map[string]interface{}{"redaction_config": redactionConfig})
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
 	}
-	bodyBytes, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest(http.MethodPut, "/projects/nonexistent-id", bytes.NewReader(bodyBytes))
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := app.Test(req)
-	if err != nil {
-		t.Fatalf("Failed to make request: %v", err)
+	if status := update(map[string]interface{}{"detectors": []string{"email"}, "patterns": []string{`acct-\d+`}}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.RedactionConfig == nil || len(stored.RedactionConfig.Detectors) != 1 || len(stored.RedactionConfig.Patterns) != 1 {
+		t.Fatalf("Expected the redaction config to be stored, got %+v", stored.RedactionConfig)
 	}
 
-	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	if status := update(map[string]interface{}{"patterns": []string{"("}}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid pattern, got %d", status)
+	}
+	if status := update(map[string]interface{}{"detectors": []string{"passport"}}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown detector, got %d", status)
+	}
+
+	// An empty object falls back to the server-wide rules
+	if status := update(map[string]interface{}{}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ = projectRepo.GetByID(project.ID)
+	if stored.RedactionConfig != nil {
+		t.Errorf("Expected the override to be cleared, got %+v", stored.RedactionConfig)
 	}
 }
 
-func TestProjectHandler_DeleteProject_Success(t *testing.T) {
+func TestProjectHandler_UpdateProject_SamplingConfig(t *testing.T) {
 	db := setupProjectTestDB(t)
 	defer db.Close()
 
@@ -656,77 +1012,826 @@ func TestProjectHandler_DeleteProject_Success(t *testing.T) {
 	projectRepo.Create(project)
 
 	app := fiber.New()
-	app.Delete("/projects/:id", projectHandler.DeleteProject)
+	app.Put("/projects/:id", projectHandler.UpdateProject)
 
-	req := httptest.NewRequest(http.MethodDelete, "/projects/"+project.ID, nil)
-	resp, err := app.Test(req)
-	if err != nil {
-		t.Fatalf("Failed to make request: %v", err)
+	update := func(levels map[string]interface{}) int {
+		bodyBytes, _ := json.Marshal(map[string]interface{}{"sampling_config": map[string]interface{}{"levels": levels}})
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	if status := update(map[string]interface{}{"debug": 0.1, "error": 1}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.SamplingConfig == nil || stored.SamplingConfig.Rate(models.LogLevelDebug) != 0.1 || stored.SamplingConfig.Rate(models.LogLevelInfo) != 1 {
+		t.Fatalf("Expected normalized sampling rates, got %+v", stored.SamplingConfig)
 	}
 
-	// Verify deletion
-	deletedProject, _ := projectRepo.GetByID(project.ID)
-	if deletedProject != nil {
-		t.Error("Project should be deleted")
+	if status := update(map[string]interface{}{"debug": 1.5}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a rate above 1, got %d", status)
+	}
+	if status := update(map[string]interface{}{"trace": 0.5}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown level, got %d", status)
+	}
+
+	// No levels turns sampling off
+	if status := update(map[string]interface{}{}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ = projectRepo.GetByID(project.ID)
+	if stored.SamplingConfig != nil {
+		t.Errorf("Expected sampling to be cleared, got %+v", stored.SamplingConfig)
 	}
 }
 
-func TestProjectHandler_RotateAPIKey_Success(t *testing.T) {
+func TestProjectHandler_UpdateProject_MaxBodyBytes(t *testing.T) {
 	db := setupProjectTestDB(t)
 	defer db.Close()
 
 	projectRepo := models.NewProjectRepository(db)
-	userProjectRepo := models.NewUserProjectRepository(db)
-	logRepo := models.NewLogRepository(db)
+	projectHandler := handlers.NewProjectHandler(projectRepo, models.NewUserProjectRepository(db), models.NewLogRepository(db))
+	projectHandler.SetMaxBodyLimit(4 * 1024 * 1024)
 
-	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+	project := &models.Project{Name: "Bulk Importer", IsActive: true}
+	projectRepo.Create(project)
 
-	project := &models.Project{
-		Name:     "Test Project",
-		IsActive: true,
-	}
-	oldAPIKey, _ := projectRepo.Create(project)
+	update := func(role models.UserRole, maxBodyBytes int) int {
+		app := fiber.New()
+		app.Put("/projects/:id", func(c *fiber.Ctx) error {
+			c.Locals("user", &models.User{ID: "user-1", Role: role})
+			return c.Next()
+		}, projectHandler.UpdateProject)
 
-	app := fiber.New()
-	app.Post("/projects/:id/rotate-key", projectHandler.RotateAPIKey)
+		bodyBytes, _ := json.Marshal(map[string]interface{}{"max_body_bytes": maxBodyBytes})
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
 
-	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.ID+"/rotate-key", nil)
-	resp, err := app.Test(req)
-	if err != nil {
-		t.Fatalf("Failed to make request: %v", err)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	if status := update(models.RoleUser, 2*1024*1024); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", status)
+	}
+	if status := update(models.RoleAdmin, 8*1024*1024); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 above the server limit, got %d", status)
+	}
+	if status := update(models.RoleAdmin, 2*1024*1024); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.MaxBodyBytes != 2*1024*1024 {
+		t.Fatalf("Expected the raised limit to be stored, got %d", stored.MaxBodyBytes)
 	}
 
-	var response map[string]interface{}
-	body, _ := io.ReadAll(resp.Body)
-	json.Unmarshal(body, &response)
+	// Zero goes back to the server default
+	if status := update(models.RoleAdmin, 0); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ = projectRepo.GetByID(project.ID)
+	if stored.MaxBodyBytes != 0 {
+		t.Errorf("Expected the override to be cleared, got %d", stored.MaxBodyBytes)
+	}
+}
 
-	newAPIKey := response["api_key"].(string)
-	if newAPIKey == "" {
-		t.Error("Expected new API key to be returned")
+func TestProjectHandler_UpdateProject_RateLimit(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	projectHandler := handlers.NewProjectHandler(projectRepo, models.NewUserProjectRepository(db), models.NewLogRepository(db))
+
+	project := &models.Project{Name: "Chatty Service", IsActive: true}
+	projectRepo.Create(project)
+
+	update := func(role models.UserRole, body map[string]interface{}) int {
+		app := fiber.New()
+		app.Put("/projects/:id", func(c *fiber.Ctx) error {
+			c.Locals("user", &models.User{ID: "user-1", Role: role})
+			return c.Next()
+		}, projectHandler.UpdateProject)
+
+		bodyBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
 	}
 
-	if newAPIKey == oldAPIKey {
-		t.Error("New API key should be different from old API key")
+	if status := update(models.RoleUser, map[string]interface{}{"rate_limit_burst": 500}); status != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a non-admin, got %d", status)
+	}
+	if status := update(models.RoleAdmin, map[string]interface{}{"rate_limit_per_minute": -1}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a negative rate, got %d", status)
+	}
+	if status := update(models.RoleAdmin, map[string]interface{}{"rate_limit_per_minute": 5000, "rate_limit_burst": 500}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.RateLimitPerMinute != 5000 || stored.RateLimitBurst != 500 {
+		t.Fatalf("Expected 5000/min with a burst of 500, got %d/%d", stored.RateLimitPerMinute, stored.RateLimitBurst)
 	}
 
-	// Verify old API key no longer works
-	oldProject, _ := projectRepo.GetByAPIKey(oldAPIKey)
-	if oldProject != nil {
-		t.Error("Old API key should not work")
+	// Resending the current values is not a change, so members may do it
+	if status := update(models.RoleUser, map[string]interface{}{"name": "Chatty", "rate_limit_burst": 500}); status != http.StatusOK {
+		t.Errorf("Expected status 200 for an unchanged limit, got %d", status)
 	}
 
-	// Verify new API key works
-	newProject, _ := projectRepo.GetByAPIKey(newAPIKey)
-	if newProject == nil {
-		t.Error("New API key should work")
+	if status := update(models.RoleAdmin, map[string]interface{}{"rate_limit_per_minute": 0, "rate_limit_burst": 0}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ = projectRepo.GetByID(project.ID)
+	if stored.RateLimitPerMinute != 0 || stored.RateLimitBurst != 0 {
+		t.Errorf("Expected the overrides to be cleared, got %d/%d", stored.RateLimitPerMinute, stored.RateLimitBurst)
+	}
+}
+
+func TestProjectHandler_UpdateProject_DefaultLevel(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	projectHandler := handlers.NewProjectHandler(projectRepo, models.NewUserProjectRepository(db), models.NewLogRepository(db))
+
+	project := &models.Project{Name: "Terse Service", IsActive: true}
+	projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Put("/projects/:id", projectHandler.UpdateProject)
+
+	update := func(body map[string]interface{}) int {
+		bodyBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := update(map[string]interface{}{"default_level": "FATAL"}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown level, got %d", status)
+	}
+	if status := update(map[string]interface{}{"default_level": "error", "require_level": true}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.DefaultLevel != models.LogLevelError || !stored.RequireLevel {
+		t.Fatalf("Expected an ERROR default in strict mode, got %q/%v", stored.DefaultLevel, stored.RequireLevel)
+	}
+
+	if status := update(map[string]interface{}{"default_level": "", "require_level": false}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ = projectRepo.GetByID(project.ID)
+	if stored.DefaultLevel != "" || stored.RequireLevel {
+		t.Errorf("Expected the INFO default to be restored, got %q/%v", stored.DefaultLevel, stored.RequireLevel)
+	}
+}
+
+func TestProjectHandler_UpdateProject_OutboundWebhook(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	projectHandler := handlers.NewProjectHandler(projectRepo, models.NewUserProjectRepository(db), models.NewLogRepository(db))
+
+	project := &models.Project{Name: "Piped Service", IsActive: true}
+	projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Put("/projects/:id", projectHandler.UpdateProject)
+
+	update := func(webhook map[string]interface{}) int {
+		bodyBytes, _ := json.Marshal(map[string]interface{}{"outbound_webhook": webhook})
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{"url": "ftp://example.com/hook"},
+		{"url": "not a url"},
+		{"url": "https://example.com/hook", "min_level": "FATAL"},
+	} {
+		if status := update(bad); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %v, got %d", bad, status)
+		}
+	}
+
+	if status := update(map[string]interface{}{"url": "https://example.com/hook", "min_level": "error"}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.OutboundWebhook == nil || stored.OutboundWebhook.URL != "https://example.com/hook" || stored.OutboundWebhook.MinLevel != models.LogLevelError {
+		t.Fatalf("Expected the webhook to be stored with an ERROR threshold, got %+v", stored.OutboundWebhook)
+	}
+
+	if status := update(map[string]interface{}{"url": ""}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if stored, _ := projectRepo.GetByID(project.ID); stored.OutboundWebhook != nil {
+		t.Errorf("Expected the webhook to be removed, got %+v", stored.OutboundWebhook)
+	}
+}
+
+func TestProjectHandler_UpdateProject_RetentionFloor(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	projectHandler := handlers.NewProjectHandler(projectRepo, models.NewUserProjectRepository(db), models.NewLogRepository(db))
+	projectHandler.SetRetentionFloor(func() config.RetentionFloor {
+		return config.RetentionFloor{MinAge: "1d", MinCount: 100}
+	})
+
+	project := &models.Project{Name: "Careful Service", IsActive: true}
+	projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Put("/projects/:id", projectHandler.UpdateProject)
+	update := func(body map[string]interface{}) (int, string) {
+		bodyBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		msg, _ := result["error"].(string)
+		return resp.StatusCode, msg
+	}
+
+	tests := []struct {
+		name      string
+		retention map[string]interface{}
+		want      string
+	}{
+		{"max_age too short", map[string]interface{}{"max_age": "1s"}, "retention_config.max_age 1s is below the retention floor of 1d"},
+		{"max_count too small", map[string]interface{}{"max_count": 10}, "retention_config.max_count 10 is below the retention floor of 100 logs"},
+		{"level too aggressive", map[string]interface{}{"levels": map[string]interface{}{"debug": map[string]interface{}{"max_age": "30m"}}}, "retention_config.levels.debug.max_age 30m"},
+	}
+	for _, tt := range tests {
+		status, msg := update(map[string]interface{}{"retention_config": tt.retention})
+		if status != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", tt.name, status)
+		}
+		if !strings.Contains(msg, tt.want) || !strings.Contains(msg, `"force": true`) {
+			t.Errorf("%s: expected an error explaining the floor, got %q", tt.name, msg)
+		}
+	}
+	if stored, _ := projectRepo.GetByID(project.ID); stored.RetentionConfig != nil {
+		t.Fatalf("Expected rejected retention to leave the project unchanged, got %+v", stored.RetentionConfig)
+	}
+
+	// Forcing doesn't let a malformed value through
+	if status, _ := update(map[string]interface{}{"retention_config": map[string]interface{}{"max_age": "soon"}, "force": true}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a malformed max_age, got %d", status)
+	}
+
+	// Within the floor, and with no count limit, needs no force
+	if status, msg := update(map[string]interface{}{"retention_config": map[string]interface{}{"max_age": "7d", "max_count": 0}}); status != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", status, msg)
+	}
+
+	if status, msg := update(map[string]interface{}{"retention_config": map[string]interface{}{"max_age": "1h", "max_count": 10}, "force": true}); status != http.StatusOK {
+		t.Fatalf("Expected a forced update to succeed, got %d: %s", status, msg)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.RetentionConfig == nil || stored.RetentionConfig.MaxAge != "1h" || stored.RetentionConfig.MaxCount != 10 {
+		t.Errorf("Expected the forced retention to be stored, got %+v", stored.RetentionConfig)
+	}
+}
+
+func TestProjectHandler_UpdateProject_Group(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		Group:    "backend",
+		IsActive: true,
+	}
+	projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Put("/projects/:id", projectHandler.UpdateProject)
+
+	update := func(body map[string]interface{}) int {
+		bodyBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// Updates that leave group out keep it
+	if status := update(map[string]interface{}{"name": "Renamed"}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ := projectRepo.GetByID(project.ID)
+	if stored.Group != "backend" {
+		t.Errorf("Expected group to be kept, got %q", stored.Group)
+	}
+
+	if status := update(map[string]interface{}{"group": "  payments "}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ = projectRepo.GetByID(project.ID)
+	if stored.Group != "payments" {
+		t.Errorf("Expected trimmed group %q, got %q", "payments", stored.Group)
+	}
+
+	if status := update(map[string]interface{}{"group": strings.Repeat("x", 65)}); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an overlong group, got %d", status)
+	}
+
+	if status := update(map[string]interface{}{"group": ""}); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	stored, _ = projectRepo.GetByID(project.ID)
+	if stored.Group != "" {
+		t.Errorf("Expected group to be cleared, got %q", stored.Group)
+	}
+}
+
+func TestProjectHandler_UpdateProject_NotFound(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	app := fiber.New()
+	app.Put("/projects/:id", projectHandler.UpdateProject)
+
+	reqBody := map[string]string{
+		"name": "Updated Project",
+	}
+	bodyBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPut, "/projects/nonexistent-id", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestProjectHandler_DeleteProject_Success(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Delete("/projects/:id", projectHandler.DeleteProject)
+
+	req := httptest.NewRequest(http.MethodDelete, "/projects/"+project.ID, nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Verify deletion
+	deletedProject, _ := projectRepo.GetByID(project.ID)
+	if deletedProject != nil {
+		t.Error("Project should be deleted")
+	}
+}
+
+func TestProjectHandler_DeleteProject_Restore(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	owner := &models.User{Username: "owner", Email: "owner@example.com", Password: "password123", Name: "Owner", Role: models.RoleUser, IsActive: true}
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Password: "password123", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	userRepo.Create(owner)
+	userRepo.Create(admin)
+
+	project := &models.Project{Name: "Test Project", IsActive: true}
+	apiKey, _ := projectRepo.Create(project)
+	userProjectRepo.Create(&models.UserProject{UserID: owner.ID, ProjectID: project.ID, Role: models.ProjectRoleOwner})
+	logRepo.Create(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "kept", Timestamp: time.Now()})
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Delete("/projects/:id", projectHandler.DeleteProject)
+	app.Post("/projects/:id/restore", projectHandler.RestoreProject)
+
+	do := func(user *models.User, method, path string) int {
+		t.Helper()
+		token, _ := jwtManager.Generate(user.ID, user.Email, string(user.Role))
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	visibleLogs := func() int {
+		t.Helper()
+		total, err := logRepo.Count(&models.LogFilter{})
+		if err != nil {
+			t.Fatalf("Failed to count logs: %v", err)
+		}
+		return total
+	}
+
+	if status := do(owner, http.MethodDelete, "/projects/"+project.ID); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if deleted, _ := projectRepo.GetByID(project.ID); deleted != nil {
+		t.Error("Soft-deleted project should not be found")
+	}
+	if p, _ := projectRepo.GetByAPIKey(apiKey); p != nil {
+		t.Error("Soft-deleted project's API key should stop working")
+	}
+	if ids, _ := userProjectRepo.GetUserProjectIDs(owner.ID); len(ids) != 0 {
+		t.Errorf("Expected no accessible projects, got %v", ids)
+	}
+	if ok, _ := userProjectRepo.HasAccess(owner.ID, project.ID); ok {
+		t.Error("Soft-deleted project should deny access to its members")
+	}
+	if ok, _ := userProjectRepo.HasRole(owner.ID, project.ID, models.ProjectRoleOwner); ok {
+		t.Error("Soft-deleted project should deny its roles")
+	}
+	if ok, _ := userProjectRepo.HasRoleInDeleted(owner.ID, project.ID, models.ProjectRoleOwner); !ok {
+		t.Error("Owner should still be recognized for a restore")
+	}
+	if n := visibleLogs(); n != 0 {
+		t.Errorf("Expected logs to be hidden, got %d", n)
+	}
+
+	if status := do(owner, http.MethodPost, "/projects/"+project.ID+"/restore"); status != http.StatusOK {
+		t.Fatalf("Expected restore to return 200, got %d", status)
+	}
+	if restored, _ := projectRepo.GetByAPIKey(apiKey); restored == nil {
+		t.Error("Restored project should accept its API key again")
+	}
+	if ok, _ := userProjectRepo.HasAccess(owner.ID, project.ID); !ok {
+		t.Error("Restored project should give its members access again")
+	}
+	if n := visibleLogs(); n != 1 {
+		t.Errorf("Expected the retained log to be visible again, got %d", n)
+	}
+
+	if status := do(owner, http.MethodDelete, "/projects/"+project.ID+"?purge=true"); status != http.StatusForbidden {
+		t.Errorf("Expected owners to be refused a purge, got %d", status)
+	}
+	if status := do(admin, http.MethodDelete, "/projects/"+project.ID+"?purge=true"); status != http.StatusOK {
+		t.Fatalf("Expected admin purge to return 200, got %d", status)
+	}
+	if status := do(admin, http.MethodPost, "/projects/"+project.ID+"/restore"); status != http.StatusNotFound {
+		t.Errorf("Expected a purged project to be unrecoverable, got %d", status)
+	}
+}
+
+func TestProjectHandler_RotateAPIKey_Success(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	oldAPIKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Post("/projects/:id/rotate-key", projectHandler.RotateAPIKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.ID+"/rotate-key", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var response map[string]interface{}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+
+	newAPIKey := response["api_key"].(string)
+	if newAPIKey == "" {
+		t.Error("Expected new API key to be returned")
+	}
+
+	if newAPIKey == oldAPIKey {
+		t.Error("New API key should be different from old API key")
+	}
+
+	// Verify old API key no longer works
+	oldProject, _ := projectRepo.GetByAPIKey(oldAPIKey)
+	if oldProject != nil {
+		t.Error("Old API key should not work")
+	}
+
+	// Verify new API key works
+	newProject, _ := projectRepo.GetByAPIKey(newAPIKey)
+	if newProject == nil {
+		t.Error("New API key should work")
+	}
+}
+
+func TestProjectHandler_RotateSigningSecret(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Post("/projects/:id/rotate-signing-secret", projectHandler.RotateSigningSecret)
+	app.Delete("/projects/:id/signing-secret", projectHandler.DisableSigning)
+
+	rotate := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/projects/"+project.ID+"/rotate-signing-secret", nil)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		var response struct {
+			SigningSecret string `json:"signing_secret"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return response.SigningSecret
+	}
+
+	first := rotate()
+	if first == "" {
+		t.Fatal("Expected a signing secret to be returned")
+	}
+	second := rotate()
+	if second == first {
+		t.Error("Rotating should issue a new secret")
+	}
+
+	updated, _ := projectRepo.GetByID(project.ID)
+	if updated.SigningSecret != second || !updated.SigningEnabled {
+		t.Errorf("Expected the latest secret to be stored and signing enabled, got %+v", updated)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/projects/"+project.ID+"/signing-secret", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	updated, _ = projectRepo.GetByID(project.ID)
+	if updated.SigningSecret != "" || updated.SigningEnabled {
+		t.Error("Expected signing to be disabled")
+	}
+}
+
+func TestProjectHandler_PauseIngestion(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+
+	project := &models.Project{
+		Name:     "Test Project",
+		IsActive: true,
+	}
+	apiKey, _ := projectRepo.Create(project)
+
+	app := fiber.New()
+	app.Post("/projects/:id/pause-ingestion", projectHandler.PauseIngestion)
+	app.Post("/projects/:id/resume-ingestion", projectHandler.ResumeIngestion)
+
+	post := func(path string) (int, *models.Project) {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var response models.Project
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return resp.StatusCode, &response
+	}
+
+	status, paused := post("/projects/" + project.ID + "/pause-ingestion")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if !paused.IngestionPaused || !paused.IsActive {
+		t.Errorf("Expected an active project with ingestion paused, got %+v", paused)
+	}
+
+	// Pausing leaves the key in place, unlike rotating it
+	stored, _ := projectRepo.GetByAPIKey(apiKey)
+	if stored == nil || !stored.IngestionPaused {
+		t.Error("Expected the existing API key to still resolve to the paused project")
+	}
+
+	status, resumed := post("/projects/" + project.ID + "/resume-ingestion")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if resumed.IngestionPaused {
+		t.Error("Expected ingestion to be resumed")
+	}
+
+	if status, _ := post("/projects/non-existent-id/pause-ingestion"); status != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown project, got %d", status)
+	}
+}
+
+func TestProjectHandler_PinProject(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+
+	projectHandler := handlers.NewProjectHandler(projectRepo, userProjectRepo, logRepo)
+	projectHandler.SetPinnedProjects(models.NewPinnedProjectRepository(db))
+
+	admin := &models.User{
+		Username: "admin",
+		Email:    "admin@example.com",
+		Password: "password123",
+		Name:     "Admin User",
+		Role:     models.RoleAdmin,
+		IsActive: true,
+	}
+	userRepo.Create(admin)
+
+	var projects []*models.Project
+	for i := 0; i < 3; i++ {
+		project := &models.Project{
+			Name:     "Project " + string(rune('0'+i)),
+			IsActive: true,
+		}
+		projectRepo.Create(project)
+		projects = append(projects, project)
+	}
+
+	token, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	app.Use(authMiddleware.RequireAuth())
+	app.Get("/projects", projectHandler.ListProjects)
+	app.Post("/projects/:id/pin", projectHandler.PinProject)
+	app.Delete("/projects/:id/pin", projectHandler.UnpinProject)
+
+	do := func(method, path string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var response map[string]interface{}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &response)
+		return resp.StatusCode, response
+	}
+	pinnedNames := func(query string) []string {
+		status, response := do(http.MethodGet, "/projects"+query)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		var names []string
+		for _, item := range response["projects"].([]interface{}) {
+			project := item.(map[string]interface{})
+			if project["pinned"] == true {
+				names = append(names, project["name"].(string))
+			}
+		}
+		return names
+	}
+
+	// Pinning twice is not an error
+	for i := 0; i < 2; i++ {
+		status, response := do(http.MethodPost, "/projects/"+projects[1].ID+"/pin")
+		if status != http.StatusOK || response["pinned"] != true {
+			t.Fatalf("Expected the project to be pinned, got %d %v", status, response)
+		}
+	}
+
+	if names := pinnedNames(""); len(names) != 1 || names[0] != "Project 1" {
+		t.Errorf("Expected only Project 1 to be marked pinned, got %v", names)
+	}
+	_, response := do(http.MethodGet, "/projects?pinned=true")
+	if listed := response["projects"].([]interface{}); len(listed) != 1 {
+		t.Errorf("Expected pinned=true to list 1 project, got %d", len(listed))
+	}
+
+	status, response := do(http.MethodDelete, "/projects/"+projects[1].ID+"/pin")
+	if status != http.StatusOK || response["pinned"] != false {
+		t.Errorf("Expected the project to be unpinned, got %d %v", status, response)
+	}
+	if names := pinnedNames(""); len(names) != 0 {
+		t.Errorf("Expected no pinned projects, got %v", names)
+	}
+	_, response = do(http.MethodGet, "/projects?pinned=true")
+	if listed := response["projects"].([]interface{}); len(listed) != 0 {
+		t.Errorf("Expected pinned=true to list nothing, got %d", len(listed))
+	}
+
+	if status, _ := do(http.MethodPost, "/projects/non-existent-id/pin"); status != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown project, got %d", status)
 	}
 }
 
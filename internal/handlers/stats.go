@@ -1,17 +1,114 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
+	"central-logs/internal/queue"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// StatsCache keeps rendered overview stats for a short TTL. Entries go to Redis
+// when it is connected so every instance shares them, and to a process-local
+// map otherwise. New logs show up once an entry expires.
+type StatsCache struct {
+	redisClient *queue.RedisClient
+	ttl         time.Duration
+
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry
+}
+
+type statsCacheEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewStatsCache(redisClient *queue.RedisClient, ttl time.Duration) *StatsCache {
+	return &StatsCache{
+		redisClient: redisClient,
+		ttl:         ttl,
+		entries:     make(map[string]statsCacheEntry),
+	}
+}
+
+// Get returns the cached payload for key if it has not expired
+func (sc *StatsCache) Get(key string) ([]byte, bool) {
+	if sc.redisClient != nil {
+		data, ok, err := sc.redisClient.GetStatsCache(context.Background(), key)
+		if err != nil {
+			log.Printf("Failed to read stats cache: %v", err)
+			return nil, false
+		}
+		return data, ok
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	entry, ok := sc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(sc.entries, key)
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// Set caches payload under key for the configured TTL
+func (sc *StatsCache) Set(key string, data []byte) {
+	if sc.redisClient != nil {
+		if err := sc.redisClient.SetStatsCache(context.Background(), key, data, sc.ttl); err != nil {
+			log.Printf("Failed to write stats cache: %v", err)
+		}
+		return
+	}
+
+	now := time.Now()
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	// Drop expired entries so users whose access changed don't pile up
+	for k, entry := range sc.entries {
+		if now.After(entry.expiresAt) {
+			delete(sc.entries, k)
+		}
+	}
+	sc.entries[key] = statsCacheEntry{data: data, expiresAt: now.Add(sc.ttl)}
+}
+
+// overviewCacheKey scopes cached overviews. Admins see every project plus the
+// user count, so they share one entry; other users share entries with anyone
+// who can access exactly the same projects. The zone is part of the key since
+// it moves the "logs today" boundary.
+func overviewCacheKey(user *models.User, projectIDs []string, loc *time.Location) string {
+	if user.IsAdmin() {
+		return "overview:admin:" + loc.String()
+	}
+
+	ids := append([]string(nil), projectIDs...)
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(strings.Join(ids, ",")))
+	return "overview:projects:" + loc.String() + ":" + hex.EncodeToString(sum[:])
+}
+
 type StatsHandler struct {
 	logRepo         *models.LogRepository
 	projectRepo     *models.ProjectRepository
 	userProjectRepo *models.UserProjectRepository
 	userRepo        *models.UserRepository
+	cache           *StatsCache
+	location        *time.Location // Default zone for "logs today"
 }
 
 func NewStatsHandler(
@@ -25,9 +122,21 @@ func NewStatsHandler(
 		projectRepo:     projectRepo,
 		userProjectRepo: userProjectRepo,
 		userRepo:        userRepo,
+		location:        time.UTC,
 	}
 }
 
+// SetCache enables caching of overview stats
+func (h *StatsHandler) SetCache(cache *StatsCache) {
+	h.cache = cache
+}
+
+// SetLocation sets the zone whose midnight starts "logs today" when a request
+// does not pass its own
+func (h *StatsHandler) SetLocation(loc *time.Location) {
+	h.location = loc
+}
+
 // GetOverview handles GET /api/admin/stats/overview
 func (h *StatsHandler) GetOverview(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
@@ -37,6 +146,24 @@ func (h *StatsHandler) GetOverview(c *fiber.Ctx) error {
 		})
 	}
 
+	loc := h.location
+	if tz := c.Query("tz"); tz != "" {
+		parsed, err := time.LoadLocation(tz)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Unknown time zone: " + tz,
+			})
+		}
+		loc = parsed
+	}
+
+	// Admins don't depend on a project list, so check their entry first
+	if user.IsAdmin() {
+		if data, ok := h.cachedOverview(overviewCacheKey(user, nil, loc)); ok {
+			return sendJSONBytes(c, data)
+		}
+	}
+
 	// Get projects
 	var projects []*models.Project
 	var err error
@@ -59,6 +186,13 @@ func (h *StatsHandler) GetOverview(c *fiber.Ctx) error {
 		projectIDs[i] = p.ID
 	}
 
+	cacheKey := overviewCacheKey(user, projectIDs, loc)
+	if !user.IsAdmin() {
+		if data, ok := h.cachedOverview(cacheKey); ok {
+			return sendJSONBytes(c, data)
+		}
+	}
+
 	// Calculate stats
 	totalLogs := 0
 	logsByLevel := make(map[string]int)
@@ -84,9 +218,9 @@ func (h *StatsHandler) GetOverview(c *fiber.Ctx) error {
 	// Get logs today count
 	var logsToday int
 	if user.IsAdmin() {
-		logsToday, _ = h.logRepo.CountToday(nil)
+		logsToday, _ = h.logRepo.CountToday(nil, loc)
 	} else {
-		logsToday, _ = h.logRepo.CountToday(projectIDs)
+		logsToday, _ = h.logRepo.CountToday(projectIDs, loc)
 	}
 
 	// Get recent logs
@@ -101,6 +235,7 @@ func (h *StatsHandler) GetOverview(c *fiber.Ctx) error {
 		"total_projects": len(projects),
 		"total_logs":     totalLogs,
 		"logs_today":     logsToday,
+		"timezone":       loc.String(),
 		"logs_by_level":  logsByLevel,
 		"recent_logs":    recentLogs,
 		"projects":       projectStats,
@@ -112,9 +247,34 @@ func (h *StatsHandler) GetOverview(c *fiber.Ctx) error {
 		response["total_users"] = userCount
 	}
 
+	if h.cache != nil {
+		data, err := json.Marshal(response)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to encode stats",
+			})
+		}
+		h.cache.Set(cacheKey, data)
+		return sendJSONBytes(c, data)
+	}
+
 	return c.JSON(response)
 }
 
+// cachedOverview looks up a cached overview when caching is enabled
+func (h *StatsHandler) cachedOverview(key string) ([]byte, bool) {
+	if h.cache == nil {
+		return nil, false
+	}
+	return h.cache.Get(key)
+}
+
+// sendJSONBytes writes an already encoded JSON body
+func sendJSONBytes(c *fiber.Ctx, data []byte) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(data)
+}
+
 // GetProjectStats handles GET /api/admin/stats/projects/:id
 func (h *StatsHandler) GetProjectStats(c *fiber.Ctx) error {
 	user := middleware.GetUser(c)
@@ -130,9 +290,7 @@ func (h *StatsHandler) GetProjectStats(c *fiber.Ctx) error {
 	if !user.IsAdmin() {
 		hasAccess, _ := h.userProjectRepo.HasAccess(user.ID, projectID)
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Access denied",
-			})
+			return middleware.DenyAccess(c, "Access denied", "Project not found")
 		}
 	}
 
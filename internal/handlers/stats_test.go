@@ -0,0 +1,156 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"central-logs/internal/handlers"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStatsHandler_GetOverview_Cached(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	logRepo := models.NewLogRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	userProjectRepo := models.NewUserProjectRepository(db)
+	userRepo := models.NewUserRepository(db)
+
+	statsHandler := handlers.NewStatsHandler(logRepo, projectRepo, userProjectRepo, userRepo)
+	statsHandler.SetCache(handlers.NewStatsCache(nil, time.Minute))
+
+	projectA := &models.Project{Name: "Project A", IsActive: true}
+	projectB := &models.Project{Name: "Project B", IsActive: true}
+	projectRepo.Create(projectA)
+	projectRepo.Create(projectB)
+
+	admin := &models.User{ID: "admin-1", Username: "admin", Role: models.RoleAdmin}
+	member := &models.User{ID: "user-1", Username: "member", Role: models.RoleUser}
+	userProjectRepo.Create(&models.UserProject{UserID: member.ID, ProjectID: projectA.ID, Role: models.ProjectRoleMember})
+
+	addLog := func(projectID string) {
+		if err := logRepo.Create(&models.Log{ProjectID: projectID, Level: models.LogLevelInfo, Message: "hello"}); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+	addLog(projectA.ID)
+	addLog(projectB.ID)
+
+	getOverview := func(user *models.User) map[string]interface{} {
+		app := fiber.New()
+		app.Get("/stats/overview", func(c *fiber.Ctx) error {
+			c.Locals("user", user)
+			return statsHandler.GetOverview(c)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/stats/overview", nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		var result map[string]interface{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return result
+	}
+
+	adminFirst := getOverview(admin)
+	memberFirst := getOverview(member)
+
+	if adminFirst["total_logs"] != float64(2) {
+		t.Errorf("Expected admin to see 2 logs, got %v", adminFirst["total_logs"])
+	}
+	if _, ok := adminFirst["total_users"]; !ok {
+		t.Error("Expected total_users in admin overview")
+	}
+	if memberFirst["total_logs"] != float64(1) {
+		t.Errorf("Expected member to see 1 log, got %v", memberFirst["total_logs"])
+	}
+	if _, ok := memberFirst["total_users"]; ok {
+		t.Error("Member overview should not include total_users")
+	}
+
+	// Within the TTL the database is not asked again, so new logs don't show
+	addLog(projectA.ID)
+
+	if got := getOverview(admin)["total_logs"]; got != float64(2) {
+		t.Errorf("Expected cached admin total of 2, got %v", got)
+	}
+	if got := getOverview(member)["total_logs"]; got != float64(1) {
+		t.Errorf("Expected cached member total of 1, got %v", got)
+	}
+
+	// A user with a different project set gets their own entry
+	other := &models.User{ID: "user-2", Username: "other", Role: models.RoleUser}
+	userProjectRepo.Create(&models.UserProject{UserID: other.ID, ProjectID: projectB.ID, Role: models.ProjectRoleMember})
+	if got := getOverview(other)["total_logs"]; got != float64(1) {
+		t.Errorf("Expected 1 log for project B member, got %v", got)
+	}
+}
+
+func TestStatsCache_Expires(t *testing.T) {
+	cache := handlers.NewStatsCache(nil, 20*time.Millisecond)
+	cache.Set("key", []byte(`{"total_logs":1}`))
+
+	if data, ok := cache.Get("key"); !ok || string(data) != `{"total_logs":1}` {
+		t.Fatalf("Expected cached value, got %q (ok=%v)", data, ok)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := cache.Get("key"); ok {
+		t.Error("Expected entry to expire after the TTL")
+	}
+}
+
+func TestStatsHandler_GetOverview_TimeZone(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	logRepo := models.NewLogRepository(db)
+	statsHandler := handlers.NewStatsHandler(logRepo, models.NewProjectRepository(db), models.NewUserProjectRepository(db), models.NewUserRepository(db))
+	statsHandler.SetCache(handlers.NewStatsCache(nil, time.Minute))
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	statsHandler.SetLocation(jakarta)
+
+	admin := &models.User{ID: "admin-1", Username: "admin", Role: models.RoleAdmin}
+	app := fiber.New()
+	app.Get("/stats/overview", func(c *fiber.Ctx) error {
+		c.Locals("user", admin)
+		return statsHandler.GetOverview(c)
+	})
+
+	getTimezone := func(query string) (int, interface{}) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/stats/overview"+query, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result["timezone"]
+	}
+
+	if status, tz := getTimezone(""); status != fiber.StatusOK || tz != "Asia/Jakarta" {
+		t.Errorf("Expected the configured zone, got %d %v", status, tz)
+	}
+
+	// The zone is part of the cache key, so a cached default is not reused
+	if status, tz := getTimezone("?tz=America/New_York"); status != fiber.StatusOK || tz != "America/New_York" {
+		t.Errorf("Expected the requested zone, got %d %v", status, tz)
+	}
+
+	if status, _ := getTimezone("?tz=Mars/Olympus"); status != fiber.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown zone, got %d", status)
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"central-logs/internal/database"
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+	"central-logs/internal/websocket"
+	"central-logs/internal/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// dbStatsTables are the tables whose row counts GET /api/admin/system/db-stats reports
+var dbStatsTables = []string{"logs", "users", "projects", "channels"}
+
+// SystemHandler exposes server maintenance information to admins
+type SystemHandler struct {
+	db         *database.DB
+	migrations []database.Migration
+	logHandler *LogHandler
+	logBuffer  *worker.LogBuffer
+	redis      *queue.RedisClient
+	maint      *worker.DBMaintenance
+	mode       *middleware.MaintenanceMode
+	audit      *AuditRecorder
+}
+
+// NewSystemHandler creates a new SystemHandler
+func NewSystemHandler(db *database.DB, migrations []database.Migration) *SystemHandler {
+	return &SystemHandler{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// SetIngestion enables the ingestion status endpoint. buffer is nil when the
+// async buffer is off.
+func (h *SystemHandler) SetIngestion(logHandler *LogHandler, buffer *worker.LogBuffer) {
+	h.logHandler = logHandler
+	h.logBuffer = buffer
+}
+
+// SetRedis reports Redis health on the readiness and Redis status endpoints.
+// Without it Redis shows as disabled.
+func (h *SystemHandler) SetRedis(redisClient *queue.RedisClient) {
+	h.redis = redisClient
+}
+
+// SetMaintenance enables the manual vacuum endpoint
+func (h *SystemHandler) SetMaintenance(maint *worker.DBMaintenance) {
+	h.maint = maint
+}
+
+// SetMaintenanceMode enables the maintenance mode toggle
+func (h *SystemHandler) SetMaintenanceMode(mode *middleware.MaintenanceMode) {
+	h.mode = mode
+}
+
+// SetAuditRecorder records maintenance mode changes to the audit trail
+func (h *SystemHandler) SetAuditRecorder(audit *AuditRecorder) {
+	h.audit = audit
+}
+
+// ReadinessResponse reports whether the server can take traffic
+type ReadinessResponse struct {
+	Status   string `json:"status"`   // ready; degraded while Redis is down; unavailable when the database is
+	Database string `json:"database"` // up or down
+	Redis    string `json:"redis"`    // up, down, or disabled when it was unreachable at startup
+}
+
+// Ready handles GET /ready. Only a database outage makes it answer 503:
+// without Redis, logs are still stored and searchable.
+func (h *SystemHandler) Ready(c *fiber.Ctx) error {
+	resp := ReadinessResponse{Status: "ready", Database: "up", Redis: "disabled"}
+
+	if h.redis != nil {
+		resp.Redis = "up"
+		if !h.redis.Available() {
+			resp.Redis = "down"
+			resp.Status = "degraded"
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 2*time.Second)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		resp.Database = "down"
+		resp.Status = "unavailable"
+		return c.Status(fiber.StatusServiceUnavailable).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+// RedisStatus handles GET /api/admin/system/redis
+func (h *SystemHandler) RedisStatus(c *fiber.Ctx) error {
+	if h.redis == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Redis is disabled; it was unreachable at startup",
+		})
+	}
+	return c.JSON(h.redis.Health())
+}
+
+// IngestionStatusResponse reports the load on ingestion's background work
+type IngestionStatusResponse struct {
+	Fanout      worker.TaskPoolStats `json:"fanout"`
+	AsyncBuffer struct {
+		Enabled bool `json:"enabled"`
+		Pending int  `json:"pending"`
+	} `json:"async_buffer"`
+	WebSocket *websocket.HubStats `json:"websocket,omitempty"`
+}
+
+// IngestionStatus handles GET /api/admin/system/ingestion
+func (h *SystemHandler) IngestionStatus(c *fiber.Ctx) error {
+	if h.logHandler == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Ingestion status is not available",
+		})
+	}
+
+	var status IngestionStatusResponse
+	status.Fanout = h.logHandler.FanoutStats()
+	if h.logBuffer != nil {
+		status.AsyncBuffer.Enabled = true
+		status.AsyncBuffer.Pending = h.logBuffer.Pending()
+	}
+	if stats, ok := h.logHandler.WebSocketStats(); ok {
+		status.WebSocket = &stats
+	}
+	return c.JSON(status)
+}
+
+// ListMigrations handles GET /api/admin/system/migrations
+func (h *SystemHandler) ListMigrations(c *fiber.Ctx) error {
+	states, err := h.db.MigrationStatesWithRegistry(h.migrations)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get migration status",
+		})
+	}
+
+	pending := 0
+	for _, state := range states {
+		if !state.Applied {
+			pending++
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"migrations": states,
+		"pending":    pending,
+	})
+}
+
+// Vacuum handles POST /api/admin/system/vacuum. It runs the same maintenance
+// as the schedule and answers once it is done, with the bytes reclaimed.
+func (h *SystemHandler) Vacuum(c *fiber.Ctx) error {
+	if h.maint == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Database maintenance is not available",
+		})
+	}
+
+	// Not tied to the request: stopping VACUUM midway only wastes the work
+	result, err := h.maint.Run(context.Background())
+	if errors.Is(err, worker.ErrMaintenanceRunning) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Database maintenance is already running",
+		})
+	}
+	if err != nil {
+		log.Printf("Manual database maintenance failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Database maintenance failed",
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// MaintenanceModeRequest turns maintenance mode on or off
+type MaintenanceModeRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// SetMaintenanceModeState handles POST /api/admin/system/maintenance. While it
+// is on, log ingestion answers 503 with Retry-After; the admin API stays up.
+func (h *SystemHandler) SetMaintenanceModeState(c *fiber.Ctx) error {
+	if h.mode == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Maintenance mode is not available",
+		})
+	}
+
+	var req MaintenanceModeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "enabled is required",
+		})
+	}
+
+	if err := h.mode.SetEnabled(*req.Enabled); err != nil {
+		log.Printf("Failed to set maintenance mode: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to set maintenance mode",
+		})
+	}
+	h.audit.Record(c, models.AuditMaintenanceMode, "system", "maintenance", strconv.FormatBool(*req.Enabled))
+
+	return c.JSON(fiber.Map{
+		"enabled": *req.Enabled,
+	})
+}
+
+// DBStats handles GET /api/admin/system/db-stats: row counts of the main
+// tables, the database and WAL file sizes and the indexes on logs, to see
+// whether slow queries come from growth or a missing index
+func (h *SystemHandler) DBStats(c *fiber.Ctx) error {
+	stats, err := h.db.Stats(c.Context(), dbStatsTables, "logs")
+	if err != nil {
+		log.Printf("Failed to read database stats: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read database stats",
+		})
+	}
+	return c.JSON(stats)
+}
@@ -0,0 +1,138 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"central-logs/internal/database"
+	"central-logs/internal/database/migrations"
+	"central-logs/internal/handlers"
+	"central-logs/internal/models"
+	"central-logs/internal/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSystemHandler_Ready(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	systemHandler := handlers.NewSystemHandler(&database.DB{DB: sqlDB}, nil)
+	app := fiber.New()
+	app.Get("/ready", systemHandler.Ready)
+
+	ready := func() (int, handlers.ReadinessResponse) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ready", nil))
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var body handlers.ReadinessResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	// Without Redis the server is still ready; Redis shows as disabled
+	status, body := ready()
+	if status != http.StatusOK || body.Status != "ready" || body.Database != "up" || body.Redis != "disabled" {
+		t.Fatalf("Expected ready with Redis disabled, got %d %+v", status, body)
+	}
+
+	sqlDB.Close()
+	status, body = ready()
+	if status != http.StatusServiceUnavailable || body.Status != "unavailable" || body.Database != "down" {
+		t.Errorf("Expected 503 once the database is gone, got %d %+v", status, body)
+	}
+}
+
+func TestSystemHandler_Vacuum(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "vacuum.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	systemHandler := handlers.NewSystemHandler(db, nil)
+	app := fiber.New()
+	app.Post("/system/vacuum", systemHandler.Vacuum)
+
+	vacuum := func() (int, database.MaintenanceResult) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/system/vacuum", nil))
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var body database.MaintenanceResult
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	if status, _ := vacuum(); status != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 without maintenance configured, got %d", status)
+	}
+
+	// No schedule: only manual runs
+	systemHandler.SetMaintenance(worker.NewDBMaintenance(db, nil))
+	status, body := vacuum()
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if body.Method != database.MaintenanceVacuum || body.SizeAfter <= 0 {
+		t.Errorf("Expected a vacuum result with the database size, got %+v", body)
+	}
+}
+
+func TestSystemHandler_DBStats(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if err := db.MigrateWithRegistry(migrations.GetAll()); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	project := &models.Project{Name: "Stats", IsActive: true}
+	if _, err := models.NewProjectRepository(db.DB).Create(project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	logRepo := models.NewLogRepository(db.DB)
+	for i := 0; i < 3; i++ {
+		if err := logRepo.Create(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "hello"}); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	app := fiber.New()
+	app.Get("/system/db-stats", handlers.NewSystemHandler(db, nil).DBStats)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/system/db-stats", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var stats database.Stats
+	json.NewDecoder(resp.Body).Decode(&stats)
+
+	rows := make(map[string]int64)
+	for _, table := range stats.Tables {
+		rows[table.Name] = table.Rows
+	}
+	if rows["logs"] != 3 || rows["projects"] != 1 {
+		t.Errorf("Expected 3 logs and 1 project, got %v", rows)
+	}
+	if stats.SizeBytes <= 0 || len(stats.Indexes) == 0 {
+		t.Errorf("Expected the file size and the logs indexes, got %+v", stats)
+	}
+	for _, index := range stats.Indexes {
+		if len(index.Columns) == 0 {
+			t.Errorf("Expected columns for index %s", index.Name)
+		}
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"crypto/rand"
 	"encoding/base32"
 	"encoding/json"
+	"log"
 	"strings"
+	"time"
 
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
+	"central-logs/internal/queue"
 	"central-logs/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -19,8 +23,15 @@ type TwoFactorHandler struct {
 	userRepo   *models.UserRepository
 	jwtManager *utils.JWTManager
 	issuer     string
+	audit      *AuditRecorder
+	totpSkew   uint
+	attempts   *attemptLimiter
 }
 
+// defaultTOTPSkew accepts codes from one 30-second step either side of the
+// server's clock
+const defaultTOTPSkew = 1
+
 func NewTwoFactorHandler(userRepo *models.UserRepository, jwtManager *utils.JWTManager, issuer string) *TwoFactorHandler {
 	if issuer == "" {
 		issuer = "Central Logs"
@@ -29,9 +40,43 @@ func NewTwoFactorHandler(userRepo *models.UserRepository, jwtManager *utils.JWTM
 		userRepo:   userRepo,
 		jwtManager: jwtManager,
 		issuer:     issuer,
+		totpSkew:   defaultTOTPSkew,
+		attempts:   newAttemptLimiter(nil, defaultTwoFactorMaxAttempts, defaultTwoFactorWindow),
 	}
 }
 
+// SetAttemptLimit sets how many codes a user may try per window across
+// sign-in and the 2FA settings; maxAttempts 0 turns the limit off. Counts go
+// to redis when it is non-nil and reachable.
+func (h *TwoFactorHandler) SetAttemptLimit(redis *queue.RateLimiter, maxAttempts int, window time.Duration) {
+	h.attempts = newAttemptLimiter(redis, maxAttempts, window)
+}
+
+// SetTOTPSkew sets how many time steps before or after the current one a
+// TOTP code is still accepted, for authenticator clocks that drift. 0 only
+// accepts the current step.
+func (h *TwoFactorHandler) SetTOTPSkew(skew int) {
+	if skew >= 0 {
+		h.totpSkew = uint(skew)
+	}
+}
+
+// validTOTP checks code against secret within the configured skew
+func (h *TwoFactorHandler) validTOTP(code, secret string) bool {
+	valid, _ := totp.ValidateCustom(code, secret, time.Now().UTC(), totp.ValidateOpts{
+		Period:    30,
+		Skew:      h.totpSkew,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	return valid
+}
+
+// SetAuditRecorder records 2FA being turned off to the audit trail
+func (h *TwoFactorHandler) SetAuditRecorder(audit *AuditRecorder) {
+	h.audit = audit
+}
+
 type SetupResponse struct {
 	Secret string `json:"secret"`
 	QRCode string `json:"qr_code"`
@@ -122,8 +167,12 @@ func (h *TwoFactorHandler) Verify(c *fiber.Ctx) error {
 		})
 	}
 
+	if allowed, retryAfter := h.attempts.allow(user.ID, time.Now()); !allowed {
+		return tooManyAttempts(c, retryAfter)
+	}
+
 	// Verify the code
-	valid := totp.Validate(req.Code, user.TwoFactorSecret)
+	valid := h.validTOTP(req.Code, user.TwoFactorSecret)
 	if !valid {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid verification code",
@@ -194,6 +243,10 @@ func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
 		})
 	}
 
+	if allowed, retryAfter := h.attempts.allow(user.ID, time.Now()); !allowed {
+		return tooManyAttempts(c, retryAfter)
+	}
+
 	// Verify the code (TOTP or backup code)
 	if !h.verifyCode(user, req.Code) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -208,6 +261,8 @@ func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
 		})
 	}
 
+	h.audit.Record(c, models.AuditTwoFactorDisable, "user", user.ID, "")
+
 	return c.JSON(fiber.Map{
 		"message": "Two-factor authentication disabled successfully",
 	})
@@ -243,8 +298,14 @@ func (h *TwoFactorHandler) RegenerateBackupCodes(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.Code != "" {
+		if allowed, retryAfter := h.attempts.allow(user.ID, time.Now()); !allowed {
+			return tooManyAttempts(c, retryAfter)
+		}
+	}
+
 	// Verify TOTP code (not backup code)
-	if req.Code == "" || !totp.Validate(req.Code, user.TwoFactorSecret) {
+	if req.Code == "" || !h.validTOTP(req.Code, user.TwoFactorSecret) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid verification code",
 		})
@@ -313,6 +374,10 @@ func (h *TwoFactorHandler) VerifyLogin(c *fiber.Ctx) error {
 		})
 	}
 
+	if allowed, retryAfter := h.attempts.allow(user.ID, time.Now()); !allowed {
+		return tooManyAttempts(c, retryAfter)
+	}
+
 	// Verify the code (TOTP or backup code)
 	if !h.verifyCode(user, req.Code) {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -321,12 +386,13 @@ func (h *TwoFactorHandler) VerifyLogin(c *fiber.Ctx) error {
 	}
 
 	// Generate full JWT token
-	token, err := h.jwtManager.Generate(user.ID, user.Username, string(user.Role))
+	token, err := h.jwtManager.GenerateForVersion(user.ID, user.Username, string(user.Role), user.TokenVersion)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
+	recordLogin(h.userRepo, user.ID)
 
 	return c.JSON(fiber.Map{
 		"token": token,
@@ -337,7 +403,7 @@ func (h *TwoFactorHandler) VerifyLogin(c *fiber.Ctx) error {
 // verifyCode checks if the code is a valid TOTP or backup code
 func (h *TwoFactorHandler) verifyCode(user *models.User, code string) bool {
 	// First, try TOTP
-	if totp.Validate(code, user.TwoFactorSecret) {
+	if h.validTOTP(code, user.TwoFactorSecret) {
 		return true
 	}
 
@@ -346,26 +412,17 @@ func (h *TwoFactorHandler) verifyCode(user *models.User, code string) bool {
 		return false
 	}
 
-	var hashedCodes []string
-	if err := json.Unmarshal([]byte(user.BackupCodes), &hashedCodes); err != nil {
-		return false
-	}
-
 	// Clean the input code (remove any dashes or spaces)
 	cleanCode := strings.ReplaceAll(strings.ReplaceAll(code, "-", ""), " ", "")
 	cleanCode = strings.ToUpper(cleanCode)
 
-	for i, hashedCode := range hashedCodes {
-		if bcrypt.CompareHashAndPassword([]byte(hashedCode), []byte(cleanCode)) == nil {
-			// Remove used backup code
-			hashedCodes = append(hashedCodes[:i], hashedCodes[i+1:]...)
-			newCodesJSON, _ := json.Marshal(hashedCodes)
-			h.userRepo.UpdateBackupCodes(user.ID, string(newCodesJSON))
-			return true
-		}
+	// Remove the used backup code; only one request may consume it
+	consumed, err := h.userRepo.ConsumeBackupCode(user.ID, cleanCode)
+	if err != nil {
+		log.Printf("Failed to consume backup code for user %s: %v", user.ID, err)
+		return false
 	}
-
-	return false
+	return consumed
 }
 
 // GetStatus returns the current 2FA status
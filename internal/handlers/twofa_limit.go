@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"central-logs/internal/queue"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultTwoFactorMaxAttempts = 5
+	defaultTwoFactorWindow      = 15 * time.Minute
+	// maxTrackedAttemptWindows bounds the in-memory counters before expired
+	// ones are swept
+	maxTrackedAttemptWindows = 1024
+)
+
+// attemptLimiter caps how many 2FA codes a user may try per window, so a
+// six-digit code can't be guessed by brute force. Counts are kept in Redis
+// when a limiter is set and reachable, so they hold across instances, and in
+// memory otherwise. A user who runs out is locked out until the window ends.
+type attemptLimiter struct {
+	redis       *queue.RateLimiter
+	maxAttempts int // 0 turns limiting off
+	window      time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*attemptWindow
+}
+
+type attemptWindow struct {
+	start time.Time
+	count int
+}
+
+func newAttemptLimiter(redis *queue.RateLimiter, maxAttempts int, window time.Duration) *attemptLimiter {
+	if window <= 0 {
+		window = defaultTwoFactorWindow
+	}
+	return &attemptLimiter{
+		redis:       redis,
+		maxAttempts: maxAttempts,
+		window:      window,
+		windows:     make(map[string]*attemptWindow),
+	}
+}
+
+// allow counts an attempt by userID. When it is over the limit, retryAfter is
+// how long until the user may try again.
+func (l *attemptLimiter) allow(userID string, now time.Time) (bool, time.Duration) {
+	if l == nil || l.maxAttempts <= 0 {
+		return true, 0
+	}
+
+	if l.redis != nil {
+		allowed, _, reset, err := l.redis.Allow(context.Background(), "ratelimit:2fa:"+userID, l.maxAttempts, l.window)
+		if err == nil {
+			return allowed, reset.Sub(now)
+		}
+		// Redis is down; count in memory rather than allow unlimited guesses
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.windows) >= maxTrackedAttemptWindows {
+		for id, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, id)
+			}
+		}
+	}
+	w, ok := l.windows[userID]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &attemptWindow{start: now}
+		l.windows[userID] = w
+	}
+	w.count++
+	if w.count > l.maxAttempts {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	return true, 0
+}
+
+// tooManyAttempts answers a user who has used up their 2FA attempts
+func tooManyAttempts(c *fiber.Ctx, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set("Retry-After", strconv.Itoa(seconds))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error":       "Too many verification attempts, try again later",
+		"retry_after": seconds,
+	})
+}
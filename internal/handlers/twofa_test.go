@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"central-logs/internal/handlers"
+	"central-logs/internal/models"
+	"central-logs/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pquerna/otp/totp"
+)
+
+// setupTwoFactorTest creates a user with 2FA enabled and returns its TOTP
+// secret and an app serving the 2FA routes with a handler set up by configure
+func setupTwoFactorTest(t *testing.T, configure func(*handlers.TwoFactorHandler)) (*models.User, string, *utils.JWTManager, *fiber.App) {
+	db := setupAuthTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	userRepo := models.NewUserRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	twoFactorHandler := handlers.NewTwoFactorHandler(userRepo, jwtManager, "")
+	configure(twoFactorHandler)
+
+	user := &models.User{Username: "testuser", Email: "test@example.com", Password: "password123", Name: "Test User", Role: models.RoleUser, IsActive: true}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "Central Logs", AccountName: user.Username})
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	if err := userRepo.UpdateTwoFactor(user.ID, key.Secret(), true, ""); err != nil {
+		t.Fatalf("Failed to enable 2FA: %v", err)
+	}
+
+	app := fiber.New()
+	app.Post("/2fa/verify", twoFactorHandler.VerifyLogin)
+	admin := app.Group("/admin", func(c *fiber.Ctx) error {
+		c.Locals("user", user)
+		return c.Next()
+	})
+	admin.Post("/2fa/disable", twoFactorHandler.Disable)
+	admin.Post("/2fa/backup-codes", twoFactorHandler.RegenerateBackupCodes)
+	return user, key.Secret(), jwtManager, app
+}
+
+func postJSON(t *testing.T, app *fiber.App, path string, body interface{}) int {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestTwoFactorHandler_ClockSkew(t *testing.T) {
+	user, secret, jwtManager, app := setupTwoFactorTest(t, func(h *handlers.TwoFactorHandler) {
+		h.SetAttemptLimit(nil, 0, 0)
+	})
+
+	codeAt := func(offset time.Duration) string {
+		code, err := totp.GenerateCode(secret, time.Now().Add(offset))
+		if err != nil {
+			t.Fatalf("Failed to generate code: %v", err)
+		}
+		return code
+	}
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   int
+	}{
+		{"current step", 0, http.StatusOK},
+		{"previous step", -30 * time.Second, http.StatusOK},
+		{"next step", 30 * time.Second, http.StatusOK},
+		{"two steps behind", -60 * time.Second, http.StatusUnauthorized},
+		{"two steps ahead", 60 * time.Second, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempToken, _ := jwtManager.GenerateTempToken(user.ID, user.Username, string(user.Role))
+			status := postJSON(t, app, "/2fa/verify", map[string]string{"temp_token": tempToken, "code": codeAt(tt.offset)})
+			if status != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, status)
+			}
+		})
+	}
+
+	// Backup code regeneration checks the code the same way
+	if status := postJSON(t, app, "/admin/2fa/backup-codes", map[string]string{"code": codeAt(-30 * time.Second)}); status != http.StatusOK {
+		t.Errorf("Expected a previous-step code to regenerate backup codes, got %d", status)
+	}
+}
+
+func TestTwoFactorHandler_NoClockSkew(t *testing.T) {
+	user, secret, jwtManager, app := setupTwoFactorTest(t, func(h *handlers.TwoFactorHandler) {
+		h.SetTOTPSkew(0)
+	})
+
+	for _, offset := range []time.Duration{-30 * time.Second, 30 * time.Second} {
+		code, _ := totp.GenerateCode(secret, time.Now().Add(offset))
+		current, _ := totp.GenerateCode(secret, time.Now())
+		if code == current {
+			continue // Adjacent steps rarely share a code
+		}
+		tempToken, _ := jwtManager.GenerateTempToken(user.ID, user.Username, string(user.Role))
+		if status := postJSON(t, app, "/2fa/verify", map[string]string{"temp_token": tempToken, "code": code}); status != http.StatusUnauthorized {
+			t.Errorf("Expected a code %s off to be rejected with no skew, got %d", offset, status)
+		}
+	}
+}
+
+func TestTwoFactorHandler_AttemptLimit(t *testing.T) {
+	user, secret, jwtManager, app := setupTwoFactorTest(t, func(h *handlers.TwoFactorHandler) {
+		h.SetAttemptLimit(nil, 3, time.Minute)
+	})
+	tempToken, _ := jwtManager.GenerateTempToken(user.ID, user.Username, string(user.Role))
+
+	// Sign-in and the 2FA settings share the user's attempts
+	if status := postJSON(t, app, "/2fa/verify", map[string]string{"temp_token": tempToken, "code": "000000"}); status != http.StatusUnauthorized {
+		t.Fatalf("Expected a wrong code to be rejected, got %d", status)
+	}
+	if status := postJSON(t, app, "/admin/2fa/disable", map[string]string{"code": "000000"}); status != http.StatusBadRequest {
+		t.Fatalf("Expected a wrong code to be rejected, got %d", status)
+	}
+	if status := postJSON(t, app, "/2fa/verify", map[string]string{"temp_token": tempToken, "code": "000000"}); status != http.StatusUnauthorized {
+		t.Fatalf("Expected a wrong code to be rejected, got %d", status)
+	}
+
+	// Once they are used up even the right code is refused
+	code, _ := totp.GenerateCode(secret, time.Now())
+	bodyBytes, _ := json.Marshal(map[string]string{"temp_token": tempToken, "code": code})
+	req := httptest.NewRequest(http.MethodPost, "/2fa/verify", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429 after too many attempts, got %d", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter == "" || retryAfter == "0" {
+		t.Errorf("Expected a Retry-After header, got %q", retryAfter)
+	}
+	if status := postJSON(t, app, "/admin/2fa/backup-codes", map[string]string{"code": code}); status != http.StatusTooManyRequests {
+		t.Errorf("Expected backup code regeneration to be locked out too, got %d", status)
+	}
+}
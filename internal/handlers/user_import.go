@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"central-logs/internal/models"
+	"central-logs/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxImportUsers caps how many users one import request may create
+const maxImportUsers = 500
+
+// ImportUserResult reports what happened to one row of an import. Rows are
+// numbered from 1 in the order they were sent, not counting a CSV header.
+type ImportUserResult struct {
+	Row               int          `json:"row"`
+	Username          string       `json:"username"`
+	Success           bool         `json:"success"`
+	Error             string       `json:"error,omitempty"`
+	User              *models.User `json:"user,omitempty"`
+	GeneratedPassword string       `json:"generated_password,omitempty"` // Only returned here; it is not stored in plain text
+}
+
+type ImportUsersResponse struct {
+	Results []ImportUserResult `json:"results"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+}
+
+// ImportUsers handles POST /api/admin/users/import (Admin only). The body is
+// a JSON array of CreateUserRequest objects, or CSV (Content-Type: text/csv)
+// with a header row naming username, name and optionally password and role.
+// Each row is created on its own, so one bad row does not stop the rest.
+// Rows without a password get a random one, returned once in the result.
+func (h *UserHandler) ImportUsers(c *fiber.Ctx) error {
+	var (
+		rows []CreateUserRequest
+		err  error
+	)
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), "text/csv") {
+		rows, err = parseUserCSV(bytes.NewReader(c.Body()))
+	} else {
+		err = json.Unmarshal(c.Body(), &rows)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid import body: " + err.Error(),
+		})
+	}
+
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No users provided",
+		})
+	}
+	if len(rows) > maxImportUsers {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Maximum %d users per import", maxImportUsers),
+		})
+	}
+
+	resp := ImportUsersResponse{Results: make([]ImportUserResult, 0, len(rows))}
+	for i, req := range rows {
+		result := ImportUserResult{Row: i + 1, Username: models.NormalizeUsername(req.Username)}
+
+		if req.Password == "" {
+			generated, err := utils.GeneratePassword()
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to generate password",
+				})
+			}
+			req.Password = generated
+			result.GeneratedPassword = generated
+		}
+
+		user, ferr := h.createUser(c, req)
+		if ferr != nil {
+			result.Error = ferr.Message
+			result.GeneratedPassword = ""
+			resp.Failed++
+		} else {
+			result.Success = true
+			result.User = user
+			resp.Created++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	return c.JSON(resp)
+}
+
+// parseUserCSV reads import rows from CSV with a header row. Column names
+// are matched case-insensitively and unknown columns are ignored.
+func parseUserCSV(r io.Reader) ([]CreateUserRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["username"]; !ok {
+		return nil, errors.New("CSV header must include a username column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []CreateUserRequest
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, CreateUserRequest{
+			Username: field(record, "username"),
+			Password: field(record, "password"),
+			Name:     field(record, "name"),
+			Role:     models.UserRole(strings.ToUpper(field(record, "role"))),
+		})
+	}
+}
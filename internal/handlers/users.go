@@ -1,13 +1,24 @@
 package handlers
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"central-logs/internal/config"
+	"central-logs/internal/middleware"
 	"central-logs/internal/models"
+	"central-logs/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type UserHandler struct {
-	userRepo *models.UserRepository
+	userRepo         *models.UserRepository
+	audit            *AuditRecorder
+	jwtManager       *utils.JWTManager
+	impersonationTTL time.Duration
 }
 
 func NewUserHandler(userRepo *models.UserRepository) *UserHandler {
@@ -16,9 +27,63 @@ func NewUserHandler(userRepo *models.UserRepository) *UserHandler {
 	}
 }
 
-// ListUsers handles GET /api/admin/users (Admin only)
+// SetAuditRecorder records user management actions to the audit trail
+func (h *UserHandler) SetAuditRecorder(audit *AuditRecorder) {
+	h.audit = audit
+}
+
+// SetImpersonation enables POST /api/admin/users/:id/impersonate, issuing
+// tokens that last ttl
+func (h *UserHandler) SetImpersonation(jwtManager *utils.JWTManager, ttl time.Duration) {
+	h.jwtManager = jwtManager
+	h.impersonationTTL = ttl
+}
+
+// ListUsers handles GET /api/admin/users (Admin only). Supports search
+// (username, name or email), role, active, limit and offset query params.
+// inactive_since (e.g. "30d") lists users with no login in that long.
 func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
-	users, err := h.userRepo.GetAll()
+	filter := &models.UserFilter{
+		Search: c.Query("search"),
+		Limit:  50,
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		filter.Limit = l
+	}
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o > 0 {
+		filter.Offset = o
+	}
+
+	if role := strings.ToUpper(strings.TrimSpace(c.Query("role"))); role != "" {
+		filter.Role = models.UserRole(role)
+		if filter.Role != models.RoleAdmin && filter.Role != models.RoleUser {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid role. Must be ADMIN or USER",
+			})
+		}
+	}
+
+	if active := c.Query("active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid active value. Must be true or false",
+			})
+		}
+		filter.Active = &isActive
+	}
+
+	if since := c.Query("inactive_since"); since != "" {
+		d, err := config.ParseRetentionDuration(since)
+		if err != nil || d <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid inactive_since value. Use a duration like 30d or 12h",
+			})
+		}
+		filter.InactiveSince = time.Now().Add(-d)
+	}
+
+	users, total, err := h.userRepo.List(filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to list users",
@@ -26,7 +91,10 @@ func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"users": users,
+		"users":  users,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
 	})
 }
 
@@ -46,24 +114,32 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.Username == "" || req.Password == "" || req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Username, password, and name are required",
+	user, ferr := h.createUser(c, req)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{
+			"error": ferr.Message,
 		})
 	}
 
-	if len(req.Password) < 8 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Password must be at least 8 characters",
-		})
+	return c.Status(fiber.StatusCreated).JSON(user)
+}
+
+// createUser validates a create request and stores the user. It is shared by
+// single creates and imports so both apply the same rules.
+func (h *UserHandler) createUser(c *fiber.Ctx, req CreateUserRequest) (*models.User, *fiber.Error) {
+	req.Username = models.NormalizeUsername(req.Username)
+	if req.Username == "" || req.Password == "" || req.Name == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Username, password, and name are required")
 	}
 
-	// Check if username exists
-	existing, _ := h.userRepo.GetByUsername(req.Username)
-	if existing != nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error": "Username already in use",
-		})
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Check if username exists, counting soft-deleted users who still hold it
+	taken, _ := h.userRepo.UsernameTaken(req.Username)
+	if taken {
+		return nil, fiber.NewError(fiber.StatusConflict, "Username already in use")
 	}
 
 	// Validate role
@@ -80,12 +156,12 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 	}
 
 	if err := h.userRepo.Create(user); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create user",
-		})
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create user")
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(user)
+	h.audit.Record(c, models.AuditUserCreate, "user", user.ID, "role="+string(user.Role))
+
+	return user, nil
 }
 
 // GetUser handles GET /api/admin/users/:id (Admin only)
@@ -138,6 +214,8 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	previousRole, previousActive := user.Role, user.IsActive
+
 	if req.Name != "" {
 		user.Name = req.Name
 	}
@@ -156,24 +234,173 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	var changes []string
+	if user.Role != previousRole {
+		changes = append(changes, fmt.Sprintf("role=%s->%s", previousRole, user.Role))
+	}
+	if user.IsActive != previousActive {
+		changes = append(changes, fmt.Sprintf("is_active=%t", user.IsActive))
+	}
+	h.audit.Record(c, models.AuditUserUpdate, "user", user.ID, strings.Join(changes, " "))
+
 	return c.JSON(user)
 }
 
-// DeleteUser handles DELETE /api/admin/users/:id (Admin only)
+// DeleteUser handles DELETE /api/admin/users/:id (Admin only). The user is
+// soft-deleted and can be restored; pass purge=true to remove them for good.
 func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	userID := c.Params("id")
 
-	if err := h.userRepo.Delete(userID); err != nil {
+	if c.QueryBool("purge") {
+		if err := h.userRepo.Delete(userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to delete user",
+			})
+		}
+
+		h.audit.Record(c, models.AuditUserDelete, "user", userID, "purge")
+
+		return c.JSON(fiber.Map{
+			"message": "User purged",
+		})
+	}
+
+	deleted, err := h.userRepo.SoftDelete(userID)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete user",
 		})
 	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	h.audit.Record(c, models.AuditUserDelete, "user", userID, "")
 
 	return c.JSON(fiber.Map{
 		"message": "User deleted",
 	})
 }
 
+// RestoreUser handles POST /api/admin/users/:id/restore (Admin only)
+func (h *UserHandler) RestoreUser(c *fiber.Ctx) error {
+	userID := c.Params("id")
+
+	restored, err := h.userRepo.Restore(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to restore user",
+		})
+	}
+	if !restored {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Deleted user not found",
+		})
+	}
+
+	h.audit.Record(c, models.AuditUserRestore, "user", userID, "")
+
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get user",
+		})
+	}
+
+	return c.JSON(user)
+}
+
+// RevokeSessions handles POST /api/admin/users/:id/revoke-sessions (Admin
+// only). Every token issued to the user so far stops working; they have to
+// log in again.
+func (h *UserHandler) RevokeSessions(c *fiber.Ctx) error {
+	userID := c.Params("id")
+
+	revoked, err := h.userRepo.BumpTokenVersion(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke sessions",
+		})
+	}
+	if !revoked {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	h.audit.Record(c, models.AuditUserRevokeSessions, "user", userID, "")
+
+	return c.JSON(fiber.Map{
+		"message": "Sessions revoked successfully",
+	})
+}
+
+type ImpersonateResponse struct {
+	Token     string       `json:"token"`
+	User      *models.User `json:"user"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// Impersonate handles POST /api/admin/users/:id/impersonate (Admin only). It
+// returns a short-lived token that signs the admin in as the user, for
+// reproducing what they see. Other admins can't be impersonated, and the
+// token can't change the user's password, profile, 2FA or project keys.
+func (h *UserHandler) Impersonate(c *fiber.Ctx) error {
+	if h.jwtManager == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Impersonation is not enabled",
+		})
+	}
+
+	admin := middleware.GetUser(c)
+	userID := c.Params("id")
+	if admin.ID == userID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot impersonate yourself",
+		})
+	}
+
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get user",
+		})
+	}
+	if user == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+	if !user.IsActive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot impersonate an inactive user",
+		})
+	}
+	if user.IsAdmin() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Cannot impersonate an admin",
+		})
+	}
+
+	expiresAt := time.Now().Add(h.impersonationTTL)
+	token, err := h.jwtManager.GenerateImpersonation(user.ID, user.Email, string(user.Role), user.TokenVersion, admin.ID, h.impersonationTTL)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate token",
+		})
+	}
+
+	h.audit.Record(c, models.AuditUserImpersonate, "user", user.ID, "expires_in="+h.impersonationTTL.String())
+
+	return c.JSON(ImpersonateResponse{
+		Token:     token,
+		User:      user,
+		ExpiresAt: expiresAt,
+	})
+}
+
 type ResetPasswordRequest struct {
 	Password string `json:"password"`
 }
@@ -189,9 +416,9 @@ func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
 		})
 	}
 
-	if len(req.Password) < 8 {
+	if err := utils.ValidatePassword(req.Password); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Password must be at least 8 characters",
+			"error": err.Error(),
 		})
 	}
 
@@ -201,6 +428,8 @@ func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
 		})
 	}
 
+	h.audit.Record(c, models.AuditUserResetPassword, "user", userID, "")
+
 	return c.JSON(fiber.Map{
 		"message": "Password reset successfully",
 	})
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"central-logs/internal/handlers"
+	"central-logs/internal/middleware"
 	"central-logs/internal/models"
+	"central-logs/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 	_ "github.com/mattn/go-sqlite3"
@@ -34,6 +37,9 @@ func setupUserTestDB(t *testing.T) *sql.DB {
 			two_factor_secret TEXT DEFAULT '',
 			two_factor_enabled INTEGER DEFAULT 0,
 			backup_codes TEXT DEFAULT '',
+			deleted_at DATETIME,
+			last_login_at DATETIME,
+			token_version INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -88,6 +94,83 @@ func TestUserHandler_ListUsers_Success(t *testing.T) {
 	}
 }
 
+func TestUserHandler_ListUsers_Filters(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	userHandler := handlers.NewUserHandler(userRepo)
+
+	userRepo.Create(&models.User{Username: "alice", Email: "alice@example.com", Password: "password123", Name: "Alice Admin", Role: models.RoleAdmin, IsActive: true})
+	userRepo.Create(&models.User{Username: "bob", Email: "bob@corp.test", Password: "password123", Name: "Bob", Role: models.RoleUser, IsActive: true})
+	userRepo.Create(&models.User{Username: "carol", Email: "carol@corp.test", Password: "password123", Name: "Carol", Role: models.RoleUser, IsActive: false})
+
+	app := fiber.New()
+	app.Get("/users", userHandler.ListUsers)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantUsers []string
+		wantTotal int
+	}{
+		{"search username", "?search=ALI", []string{"alice"}, 1},
+		{"search email", "?search=corp.test", []string{"bob", "carol"}, 2},
+		{"search name", "?search=admin", []string{"alice"}, 1},
+		{"role", "?role=user", []string{"bob", "carol"}, 2},
+		{"role and active", "?role=USER&active=true", []string{"bob"}, 1},
+		{"inactive", "?active=false", []string{"carol"}, 1},
+		{"paged", "?limit=1&offset=1", nil, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+			}
+
+			var response struct {
+				Users []models.User `json:"users"`
+				Total int           `json:"total"`
+				Limit int           `json:"limit"`
+			}
+			body, _ := io.ReadAll(resp.Body)
+			json.Unmarshal(body, &response)
+
+			if response.Total != tt.wantTotal {
+				t.Errorf("Expected total %d, got %d", tt.wantTotal, response.Total)
+			}
+			if tt.wantUsers == nil {
+				if len(response.Users) != 1 || response.Limit != 1 {
+					t.Errorf("Expected a single-user page, got %d users (limit %d)", len(response.Users), response.Limit)
+				}
+				return
+			}
+			got := map[string]bool{}
+			for _, u := range response.Users {
+				got[u.Username] = true
+			}
+			if len(got) != len(tt.wantUsers) {
+				t.Errorf("Expected %v, got %s", tt.wantUsers, body)
+			}
+			for _, name := range tt.wantUsers {
+				if !got[name] {
+					t.Errorf("Expected %s in results, got %s", name, body)
+				}
+			}
+		})
+	}
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/users?role=OWNER", nil))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown role, got %d", resp.StatusCode)
+	}
+}
+
 func TestUserHandler_CreateUser_Success(t *testing.T) {
 	db := setupUserTestDB(t)
 	defer db.Close()
@@ -271,6 +354,43 @@ func TestUserHandler_CreateUser_EmailAlreadyExists(t *testing.T) {
 	}
 }
 
+func TestUserHandler_CreateUser_UsernameDiffersOnlyInCase(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	userHandler := handlers.NewUserHandler(userRepo)
+
+	userRepo.Create(&models.User{
+		Username: "existing",
+		Password: "password123",
+		Name:     "Existing User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	})
+
+	app := fiber.New()
+	app.Post("/users", userHandler.CreateUser)
+
+	for _, username := range []string{"Existing", " EXISTING "} {
+		bodyBytes, _ := json.Marshal(map[string]string{
+			"username": username,
+			"password": "password123",
+			"name":     "New User",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected status 409 for %q, got %d", username, resp.StatusCode)
+		}
+	}
+}
+
 func TestUserHandler_CreateUser_DefaultRole(t *testing.T) {
 	db := setupUserTestDB(t)
 	defer db.Close()
@@ -567,6 +687,73 @@ func TestUserHandler_DeleteUser_Success(t *testing.T) {
 	}
 }
 
+func TestUserHandler_DeleteUser_RestoreAndPurge(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	userHandler := handlers.NewUserHandler(userRepo)
+
+	user := &models.User{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+		Name:     "Test User",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	userRepo.Create(user)
+
+	app := fiber.New()
+	app.Post("/users", userHandler.CreateUser)
+	app.Delete("/users/:id", userHandler.DeleteUser)
+	app.Post("/users/:id/restore", userHandler.RestoreUser)
+
+	do := func(method, path, body string) int {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := do(http.MethodDelete, "/users/"+user.ID, ""); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if deleted, _ := userRepo.GetByUsername("testuser"); deleted != nil {
+		t.Error("Soft-deleted user should not be found")
+	}
+
+	// The username stays reserved while the user can still be restored
+	if status := do(http.MethodPost, "/users", `{"username":"testuser","password":"password123","name":"Again"}`); status != http.StatusConflict {
+		t.Errorf("Expected status 409 for a soft-deleted username, got %d", status)
+	}
+
+	if status := do(http.MethodPost, "/users/"+user.ID+"/restore", ""); status != http.StatusOK {
+		t.Fatalf("Expected restore to return 200, got %d", status)
+	}
+	restored, _ := userRepo.GetByID(user.ID)
+	if restored == nil || !restored.CheckPassword("password123") {
+		t.Fatal("Restored user should be back with the same credentials")
+	}
+	if status := do(http.MethodPost, "/users/"+user.ID+"/restore", ""); status != http.StatusNotFound {
+		t.Errorf("Expected restoring a live user to return 404, got %d", status)
+	}
+
+	if status := do(http.MethodDelete, "/users/"+user.ID+"?purge=true", ""); status != http.StatusOK {
+		t.Fatalf("Expected purge to return 200, got %d", status)
+	}
+	if status := do(http.MethodPost, "/users/"+user.ID+"/restore", ""); status != http.StatusNotFound {
+		t.Errorf("Expected a purged user to be unrecoverable, got %d", status)
+	}
+	if taken, _ := userRepo.UsernameTaken("testuser"); taken {
+		t.Error("Purged user should free the username")
+	}
+}
+
 func TestUserHandler_ResetPassword_Success(t *testing.T) {
 	db := setupUserTestDB(t)
 	defer db.Close()
@@ -683,3 +870,224 @@ func TestUserHandler_ResetPassword_PasswordTooShort(t *testing.T) {
 		t.Errorf("Expected status 400, got %d", resp.StatusCode)
 	}
 }
+
+func TestUserHandler_ImportUsers_JSON(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	userHandler := handlers.NewUserHandler(userRepo)
+
+	userRepo.Create(&models.User{Username: "taken", Password: "password123", Name: "Taken", Role: models.RoleUser, IsActive: true})
+
+	app := fiber.New()
+	app.Post("/users/import", userHandler.ImportUsers)
+
+	body := `[
+		{"username": "alice", "password": "password123", "name": "Alice", "role": "ADMIN"},
+		{"username": "bob", "name": "Bob"},
+		{"username": "Taken", "password": "password123", "name": "Dup"},
+		{"username": "carol", "password": "short", "name": "Carol"},
+		{"username": "alice", "password": "password123", "name": "Alice again"}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result handlers.ImportUsersResponse
+	respBody, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(respBody, &result)
+
+	if result.Created != 2 || result.Failed != 3 || len(result.Results) != 5 {
+		t.Fatalf("Expected 2 created and 3 failed, got %s", respBody)
+	}
+
+	wantErrors := []string{"", "", "Username already in use", "Password must be at least 8 characters", "Username already in use"}
+	for i, r := range result.Results {
+		if r.Row != i+1 {
+			t.Errorf("Expected row %d, got %d", i+1, r.Row)
+		}
+		if r.Error != wantErrors[i] || r.Success != (wantErrors[i] == "") {
+			t.Errorf("Row %d: expected error %q, got success=%t error=%q", r.Row, wantErrors[i], r.Success, r.Error)
+		}
+	}
+
+	if result.Results[0].User == nil || result.Results[0].User.Role != models.RoleAdmin || result.Results[0].GeneratedPassword != "" {
+		t.Errorf("Expected alice created as admin without a generated password, got %+v", result.Results[0])
+	}
+
+	generated := result.Results[1].GeneratedPassword
+	if generated == "" {
+		t.Fatal("Expected a generated password for bob")
+	}
+	bob, _ := userRepo.GetByUsername("bob")
+	if bob == nil || !bob.CheckPassword(generated) {
+		t.Error("Expected bob to sign in with the generated password")
+	}
+}
+
+func TestUserHandler_ImportUsers_CSV(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	userHandler := handlers.NewUserHandler(userRepo)
+
+	app := fiber.New()
+	app.Post("/users/import", userHandler.ImportUsers)
+
+	body := "Username,Name,Role,Password\n" +
+		"dave,Dave,admin,password123\n" +
+		"erin,\"Erin, Ops\",,\n"
+	req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "text/csv")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	var result handlers.ImportUsersResponse
+	respBody, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(respBody, &result)
+
+	if result.Created != 2 {
+		t.Fatalf("Expected 2 users created, got %s", respBody)
+	}
+	if dave, _ := userRepo.GetByUsername("dave"); dave == nil || dave.Role != models.RoleAdmin {
+		t.Errorf("Expected dave to be an admin, got %+v", dave)
+	}
+	if erin, _ := userRepo.GetByUsername("erin"); erin == nil || erin.Name != "Erin, Ops" || erin.Role != models.RoleUser {
+		t.Errorf("Expected erin as a regular user named \"Erin, Ops\", got %+v", erin)
+	}
+	if result.Results[1].GeneratedPassword == "" {
+		t.Error("Expected a generated password for erin")
+	}
+}
+
+func TestUserHandler_ImportUsers_TooMany(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	userHandler := handlers.NewUserHandler(models.NewUserRepository(db))
+
+	app := fiber.New()
+	app.Post("/users/import", userHandler.ImportUsers)
+
+	rows := make([]handlers.CreateUserRequest, 501)
+	bodyBytes, _ := json.Marshal(rows)
+	req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestUserHandler_Impersonate(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	userRepo := models.NewUserRepository(db)
+	jwtManager := utils.NewJWTManager("test-secret", 24*time.Hour)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+	userHandler := handlers.NewUserHandler(userRepo)
+	userHandler.SetImpersonation(jwtManager, 10*time.Minute)
+
+	admin := &models.User{Username: "admin", Password: "password123", Name: "Admin", Role: models.RoleAdmin, IsActive: true}
+	target := &models.User{Username: "target", Password: "password123", Name: "Target", Role: models.RoleUser, IsActive: true}
+	otherAdmin := &models.User{Username: "other", Password: "password123", Name: "Other", Role: models.RoleAdmin, IsActive: true}
+	for _, u := range []*models.User{admin, target, otherAdmin} {
+		if err := userRepo.Create(u); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+	adminToken, _ := jwtManager.Generate(admin.ID, admin.Email, string(admin.Role))
+
+	app := fiber.New()
+	protected := app.Group("", authMiddleware.RequireAuth())
+	protected.Post("/users/:id/impersonate", authMiddleware.RequireAdmin(), userHandler.Impersonate)
+	protected.Get("/me", func(c *fiber.Ctx) error {
+		resp := fiber.Map{"user_id": middleware.GetUser(c).ID}
+		if impersonator := middleware.GetImpersonator(c); impersonator != nil {
+			resp["impersonator_id"] = impersonator.ID
+		}
+		return c.JSON(resp)
+	})
+	protected.Put("/me", authMiddleware.BlockImpersonation(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	do := func(method, path, token string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	if resp := do(http.MethodPost, "/users/"+otherAdmin.ID+"/impersonate", adminToken); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 impersonating an admin, got %d", resp.StatusCode)
+	}
+
+	resp := do(http.MethodPost, "/users/"+target.ID+"/impersonate", adminToken)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var issued handlers.ImpersonateResponse
+	json.NewDecoder(resp.Body).Decode(&issued)
+	if issued.User == nil || issued.User.ID != target.ID {
+		t.Fatalf("Expected the response to describe the target user, got %+v", issued.User)
+	}
+	if until := time.Until(issued.ExpiresAt); until <= 0 || until > 10*time.Minute {
+		t.Errorf("Expected the token to expire within 10m, got %v", until)
+	}
+
+	claims, err := jwtManager.Validate(issued.Token)
+	if err != nil {
+		t.Fatalf("Failed to validate impersonation token: %v", err)
+	}
+	if claims.UserID != target.ID || claims.ImpersonatedBy != admin.ID {
+		t.Errorf("Expected claims for %s impersonated by %s, got user %s impersonated by %q", target.ID, admin.ID, claims.UserID, claims.ImpersonatedBy)
+	}
+
+	// The token signs in as the target, with the admin surfaced alongside
+	resp = do(http.MethodGet, "/me", issued.Token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var me map[string]string
+	json.NewDecoder(resp.Body).Decode(&me)
+	if me["user_id"] != target.ID || me["impersonator_id"] != admin.ID {
+		t.Errorf("Expected user %s impersonated by %s, got %v", target.ID, admin.ID, me)
+	}
+
+	if resp := do(http.MethodPut, "/me", issued.Token); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a blocked action while impersonating, got %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodPut, "/me", adminToken); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected the blocked action to be allowed without impersonation, got %d", resp.StatusCode)
+	}
+
+	// Demoting the admin ends the impersonation
+	admin.Role = models.RoleUser
+	if err := userRepo.Update(admin); err != nil {
+		t.Fatalf("Failed to update admin: %v", err)
+	}
+	if resp := do(http.MethodGet, "/me", issued.Token); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 once the admin is demoted, got %d", resp.StatusCode)
+	}
+}
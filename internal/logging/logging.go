@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Formats for the server's operational log
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Setup points the standard logger, which all of the server's operational
+// logging goes through, at out in format. Text keeps the standard logger's
+// own layout; JSON writes one object per line. It is meant to be called once
+// at startup, before anything else logs.
+func Setup(format string, out io.Writer) {
+	if format != FormatJSON {
+		log.SetOutput(out)
+		return
+	}
+	// Lshortfile gives the writer the caller; it adds the time itself
+	log.SetFlags(log.Lshortfile)
+	log.SetOutput(NewJSONWriter(out))
+}
+
+type entry struct {
+	Timestamp string            `json:"timestamp"`
+	Level     string            `json:"level"` // info, warn or error
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// JSONWriter turns each line the standard logger writes into a JSON object
+// with a timestamp, a level guessed from the message, the message and, when
+// the logger has Lshortfile set, a caller field
+type JSONWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	now     func() time.Time
+}
+
+// NewJSONWriter creates a JSONWriter writing to out
+func NewJSONWriter(out io.Writer) *JSONWriter {
+	return &JSONWriter{encoder: json.NewEncoder(out), now: time.Now}
+}
+
+// Write encodes p, one call of the standard logger, as a single JSON line
+func (w *JSONWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSuffix(string(p), "\n")
+	e := entry{Timestamp: w.now().UTC().Format(time.RFC3339Nano)}
+
+	if caller, rest, ok := strings.Cut(message, ": "); ok && isCaller(caller) {
+		e.Fields = map[string]string{"caller": caller}
+		message = rest
+	}
+	e.Level, e.Message = levelOf(message)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.encoder.Encode(e); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// isCaller reports whether s is Lshortfile's "file.go:123"
+func isCaller(s string) bool {
+	file, line, ok := strings.Cut(s, ".go:")
+	if !ok || file == "" || line == "" || strings.ContainsAny(file, " \t") {
+		return false
+	}
+	for _, r := range line {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// levelOf guesses a message's level from the wording the server's log calls
+// use: a "Warning:" prefix, which is dropped, or a mention of a failure or
+// error. Anything else is info.
+func levelOf(message string) (level, rest string) {
+	for _, prefix := range []string{"Warning: ", "WARNING: "} {
+		if strings.HasPrefix(message, prefix) {
+			return "warn", strings.TrimPrefix(message, prefix)
+		}
+	}
+	lower := strings.ToLower(message)
+	if strings.Contains(lower, "fail") || strings.Contains(lower, "error") || strings.Contains(lower, "panic") {
+		return "error", message
+	}
+	return "info", message
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewJSONWriter(&out)
+	writer.now = func() time.Time { return time.Date(2026, 1, 12, 9, 14, 3, 0, time.FixedZone("WIB", 7*3600)) }
+
+	logger := log.New(writer, "", log.Lshortfile)
+	logger.Printf("Warning: Failed to connect to Redis: %v", "dial tcp: refused")
+	logger.Printf("Database maintenance reclaimed %d bytes", 4096)
+	logger.Printf("Outbound webhook for log %s failed after %d attempts", "log-1", 3)
+	// Without Lshortfile there is no caller, and "x: y" stays in the message
+	log.New(writer, "", 0).Print("Migrating: 20250201000001_create_users_table\nsecond line")
+
+	var entries []entry
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var e entry
+		if err := decoder.Decode(&e); err != nil {
+			t.Fatalf("Expected one JSON object per line: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(entries))
+	}
+
+	want := []struct {
+		level, message string
+		caller         bool
+	}{
+		{"warn", "Failed to connect to Redis: dial tcp: refused", true},
+		{"info", "Database maintenance reclaimed 4096 bytes", true},
+		{"error", "Outbound webhook for log log-1 failed after 3 attempts", true},
+		{"info", "Migrating: 20250201000001_create_users_table\nsecond line", false},
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Timestamp != "2026-01-12T02:14:03Z" {
+			t.Errorf("Entry %d: expected the time in UTC, got %q", i, e.Timestamp)
+		}
+		if e.Level != w.level || e.Message != w.message {
+			t.Errorf("Entry %d: expected %s %q, got %s %q", i, w.level, w.message, e.Level, e.Message)
+		}
+		if caller := e.Fields["caller"]; (caller != "") != w.caller {
+			t.Errorf("Entry %d: unexpected caller %q", i, caller)
+		}
+	}
+	if caller := entries[0].Fields["caller"]; !strings.HasPrefix(caller, "logging_test.go:") {
+		t.Errorf("Expected the caller to be this file, got %q", caller)
+	}
+}
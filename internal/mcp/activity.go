@@ -16,6 +16,7 @@ func LogActivity(
 	requestParams map[string]interface{},
 	success bool,
 	errorMessage string,
+	requestID string,
 	startTime time.Time,
 ) error {
 	duration := time.Since(startTime).Milliseconds()
@@ -35,6 +36,7 @@ func LogActivity(
 		Success:       success,
 		ErrorMessage:  errorMessage,
 		DurationMS:    int(duration),
+		RequestID:     requestID,
 	}
 
 	return activityRepo.Create(log)
@@ -1,11 +1,14 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
 
 	"central-logs/internal/models"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 var (
@@ -16,6 +19,26 @@ var (
 	ErrProjectAccess     = errors.New("access denied to project")
 )
 
+// tokenContextKey is the context key for the authenticated MCP token. Being an
+// unexported type, no other package can read or overwrite the value.
+type tokenContextKey struct{}
+
+// WithToken returns a copy of ctx carrying the authenticated MCP token
+func WithToken(ctx context.Context, token *models.MCPToken) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// TokenFromContext returns the MCP token stored by WithToken
+func TokenFromContext(ctx context.Context) (*models.MCPToken, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(*models.MCPToken)
+	return token, ok && token != nil
+}
+
+// authErrorResult is returned by every tool when no token is in the context
+func authErrorResult() *mcp.CallToolResult {
+	return mcp.NewToolResultError("Authentication error: missing MCP token")
+}
+
 // ValidateMCPToken validates an MCP token from the Authorization header
 // Returns the token if valid, or an error
 func ValidateMCPToken(authHeader string, mcpTokenRepo *models.MCPTokenRepository) (*models.MCPToken, error) {
@@ -3,10 +3,13 @@ package mcp
 import (
 	"bytes"
 	"context"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"time"
 
+	"central-logs/internal/middleware"
 	"central-logs/internal/models"
 
 	"github.com/gofiber/fiber/v2"
@@ -23,6 +26,10 @@ type MCPServer struct {
 	logRepo         *models.LogRepository
 	projectRepo     *models.ProjectRepository
 	userRepo        *models.UserRepository
+	auditRepo       *models.AuditLogRepository // Optional; write tools are audited when set
+	queryBounds     models.LogQueryBounds
+	statsLocation   *time.Location // Default zone for logs_today in get_stats
+	activityWG      sync.WaitGroup // Tracks in-flight activity log writes
 }
 
 // NewMCPServer creates a new MCP server instance
@@ -39,6 +46,8 @@ func NewMCPServer(
 		logRepo:         logRepo,
 		projectRepo:     projectRepo,
 		userRepo:        userRepo,
+		queryBounds:     models.LogQueryBounds{MaxLimit: 1000},
+		statsLocation:   time.UTC,
 	}
 
 	// Create MCP server with server info
@@ -63,42 +72,33 @@ func NewMCPServer(
 	return mcpServer
 }
 
+// SetQueryBounds sets the default time range and page size cap applied by
+// the log query tools
+func (s *MCPServer) SetQueryBounds(bounds models.LogQueryBounds) {
+	s.queryBounds = bounds
+}
+
+// SetStatsLocation sets the zone get_stats uses for logs_today when the call
+// does not name one
+func (s *MCPServer) SetStatsLocation(loc *time.Location) {
+	s.statsLocation = loc
+}
+
+// SetAuditLogRepository records changes made through write tools in the
+// audit trail
+func (s *MCPServer) SetAuditLogRepository(repo *models.AuditLogRepository) {
+	s.auditRepo = repo
+}
+
 // registerTools registers all MCP tools
 func (s *MCPServer) registerTools(srv *server.MCPServer) {
 	// Tool 1: query_logs - Search and filter logs
-	queryLogsTool := mcp.NewTool("query_logs",
+	queryLogsTool := mcp.NewTool("query_logs", append(logFilterOptions(),
 		mcp.WithDescription("Search and filter logs with advanced criteria including project IDs, levels, source, time range, and full-text search"),
-		mcp.WithArray("project_ids",
-			mcp.WithStringItems(
-				mcp.Description("Project ID"),
-			),
-			mcp.Description("Filter by project IDs (optional)"),
-		),
-		mcp.WithArray("levels",
-			mcp.WithStringItems(
-				mcp.Enum("debug", "info", "warn", "error"),
-			),
-			mcp.Description("Filter by log levels: debug, info, warn, error (optional)"),
-		),
-		mcp.WithString("source",
-			mcp.Description("Filter by log source (optional)"),
-		),
-		mcp.WithString("search",
-			mcp.Description("Full-text search in message and metadata (optional)"),
-		),
-		mcp.WithString("start_time",
-			mcp.Description("Start time in RFC3339 format (optional)"),
-		),
-		mcp.WithString("end_time",
-			mcp.Description("End time in RFC3339 format (optional)"),
-		),
 		mcp.WithNumber("limit",
-			mcp.Description("Number of logs to return (default: 100, max: 1000)"),
+			mcp.Description("Number of logs to return (default: 100; capped by the server, 1000 unless configured otherwise)"),
 		),
-		mcp.WithNumber("offset",
-			mcp.Description("Offset for pagination (default: 0)"),
-		),
-	)
+	)...)
 	srv.AddTool(queryLogsTool, s.handleQueryLogs)
 
 	// Tool 2: get_log - Retrieve single log by ID
@@ -113,7 +113,19 @@ func (s *MCPServer) registerTools(srv *server.MCPServer) {
 
 	// Tool 3: list_projects - List accessible projects
 	listProjectsTool := mcp.NewTool("list_projects",
-		mcp.WithDescription("List all projects accessible by the MCP token"),
+		mcp.WithDescription("List projects accessible by the MCP token, optionally filtered by name and active status"),
+		mcp.WithString("name_contains",
+			mcp.Description("Only projects whose name contains this text, case-insensitive (optional)"),
+		),
+		mcp.WithBoolean("active_only",
+			mcp.Description("Only active projects (optional, default: false)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of projects to return (default: 50, max: 200)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Offset for pagination (default: 0)"),
+		),
 	)
 	srv.AddTool(listProjectsTool, s.handleListProjects)
 
@@ -138,6 +150,9 @@ func (s *MCPServer) registerTools(srv *server.MCPServer) {
 		mcp.WithString("project_id",
 			mcp.Description("Required if scope is 'project'"),
 		),
+		mcp.WithString("timezone",
+			mcp.Description("IANA time zone whose midnight starts logs_today, e.g. Asia/Jakarta (default: server setting)"),
+		),
 	)
 	srv.AddTool(getStatsTool, s.handleGetStats)
 
@@ -161,7 +176,7 @@ func (s *MCPServer) registerTools(srv *server.MCPServer) {
 			mcp.Description("Filter by log levels (optional)"),
 		),
 		mcp.WithNumber("limit",
-			mcp.Description("Number of results to return (default: 100, max: 1000)"),
+			mcp.Description("Number of results to return (default: 100; capped by the server, 1000 unless configured otherwise)"),
 		),
 	)
 	srv.AddTool(searchLogsTool, s.handleSearchLogs)
@@ -180,6 +195,157 @@ func (s *MCPServer) registerTools(srv *server.MCPServer) {
 		),
 	)
 	srv.AddTool(getRecentLogsTool, s.handleGetRecentLogs)
+
+	// Tool 8: compare_log_volume - Compare log counts between two time windows
+	compareLogVolumeTool := mcp.NewTool("compare_log_volume",
+		mcp.WithDescription("Compare how many logs arrived in a baseline window versus a current window (e.g. errors yesterday vs today) and report the percentage change"),
+		mcp.WithArray("project_ids",
+			mcp.WithStringItems(
+				mcp.Description("Project ID"),
+			),
+			mcp.Description("Filter by project IDs (optional, defaults to all accessible projects)"),
+		),
+		mcp.WithString("level",
+			mcp.Enum("debug", "info", "warn", "error", "critical"),
+			mcp.Description("Only count logs of this level (optional)"),
+		),
+		mcp.WithString("baseline_start",
+			mcp.Required(),
+			mcp.Description("Start of the baseline window in RFC3339 format"),
+		),
+		mcp.WithString("baseline_end",
+			mcp.Required(),
+			mcp.Description("End of the baseline window in RFC3339 format"),
+		),
+		mcp.WithString("current_start",
+			mcp.Required(),
+			mcp.Description("Start of the window to compare in RFC3339 format"),
+		),
+		mcp.WithString("current_end",
+			mcp.Required(),
+			mcp.Description("End of the window to compare in RFC3339 format"),
+		),
+		mcp.WithString("time_field",
+			mcp.Enum(models.LogTimeFieldTimestamp, models.LogTimeFieldCreatedAt),
+			mcp.Description("Which time the windows apply to: timestamp (event time, default) or created_at (ingestion time)"),
+		),
+	)
+	srv.AddTool(compareLogVolumeTool, s.handleCompareLogVolume)
+
+	// Tool 9: get_log_context - Logs surrounding a given log
+	getLogContextTool := mcp.NewTool("get_log_context",
+		mcp.WithDescription("Get the logs immediately before and after a given log in the same project, ordered by timestamp"),
+		mcp.WithString("log_id",
+			mcp.Required(),
+			mcp.Description("The log ID to get context for"),
+		),
+		mcp.WithNumber("window",
+			mcp.Description("Number of logs to return on each side (default: 10, max: 100)"),
+		),
+	)
+	srv.AddTool(getLogContextTool, s.handleGetLogContext)
+
+	// Tool 10: get_error_clusters - Top recurring errors
+	getErrorClustersTool := mcp.NewTool("get_error_clusters",
+		mcp.WithDescription("Find the top recurring errors: recent ERROR and CRITICAL logs grouped by message pattern (numbers, UUIDs and hex IDs masked), with counts, a sample message and first/last seen times"),
+		mcp.WithArray("project_ids",
+			mcp.WithStringItems(
+				mcp.Description("Project ID"),
+			),
+			mcp.Description("Filter by project IDs (optional, defaults to all accessible projects)"),
+		),
+		mcp.WithNumber("hours",
+			mcp.Description("How far back to look, in hours (default: 24, max: 168)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of clusters to return (default: 10, max: 50)"),
+		),
+	)
+	srv.AddTool(getErrorClustersTool, s.handleGetErrorClusters)
+
+	// Tool 11: get_project_health - One-call project health snapshot
+	getProjectHealthTool := mcp.NewTool("get_project_health",
+		mcp.WithDescription("Get a one-call health snapshot of a project: total logs, logs in the last hour and day, the error rate over the last 24h versus the 24h before (rising, falling or steady), the top 3 error sources and error patterns, and the newest CRITICAL log"),
+		mcp.WithString("project_id",
+			mcp.Required(),
+			mcp.Description("The project ID to check"),
+		),
+	)
+	srv.AddTool(getProjectHealthTool, s.handleGetProjectHealth)
+
+	// Tool 12: resolve_project - Look up a project's ID by its name
+	resolveProjectTool := mcp.NewTool("resolve_project",
+		mcp.WithDescription("Find accessible projects by name, case-insensitive: an exact name or the start of one. Returns each match's ID, exact matches first; when ambiguous is true, ask the user which one they mean or pick by ID"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("The project name, or the start of it"),
+		),
+	)
+	srv.AddTool(resolveProjectTool, s.handleResolveProject)
+
+	// Tool 13: export_logs_text - query_logs as compact plain text
+	exportLogsTextTool := mcp.NewTool("export_logs_text", append(logFilterOptions(),
+		mcp.WithDescription("Return the logs query_logs would, newest first, as plain text with one \"timestamp LEVEL [source] message\" line per log. Uses far fewer tokens than query_logs; ends with a [truncated: ...] line when more logs match than max_lines"),
+		mcp.WithNumber("max_lines",
+			mcp.Description("Most logs to return (default: 200; capped by the server, 1000 unless configured otherwise)"),
+		),
+	)...)
+	srv.AddTool(exportLogsTextTool, s.handleExportLogsText)
+
+	// Tool 14: acknowledge_log - Set a log's status (write scope)
+	acknowledgeLogTool := mcp.NewTool("acknowledge_log",
+		mcp.WithDescription("Set a log's status, e.g. mark an error acknowledged once it is being looked at. Requires a token with write scope"),
+		mcp.WithString("log_id",
+			mcp.Required(),
+			mcp.Description("The ID of the log"),
+		),
+		mcp.WithString("status",
+			mcp.Enum(string(models.LogStatusAcknowledged), string(models.LogStatusResolved), string(models.LogStatusNew)),
+			mcp.Description("New status (default: acknowledged)"),
+		),
+		mcp.WithString("comment",
+			mcp.Description("Why the status changed (optional; kept in the audit trail, max 1000 characters)"),
+		),
+	)
+	srv.AddTool(acknowledgeLogTool, s.handleAcknowledgeLog)
+}
+
+// logFilterOptions are the filter parameters shared by query_logs and
+// export_logs_text
+func logFilterOptions() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithArray("project_ids",
+			mcp.WithStringItems(
+				mcp.Description("Project ID"),
+			),
+			mcp.Description("Filter by project IDs (optional)"),
+		),
+		mcp.WithArray("levels",
+			mcp.WithStringItems(
+				mcp.Enum("debug", "info", "warn", "error"),
+			),
+			mcp.Description("Filter by log levels: debug, info, warn, error (optional)"),
+		),
+		mcp.WithString("source",
+			mcp.Description("Filter by log source (optional)"),
+		),
+		mcp.WithString("search",
+			mcp.Description("Full-text search in message and metadata (optional)"),
+		),
+		mcp.WithString("start_time",
+			mcp.Description("Start time in RFC3339 format (optional; with neither start_time nor end_time only recent logs are returned, the last 7 days unless the server is configured otherwise)"),
+		),
+		mcp.WithString("end_time",
+			mcp.Description("End time in RFC3339 format (optional)"),
+		),
+		mcp.WithString("time_field",
+			mcp.Enum(models.LogTimeFieldTimestamp, models.LogTimeFieldCreatedAt),
+			mcp.Description("Which time start_time/end_time apply to: timestamp (event time, default) or created_at (ingestion time)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Offset for pagination (default: 0)"),
+		),
+	}
 }
 
 // HandleFiberRequest handles incoming Fiber HTTP requests for MCP
@@ -197,11 +363,11 @@ func (s *MCPServer) HandleFiberRequest(c *fiber.Ctx) error {
 	go s.mcpTokenRepo.UpdateLastUsed(token.ID)
 
 	// Store token in request context for tool handlers
-	ctx := context.WithValue(c.UserContext(), "mcp_token", token)
+	ctx := WithToken(c.UserContext(), token)
 	c.SetUserContext(ctx)
 
 	// Convert Fiber request to http.Request
-	req := c.Context().Request
+	req := &c.Context().Request
 	httpReq, err := http.NewRequestWithContext(ctx, string(req.Header.Method()), string(req.URI().FullURI()), bytes.NewReader(req.Body()))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -236,6 +402,7 @@ func (s *MCPServer) HandleFiberRequest(c *fiber.Ctx) error {
 
 // logToolActivity logs MCP tool activity
 func (s *MCPServer) logToolActivity(
+	ctx context.Context,
 	token *models.MCPToken,
 	toolName string,
 	projectIDs []string,
@@ -244,8 +411,12 @@ func (s *MCPServer) logToolActivity(
 	errorMessage string,
 	startTime time.Time,
 ) {
+	requestID := middleware.RequestIDFromContext(ctx)
+
 	// Log asynchronously to avoid blocking
+	s.activityWG.Add(1)
 	go func() {
+		defer s.activityWG.Done()
 		params := ConvertParamsToMap(args)
 		_ = LogActivity(
 			s.mcpActivityRepo,
@@ -255,7 +426,34 @@ func (s *MCPServer) logToolActivity(
 			params,
 			success,
 			errorMessage,
+			requestID,
 			startTime,
 		)
 	}()
 }
+
+// recordAudit writes a change made through a write tool to the audit trail,
+// with the token's creator as the actor. Like the admin API's audit, it is
+// best-effort: a failed write is logged and the tool call still succeeds.
+func (s *MCPServer) recordAudit(token *models.MCPToken, action, targetType, targetID, details string) {
+	if s.auditRepo == nil {
+		return
+	}
+
+	entry := &models.AuditLog{
+		ActorID:    token.CreatedBy,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    details,
+	}
+	if s.userRepo != nil {
+		if user, err := s.userRepo.GetByID(token.CreatedBy); err == nil && user != nil {
+			entry.ActorName = user.Username
+		}
+	}
+
+	if err := s.auditRepo.Create(entry); err != nil {
+		log.Printf("[MCP] Failed to record %s on %s %s: %v", action, targetType, targetID, err)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"central-logs/internal/models"
@@ -16,39 +19,39 @@ func (s *MCPServer) handleGetLog(ctx context.Context, request mcp.CallToolReques
 	startTime := time.Now()
 
 	// Extract token from context
-	token, ok := ctx.Value("mcp_token").(*models.MCPToken)
+	token, ok := TokenFromContext(ctx)
 	if !ok {
-		return mcp.NewToolResultError("Authentication error"), nil
+		return authErrorResult(), nil
 	}
 
 	// Get log_id parameter
 	logID, err := request.RequireString("log_id")
 	if err != nil {
-		s.logToolActivity(token, "get_log", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_log", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
 	}
 
 	// Retrieve log
 	log, err := s.logRepo.GetByID(logID)
 	if err != nil {
-		s.logToolActivity(token, "get_log", nil, nil, false, fmt.Sprintf("Failed to retrieve log: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_log", nil, nil, false, fmt.Sprintf("Failed to retrieve log: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to retrieve log: %v", err)), nil
 	}
 
 	if log == nil {
-		s.logToolActivity(token, "get_log", nil, nil, false, "Log not found", startTime)
+		s.logToolActivity(ctx, token, "get_log", nil, nil, false, "Log not found", startTime)
 		return mcp.NewToolResultError("Log not found"), nil
 	}
 
 	// Check if token has access to this log's project
 	hasAccess, err := token.HasAccessToProject(log.ProjectID)
 	if err != nil {
-		s.logToolActivity(token, "get_log", nil, nil, false, fmt.Sprintf("Access check failed: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_log", nil, nil, false, fmt.Sprintf("Access check failed: %v", err), startTime)
 		return mcp.NewToolResultError("Access check failed"), nil
 	}
 
 	if !hasAccess {
-		s.logToolActivity(token, "get_log", []string{log.ProjectID}, nil, false, "Access denied to this log's project", startTime)
+		s.logToolActivity(ctx, token, "get_log", []string{log.ProjectID}, nil, false, "Access denied to this log's project", startTime)
 		return mcp.NewToolResultError("Access denied to this log's project"), nil
 	}
 
@@ -59,12 +62,12 @@ func (s *MCPServer) handleGetLog(ctx context.Context, request mcp.CallToolReques
 
 	result, err := mcp.NewToolResultJSON(output)
 	if err != nil {
-		s.logToolActivity(token, "get_log", []string{log.ProjectID}, nil, false, fmt.Sprintf("Failed to serialize result: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_log", []string{log.ProjectID}, nil, false, fmt.Sprintf("Failed to serialize result: %v", err), startTime)
 		return mcp.NewToolResultError("Failed to serialize result"), nil
 	}
 
 	// Log success
-	s.logToolActivity(token, "get_log", []string{log.ProjectID}, map[string]interface{}{"log_id": logID}, true, "", startTime)
+	s.logToolActivity(ctx, token, "get_log", []string{log.ProjectID}, map[string]interface{}{"log_id": logID}, true, "", startTime)
 
 	return result, nil
 }
@@ -74,54 +77,78 @@ func (s *MCPServer) handleListProjects(ctx context.Context, request mcp.CallTool
 	startTime := time.Now()
 
 	// Extract token from context
-	token, ok := ctx.Value("mcp_token").(*models.MCPToken)
+	token, ok := TokenFromContext(ctx)
 	if !ok {
-		return mcp.NewToolResultError("Authentication error"), nil
+		return authErrorResult(), nil
+	}
+
+	// Get optional parameters
+	nameContains := request.GetString("name_contains", "")
+	activeOnly := request.GetBool("active_only", false)
+	limit := request.GetInt("limit", 50)
+	offset := request.GetInt("offset", 0)
+
+	// Enforce limits
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
 	}
 
 	// Get granted project IDs
 	grantedProjects, allProjects, err := token.GetGrantedProjectIDs()
 	if err != nil {
-		s.logToolActivity(token, "list_projects", nil, nil, false, fmt.Sprintf("Failed to get granted projects: %v", err), startTime)
+		s.logToolActivity(ctx, token, "list_projects", nil, nil, false, fmt.Sprintf("Failed to get granted projects: %v", err), startTime)
 		return mcp.NewToolResultError("Failed to get granted projects"), nil
 	}
 
-	var projects []*models.Project
-
-	if allProjects {
-		// Get all projects
-		projects, err = s.projectRepo.GetAll()
-		if err != nil {
-			s.logToolActivity(token, "list_projects", nil, nil, false, fmt.Sprintf("Failed to list projects: %v", err), startTime)
-			return mcp.NewToolResultError("Failed to list projects"), nil
-		}
-	} else {
-		// Get only granted projects
-		for _, projectID := range grantedProjects {
-			project, err := s.projectRepo.GetByID(projectID)
-			if err != nil {
-				continue // Skip projects that can't be retrieved
-			}
-			if project != nil {
-				projects = append(projects, project)
-			}
+	filter := &models.ProjectFilter{
+		NameContains: nameContains,
+		ActiveOnly:   activeOnly,
+		Limit:        limit,
+		Offset:       offset,
+	}
+	if !allProjects {
+		// Restrict to granted projects; an empty grant matches nothing
+		filter.IDs = grantedProjects
+		if filter.IDs == nil {
+			filter.IDs = []string{}
 		}
 	}
 
+	projects, total, err := s.projectRepo.List(filter)
+	if err != nil {
+		s.logToolActivity(ctx, token, "list_projects", nil, nil, false, fmt.Sprintf("Failed to list projects: %v", err), startTime)
+		return mcp.NewToolResultError("Failed to list projects"), nil
+	}
+
 	// Convert to output format
 	output := &ListProjectsOutput{
 		Projects: projects,
 		Count:    len(projects),
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
 	}
 
 	result, err := mcp.NewToolResultJSON(output)
 	if err != nil {
-		s.logToolActivity(token, "list_projects", nil, nil, false, "Failed to serialize result", startTime)
+		s.logToolActivity(ctx, token, "list_projects", nil, nil, false, "Failed to serialize result", startTime)
 		return mcp.NewToolResultError("Failed to serialize result"), nil
 	}
 
 	// Log success
-	s.logToolActivity(token, "list_projects", nil, nil, true, "", startTime)
+	args := map[string]interface{}{
+		"name_contains": nameContains,
+		"active_only":   activeOnly,
+		"limit":         limit,
+		"offset":        offset,
+	}
+	s.logToolActivity(ctx, token, "list_projects", nil, args, true, "", startTime)
 
 	return result, nil
 }
@@ -131,39 +158,39 @@ func (s *MCPServer) handleGetProject(ctx context.Context, request mcp.CallToolRe
 	startTime := time.Now()
 
 	// Extract token from context
-	token, ok := ctx.Value("mcp_token").(*models.MCPToken)
+	token, ok := TokenFromContext(ctx)
 	if !ok {
-		return mcp.NewToolResultError("Authentication error"), nil
+		return authErrorResult(), nil
 	}
 
 	// Get project_id parameter
 	projectID, err := request.RequireString("project_id")
 	if err != nil {
-		s.logToolActivity(token, "get_project", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_project", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
 	}
 
 	// Check if token has access to this project
 	hasAccess, err := token.HasAccessToProject(projectID)
 	if err != nil {
-		s.logToolActivity(token, "get_project", nil, nil, false, fmt.Sprintf("Access check failed: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_project", nil, nil, false, fmt.Sprintf("Access check failed: %v", err), startTime)
 		return mcp.NewToolResultError("Access check failed"), nil
 	}
 
 	if !hasAccess {
-		s.logToolActivity(token, "get_project", []string{projectID}, nil, false, "Access denied to this project", startTime)
+		s.logToolActivity(ctx, token, "get_project", []string{projectID}, nil, false, "Access denied to this project", startTime)
 		return mcp.NewToolResultError("Access denied to this project"), nil
 	}
 
 	// Get project
 	project, err := s.projectRepo.GetByID(projectID)
 	if err != nil {
-		s.logToolActivity(token, "get_project", []string{projectID}, nil, false, fmt.Sprintf("Failed to get project: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_project", []string{projectID}, nil, false, fmt.Sprintf("Failed to get project: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get project: %v", err)), nil
 	}
 
 	if project == nil {
-		s.logToolActivity(token, "get_project", []string{projectID}, nil, false, "Project not found", startTime)
+		s.logToolActivity(ctx, token, "get_project", []string{projectID}, nil, false, "Project not found", startTime)
 		return mcp.NewToolResultError("Project not found"), nil
 	}
 
@@ -192,13 +219,13 @@ func (s *MCPServer) handleGetProject(ctx context.Context, request mcp.CallToolRe
 
 	result, err := mcp.NewToolResultJSON(output)
 	if err != nil {
-		s.logToolActivity(token, "get_project", []string{projectID}, nil, false, "Failed to serialize result", startTime)
+		s.logToolActivity(ctx, token, "get_project", []string{projectID}, nil, false, "Failed to serialize result", startTime)
 		return mcp.NewToolResultError("Failed to serialize result"), nil
 	}
 
 	// Log success
 	args := map[string]interface{}{"project_id": projectID}
-	s.logToolActivity(token, "get_project", []string{projectID}, args, true, "", startTime)
+	s.logToolActivity(ctx, token, "get_project", []string{projectID}, args, true, "", startTime)
 
 	return result, nil
 }
@@ -208,9 +235,9 @@ func (s *MCPServer) handleGetRecentLogs(ctx context.Context, request mcp.CallToo
 	startTime := time.Now()
 
 	// Extract token from context
-	token, ok := ctx.Value("mcp_token").(*models.MCPToken)
+	token, ok := TokenFromContext(ctx)
 	if !ok {
-		return mcp.NewToolResultError("Authentication error"), nil
+		return authErrorResult(), nil
 	}
 
 	// Get optional parameters
@@ -225,14 +252,14 @@ func (s *MCPServer) handleGetRecentLogs(ctx context.Context, request mcp.CallToo
 	// Validate project access
 	allowedProjects, err := ValidateProjectAccess(token, projectIDs)
 	if err != nil {
-		s.logToolActivity(token, "get_recent_logs", projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_recent_logs", projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
 		return mcp.NewToolResultError("Access denied to requested projects"), nil
 	}
 
 	// Get recent logs
 	logs, err := s.logRepo.GetRecent(allowedProjects, limit)
 	if err != nil {
-		s.logToolActivity(token, "get_recent_logs", allowedProjects, nil, false, fmt.Sprintf("Failed to retrieve logs: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_recent_logs", allowedProjects, nil, false, fmt.Sprintf("Failed to retrieve logs: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to retrieve logs: %v", err)), nil
 	}
 
@@ -243,13 +270,13 @@ func (s *MCPServer) handleGetRecentLogs(ctx context.Context, request mcp.CallToo
 
 	result, err := mcp.NewToolResultJSON(output)
 	if err != nil {
-		s.logToolActivity(token, "get_recent_logs", allowedProjects, nil, false, "Failed to serialize result", startTime)
+		s.logToolActivity(ctx, token, "get_recent_logs", allowedProjects, nil, false, "Failed to serialize result", startTime)
 		return mcp.NewToolResultError("Failed to serialize result"), nil
 	}
 
 	// Log success
 	args := map[string]interface{}{"project_ids": projectIDs, "limit": limit}
-	s.logToolActivity(token, "get_recent_logs", allowedProjects, args, true, "", startTime)
+	s.logToolActivity(ctx, token, "get_recent_logs", allowedProjects, args, true, "", startTime)
 
 	return result, nil
 }
@@ -259,11 +286,49 @@ func (s *MCPServer) handleQueryLogs(ctx context.Context, request mcp.CallToolReq
 	startTime := time.Now()
 
 	// Extract token from context
-	token, ok := ctx.Value("mcp_token").(*models.MCPToken)
+	token, ok := TokenFromContext(ctx)
 	if !ok {
-		return mcp.NewToolResultError("Authentication error"), nil
+		return authErrorResult(), nil
 	}
 
+	filter, args, errResult := s.logFilterFromRequest(ctx, token, "query_logs", request, "limit", 100, startTime)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Query logs
+	logs, total, err := s.logRepo.List(filter)
+	if err != nil {
+		s.logToolActivity(ctx, token, "query_logs", filter.ProjectIDs, nil, false, fmt.Sprintf("Failed to query logs: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query logs: %v", err)), nil
+	}
+
+	// Convert to output format
+	output := &QueryLogsOutput{
+		Logs:   logs,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	result, err := mcp.NewToolResultJSON(output)
+	if err != nil {
+		s.logToolActivity(ctx, token, "query_logs", filter.ProjectIDs, nil, false, "Failed to serialize result", startTime)
+		return mcp.NewToolResultError("Failed to serialize result"), nil
+	}
+
+	// Log success
+	s.logToolActivity(ctx, token, "query_logs", filter.ProjectIDs, args, true, "", startTime)
+
+	return result, nil
+}
+
+// logFilterFromRequest builds the log filter shared by query_logs and
+// export_logs_text from request, the page size coming from limitParam. On
+// invalid input or denied access it logs the failed call as tool and returns
+// the error result to send; otherwise it returns the filter, bounded by the
+// server's query limits, and the arguments to record with the activity.
+func (s *MCPServer) logFilterFromRequest(ctx context.Context, token *models.MCPToken, tool string, request mcp.CallToolRequest, limitParam string, defaultLimit int, startTime time.Time) (*models.LogFilter, map[string]interface{}, *mcp.CallToolResult) {
 	// Parse parameters
 	projectIDs := request.GetStringSlice("project_ids", nil)
 	levelStrs := request.GetStringSlice("levels", nil)
@@ -271,19 +336,15 @@ func (s *MCPServer) handleQueryLogs(ctx context.Context, request mcp.CallToolReq
 	search := request.GetString("search", "")
 	startTimeStr := request.GetString("start_time", "")
 	endTimeStr := request.GetString("end_time", "")
-	limit := request.GetInt("limit", 100)
+	timeField := request.GetString("time_field", "")
+	limit := request.GetInt(limitParam, defaultLimit)
 	offset := request.GetInt("offset", 0)
 
-	// Enforce max limit
-	if limit > 1000 {
-		limit = 1000
-	}
-
 	// Validate project access
 	allowedProjects, err := ValidateProjectAccess(token, projectIDs)
 	if err != nil {
-		s.logToolActivity(token, "query_logs", projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
-		return mcp.NewToolResultError("Access denied to requested projects"), nil
+		s.logToolActivity(ctx, token, tool, projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
+		return nil, nil, mcp.NewToolResultError("Access denied to requested projects")
 	}
 
 	// Parse time parameters
@@ -291,8 +352,8 @@ func (s *MCPServer) handleQueryLogs(ctx context.Context, request mcp.CallToolReq
 	if startTimeStr != "" {
 		t, err := time.Parse(time.RFC3339, startTimeStr)
 		if err != nil {
-			s.logToolActivity(token, "query_logs", allowedProjects, nil, false, fmt.Sprintf("Invalid start_time: %v", err), startTime)
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid start_time format: %v", err)), nil
+			s.logToolActivity(ctx, token, tool, allowedProjects, nil, false, fmt.Sprintf("Invalid start_time: %v", err), startTime)
+			return nil, nil, mcp.NewToolResultError(fmt.Sprintf("Invalid start_time format: %v", err))
 		}
 		startTime2 = &t
 	}
@@ -300,12 +361,17 @@ func (s *MCPServer) handleQueryLogs(ctx context.Context, request mcp.CallToolReq
 	if endTimeStr != "" {
 		t, err := time.Parse(time.RFC3339, endTimeStr)
 		if err != nil {
-			s.logToolActivity(token, "query_logs", allowedProjects, nil, false, fmt.Sprintf("Invalid end_time: %v", err), startTime)
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid end_time format: %v", err)), nil
+			s.logToolActivity(ctx, token, tool, allowedProjects, nil, false, fmt.Sprintf("Invalid end_time: %v", err), startTime)
+			return nil, nil, mcp.NewToolResultError(fmt.Sprintf("Invalid end_time format: %v", err))
 		}
 		endTime2 = &t
 	}
 
+	if !models.IsValidLogTimeField(timeField) {
+		s.logToolActivity(ctx, token, tool, allowedProjects, nil, false, fmt.Sprintf("Invalid time_field: %s", timeField), startTime)
+		return nil, nil, mcp.NewToolResultError("Invalid time_field: must be timestamp or created_at")
+	}
+
 	// Convert level strings to LogLevel type
 	var levels []models.LogLevel
 	for _, levelStr := range levelStrs {
@@ -320,43 +386,70 @@ func (s *MCPServer) handleQueryLogs(ctx context.Context, request mcp.CallToolReq
 		Search:     search,
 		StartTime:  startTime2,
 		EndTime:    endTime2,
+		TimeField:  timeField,
 		Limit:      limit,
 		Offset:     offset,
 	}
+	s.queryBounds.Apply(filter)
 
-	// Query logs
-	logs, total, err := s.logRepo.List(filter)
-	if err != nil {
-		s.logToolActivity(token, "query_logs", allowedProjects, nil, false, fmt.Sprintf("Failed to query logs: %v", err), startTime)
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to query logs: %v", err)), nil
+	args := map[string]interface{}{
+		"project_ids": projectIDs,
+		"levels":      levelStrs,
+		"source":      source,
+		"search":      search,
+		limitParam:    filter.Limit,
+		"offset":      offset,
 	}
+	return filter, args, nil
+}
 
-	// Convert to output format
-	output := &QueryLogsOutput{
-		Logs:   logs,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+// handleExportLogsText returns the logs query_logs would, newest first, as
+// one line of plain text each, which costs an agent far fewer tokens than JSON
+func (s *MCPServer) handleExportLogsText(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return authErrorResult(), nil
 	}
 
-	result, err := mcp.NewToolResultJSON(output)
+	filter, args, errResult := s.logFilterFromRequest(ctx, token, "export_logs_text", request, "max_lines", 200, startTime)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	logs, total, err := s.logRepo.List(filter)
 	if err != nil {
-		s.logToolActivity(token, "query_logs", allowedProjects, nil, false, "Failed to serialize result", startTime)
-		return mcp.NewToolResultError("Failed to serialize result"), nil
+		s.logToolActivity(ctx, token, "export_logs_text", filter.ProjectIDs, nil, false, fmt.Sprintf("Failed to query logs: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query logs: %v", err)), nil
 	}
 
-	// Log success
-	args := map[string]interface{}{
-		"project_ids": projectIDs,
-		"levels":      levelStrs,
-		"source":      source,
-		"search":      search,
-		"limit":       limit,
-		"offset":      offset,
+	var text strings.Builder
+	for _, entry := range logs {
+		writeLogLine(&text, entry)
+	}
+	if shown := filter.Offset + len(logs); shown < total {
+		fmt.Fprintf(&text, "[truncated: showing logs %d-%d of %d; %d more not shown, continue with offset %d or narrow the filters]\n",
+			filter.Offset+1, shown, total, total-shown, shown)
+	}
+	if len(logs) == 0 {
+		text.WriteString("No logs match these filters\n")
 	}
-	s.logToolActivity(token, "query_logs", allowedProjects, args, true, "", startTime)
 
-	return result, nil
+	s.logToolActivity(ctx, token, "export_logs_text", filter.ProjectIDs, args, true, "", startTime)
+
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// writeLogLine writes entry as "timestamp LEVEL [source] message". Line
+// breaks in the message are escaped so each log stays on one line.
+func writeLogLine(w *strings.Builder, entry *models.Log) {
+	source := entry.Source
+	if source == "" {
+		source = "-"
+	}
+	message := strings.NewReplacer("\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(entry.Message)
+	fmt.Fprintf(w, "%s %-8s [%s] %s\n", entry.Timestamp.UTC().Format(time.RFC3339), entry.Level, source, message)
 }
 
 // handleSearchLogs performs full-text search across logs
@@ -364,32 +457,27 @@ func (s *MCPServer) handleSearchLogs(ctx context.Context, request mcp.CallToolRe
 	startTime := time.Now()
 
 	// Extract token from context
-	token, ok := ctx.Value("mcp_token").(*models.MCPToken)
+	token, ok := TokenFromContext(ctx)
 	if !ok {
-		return mcp.NewToolResultError("Authentication error"), nil
+		return authErrorResult(), nil
 	}
 
 	// Get required query parameter
 	query, err := request.RequireString("query")
 	if err != nil {
-		s.logToolActivity(token, "search_logs", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		s.logToolActivity(ctx, token, "search_logs", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
 	}
 
 	// Parse optional parameters
 	projectIDs := request.GetStringSlice("project_ids", nil)
 	levelStrs := request.GetStringSlice("levels", nil)
-	limit := request.GetInt("limit", 100)
-
-	// Enforce max limit
-	if limit > 1000 {
-		limit = 1000
-	}
+	limit := s.queryBounds.ClampLimit(request.GetInt("limit", 100))
 
 	// Validate project access
 	allowedProjects, err := ValidateProjectAccess(token, projectIDs)
 	if err != nil {
-		s.logToolActivity(token, "search_logs", projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
+		s.logToolActivity(ctx, token, "search_logs", projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
 		return mcp.NewToolResultError("Access denied to requested projects"), nil
 	}
 
@@ -411,7 +499,7 @@ func (s *MCPServer) handleSearchLogs(ctx context.Context, request mcp.CallToolRe
 	// Search logs
 	logs, total, err := s.logRepo.List(filter)
 	if err != nil {
-		s.logToolActivity(token, "search_logs", allowedProjects, nil, false, fmt.Sprintf("Failed to search logs: %v", err), startTime)
+		s.logToolActivity(ctx, token, "search_logs", allowedProjects, nil, false, fmt.Sprintf("Failed to search logs: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to search logs: %v", err)), nil
 	}
 
@@ -425,7 +513,7 @@ func (s *MCPServer) handleSearchLogs(ctx context.Context, request mcp.CallToolRe
 
 	result, err := mcp.NewToolResultJSON(output)
 	if err != nil {
-		s.logToolActivity(token, "search_logs", allowedProjects, nil, false, "Failed to serialize result", startTime)
+		s.logToolActivity(ctx, token, "search_logs", allowedProjects, nil, false, "Failed to serialize result", startTime)
 		return mcp.NewToolResultError("Failed to serialize result"), nil
 	}
 
@@ -436,7 +524,7 @@ func (s *MCPServer) handleSearchLogs(ctx context.Context, request mcp.CallToolRe
 		"levels":      levelStrs,
 		"limit":       limit,
 	}
-	s.logToolActivity(token, "search_logs", allowedProjects, args, true, "", startTime)
+	s.logToolActivity(ctx, token, "search_logs", allowedProjects, args, true, "", startTime)
 
 	return result, nil
 }
@@ -446,31 +534,41 @@ func (s *MCPServer) handleGetStats(ctx context.Context, request mcp.CallToolRequ
 	startTime := time.Now()
 
 	// Extract token from context
-	token, ok := ctx.Value("mcp_token").(*models.MCPToken)
+	token, ok := TokenFromContext(ctx)
 	if !ok {
-		return mcp.NewToolResultError("Authentication error"), nil
+		return authErrorResult(), nil
 	}
 
 	// Get required scope parameter
 	scope, err := request.RequireString("scope")
 	if err != nil {
-		s.logToolActivity(token, "get_stats", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		s.logToolActivity(ctx, token, "get_stats", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
 	}
 
 	// Validate scope
 	if scope != "overview" && scope != "project" {
-		s.logToolActivity(token, "get_stats", nil, nil, false, "Invalid scope value", startTime)
+		s.logToolActivity(ctx, token, "get_stats", nil, nil, false, "Invalid scope value", startTime)
 		return mcp.NewToolResultError("Scope must be 'overview' or 'project'"), nil
 	}
 
+	loc := s.statsLocation
+	if tz := request.GetString("timezone", ""); tz != "" {
+		parsed, err := time.LoadLocation(tz)
+		if err != nil {
+			s.logToolActivity(ctx, token, "get_stats", nil, nil, false, "Unknown time zone", startTime)
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown time zone: %s", tz)), nil
+		}
+		loc = parsed
+	}
+
 	var output GetStatsOutput
 
 	if scope == "overview" {
 		// Get system-wide statistics
 		grantedProjects, allProjects, err := token.GetGrantedProjectIDs()
 		if err != nil {
-			s.logToolActivity(token, "get_stats", nil, nil, false, fmt.Sprintf("Failed to get granted projects: %v", err), startTime)
+			s.logToolActivity(ctx, token, "get_stats", nil, nil, false, fmt.Sprintf("Failed to get granted projects: %v", err), startTime)
 			return mcp.NewToolResultError("Failed to get granted projects"), nil
 		}
 
@@ -478,7 +576,7 @@ func (s *MCPServer) handleGetStats(ctx context.Context, request mcp.CallToolRequ
 		if allProjects {
 			projects, err = s.projectRepo.GetAll()
 			if err != nil {
-				s.logToolActivity(token, "get_stats", nil, nil, false, fmt.Sprintf("Failed to get projects: %v", err), startTime)
+				s.logToolActivity(ctx, token, "get_stats", nil, nil, false, fmt.Sprintf("Failed to get projects: %v", err), startTime)
 				return mcp.NewToolResultError("Failed to get projects"), nil
 			}
 		} else {
@@ -496,32 +594,29 @@ func (s *MCPServer) handleGetStats(ctx context.Context, request mcp.CallToolRequ
 			projectIDs = append(projectIDs, p.ID)
 		}
 
-		// Calculate total logs by summing each project
-		var totalLogs int
-		for _, pid := range projectIDs {
-			count, err := s.logRepo.CountByProject(pid)
-			if err == nil {
-				totalLogs += count
-			}
+		// Per-project, per-level counts in one query
+		projectStats, err := s.logRepo.GetStatsForProjects(projectIDs)
+		if err != nil {
+			s.logToolActivity(ctx, token, "get_stats", nil, nil, false, fmt.Sprintf("Failed to get log stats: %v", err), startTime)
+			return mcp.NewToolResultError("Failed to get log stats"), nil
 		}
 
-		// Count logs today
-		logsToday, _ := s.logRepo.CountToday(projectIDs)
-
-		// Get logs by level aggregated
-		logsByLevel := make(map[string]int)
-		for _, levelStr := range []string{"debug", "info", "warn", "error"} {
-			level := models.LogLevel(levelStr)
-			var count int
-			for _, pid := range projectIDs {
-				c, err := s.logRepo.CountByProjectAndLevel(pid, level)
-				if err == nil {
-					count += c
+		// Aggregate totals and the level breakdown across projects
+		var totalLogs int
+		logsByLevel := map[string]int{"debug": 0, "info": 0, "warn": 0, "error": 0}
+		for _, ps := range projectStats {
+			totalLogs += ps.Total
+			for level, count := range ps.ByLevel {
+				key := strings.ToLower(string(level))
+				if _, tracked := logsByLevel[key]; tracked {
+					logsByLevel[key] += count
 				}
 			}
-			logsByLevel[levelStr] = count
 		}
 
+		// Count logs today
+		logsToday, _ := s.logRepo.CountToday(projectIDs, loc)
+
 		// Get recent logs
 		recentLogs, _ := s.logRepo.GetRecent(projectIDs, 10)
 
@@ -534,7 +629,10 @@ func (s *MCPServer) handleGetStats(ctx context.Context, request mcp.CallToolRequ
 		// Build project summaries
 		var projectSummaries []ProjectStatsSummary
 		for _, p := range projects {
-			logCount, _ := s.logRepo.CountByProject(p.ID)
+			var logCount int
+			if ps, ok := projectStats[p.ID]; ok {
+				logCount = ps.Total
+			}
 			projectSummaries = append(projectSummaries, ProjectStatsSummary{
 				ID:       p.ID,
 				Name:     p.Name,
@@ -547,32 +645,33 @@ func (s *MCPServer) handleGetStats(ctx context.Context, request mcp.CallToolRequ
 			TotalProjects: len(projects),
 			TotalLogs:     totalLogs,
 			LogsToday:     logsToday,
+			Timezone:      loc.String(),
 			TotalUsers:    totalUsers,
 			Projects:      projectSummaries,
 			LogsByLevel:   logsByLevel,
 			RecentLogs:    recentLogs,
 		}
 
-		s.logToolActivity(token, "get_stats", nil, map[string]interface{}{"scope": "overview"}, true, "", startTime)
+		s.logToolActivity(ctx, token, "get_stats", nil, map[string]interface{}{"scope": "overview"}, true, "", startTime)
 
 	} else {
 		// Get project-specific statistics
 		projectID, err := request.RequireString("project_id")
 		if err != nil {
-			s.logToolActivity(token, "get_stats", nil, nil, false, "project_id required when scope is 'project'", startTime)
+			s.logToolActivity(ctx, token, "get_stats", nil, nil, false, "project_id required when scope is 'project'", startTime)
 			return mcp.NewToolResultError("project_id required when scope is 'project'"), nil
 		}
 
 		// Check access
 		hasAccess, err := token.HasAccessToProject(projectID)
 		if err != nil || !hasAccess {
-			s.logToolActivity(token, "get_stats", []string{projectID}, nil, false, "Access denied to project", startTime)
+			s.logToolActivity(ctx, token, "get_stats", []string{projectID}, nil, false, "Access denied to project", startTime)
 			return mcp.NewToolResultError("Access denied to project"), nil
 		}
 
 		// Get project stats
 		totalLogs, _ := s.logRepo.CountByProject(projectID)
-		logsToday, _ := s.logRepo.CountToday([]string{projectID})
+		logsToday, _ := s.logRepo.CountToday([]string{projectID}, loc)
 
 		logsByLevel := make(map[string]int)
 		for _, levelStr := range []string{"debug", "info", "warn", "error"} {
@@ -588,23 +687,596 @@ func (s *MCPServer) handleGetStats(ctx context.Context, request mcp.CallToolRequ
 		output = GetStatsOutput{
 			TotalLogs:   totalLogs,
 			LogsToday:   logsToday,
+			Timezone:    loc.String(),
 			LogsByLevel: logsByLevel,
 			RecentLogs:  recentLogs,
 		}
 
-		s.logToolActivity(token, "get_stats", []string{projectID}, map[string]interface{}{"scope": "project", "project_id": projectID}, true, "", startTime)
+		s.logToolActivity(ctx, token, "get_stats", []string{projectID}, map[string]interface{}{"scope": "project", "project_id": projectID}, true, "", startTime)
+	}
+
+	result, err := mcp.NewToolResultJSON(output)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_stats", nil, nil, false, "Failed to serialize result", startTime)
+		return mcp.NewToolResultError("Failed to serialize result"), nil
+	}
+
+	return result, nil
+}
+
+// handleCompareLogVolume compares log counts between a baseline and a current window
+func (s *MCPServer) handleCompareLogVolume(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+
+	// Extract token from context
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return authErrorResult(), nil
+	}
+
+	// Parse parameters
+	projectIDs := request.GetStringSlice("project_ids", nil)
+	level := request.GetString("level", "")
+	timeField := request.GetString("time_field", "")
+
+	// Validate project access
+	allowedProjects, err := ValidateProjectAccess(token, projectIDs)
+	if err != nil {
+		s.logToolActivity(ctx, token, "compare_log_volume", projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
+		return mcp.NewToolResultError("Access denied to requested projects"), nil
+	}
+
+	// Parse both windows
+	var bounds [4]time.Time
+	for i, name := range []string{"baseline_start", "baseline_end", "current_start", "current_end"} {
+		value, err := request.RequireString(name)
+		if err != nil {
+			s.logToolActivity(ctx, token, "compare_log_volume", allowedProjects, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
+		}
+		bounds[i], err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			s.logToolActivity(ctx, token, "compare_log_volume", allowedProjects, nil, false, fmt.Sprintf("Invalid %s: %v", name, err), startTime)
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid %s format: %v", name, err)), nil
+		}
+	}
+	baseline := VolumeWindow{Start: bounds[0], End: bounds[1]}
+	current := VolumeWindow{Start: bounds[2], End: bounds[3]}
+
+	if !baseline.End.After(baseline.Start) || !current.End.After(current.Start) {
+		s.logToolActivity(ctx, token, "compare_log_volume", allowedProjects, nil, false, "Window end must be after its start", startTime)
+		return mcp.NewToolResultError("Each window's end must be after its start"), nil
+	}
+
+	if !models.IsValidLogTimeField(timeField) {
+		s.logToolActivity(ctx, token, "compare_log_volume", allowedProjects, nil, false, fmt.Sprintf("Invalid time_field: %s", timeField), startTime)
+		return mcp.NewToolResultError("Invalid time_field: must be timestamp or created_at"), nil
+	}
+	if timeField == "" {
+		timeField = models.LogTimeFieldTimestamp
+	}
+
+	// Levels are stored upper-case
+	var levels []models.LogLevel
+	if level != "" {
+		levels = []models.LogLevel{models.LogLevel(strings.ToUpper(level))}
+	}
+
+	// Count each window
+	for _, window := range []*VolumeWindow{&baseline, &current} {
+		count, err := s.logRepo.Count(&models.LogFilter{
+			ProjectIDs: allowedProjects,
+			Levels:     levels,
+			StartTime:  &window.Start,
+			EndTime:    &window.End,
+			TimeField:  timeField,
+		})
+		if err != nil {
+			s.logToolActivity(ctx, token, "compare_log_volume", allowedProjects, nil, false, fmt.Sprintf("Failed to count logs: %v", err), startTime)
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to count logs: %v", err)), nil
+		}
+		window.Count = count
+		window.PerHour = float64(count) / window.End.Sub(window.Start).Hours()
+	}
+
+	output := &CompareLogVolumeOutput{
+		ProjectIDs: allowedProjects,
+		Level:      level,
+		TimeField:  timeField,
+		Baseline:   baseline,
+		Current:    current,
+		Change:     current.Count - baseline.Count,
+	}
+	if baseline.Count > 0 {
+		pct := float64(current.Count-baseline.Count) / float64(baseline.Count) * 100
+		output.PercentChange = &pct
+	}
+
+	result, err := mcp.NewToolResultJSON(output)
+	if err != nil {
+		s.logToolActivity(ctx, token, "compare_log_volume", allowedProjects, nil, false, "Failed to serialize result", startTime)
+		return mcp.NewToolResultError("Failed to serialize result"), nil
+	}
+
+	// Log success
+	args := map[string]interface{}{
+		"project_ids":    projectIDs,
+		"level":          level,
+		"baseline_start": baseline.Start,
+		"baseline_end":   baseline.End,
+		"current_start":  current.Start,
+		"current_end":    current.End,
+		"time_field":     timeField,
+	}
+	s.logToolActivity(ctx, token, "compare_log_volume", allowedProjects, args, true, "", startTime)
+
+	return result, nil
+}
+
+// handleGetLogContext returns the logs around a given log in the same project
+func (s *MCPServer) handleGetLogContext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+
+	// Extract token from context
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return authErrorResult(), nil
+	}
+
+	// Get parameters
+	logID, err := request.RequireString("log_id")
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_log_context", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
+	}
+
+	window := request.GetInt("window", 10)
+	if window < 1 {
+		window = 1
+	}
+	if window > 100 {
+		window = 100
+	}
+
+	// Retrieve log
+	log, err := s.logRepo.GetByID(logID)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_log_context", nil, nil, false, fmt.Sprintf("Failed to retrieve log: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to retrieve log: %v", err)), nil
+	}
+
+	if log == nil {
+		s.logToolActivity(ctx, token, "get_log_context", nil, nil, false, "Log not found", startTime)
+		return mcp.NewToolResultError("Log not found"), nil
+	}
+
+	// Check if token has access to this log's project
+	hasAccess, err := token.HasAccessToProject(log.ProjectID)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_log_context", nil, nil, false, fmt.Sprintf("Access check failed: %v", err), startTime)
+		return mcp.NewToolResultError("Access check failed"), nil
+	}
+
+	if !hasAccess {
+		s.logToolActivity(ctx, token, "get_log_context", []string{log.ProjectID}, nil, false, "Access denied to this log's project", startTime)
+		return mcp.NewToolResultError("Access denied to this log's project"), nil
+	}
+
+	before, after, err := s.logRepo.GetContext(log, window, window)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_log_context", []string{log.ProjectID}, nil, false, fmt.Sprintf("Failed to retrieve context: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to retrieve context: %v", err)), nil
+	}
+
+	// Return empty arrays rather than null
+	if before == nil {
+		before = []*models.Log{}
+	}
+	if after == nil {
+		after = []*models.Log{}
+	}
+
+	output := &GetLogContextOutput{
+		Log:    log,
+		Before: before,
+		After:  after,
+	}
+
+	result, err := mcp.NewToolResultJSON(output)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_log_context", []string{log.ProjectID}, nil, false, "Failed to serialize result", startTime)
+		return mcp.NewToolResultError("Failed to serialize result"), nil
+	}
+
+	// Log success
+	args := map[string]interface{}{"log_id": logID, "window": window}
+	s.logToolActivity(ctx, token, "get_log_context", []string{log.ProjectID}, args, true, "", startTime)
+
+	return result, nil
+}
+
+// maxErrorClusterScan caps how many recent errors get_error_clusters groups
+const maxErrorClusterScan = 5000
+
+// handleGetErrorClusters groups recent ERROR and CRITICAL logs by message pattern
+func (s *MCPServer) handleGetErrorClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+
+	// Extract token from context
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return authErrorResult(), nil
+	}
+
+	// Get optional parameters
+	projectIDs := request.GetStringSlice("project_ids", nil)
+	hours := request.GetInt("hours", 24)
+	limit := request.GetInt("limit", 10)
+
+	// Enforce limits
+	if hours <= 0 {
+		hours = 24
+	}
+	if hours > 168 {
+		hours = 168
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	// Validate project access
+	allowedProjects, err := ValidateProjectAccess(token, projectIDs)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_error_clusters", projectIDs, nil, false, fmt.Sprintf("Access denied: %v", err), startTime)
+		return mcp.NewToolResultError("Access denied to requested projects"), nil
+	}
+
+	since := startTime.Add(-time.Duration(hours) * time.Hour)
+	output := &GetErrorClustersOutput{
+		Clusters: []ErrorCluster{},
+		Since:    since,
+	}
+
+	// A token granted no projects sees nothing, not everything
+	if allowedProjects == nil || len(allowedProjects) > 0 {
+		logs, total, err := s.logRepo.List(&models.LogFilter{
+			ProjectIDs: allowedProjects,
+			Levels:     []models.LogLevel{models.LogLevelError, models.LogLevelCritical},
+			StartTime:  &since,
+			Limit:      maxErrorClusterScan,
+		})
+		if err != nil {
+			s.logToolActivity(ctx, token, "get_error_clusters", allowedProjects, nil, false, fmt.Sprintf("Failed to retrieve logs: %v", err), startTime)
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to retrieve logs: %v", err)), nil
+		}
+
+		clusters := clusterErrors(logs)
+		output.TotalClusters = len(clusters)
+		if len(clusters) > limit {
+			clusters = clusters[:limit]
+		}
+		output.Clusters = clusters
+		output.Scanned = len(logs)
+		output.Truncated = total > len(logs)
+	}
+
+	result, err := mcp.NewToolResultJSON(output)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_error_clusters", allowedProjects, nil, false, "Failed to serialize result", startTime)
+		return mcp.NewToolResultError("Failed to serialize result"), nil
+	}
+
+	// Log success
+	args := map[string]interface{}{"project_ids": projectIDs, "hours": hours, "limit": limit}
+	s.logToolActivity(ctx, token, "get_error_clusters", allowedProjects, args, true, "", startTime)
+
+	return result, nil
+}
+
+// clusterErrors groups logs by project and normalized message, largest
+// cluster first. Logs are expected newest first, so each cluster's sample is
+// its most recent occurrence.
+func clusterErrors(logs []*models.Log) []ErrorCluster {
+	index := make(map[string]int)
+	var clusters []ErrorCluster
+
+	for _, l := range logs {
+		pattern := models.NormalizeMessage(l.Message)
+		key := l.ProjectID + "\x00" + pattern
+
+		i, ok := index[key]
+		if !ok {
+			i = len(clusters)
+			index[key] = i
+			clusters = append(clusters, ErrorCluster{
+				ProjectID:     l.ProjectID,
+				ProjectName:   l.ProjectName,
+				Pattern:       pattern,
+				Levels:        make(map[string]int),
+				SampleLogID:   l.ID,
+				SampleMessage: l.Message,
+				FirstSeen:     l.Timestamp,
+				LastSeen:      l.Timestamp,
+			})
+		}
+
+		c := &clusters[i]
+		c.Count++
+		c.Levels[string(l.Level)]++
+		if l.Timestamp.Before(c.FirstSeen) {
+			c.FirstSeen = l.Timestamp
+		}
+		if l.Timestamp.After(c.LastSeen) {
+			c.LastSeen = l.Timestamp
+		}
+	}
+
+	// Ties go to the most recently seen
+	sort.SliceStable(clusters, func(a, b int) bool {
+		if clusters[a].Count != clusters[b].Count {
+			return clusters[a].Count > clusters[b].Count
+		}
+		return clusters[a].LastSeen.After(clusters[b].LastSeen)
+	})
+	return clusters
+}
+
+// projectHealthTopN is how many error sources and patterns get_project_health lists
+const projectHealthTopN = 3
+
+// handleGetProjectHealth summarizes a project's recent volume and errors
+func (s *MCPServer) handleGetProjectHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+
+	// Extract token from context
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return authErrorResult(), nil
+	}
+
+	projectID, err := request.RequireString("project_id")
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_project_health", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
+	}
+
+	// Check if token has access to this project
+	hasAccess, err := token.HasAccessToProject(projectID)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_project_health", nil, nil, false, fmt.Sprintf("Access check failed: %v", err), startTime)
+		return mcp.NewToolResultError("Access check failed"), nil
+	}
+	if !hasAccess {
+		s.logToolActivity(ctx, token, "get_project_health", []string{projectID}, nil, false, "Access denied to this project", startTime)
+		return mcp.NewToolResultError("Access denied to this project"), nil
+	}
+
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_project_health", []string{projectID}, nil, false, fmt.Sprintf("Failed to get project: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get project: %v", err)), nil
+	}
+	if project == nil {
+		s.logToolActivity(ctx, token, "get_project_health", []string{projectID}, nil, false, "Project not found", startTime)
+		return mcp.NewToolResultError("Project not found"), nil
+	}
+
+	output, err := s.projectHealth(project, startTime.UTC())
+	if err != nil {
+		s.logToolActivity(ctx, token, "get_project_health", []string{projectID}, nil, false, fmt.Sprintf("Failed to gather health: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to gather health: %v", err)), nil
 	}
 
 	result, err := mcp.NewToolResultJSON(output)
 	if err != nil {
-		s.logToolActivity(token, "get_stats", nil, nil, false, "Failed to serialize result", startTime)
+		s.logToolActivity(ctx, token, "get_project_health", []string{projectID}, nil, false, "Failed to serialize result", startTime)
 		return mcp.NewToolResultError("Failed to serialize result"), nil
 	}
 
+	// Log success
+	args := map[string]interface{}{"project_id": projectID}
+	s.logToolActivity(ctx, token, "get_project_health", []string{projectID}, args, true, "", startTime)
+
 	return result, nil
 }
 
+// projectHealth gathers get_project_health's snapshot as of now. Windows are
+// on the log timestamp.
+func (s *MCPServer) projectHealth(project *models.Project, now time.Time) (*GetProjectHealthOutput, error) {
+	errorLevels := []models.LogLevel{models.LogLevelError, models.LogLevelCritical}
+	hourAgo := now.Add(-time.Hour)
+	dayAgo := now.Add(-24 * time.Hour)
+	twoDaysAgo := now.Add(-48 * time.Hour)
+
+	count := func(levels []models.LogLevel, start, end time.Time) (int, error) {
+		return s.logRepo.Count(&models.LogFilter{
+			ProjectIDs: []string{project.ID},
+			Levels:     levels,
+			StartTime:  &start,
+			EndTime:    &end,
+			TimeField:  models.LogTimeFieldTimestamp,
+		})
+	}
+
+	output := &GetProjectHealthOutput{
+		ProjectID:   project.ID,
+		ProjectName: project.Name,
+		IsActive:    project.IsActive,
+		GeneratedAt: now,
+	}
+
+	var err error
+	if output.TotalLogs, err = s.logRepo.CountByProject(project.ID); err != nil {
+		return nil, err
+	}
+	if output.LogsLastHour, err = count(nil, hourAgo, now); err != nil {
+		return nil, err
+	}
+	if output.LogsLastDay, err = count(nil, dayAgo, now); err != nil {
+		return nil, err
+	}
+	previousDay, err := count(nil, twoDaysAgo, dayAgo)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := &output.ErrorRate
+	if rate.CurrentErrors, err = count(errorLevels, dayAgo, now); err != nil {
+		return nil, err
+	}
+	if rate.PreviousErrors, err = count(errorLevels, twoDaysAgo, dayAgo); err != nil {
+		return nil, err
+	}
+	if output.LogsLastDay > 0 {
+		rate.Current = float64(rate.CurrentErrors) / float64(output.LogsLastDay)
+	}
+	if previousDay > 0 {
+		rate.Previous = float64(rate.PreviousErrors) / float64(previousDay)
+	}
+	rate.Trend = errorRateTrend(rate.Current, rate.Previous, output.LogsLastDay+previousDay)
+
+	recentErrors := &models.LogFilter{
+		ProjectIDs: []string{project.ID},
+		Levels:     errorLevels,
+		StartTime:  &dayAgo,
+		TimeField:  models.LogTimeFieldTimestamp,
+	}
+	sources, err := s.logRepo.FacetBySource(recentErrors)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) > projectHealthTopN {
+		sources = sources[:projectHealthTopN]
+	}
+	output.TopErrorSources = append([]models.FacetCount{}, sources...)
+
+	recentErrors.Limit = maxErrorClusterScan
+	errorLogs, _, err := s.logRepo.List(recentErrors)
+	if err != nil {
+		return nil, err
+	}
+	clusters := clusterErrors(errorLogs)
+	if len(clusters) > projectHealthTopN {
+		clusters = clusters[:projectHealthTopN]
+	}
+	output.TopErrors = append([]ErrorCluster{}, clusters...)
+
+	critical, _, err := s.logRepo.List(&models.LogFilter{
+		ProjectIDs: []string{project.ID},
+		Levels:     []models.LogLevel{models.LogLevelCritical},
+		TimeField:  models.LogTimeFieldTimestamp,
+		Limit:      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(critical) > 0 {
+		output.LatestCritical = critical[0]
+	}
+
+	return output, nil
+}
+
+// errorRateTrend compares two error rates. A move of less than one
+// percentage point, or less than a tenth of the previous rate, is steady.
+func errorRateTrend(current, previous float64, logs int) string {
+	if logs == 0 {
+		return TrendNoData
+	}
+	diff := current - previous
+	if math.Abs(diff) < 0.01 || math.Abs(diff) < previous*0.1 {
+		return TrendSteady
+	}
+	if diff > 0 {
+		return TrendRising
+	}
+	return TrendFalling
+}
+
 // Helper function to serialize any data to JSON string
+// resolveProjectLimit caps the matches resolve_project returns
+const resolveProjectLimit = 20
+
+// handleResolveProject finds the accessible projects with a given name, or
+// whose name starts with it, so agents can go from a name to a project ID
+func (s *MCPServer) handleResolveProject(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+
+	// Extract token from context
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return authErrorResult(), nil
+	}
+
+	name, err := request.RequireString("name")
+	if err == nil && strings.TrimSpace(name) == "" {
+		err = fmt.Errorf("name must not be empty")
+	}
+	if err != nil {
+		s.logToolActivity(ctx, token, "resolve_project", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
+	}
+	name = strings.TrimSpace(name)
+
+	grantedProjects, allProjects, err := token.GetGrantedProjectIDs()
+	if err != nil {
+		s.logToolActivity(ctx, token, "resolve_project", nil, nil, false, fmt.Sprintf("Failed to get granted projects: %v", err), startTime)
+		return mcp.NewToolResultError("Failed to get granted projects"), nil
+	}
+	granted := make(map[string]bool, len(grantedProjects))
+	for _, id := range grantedProjects {
+		granted[id] = true
+	}
+
+	projects, err := s.projectRepo.FindByName(name)
+	if err != nil {
+		s.logToolActivity(ctx, token, "resolve_project", nil, nil, false, fmt.Sprintf("Failed to find projects: %v", err), startTime)
+		return mcp.NewToolResultError("Failed to find projects"), nil
+	}
+
+	output := &ResolveProjectOutput{Query: name, Matches: []ProjectMatch{}}
+	exact := 0
+	var matchedIDs []string
+	for _, project := range projects {
+		if !allProjects && !granted[project.ID] {
+			continue
+		}
+		if len(output.Matches) == resolveProjectLimit {
+			output.Truncated = true
+			break
+		}
+		match := ProjectMatch{
+			ID:       project.ID,
+			Name:     project.Name,
+			Group:    project.Group,
+			IsActive: project.IsActive,
+			Exact:    strings.EqualFold(project.Name, name),
+		}
+		if match.Exact {
+			exact++
+		}
+		output.Matches = append(output.Matches, match)
+		matchedIDs = append(matchedIDs, project.ID)
+	}
+	output.Count = len(output.Matches)
+	output.Ambiguous = output.Count > 1 && exact != 1
+
+	result, err := mcp.NewToolResultJSON(output)
+	if err != nil {
+		s.logToolActivity(ctx, token, "resolve_project", matchedIDs, nil, false, "Failed to serialize result", startTime)
+		return mcp.NewToolResultError("Failed to serialize result"), nil
+	}
+
+	// Log success
+	args := map[string]interface{}{"name": name}
+	s.logToolActivity(ctx, token, "resolve_project", matchedIDs, args, true, "", startTime)
+
+	return result, nil
+}
+
 func toJSONString(data interface{}) (string, error) {
 	bytes, err := json.Marshal(data)
 	if err != nil {
@@ -612,3 +1284,96 @@ func toJSONString(data interface{}) (string, error) {
 	}
 	return string(bytes), nil
 }
+
+// maxAcknowledgeCommentLength caps acknowledge_log's comment, in characters
+const maxAcknowledgeCommentLength = 1000
+
+// handleAcknowledgeLog sets a log's status for a token with write scope. The
+// change is attributed to the token's creator and recorded in the audit trail
+// along with the optional comment.
+func (s *MCPServer) handleAcknowledgeLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return authErrorResult(), nil
+	}
+
+	logID, err := request.RequireString("log_id")
+	if err != nil {
+		s.logToolActivity(ctx, token, "acknowledge_log", nil, nil, false, fmt.Sprintf("Invalid input: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid input: %v", err)), nil
+	}
+	status := models.LogStatus(request.GetString("status", string(models.LogStatusAcknowledged)))
+	comment := strings.TrimSpace(request.GetString("comment", ""))
+	params := map[string]interface{}{"log_id": logID, "status": status}
+	if comment != "" {
+		params["comment"] = comment
+	}
+
+	if !models.IsValidLogStatus(status) {
+		s.logToolActivity(ctx, token, "acknowledge_log", nil, params, false, "Invalid status", startTime)
+		return mcp.NewToolResultError("Invalid status: must be acknowledged, resolved or new"), nil
+	}
+	if len([]rune(comment)) > maxAcknowledgeCommentLength {
+		s.logToolActivity(ctx, token, "acknowledge_log", nil, params, false, "Comment too long", startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Comment must be at most %d characters", maxAcknowledgeCommentLength)), nil
+	}
+
+	if !token.CanWrite() {
+		s.logToolActivity(ctx, token, "acknowledge_log", nil, params, false, "Token does not have write scope", startTime)
+		return mcp.NewToolResultError("This token is read-only; acknowledge_log requires a token with write scope"), nil
+	}
+
+	log, err := s.logRepo.GetByID(logID)
+	if err != nil {
+		s.logToolActivity(ctx, token, "acknowledge_log", nil, params, false, fmt.Sprintf("Failed to retrieve log: %v", err), startTime)
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to retrieve log: %v", err)), nil
+	}
+	if log == nil {
+		s.logToolActivity(ctx, token, "acknowledge_log", nil, params, false, "Log not found", startTime)
+		return mcp.NewToolResultError("Log not found"), nil
+	}
+
+	hasAccess, err := token.HasAccessToProject(log.ProjectID)
+	if err != nil {
+		s.logToolActivity(ctx, token, "acknowledge_log", nil, params, false, fmt.Sprintf("Access check failed: %v", err), startTime)
+		return mcp.NewToolResultError("Access check failed"), nil
+	}
+	if !hasAccess {
+		s.logToolActivity(ctx, token, "acknowledge_log", []string{log.ProjectID}, params, false, "Access denied to this log's project", startTime)
+		return mcp.NewToolResultError("Access denied to this log's project"), nil
+	}
+
+	previous := log.Status
+	if _, err := s.logRepo.UpdateStatus(log.ID, status, token.CreatedBy); err != nil {
+		s.logToolActivity(ctx, token, "acknowledge_log", []string{log.ProjectID}, params, false, fmt.Sprintf("Failed to update log: %v", err), startTime)
+		return mcp.NewToolResultError("Failed to update log"), nil
+	}
+	s.recordAudit(token, models.AuditLogStatusChange, "log", log.ID, acknowledgeAuditDetails(token, previous, status, comment))
+
+	updated, err := s.logRepo.GetByID(log.ID)
+	if err != nil || updated == nil {
+		updated = log
+		updated.Status = status
+	}
+
+	result, err := mcp.NewToolResultJSON(&AcknowledgeLogOutput{Log: updated, PreviousStatus: previous})
+	if err != nil {
+		s.logToolActivity(ctx, token, "acknowledge_log", []string{log.ProjectID}, params, false, fmt.Sprintf("Failed to serialize result: %v", err), startTime)
+		return mcp.NewToolResultError("Failed to serialize result"), nil
+	}
+
+	s.logToolActivity(ctx, token, "acknowledge_log", []string{log.ProjectID}, params, true, "", startTime)
+
+	return result, nil
+}
+
+// acknowledgeAuditDetails describes a status change made through a token
+func acknowledgeAuditDetails(token *models.MCPToken, from, to models.LogStatus, comment string) string {
+	details := fmt.Sprintf("status=%s->%s mcp_token=%s", from, to, token.TokenPrefix)
+	if comment != "" {
+		details += fmt.Sprintf(" comment=%q", comment)
+	}
+	return details
+}
@@ -3,6 +3,8 @@ package mcp
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,16 +20,26 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
+	// Every connection to :memory: is a separate database, and activity is
+	// logged from other goroutines
+	db.SetMaxOpenConns(1)
 
 	// Create tables
 	schema := `
 	CREATE TABLE users (
 		id TEXT PRIMARY KEY,
 		username TEXT NOT NULL UNIQUE,
+		email TEXT NOT NULL DEFAULT '',
 		password TEXT NOT NULL,
 		name TEXT NOT NULL,
 		role TEXT NOT NULL,
 		is_active INTEGER NOT NULL DEFAULT 1,
+		two_factor_secret TEXT,
+		two_factor_enabled INTEGER NOT NULL DEFAULT 0,
+		backup_codes TEXT,
+		deleted_at DATETIME,
+		last_login_at DATETIME,
+		token_version INTEGER NOT NULL DEFAULT 0,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
@@ -36,8 +48,24 @@ func setupTestDB(t *testing.T) *sql.DB {
 		id TEXT PRIMARY KEY,
 		name TEXT NOT NULL,
 		description TEXT,
+		icon_type TEXT DEFAULT 'initials',
+		icon_value TEXT DEFAULT '',
 		api_key TEXT NOT NULL UNIQUE,
+		api_key_prefix TEXT NOT NULL DEFAULT '',
 		is_active INTEGER NOT NULL DEFAULT 1,
+		retention_config TEXT,
+		redaction_config TEXT,
+		sampling_config TEXT,
+		signing_secret TEXT,
+		max_body_bytes INTEGER,
+		rate_limit_per_minute INTEGER,
+		rate_limit_burst INTEGER,
+		ingestion_paused INTEGER NOT NULL DEFAULT 0,
+		default_level TEXT,
+		require_level INTEGER NOT NULL DEFAULT 0,
+		outbound_webhook TEXT,
+		group_name TEXT,
+		deleted_at DATETIME,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
@@ -51,6 +79,9 @@ func setupTestDB(t *testing.T) *sql.DB {
 		metadata TEXT,
 		timestamp DATETIME NOT NULL,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		status TEXT NOT NULL DEFAULT 'new',
+		acknowledged_by TEXT,
+		acknowledged_at DATETIME,
 		FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
 	);
 	CREATE INDEX idx_logs_project_id ON logs(project_id);
@@ -63,6 +94,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 		token_hash TEXT NOT NULL,
 		token_prefix TEXT NOT NULL,
 		granted_projects TEXT,
+		scope TEXT NOT NULL DEFAULT 'read',
 		expires_at DATETIME,
 		is_active INTEGER NOT NULL DEFAULT 1,
 		created_by TEXT NOT NULL,
@@ -80,6 +112,19 @@ func setupTestDB(t *testing.T) *sql.DB {
 		success INTEGER NOT NULL,
 		error_message TEXT,
 		duration_ms INTEGER,
+		request_id TEXT,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE audit_logs (
+		id TEXT PRIMARY KEY,
+		actor_id TEXT NOT NULL,
+		actor_name TEXT NOT NULL,
+		action TEXT NOT NULL,
+		target_type TEXT NOT NULL,
+		target_id TEXT NOT NULL,
+		details TEXT,
+		ip_address TEXT,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 	`
@@ -190,7 +235,7 @@ func TestHandleGetLog(t *testing.T) {
 
 	// Test successful retrieval
 	t.Run("Success", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"log_id": logID,
 		})
@@ -209,7 +254,7 @@ func TestHandleGetLog(t *testing.T) {
 	t.Run("AccessDenied", func(t *testing.T) {
 		// Create token with access to project2 only
 		token2, _ := createTestToken(t, db, userID, `["test-project-2"]`)
-		ctx := context.WithValue(context.Background(), "mcp_token", token2)
+		ctx := WithToken(context.Background(), token2)
 
 		request := createMockRequest(map[string]interface{}{
 			"log_id": logID, // This log belongs to project1
@@ -227,7 +272,7 @@ func TestHandleGetLog(t *testing.T) {
 
 	// Test log not found
 	t.Run("NotFound", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"log_id": "non-existent-log",
 		})
@@ -244,7 +289,7 @@ func TestHandleGetLog(t *testing.T) {
 
 	// Test missing parameter
 	t.Run("MissingParameter", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{})
 
 		result, err := server.handleGetLog(ctx, request)
@@ -258,6 +303,7 @@ func TestHandleGetLog(t *testing.T) {
 	})
 
 	// Verify activity was logged
+	server.activityWG.Wait()
 	activityRepo := models.NewMCPActivityLogRepository(db)
 	activities, _, err := activityRepo.GetByTokenID(token.ID, 10, 0)
 	if err != nil {
@@ -287,7 +333,7 @@ func TestHandleListProjects(t *testing.T) {
 	// Test with all projects access
 	t.Run("AllProjects", func(t *testing.T) {
 		token, _ := createTestToken(t, db, userID, "*")
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{})
 
 		result, err := server.handleListProjects(ctx, request)
@@ -303,7 +349,7 @@ func TestHandleListProjects(t *testing.T) {
 	// Test with specific projects access
 	t.Run("SpecificProjects", func(t *testing.T) {
 		token, _ := createTestToken(t, db, userID, `["test-project-1"]`)
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{})
 
 		result, err := server.handleListProjects(ctx, request)
@@ -314,6 +360,166 @@ func TestHandleListProjects(t *testing.T) {
 		if result.IsError {
 			t.Errorf("Expected success, got error result")
 		}
+
+		output := result.StructuredContent.(*ListProjectsOutput)
+		if output.Total != 1 || len(output.Projects) != 1 || output.Projects[0].ID != "test-project-1" {
+			t.Errorf("Expected only the granted project, got %+v", output)
+		}
+	})
+
+	// Extra projects for filtering
+	for _, p := range []struct {
+		id, name string
+		active   bool
+	}{
+		{"billing-api", "Billing API", true},
+		{"billing-worker", "billing worker", false},
+		{"search", "Search", true},
+	} {
+		if _, err := db.Exec(`INSERT INTO projects (id, name, api_key, is_active) VALUES (?, ?, ?, ?)`, p.id, p.name, "key-"+p.id, p.active); err != nil {
+			t.Fatalf("Failed to create project %s: %v", p.id, err)
+		}
+	}
+
+	listIDs := func(t *testing.T, token *models.MCPToken, params map[string]interface{}) (*ListProjectsOutput, []string) {
+		t.Helper()
+		ctx := WithToken(context.Background(), token)
+		result, err := server.handleListProjects(ctx, createMockRequest(params))
+		if err != nil {
+			t.Fatalf("handleListProjects returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Expected success, got error result: %v", result.Content)
+		}
+		output := result.StructuredContent.(*ListProjectsOutput)
+		ids := make([]string, len(output.Projects))
+		for i, p := range output.Projects {
+			ids[i] = p.ID
+		}
+		return output, ids
+	}
+
+	t.Run("NameContains", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, "*")
+		output, ids := listIDs(t, token, map[string]interface{}{"name_contains": "BILLING"})
+		if output.Total != 2 || len(ids) != 2 {
+			t.Errorf("Expected both billing projects case-insensitively, got %v", ids)
+		}
+	})
+
+	t.Run("ActiveOnly", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, "*")
+		output, ids := listIDs(t, token, map[string]interface{}{"name_contains": "billing", "active_only": true})
+		if output.Total != 1 || len(ids) != 1 || ids[0] != "billing-api" {
+			t.Errorf("Expected only the active billing project, got %v", ids)
+		}
+
+		output, _ = listIDs(t, token, map[string]interface{}{"active_only": false})
+		if output.Total != 5 {
+			t.Errorf("Expected inactive projects when active_only is false, got total %d", output.Total)
+		}
+	})
+
+	t.Run("FiltersStayWithinGrant", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, `["billing-worker", "search"]`)
+		output, ids := listIDs(t, token, map[string]interface{}{"name_contains": "billing"})
+		if output.Total != 1 || len(ids) != 1 || ids[0] != "billing-worker" {
+			t.Errorf("Expected only the granted billing project, got %v", ids)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, "*")
+		first, firstIDs := listIDs(t, token, map[string]interface{}{"limit": float64(2)})
+		second, secondIDs := listIDs(t, token, map[string]interface{}{"limit": float64(2), "offset": float64(2)})
+		if first.Total != 5 || second.Total != 5 {
+			t.Errorf("Expected total 5 on every page, got %d and %d", first.Total, second.Total)
+		}
+		if len(firstIDs) != 2 || len(secondIDs) != 2 || firstIDs[0] == secondIDs[0] {
+			t.Errorf("Expected distinct pages of 2, got %v and %v", firstIDs, secondIDs)
+		}
+	})
+}
+
+func TestHandleResolveProject(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userID, _, _, _ := setupTestData(t, db)
+
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+	}
+
+	for _, p := range []struct{ id, name string }{
+		{"billing", "Billing"},
+		{"billing-api", "Billing API"},
+		{"billing-worker", "Billing Worker"},
+	} {
+		if _, err := db.Exec(`INSERT INTO projects (id, name, api_key) VALUES (?, ?, ?)`, p.id, p.name, "key-"+p.id); err != nil {
+			t.Fatalf("Failed to create project %s: %v", p.id, err)
+		}
+	}
+
+	resolve := func(t *testing.T, grant string, name string) *mcp.CallToolResult {
+		t.Helper()
+		token, _ := createTestToken(t, db, userID, grant)
+		result, err := server.handleResolveProject(WithToken(context.Background(), token), createMockRequest(map[string]interface{}{"name": name}))
+		if err != nil {
+			t.Fatalf("handleResolveProject returned error: %v", err)
+		}
+		return result
+	}
+	ids := func(output *ResolveProjectOutput) []string {
+		var out []string
+		for _, m := range output.Matches {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	t.Run("ExactMatchFirst", func(t *testing.T) {
+		output := resolve(t, "*", "billing").StructuredContent.(*ResolveProjectOutput)
+		if output.Count != 3 || output.Matches[0].ID != "billing" || !output.Matches[0].Exact || output.Matches[1].Exact {
+			t.Errorf("Expected the exact match first among 3, got %+v", output.Matches)
+		}
+		if output.Ambiguous {
+			t.Error("A single exact match should not be ambiguous")
+		}
+	})
+
+	t.Run("AmbiguousPrefix", func(t *testing.T) {
+		output := resolve(t, "*", "BIL").StructuredContent.(*ResolveProjectOutput)
+		if !output.Ambiguous {
+			t.Errorf("Expected prefix matches without an exact one to be ambiguous, got %+v", output)
+		}
+	})
+
+	t.Run("StaysWithinGrant", func(t *testing.T) {
+		output := resolve(t, `["billing-worker"]`, "bill").StructuredContent.(*ResolveProjectOutput)
+		if got := ids(output); len(got) != 1 || got[0] != "billing-worker" || output.Ambiguous {
+			t.Errorf("Expected only the granted project, got %v", got)
+		}
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		result := resolve(t, "*", "payments")
+		if result.IsError {
+			t.Fatalf("Expected success, got error result: %v", result.Content)
+		}
+		if output := result.StructuredContent.(*ResolveProjectOutput); output.Count != 0 || output.Matches == nil {
+			t.Errorf("Expected an empty match list, got %+v", output)
+		}
+	})
+
+	t.Run("EmptyName", func(t *testing.T) {
+		if result := resolve(t, "*", "  "); !result.IsError {
+			t.Error("Expected an error for an empty name")
+		}
 	})
 }
 
@@ -335,7 +541,7 @@ func TestHandleGetProject(t *testing.T) {
 
 	// Test successful retrieval
 	t.Run("Success", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"project_id": project1ID,
 		})
@@ -353,7 +559,7 @@ func TestHandleGetProject(t *testing.T) {
 	// Test access denied
 	t.Run("AccessDenied", func(t *testing.T) {
 		token2, _ := createTestToken(t, db, userID, `["test-project-2"]`)
-		ctx := context.WithValue(context.Background(), "mcp_token", token2)
+		ctx := WithToken(context.Background(), token2)
 
 		request := createMockRequest(map[string]interface{}{
 			"project_id": project1ID, // Token only has access to project2
@@ -371,7 +577,7 @@ func TestHandleGetProject(t *testing.T) {
 
 	// Test project not found
 	t.Run("NotFound", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"project_id": "non-existent-project",
 		})
@@ -405,7 +611,7 @@ func TestHandleGetRecentLogs(t *testing.T) {
 
 	// Test with no filters (all projects)
 	t.Run("AllProjects", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{})
 
 		result, err := server.handleGetRecentLogs(ctx, request)
@@ -420,7 +626,7 @@ func TestHandleGetRecentLogs(t *testing.T) {
 
 	// Test with project filter
 	t.Run("SpecificProject", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"project_ids": []interface{}{project1ID},
 			"limit":       float64(10),
@@ -438,7 +644,7 @@ func TestHandleGetRecentLogs(t *testing.T) {
 
 	// Test limit enforcement
 	t.Run("LimitEnforcement", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"limit": float64(1000), // Above max of 500
 		})
@@ -456,7 +662,7 @@ func TestHandleGetRecentLogs(t *testing.T) {
 	// Test access denied
 	t.Run("AccessDenied", func(t *testing.T) {
 		token2, _ := createTestToken(t, db, userID, `["test-project-1"]`)
-		ctx := context.WithValue(context.Background(), "mcp_token", token2)
+		ctx := WithToken(context.Background(), token2)
 
 		request := createMockRequest(map[string]interface{}{
 			"project_ids": []interface{}{project2ID}, // Token doesn't have access
@@ -487,11 +693,12 @@ func TestHandleQueryLogs(t *testing.T) {
 		logRepo:         models.NewLogRepository(db),
 		projectRepo:     models.NewProjectRepository(db),
 		userRepo:        models.NewUserRepository(db),
+		queryBounds:     models.LogQueryBounds{DefaultRange: 24 * time.Hour, MaxLimit: 1000},
 	}
 
 	// Test basic query
 	t.Run("BasicQuery", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"limit": float64(10),
 		})
@@ -508,7 +715,7 @@ func TestHandleQueryLogs(t *testing.T) {
 
 	// Test with filters
 	t.Run("WithFilters", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"project_ids": []interface{}{project1ID},
 			"levels":      []interface{}{"info", "error"},
@@ -529,7 +736,7 @@ func TestHandleQueryLogs(t *testing.T) {
 
 	// Test with search
 	t.Run("WithSearch", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"search": "error",
 			"limit":  float64(10),
@@ -547,7 +754,7 @@ func TestHandleQueryLogs(t *testing.T) {
 
 	// Test limit enforcement
 	t.Run("LimitEnforcement", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"limit": float64(2000), // Above max of 1000
 		})
@@ -558,11 +765,119 @@ func TestHandleQueryLogs(t *testing.T) {
 		}
 
 		if result.IsError {
-			t.Errorf("Expected success, got error result")
+			t.Fatalf("Expected success, got error result")
+		}
+		if output := result.StructuredContent.(*QueryLogsOutput); output.Limit != 1000 {
+			t.Errorf("Expected limit clamped to 1000, got %d", output.Limit)
+		}
+	})
+
+	// Without a time range only logs inside the default range are returned
+	t.Run("DefaultRange", func(t *testing.T) {
+		old := time.Now().Add(-48 * time.Hour)
+		if _, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, source, timestamp) VALUES (?, ?, ?, ?, ?, ?)`,
+			"log-old", project1ID, "info", "Old log", "test-source", old); err != nil {
+			t.Fatalf("Failed to create old log: %v", err)
+		}
+
+		ctx := WithToken(context.Background(), token)
+		result, err := server.handleQueryLogs(ctx, createMockRequest(map[string]interface{}{}))
+		if err != nil {
+			t.Fatalf("handleQueryLogs returned error: %v", err)
+		}
+		if total := result.StructuredContent.(*QueryLogsOutput).Total; total != 5 {
+			t.Errorf("Expected the 5 recent logs, got %d", total)
+		}
+
+		result, err = server.handleQueryLogs(ctx, createMockRequest(map[string]interface{}{
+			"start_time": old.Add(-time.Hour).Format(time.RFC3339),
+		}))
+		if err != nil {
+			t.Fatalf("handleQueryLogs returned error: %v", err)
+		}
+		if total := result.StructuredContent.(*QueryLogsOutput).Total; total != 6 {
+			t.Errorf("Expected an explicit start_time to include the old log, got %d", total)
 		}
 	})
 }
 
+// TestHandleExportLogsText tests the handleExportLogsText tool
+func TestHandleExportLogsText(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userID, project1ID, project2ID, _ := setupTestData(t, db)
+	token, _ := createTestToken(t, db, userID, "*")
+
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+		queryBounds:     models.LogQueryBounds{DefaultRange: 24 * time.Hour, MaxLimit: 1000},
+	}
+
+	// Newer than the fixtures, so it comes first
+	stamp := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	if _, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, source, timestamp) VALUES (?, ?, ?, ?, ?, ?)`,
+		"log-trace", project1ID, "ERROR", "panic: boom\ngoroutine 1", "", stamp); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	t.Run("TruncatesAtMaxLines", func(t *testing.T) {
+		ctx := WithToken(context.Background(), token)
+		result, err := server.handleExportLogsText(ctx, createMockRequest(map[string]interface{}{
+			"project_ids": []interface{}{project1ID},
+			"max_lines":   float64(2),
+		}))
+		if err != nil {
+			t.Fatalf("handleExportLogsText returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Expected success, got error result")
+		}
+
+		lines := strings.Split(strings.TrimSuffix(result.Content[0].(mcp.TextContent).Text, "\n"), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Expected 2 logs and a truncation marker, got %q", lines)
+		}
+		// The message's line break is escaped, and a missing source shown as -
+		if want := stamp.Format(time.RFC3339) + ` ERROR    [-] panic: boom\ngoroutine 1`; lines[0] != want {
+			t.Errorf("Expected first line %q, got %q", want, lines[0])
+		}
+		if !strings.Contains(lines[1], " [test-source] Test ") {
+			t.Errorf("Expected a fixture log on the second line, got %q", lines[1])
+		}
+		if want := "[truncated: showing logs 1-2 of 4; 2 more not shown, continue with offset 2"; !strings.HasPrefix(lines[2], want) {
+			t.Errorf("Expected marker starting %q, got %q", want, lines[2])
+		}
+	})
+
+	t.Run("AccessDenied", func(t *testing.T) {
+		token2, _ := createTestToken(t, db, userID, `["test-project-1"]`)
+		ctx := WithToken(context.Background(), token2)
+		result, err := server.handleExportLogsText(ctx, createMockRequest(map[string]interface{}{
+			"project_ids": []interface{}{project2ID},
+		}))
+		if err != nil {
+			t.Fatalf("handleExportLogsText returned error: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("Expected error result for access denied")
+		}
+	})
+
+	server.activityWG.Wait()
+	activities, _, err := server.mcpActivityRepo.GetByTokenID(token.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get activities: %v", err)
+	}
+	if len(activities) != 1 || activities[0].ToolName != "export_logs_text" || !activities[0].Success {
+		t.Errorf("Expected one successful export_logs_text activity, got %+v", activities)
+	}
+}
+
 // TestHandleSearchLogs tests the handleSearchLogs tool
 func TestHandleSearchLogs(t *testing.T) {
 	db := setupTestDB(t)
@@ -581,7 +896,7 @@ func TestHandleSearchLogs(t *testing.T) {
 
 	// Test successful search
 	t.Run("Success", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"query": "test",
 		})
@@ -598,7 +913,7 @@ func TestHandleSearchLogs(t *testing.T) {
 
 	// Test with filters
 	t.Run("WithFilters", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"query":       "error",
 			"project_ids": []interface{}{project1ID},
@@ -618,7 +933,7 @@ func TestHandleSearchLogs(t *testing.T) {
 
 	// Test missing query parameter
 	t.Run("MissingQuery", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{})
 
 		result, err := server.handleSearchLogs(ctx, request)
@@ -650,7 +965,7 @@ func TestHandleGetStats(t *testing.T) {
 
 	// Test overview stats
 	t.Run("Overview", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"scope": "overview",
 		})
@@ -661,13 +976,29 @@ func TestHandleGetStats(t *testing.T) {
 		}
 
 		if result.IsError {
-			t.Errorf("Expected success, got error result")
+			t.Fatalf("Expected success, got error result")
+		}
+
+		output := result.StructuredContent.(GetStatsOutput)
+		if output.TotalLogs != 5 {
+			t.Errorf("Expected 5 total logs, got %d", output.TotalLogs)
+		}
+		want := map[string]int{"debug": 1, "info": 2, "warn": 1, "error": 1}
+		for level, count := range want {
+			if output.LogsByLevel[level] != count {
+				t.Errorf("Expected %d %s logs, got %d", count, level, output.LogsByLevel[level])
+			}
+		}
+		for _, p := range output.Projects {
+			if p.ID == project1ID && p.LogCount != 3 {
+				t.Errorf("Expected 3 logs for project 1, got %d", p.LogCount)
+			}
 		}
 	})
 
 	// Test project stats
 	t.Run("ProjectStats", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"scope":      "project",
 			"project_id": project1ID,
@@ -685,7 +1016,7 @@ func TestHandleGetStats(t *testing.T) {
 
 	// Test invalid scope
 	t.Run("InvalidScope", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"scope": "invalid",
 		})
@@ -702,7 +1033,7 @@ func TestHandleGetStats(t *testing.T) {
 
 	// Test project scope without project_id
 	t.Run("MissingProjectID", func(t *testing.T) {
-		ctx := context.WithValue(context.Background(), "mcp_token", token)
+		ctx := WithToken(context.Background(), token)
 		request := createMockRequest(map[string]interface{}{
 			"scope": "project",
 		})
@@ -720,7 +1051,7 @@ func TestHandleGetStats(t *testing.T) {
 	// Test access denied for project stats
 	t.Run("AccessDenied", func(t *testing.T) {
 		token2, _ := createTestToken(t, db, userID, `["test-project-2"]`)
-		ctx := context.WithValue(context.Background(), "mcp_token", token2)
+		ctx := WithToken(context.Background(), token2)
 
 		request := createMockRequest(map[string]interface{}{
 			"scope":      "project",
@@ -737,3 +1068,604 @@ func TestHandleGetStats(t *testing.T) {
 		}
 	})
 }
+
+// TestHandleCompareLogVolume tests the compare_log_volume tool
+func TestHandleCompareLogVolume(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userID, project1ID, project2ID, _ := setupTestData(t, db)
+
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+	}
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	insert := func(id, projectID, level string, at time.Time) {
+		_, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, timestamp) VALUES (?, ?, ?, ?, ?)`,
+			id, projectID, level, "volume test", at)
+		if err != nil {
+			t.Fatalf("Failed to insert log %s: %v", id, err)
+		}
+	}
+	// Baseline day: 2 errors in project 1
+	insert("base-1", project1ID, "ERROR", day.Add(1*time.Hour))
+	insert("base-2", project1ID, "ERROR", day.Add(2*time.Hour))
+	// Current day: 5 errors and 1 info in project 1, plus errors in project 2
+	for i := 0; i < 5; i++ {
+		insert(fmt.Sprintf("cur-%d", i), project1ID, "ERROR", day.Add(24*time.Hour+time.Duration(i)*time.Hour))
+	}
+	insert("cur-info", project1ID, "INFO", day.Add(25*time.Hour))
+	insert("cur-p2-1", project2ID, "ERROR", day.Add(26*time.Hour))
+	insert("cur-p2-2", project2ID, "ERROR", day.Add(27*time.Hour))
+
+	windows := map[string]interface{}{
+		"baseline_start": day.Format(time.RFC3339),
+		"baseline_end":   day.Add(24*time.Hour - time.Second).Format(time.RFC3339),
+		"current_start":  day.Add(24 * time.Hour).Format(time.RFC3339),
+		"current_end":    day.Add(48*time.Hour - time.Second).Format(time.RFC3339),
+	}
+	params := func(extra map[string]interface{}) map[string]interface{} {
+		p := map[string]interface{}{}
+		for k, v := range windows {
+			p[k] = v
+		}
+		for k, v := range extra {
+			p[k] = v
+		}
+		return p
+	}
+
+	t.Run("ComparesWindowsWithinGrantedProjects", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, `["test-project-1"]`)
+		ctx := WithToken(context.Background(), token)
+
+		result, err := server.handleCompareLogVolume(ctx, createMockRequest(params(map[string]interface{}{"level": "error"})))
+		if err != nil {
+			t.Fatalf("handleCompareLogVolume returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Expected success, got error result: %v", result.Content)
+		}
+
+		output, ok := result.StructuredContent.(*CompareLogVolumeOutput)
+		if !ok {
+			t.Fatalf("Unexpected structured content %T", result.StructuredContent)
+		}
+		if output.Baseline.Count != 2 || output.Current.Count != 5 {
+			t.Errorf("Expected counts 2 -> 5, got %d -> %d", output.Baseline.Count, output.Current.Count)
+		}
+		if output.Change != 3 {
+			t.Errorf("Expected change 3, got %d", output.Change)
+		}
+		if output.PercentChange == nil || *output.PercentChange != 150 {
+			t.Errorf("Expected +150%%, got %v", output.PercentChange)
+		}
+		if !output.Baseline.Start.Equal(day) || !output.Current.Start.Equal(day.Add(24*time.Hour)) {
+			t.Errorf("Expected windows to be echoed back, got %+v / %+v", output.Baseline, output.Current)
+		}
+	})
+
+	t.Run("EmptyBaselineHasNoPercentage", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, "*")
+		ctx := WithToken(context.Background(), token)
+
+		result, err := server.handleCompareLogVolume(ctx, createMockRequest(params(map[string]interface{}{
+			"project_ids": []interface{}{project2ID},
+		})))
+		if err != nil {
+			t.Fatalf("handleCompareLogVolume returned error: %v", err)
+		}
+		output := result.StructuredContent.(*CompareLogVolumeOutput)
+		if output.Baseline.Count != 0 || output.Current.Count != 2 {
+			t.Errorf("Expected counts 0 -> 2, got %d -> %d", output.Baseline.Count, output.Current.Count)
+		}
+		if output.PercentChange != nil {
+			t.Errorf("Expected nil percent change for an empty baseline, got %v", *output.PercentChange)
+		}
+	})
+
+	t.Run("AccessDenied", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, `["test-project-1"]`)
+		ctx := WithToken(context.Background(), token)
+
+		result, err := server.handleCompareLogVolume(ctx, createMockRequest(params(map[string]interface{}{
+			"project_ids": []interface{}{project2ID},
+		})))
+		if err != nil {
+			t.Fatalf("handleCompareLogVolume returned error: %v", err)
+		}
+		if !result.IsError {
+			t.Error("Expected error result for a project outside the token's grant")
+		}
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, "*")
+		ctx := WithToken(context.Background(), token)
+
+		result, err := server.handleCompareLogVolume(ctx, createMockRequest(params(map[string]interface{}{
+			"current_end": day.Format(time.RFC3339),
+		})))
+		if err != nil {
+			t.Fatalf("handleCompareLogVolume returned error: %v", err)
+		}
+		if !result.IsError {
+			t.Error("Expected error result when a window ends before it starts")
+		}
+	})
+}
+
+// TestHandleGetLogContext tests the get_log_context tool
+func TestHandleGetLogContext(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userID, project1ID, project2ID, _ := setupTestData(t, db)
+
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+	}
+
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		_, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, timestamp) VALUES (?, ?, ?, ?, ?)`,
+			fmt.Sprintf("ctx-%d", i), project1ID, "INFO", fmt.Sprintf("step %d", i), base.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("Failed to insert log: %v", err)
+		}
+	}
+	// Same moment, different project
+	if _, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		"ctx-other", project2ID, "ERROR", "other project", base.Add(2*time.Second)); err != nil {
+		t.Fatalf("Failed to insert log: %v", err)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, `["test-project-1"]`)
+		ctx := WithToken(context.Background(), token)
+
+		result, err := server.handleGetLogContext(ctx, createMockRequest(map[string]interface{}{
+			"log_id": "ctx-2",
+			"window": float64(1),
+		}))
+		if err != nil {
+			t.Fatalf("handleGetLogContext returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Expected success, got error result: %v", result.Content)
+		}
+
+		output := result.StructuredContent.(*GetLogContextOutput)
+		if output.Log.ID != "ctx-2" {
+			t.Errorf("Expected target log ctx-2, got %s", output.Log.ID)
+		}
+		if len(output.Before) != 1 || output.Before[0].ID != "ctx-1" {
+			t.Errorf("Expected [ctx-1] before, got %v", output.Before)
+		}
+		if len(output.After) != 1 || output.After[0].ID != "ctx-3" {
+			t.Errorf("Expected [ctx-3] after, got %v", output.After)
+		}
+	})
+
+	t.Run("AccessDenied", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, `["test-project-2"]`)
+		ctx := WithToken(context.Background(), token)
+
+		result, err := server.handleGetLogContext(ctx, createMockRequest(map[string]interface{}{
+			"log_id": "ctx-2",
+		}))
+		if err != nil {
+			t.Fatalf("handleGetLogContext returned error: %v", err)
+		}
+		if !result.IsError {
+			t.Error("Expected error result for a log outside the token's projects")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		token, _ := createTestToken(t, db, userID, "*")
+		ctx := WithToken(context.Background(), token)
+
+		result, err := server.handleGetLogContext(ctx, createMockRequest(map[string]interface{}{
+			"log_id": "missing",
+		}))
+		if err != nil {
+			t.Fatalf("handleGetLogContext returned error: %v", err)
+		}
+		if !result.IsError {
+			t.Error("Expected error result for a missing log")
+		}
+	})
+}
+
+// TestHandleGetErrorClusters tests the get_error_clusters tool
+func TestHandleGetErrorClusters(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userID, project1ID, project2ID, _ := setupTestData(t, db)
+
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+	}
+
+	now := time.Now().UTC()
+	n := 0
+	insert := func(projectID, level, message string, at time.Time) {
+		n++
+		_, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, timestamp) VALUES (?, ?, ?, ?, ?)`,
+			fmt.Sprintf("cluster-%d", n), projectID, level, message, at)
+		if err != nil {
+			t.Fatalf("Failed to insert log: %v", err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		insert(project1ID, "ERROR", fmt.Sprintf("payment %d timed out after %dms", 1000+i, 300+i), now.Add(-time.Duration(i+1)*time.Hour))
+	}
+	insert(project1ID, "CRITICAL", "payment 2000 timed out after 999ms", now.Add(-30*time.Minute))
+	insert(project1ID, "ERROR", "user 8f14e45f-ceea-467f-a0e6-a1bd16f4b3a2 not found", now.Add(-2*time.Hour))
+	insert(project1ID, "ERROR", "user 1c383cd3-0b7c-4bf2-9b5f-2d2b5f6e4f10 not found", now.Add(-3*time.Hour))
+	insert(project1ID, "WARN", "payment 3000 timed out after 1ms", now.Add(-time.Hour))
+	insert(project1ID, "ERROR", "payment 4000 timed out after 2ms", now.Add(-72*time.Hour))
+	insert(project2ID, "ERROR", "payment 5000 timed out after 3ms", now.Add(-time.Hour))
+
+	call := func(grant string, params map[string]interface{}) *mcp.CallToolResult {
+		token, _ := createTestToken(t, db, userID, grant)
+		result, err := server.handleGetErrorClusters(WithToken(context.Background(), token), createMockRequest(params))
+		if err != nil {
+			t.Fatalf("handleGetErrorClusters returned error: %v", err)
+		}
+		return result
+	}
+
+	t.Run("GroupsByPatternWithinGrantedProjects", func(t *testing.T) {
+		result := call(`["test-project-1"]`, map[string]interface{}{})
+		if result.IsError {
+			t.Fatalf("Expected success, got error result: %v", result.Content)
+		}
+		output := result.StructuredContent.(*GetErrorClustersOutput)
+
+		if output.TotalClusters != 2 || len(output.Clusters) != 2 || output.Scanned != 7 {
+			t.Fatalf("Expected 2 clusters from 7 recent errors, got %+v", output)
+		}
+		top := output.Clusters[0]
+		if top.Pattern != "payment <n> timed out after <n>ms" || top.Count != 5 || top.ProjectID != project1ID {
+			t.Errorf("Expected the payment timeouts first, got %+v", top)
+		}
+		if top.Levels["ERROR"] != 4 || top.Levels["CRITICAL"] != 1 {
+			t.Errorf("Expected counts per level, got %v", top.Levels)
+		}
+		if top.SampleMessage != "payment 2000 timed out after 999ms" {
+			t.Errorf("Expected the latest occurrence as sample, got %q", top.SampleMessage)
+		}
+		if !top.FirstSeen.Before(top.LastSeen) || top.LastSeen.Before(now.Add(-31*time.Minute)) {
+			t.Errorf("Expected first and last seen to span the cluster, got %s - %s", top.FirstSeen, top.LastSeen)
+		}
+		if output.Clusters[1].Pattern != "user <uuid> not found" || output.Clusters[1].Count != 2 {
+			t.Errorf("Expected UUIDs masked into one cluster, got %+v", output.Clusters[1])
+		}
+	})
+
+	t.Run("CapsClustersAndWindow", func(t *testing.T) {
+		result := call("*", map[string]interface{}{"limit": float64(1), "hours": float64(100)})
+		output := result.StructuredContent.(*GetErrorClustersOutput)
+		if len(output.Clusters) != 1 || output.TotalClusters != 3 {
+			t.Errorf("Expected 1 of 3 clusters, got %d of %d", len(output.Clusters), output.TotalClusters)
+		}
+		if output.Clusters[0].Count != 6 {
+			t.Errorf("Expected the 72h-old error inside a 100h window, got count %d", output.Clusters[0].Count)
+		}
+	})
+
+	t.Run("AccessDenied", func(t *testing.T) {
+		result := call(`["test-project-1"]`, map[string]interface{}{"project_ids": []interface{}{project2ID}})
+		if !result.IsError {
+			t.Error("Expected error result for a project outside the token's grant")
+		}
+	})
+
+	t.Run("NoGrantedProjects", func(t *testing.T) {
+		result := call(`[]`, map[string]interface{}{})
+		output := result.StructuredContent.(*GetErrorClustersOutput)
+		if len(output.Clusters) != 0 || output.Scanned != 0 {
+			t.Errorf("Expected nothing for a token without projects, got %+v", output)
+		}
+	})
+}
+
+func TestHandleGetProjectHealth(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userID, _, _, _ := setupTestData(t, db)
+
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+	}
+
+	projectID := "health-project"
+	if _, err := db.Exec(`INSERT INTO projects (id, name, api_key) VALUES (?, ?, ?)`, projectID, "Checkout", "key3"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	now := time.Now().UTC()
+	n := 0
+	insert := func(level, source, message string, at time.Time) {
+		n++
+		_, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, source, timestamp) VALUES (?, ?, ?, ?, ?, ?)`,
+			fmt.Sprintf("health-%d", n), projectID, level, message, source, at)
+		if err != nil {
+			t.Fatalf("Failed to insert log: %v", err)
+		}
+	}
+	// Last 24h: 10 logs, 4 of them errors
+	for i := 0; i < 6; i++ {
+		insert("INFO", "api", "request served", now.Add(-time.Duration(i+1)*time.Minute))
+	}
+	insert("ERROR", "payments", "charge 101 declined", now.Add(-10*time.Minute))
+	insert("ERROR", "payments", "charge 102 declined", now.Add(-2*time.Hour))
+	insert("ERROR", "db", "connection reset", now.Add(-3*time.Hour))
+	insert("CRITICAL", "db", "replica 2 unreachable", now.Add(-5*time.Hour))
+	// The 24h before: 10 logs, 1 error
+	for i := 0; i < 9; i++ {
+		insert("INFO", "api", "request served", now.Add(-30*time.Hour))
+	}
+	insert("ERROR", "cache", "eviction storm", now.Add(-30*time.Hour))
+	// An older critical doesn't hide the newer one
+	insert("CRITICAL", "db", "primary down", now.Add(-72*time.Hour))
+
+	call := func(grant string, params map[string]interface{}) *mcp.CallToolResult {
+		token, _ := createTestToken(t, db, userID, grant)
+		result, err := server.handleGetProjectHealth(WithToken(context.Background(), token), createMockRequest(params))
+		if err != nil {
+			t.Fatalf("handleGetProjectHealth returned error: %v", err)
+		}
+		return result
+	}
+
+	t.Run("Snapshot", func(t *testing.T) {
+		result := call("*", map[string]interface{}{"project_id": projectID})
+		if result.IsError {
+			t.Fatalf("Expected success, got error result: %v", result.Content)
+		}
+		output := result.StructuredContent.(*GetProjectHealthOutput)
+
+		if output.ProjectName != "Checkout" || output.TotalLogs != 21 || output.LogsLastHour != 7 || output.LogsLastDay != 10 {
+			t.Errorf("Expected 21 total, 7 in the hour and 10 in the day, got %+v", output)
+		}
+
+		rate := output.ErrorRate
+		if rate.CurrentErrors != 4 || rate.PreviousErrors != 1 || rate.Current != 0.4 || rate.Previous != 0.1 {
+			t.Errorf("Expected error rates 0.4 (4) vs 0.1 (1), got %+v", rate)
+		}
+		if rate.Trend != TrendRising {
+			t.Errorf("Expected a rising trend, got %q", rate.Trend)
+		}
+
+		if len(output.TopErrorSources) != 2 || output.TopErrorSources[0].Count != 2 {
+			t.Errorf("Expected payments and db with 2 errors each, got %+v", output.TopErrorSources)
+		}
+		if len(output.TopErrors) != 3 || output.TopErrors[0].Pattern != "charge <n> declined" || output.TopErrors[0].Count != 2 {
+			t.Errorf("Expected the declined charges as the top error, got %+v", output.TopErrors)
+		}
+
+		if output.LatestCritical == nil || output.LatestCritical.Message != "replica 2 unreachable" {
+			t.Errorf("Expected the newest critical log, got %+v", output.LatestCritical)
+		}
+	})
+
+	t.Run("QuietProject", func(t *testing.T) {
+		result := call("*", map[string]interface{}{"project_id": "test-project-2"})
+		output := result.StructuredContent.(*GetProjectHealthOutput)
+		if output.ErrorRate.Trend != TrendSteady || output.LatestCritical != nil {
+			t.Errorf("Expected a steady trend and no critical log, got %+v", output)
+		}
+		if output.TopErrorSources == nil || output.TopErrors == nil {
+			t.Error("Expected empty lists rather than null")
+		}
+	})
+
+	t.Run("AccessDenied", func(t *testing.T) {
+		result := call(`["test-project-1"]`, map[string]interface{}{"project_id": projectID})
+		if !result.IsError {
+			t.Error("Expected error result for a project outside the token's grant")
+		}
+	})
+
+	t.Run("ProjectNotFound", func(t *testing.T) {
+		result := call("*", map[string]interface{}{"project_id": "missing"})
+		if !result.IsError {
+			t.Error("Expected error result for an unknown project")
+		}
+	})
+}
+
+func TestErrorRateTrend(t *testing.T) {
+	tests := []struct {
+		current, previous float64
+		logs              int
+		want              string
+	}{
+		{0, 0, 0, TrendNoData},
+		{0.2, 0.1, 100, TrendRising},
+		{0.05, 0.2, 100, TrendFalling},
+		{0.205, 0.2, 100, TrendSteady}, // Under a point
+		{0.5, 0.46, 100, TrendSteady},  // Under a tenth of the previous rate
+	}
+	for _, tt := range tests {
+		if got := errorRateTrend(tt.current, tt.previous, tt.logs); got != tt.want {
+			t.Errorf("errorRateTrend(%v, %v, %d) = %q, want %q", tt.current, tt.previous, tt.logs, got, tt.want)
+		}
+	}
+}
+
+// TestToolsRequireToken checks every tool rejects a context without a token
+func TestHandleAcknowledgeLog(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userID, _, _, logID := setupTestData(t, db)
+	auditRepo := models.NewAuditLogRepository(db)
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+		auditRepo:       auditRepo,
+	}
+	writeToken := func(grantedProjects string) *models.MCPToken {
+		token, _ := createTestToken(t, db, userID, grantedProjects)
+		token.Scope = models.MCPScopeWrite
+		if err := server.mcpTokenRepo.Update(token); err != nil {
+			t.Fatalf("Failed to grant write scope: %v", err)
+		}
+		return token
+	}
+	call := func(token *models.MCPToken, args map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		result, err := server.handleAcknowledgeLog(WithToken(context.Background(), token), createMockRequest(args))
+		if err != nil {
+			t.Fatalf("handleAcknowledgeLog returned error: %v", err)
+		}
+		return result
+	}
+	statusOf := func(id string) models.LogStatus {
+		log, err := server.logRepo.GetByID(id)
+		if err != nil || log == nil {
+			t.Fatalf("Failed to get log %s: %v", id, err)
+		}
+		return log.Status
+	}
+
+	t.Run("ReadScopeDenied", func(t *testing.T) {
+		readToken, _ := createTestToken(t, db, userID, "*")
+		result := call(readToken, map[string]interface{}{"log_id": logID})
+		if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "write scope") {
+			t.Errorf("Expected a write scope error, got %v", result.Content)
+		}
+		if statusOf(logID) != models.LogStatusNew {
+			t.Error("A read-only token must not change the log")
+		}
+	})
+
+	t.Run("UngrantedProjectDenied", func(t *testing.T) {
+		token := writeToken(`["test-project-2"]`)
+		result := call(token, map[string]interface{}{"log_id": logID})
+		if !result.IsError {
+			t.Error("Expected access denied for a log outside the granted projects")
+		}
+		if statusOf(logID) != models.LogStatusNew {
+			t.Error("The log must not change without project access")
+		}
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		result := call(writeToken("*"), map[string]interface{}{"log_id": logID, "status": "closed"})
+		if !result.IsError {
+			t.Error("Expected an error for an unknown status")
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		token := writeToken(`["test-project-1"]`)
+		result := call(token, map[string]interface{}{"log_id": logID, "comment": "Looking into it"})
+		if result.IsError {
+			t.Fatalf("Expected success, got %v", result.Content)
+		}
+		log, _ := server.logRepo.GetByID(logID)
+		if log.Status != models.LogStatusAcknowledged || log.AcknowledgedBy != userID {
+			t.Errorf("Expected the log acknowledged by the token's creator, got %+v", log)
+		}
+
+		entries, _, err := auditRepo.List(models.AuditLogFilter{Action: models.AuditLogStatusChange, Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to list audit entries: %v", err)
+		}
+		if len(entries) != 1 || entries[0].TargetID != logID || entries[0].ActorName != "testuser" ||
+			!strings.Contains(entries[0].Details, "status=new->acknowledged") || !strings.Contains(entries[0].Details, `comment="Looking into it"`) {
+			t.Errorf("Expected one audit entry for the change, got %+v", entries)
+		}
+
+		server.activityWG.Wait()
+		activities, _, err := server.mcpActivityRepo.GetByTokenID(token.ID, 10, 0)
+		if err != nil {
+			t.Fatalf("Failed to get activity: %v", err)
+		}
+		if len(activities) != 1 || activities[0].ToolName != "acknowledge_log" || !activities[0].Success {
+			t.Errorf("Expected the call in the activity log, got %+v", activities)
+		}
+	})
+}
+
+func TestToolsRequireToken(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	server := &MCPServer{
+		mcpTokenRepo:    models.NewMCPTokenRepository(db),
+		mcpActivityRepo: models.NewMCPActivityLogRepository(db),
+		logRepo:         models.NewLogRepository(db),
+		projectRepo:     models.NewProjectRepository(db),
+		userRepo:        models.NewUserRepository(db),
+	}
+
+	handlers := map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		"query_logs":         server.handleQueryLogs,
+		"get_log":            server.handleGetLog,
+		"list_projects":      server.handleListProjects,
+		"get_project":        server.handleGetProject,
+		"get_stats":          server.handleGetStats,
+		"search_logs":        server.handleSearchLogs,
+		"get_recent_logs":    server.handleGetRecentLogs,
+		"compare_log_volume": server.handleCompareLogVolume,
+		"get_log_context":    server.handleGetLogContext,
+		"get_error_clusters": server.handleGetErrorClusters,
+		"get_project_health": server.handleGetProjectHealth,
+		"resolve_project":    server.handleResolveProject,
+		"export_logs_text":   server.handleExportLogsText,
+		"acknowledge_log":    server.handleAcknowledgeLog,
+	}
+
+	// A token stored under a plain string key must not be picked up
+	token := &models.MCPToken{ID: "t", GrantedProjects: "*", IsActive: true}
+	contexts := map[string]context.Context{
+		"no token":        context.Background(),
+		"string key":      context.WithValue(context.Background(), "mcp_token", token),
+		"nil typed value": WithToken(context.Background(), nil),
+	}
+
+	for name, handler := range handlers {
+		for ctxName, ctx := range contexts {
+			result, err := handler(ctx, createMockRequest(map[string]interface{}{}))
+			if err != nil {
+				t.Fatalf("%s (%s) returned error: %v", name, ctxName, err)
+			}
+			if !result.IsError {
+				t.Errorf("%s (%s): expected auth error result", name, ctxName)
+				continue
+			}
+			text, ok := result.Content[0].(mcp.TextContent)
+			if !ok || text.Text != "Authentication error: missing MCP token" {
+				t.Errorf("%s (%s): expected consistent auth error, got %v", name, ctxName, result.Content)
+			}
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package mcp
 
 import (
+	"time"
+
 	"central-logs/internal/models"
 )
 
@@ -12,6 +14,7 @@ type QueryLogsInput struct {
 	Search     string   `json:"search,omitempty"`
 	StartTime  string   `json:"start_time,omitempty"` // RFC3339 format
 	EndTime    string   `json:"end_time,omitempty"`   // RFC3339 format
+	TimeField  string   `json:"time_field,omitempty"` // timestamp (default) or created_at
 	Limit      int      `json:"limit,omitempty"`
 	Offset     int      `json:"offset,omitempty"`
 }
@@ -34,12 +37,18 @@ type GetLogOutput struct {
 
 // Tool 3: list_projects - List accessible projects
 type ListProjectsInput struct {
-	// No parameters - returns projects based on token's access
+	NameContains string `json:"name_contains,omitempty"` // Case-insensitive substring of the name
+	ActiveOnly   bool   `json:"active_only,omitempty"`
+	Limit        int    `json:"limit,omitempty"`  // Default 50, max 200
+	Offset       int    `json:"offset,omitempty"`
 }
 
 type ListProjectsOutput struct {
 	Projects []*models.Project `json:"projects"`
-	Count    int               `json:"count"`
+	Count    int               `json:"count"` // Projects in this page
+	Total    int               `json:"total"` // Projects matching the filters
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
 }
 
 // Tool 4: get_project - Get project details with statistics
@@ -57,6 +66,7 @@ type GetProjectOutput struct {
 type GetStatsInput struct {
 	Scope     string `json:"scope"`      // "overview" or "project"
 	ProjectID string `json:"project_id,omitempty"` // Required if scope is "project"
+	Timezone  string `json:"timezone,omitempty"`   // Zone for logs_today; defaults to the server's stats.timezone
 }
 
 type ProjectStatsSummary struct {
@@ -71,6 +81,7 @@ type GetStatsOutput struct {
 	TotalProjects int                    `json:"total_projects,omitempty"`
 	TotalLogs     int                    `json:"total_logs,omitempty"`
 	LogsToday     int                    `json:"logs_today,omitempty"`
+	Timezone      string                 `json:"timezone,omitempty"` // Zone whose midnight starts logs_today
 	TotalUsers    int                    `json:"total_users,omitempty"`
 	Projects      []ProjectStatsSummary  `json:"projects,omitempty"`
 
@@ -98,3 +109,153 @@ type GetRecentLogsInput struct {
 type GetRecentLogsOutput struct {
 	Logs []*models.Log `json:"logs"`
 }
+
+// Tool 8: compare_log_volume - Compare log counts between two time windows
+type CompareLogVolumeInput struct {
+	ProjectIDs    []string `json:"project_ids,omitempty"`
+	Level         string   `json:"level,omitempty"`
+	BaselineStart string   `json:"baseline_start"` // RFC3339 format
+	BaselineEnd   string   `json:"baseline_end"`   // RFC3339 format
+	CurrentStart  string   `json:"current_start"`  // RFC3339 format
+	CurrentEnd    string   `json:"current_end"`    // RFC3339 format
+	TimeField     string   `json:"time_field,omitempty"`
+}
+
+type VolumeWindow struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Count   int       `json:"count"`
+	PerHour float64   `json:"per_hour"` // Normalised so windows of different length compare fairly
+}
+
+type CompareLogVolumeOutput struct {
+	ProjectIDs    []string     `json:"project_ids,omitempty"` // Empty means all accessible projects
+	Level         string       `json:"level,omitempty"`
+	TimeField     string       `json:"time_field"`
+	Baseline      VolumeWindow `json:"baseline"`
+	Current       VolumeWindow `json:"current"`
+	Change        int          `json:"change"`
+	PercentChange *float64     `json:"percent_change"` // null when the baseline has no logs
+}
+
+// Tool 9: get_log_context - Logs surrounding a given log in the same project
+type GetLogContextInput struct {
+	LogID  string `json:"log_id"`
+	Window int    `json:"window,omitempty"`
+}
+
+type GetLogContextOutput struct {
+	Log    *models.Log   `json:"log"`
+	Before []*models.Log `json:"before"` // Oldest first
+	After  []*models.Log `json:"after"`  // Oldest first
+}
+
+// Tool 10: get_error_clusters - Recurring ERROR/CRITICAL logs grouped by message pattern
+type GetErrorClustersInput struct {
+	ProjectIDs []string `json:"project_ids,omitempty"`
+	Hours      int      `json:"hours,omitempty"`
+	Limit      int      `json:"limit,omitempty"`
+}
+
+type ErrorCluster struct {
+	ProjectID     string         `json:"project_id"`
+	ProjectName   string         `json:"project_name"`
+	Pattern       string         `json:"pattern"` // Message with numbers, UUIDs and hex IDs masked
+	Count         int            `json:"count"`
+	Levels        map[string]int `json:"levels"` // Count per level, ERROR and CRITICAL
+	SampleLogID   string         `json:"sample_log_id"`
+	SampleMessage string         `json:"sample_message"` // Most recent occurrence
+	FirstSeen     time.Time      `json:"first_seen"`
+	LastSeen      time.Time      `json:"last_seen"`
+}
+
+type GetErrorClustersOutput struct {
+	Clusters      []ErrorCluster `json:"clusters"` // Largest first
+	TotalClusters int            `json:"total_clusters"`
+	Since         time.Time      `json:"since"`
+	Scanned       int            `json:"scanned"`   // Logs grouped
+	Truncated     bool           `json:"truncated"` // More logs matched than were scanned; older ones were left out
+}
+
+// Tool 11: get_project_health - One-call health snapshot of a project. Agents
+// rely on these fields: new ones may be added, existing ones keep their name
+// and meaning.
+type GetProjectHealthInput struct {
+	ProjectID string `json:"project_id"`
+}
+
+// Error rate trends
+const (
+	TrendRising  = "rising"
+	TrendFalling = "falling"
+	TrendSteady  = "steady"
+	TrendNoData  = "no_data" // No logs in either period
+)
+
+type ErrorRateTrend struct {
+	Current        float64 `json:"current"`  // Share of the last 24h's logs that were ERROR or CRITICAL, 0 to 1
+	Previous       float64 `json:"previous"` // The same share for the 24h before that
+	CurrentErrors  int     `json:"current_errors"`
+	PreviousErrors int     `json:"previous_errors"`
+	Trend          string  `json:"trend"` // rising, falling, steady or no_data
+}
+
+type GetProjectHealthOutput struct {
+	ProjectID       string              `json:"project_id"`
+	ProjectName     string              `json:"project_name"`
+	IsActive        bool                `json:"is_active"`
+	TotalLogs       int                 `json:"total_logs"`
+	LogsLastHour    int                 `json:"logs_last_hour"`
+	LogsLastDay     int                 `json:"logs_last_day"`
+	ErrorRate       ErrorRateTrend      `json:"error_rate"`
+	TopErrorSources []models.FacetCount `json:"top_error_sources"` // Up to 3 sources with the most errors in the last 24h; "" is logs without a source
+	TopErrors       []ErrorCluster      `json:"top_errors"`        // Up to 3 most repeated error patterns in the last 24h
+	LatestCritical  *models.Log         `json:"latest_critical"`   // Newest CRITICAL log ever, null if none
+	GeneratedAt     time.Time           `json:"generated_at"`
+}
+
+// Tool 12: resolve_project - Find projects by their name
+type ResolveProjectInput struct {
+	Name string `json:"name"` // Exact name or its start, case-insensitive
+}
+
+type ProjectMatch struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Group    string `json:"group,omitempty"`
+	IsActive bool   `json:"is_active"`
+	Exact    bool   `json:"exact"` // The whole name matched, not just its start
+}
+
+type ResolveProjectOutput struct {
+	Query     string         `json:"query"`
+	Matches   []ProjectMatch `json:"matches"` // Exact matches first
+	Count     int            `json:"count"`
+	Ambiguous bool           `json:"ambiguous"` // More than one match and no single exact one; pick by ID
+	Truncated bool           `json:"truncated"` // More projects matched than are listed
+}
+
+// Tool 13: export_logs_text - query_logs' results as plain text, one line per log
+type ExportLogsTextInput struct {
+	ProjectIDs []string `json:"project_ids,omitempty"`
+	Levels     []string `json:"levels,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	Search     string   `json:"search,omitempty"`
+	StartTime  string   `json:"start_time,omitempty"` // RFC3339 format
+	EndTime    string   `json:"end_time,omitempty"`   // RFC3339 format
+	TimeField  string   `json:"time_field,omitempty"` // timestamp (default) or created_at
+	MaxLines   int      `json:"max_lines,omitempty"`  // Default 200, capped like query_logs' limit
+	Offset     int      `json:"offset,omitempty"`
+}
+
+// Tool 14: acknowledge_log - Set a log's status; needs a write-scope token
+type AcknowledgeLogInput struct {
+	LogID   string `json:"log_id"`
+	Status  string `json:"status,omitempty"`  // Default acknowledged
+	Comment string `json:"comment,omitempty"` // Recorded in the audit trail
+}
+
+type AcknowledgeLogOutput struct {
+	Log            *models.Log      `json:"log"`
+	PreviousStatus models.LogStatus `json:"previous_status"`
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type accessLogEntry struct {
+	Time      string  `json:"time"`
+	RequestID string  `json:"request_id,omitempty"`
+	Status    int     `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	IP        string  `json:"ip"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	ProjectID string  `json:"project_id,omitempty"`
+	UserID    string  `json:"user_id,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// AccessLog writes one JSON line per request to out, including the request ID,
+// the project ID for API key requests and the user ID for authenticated ones.
+// Errors returned by handlers are passed to the app's error handler first so
+// the logged status matches what the client received.
+func AccessLog(out io.Writer) fiber.Handler {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(out)
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if chainErr := c.Next(); chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+			if GetRequestError(c) == "" {
+				SetRequestError(c, chainErr)
+			}
+		}
+
+		entry := accessLogEntry{
+			Time:      start.UTC().Format(time.RFC3339Nano),
+			RequestID: GetRequestID(c),
+			Status:    c.Response().StatusCode(),
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			IP:        c.IP(),
+			Method:    c.Method(),
+			Path:      c.Path(),
+			Error:     GetRequestError(c),
+		}
+		if project := GetProject(c); project != nil {
+			entry.ProjectID = project.ID
+		}
+		if user := GetUser(c); user != nil {
+			entry.UserID = user.ID
+		}
+
+		mu.Lock()
+		_ = encoder.Encode(entry)
+		mu.Unlock()
+
+		return nil
+	}
+}
+
+// SetRequestError records an internal error for the access log without
+// exposing it in the response
+func SetRequestError(c *fiber.Ctx, err error) {
+	if err != nil {
+		c.Locals("request_error", err.Error())
+	}
+}
+
+// GetRequestError returns the error recorded by SetRequestError
+func GetRequestError(c *fiber.Ctx) string {
+	msg, _ := c.Locals("request_error").(string)
+	return msg
+}
@@ -1,13 +1,16 @@
 package middleware
 
 import (
+	"time"
+
 	"central-logs/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type APIKeyMiddleware struct {
-	projectRepo *models.ProjectRepository
+	projectRepo  *models.ProjectRepository
+	usageTracker *APIKeyUsageTracker
 }
 
 func NewAPIKeyMiddleware(projectRepo *models.ProjectRepository) *APIKeyMiddleware {
@@ -16,7 +19,13 @@ func NewAPIKeyMiddleware(projectRepo *models.ProjectRepository) *APIKeyMiddlewar
 	}
 }
 
-// RequireAPIKey validates the X-API-Key header and sets project in context
+// SetUsageTracker enables recording of API key usage on each authenticated request
+func (m *APIKeyMiddleware) SetUsageTracker(tracker *APIKeyUsageTracker) {
+	m.usageTracker = tracker
+}
+
+// RequireAPIKey validates the X-API-Key header, and the X-Signature header for
+// projects that require signed requests, then sets project in context
 func (m *APIKeyMiddleware) RequireAPIKey() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		apiKey := c.Get("X-API-Key")
@@ -45,6 +54,31 @@ func (m *APIKeyMiddleware) RequireAPIKey() fiber.Handler {
 			})
 		}
 
+		if project.IngestionPaused {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Ingestion paused",
+			})
+		}
+
+		// Projects with a signing secret only accept bodies signed with it
+		if project.SigningSecret != "" {
+			signature := c.Get(SignatureHeader)
+			if signature == "" {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Missing signature",
+				})
+			}
+			if !VerifySignature(project.SigningSecret, c.Body(), signature) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Invalid signature",
+				})
+			}
+		}
+
+		if m.usageTracker != nil {
+			m.usageTracker.RecordAsync(project, time.Now())
+		}
+
 		// Set project in context
 		c.Locals("project", project)
 
@@ -2,9 +2,12 @@ package middleware_test
 
 import (
 	"database/sql"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"central-logs/internal/middleware"
 	"central-logs/internal/models"
@@ -28,8 +31,20 @@ func setupAPIKeyTestDB(t *testing.T) *sql.DB {
 			api_key_prefix TEXT NOT NULL,
 			is_active INTEGER NOT NULL DEFAULT 1,
 			retention_config TEXT,
+			redaction_config TEXT,
+			sampling_config TEXT,
+			signing_secret TEXT,
+			max_body_bytes INTEGER,
+			rate_limit_per_minute INTEGER,
+			rate_limit_burst INTEGER,
+			ingestion_paused INTEGER NOT NULL DEFAULT 0,
+			default_level TEXT,
+			require_level INTEGER NOT NULL DEFAULT 0,
+			outbound_webhook TEXT,
+			group_name TEXT,
 			icon_type TEXT DEFAULT 'initials',
 			icon_value TEXT DEFAULT '',
+			deleted_at DATETIME,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -130,6 +145,56 @@ func TestAPIKeyMiddleware_InvalidKey(t *testing.T) {
 	}
 }
 
+func TestAPIKeyMiddleware_IngestionPaused(t *testing.T) {
+	db := setupAPIKeyTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	project := &models.Project{Name: "Paused Project", IsActive: true}
+	apiKey, err := projectRepo.Create(project)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	send := func() *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/logs", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	if ok, err := projectRepo.SetIngestionPaused(project.ID, true); err != nil || !ok {
+		t.Fatalf("Failed to pause ingestion: ok=%v err=%v", ok, err)
+	}
+	resp := send()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status 403 while paused, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Ingestion paused") {
+		t.Errorf("Expected ingestion paused error, got %s", body)
+	}
+
+	// The key itself is untouched, so resuming accepts it again
+	if ok, err := projectRepo.SetIngestionPaused(project.ID, false); err != nil || !ok {
+		t.Fatalf("Failed to resume ingestion: ok=%v err=%v", ok, err)
+	}
+	if resp := send(); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 after resuming, got %d", resp.StatusCode)
+	}
+}
+
 func TestGetProject_ReturnsProject(t *testing.T) {
 	db := setupAPIKeyTestDB(t)
 	defer db.Close()
@@ -177,3 +242,141 @@ func TestGetProject_ReturnsProject(t *testing.T) {
 		t.Errorf("Expected project name %s, got %s", project.Name, gotProject.Name)
 	}
 }
+
+func TestAPIKeyUsageTracker_Record(t *testing.T) {
+	db := setupAPIKeyTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE api_key_usage (
+			project_id TEXT NOT NULL,
+			key_prefix TEXT NOT NULL,
+			last_used_at DATETIME NOT NULL,
+			PRIMARY KEY (project_id, key_prefix)
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create api_key_usage table: %v", err)
+	}
+
+	projectRepo := models.NewProjectRepository(db)
+	usageRepo := models.NewAPIKeyUsageRepository(db)
+	tracker := middleware.NewAPIKeyUsageTracker(usageRepo, nil)
+
+	project := &models.Project{Name: "Test Project", IsActive: true}
+	if _, err := projectRepo.Create(project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	usages, _ := usageRepo.GetByProjectID(project.ID)
+	if len(usages) != 0 {
+		t.Fatal("A new key should have no recorded usage")
+	}
+
+	first := time.Now().Add(-time.Hour)
+	tracker.Record(project, first)
+	// Within the persist interval this must not cause another write
+	tracker.Record(project, first.Add(10*time.Second))
+
+	usages, err = usageRepo.GetByProjectID(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get usage: %v", err)
+	}
+	if len(usages) != 1 || usages[0].KeyPrefix != project.APIKeyPrefix {
+		t.Fatalf("Expected one usage row for the current key, got %+v", usages)
+	}
+	if !usages[0].LastUsedAt.Equal(first) {
+		t.Errorf("Expected last used %s, got %s", first, usages[0].LastUsedAt)
+	}
+
+	later := first.Add(2 * time.Minute)
+	tracker.Record(project, later)
+	usages, _ = usageRepo.GetByProjectID(project.ID)
+	if !usages[0].LastUsedAt.Equal(later) {
+		t.Errorf("Expected last used to advance to %s, got %s", later, usages[0].LastUsedAt)
+	}
+
+	if _, ok := tracker.Requests24h(project.ID, project.APIKeyPrefix); ok {
+		t.Error("Request counts should be unavailable without Redis")
+	}
+
+	// Asynchronous records are written by the tracker's pool
+	async := later.Add(2 * time.Minute)
+	tracker.RecordAsync(project, async)
+	if remaining := tracker.Drain(time.Second); remaining != 0 {
+		t.Fatalf("Expected queued usage to be written, %d left", remaining)
+	}
+	usages, _ = usageRepo.GetByProjectID(project.ID)
+	if !usages[0].LastUsedAt.Equal(async) {
+		t.Errorf("Expected last used to advance to %s, got %s", async, usages[0].LastUsedAt)
+	}
+}
+
+func TestAPIKeyMiddleware_Signature(t *testing.T) {
+	db := setupAPIKeyTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
+
+	signed := &models.Project{Name: "Signed", IsActive: true}
+	signedKey, _ := projectRepo.Create(signed)
+	secret, err := projectRepo.RotateSigningSecret(signed.ID)
+	if err != nil {
+		t.Fatalf("Failed to set signing secret: %v", err)
+	}
+
+	unsigned := &models.Project{Name: "Unsigned", IsActive: true}
+	unsignedKey, _ := projectRepo.Create(unsigned)
+
+	app := fiber.New()
+	app.Use(apiKeyMiddleware.RequireAPIKey())
+	app.Post("/logs", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	body := `{"level":"INFO","message":"hello"}`
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		signature string
+		want      int
+	}{
+		{"valid signature", signedKey, middleware.ComputeSignature(secret, []byte(body)), http.StatusOK},
+		{"missing signature", signedKey, "", http.StatusUnauthorized},
+		{"wrong secret", signedKey, middleware.ComputeSignature("other-secret", []byte(body)), http.StatusUnauthorized},
+		{"signature of another body", signedKey, middleware.ComputeSignature(secret, []byte(body+" ")), http.StatusUnauthorized},
+		{"malformed signature", signedKey, "sha256=not-hex", http.StatusUnauthorized},
+		{"unsigned project ignores header", unsignedKey, "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/logs", strings.NewReader(body))
+			req.Header.Set("X-API-Key", tt.apiKey)
+			if tt.signature != "" {
+				req.Header.Set(middleware.SignatureHeader, tt.signature)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+
+	// Disabling signing lets unsigned requests through again
+	if err := projectRepo.ClearSigningSecret(signed.ID); err != nil {
+		t.Fatalf("Failed to clear signing secret: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/logs", strings.NewReader(body))
+	req.Header.Set("X-API-Key", signedKey)
+	resp, _ := app.Test(req)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 after disabling signing, got %d", resp.StatusCode)
+	}
+}
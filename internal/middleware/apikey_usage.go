@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+	"central-logs/internal/worker"
+)
+
+// Usage is recorded off the request path by a few goroutines; under a burst
+// the counts are approximate rather than a goroutine per request
+const (
+	usageRecordWorkers   = 2
+	usageRecordQueueSize = 1000
+)
+
+// APIKeyUsageTracker records API key activity. Request counts live in Redis
+// hourly counters; the last-used time is written to the database at most once
+// per persistInterval per key so ingestion doesn't pay for a write per request.
+type APIKeyUsageTracker struct {
+	usageRepo       *models.APIKeyUsageRepository
+	redisClient     *queue.RedisClient
+	persistInterval time.Duration
+	pool            *worker.TaskPool
+
+	mu            sync.Mutex
+	lastPersisted map[string]time.Time
+}
+
+func NewAPIKeyUsageTracker(usageRepo *models.APIKeyUsageRepository, redisClient *queue.RedisClient) *APIKeyUsageTracker {
+	return &APIKeyUsageTracker{
+		usageRepo:       usageRepo,
+		redisClient:     redisClient,
+		persistInterval: time.Minute,
+		pool:            worker.NewTaskPool(usageRecordWorkers, usageRecordQueueSize),
+		lastPersisted:   make(map[string]time.Time),
+	}
+}
+
+// RecordAsync queues a Record without waiting on Redis or the database. The
+// request is not counted when the queue is full.
+func (t *APIKeyUsageTracker) RecordAsync(project *models.Project, at time.Time) {
+	t.pool.Submit(func() { t.Record(project, at) })
+}
+
+// Drain stops accepting usage and waits up to timeout for queued records to
+// be written. It returns how many were left unfinished.
+func (t *APIKeyUsageTracker) Drain(timeout time.Duration) int {
+	return t.pool.Shutdown(timeout)
+}
+
+// Record notes a request made with the project's current API key
+func (t *APIKeyUsageTracker) Record(project *models.Project, at time.Time) {
+	if t.redisClient != nil {
+		if err := t.redisClient.RecordAPIKeyUsage(context.Background(), project.ID, project.APIKeyPrefix, at); err != nil {
+			log.Printf("Failed to record API key usage: %v", err)
+		}
+	}
+
+	key := project.ID + ":" + project.APIKeyPrefix
+	t.mu.Lock()
+	last, seen := t.lastPersisted[key]
+	due := !seen || at.Sub(last) >= t.persistInterval
+	if due {
+		t.lastPersisted[key] = at
+	}
+	t.mu.Unlock()
+
+	if due {
+		if err := t.usageRepo.Touch(project.ID, project.APIKeyPrefix, at); err != nil {
+			log.Printf("Failed to persist API key last-used time: %v", err)
+		}
+	}
+}
+
+// Requests24h returns the request count for a key over the last 24 hours.
+// ok is false when Redis is unavailable and no count is tracked.
+func (t *APIKeyUsageTracker) Requests24h(projectID, keyPrefix string) (count int64, ok bool) {
+	if t.redisClient == nil {
+		return 0, false
+	}
+	count, err := t.redisClient.CountAPIKeyUsage24h(context.Background(), projectID, keyPrefix, time.Now())
+	if err != nil {
+		return 0, false
+	}
+	return count, true
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
 	"central-logs/internal/models"
@@ -10,8 +11,9 @@ import (
 )
 
 type AuthMiddleware struct {
-	jwtManager *utils.JWTManager
-	userRepo   *models.UserRepository
+	jwtManager    *utils.JWTManager
+	userRepo      *models.UserRepository
+	revokedTokens *models.RevokedTokenRepository
 }
 
 func NewAuthMiddleware(jwtManager *utils.JWTManager, userRepo *models.UserRepository) *AuthMiddleware {
@@ -21,6 +23,45 @@ func NewAuthMiddleware(jwtManager *utils.JWTManager, userRepo *models.UserReposi
 	}
 }
 
+// SetRevokedTokens enables checking tokens against the revocation denylist
+func (m *AuthMiddleware) SetRevokedTokens(revokedTokens *models.RevokedTokenRepository) {
+	m.revokedTokens = revokedTokens
+}
+
+// SessionRevoked reports whether a session token is no longer valid for the
+// user: either its version predates the user's last revoke-sessions, or the
+// token itself was denylisted (e.g. on logout).
+func SessionRevoked(claims *utils.JWTClaims, user *models.User, revokedTokens *models.RevokedTokenRepository) (bool, error) {
+	if claims.TokenVersion != user.TokenVersion {
+		return true, nil
+	}
+	if revokedTokens == nil || claims.ID == "" {
+		return false, nil
+	}
+	return revokedTokens.IsRevoked(claims.ID)
+}
+
+// ErrImpersonatorInvalid means an impersonation token's admin has since been
+// deactivated, deleted or demoted, so the token is no longer honoured
+var ErrImpersonatorInvalid = errors.New("impersonating admin is no longer an active admin")
+
+// Impersonator returns the admin an impersonation token was issued to, or nil
+// for an ordinary token. The admin is looked up on every request so that
+// removing their access also ends any impersonation in progress.
+func Impersonator(claims *utils.JWTClaims, userRepo *models.UserRepository) (*models.User, error) {
+	if claims.ImpersonatedBy == "" {
+		return nil, nil
+	}
+	admin, err := userRepo.GetByID(claims.ImpersonatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if admin == nil || !admin.IsActive || !admin.IsAdmin() {
+		return nil, ErrImpersonatorInvalid
+	}
+	return admin, nil
+}
+
 // RequireAuth validates JWT token and sets user in context
 func (m *AuthMiddleware) RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -65,9 +106,36 @@ func (m *AuthMiddleware) RequireAuth() fiber.Handler {
 			})
 		}
 
+		revoked, err := SessionRevoked(claims, user, m.revokedTokens)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check token",
+			})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token has been revoked",
+			})
+		}
+
+		impersonator, err := Impersonator(claims, m.userRepo)
+		if errors.Is(err, ErrImpersonatorInvalid) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token has been revoked",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check token",
+			})
+		}
+
 		// Set user in context
 		c.Locals("user", user)
 		c.Locals("claims", claims)
+		if impersonator != nil {
+			c.Locals("impersonator", impersonator)
+		}
 
 		return c.Next()
 	}
@@ -86,6 +154,19 @@ func (m *AuthMiddleware) RequireAdmin() fiber.Handler {
 	}
 }
 
+// BlockImpersonation refuses the request when an admin is impersonating the
+// user, for actions only the account owner should take
+func (m *AuthMiddleware) BlockImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if GetImpersonator(c) != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Not available while impersonating a user",
+			})
+		}
+		return c.Next()
+	}
+}
+
 // GetUser returns the authenticated user from context
 func GetUser(c *fiber.Ctx) *models.User {
 	user, ok := c.Locals("user").(*models.User)
@@ -103,3 +184,13 @@ func GetClaims(c *fiber.Ctx) *utils.JWTClaims {
 	}
 	return claims
 }
+
+// GetImpersonator returns the admin acting as the authenticated user, or nil
+// when the user is signed in as themselves
+func GetImpersonator(c *fiber.Ctx) *models.User {
+	admin, ok := c.Locals("impersonator").(*models.User)
+	if !ok {
+		return nil
+	}
+	return admin
+}
@@ -33,6 +33,9 @@ func setupAuthTestDB(t *testing.T) *sql.DB {
 			two_factor_secret TEXT DEFAULT '',
 			two_factor_enabled INTEGER DEFAULT 0,
 			backup_codes TEXT DEFAULT '',
+			deleted_at DATETIME,
+			last_login_at DATETIME,
+			token_version INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
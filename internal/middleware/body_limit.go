@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IngestionBodyLimit rejects ingestion requests whose body is larger than
+// limit bytes with 413. A project's own MaxBodyBytes replaces the limit, so it
+// must run after RequireAPIKey. Bodies above the server-wide limit never get
+// this far; the server answers 413 before reading them.
+func IngestionBodyLimit(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		max := limit
+		if project := GetProject(c); project != nil && project.MaxBodyBytes > 0 {
+			max = project.MaxBodyBytes
+		}
+		if max <= 0 {
+			return c.Next()
+		}
+
+		if size := len(c.Request().Body()); size > max {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": fmt.Sprintf("Request body is %d bytes, over the %d byte ingestion limit", size, max),
+				"limit": max,
+			})
+		}
+		return c.Next()
+	}
+}
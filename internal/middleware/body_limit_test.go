@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIngestionBodyLimit(t *testing.T) {
+	db := setupAPIKeyTestDB(t)
+	defer db.Close()
+
+	projectRepo := models.NewProjectRepository(db)
+	regularKey, _ := projectRepo.Create(&models.Project{Name: "Regular", IsActive: true})
+	trustedKey, _ := projectRepo.Create(&models.Project{Name: "Trusted", IsActive: true, MaxBodyBytes: 3000})
+
+	app := fiber.New(fiber.Config{BodyLimit: 4096})
+	logs := app.Group("/logs", middleware.NewAPIKeyMiddleware(projectRepo).RequireAPIKey(), middleware.IngestionBodyLimit(1024))
+	logs.Post("", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	// Served over a real listener: bodies over the server limit are refused
+	// while reading the request, which app.Test reports as an error
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go app.Listener(ln)
+	defer app.Shutdown()
+
+	post := func(apiKey string, body []byte) (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodPost, "http://"+ln.Addr().String()+"/logs", bytes.NewReader(body))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		return http.DefaultClient.Do(req)
+	}
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		size       int
+		wantStatus int
+	}{
+		{"under the ingestion limit", regularKey, 512, fiber.StatusCreated},
+		{"over the ingestion limit", regularKey, 2000, fiber.StatusRequestEntityTooLarge},
+		{"raised for a trusted project", trustedKey, 2000, fiber.StatusCreated},
+		{"over the project's own limit", trustedKey, 3500, fiber.StatusRequestEntityTooLarge},
+		{"over the server limit", trustedKey, 5000, fiber.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"message":"` + strings.Repeat("x", tt.size) + `"}`
+			resp, err := post(tt.apiKey, []byte(body))
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	// The ingestion limit names the size and the limit that applied
+	resp, err := post(regularKey, make([]byte, 2000))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	var result map[string]interface{}
+	json.Unmarshal(data, &result)
+	if result["error"] != "Request body is 2000 bytes, over the 1024 byte ingestion limit" || result["limit"] != float64(1024) {
+		t.Errorf("Expected the limit in the response, got %s", data)
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// maintenanceRefresh is how often the stored state is re-read, so a toggle
+	// on one instance reaches the others without a query per request
+	maintenanceRefresh = 5 * time.Second
+	// maintenanceRetryAfter is the Retry-After, in seconds, sent with the 503
+	maintenanceRetryAfter = 60
+)
+
+// MaintenanceMode turns ingestion away with a retryable 503 while an admin
+// has the server in maintenance, e.g. during a migration. Only the routes it
+// is mounted on are affected; the admin API stays up to turn it off again.
+type MaintenanceMode struct {
+	settings *models.SystemSettingRepository
+
+	mu       sync.Mutex
+	enabled  bool
+	loadedAt time.Time
+}
+
+// NewMaintenanceMode creates a MaintenanceMode whose state is kept in settings
+func NewMaintenanceMode(settings *models.SystemSettingRepository) *MaintenanceMode {
+	return &MaintenanceMode{settings: settings}
+}
+
+// Enabled reports whether the server is in maintenance. If the stored state
+// can't be read, the last known state is kept.
+func (m *MaintenanceMode) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Since(m.loadedAt) < maintenanceRefresh {
+		return m.enabled
+	}
+
+	value, _, err := m.settings.Get(models.SettingMaintenanceMode)
+	if err != nil {
+		log.Printf("Failed to read maintenance mode: %v", err)
+	} else {
+		m.enabled = value == "true"
+	}
+	m.loadedAt = time.Now()
+	return m.enabled
+}
+
+// SetEnabled stores the maintenance state; this instance applies it at once
+func (m *MaintenanceMode) SetEnabled(enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.settings.Set(models.SettingMaintenanceMode, strconv.FormatBool(enabled)); err != nil {
+		return err
+	}
+	m.enabled = enabled
+	m.loadedAt = time.Now()
+	return nil
+}
+
+// RejectIngestion answers 503 with Retry-After while the server is in
+// maintenance. It runs before RequireAPIKey, so rejected requests don't touch
+// the projects table.
+func (m *MaintenanceMode) RejectIngestion() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !m.Enabled() {
+			return c.Next()
+		}
+		c.Set("Retry-After", strconv.Itoa(maintenanceRetryAfter))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error":       "Ingestion is paused for maintenance",
+			"retry_after": maintenanceRetryAfter,
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+var obscureNotFound bool
+
+// SetObscureNotFound makes DenyAccess answer 404 instead of 403, so a user
+// can't tell a project, log or channel they may not see from one that does
+// not exist. It is meant to be called once at startup.
+func SetObscureNotFound(enabled bool) {
+	obscureNotFound = enabled
+}
+
+// ObscuresNotFound reports whether denied access is answered with 404
+func ObscuresNotFound() bool {
+	return obscureNotFound
+}
+
+// DenyAccess answers a non-admin asking for something they may not access:
+// 403 with message, or, when obscuring, the 404 with notFound that a missing
+// resource gets. Admins are never denied, so they always see the real status.
+func DenyAccess(c *fiber.Ctx, message, notFound string) error {
+	if obscureNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": notFound,
+		})
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error": message,
+	})
+}
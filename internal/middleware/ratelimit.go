@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"math"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"central-logs/internal/queue"
@@ -12,17 +14,41 @@ import (
 
 type RateLimitMiddleware struct {
 	limiter *queue.RateLimiter
-	limit   int
+	limit   atomic.Int64
+	burst   atomic.Int64
 }
 
-func NewRateLimitMiddleware(limiter *queue.RateLimiter, limit int) *RateLimitMiddleware {
-	return &RateLimitMiddleware{
+func NewRateLimitMiddleware(limiter *queue.RateLimiter, limit, burst int) *RateLimitMiddleware {
+	m := &RateLimitMiddleware{
 		limiter: limiter,
-		limit:   limit,
 	}
+	m.SetLimit(limit, burst)
+	return m
 }
 
-// RateLimitByProject limits requests per project
+// SetLimit changes the default per-project requests-per-minute limit and
+// burst on the fly. A burst of 0 matches the limit.
+func (m *RateLimitMiddleware) SetLimit(limit, burst int) {
+	m.limit.Store(int64(limit))
+	m.burst.Store(int64(burst))
+}
+
+// Limit returns the default per-project requests-per-minute limit
+func (m *RateLimitMiddleware) Limit() int {
+	return int(m.limit.Load())
+}
+
+// Burst returns the default per-project burst
+func (m *RateLimitMiddleware) Burst() int {
+	if burst := int(m.burst.Load()); burst > 0 {
+		return burst
+	}
+	return m.Limit()
+}
+
+// RateLimitByProject limits requests per project with a token bucket. A
+// project's own rate and burst replace the defaults; setting only its rate
+// makes the burst match it.
 func (m *RateLimitMiddleware) RateLimitByProject() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		project := GetProject(c)
@@ -30,23 +56,33 @@ func (m *RateLimitMiddleware) RateLimitByProject() fiber.Handler {
 			return c.Next()
 		}
 
+		bucket := queue.TokenBucket{RatePerMinute: m.Limit(), Burst: m.Burst()}
+		if project.RateLimitPerMinute > 0 {
+			bucket = queue.TokenBucket{RatePerMinute: project.RateLimitPerMinute, Burst: project.RateLimitPerMinute}
+		}
+		if project.RateLimitBurst > 0 {
+			bucket.Burst = project.RateLimitBurst
+		}
+
 		ctx := context.Background()
-		allowed, remaining, resetTime, err := m.limiter.AllowAPI(ctx, project.ID, m.limit)
+		result, err := m.limiter.AllowAPI(ctx, project.ID, bucket)
 		if err != nil {
 			// Log error but don't block request
 			return c.Next()
 		}
 
 		// Set rate limit headers
-		c.Set("X-RateLimit-Limit", strconv.Itoa(m.limit))
-		c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		c.Set("X-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
+		c.Set("X-RateLimit-Limit", strconv.Itoa(bucket.RatePerMinute))
+		c.Set("X-RateLimit-Burst", strconv.Itoa(bucket.Burst))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(result.ResetAfter).Unix(), 10))
 
-		if !allowed {
-			c.Set("Retry-After", strconv.FormatInt(int64(time.Until(resetTime).Seconds()), 10))
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Set("Retry-After", strconv.Itoa(retryAfter))
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error":       "Rate limit exceeded",
-				"retry_after": int(time.Until(resetTime).Seconds()),
+				"retry_after": retryAfter,
 			})
 		}
 
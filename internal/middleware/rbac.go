@@ -49,9 +49,7 @@ func (m *RBACMiddleware) RequireProjectAccess() fiber.Handler {
 		}
 
 		if !hasAccess {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Access denied to this project",
-			})
+			return DenyAccess(c, "Access denied to this project", "Project not found")
 		}
 
 		return c.Next()
@@ -91,6 +89,13 @@ func (m *RBACMiddleware) RequireProjectRole(roles ...models.ProjectRole) fiber.H
 		}
 
 		if !hasRole {
+			// Members with another role know the project exists; only
+			// outsiders are told it doesn't
+			if ObscuresNotFound() {
+				if member, err := m.userProjectRepo.HasAccess(user.ID, projectID); err == nil && !member {
+					return DenyAccess(c, "Insufficient permissions", "Project not found")
+				}
+			}
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error": "Insufficient permissions",
 			})
@@ -109,3 +114,33 @@ func (m *RBACMiddleware) RequireOwner() fiber.Handler {
 func (m *RBACMiddleware) RequireOwnerOrMember() fiber.Handler {
 	return m.RequireProjectRole(models.ProjectRoleOwner, models.ProjectRoleMember)
 }
+
+// RequireDeletedProjectOwner requires the OWNER role in a soft-deleted
+// project. RequireOwner refuses deleted projects, so restoring one needs
+// its own check.
+func (m *RBACMiddleware) RequireDeletedProjectOwner() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := GetUser(c)
+		if user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		if user.IsAdmin() {
+			return c.Next()
+		}
+
+		isOwner, err := m.userProjectRepo.HasRoleInDeleted(user.ID, c.Params("id"), models.ProjectRoleOwner)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check role",
+			})
+		}
+		if !isOwner {
+			return DenyAccess(c, "Insufficient permissions", "Deleted project not found")
+		}
+
+		return c.Next()
+	}
+}
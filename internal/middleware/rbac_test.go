@@ -0,0 +1,172 @@
+package middleware_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"central-logs/internal/middleware"
+	"central-logs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupRBACTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS projects (
+			id TEXT PRIMARY KEY,
+			deleted_at DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS user_projects (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			project_id TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'MEMBER',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO projects (id) VALUES ('proj-1');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create tables: %v", err)
+	}
+
+	return db
+}
+
+func TestRBACMiddleware_ObscureNotFound(t *testing.T) {
+	db := setupRBACTestDB(t)
+	defer db.Close()
+
+	userProjectRepo := models.NewUserProjectRepository(db)
+	rbacMiddleware := middleware.NewRBACMiddleware(userProjectRepo)
+
+	viewer := &models.User{ID: "viewer-1", Role: models.RoleUser}
+	outsider := &models.User{ID: "outsider-1", Role: models.RoleUser}
+	admin := &models.User{ID: "admin-1", Role: models.RoleAdmin}
+	userProjectRepo.Create(&models.UserProject{UserID: viewer.ID, ProjectID: "proj-1", Role: models.ProjectRoleViewer})
+
+	newApp := func(user *models.User) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("user", user)
+			return c.Next()
+		})
+		ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+		app.Get("/projects/:id", rbacMiddleware.RequireProjectAccess(), ok)
+		app.Put("/projects/:id", rbacMiddleware.RequireOwnerOrMember(), ok)
+		return app
+	}
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		method     string
+		obscure    bool
+		wantStatus int
+		wantError  string
+	}{
+		{"outsider denied", outsider, http.MethodGet, false, http.StatusForbidden, "Access denied to this project"},
+		{"outsider denied, obscured", outsider, http.MethodGet, true, http.StatusNotFound, "Project not found"},
+		{"outsider lacks role, obscured", outsider, http.MethodPut, true, http.StatusNotFound, "Project not found"},
+		// A viewer can already see the project, so hiding it gains nothing
+		{"viewer lacks role, obscured", viewer, http.MethodPut, true, http.StatusForbidden, "Insufficient permissions"},
+		{"viewer allowed, obscured", viewer, http.MethodGet, true, http.StatusOK, ""},
+		{"admin allowed, obscured", admin, http.MethodPut, true, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware.SetObscureNotFound(tt.obscure)
+			defer middleware.SetObscureNotFound(false)
+
+			req := httptest.NewRequest(tt.method, "/projects/proj-1", nil)
+			resp, err := newApp(tt.user).Test(req)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantError == "" {
+				return
+			}
+
+			var response map[string]interface{}
+			body, _ := io.ReadAll(resp.Body)
+			json.Unmarshal(body, &response)
+			if response["error"] != tt.wantError {
+				t.Errorf("Expected error %q, got %v", tt.wantError, response["error"])
+			}
+		})
+	}
+}
+
+func TestRBACMiddleware_SoftDeletedProject(t *testing.T) {
+	db := setupRBACTestDB(t)
+	defer db.Close()
+
+	userProjectRepo := models.NewUserProjectRepository(db)
+	rbacMiddleware := middleware.NewRBACMiddleware(userProjectRepo)
+
+	owner := &models.User{ID: "owner-1", Role: models.RoleUser}
+	member := &models.User{ID: "member-1", Role: models.RoleUser}
+	userProjectRepo.Create(&models.UserProject{UserID: owner.ID, ProjectID: "proj-1", Role: models.ProjectRoleOwner})
+	userProjectRepo.Create(&models.UserProject{UserID: member.ID, ProjectID: "proj-1", Role: models.ProjectRoleMember})
+
+	request := func(user *models.User, method, path string) int {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("user", user)
+			return c.Next()
+		})
+		ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+		app.Get("/projects/:id", rbacMiddleware.RequireProjectAccess(), ok)
+		app.Put("/projects/:id", rbacMiddleware.RequireOwner(), ok)
+		app.Post("/projects/:id/restore", rbacMiddleware.RequireDeletedProjectOwner(), ok)
+
+		resp, err := app.Test(httptest.NewRequest(method, path, nil))
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// Restore only applies to a deleted project
+	if status := request(owner, http.MethodPost, "/projects/proj-1/restore"); status != http.StatusForbidden {
+		t.Errorf("Expected restore of a live project to be refused, got %d", status)
+	}
+
+	if _, err := db.Exec(`UPDATE projects SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'proj-1'`); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"member loses access", member, http.MethodGet, "/projects/proj-1", http.StatusForbidden},
+		{"owner loses access", owner, http.MethodGet, "/projects/proj-1", http.StatusForbidden},
+		{"owner loses role", owner, http.MethodPut, "/projects/proj-1", http.StatusForbidden},
+		{"member cannot restore", member, http.MethodPost, "/projects/proj-1/restore", http.StatusForbidden},
+		{"owner can restore", owner, http.MethodPost, "/projects/proj-1/restore", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := request(tt.user, tt.method, tt.path); status != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, status)
+			}
+		})
+	}
+}
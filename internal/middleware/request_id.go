@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header used to receive and return request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they can't bloat logs
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID assigns every request an ID, honoring a well-formed incoming
+// X-Request-ID. The ID is echoed in the response header, stored in Locals and
+// the user context, and added to JSON error bodies.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		c.Locals("request_id", id)
+		c.SetUserContext(context.WithValue(c.UserContext(), requestIDKey{}, id))
+		c.Set(RequestIDHeader, id)
+
+		if err := c.Next(); err != nil {
+			// The app's error handler adds the ID itself
+			return err
+		}
+
+		addRequestIDToError(c, id)
+		return nil
+	}
+}
+
+// GetRequestID returns the request ID from context (set by RequestID middleware)
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals("request_id").(string)
+	return id
+}
+
+// RequestIDFromContext returns the request ID carried by a context derived
+// from the request's user context, or "" if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// addRequestIDToError injects request_id into JSON error responses of the
+// form {"error": ...} so clients can quote it when reporting problems
+func addRequestIDToError(c *fiber.Ctx, id string) {
+	resp := c.Response()
+	if resp.StatusCode() < fiber.StatusBadRequest {
+		return
+	}
+	if !strings.HasPrefix(string(resp.Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return
+	}
+	if _, ok := body["error"]; !ok {
+		return
+	}
+	if _, ok := body["request_id"]; ok {
+		return
+	}
+
+	encodedID, _ := json.Marshal(id)
+	body["request_id"] = encodedID
+	if updated, err := json.Marshal(body); err == nil {
+		resp.SetBody(updated)
+	}
+}
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"central-logs/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestID_PropagatesAndAnnotatesErrors(t *testing.T) {
+	var logged bytes.Buffer
+
+	app := fiber.New()
+	app.Use(middleware.RequestID())
+	app.Use(middleware.AccessLog(&logged))
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		if middleware.RequestIDFromContext(c.UserContext()) != middleware.GetRequestID(c) {
+			t.Error("Expected request ID in user context")
+		}
+		return c.SendString("ok")
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "bad"})
+	})
+
+	// Incoming ID is honored and echoed
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(middleware.RequestIDHeader, "abc-123")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if got := resp.Header.Get(middleware.RequestIDHeader); got != "abc-123" {
+		t.Errorf("Expected echoed request ID abc-123, got %q", got)
+	}
+
+	// Malformed IDs are replaced and errors carry the ID
+	req = httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(middleware.RequestIDHeader, "has spaces")
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	id := resp.Header.Get(middleware.RequestIDHeader)
+	if id == "" || id == "has spaces" {
+		t.Errorf("Expected a generated request ID, got %q", id)
+	}
+
+	var body map[string]string
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &body)
+	if body["error"] != "bad" || body["request_id"] != id {
+		t.Errorf("Expected error body with request_id %s, got %v", id, body)
+	}
+
+	// One JSON access log line per request
+	lines := bytes.Split(bytes.TrimSpace(logged.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 access log lines, got %d", len(lines))
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(lines[1], &entry); err != nil {
+		t.Fatalf("Access log line is not JSON: %v", err)
+	}
+	if entry["request_id"] != id || entry["status"] != float64(400) || entry["path"] != "/fail" {
+		t.Errorf("Unexpected access log entry: %v", entry)
+	}
+}
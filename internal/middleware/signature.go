@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureHeader carries the HMAC of the raw request body for projects that
+// require signed ingestion
+const SignatureHeader = "X-Signature"
+
+const signaturePrefix = "sha256="
+
+// ComputeSignature returns the X-Signature value for body signed with secret
+func ComputeSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether header is a valid "sha256=<hex>" HMAC of
+// body under secret. The comparison is constant-time.
+func VerifySignature(secret string, body []byte, header string) bool {
+	digest, ok := strings.CutPrefix(strings.TrimSpace(header), signaturePrefix)
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertRule triggers a notification when at least Threshold logs at or above
+// MinLevel (optionally limited to Source and to logs whose metadata has
+// MetadataKey set to MetadataValue) arrive within WindowSeconds.
+type AlertRule struct {
+	ID            string     `json:"id"`
+	ProjectID     string     `json:"project_id"`
+	Name          string     `json:"name"`
+	MinLevel      LogLevel   `json:"min_level"`
+	Source        string     `json:"source,omitempty"`
+	MetadataKey   string     `json:"metadata_key,omitempty"`   // Top-level metadata key; empty means any metadata
+	MetadataValue string     `json:"metadata_value,omitempty"` // Compared as text, so 500 matches "500"
+	Threshold     int        `json:"threshold"`
+	WindowSeconds int        `json:"window_seconds"`
+	ChannelID     string     `json:"channel_id,omitempty"` // Empty means all active project channels
+	IsActive      bool       `json:"is_active"`
+	LastFiredAt   *time.Time `json:"last_fired_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// metadataKeyPattern limits metadata keys to names that are safe to embed in
+// a JSON path
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// IsValidMetadataKey reports whether key can be used as an alert rule's
+// metadata condition
+func IsValidMetadataKey(key string) bool {
+	return metadataKeyPattern.MatchString(key)
+}
+
+// Window returns the rule's evaluation window as a duration
+func (a *AlertRule) Window() time.Duration {
+	return time.Duration(a.WindowSeconds) * time.Second
+}
+
+// CanFire reports whether the rule is outside its de-duplication window,
+// so a rule fires at most once per window instead of once per log.
+func (a *AlertRule) CanFire(now time.Time) bool {
+	if a.LastFiredAt == nil {
+		return true
+	}
+	return !now.Before(a.LastFiredAt.Add(a.Window()))
+}
+
+type AlertRuleRepository struct {
+	db *sql.DB
+}
+
+func NewAlertRuleRepository(db *sql.DB) *AlertRuleRepository {
+	return &AlertRuleRepository{db: db}
+}
+
+const alertRuleColumns = `id, project_id, name, min_level, source, metadata_key, metadata_value, threshold, window_seconds, channel_id, is_active, last_fired_at, created_at, updated_at`
+
+func (r *AlertRuleRepository) Create(rule *AlertRule) error {
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(`
+		INSERT INTO alert_rules (id, project_id, name, min_level, source, metadata_key, metadata_value, threshold, window_seconds, channel_id, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID, rule.ProjectID, rule.Name, rule.MinLevel, nullString(rule.Source), nullString(rule.MetadataKey), nullString(rule.MetadataValue), rule.Threshold, rule.WindowSeconds, nullString(rule.ChannelID), rule.IsActive, rule.CreatedAt, rule.UpdatedAt)
+
+	return err
+}
+
+func (r *AlertRuleRepository) GetByID(id string) (*AlertRule, error) {
+	rule, err := scanAlertRule(r.db.QueryRow(`SELECT `+alertRuleColumns+` FROM alert_rules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *AlertRuleRepository) GetByProjectID(projectID string) ([]*AlertRule, error) {
+	return r.query(`SELECT `+alertRuleColumns+` FROM alert_rules WHERE project_id = ? ORDER BY created_at ASC`, projectID)
+}
+
+// GetActive returns all active rules across projects, used by the evaluator
+func (r *AlertRuleRepository) GetActive() ([]*AlertRule, error) {
+	return r.query(`SELECT `+alertRuleColumns+` FROM alert_rules WHERE is_active = ? ORDER BY created_at ASC`, true)
+}
+
+func (r *AlertRuleRepository) Update(rule *AlertRule) error {
+	rule.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(`
+		UPDATE alert_rules SET name = ?, min_level = ?, source = ?, metadata_key = ?, metadata_value = ?, threshold = ?, window_seconds = ?, channel_id = ?, is_active = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.MinLevel, nullString(rule.Source), nullString(rule.MetadataKey), nullString(rule.MetadataValue), rule.Threshold, rule.WindowSeconds, nullString(rule.ChannelID), rule.IsActive, rule.UpdatedAt, rule.ID)
+	return err
+}
+
+// MarkFired records when a rule last triggered
+func (r *AlertRuleRepository) MarkFired(id string, firedAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE alert_rules SET last_fired_at = ? WHERE id = ?`, firedAt, id)
+	return err
+}
+
+func (r *AlertRuleRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM alert_rules WHERE id = ?`, id)
+	return err
+}
+
+func (r *AlertRuleRepository) query(query string, args ...interface{}) ([]*AlertRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*AlertRule
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAlertRule(row rowScanner) (*AlertRule, error) {
+	rule := &AlertRule{}
+	var source, metadataKey, metadataValue, channelID sql.NullString
+	var lastFiredAt sql.NullTime
+
+	if err := row.Scan(&rule.ID, &rule.ProjectID, &rule.Name, &rule.MinLevel, &source, &metadataKey, &metadataValue, &rule.Threshold, &rule.WindowSeconds, &channelID, &rule.IsActive, &lastFiredAt, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	rule.Source = source.String
+	rule.MetadataKey = metadataKey.String
+	rule.MetadataValue = metadataValue.String
+	rule.ChannelID = channelID.String
+	if lastFiredAt.Valid {
+		rule.LastFiredAt = &lastFiredAt.Time
+	}
+
+	return rule, nil
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullPositiveInt(n int) interface{} {
+	if n <= 0 {
+		return nil
+	}
+	return n
+}
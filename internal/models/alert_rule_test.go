@@ -0,0 +1,212 @@
+package models_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+)
+
+func setupAlertRuleTestDB(t *testing.T) *sql.DB {
+	db := setupLogTestDB(t)
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS alert_rules (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			min_level TEXT NOT NULL DEFAULT 'ERROR',
+			source TEXT,
+			metadata_key TEXT,
+			metadata_value TEXT,
+			threshold INTEGER NOT NULL,
+			window_seconds INTEGER NOT NULL,
+			channel_id TEXT,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			last_fired_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create alert_rules table: %v", err)
+	}
+
+	return db
+}
+
+func TestAlertRuleRepository_CRUD(t *testing.T) {
+	db := setupAlertRuleTestDB(t)
+	defer db.Close()
+
+	repo := models.NewAlertRuleRepository(db)
+
+	rule := &models.AlertRule{
+		ProjectID:     "proj-1",
+		Name:          "Payment errors",
+		MinLevel:      models.LogLevelError,
+		Source:        "payment-service",
+		MetadataKey:   "region",
+		MetadataValue: "eu-west",
+		Threshold:     10,
+		WindowSeconds: 300,
+		IsActive:      true,
+	}
+	if err := repo.Create(rule); err != nil {
+		t.Fatalf("Failed to create alert rule: %v", err)
+	}
+
+	got, err := repo.GetByID(rule.ID)
+	if err != nil {
+		t.Fatalf("Failed to get alert rule: %v", err)
+	}
+	if got == nil || got.Source != "payment-service" || got.MetadataKey != "region" || got.MetadataValue != "eu-west" || got.Threshold != 10 {
+		t.Fatalf("Unexpected alert rule: %+v", got)
+	}
+	if got.LastFiredAt != nil {
+		t.Error("New rule should not have last_fired_at set")
+	}
+
+	firedAt := time.Now()
+	if err := repo.MarkFired(rule.ID, firedAt); err != nil {
+		t.Fatalf("Failed to mark rule fired: %v", err)
+	}
+
+	active, err := repo.GetActive()
+	if err != nil {
+		t.Fatalf("Failed to get active rules: %v", err)
+	}
+	if len(active) != 1 || active[0].LastFiredAt == nil {
+		t.Fatalf("Expected one active fired rule, got %+v", active)
+	}
+
+	if err := repo.Delete(rule.ID); err != nil {
+		t.Fatalf("Failed to delete alert rule: %v", err)
+	}
+	got, _ = repo.GetByID(rule.ID)
+	if got != nil {
+		t.Error("Rule should be deleted")
+	}
+}
+
+func TestAlertRule_CanFire(t *testing.T) {
+	now := time.Now()
+	rule := &models.AlertRule{WindowSeconds: 60}
+
+	if !rule.CanFire(now) {
+		t.Error("Rule that never fired should be able to fire")
+	}
+
+	recent := now.Add(-30 * time.Second)
+	rule.LastFiredAt = &recent
+	if rule.CanFire(now) {
+		t.Error("Rule should not fire again within its window")
+	}
+
+	old := now.Add(-61 * time.Second)
+	rule.LastFiredAt = &old
+	if !rule.CanFire(now) {
+		t.Error("Rule should fire again after its window")
+	}
+}
+
+func TestLogRepository_CountSince(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	logs := []*models.Log{
+		{ProjectID: "proj-1", Level: models.LogLevelWarn, Message: "slow", Source: "api"},
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "failed", Source: "api"},
+		{ProjectID: "proj-1", Level: models.LogLevelCritical, Message: "down", Source: "api"},
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "failed", Source: "worker"},
+	}
+	if err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+
+	since := time.Now().Add(-time.Minute)
+
+	count, err := repo.CountSince(&models.AlertRule{ProjectID: "proj-1", MinLevel: models.LogLevelError}, since)
+	if err != nil {
+		t.Fatalf("CountSince failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 logs at ERROR or above, got %d", count)
+	}
+
+	count, _ = repo.CountSince(&models.AlertRule{ProjectID: "proj-1", MinLevel: models.LogLevelError, Source: "api"}, since)
+	if count != 2 {
+		t.Errorf("Expected 2 api logs at ERROR or above, got %d", count)
+	}
+
+	count, _ = repo.CountSince(&models.AlertRule{ProjectID: "proj-1", MinLevel: models.LogLevelDebug}, time.Now().Add(time.Minute))
+	if count != 0 {
+		t.Errorf("Expected no logs after a future cutoff, got %d", count)
+	}
+}
+
+func TestLogRepository_CountSince_MetadataCondition(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	logs := []*models.Log{
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "charge failed", Metadata: map[string]interface{}{"region": "eu-west", "status": 502}},
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "charge failed", Metadata: map[string]interface{}{"region": "eu-west", "status": 500}},
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "charge failed", Metadata: map[string]interface{}{"region": "us-east", "status": 500}},
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "no metadata"},
+		{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "charged", Metadata: map[string]interface{}{"region": "eu-west"}},
+	}
+	if err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+
+	since := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  int
+	}{
+		{"string value", "region", "eu-west", 2},
+		{"numeric value compared as text", "status", "500", 2},
+		{"no match", "region", "ap-south", 0},
+		{"missing key", "customer", "acme", 0},
+		{"no condition", "", "", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &models.AlertRule{ProjectID: "proj-1", MinLevel: models.LogLevelError, MetadataKey: tt.key, MetadataValue: tt.value}
+			count, err := repo.CountSince(rule, since)
+			if err != nil {
+				t.Fatalf("CountSince failed: %v", err)
+			}
+			if count != tt.want {
+				t.Errorf("Expected %d logs, got %d", tt.want, count)
+			}
+		})
+	}
+
+	rule := &models.AlertRule{ProjectID: "proj-1", MinLevel: models.LogLevelError, MetadataKey: "a') OR 1=1 --", MetadataValue: "x"}
+	if _, err := repo.CountSince(rule, since); err == nil {
+		t.Error("Expected an unsafe metadata key to be rejected")
+	}
+}
+
+func TestIsValidMetadataKey(t *testing.T) {
+	for _, key := range []string{"region", "status_code", "request-id", "A1"} {
+		if !models.IsValidMetadataKey(key) {
+			t.Errorf("Expected %q to be a valid key", key)
+		}
+	}
+	for _, key := range []string{"", "user.id", "a b", "x'", strings.Repeat("k", 65)} {
+		if models.IsValidMetadataKey(key) {
+			t.Errorf("Expected %q to be rejected", key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// APIKeyUsage records when a project's API key was last used. Keys are
+// identified by their display prefix since only the hash is stored.
+type APIKeyUsage struct {
+	ProjectID  string    `json:"project_id"`
+	KeyPrefix  string    `json:"key_prefix"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+type APIKeyUsageRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyUsageRepository(db *sql.DB) *APIKeyUsageRepository {
+	return &APIKeyUsageRepository{db: db}
+}
+
+// Touch upserts the last-used time for a key
+func (r *APIKeyUsageRepository) Touch(projectID, keyPrefix string, usedAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO api_key_usage (project_id, key_prefix, last_used_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(project_id, key_prefix) DO UPDATE SET last_used_at = excluded.last_used_at
+	`, projectID, keyPrefix, usedAt)
+	return err
+}
+
+// GetByProjectID returns usage rows for every key a project has used, most recent first
+func (r *APIKeyUsageRepository) GetByProjectID(projectID string) ([]*APIKeyUsage, error) {
+	rows, err := r.db.Query(`
+		SELECT project_id, key_prefix, last_used_at
+		FROM api_key_usage
+		WHERE project_id = ?
+		ORDER BY last_used_at DESC
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []*APIKeyUsage
+	for rows.Next() {
+		u := &APIKeyUsage{}
+		if err := rows.Scan(&u.ProjectID, &u.KeyPrefix, &u.LastUsedAt); err != nil {
+			return nil, err
+		}
+		usages = append(usages, u)
+	}
+	return usages, rows.Err()
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions recorded for administrative changes
+const (
+	AuditUserCreate          = "user.create"
+	AuditUserUpdate          = "user.update"
+	AuditUserDelete          = "user.delete"
+	AuditUserResetPassword   = "user.reset_password"
+	AuditUserRestore         = "user.restore"
+	AuditUserRevokeSessions  = "user.revoke_sessions"
+	AuditUserImpersonate     = "user.impersonate"
+	AuditProjectDelete       = "project.delete"
+	AuditProjectRestore      = "project.restore"
+	AuditProjectClone        = "project.clone"
+	AuditProjectRotateKey    = "project.rotate_key"
+	AuditProjectRotateSecret = "project.rotate_signing_secret"
+	AuditProjectDisableSign  = "project.disable_signing"
+	AuditProjectPauseIngest  = "project.pause_ingestion"
+	AuditProjectResumeIngest = "project.resume_ingestion"
+	AuditMemberAdd           = "member.add"
+	AuditMemberRoleChange    = "member.role_change"
+	AuditMemberRemove        = "member.remove"
+	AuditTwoFactorDisable    = "2fa.disable"
+	AuditMaintenanceMode     = "system.maintenance_mode"
+	AuditLogStatusChange     = "log.status_change"
+)
+
+// AuditLog is one entry in the administrative audit trail
+type AuditLog struct {
+	ID         string    `json:"id"`
+	ActorID    string    `json:"actor_id"`
+	ActorName  string    `json:"actor_name"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	Details    string    `json:"details,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditLogFilter narrows an audit listing. Actor matches either the actor's
+// user ID or username.
+type AuditLogFilter struct {
+	Actor  string
+	Action string
+	Limit  int
+	Offset int
+}
+
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(entry *AuditLog) error {
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now()
+
+	_, err := r.db.Exec(`
+		INSERT INTO audit_logs (id, actor_id, actor_name, action, target_type, target_id, details, ip_address, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.ID, entry.ActorID, entry.ActorName, entry.Action, entry.TargetType, entry.TargetID,
+		nullString(entry.Details), nullString(entry.IPAddress), entry.CreatedAt)
+
+	return err
+}
+
+// List returns matching entries, newest first, with the total count
+func (r *AuditLogRepository) List(filter AuditLogFilter) ([]*AuditLog, int, error) {
+	where := "WHERE 1=1"
+	var args []interface{}
+	if filter.Actor != "" {
+		where += " AND (actor_id = ? OR actor_name = ?)"
+		args = append(args, filter.Actor, filter.Actor)
+	}
+	if filter.Action != "" {
+		where += " AND action = ?"
+		args = append(args, filter.Action)
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM audit_logs "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, actor_id, actor_name, action, target_type, target_id, details, ip_address, created_at
+		FROM audit_logs `+where+`
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []*AuditLog{}
+	for rows.Next() {
+		e := &AuditLog{}
+		var details, ipAddress sql.NullString
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.ActorName, &e.Action, &e.TargetType, &e.TargetID, &details, &ipAddress, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		e.Details = details.String
+		e.IPAddress = ipAddress.String
+		entries = append(entries, e)
+	}
+
+	return entries, total, rows.Err()
+}
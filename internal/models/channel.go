@@ -3,6 +3,8 @@ package models
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,9 +13,13 @@ import (
 type ChannelType string
 
 const (
-	ChannelTypePush     ChannelType = "PUSH"
-	ChannelTypeTelegram ChannelType = "TELEGRAM"
-	ChannelTypeDiscord  ChannelType = "DISCORD"
+	ChannelTypePush      ChannelType = "PUSH"
+	ChannelTypeTelegram  ChannelType = "TELEGRAM"
+	ChannelTypeDiscord   ChannelType = "DISCORD"
+	ChannelTypeApprise   ChannelType = "APPRISE"
+	ChannelTypeTeams     ChannelType = "TEAMS"
+	ChannelTypeEmail     ChannelType = "EMAIL"
+	ChannelTypePagerDuty ChannelType = "PAGERDUTY"
 )
 
 type Channel struct {
@@ -28,6 +34,33 @@ type Channel struct {
 	UpdatedAt time.Time              `json:"updated_at"`
 }
 
+// ChannelSecretKeys are the config keys holding credentials, which must not
+// be shown back to users or written to logs. Apprise target URLs embed the
+// credentials of the services they point at, and a PagerDuty routing key is
+// enough to open incidents.
+var ChannelSecretKeys = []string{"bot_token", "webhook_url", "urls", "routing_key"}
+
+// SecretValues returns the non-empty secret strings in the channel config,
+// including each entry of list-valued secrets
+func (c *Channel) SecretValues() []string {
+	var secrets []string
+	for _, key := range ChannelSecretKeys {
+		switch v := c.Config[key].(type) {
+		case string:
+			if v != "" {
+				secrets = append(secrets, v)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok && s != "" {
+					secrets = append(secrets, s)
+				}
+			}
+		}
+	}
+	return secrets
+}
+
 type TelegramConfig struct {
 	BotToken string `json:"bot_token"`
 	ChatID   string `json:"chat_id"`
@@ -37,6 +70,27 @@ type DiscordConfig struct {
 	WebhookURL string `json:"webhook_url"`
 }
 
+// AppriseConfig forwards notifications to an Apprise API server, which fans
+// them out to the target URLs (e.g. "slack://...", "mailto://..."). ServerURL
+// falls back to the server-wide apprise.server_url setting when empty.
+type AppriseConfig struct {
+	ServerURL string   `json:"server_url"`
+	URLs      []string `json:"urls"`
+}
+
+// DigestConfig enables coalescing a channel's notifications into one summary
+// message per window. It is stored under the "digest" key of the channel config.
+type DigestConfig struct {
+	Enabled       bool `json:"enabled"`
+	WindowSeconds int  `json:"window_seconds"`
+	MaxSamples    int  `json:"max_samples"`
+}
+
+// Window returns the coalescing window as a duration
+func (d *DigestConfig) Window() time.Duration {
+	return time.Duration(d.WindowSeconds) * time.Second
+}
+
 type ChannelRepository struct {
 	db *sql.DB
 }
@@ -118,9 +172,39 @@ func (r *ChannelRepository) GetByProjectID(projectID string) ([]*Channel, error)
 func (r *ChannelRepository) GetActiveByProjectID(projectID string) ([]*Channel, error) {
 	rows, err := r.db.Query(`
 		SELECT id, project_id, type, name, config, min_level, is_active, created_at, updated_at
-		FROM channels WHERE project_id = ? AND is_active = 1
+		FROM channels WHERE project_id = ? AND is_active = ?
 		ORDER BY created_at ASC
-	`, projectID)
+	`, projectID, true)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []*Channel
+	for rows.Next() {
+		channel := &Channel{}
+		var configJSON string
+
+		if err := rows.Scan(&channel.ID, &channel.ProjectID, &channel.Type, &channel.Name, &configJSON, &channel.MinLevel, &channel.IsActive, &channel.CreatedAt, &channel.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(configJSON), &channel.Config); err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, channel)
+	}
+	return channels, nil
+}
+
+// GetActiveByType lists active channels of one type across all projects
+func (r *ChannelRepository) GetActiveByType(channelType ChannelType) ([]*Channel, error) {
+	rows, err := r.db.Query(`
+		SELECT id, project_id, type, name, config, min_level, is_active, created_at, updated_at
+		FROM channels WHERE type = ? AND is_active = ?
+		ORDER BY created_at ASC
+	`, channelType, true)
 	if err != nil {
 		return nil, err
 	}
@@ -164,8 +248,47 @@ func (r *ChannelRepository) Delete(id string) error {
 	return err
 }
 
-func (c *Channel) ShouldNotify(level LogLevel) bool {
-	return level.Priority() >= c.MinLevel.Priority()
+// ShouldNotify reports whether a log at level from source is important
+// enough for this channel right now
+func (c *Channel) ShouldNotify(level LogLevel, source string) bool {
+	return c.ShouldNotifyAt(level, source, time.Now())
+}
+
+// ShouldNotifyAt is ShouldNotify at the given time, which decides whether
+// the channel's quiet hours apply. Email channels get scheduled digests
+// instead of a message per log, so they never match.
+func (c *Channel) ShouldNotifyAt(level LogLevel, source string, at time.Time) bool {
+	if c.Type == ChannelTypeEmail {
+		return false
+	}
+	return level.Priority() >= c.EffectiveMinLevel(source, at).Priority()
+}
+
+// EffectiveMinLevel is the threshold for logs from source at the given time.
+// During quiet hours it is raised to the quiet-hours min_level, never lowered.
+func (c *Channel) EffectiveMinLevel(source string, at time.Time) LogLevel {
+	min := c.MinLevelFor(source)
+	if quiet := c.GetQuietHours(); quiet != nil && quiet.Contains(at) && quiet.MinLevel.Priority() > min.Priority() {
+		return quiet.MinLevel
+	}
+	return min
+}
+
+// MinLevelFor returns the minimum level for logs from source: its entry in
+// the "source_levels" config map (source -> level) when there is one, and
+// the channel's min_level otherwise
+func (c *Channel) MinLevelFor(source string) LogLevel {
+	if source == "" {
+		return c.MinLevel
+	}
+	overrides, ok := c.Config["source_levels"].(map[string]interface{})
+	if !ok {
+		return c.MinLevel
+	}
+	if level, ok := overrides[source].(string); ok && LogLevel(level).Priority() >= 0 {
+		return LogLevel(level)
+	}
+	return c.MinLevel
 }
 
 func (c *Channel) GetTelegramConfig() (*TelegramConfig, error) {
@@ -191,3 +314,207 @@ func (c *Channel) GetDiscordConfig() (*DiscordConfig, error) {
 	}
 	return &config, nil
 }
+
+// QuietHoursConfig limits a channel to important logs during a daily window,
+// e.g. only CRITICAL between 22:00 and 07:00. It is stored under the
+// "quiet_hours" key of the channel config.
+type QuietHoursConfig struct {
+	Timezone string   `json:"timezone"` // IANA name, defaults to UTC
+	Start    string   `json:"start"`    // "HH:MM", local to Timezone
+	End      string   `json:"end"`      // "HH:MM"; before Start means the window crosses midnight
+	MinLevel LogLevel `json:"min_level"`
+}
+
+// Validate checks the timezone and window, returning the config field at
+// fault with the error
+func (q *QuietHoursConfig) Validate() (string, error) {
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return "timezone", fmt.Errorf("unknown timezone %q", q.Timezone)
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return "start", err
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return "end", err
+	}
+	if start == end {
+		return "end", fmt.Errorf("end must differ from start")
+	}
+	if q.MinLevel != "" && q.MinLevel.Priority() < 0 {
+		return "min_level", fmt.Errorf("unknown level %q", q.MinLevel)
+	}
+	return "", nil
+}
+
+// Contains reports whether t falls inside the quiet window. An invalid
+// config never matches.
+func (q *QuietHoursConfig) Contains(t time.Time) bool {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err1 := parseClock(q.Start)
+	end, err2 := parseClock(q.End)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// parseClock parses "HH:MM" into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// GetQuietHours returns the channel's quiet-hours settings with defaults
+// applied, or nil when none are configured
+func (c *Channel) GetQuietHours() *QuietHoursConfig {
+	raw, ok := c.Config["quiet_hours"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var quiet QuietHoursConfig
+	if err := json.Unmarshal(data, &quiet); err != nil {
+		return nil
+	}
+
+	if quiet.MinLevel == "" {
+		quiet.MinLevel = LogLevelCritical
+	}
+	return &quiet
+}
+
+// EmailDigestConfig is the recipient list and schedule of an EMAIL channel,
+// stored at the top level of its config. A digest covers the day or week
+// ending at the scheduled time.
+type EmailDigestConfig struct {
+	Recipients []string `json:"recipients"`
+	Schedule   string   `json:"schedule"` // "daily" or "weekly"
+	At         string   `json:"at"`       // "HH:MM" local to Timezone, defaults to 08:00
+	Weekday    string   `json:"weekday"`  // Day weekly digests go out, defaults to monday
+	Timezone   string   `json:"timezone"` // IANA name, defaults to UTC
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// Validate checks the schedule, returning the config field at fault with
+// the error. Recipient addresses are checked by the caller.
+func (d *EmailDigestConfig) Validate() (string, error) {
+	if len(d.Recipients) == 0 {
+		return "recipients", fmt.Errorf("at least one recipient is required")
+	}
+	if d.Schedule != "daily" && d.Schedule != "weekly" {
+		return "schedule", fmt.Errorf("schedule must be daily or weekly")
+	}
+	if _, err := parseClock(d.At); err != nil {
+		return "at", err
+	}
+	if _, ok := weekdays[strings.ToLower(d.Weekday)]; !ok {
+		return "weekday", fmt.Errorf("unknown weekday %q", d.Weekday)
+	}
+	if _, err := time.LoadLocation(d.Timezone); err != nil {
+		return "timezone", fmt.Errorf("unknown timezone %q", d.Timezone)
+	}
+	return "", nil
+}
+
+// Period returns the most recent digest period that has ended by now: the
+// last scheduled send time and the day or week before it
+func (d *EmailDigestConfig) Period(now time.Time) (from, to time.Time, err error) {
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		return from, to, err
+	}
+	minutes, err := parseClock(d.At)
+	if err != nil {
+		return from, to, err
+	}
+
+	local := now.In(loc)
+	to = time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, loc)
+	if to.After(local) {
+		to = to.AddDate(0, 0, -1)
+	}
+	if d.Schedule != "weekly" {
+		return to.AddDate(0, 0, -1), to, nil
+	}
+
+	// Step back to the most recent send weekday
+	back := (int(to.Weekday()) - int(weekdays[strings.ToLower(d.Weekday)]) + 7) % 7
+	to = to.AddDate(0, 0, -back)
+	return to.AddDate(0, 0, -7), to, nil
+}
+
+// GetEmailDigestConfig returns the channel's digest settings with defaults
+// applied, or nil when the config fields have the wrong types
+func (c *Channel) GetEmailDigestConfig() *EmailDigestConfig {
+	data, err := json.Marshal(c.Config)
+	if err != nil {
+		return nil
+	}
+	digest := EmailDigestConfig{Schedule: "daily", At: "08:00", Weekday: "monday"}
+	if err := json.Unmarshal(data, &digest); err != nil {
+		return nil
+	}
+	return &digest
+}
+
+// GetAppriseConfig returns the channel's Apprise settings, or nil when the
+// config fields have the wrong types
+func (c *Channel) GetAppriseConfig() *AppriseConfig {
+	data, err := json.Marshal(c.Config)
+	if err != nil {
+		return nil
+	}
+	var apprise AppriseConfig
+	if err := json.Unmarshal(data, &apprise); err != nil {
+		return nil
+	}
+	return &apprise
+}
+
+// GetDigestConfig returns the channel's digest settings with defaults applied,
+// or nil when digest mode is off and each log is sent on its own
+func (c *Channel) GetDigestConfig() *DigestConfig {
+	raw, ok := c.Config["digest"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var digest DigestConfig
+	if err := json.Unmarshal(data, &digest); err != nil || !digest.Enabled {
+		return nil
+	}
+
+	if digest.WindowSeconds <= 0 {
+		digest.WindowSeconds = 60
+	}
+	if digest.MaxSamples <= 0 {
+		digest.MaxSamples = 3
+	}
+	return &digest
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// channelDeliveryRetention is how long per-attempt delivery outcomes are kept
+const channelDeliveryRetention = 7 * 24 * time.Hour
+
+// ChannelDelivery is the outcome of one attempt to deliver a notification
+type ChannelDelivery struct {
+	ID        string    `json:"id"`
+	ChannelID string    `json:"channel_id"`
+	JobID     string    `json:"job_id,omitempty"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChannelHealth summarizes recent delivery attempts to a channel
+type ChannelHealth struct {
+	LastSuccessAt  *time.Time `json:"last_success_at"`
+	LastFailureAt  *time.Time `json:"last_failure_at"`
+	LastError      string     `json:"last_error,omitempty"`
+	RecentAttempts int        `json:"recent_attempts"`
+	RecentFailures int        `json:"recent_failures"`
+	SuccessRate    *float64   `json:"success_rate"` // Share of recent attempts that succeeded, nil when there were none
+}
+
+// ChannelDeliveryRepository stores the outcome of every attempt to deliver
+// a notification to a channel
+type ChannelDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewChannelDeliveryRepository(db *sql.DB) *ChannelDeliveryRepository {
+	return &ChannelDeliveryRepository{db: db}
+}
+
+// Record stores one delivery attempt; a nil sendErr means it succeeded.
+// jobID ties the attempt to a queued job and may be empty for direct sends.
+// The channel's entries older than the retention window are pruned as well.
+func (r *ChannelDeliveryRepository) Record(channelID, jobID string, sendErr error) error {
+	now := time.Now()
+	var errMsg sql.NullString
+	if sendErr != nil {
+		errMsg = sql.NullString{String: sendErr.Error(), Valid: true}
+	}
+
+	if _, err := r.db.Exec(`
+		INSERT INTO channel_deliveries (id, channel_id, job_id, success, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), channelID, nullString(jobID), sendErr == nil, errMsg, now); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(`DELETE FROM channel_deliveries WHERE channel_id = ? AND created_at < ?`,
+		channelID, now.Add(-channelDeliveryRetention))
+	return err
+}
+
+// GetLatestByJobID returns the most recent attempt for a queued job, or nil
+// while the worker has not tried it yet
+func (r *ChannelDeliveryRepository) GetLatestByJobID(jobID string) (*ChannelDelivery, error) {
+	d := &ChannelDelivery{}
+	var errMsg sql.NullString
+	err := r.db.QueryRow(`
+		SELECT id, channel_id, job_id, success, error, created_at FROM channel_deliveries
+		WHERE job_id = ?
+		ORDER BY created_at DESC LIMIT 1
+	`, jobID).Scan(&d.ID, &d.ChannelID, &d.JobID, &d.Success, &errMsg, &d.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Error = errMsg.String
+	return d, nil
+}
+
+// Health summarizes a channel's deliveries. The last success and failure
+// cover everything retained; the counts and rate only cover attempts since
+// the given time.
+func (r *ChannelDeliveryRepository) Health(channelID string, since time.Time) (*ChannelHealth, error) {
+	health := &ChannelHealth{}
+
+	var lastSuccess sql.NullTime
+	err := r.db.QueryRow(`
+		SELECT created_at FROM channel_deliveries
+		WHERE channel_id = ? AND success = ?
+		ORDER BY created_at DESC LIMIT 1
+	`, channelID, true).Scan(&lastSuccess)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if lastSuccess.Valid {
+		health.LastSuccessAt = &lastSuccess.Time
+	}
+
+	var lastFailure sql.NullTime
+	var lastError sql.NullString
+	err = r.db.QueryRow(`
+		SELECT created_at, error FROM channel_deliveries
+		WHERE channel_id = ? AND success = ?
+		ORDER BY created_at DESC LIMIT 1
+	`, channelID, false).Scan(&lastFailure, &lastError)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if lastFailure.Valid {
+		health.LastFailureAt = &lastFailure.Time
+		health.LastError = lastError.String
+	}
+
+	var failures sql.NullInt64
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*), SUM(CASE WHEN success THEN 0 ELSE 1 END) FROM channel_deliveries
+		WHERE channel_id = ? AND created_at >= ?
+	`, channelID, since).Scan(&health.RecentAttempts, &failures); err != nil {
+		return nil, err
+	}
+	health.RecentFailures = int(failures.Int64)
+
+	if health.RecentAttempts > 0 {
+		rate := float64(health.RecentAttempts-health.RecentFailures) / float64(health.RecentAttempts)
+		health.SuccessRate = &rate
+	}
+
+	return health, nil
+}
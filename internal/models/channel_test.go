@@ -0,0 +1,200 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+)
+
+func TestChannel_ShouldNotify_SourceOverrides(t *testing.T) {
+	channel := &models.Channel{
+		MinLevel: models.LogLevelWarn,
+		Config: map[string]interface{}{
+			"chat_id": "42",
+			"source_levels": map[string]interface{}{
+				"cache-service": "ERROR",
+				"payments":      "DEBUG",
+				"broken":        "LOUD", // Invalid levels fall back to the channel default
+			},
+		},
+	}
+
+	tests := []struct {
+		level  models.LogLevel
+		source string
+		want   bool
+	}{
+		{models.LogLevelWarn, "api", true},
+		{models.LogLevelInfo, "api", false},
+		{models.LogLevelWarn, "", true},
+		{models.LogLevelWarn, "cache-service", false},
+		{models.LogLevelError, "cache-service", true},
+		{models.LogLevelDebug, "payments", true},
+		{models.LogLevelInfo, "broken", false},
+		{models.LogLevelWarn, "broken", true},
+	}
+
+	for _, tt := range tests {
+		if got := channel.ShouldNotify(tt.level, tt.source); got != tt.want {
+			t.Errorf("ShouldNotify(%s, %q) = %v, want %v", tt.level, tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestChannel_ShouldNotify_WithoutOverrides(t *testing.T) {
+	channel := &models.Channel{MinLevel: models.LogLevelError, Config: map[string]interface{}{}}
+
+	if channel.ShouldNotify(models.LogLevelWarn, "cache-service") {
+		t.Error("Expected WARN to be below the channel's ERROR threshold")
+	}
+	if !channel.ShouldNotify(models.LogLevelCritical, "cache-service") {
+		t.Error("Expected CRITICAL to pass the channel's ERROR threshold")
+	}
+}
+
+func TestChannel_ShouldNotifyAt_QuietHours(t *testing.T) {
+	channel := &models.Channel{
+		MinLevel: models.LogLevelWarn,
+		Config: map[string]interface{}{
+			"quiet_hours": map[string]interface{}{
+				"timezone":  "Asia/Jakarta", // UTC+7
+				"start":     "22:00",
+				"end":       "07:00",
+				"min_level": "CRITICAL",
+			},
+		},
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 3, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		level models.LogLevel
+		at    time.Time
+		want  bool
+	}{
+		{"warn at 03:00 local is suppressed", models.LogLevelWarn, at(20, 0), false},
+		{"error at 03:00 local is suppressed", models.LogLevelError, at(20, 0), false},
+		{"critical at 03:00 local passes", models.LogLevelCritical, at(20, 0), true},
+		{"warn at 22:00 local is suppressed", models.LogLevelWarn, at(15, 0), false},
+		{"warn at 07:00 local passes", models.LogLevelWarn, at(0, 0), true},
+		{"warn at 14:00 local passes", models.LogLevelWarn, at(7, 0), true},
+		{"info at 14:00 local stays below the channel level", models.LogLevelInfo, at(7, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := channel.ShouldNotifyAt(tt.level, "", tt.at); got != tt.want {
+				t.Errorf("ShouldNotifyAt(%s, %s) = %v, want %v", tt.level, tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		quiet     models.QuietHoursConfig
+		wantField string
+	}{
+		{"valid", models.QuietHoursConfig{Timezone: "Europe/Berlin", Start: "22:00", End: "06:30"}, ""},
+		{"utc by default", models.QuietHoursConfig{Start: "01:00", End: "05:00", MinLevel: models.LogLevelError}, ""},
+		{"unknown timezone", models.QuietHoursConfig{Timezone: "Mars/Olympus", Start: "22:00", End: "06:00"}, "timezone"},
+		{"bad start", models.QuietHoursConfig{Start: "25:00", End: "06:00"}, "start"},
+		{"empty window", models.QuietHoursConfig{Start: "06:00", End: "06:00"}, "end"},
+		{"bad level", models.QuietHoursConfig{Start: "22:00", End: "06:00", MinLevel: "LOUD"}, "min_level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, err := tt.quiet.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Expected valid config, got %s: %v", field, err)
+				}
+				return
+			}
+			if err == nil || field != tt.wantField {
+				t.Errorf("Expected error on %s, got %q (%v)", tt.wantField, field, err)
+			}
+		})
+	}
+}
+
+func TestEmailDigestConfig_Period(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		now      time.Time
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{
+			name:     "daily after send time",
+			config:   map[string]interface{}{"schedule": "daily"},
+			now:      time.Date(2025, 2, 5, 9, 0, 0, 0, time.UTC),
+			wantFrom: time.Date(2025, 2, 4, 8, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 2, 5, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily before send time",
+			config:   map[string]interface{}{"schedule": "daily", "at": "18:30"},
+			now:      time.Date(2025, 2, 5, 9, 0, 0, 0, time.UTC),
+			wantFrom: time.Date(2025, 2, 3, 18, 30, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 2, 4, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly steps back to the weekday",
+			config:   map[string]interface{}{"schedule": "weekly", "weekday": "Monday"},
+			now:      time.Date(2025, 2, 5, 9, 0, 0, 0, time.UTC), // Wednesday
+			wantFrom: time.Date(2025, 1, 27, 8, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 2, 3, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly on the weekday before send time",
+			config:   map[string]interface{}{"schedule": "weekly", "weekday": "wednesday"},
+			now:      time.Date(2025, 2, 5, 7, 0, 0, 0, time.UTC),
+			wantFrom: time.Date(2025, 1, 22, 8, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2025, 1, 29, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone",
+			config:   map[string]interface{}{"schedule": "daily", "timezone": "Europe/Berlin"},
+			now:      time.Date(2025, 2, 5, 7, 30, 0, 0, time.UTC), // 08:30 in Berlin
+			wantFrom: time.Date(2025, 2, 4, 8, 0, 0, 0, berlin),
+			wantTo:   time.Date(2025, 2, 5, 8, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["recipients"] = []interface{}{"ops@example.com"}
+			digest := (&models.Channel{Type: models.ChannelTypeEmail, Config: tt.config}).GetEmailDigestConfig()
+			if field, err := digest.Validate(); err != nil {
+				t.Fatalf("Expected a valid config, got %s: %v", field, err)
+			}
+
+			from, to, err := digest.Period(tt.now)
+			if err != nil {
+				t.Fatalf("Failed to compute period: %v", err)
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("Expected %s to %s, got %s to %s", tt.wantFrom, tt.wantTo, from, to)
+			}
+		})
+	}
+}
+
+func TestChannel_ShouldNotify_EmailOnlyGetsDigests(t *testing.T) {
+	channel := &models.Channel{Type: models.ChannelTypeEmail, MinLevel: models.LogLevelDebug,
+		Config: map[string]interface{}{"recipients": []interface{}{"ops@example.com"}}}
+
+	if channel.ShouldNotify(models.LogLevelCritical, "api") {
+		t.Error("Expected email channels not to receive individual logs")
+	}
+}
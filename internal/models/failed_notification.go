@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FailedNotification is a dead-lettered notification job that exhausted its retries
+type FailedNotification struct {
+	ID         string          `json:"id"`
+	ChannelID  string          `json:"channel_id"`
+	ProjectID  string          `json:"project_id"`
+	LogID      string          `json:"log_id"`
+	Payload    json.RawMessage `json:"payload"` // The original queued job
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"last_error,omitempty"`
+	ReplayedAt *time.Time      `json:"replayed_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type FailedNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewFailedNotificationRepository(db *sql.DB) *FailedNotificationRepository {
+	return &FailedNotificationRepository{db: db}
+}
+
+func (r *FailedNotificationRepository) Create(f *FailedNotification) error {
+	f.ID = uuid.New().String()
+	f.CreatedAt = time.Now()
+
+	_, err := r.db.Exec(`
+		INSERT INTO failed_notifications (id, channel_id, project_id, log_id, payload, attempts, last_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, f.ID, f.ChannelID, f.ProjectID, f.LogID, string(f.Payload), f.Attempts, f.LastError, f.CreatedAt)
+
+	return err
+}
+
+func (r *FailedNotificationRepository) GetByID(id string) (*FailedNotification, error) {
+	f, err := scanFailedNotification(r.db.QueryRow(`
+		SELECT id, channel_id, project_id, log_id, payload, attempts, last_error, replayed_at, created_at
+		FROM failed_notifications WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// GetByChannelID returns a channel's failures, newest first, with the total count
+func (r *FailedNotificationRepository) GetByChannelID(channelID string, limit, offset int) ([]*FailedNotification, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM failed_notifications WHERE channel_id = ?`, channelID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, channel_id, project_id, log_id, payload, attempts, last_error, replayed_at, created_at
+		FROM failed_notifications
+		WHERE channel_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, channelID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	failures := []*FailedNotification{}
+	for rows.Next() {
+		f, err := scanFailedNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		failures = append(failures, f)
+	}
+
+	return failures, total, rows.Err()
+}
+
+// MarkReplayed records that a failure was re-queued
+func (r *FailedNotificationRepository) MarkReplayed(id string) error {
+	_, err := r.db.Exec(`UPDATE failed_notifications SET replayed_at = ? WHERE id = ?`, time.Now(), id)
+	return err
+}
+
+func scanFailedNotification(row rowScanner) (*FailedNotification, error) {
+	f := &FailedNotification{}
+	var payload string
+	var lastError sql.NullString
+	var replayedAt sql.NullTime
+
+	if err := row.Scan(&f.ID, &f.ChannelID, &f.ProjectID, &f.LogID, &payload, &f.Attempts, &lastError, &replayedAt, &f.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	f.Payload = json.RawMessage(payload)
+	f.LastError = lastError.String
+	if replayedAt.Valid {
+		f.ReplayedAt = &replayedAt.Time
+	}
+
+	return f, nil
+}
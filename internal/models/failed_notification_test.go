@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"central-logs/internal/models"
+)
+
+func TestFailedNotificationRepository_CreateListReplay(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE failed_notifications (
+			id TEXT PRIMARY KEY,
+			channel_id TEXT NOT NULL,
+			project_id TEXT NOT NULL,
+			log_id TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			replayed_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create failed_notifications table: %v", err)
+	}
+
+	repo := models.NewFailedNotificationRepository(db)
+
+	for i := 0; i < 3; i++ {
+		f := &models.FailedNotification{
+			ChannelID: "chan-1",
+			ProjectID: "proj-1",
+			LogID:     "log-1",
+			Payload:   json.RawMessage(`{"log_id":"log-1","channel_id":"chan-1","attempts":5}`),
+			Attempts:  5,
+			LastError: "Telegram API returned status 502",
+		}
+		if err := repo.Create(f); err != nil {
+			t.Fatalf("Failed to create failed notification: %v", err)
+		}
+	}
+
+	failures, total, err := repo.GetByChannelID("chan-1", 2, 0)
+	if err != nil {
+		t.Fatalf("Failed to list failures: %v", err)
+	}
+	if total != 3 || len(failures) != 2 {
+		t.Fatalf("Expected 2 of 3 failures, got %d of %d", len(failures), total)
+	}
+	if failures[0].LastError == "" || failures[0].Attempts != 5 {
+		t.Errorf("Unexpected failure: %+v", failures[0])
+	}
+
+	if err := repo.MarkReplayed(failures[0].ID); err != nil {
+		t.Fatalf("Failed to mark replayed: %v", err)
+	}
+	replayed, _ := repo.GetByID(failures[0].ID)
+	if replayed == nil || replayed.ReplayedAt == nil {
+		t.Error("Expected replayed_at to be set")
+	}
+
+	var job map[string]interface{}
+	if err := json.Unmarshal(replayed.Payload, &job); err != nil || job["log_id"] != "log-1" {
+		t.Errorf("Expected payload to round-trip, got %s", string(replayed.Payload))
+	}
+}
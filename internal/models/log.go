@@ -1,9 +1,17 @@
 package models
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
+
+	"central-logs/internal/database"
 
 	"github.com/google/uuid"
 )
@@ -18,6 +26,20 @@ const (
 	LogLevelCritical LogLevel = "CRITICAL"
 )
 
+// AllLogLevels lists the supported levels in ascending priority
+var AllLogLevels = []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, LogLevelCritical}
+
+// LevelsAtOrAbove returns the levels whose priority is at least that of min
+func LevelsAtOrAbove(min LogLevel) []LogLevel {
+	levels := make([]LogLevel, 0, len(AllLogLevels))
+	for _, level := range AllLogLevels {
+		if level.Priority() >= min.Priority() {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
 func (l LogLevel) Priority() int {
 	switch l {
 	case LogLevelDebug:
@@ -52,6 +74,60 @@ func ParseLogLevel(s string) LogLevel {
 	}
 }
 
+// LevelInfo describes a level for clients that display logs
+type LevelInfo struct {
+	Level     LogLevel `json:"level"`
+	Priority  int      `json:"priority"`
+	Label     string   `json:"label"`
+	Color     string   `json:"color"`      // Suggested badge background, as hex
+	TextColor string   `json:"text_color"` // Readable text color on Color
+}
+
+// levelDisplay holds the suggested label and colors for each level; they
+// match the dashboard's level badges
+var levelDisplay = map[LogLevel]LevelInfo{
+	LogLevelDebug:    {Label: "Debug", Color: "#6b7280", TextColor: "#ffffff"},
+	LogLevelInfo:     {Label: "Info", Color: "#3b82f6", TextColor: "#ffffff"},
+	LogLevelWarn:     {Label: "Warning", Color: "#eab308", TextColor: "#000000"},
+	LogLevelError:    {Label: "Error", Color: "#ef4444", TextColor: "#ffffff"},
+	LogLevelCritical: {Label: "Critical", Color: "#9333ea", TextColor: "#ffffff"},
+}
+
+// Info returns the level's priority and suggested display. Levels without a
+// display entry get their name as the label and a neutral gray.
+func (l LogLevel) Info() LevelInfo {
+	info, ok := levelDisplay[l]
+	if !ok {
+		info = LevelInfo{Label: string(l), Color: "#6b7280", TextColor: "#ffffff"}
+	}
+	info.Level = l
+	info.Priority = l.Priority()
+	return info
+}
+
+// AllLevelInfo describes every supported level in ascending priority
+func AllLevelInfo() []LevelInfo {
+	infos := make([]LevelInfo, len(AllLogLevels))
+	for i, level := range AllLogLevels {
+		infos[i] = level.Info()
+	}
+	return infos
+}
+
+// LogStatus tracks whether someone has looked at a log
+type LogStatus string
+
+const (
+	LogStatusNew          LogStatus = "new"
+	LogStatusAcknowledged LogStatus = "acknowledged"
+	LogStatusResolved     LogStatus = "resolved"
+)
+
+// IsValidLogStatus reports whether s is a known status
+func IsValidLogStatus(s LogStatus) bool {
+	return s == LogStatusNew || s == LogStatusAcknowledged || s == LogStatusResolved
+}
+
 type Log struct {
 	ID        string                 `json:"id"`
 	ProjectID string                 `json:"project_id"`
@@ -62,35 +138,140 @@ type Log struct {
 	Timestamp time.Time              `json:"timestamp"`
 	CreatedAt time.Time              `json:"created_at"`
 
+	Status         LogStatus  `json:"status"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"` // User who last changed the status
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+
 	// Joined fields
 	ProjectName string `json:"project_name,omitempty"`
 }
 
+// Fingerprint identifies logs that report the same event: the same project,
+// source and message once digits are masked, so "timeout after 532ms" and
+// "timeout after 611ms" share a fingerprint. Level is left out so an error
+// that escalates to CRITICAL keeps its fingerprint.
+func (l *Log) Fingerprint() string {
+	message := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '#'
+		}
+		return r
+	}, l.Message)
+
+	sum := sha256.Sum256([]byte(l.ProjectID + "\x00" + l.Source + "\x00" + message))
+	return hex.EncodeToString(sum[:16])
+}
+
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexIDPattern  = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{8,}\b`)
+	numberPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+)
+
+// NormalizeMessage reduces a message to its pattern so that occurrences of
+// the same error group together: only the first line is kept, UUIDs become
+// <uuid>, long hex IDs <hex> and numbers <n>, and whitespace is collapsed.
+func NormalizeMessage(message string) string {
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message = message[:i]
+	}
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = hexIDPattern.ReplaceAllStringFunc(message, func(id string) string {
+		// All-letter words such as "deadbeef" or "facade" are left alone
+		if strings.IndexFunc(id, unicode.IsDigit) < 0 {
+			return id
+		}
+		return "<hex>"
+	})
+	message = numberPattern.ReplaceAllString(message, "<n>")
+	return strings.Join(strings.Fields(message), " ")
+}
+
+// Time fields a LogFilter's range can apply to
+const (
+	LogTimeFieldTimestamp = "timestamp"  // Event time reported by the client
+	LogTimeFieldCreatedAt = "created_at" // Time the server stored the log
+)
+
+// IsValidLogTimeField reports whether field can be used as LogFilter.TimeField
+func IsValidLogTimeField(field string) bool {
+	return field == "" || field == LogTimeFieldTimestamp || field == LogTimeFieldCreatedAt
+}
+
 type LogFilter struct {
 	ProjectIDs []string
 	Levels     []LogLevel
 	Source     string
-	Search     string
+	Search     string // Space-separated terms that must all appear; "quoted text" matches as a phrase
+	Status     LogStatus
 	StartTime  *time.Time
 	EndTime    *time.Time
+	TimeField  string // Column StartTime/EndTime apply to; defaults to timestamp
 	Limit      int
 	Offset     int
+	// SkipMetadata leaves Metadata nil on listed logs, so List neither reads
+	// nor decodes it
+	SkipMetadata bool
+}
+
+// LogQueryBounds keeps log listings from reading the whole table. The REST
+// API and the MCP tools share it so both clamp queries the same way.
+type LogQueryBounds struct {
+	DefaultRange time.Duration // Lookback used when a filter has no time range; zero disables it
+	MaxLimit     int           // Largest page size; zero means no cap
+}
+
+// Apply sets the default time range on filters without a start or end time
+// and clamps the limit
+func (b LogQueryBounds) Apply(filter *LogFilter) {
+	if b.DefaultRange > 0 && filter.StartTime == nil && filter.EndTime == nil {
+		start := time.Now().Add(-b.DefaultRange)
+		filter.StartTime = &start
+	}
+	filter.Limit = b.ClampLimit(filter.Limit)
+}
+
+// ClampLimit returns limit, lowered to MaxLimit if it is larger
+func (b LogQueryBounds) ClampLimit(limit int) int {
+	if b.MaxLimit > 0 && limit > b.MaxLimit {
+		return b.MaxLimit
+	}
+	return limit
+}
+
+// timeColumn returns the qualified column the time range filters and sorts on
+func (f *LogFilter) timeColumn() string {
+	if f.TimeField == LogTimeFieldCreatedAt {
+		return "l.created_at"
+	}
+	return "l.timestamp"
 }
 
 type LogRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect database.Dialect
 }
 
 func NewLogRepository(db *sql.DB) *LogRepository {
-	return &LogRepository{db: db}
+	dialect, _ := database.DialectFor(database.DriverSQLite)
+	return &LogRepository{db: db, dialect: dialect}
+}
+
+// SetDialect sets the SQL dialect of the few queries that differ between
+// backends; SQLite is assumed until it is called
+func (r *LogRepository) SetDialect(dialect database.Dialect) {
+	r.dialect = dialect
 }
 
 func (r *LogRepository) Create(log *Log) error {
 	log.ID = uuid.New().String()
-	log.CreatedAt = time.Now()
+	log.CreatedAt = time.Now().UTC()
+	log.Status = LogStatusNew
 	if log.Timestamp.IsZero() {
 		log.Timestamp = log.CreatedAt
 	}
+	// SQLite compares stored times as text, so they must all share one offset
+	log.Timestamp = log.Timestamp.UTC()
 
 	var metadataJSON *string
 	if log.Metadata != nil {
@@ -117,75 +298,158 @@ func (r *LogRepository) CreateBatch(logs []*Log) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-		INSERT INTO logs (id, project_id, level, message, metadata, source, timestamp, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`)
+	stmt, err := tx.Prepare(batchInsertLog)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for _, log := range logs {
-		log.ID = uuid.New().String()
-		log.CreatedAt = time.Now()
-		if log.Timestamp.IsZero() {
-			log.Timestamp = log.CreatedAt
+		if err := insertBatchLog(stmt, log); err != nil {
+			return err
 		}
+	}
 
-		var metadataJSON *string
-		if log.Metadata != nil {
-			data, err := json.Marshal(log.Metadata)
-			if err != nil {
-				return err
+	return tx.Commit()
+}
+
+// BatchInsertError is a log of a best-effort batch that could not be stored
+type BatchInsertError struct {
+	Index int // Into the logs passed to CreateBatchBestEffort
+	Err   error
+}
+
+// CreateBatchBestEffort stores logs like CreateBatch, except that a log which
+// fails to insert is skipped instead of rolling back the others. Each insert
+// runs in its own savepoint of one transaction, so this stays about as fast
+// as CreateBatch. It returns the logs that were skipped; the rest have their
+// IDs set. An error means nothing was stored.
+func (r *LogRepository) CreateBatchBestEffort(logs []*Log) ([]BatchInsertError, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(batchInsertLog)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var failed []BatchInsertError
+	for i, log := range logs {
+		if _, err := tx.Exec("SAVEPOINT batch_log"); err != nil {
+			return nil, err
+		}
+		if err := insertBatchLog(stmt, log); err != nil {
+			// Postgres refuses further statements until the failed one is undone
+			if _, rollbackErr := tx.Exec("ROLLBACK TO SAVEPOINT batch_log"); rollbackErr != nil {
+				return nil, rollbackErr
 			}
-			s := string(data)
-			metadataJSON = &s
+			failed = append(failed, BatchInsertError{Index: i, Err: err})
+			continue
+		}
+		if _, err := tx.Exec("RELEASE SAVEPOINT batch_log"); err != nil {
+			return nil, err
 		}
+	}
 
-		if _, err := stmt.Exec(log.ID, log.ProjectID, log.Level, log.Message, metadataJSON, log.Source, log.Timestamp, log.CreatedAt); err != nil {
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+const batchInsertLog = `
+	INSERT INTO logs (id, project_id, level, message, metadata, source, timestamp, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// insertBatchLog fills in log's defaults and inserts it with stmt
+func insertBatchLog(stmt *sql.Stmt, log *Log) error {
+	// Buffered ingestion assigns IDs up front, so keep any that are set
+	if log.ID == "" {
+		log.ID = uuid.New().String()
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+	if log.Timestamp.IsZero() {
+		log.Timestamp = log.CreatedAt
+	}
+	log.CreatedAt = log.CreatedAt.UTC()
+	log.Timestamp = log.Timestamp.UTC()
+	log.Status = LogStatusNew
+
+	var metadataJSON *string
+	if log.Metadata != nil {
+		data, err := json.Marshal(log.Metadata)
+		if err != nil {
 			return err
 		}
+		s := string(data)
+		metadataJSON = &s
 	}
 
-	return tx.Commit()
+	_, err := stmt.Exec(log.ID, log.ProjectID, log.Level, log.Message, metadataJSON, log.Source, log.Timestamp, log.CreatedAt)
+	return err
 }
 
 func (r *LogRepository) GetByID(id string) (*Log, error) {
-	log := &Log{}
-	var metadataJSON sql.NullString
-	var source sql.NullString
-
-	err := r.db.QueryRow(`
-		SELECT l.id, l.project_id, l.level, l.message, l.metadata, l.source, l.timestamp, l.created_at, p.name
+	rows, err := r.db.Query(`
+		SELECT `+logColumns+`
 		FROM logs l
 		INNER JOIN projects p ON l.project_id = p.id
-		WHERE l.id = ?
-	`, id).Scan(&log.ID, &log.ProjectID, &log.Level, &log.Message, &metadataJSON, &source, &log.Timestamp, &log.CreatedAt, &log.ProjectName)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+		WHERE l.id = ? AND `+liveProjectsClause+`
+	`, id)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	if source.Valid {
-		log.Source = source.String
+	if !rows.Next() {
+		return nil, rows.Err()
 	}
+	return scanLog(rows)
+}
 
-	if metadataJSON.Valid {
-		if err := json.Unmarshal([]byte(metadataJSON.String), &log.Metadata); err != nil {
-			return nil, err
+// searchTerms splits a search query on whitespace, keeping text inside double
+// quotes together as one phrase. An unclosed quote runs to the end.
+func searchTerms(query string) []string {
+	var terms []string
+	for {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			return terms
 		}
-	}
 
-	return log, nil
+		if rest, ok := strings.CutPrefix(query, `"`); ok {
+			phrase, after, _ := strings.Cut(rest, `"`)
+			if phrase = strings.TrimSpace(phrase); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			query = after
+			continue
+		}
+
+		end := strings.IndexFunc(query, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(query)
+		}
+		terms = append(terms, query[:end])
+		query = query[end:]
+	}
 }
 
-func (r *LogRepository) List(filter *LogFilter) ([]*Log, int, error) {
-	// Build WHERE clause
-	where := "1=1"
+// liveProjectsClause keeps logs of soft-deleted projects out of every listing
+// and count while leaving the rows in place for a restore
+const liveProjectsClause = "l.project_id NOT IN (SELECT id FROM projects WHERE deleted_at IS NOT NULL)"
+
+// buildWhere turns a filter into a SQL predicate over the logs table aliased
+// as "l", along with its positional arguments
+func buildWhere(filter *LogFilter) (string, []interface{}) {
+	where := liveProjectsClause
 	args := []interface{}{}
 
 	if len(filter.ProjectIDs) > 0 {
@@ -217,21 +481,35 @@ func (r *LogRepository) List(filter *LogFilter) ([]*Log, int, error) {
 		args = append(args, filter.Source)
 	}
 
-	if filter.Search != "" {
-		where += " AND l.message LIKE ?"
-		args = append(args, "%"+filter.Search+"%")
+	if filter.Status != "" {
+		where += " AND l.status = ?"
+		args = append(args, filter.Status)
 	}
 
+	// LOWER on both sides keeps matching case-insensitive on every dialect
+	for _, term := range searchTerms(filter.Search) {
+		where += " AND LOWER(l.message) LIKE ?"
+		args = append(args, "%"+strings.ToLower(term)+"%")
+	}
+
+	// Times are stored in UTC; bounds in another offset would compare as
+	// text against the wrong hour
 	if filter.StartTime != nil {
-		where += " AND l.created_at >= ?"
-		args = append(args, filter.StartTime)
+		where += " AND " + filter.timeColumn() + " >= ?"
+		args = append(args, filter.StartTime.UTC())
 	}
 
 	if filter.EndTime != nil {
-		where += " AND l.created_at <= ?"
-		args = append(args, filter.EndTime)
+		where += " AND " + filter.timeColumn() + " <= ?"
+		args = append(args, filter.EndTime.UTC())
 	}
 
+	return where, args
+}
+
+func (r *LogRepository) List(filter *LogFilter) ([]*Log, int, error) {
+	where, args := buildWhere(filter)
+
 	// Get total count
 	var total int
 	countQuery := "SELECT COUNT(*) FROM logs l WHERE " + where
@@ -240,12 +518,28 @@ func (r *LogRepository) List(filter *LogFilter) ([]*Log, int, error) {
 	}
 
 	// Get logs
+	query, args := listQuery(filter)
+	logs, err := r.queryLogs(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// listQuery builds the query List pages through, newest first
+func listQuery(filter *LogFilter) (string, []interface{}) {
+	where, args := buildWhere(filter)
+	columns := logColumns
+	if filter.SkipMetadata {
+		columns = logColumnsWithoutMetadata
+	}
 	query := `
-		SELECT l.id, l.project_id, l.level, l.message, l.metadata, l.source, l.timestamp, l.created_at, p.name
+		SELECT ` + columns + `
 		FROM logs l
 		INNER JOIN projects p ON l.project_id = p.id
 		WHERE ` + where + `
-		ORDER BY l.created_at DESC
+		ORDER BY ` + filter.timeColumn() + ` DESC
 		LIMIT ? OFFSET ?
 	`
 
@@ -258,38 +552,361 @@ func (r *LogRepository) List(filter *LogFilter) ([]*Log, int, error) {
 		offset = 0
 	}
 
-	args = append(args, limit, offset)
+	return query, append(args, limit, offset)
+}
+
+// logColumns selects a log with its project name; queries using it must join
+// projects as "p" onto logs as "l"
+const logColumns = "l.id, l.project_id, l.level, l.message, l.metadata, l.source, l.timestamp, l.created_at, l.status, l.acknowledged_by, l.acknowledged_at, p.name"
 
+// logColumnsWithoutMetadata is logColumns with NULL in place of the metadata
+const logColumnsWithoutMetadata = "l.id, l.project_id, l.level, l.message, NULL, l.source, l.timestamp, l.created_at, l.status, l.acknowledged_by, l.acknowledged_at, p.name"
+
+// queryLogs runs a query selecting logColumns and scans the results
+func (r *LogRepository) queryLogs(query string, args ...interface{}) ([]*Log, error) {
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	var logs []*Log
 	for rows.Next() {
-		log := &Log{}
-		var metadataJSON sql.NullString
-		var source sql.NullString
+		log, err := scanLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
 
-		if err := rows.Scan(&log.ID, &log.ProjectID, &log.Level, &log.Message, &metadataJSON, &source, &log.Timestamp, &log.CreatedAt, &log.ProjectName); err != nil {
-			return nil, 0, err
+	return logs, rows.Err()
+}
+
+// scanLog reads a row selected with logColumns
+func scanLog(rows *sql.Rows) (*Log, error) {
+	log := &Log{}
+	var metadataJSON sql.NullString
+	var source, acknowledgedBy sql.NullString
+	var acknowledgedAt sql.NullTime
+
+	if err := rows.Scan(&log.ID, &log.ProjectID, &log.Level, &log.Message, &metadataJSON, &source, &log.Timestamp, &log.CreatedAt,
+		&log.Status, &acknowledgedBy, &acknowledgedAt, &log.ProjectName); err != nil {
+		return nil, err
+	}
+
+	if source.Valid {
+		log.Source = source.String
+	}
+	log.AcknowledgedBy = acknowledgedBy.String
+	if acknowledgedAt.Valid {
+		log.AcknowledgedAt = &acknowledgedAt.Time
+	}
+
+	if metadataJSON.Valid {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &log.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return log, nil
+}
+
+// Walk calls fn for every log matching filter, oldest first, stopping at the
+// first error fn returns. Rows are read batchSize at a time and each batch's
+// query finishes before fn sees it, so a slow fn, such as one writing to an
+// HTTP client, never holds the database connection. Limit and Offset are
+// ignored.
+func (r *LogRepository) Walk(filter *LogFilter, batchSize int, fn func(*Log) error) error {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	where, args := buildWhere(filter)
+	column := filter.timeColumn()
+
+	var last *Log
+	for {
+		pageWhere, pageArgs := where, append([]interface{}(nil), args...)
+		if last != nil {
+			// Resume after the previous batch; the id breaks ties between
+			// logs sharing a time
+			lastTime := last.Timestamp
+			if filter.TimeField == LogTimeFieldCreatedAt {
+				lastTime = last.CreatedAt
+			}
+			pageWhere += " AND (" + column + " > ? OR (" + column + " = ? AND l.id > ?))"
+			pageArgs = append(pageArgs, lastTime, lastTime, last.ID)
 		}
+		pageArgs = append(pageArgs, batchSize)
 
-		if source.Valid {
-			log.Source = source.String
+		logs, err := r.queryLogs(`
+			SELECT `+logColumns+`
+			FROM logs l
+			INNER JOIN projects p ON l.project_id = p.id
+			WHERE `+pageWhere+`
+			ORDER BY `+column+` ASC, l.id ASC
+			LIMIT ?
+		`, pageArgs...)
+		if err != nil {
+			return err
 		}
 
-		if metadataJSON.Valid {
-			if err := json.Unmarshal([]byte(metadataJSON.String), &log.Metadata); err != nil {
-				return nil, 0, err
+		for _, log := range logs {
+			if err := fn(log); err != nil {
+				return err
 			}
 		}
+		if len(logs) < batchSize {
+			return nil
+		}
+		last = logs[len(logs)-1]
+	}
+}
 
-		logs = append(logs, log)
+// Count returns the number of logs matching the filter; Limit and Offset are ignored
+func (r *LogRepository) Count(filter *LogFilter) (int, error) {
+	where, args := buildWhere(filter)
+
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM logs l WHERE "+where, args...).Scan(&count)
+	return count, err
+}
+
+// FacetCount is the number of logs sharing one value of a facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// maxFacetValues caps facets with open-ended values such as source
+const maxFacetValues = 20
+
+// FacetByLevel counts logs matching the filter per level
+func (r *LogRepository) FacetByLevel(filter *LogFilter) ([]FacetCount, error) {
+	where, args := buildWhere(filter)
+	return r.queryFacet(`
+		SELECT l.level, '', COUNT(*) AS cnt
+		FROM logs l
+		WHERE `+where+`
+		GROUP BY l.level
+		ORDER BY cnt DESC, l.level
+	`, args)
+}
+
+// FacetByProject counts logs matching the filter per project, labelled with the project name
+func (r *LogRepository) FacetByProject(filter *LogFilter) ([]FacetCount, error) {
+	where, args := buildWhere(filter)
+	return r.queryFacet(`
+		SELECT l.project_id, p.name, COUNT(*) AS cnt
+		FROM logs l
+		INNER JOIN projects p ON l.project_id = p.id
+		WHERE `+where+`
+		GROUP BY l.project_id, p.name
+		ORDER BY cnt DESC, p.name
+	`, args)
+}
+
+// FacetBySource counts logs matching the filter per source, keeping the most common ones
+func (r *LogRepository) FacetBySource(filter *LogFilter) ([]FacetCount, error) {
+	where, args := buildWhere(filter)
+	args = append(args, maxFacetValues)
+	return r.queryFacet(`
+		SELECT COALESCE(l.source, ''), '', COUNT(*) AS cnt
+		FROM logs l
+		WHERE `+where+`
+		GROUP BY COALESCE(l.source, '')
+		ORDER BY cnt DESC, 1
+		LIMIT ?
+	`, args)
+}
+
+// FacetByMessage counts logs matching the filter per message, keeping the
+// limit most repeated ones
+func (r *LogRepository) FacetByMessage(filter *LogFilter, limit int) ([]FacetCount, error) {
+	where, args := buildWhere(filter)
+	args = append(args, limit)
+	return r.queryFacet(`
+		SELECT l.message, '', COUNT(*) AS cnt
+		FROM logs l
+		WHERE `+where+`
+		GROUP BY l.message
+		ORDER BY cnt DESC, l.message
+		LIMIT ?
+	`, args)
+}
+
+// maxDistinctSources caps the source list offered in filter dropdowns
+const maxDistinctSources = 200
+
+// DistinctSources lists the non-empty sources seen in a project, most common first
+func (r *LogRepository) DistinctSources(projectID string) ([]FacetCount, error) {
+	return r.DistinctSourcesForProjects([]string{projectID})
+}
+
+// DistinctSourcesForProjects lists the non-empty sources seen across projects,
+// most common first. Nil covers every project; an empty slice matches none.
+func (r *LogRepository) DistinctSourcesForProjects(projectIDs []string) ([]FacetCount, error) {
+	if projectIDs != nil && len(projectIDs) == 0 {
+		return []FacetCount{}, nil
 	}
 
-	return logs, total, nil
+	where, args := buildWhere(&LogFilter{ProjectIDs: projectIDs})
+	args = append(args, maxDistinctSources)
+	return r.queryFacet(`
+		SELECT l.source, '', COUNT(*) AS cnt
+		FROM logs l
+		WHERE `+where+` AND l.source IS NOT NULL AND l.source != ''
+		GROUP BY l.source
+		ORDER BY cnt DESC, l.source
+		LIMIT ?
+	`, args)
+}
+
+// maxMetadataKeys caps the keys MetadataKeys returns
+const maxMetadataKeys = 200
+
+// MetadataKeyCount is a top-level metadata key and how many of the sampled
+// logs have it
+type MetadataKeyCount struct {
+	Key       string  `json:"key"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"` // Share of the sampled logs, 0 to 1
+}
+
+// MetadataKeySample is the result of MetadataKeys along with the logs it
+// looked at
+type MetadataKeySample struct {
+	Keys    []MetadataKeyCount `json:"keys"`
+	Sampled int                `json:"sampled"`         // Logs examined
+	Since   *time.Time         `json:"since,omitempty"` // Timestamp of the oldest examined log
+}
+
+// MetadataKeys lists the top-level metadata keys of a project's newest
+// sampleSize logs, most common first. Reading the metadata of every log would
+// scan the whole project, so older logs are not examined.
+func (r *LogRepository) MetadataKeys(projectID string, sampleSize int) (*MetadataKeySample, error) {
+	sample := `
+		SELECT l.metadata
+		FROM logs l
+		WHERE l.project_id = ?
+		ORDER BY l.timestamp DESC
+		LIMIT ?
+	`
+
+	result := &MetadataKeySample{Keys: []MetadataKeyCount{}}
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM ("+sample+") s", projectID, sampleSize).Scan(&result.Sampled); err != nil {
+		return nil, err
+	}
+	if result.Sampled == 0 {
+		return result, nil
+	}
+
+	var since time.Time
+	err := r.db.QueryRow(`
+		SELECT l.timestamp
+		FROM logs l
+		WHERE l.project_id = ?
+		ORDER BY l.timestamp DESC
+		LIMIT 1 OFFSET ?
+	`, projectID, result.Sampled-1).Scan(&since)
+	if err != nil {
+		return nil, err
+	}
+	result.Since = &since
+
+	rows, err := r.db.Query(`
+		SELECT k.key, COUNT(*) AS cnt
+		FROM (`+sample+`) s, `+r.dialect.JSONKeys("s.metadata", "k")+`
+		GROUP BY k.key
+		ORDER BY cnt DESC, k.key
+		LIMIT ?
+	`, projectID, sampleSize, maxMetadataKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key MetadataKeyCount
+		if err := rows.Scan(&key.Key, &key.Count); err != nil {
+			return nil, err
+		}
+		key.Frequency = float64(key.Count) / float64(result.Sampled)
+		result.Keys = append(result.Keys, key)
+	}
+	return result, rows.Err()
+}
+
+func (r *LogRepository) queryFacet(query string, args []interface{}) ([]FacetCount, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := []FacetCount{}
+	for rows.Next() {
+		var f FacetCount
+		if err := rows.Scan(&f.Value, &f.Label, &f.Count); err != nil {
+			return nil, err
+		}
+		facets = append(facets, f)
+	}
+
+	return facets, rows.Err()
+}
+
+// UpdateStatusByFilter sets the status of every log matching filter that
+// does not have it yet, recording userID as the one who changed it, and
+// returns how many logs changed. Rows are updated batchSize at a time so a
+// large burst doesn't hold the write lock in one long statement. Limit and
+// Offset are ignored.
+func (r *LogRepository) UpdateStatusByFilter(filter *LogFilter, status LogStatus, userID string, batchSize int) (int64, error) {
+	where, args := buildWhere(filter)
+	query := `
+		UPDATE logs SET status = ?, acknowledged_by = ?, acknowledged_at = ?
+		WHERE id IN (
+			SELECT l.id FROM logs l
+			WHERE ` + where + ` AND l.status <> ?
+			LIMIT ?
+		)
+	`
+
+	now := time.Now()
+	var total int64
+	for {
+		batchArgs := append([]interface{}{status, userID, now}, args...)
+		batchArgs = append(batchArgs, status, batchSize)
+		changed, err := r.db.Exec(query, batchArgs...)
+		if err != nil {
+			return total, err
+		}
+		n, err := changed.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// UpdateStatus sets one log's status, recording userID as who changed it.
+// It reports false if no such log exists.
+func (r *LogRepository) UpdateStatus(id string, status LogStatus, userID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE logs SET status = ?, acknowledged_by = ?, acknowledged_at = ?
+		WHERE id = ? AND project_id NOT IN (SELECT id FROM projects WHERE deleted_at IS NOT NULL)
+	`, status, userID, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
 }
 
 func (r *LogRepository) DeleteOlderThan(projectID string, level LogLevel, before time.Time, batchSize int) (int64, error) {
@@ -299,7 +916,7 @@ func (r *LogRepository) DeleteOlderThan(projectID string, level LogLevel, before
 			WHERE project_id = ? AND level = ? AND created_at < ?
 			LIMIT ?
 		)
-	`, projectID, level, before, batchSize)
+	`, projectID, level, before.UTC(), batchSize)
 	if err != nil {
 		return 0, err
 	}
@@ -337,6 +954,74 @@ func (r *LogRepository) CountByProject(projectID string) (int, error) {
 	return count, err
 }
 
+// CountSince counts the logs matching an alert rule's project, level, source
+// and metadata condition that were created since the given time
+func (r *LogRepository) CountSince(rule *AlertRule, since time.Time) (int, error) {
+	// Alert windows measure ingestion volume, so backfilled logs count when they arrive
+	where, args := buildWhere(&LogFilter{
+		ProjectIDs: []string{rule.ProjectID},
+		Levels:     LevelsAtOrAbove(rule.MinLevel),
+		Source:     rule.Source,
+		StartTime:  &since,
+		TimeField:  LogTimeFieldCreatedAt,
+	})
+
+	if rule.MetadataKey != "" {
+		// The key is embedded in the JSON path, so it must not carry SQL
+		if !IsValidMetadataKey(rule.MetadataKey) {
+			return 0, fmt.Errorf("invalid metadata key %q", rule.MetadataKey)
+		}
+		where += " AND CAST(" + r.dialect.JSONExtract("l.metadata", rule.MetadataKey) + " AS TEXT) = ?"
+		args = append(args, rule.MetadataValue)
+	}
+
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM logs l WHERE "+where, args...).Scan(&count)
+	return count, err
+}
+
+// ProjectLogStats is a project's log count in total and per level
+type ProjectLogStats struct {
+	Total   int
+	ByLevel map[LogLevel]int
+}
+
+// GetStatsForProjects counts logs per project and level in a single grouped
+// query. Projects without logs are absent from the result; no IDs yields an
+// empty map rather than stats for every project.
+func (r *LogRepository) GetStatsForProjects(projectIDs []string) (map[string]*ProjectLogStats, error) {
+	stats := make(map[string]*ProjectLogStats)
+	if len(projectIDs) == 0 {
+		return stats, nil
+	}
+
+	where, args := buildWhere(&LogFilter{ProjectIDs: projectIDs})
+	rows, err := r.db.Query("SELECT l.project_id, l.level, COUNT(*) FROM logs l WHERE "+where+" GROUP BY l.project_id, l.level", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var projectID string
+		var level LogLevel
+		var count int
+		if err := rows.Scan(&projectID, &level, &count); err != nil {
+			return nil, err
+		}
+
+		ps, ok := stats[projectID]
+		if !ok {
+			ps = &ProjectLogStats{ByLevel: make(map[LogLevel]int)}
+			stats[projectID] = ps
+		}
+		ps.Total += count
+		ps.ByLevel[level] += count
+	}
+
+	return stats, rows.Err()
+}
+
 func (r *LogRepository) GetStats() (map[string]int, error) {
 	rows, err := r.db.Query(`
 		SELECT level, COUNT(*) FROM logs GROUP BY level
@@ -379,32 +1064,26 @@ func (r *LogRepository) GetProjectStats(projectID string) (map[string]int, error
 	return stats, nil
 }
 
-// CountToday returns the count of logs created today
-func (r *LogRepository) CountToday(projectIDs []string) (int, error) {
-	today := time.Now().Truncate(24 * time.Hour)
-
-	var count int
-	var err error
-
-	if len(projectIDs) == 0 {
-		err = r.db.QueryRow(`
-			SELECT COUNT(*) FROM logs WHERE created_at >= ?
-		`, today).Scan(&count)
-	} else {
-		placeholders := ""
-		args := []interface{}{today}
-		for i, id := range projectIDs {
-			if i > 0 {
-				placeholders += ","
-			}
-			placeholders += "?"
-			args = append(args, id)
-		}
-		err = r.db.QueryRow(`
-			SELECT COUNT(*) FROM logs WHERE created_at >= ? AND project_id IN (`+placeholders+`)
-		`, args...).Scan(&count)
+// StartOfDay returns midnight of t's calendar day in loc. A nil loc means UTC.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
 	}
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// CountToday returns the count of logs created since midnight in loc, so
+// "today" follows the viewer's day rather than the server's. A nil loc
+// means UTC.
+func (r *LogRepository) CountToday(projectIDs []string, loc *time.Location) (int, error) {
+	// created_at is stored in UTC and SQLite compares it as text, so the
+	// bound has to be written in UTC too
+	today := StartOfDay(time.Now(), loc).UTC()
+	where, args := buildWhere(&LogFilter{ProjectIDs: projectIDs, StartTime: &today, TimeField: LogTimeFieldCreatedAt})
 
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM logs l WHERE "+where, args...).Scan(&count)
 	return count, err
 }
 
@@ -414,66 +1093,94 @@ func (r *LogRepository) GetRecent(projectIDs []string, limit int) ([]*Log, error
 		limit = 10
 	}
 
-	var query string
-	var args []interface{}
+	where, args := buildWhere(&LogFilter{ProjectIDs: projectIDs})
+	query := `
+		SELECT ` + logColumns + `
+		FROM logs l
+		INNER JOIN projects p ON l.project_id = p.id
+		WHERE ` + where + `
+		ORDER BY l.created_at DESC
+		LIMIT ?
+	`
+	args = append(args, limit)
 
-	if len(projectIDs) == 0 {
-		query = `
-			SELECT l.id, l.project_id, l.level, l.message, l.metadata, l.source, l.timestamp, l.created_at, p.name
-			FROM logs l
-			INNER JOIN projects p ON l.project_id = p.id
-			ORDER BY l.created_at DESC
-			LIMIT ?
-		`
-		args = []interface{}{limit}
-	} else {
-		placeholders := ""
-		for i, id := range projectIDs {
-			if i > 0 {
-				placeholders += ","
-			}
-			placeholders += "?"
-			args = append(args, id)
-		}
-		query = `
-			SELECT l.id, l.project_id, l.level, l.message, l.metadata, l.source, l.timestamp, l.created_at, p.name
-			FROM logs l
-			INNER JOIN projects p ON l.project_id = p.id
-			WHERE l.project_id IN (` + placeholders + `)
-			ORDER BY l.created_at DESC
-			LIMIT ?
-		`
-		args = append(args, limit)
-	}
+	return r.queryLogs(query, args...)
+}
 
-	rows, err := r.db.Query(query, args...)
-	if err != nil {
-		return nil, err
+// maxRecentByLevel caps how many logs GetRecentByMinLevel returns
+const maxRecentByLevel = 100
+
+// GetRecentByMinLevel returns the most recent logs at minLevel or above, newest
+// first. A nil projectIDs covers all projects; an empty one matches nothing.
+func (r *LogRepository) GetRecentByMinLevel(projectIDs []string, minLevel LogLevel, limit int) ([]*Log, error) {
+	if projectIDs != nil && len(projectIDs) == 0 {
+		return []*Log{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxRecentByLevel {
+		limit = maxRecentByLevel
 	}
-	defer rows.Close()
 
-	var logs []*Log
-	for rows.Next() {
-		log := &Log{}
-		var metadataJSON sql.NullString
-		var source sql.NullString
+	where, args := buildWhere(&LogFilter{ProjectIDs: projectIDs, Levels: LevelsAtOrAbove(minLevel)})
+	query := `
+		SELECT ` + logColumns + `
+		FROM logs l
+		INNER JOIN projects p ON l.project_id = p.id
+		WHERE ` + where + `
+		ORDER BY l.timestamp DESC, l.id DESC
+		LIMIT ?
+	`
+	args = append(args, limit)
 
-		if err := rows.Scan(&log.ID, &log.ProjectID, &log.Level, &log.Message, &metadataJSON, &source, &log.Timestamp, &log.CreatedAt, &log.ProjectName); err != nil {
-			return nil, err
-		}
+	return r.queryLogs(query, args...)
+}
 
-		if source.Valid {
-			log.Source = source.String
+// GetContext returns up to before logs preceding and after logs following the
+// given log in the same project, ordered by event timestamp. Both slices are
+// in chronological order. Ties on timestamp are broken by ID so paging
+// through a burst of same-second logs is stable.
+func (r *LogRepository) GetContext(log *Log, before, after int) ([]*Log, []*Log, error) {
+	var older, newer []*Log
+
+	if before > 0 {
+		var err error
+		older, err = r.queryLogs(`
+			SELECT `+logColumns+`
+			FROM logs l
+			INNER JOIN projects p ON l.project_id = p.id
+			WHERE l.project_id = ?
+			AND (l.timestamp < (SELECT timestamp FROM logs WHERE id = ?)
+				OR (l.timestamp = (SELECT timestamp FROM logs WHERE id = ?) AND l.id < ?))
+			ORDER BY l.timestamp DESC, l.id DESC
+			LIMIT ?
+		`, log.ProjectID, log.ID, log.ID, log.ID, before)
+		if err != nil {
+			return nil, nil, err
 		}
-
-		if metadataJSON.Valid {
-			if err := json.Unmarshal([]byte(metadataJSON.String), &log.Metadata); err != nil {
-				return nil, err
-			}
+		// Fetched newest first to get the closest logs; flip to chronological
+		for i, j := 0, len(older)-1; i < j; i, j = i+1, j-1 {
+			older[i], older[j] = older[j], older[i]
 		}
+	}
 
-		logs = append(logs, log)
+	if after > 0 {
+		var err error
+		newer, err = r.queryLogs(`
+			SELECT `+logColumns+`
+			FROM logs l
+			INNER JOIN projects p ON l.project_id = p.id
+			WHERE l.project_id = ?
+			AND (l.timestamp > (SELECT timestamp FROM logs WHERE id = ?)
+				OR (l.timestamp = (SELECT timestamp FROM logs WHERE id = ?) AND l.id > ?))
+			ORDER BY l.timestamp ASC, l.id ASC
+			LIMIT ?
+		`, log.ProjectID, log.ID, log.ID, log.ID, after)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return logs, nil
+	return older, newer, nil
 }
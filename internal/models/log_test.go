@@ -2,6 +2,8 @@ package models_test
 
 import (
 	"database/sql"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -10,7 +12,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func setupLogTestDB(t *testing.T) *sql.DB {
+func setupLogTestDB(t testing.TB) *sql.DB {
 	db, err := sql.Open("sqlite3", ":memory:")
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
@@ -24,6 +26,7 @@ func setupLogTestDB(t *testing.T) *sql.DB {
 			description TEXT,
 			api_key TEXT UNIQUE NOT NULL,
 			api_key_hash TEXT NOT NULL,
+			deleted_at DATETIME,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
@@ -36,7 +39,10 @@ func setupLogTestDB(t *testing.T) *sql.DB {
 			metadata TEXT,
 			source TEXT,
 			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			status TEXT NOT NULL DEFAULT 'new',
+			acknowledged_by TEXT,
+			acknowledged_at DATETIME
 		);
 		CREATE INDEX IF NOT EXISTS idx_logs_project_id ON logs(project_id);
 		CREATE INDEX IF NOT EXISTS idx_logs_level ON logs(level);
@@ -112,6 +118,53 @@ func TestLogRepository_CreateBatch(t *testing.T) {
 	_ = allLogs
 }
 
+func TestLogRepository_CreateBatchBestEffort(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+	existing := &models.Log{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "Already stored"}
+	if err := repo.Create(existing); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	newBatch := func() []*models.Log {
+		return []*models.Log{
+			{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "Log 1"},
+			// The database refuses a duplicate ID...
+			{ID: existing.ID, ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "Duplicate"},
+			// ...and NaN can't be encoded as JSON
+			{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "Bad metadata", Metadata: map[string]interface{}{"ratio": math.NaN()}},
+			{ProjectID: "proj-1", Level: models.LogLevelError, Message: "Log 4"},
+		}
+	}
+
+	// The transactional path still stores nothing when one row fails
+	if err := repo.CreateBatch(newBatch()); err == nil {
+		t.Fatal("Expected CreateBatch to fail")
+	}
+	if count, _ := repo.CountByProject("proj-1"); count != 1 {
+		t.Fatalf("Expected CreateBatch to roll back, got %d logs", count)
+	}
+
+	logs := newBatch()
+	failed, err := repo.CreateBatchBestEffort(logs)
+	if err != nil {
+		t.Fatalf("CreateBatchBestEffort failed: %v", err)
+	}
+	if len(failed) != 2 || failed[0].Index != 1 || failed[1].Index != 2 || failed[0].Err == nil || failed[1].Err == nil {
+		t.Fatalf("Expected entries 1 and 2 to fail, got %+v", failed)
+	}
+	if count, _ := repo.CountByProject("proj-1"); count != 3 {
+		t.Errorf("Expected the 2 good logs stored next to the existing one, got %d logs", count)
+	}
+	for _, i := range []int{0, 3} {
+		if stored, _ := repo.GetByID(logs[i].ID); stored == nil || stored.Message != logs[i].Message {
+			t.Errorf("Expected %q to be stored, got %+v", logs[i].Message, stored)
+		}
+	}
+}
+
 func TestLogRepository_GetByID(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
@@ -149,6 +202,65 @@ func TestLogRepository_GetByID(t *testing.T) {
 	}
 }
 
+func TestLogRepository_UpdateStatusByFilter(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+	db.Exec(`INSERT INTO projects (id, name, description, api_key, api_key_hash) VALUES ('proj-2', 'Project 2', 'Test', 'key-2', 'hash2')`)
+
+	var errorLogs []*models.Log
+	for i := 0; i < 7; i++ {
+		log := &models.Log{ProjectID: "proj-1", Level: models.LogLevelError, Message: fmt.Sprintf("error %d", i)}
+		repo.Create(log)
+		errorLogs = append(errorLogs, log)
+	}
+	info := &models.Log{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "info"}
+	repo.Create(info)
+	other := &models.Log{ProjectID: "proj-2", Level: models.LogLevelError, Message: "other project"}
+	repo.Create(other)
+
+	filter := &models.LogFilter{ProjectIDs: []string{"proj-1"}, Levels: []models.LogLevel{models.LogLevelError}}
+
+	// A batch size below the match count takes several UPDATEs
+	updated, err := repo.UpdateStatusByFilter(filter, models.LogStatusAcknowledged, "user-1", 3)
+	if err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if updated != 7 {
+		t.Errorf("Expected 7 logs updated, got %d", updated)
+	}
+
+	for _, log := range errorLogs {
+		found, _ := repo.GetByID(log.ID)
+		if found.Status != models.LogStatusAcknowledged || found.AcknowledgedBy != "user-1" || found.AcknowledgedAt == nil {
+			t.Errorf("Expected %s acknowledged by user-1, got %s by %q", log.Message, found.Status, found.AcknowledgedBy)
+		}
+	}
+	for _, log := range []*models.Log{info, other} {
+		if found, _ := repo.GetByID(log.ID); found.Status != models.LogStatusNew || found.AcknowledgedAt != nil {
+			t.Errorf("Expected %s to stay new, got %s", log.Message, found.Status)
+		}
+	}
+
+	// Logs that already have the status are left alone
+	updated, err = repo.UpdateStatusByFilter(filter, models.LogStatusAcknowledged, "user-2", 3)
+	if err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if updated != 0 {
+		t.Errorf("Expected nothing left to update, got %d", updated)
+	}
+	if found, _ := repo.GetByID(errorLogs[0].ID); found.AcknowledgedBy != "user-1" {
+		t.Errorf("Expected the first acknowledgement to be kept, got %q", found.AcknowledgedBy)
+	}
+
+	count, _ := repo.Count(&models.LogFilter{Status: models.LogStatusNew})
+	if count != 2 {
+		t.Errorf("Expected 2 new logs, got %d", count)
+	}
+}
+
 func TestLogRepository_List_WithFilter(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
@@ -301,6 +413,43 @@ func TestLogRepository_List_Pagination(t *testing.T) {
 	}
 }
 
+func TestLogRepository_List_SkipMetadata(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+	log := &models.Log{
+		ProjectID: "proj-1",
+		Level:     models.LogLevelInfo,
+		Message:   "Log message",
+		Metadata:  map[string]interface{}{"user_id": 42},
+	}
+	if err := repo.Create(log); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	results, _, err := repo.List(&models.LogFilter{})
+	if err != nil || len(results) != 1 || results[0].Metadata["user_id"] != float64(42) {
+		t.Fatalf("Expected the metadata by default, got %v (%v)", results, err)
+	}
+
+	// Metadata that can't be decoded shows whether it is read at all
+	if _, err := db.Exec(`UPDATE logs SET metadata = 'not json' WHERE id = ?`, log.ID); err != nil {
+		t.Fatalf("Failed to corrupt metadata: %v", err)
+	}
+	if _, _, err := repo.List(&models.LogFilter{}); err == nil {
+		t.Fatal("Expected decoding the corrupt metadata to fail")
+	}
+
+	results, total, err := repo.List(&models.LogFilter{SkipMetadata: true})
+	if err != nil {
+		t.Fatalf("Expected the metadata to be skipped, got %v", err)
+	}
+	if total != 1 || len(results) != 1 || results[0].Message != "Log message" || results[0].Metadata != nil {
+		t.Errorf("Unexpected logs without metadata: %+v", results)
+	}
+}
+
 func TestLogLevel_Priority(t *testing.T) {
 	tests := []struct {
 		level    models.LogLevel
@@ -341,6 +490,52 @@ func TestParseLogLevel(t *testing.T) {
 	}
 }
 
+func TestLog_Fingerprint(t *testing.T) {
+	base := &models.Log{ProjectID: "proj-1", Source: "billing", Level: models.LogLevelError, Message: "timeout after 532ms"}
+	fp := base.Fingerprint()
+
+	same := []*models.Log{
+		{ProjectID: "proj-1", Source: "billing", Level: models.LogLevelError, Message: "timeout after 611ms"},
+		{ProjectID: "proj-1", Source: "billing", Level: models.LogLevelCritical, Message: "timeout after 532ms"},
+	}
+	for _, l := range same {
+		if got := l.Fingerprint(); got != fp {
+			t.Errorf("Expected %q to share the fingerprint of %q", l.Message, base.Message)
+		}
+	}
+
+	different := []*models.Log{
+		{ProjectID: "proj-2", Source: "billing", Message: "timeout after 532ms"},
+		{ProjectID: "proj-1", Source: "api", Message: "timeout after 532ms"},
+		{ProjectID: "proj-1", Source: "billing", Message: "timeout after 532s"},
+	}
+	for _, l := range different {
+		if l.Fingerprint() == fp {
+			t.Errorf("Expected %+v to have its own fingerprint", l)
+		}
+	}
+}
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"timeout after 532ms", "timeout after <n>ms"},
+		{"order 8f14e45f-ceea-467f-a0e6-a1bd16f4b3a2 failed", "order <uuid> failed"},
+		{"commit 3f2a9c81d4e7 not found", "commit <hex> not found"},
+		{"decoded deadbeef payload", "decoded deadbeef payload"},
+		{"retry 3 of 5 took 1.25s", "retry <n> of <n> took <n>s"},
+		{"panic: nil map\n\tat handler.go:42\n", "panic: nil map"},
+		{"  spaced   out\tmessage ", "spaced out message"},
+	}
+	for _, tt := range tests {
+		if got := models.NormalizeMessage(tt.message); got != tt.want {
+			t.Errorf("NormalizeMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
 func TestLog_WithMetadata(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
@@ -414,6 +609,34 @@ func TestLogFilter_TimeRange(t *testing.T) {
 	_ = results
 }
 
+func TestLogQueryBounds_Apply(t *testing.T) {
+	bounds := models.LogQueryBounds{DefaultRange: time.Hour, MaxLimit: 100}
+
+	filter := &models.LogFilter{Limit: 500}
+	bounds.Apply(filter)
+	if filter.StartTime == nil || time.Since(*filter.StartTime) < 59*time.Minute {
+		t.Errorf("Expected a start time an hour ago, got %v", filter.StartTime)
+	}
+	if filter.Limit != 100 {
+		t.Errorf("Expected limit clamped to 100, got %d", filter.Limit)
+	}
+
+	// An explicit end time alone keeps the range open-ended
+	end := time.Now()
+	filter = &models.LogFilter{EndTime: &end, Limit: 20}
+	bounds.Apply(filter)
+	if filter.StartTime != nil || filter.Limit != 20 {
+		t.Errorf("Expected filter left alone, got start %v limit %d", filter.StartTime, filter.Limit)
+	}
+
+	// Zero bounds change nothing
+	filter = &models.LogFilter{Limit: 5000}
+	models.LogQueryBounds{}.Apply(filter)
+	if filter.StartTime != nil || filter.Limit != 5000 {
+		t.Errorf("Expected zero bounds to be a no-op, got start %v limit %d", filter.StartTime, filter.Limit)
+	}
+}
+
 func TestLogRepository_GetStats(t *testing.T) {
 	db := setupLogTestDB(t)
 	defer db.Close()
@@ -509,3 +732,594 @@ func TestLogRepository_CountByProject(t *testing.T) {
 		t.Errorf("Expected 5, got %d", count)
 	}
 }
+
+func TestStartOfDay(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")          // UTC+7
+	newYork, _ := time.LoadLocation("America/New_York")      // UTC-5 in January
+	kiritimati, _ := time.LoadLocation("Pacific/Kiritimati") // UTC+14
+
+	tests := []struct {
+		name string
+		now  time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{"nil means UTC", time.Date(2025, 1, 15, 23, 59, 0, 0, time.UTC), nil, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"ahead of UTC, just before local midnight", time.Date(2025, 1, 15, 16, 59, 0, 0, time.UTC), jakarta, time.Date(2025, 1, 15, 0, 0, 0, 0, jakarta)},
+		{"ahead of UTC, just after local midnight", time.Date(2025, 1, 15, 17, 1, 0, 0, time.UTC), jakarta, time.Date(2025, 1, 16, 0, 0, 0, 0, jakarta)},
+		{"behind UTC, already tomorrow in UTC", time.Date(2025, 1, 16, 4, 30, 0, 0, time.UTC), newYork, time.Date(2025, 1, 15, 0, 0, 0, 0, newYork)},
+		{"behind UTC, just after local midnight", time.Date(2025, 1, 16, 5, 1, 0, 0, time.UTC), newYork, time.Date(2025, 1, 16, 0, 0, 0, 0, newYork)},
+		{"fourteen hours ahead", time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), kiritimati, time.Date(2025, 1, 16, 0, 0, 0, 0, kiritimati)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.StartOfDay(tt.now, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("StartOfDay(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogRepository_CountToday_TimeZones(t *testing.T) {
+	for _, zone := range []string{"UTC", "Asia/Jakarta", "America/New_York", "Pacific/Kiritimati"} {
+		t.Run(zone, func(t *testing.T) {
+			db := setupLogTestDB(t)
+			defer db.Close()
+
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				t.Fatalf("Failed to load %s: %v", zone, err)
+			}
+			midnight := models.StartOfDay(time.Now(), loc)
+
+			// Stored the way Create stores them, in the server's zone
+			for i, createdAt := range []time.Time{midnight.Add(-time.Minute), midnight.Add(time.Minute)} {
+				_, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, timestamp, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+					fmt.Sprintf("log-%d", i), "proj-1", models.LogLevelInfo, "Test", createdAt.Local(), createdAt.Local())
+				if err != nil {
+					t.Fatalf("Failed to insert log: %v", err)
+				}
+			}
+
+			count, err := models.NewLogRepository(db).CountToday([]string{"proj-1"}, loc)
+			if err != nil {
+				t.Fatalf("CountToday failed: %v", err)
+			}
+			if count != 1 {
+				t.Errorf("Expected only the log after %s midnight, got %d", zone, count)
+			}
+		})
+	}
+}
+
+func TestLogRepository_Facets(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	db.Exec(`INSERT INTO projects (id, name, description, api_key, api_key_hash) VALUES ('proj-2', 'Project 2', 'Test', 'key-2', 'hash2')`)
+
+	repo := models.NewLogRepository(db)
+
+	logs := []*models.Log{
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "db timeout", Source: "api"},
+		{ProjectID: "proj-1", Level: models.LogLevelError, Message: "db timeout", Source: "api"},
+		{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "started", Source: "worker"},
+		{ProjectID: "proj-2", Level: models.LogLevelError, Message: "db timeout"},
+	}
+	for _, log := range logs {
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	filter := &models.LogFilter{Search: "timeout"}
+
+	byProject, err := repo.FacetByProject(filter)
+	if err != nil {
+		t.Fatalf("FacetByProject failed: %v", err)
+	}
+	if len(byProject) != 2 || byProject[0].Value != "proj-1" || byProject[0].Count != 2 || byProject[1].Label != "Project 2" {
+		t.Errorf("Unexpected project facets: %+v", byProject)
+	}
+
+	byLevel, err := repo.FacetByLevel(filter)
+	if err != nil {
+		t.Fatalf("FacetByLevel failed: %v", err)
+	}
+	if len(byLevel) != 1 || byLevel[0].Value != string(models.LogLevelError) || byLevel[0].Count != 3 {
+		t.Errorf("Unexpected level facets: %+v", byLevel)
+	}
+
+	bySource, err := repo.FacetBySource(filter)
+	if err != nil {
+		t.Fatalf("FacetBySource failed: %v", err)
+	}
+	if len(bySource) != 2 || bySource[0].Value != "api" || bySource[0].Count != 2 || bySource[1].Value != "" {
+		t.Errorf("Unexpected source facets: %+v", bySource)
+	}
+}
+
+func TestLogFilter_TimeField(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	// A backfilled log: event time is a week ago but it was stored just now
+	backfilled := &models.Log{
+		ProjectID: "proj-1",
+		Level:     models.LogLevelInfo,
+		Message:   "Backfilled",
+		Timestamp: time.Now().Add(-7 * 24 * time.Hour),
+	}
+	if err := repo.Create(backfilled); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	lastHour := time.Now().Add(-time.Hour)
+
+	// Default filters on the event timestamp
+	_, total, err := repo.List(&models.LogFilter{StartTime: &lastHour})
+	if err != nil {
+		t.Fatalf("Failed to list logs: %v", err)
+	}
+	if total != 0 {
+		t.Errorf("Expected backfilled log outside the last hour by timestamp, got %d", total)
+	}
+
+	lastWeek := time.Now().Add(-8 * 24 * time.Hour)
+	twoDaysAgo := time.Now().Add(-2 * 24 * time.Hour)
+	_, total, err = repo.List(&models.LogFilter{StartTime: &lastWeek, EndTime: &twoDaysAgo})
+	if err != nil {
+		t.Fatalf("Failed to list logs: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("Expected backfilled log in its event time range, got %d", total)
+	}
+
+	// created_at matches on ingestion time instead
+	_, total, err = repo.List(&models.LogFilter{StartTime: &lastHour, TimeField: models.LogTimeFieldCreatedAt})
+	if err != nil {
+		t.Fatalf("Failed to list logs: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("Expected backfilled log to match by created_at, got %d", total)
+	}
+
+	// Volume counters stay on ingestion time
+	count, err := repo.CountToday([]string{"proj-1"}, nil)
+	if err != nil {
+		t.Fatalf("CountToday failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected CountToday to count by created_at, got %d", count)
+	}
+}
+
+func TestLogRepository_GetContext(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		INSERT INTO projects (id, name, description, api_key, api_key_hash)
+		VALUES ('proj-2', 'Other Project', 'Other', 'other-key', 'hash')
+	`); err != nil {
+		t.Fatalf("Failed to create second project: %v", err)
+	}
+
+	repo := models.NewLogRepository(db)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var logs []*models.Log
+	for i := 0; i < 7; i++ {
+		logs = append(logs, &models.Log{
+			ProjectID: "proj-1",
+			Level:     models.LogLevelInfo,
+			Message:   fmt.Sprintf("log %d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		})
+	}
+	// Interleaved log from another project must never appear
+	logs = append(logs, &models.Log{ProjectID: "proj-2", Level: models.LogLevelError, Message: "other", Timestamp: base.Add(3 * time.Second)})
+	if err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+
+	target := logs[3]
+	before, after, err := repo.GetContext(target, 2, 10)
+	if err != nil {
+		t.Fatalf("GetContext failed: %v", err)
+	}
+
+	messages := func(logs []*models.Log) []string {
+		var out []string
+		for _, l := range logs {
+			out = append(out, l.Message)
+		}
+		return out
+	}
+
+	if got := messages(before); len(got) != 2 || got[0] != "log 1" || got[1] != "log 2" {
+		t.Errorf("Expected [log 1 log 2] before, got %v", got)
+	}
+	if got := messages(after); len(got) != 3 || got[0] != "log 4" || got[2] != "log 6" {
+		t.Errorf("Expected [log 4 log 5 log 6] after, got %v", got)
+	}
+
+	// The first log has nothing before it
+	before, _, err = repo.GetContext(logs[0], 5, 0)
+	if err != nil {
+		t.Fatalf("GetContext failed: %v", err)
+	}
+	if len(before) != 0 {
+		t.Errorf("Expected no logs before the first log, got %v", messages(before))
+	}
+}
+
+func TestLogRepository_GetStatsForProjects(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	seed := []struct {
+		projectID string
+		level     models.LogLevel
+		count     int
+	}{
+		{"proj-1", models.LogLevelInfo, 3},
+		{"proj-1", models.LogLevelError, 2},
+		{"proj-2", models.LogLevelWarn, 1},
+		{"proj-3", models.LogLevelDebug, 4},
+	}
+	for _, s := range seed {
+		for i := 0; i < s.count; i++ {
+			if err := repo.Create(&models.Log{ProjectID: s.projectID, Level: s.level, Message: "msg"}); err != nil {
+				t.Fatalf("Failed to create log: %v", err)
+			}
+		}
+	}
+
+	stats, err := repo.GetStatsForProjects([]string{"proj-1", "proj-2", "proj-empty"})
+	if err != nil {
+		t.Fatalf("GetStatsForProjects failed: %v", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("Expected stats for 2 projects, got %d", len(stats))
+	}
+	if stats["proj-1"].Total != 5 {
+		t.Errorf("Expected 5 logs for proj-1, got %d", stats["proj-1"].Total)
+	}
+	if stats["proj-1"].ByLevel[models.LogLevelInfo] != 3 || stats["proj-1"].ByLevel[models.LogLevelError] != 2 {
+		t.Errorf("Unexpected level counts for proj-1: %v", stats["proj-1"].ByLevel)
+	}
+	if stats["proj-2"].ByLevel[models.LogLevelWarn] != 1 {
+		t.Errorf("Expected 1 warn log for proj-2, got %v", stats["proj-2"].ByLevel)
+	}
+	if _, ok := stats["proj-3"]; ok {
+		t.Error("proj-3 was not requested and should not be counted")
+	}
+
+	empty, err := repo.GetStatsForProjects(nil)
+	if err != nil {
+		t.Fatalf("GetStatsForProjects(nil) failed: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("Expected no stats without project IDs, got %d", len(empty))
+	}
+}
+
+// seedStatsBenchmark creates 100 projects with logs spread over every level
+func seedStatsBenchmark(b *testing.B) (*models.LogRepository, []string) {
+	db := setupLogTestDB(b)
+	b.Cleanup(func() { db.Close() })
+
+	repo := models.NewLogRepository(db)
+	levels := []models.LogLevel{models.LogLevelDebug, models.LogLevelInfo, models.LogLevelWarn, models.LogLevelError, models.LogLevelCritical}
+
+	projectIDs := make([]string, 100)
+	var batch []*models.Log
+	for i := range projectIDs {
+		projectIDs[i] = fmt.Sprintf("bench-%03d", i)
+		for j := 0; j < 20; j++ {
+			batch = append(batch, &models.Log{ProjectID: projectIDs[i], Level: levels[j%len(levels)], Message: "msg"})
+		}
+	}
+	if err := repo.CreateBatch(batch); err != nil {
+		b.Fatalf("Failed to seed logs: %v", err)
+	}
+
+	return repo, projectIDs
+}
+
+// BenchmarkStatsOverview_PerProject is the per-project, per-level loop the
+// overview stats used before GetStatsForProjects
+func BenchmarkStatsOverview_PerProject(b *testing.B) {
+	repo, projectIDs := seedStatsBenchmark(b)
+	levels := []models.LogLevel{models.LogLevelDebug, models.LogLevelInfo, models.LogLevelWarn, models.LogLevelError}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, id := range projectIDs {
+			if _, err := repo.CountByProject(id); err != nil {
+				b.Fatal(err)
+			}
+			for _, level := range levels {
+				if _, err := repo.CountByProjectAndLevel(id, level); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkStatsOverview_Grouped(b *testing.B) {
+	repo, projectIDs := seedStatsBenchmark(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetStatsForProjects(projectIDs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLogRepository_DistinctSources(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	for _, l := range []struct{ projectID, source string }{
+		{"proj-1", "worker"},
+		{"proj-1", "api"},
+		{"proj-1", "api"},
+		{"proj-1", ""},
+		{"proj-2", "cron"},
+	} {
+		if err := repo.Create(&models.Log{ProjectID: l.projectID, Level: models.LogLevelInfo, Message: "msg", Source: l.source}); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	sources, err := repo.DistinctSources("proj-1")
+	if err != nil {
+		t.Fatalf("DistinctSources failed: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %v", sources)
+	}
+	if sources[0].Value != "api" || sources[0].Count != 2 || sources[1].Value != "worker" {
+		t.Errorf("Expected api before worker by frequency, got %v", sources)
+	}
+
+	all, err := repo.DistinctSourcesForProjects(nil)
+	if err != nil {
+		t.Fatalf("DistinctSourcesForProjects failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 sources across projects, got %v", all)
+	}
+
+	none, err := repo.DistinctSourcesForProjects([]string{})
+	if err != nil {
+		t.Fatalf("DistinctSourcesForProjects failed: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("Expected no sources for an empty project list, got %v", none)
+	}
+}
+
+func TestLogRepository_MetadataKeys(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, metadata := range []map[string]interface{}{
+		{"user_id": "u1", "request": map[string]interface{}{"path": "/", "method": "GET"}},
+		{"user_id": "u2", "duration_ms": 12},
+		{"user_id": "u3"},
+		nil,
+		{"legacy": true}, // Oldest, outside a sample of 4
+	} {
+		l := &models.Log{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: "msg", Metadata: metadata, Timestamp: now.Add(-time.Duration(i) * time.Minute)}
+		if err := repo.Create(l); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+	// Other projects' keys and unreadable metadata are left out
+	if err := repo.Create(&models.Log{ProjectID: "proj-2", Level: models.LogLevelInfo, Message: "msg", Metadata: map[string]interface{}{"tenant": "t1"}, Timestamp: now}); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO logs (id, project_id, level, message, metadata, timestamp) VALUES ('corrupt', 'proj-1', 'INFO', 'msg', '{not json', ?)`, now.Add(-30*time.Second)); err != nil {
+		t.Fatalf("Failed to insert log: %v", err)
+	}
+
+	sample, err := repo.MetadataKeys("proj-1", 5)
+	if err != nil {
+		t.Fatalf("MetadataKeys failed: %v", err)
+	}
+	if sample.Sampled != 5 {
+		t.Errorf("Expected 5 logs sampled, got %d", sample.Sampled)
+	}
+	if sample.Since == nil || !sample.Since.Equal(now.Add(-3*time.Minute)) {
+		t.Errorf("Expected the sample to reach back to the fourth log, got %v", sample.Since)
+	}
+	want := []models.MetadataKeyCount{
+		{Key: "user_id", Count: 3, Frequency: 0.6},
+		{Key: "duration_ms", Count: 1, Frequency: 0.2},
+		{Key: "request", Count: 1, Frequency: 0.2},
+	}
+	if len(sample.Keys) != len(want) {
+		t.Fatalf("Expected keys %v, got %v", want, sample.Keys)
+	}
+	for i := range want {
+		if sample.Keys[i] != want[i] {
+			t.Errorf("Key %d: expected %+v, got %+v", i, want[i], sample.Keys[i])
+		}
+	}
+
+	all, err := repo.MetadataKeys("proj-1", 100)
+	if err != nil {
+		t.Fatalf("MetadataKeys failed: %v", err)
+	}
+	if all.Sampled != 6 || len(all.Keys) != 4 || all.Keys[2].Key != "legacy" {
+		t.Errorf("Expected every log sampled including the legacy key, got %+v", all)
+	}
+
+	empty, err := repo.MetadataKeys("proj-3", 100)
+	if err != nil {
+		t.Fatalf("MetadataKeys failed: %v", err)
+	}
+	if empty.Sampled != 0 || empty.Since != nil || len(empty.Keys) != 0 {
+		t.Errorf("Expected nothing for a project without logs, got %+v", empty)
+	}
+}
+
+func TestLogRepository_GetRecentByMinLevel(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	if _, err := db.Exec(`INSERT INTO projects (id, name, description, api_key, api_key_hash) VALUES ('proj-2', 'Other Project', '', 'other-key', 'hash2')`); err != nil {
+		t.Fatalf("Failed to create second project: %v", err)
+	}
+
+	now := time.Now()
+	for i, l := range []struct {
+		projectID string
+		level     models.LogLevel
+	}{
+		{"proj-1", models.LogLevelError},
+		{"proj-1", models.LogLevelInfo},
+		{"proj-2", models.LogLevelCritical},
+		{"proj-1", models.LogLevelWarn},
+		{"proj-1", models.LogLevelError},
+	} {
+		log := &models.Log{ProjectID: l.projectID, Level: l.level, Message: "msg", Timestamp: now.Add(time.Duration(i) * time.Second)}
+		if err := repo.Create(log); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	all, err := repo.GetRecentByMinLevel(nil, models.LogLevelError, 10)
+	if err != nil {
+		t.Fatalf("GetRecentByMinLevel failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 logs at ERROR or above, got %d", len(all))
+	}
+	if all[0].Level != models.LogLevelError || all[1].Level != models.LogLevelCritical {
+		t.Errorf("Expected newest first, got %s then %s", all[0].Level, all[1].Level)
+	}
+	if all[1].ProjectName == "" {
+		t.Error("Expected project name to be populated")
+	}
+
+	limited, _ := repo.GetRecentByMinLevel([]string{"proj-1"}, models.LogLevelError, 1)
+	if len(limited) != 1 || limited[0].ProjectID != "proj-1" {
+		t.Errorf("Expected one proj-1 log, got %v", limited)
+	}
+
+	none, _ := repo.GetRecentByMinLevel([]string{}, models.LogLevelError, 10)
+	if len(none) != 0 {
+		t.Errorf("Expected no logs for an empty project list, got %d", len(none))
+	}
+}
+
+func TestLogRepository_List_SearchTermsAndCase(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	for _, msg := range []string{
+		"Connection refused by DB host",
+		"db connection pool exhausted",
+		"Refused connection from client",
+		"Cache warmed",
+	} {
+		if err := repo.Create(&models.Log{ProjectID: "proj-1", Level: models.LogLevelInfo, Message: msg}); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   int
+	}{
+		{"CONNECTION", 3},
+		{"connection refused", 2},
+		{`"connection refused"`, 1},
+		{`db "Connection Pool"`, 1},
+		{"connection missing", 0},
+	}
+
+	for _, tt := range tests {
+		_, total, err := repo.List(&models.LogFilter{Search: tt.search})
+		if err != nil {
+			t.Fatalf("Failed to list logs for %q: %v", tt.search, err)
+		}
+		if total != tt.want {
+			t.Errorf("Search %q matched %d logs, want %d", tt.search, total, tt.want)
+		}
+	}
+}
+
+func TestLogRepository_Walk(t *testing.T) {
+	db := setupLogTestDB(t)
+	defer db.Close()
+
+	repo := models.NewLogRepository(db)
+
+	// Pairs of logs share a timestamp, so batches have to break ties by id
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var logs []*models.Log
+	for i := 0; i < 7; i++ {
+		logs = append(logs, &models.Log{
+			ProjectID: "proj-1",
+			Level:     models.LogLevelInfo,
+			Message:   fmt.Sprintf("Log %d", i),
+			Timestamp: base.Add(time.Duration(i/2) * time.Minute),
+		})
+	}
+	if err := repo.CreateBatch(logs); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var last time.Time
+	err := repo.Walk(&models.LogFilter{ProjectIDs: []string{"proj-1"}}, 2, func(log *models.Log) error {
+		if seen[log.ID] {
+			t.Errorf("Log %s visited twice", log.ID)
+		}
+		seen[log.ID] = true
+		if log.Timestamp.Before(last) {
+			t.Errorf("Expected oldest first, got %v after %v", log.Timestamp, last)
+		}
+		last = log.Timestamp
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if len(seen) != 7 {
+		t.Errorf("Expected 7 logs, visited %d", len(seen))
+	}
+
+	// An error from fn stops the walk
+	stop := fmt.Errorf("stop")
+	visited := 0
+	err = repo.Walk(&models.LogFilter{ProjectIDs: []string{"proj-1"}}, 2, func(*models.Log) error {
+		visited++
+		return stop
+	})
+	if err != stop || visited != 1 {
+		t.Errorf("Expected the walk to stop after the first log, got %v after %d", err, visited)
+	}
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildWhere(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	startJakarta := start.In(time.FixedZone("WIB", 7*3600))
+
+	tests := []struct {
+		name      string
+		filter    *LogFilter
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "empty filter",
+			filter:    &LogFilter{},
+			wantWhere: liveProjectsClause,
+			wantArgs:  []interface{}{},
+		},
+		{
+			name:      "projects and levels",
+			filter:    &LogFilter{ProjectIDs: []string{"a", "b"}, Levels: []LogLevel{LogLevelError, LogLevelCritical}},
+			wantWhere: liveProjectsClause + " AND l.project_id IN (?,?) AND l.level IN (?,?)",
+			wantArgs:  []interface{}{"a", "b", LogLevelError, LogLevelCritical},
+		},
+		{
+			name:      "source and search",
+			filter:    &LogFilter{Source: "api", Search: "timeout"},
+			wantWhere: liveProjectsClause + " AND l.source = ? AND LOWER(l.message) LIKE ?",
+			wantArgs:  []interface{}{"api", "%timeout%"},
+		},
+		{
+			name:      "search terms must all match",
+			filter:    &LogFilter{Search: `DB "Connection Refused" retry`},
+			wantWhere: liveProjectsClause + " AND LOWER(l.message) LIKE ? AND LOWER(l.message) LIKE ? AND LOWER(l.message) LIKE ?",
+			wantArgs:  []interface{}{"%db%", "%connection refused%", "%retry%"},
+		},
+		{
+			name:      "time range defaults to timestamp",
+			filter:    &LogFilter{StartTime: &start, EndTime: &end},
+			wantWhere: liveProjectsClause + " AND l.timestamp >= ? AND l.timestamp <= ?",
+			wantArgs:  []interface{}{start, end},
+		},
+		{
+			name:      "time range on created_at",
+			filter:    &LogFilter{StartTime: &start, TimeField: LogTimeFieldCreatedAt},
+			wantWhere: liveProjectsClause + " AND l.created_at >= ?",
+			wantArgs:  []interface{}{start},
+		},
+		{
+			name:      "time range bounds are converted to UTC",
+			filter:    &LogFilter{StartTime: &startJakarta},
+			wantWhere: liveProjectsClause + " AND l.timestamp >= ?",
+			wantArgs:  []interface{}{start},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := buildWhere(tt.filter)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"timeout", []string{"timeout"}},
+		{"  disk   full ", []string{"disk", "full"}},
+		{`"connection refused"`, []string{"connection refused"}},
+		{`db "connection refused" retry`, []string{"db", "connection refused", "retry"}},
+		{`user"quoted phrase"`, []string{"user", "quoted phrase"}},
+		{`"unclosed phrase`, []string{"unclosed phrase"}},
+		{`""`, nil},
+	}
+
+	for _, tt := range tests {
+		if got := searchTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
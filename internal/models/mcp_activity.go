@@ -17,6 +17,7 @@ type MCPActivityLog struct {
 	Success       bool       `json:"success"`
 	ErrorMessage  string     `json:"error_message,omitempty"`
 	DurationMS    int        `json:"duration_ms"`
+	RequestID     string     `json:"request_id,omitempty"`
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
@@ -34,9 +35,9 @@ func (r *MCPActivityLogRepository) Create(log *MCPActivityLog) error {
 	log.CreatedAt = time.Now()
 
 	_, err := r.db.Exec(`
-		INSERT INTO mcp_activity_logs (id, token_id, tool_name, project_ids, request_params, success, error_message, duration_ms, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, log.ID, log.TokenID, log.ToolName, log.ProjectIDs, log.RequestParams, log.Success, log.ErrorMessage, log.DurationMS, log.CreatedAt)
+		INSERT INTO mcp_activity_logs (id, token_id, tool_name, project_ids, request_params, success, error_message, duration_ms, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, log.ID, log.TokenID, log.ToolName, log.ProjectIDs, log.RequestParams, log.Success, log.ErrorMessage, log.DurationMS, log.RequestID, log.CreatedAt)
 
 	return err
 }
@@ -52,7 +53,7 @@ func (r *MCPActivityLogRepository) GetByTokenID(tokenID string, limit, offset in
 
 	// Get logs with pagination
 	rows, err := r.db.Query(`
-		SELECT id, token_id, tool_name, project_ids, request_params, success, error_message, duration_ms, created_at
+		SELECT id, token_id, tool_name, project_ids, request_params, success, error_message, duration_ms, request_id, created_at
 		FROM mcp_activity_logs
 		WHERE token_id = ?
 		ORDER BY created_at DESC
@@ -69,8 +70,9 @@ func (r *MCPActivityLogRepository) GetByTokenID(tokenID string, limit, offset in
 		var projectIDs sql.NullString
 		var requestParams sql.NullString
 		var errorMessage sql.NullString
+		var requestID sql.NullString
 
-		err := rows.Scan(&log.ID, &log.TokenID, &log.ToolName, &projectIDs, &requestParams, &log.Success, &errorMessage, &log.DurationMS, &log.CreatedAt)
+		err := rows.Scan(&log.ID, &log.TokenID, &log.ToolName, &projectIDs, &requestParams, &log.Success, &errorMessage, &log.DurationMS, &requestID, &log.CreatedAt)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -84,6 +86,7 @@ func (r *MCPActivityLogRepository) GetByTokenID(tokenID string, limit, offset in
 		if errorMessage.Valid {
 			log.ErrorMessage = errorMessage.String
 		}
+		log.RequestID = requestID.String
 
 		logs = append(logs, log)
 	}
@@ -94,7 +97,7 @@ func (r *MCPActivityLogRepository) GetByTokenID(tokenID string, limit, offset in
 // GetRecent retrieves the most recent activity logs across all tokens
 func (r *MCPActivityLogRepository) GetRecent(limit int) ([]*MCPActivityLog, error) {
 	rows, err := r.db.Query(`
-		SELECT id, token_id, tool_name, project_ids, request_params, success, error_message, duration_ms, created_at
+		SELECT id, token_id, tool_name, project_ids, request_params, success, error_message, duration_ms, request_id, created_at
 		FROM mcp_activity_logs
 		ORDER BY created_at DESC
 		LIMIT ?
@@ -110,8 +113,9 @@ func (r *MCPActivityLogRepository) GetRecent(limit int) ([]*MCPActivityLog, erro
 		var projectIDs sql.NullString
 		var requestParams sql.NullString
 		var errorMessage sql.NullString
+		var requestID sql.NullString
 
-		err := rows.Scan(&log.ID, &log.TokenID, &log.ToolName, &projectIDs, &requestParams, &log.Success, &errorMessage, &log.DurationMS, &log.CreatedAt)
+		err := rows.Scan(&log.ID, &log.TokenID, &log.ToolName, &projectIDs, &requestParams, &log.Success, &errorMessage, &log.DurationMS, &requestID, &log.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -125,6 +129,7 @@ func (r *MCPActivityLogRepository) GetRecent(limit int) ([]*MCPActivityLog, erro
 		if errorMessage.Valid {
 			log.ErrorMessage = errorMessage.String
 		}
+		log.RequestID = requestID.String
 
 		logs = append(logs, log)
 	}
@@ -18,6 +18,7 @@ type MCPToken struct {
 	TokenHash       string     `json:"-"`
 	TokenPrefix     string     `json:"token_prefix"`
 	GrantedProjects string     `json:"granted_projects"` // JSON array of project IDs or "*"
+	Scope           MCPScope   `json:"scope"`
 	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
 	IsActive        bool       `json:"is_active"`
 	CreatedBy       string     `json:"created_by"`
@@ -26,6 +27,21 @@ type MCPToken struct {
 	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
+// MCPScope is what a token may do in its granted projects
+type MCPScope string
+
+const (
+	// MCPScopeRead allows the query tools only
+	MCPScopeRead MCPScope = "read"
+	// MCPScopeWrite also allows tools that change logs, such as acknowledge_log
+	MCPScopeWrite MCPScope = "write"
+)
+
+// IsValidMCPScope checks if scope is a known token scope
+func IsValidMCPScope(scope MCPScope) bool {
+	return scope == MCPScopeRead || scope == MCPScopeWrite
+}
+
 type MCPTokenRepository struct {
 	db *sql.DB
 }
@@ -72,6 +88,9 @@ func (r *MCPTokenRepository) Create(mcpToken *MCPToken) (string, error) {
 	}
 	mcpToken.TokenHash = tokenHash
 	mcpToken.TokenPrefix = tokenPrefix
+	if mcpToken.Scope == "" {
+		mcpToken.Scope = MCPScopeRead
+	}
 
 	// Handle nullable fields
 	var expiresAt interface{}
@@ -80,9 +99,9 @@ func (r *MCPTokenRepository) Create(mcpToken *MCPToken) (string, error) {
 	}
 
 	_, err = r.db.Exec(`
-		INSERT INTO mcp_tokens (id, name, token_hash, token_prefix, granted_projects, expires_at, is_active, created_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, mcpToken.ID, mcpToken.Name, mcpToken.TokenHash, mcpToken.TokenPrefix, mcpToken.GrantedProjects, expiresAt, mcpToken.IsActive, mcpToken.CreatedBy, mcpToken.CreatedAt, mcpToken.UpdatedAt)
+		INSERT INTO mcp_tokens (id, name, token_hash, token_prefix, granted_projects, scope, expires_at, is_active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, mcpToken.ID, mcpToken.Name, mcpToken.TokenHash, mcpToken.TokenPrefix, mcpToken.GrantedProjects, mcpToken.Scope, expiresAt, mcpToken.IsActive, mcpToken.CreatedBy, mcpToken.CreatedAt, mcpToken.UpdatedAt)
 
 	if err != nil {
 		return "", err
@@ -99,9 +118,9 @@ func (r *MCPTokenRepository) GetByID(id string) (*MCPToken, error) {
 	var grantedProjects sql.NullString
 
 	err := r.db.QueryRow(`
-		SELECT id, name, token_hash, token_prefix, granted_projects, expires_at, is_active, created_by, last_used_at, created_at, updated_at
+		SELECT id, name, token_hash, token_prefix, granted_projects, scope, expires_at, is_active, created_by, last_used_at, created_at, updated_at
 		FROM mcp_tokens WHERE id = ?
-	`, id).Scan(&token.ID, &token.Name, &token.TokenHash, &token.TokenPrefix, &grantedProjects, &expiresAt, &token.IsActive, &token.CreatedBy, &lastUsedAt, &token.CreatedAt, &token.UpdatedAt)
+	`, id).Scan(&token.ID, &token.Name, &token.TokenHash, &token.TokenPrefix, &grantedProjects, &token.Scope, &expiresAt, &token.IsActive, &token.CreatedBy, &lastUsedAt, &token.CreatedAt, &token.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -133,9 +152,9 @@ func (r *MCPTokenRepository) GetByToken(rawToken string) (*MCPToken, error) {
 	var grantedProjects sql.NullString
 
 	err := r.db.QueryRow(`
-		SELECT id, name, token_hash, token_prefix, granted_projects, expires_at, is_active, created_by, last_used_at, created_at, updated_at
-		FROM mcp_tokens WHERE token_hash = ? AND is_active = 1
-	`, hashedToken).Scan(&token.ID, &token.Name, &token.TokenHash, &token.TokenPrefix, &grantedProjects, &expiresAt, &token.IsActive, &token.CreatedBy, &lastUsedAt, &token.CreatedAt, &token.UpdatedAt)
+		SELECT id, name, token_hash, token_prefix, granted_projects, scope, expires_at, is_active, created_by, last_used_at, created_at, updated_at
+		FROM mcp_tokens WHERE token_hash = ? AND is_active = ?
+	`, hashedToken, true).Scan(&token.ID, &token.Name, &token.TokenHash, &token.TokenPrefix, &grantedProjects, &token.Scope, &expiresAt, &token.IsActive, &token.CreatedBy, &lastUsedAt, &token.CreatedAt, &token.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -169,7 +188,7 @@ func (r *MCPTokenRepository) GetByToken(rawToken string) (*MCPToken, error) {
 // GetAll retrieves all tokens
 func (r *MCPTokenRepository) GetAll() ([]*MCPToken, error) {
 	rows, err := r.db.Query(`
-		SELECT id, name, token_hash, token_prefix, granted_projects, expires_at, is_active, created_by, last_used_at, created_at, updated_at
+		SELECT id, name, token_hash, token_prefix, granted_projects, scope, expires_at, is_active, created_by, last_used_at, created_at, updated_at
 		FROM mcp_tokens ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -184,7 +203,7 @@ func (r *MCPTokenRepository) GetAll() ([]*MCPToken, error) {
 		var lastUsedAt sql.NullTime
 		var grantedProjects sql.NullString
 
-		err := rows.Scan(&token.ID, &token.Name, &token.TokenHash, &token.TokenPrefix, &grantedProjects, &expiresAt, &token.IsActive, &token.CreatedBy, &lastUsedAt, &token.CreatedAt, &token.UpdatedAt)
+		err := rows.Scan(&token.ID, &token.Name, &token.TokenHash, &token.TokenPrefix, &grantedProjects, &token.Scope, &expiresAt, &token.IsActive, &token.CreatedBy, &lastUsedAt, &token.CreatedAt, &token.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -205,7 +224,7 @@ func (r *MCPTokenRepository) GetAll() ([]*MCPToken, error) {
 	return tokens, nil
 }
 
-// Update updates a token's properties (name, granted_projects, expires_at, is_active)
+// Update updates a token's properties (name, granted_projects, scope, expires_at, is_active)
 func (r *MCPTokenRepository) Update(token *MCPToken) error {
 	token.UpdatedAt = time.Now()
 
@@ -216,9 +235,9 @@ func (r *MCPTokenRepository) Update(token *MCPToken) error {
 
 	_, err := r.db.Exec(`
 		UPDATE mcp_tokens
-		SET name = ?, granted_projects = ?, expires_at = ?, is_active = ?, updated_at = ?
+		SET name = ?, granted_projects = ?, scope = ?, expires_at = ?, is_active = ?, updated_at = ?
 		WHERE id = ?
-	`, token.Name, token.GrantedProjects, expiresAt, token.IsActive, token.UpdatedAt, token.ID)
+	`, token.Name, token.GrantedProjects, token.Scope, expiresAt, token.IsActive, token.UpdatedAt, token.ID)
 
 	return err
 }
@@ -275,3 +294,8 @@ func (token *MCPToken) HasAccessToProject(projectID string) (bool, error) {
 
 	return false, nil
 }
+
+// CanWrite reports whether the token may use tools that change logs
+func (token *MCPToken) CanWrite() bool {
+	return token.Scope == MCPScopeWrite
+}
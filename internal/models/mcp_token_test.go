@@ -22,6 +22,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 			token_hash TEXT NOT NULL,
 			token_prefix TEXT NOT NULL,
 			granted_projects TEXT,
+			scope TEXT NOT NULL DEFAULT 'read',
 			expires_at DATETIME,
 			is_active INTEGER NOT NULL DEFAULT 1,
 			created_by TEXT NOT NULL,
@@ -153,6 +154,19 @@ func TestMCPTokenRepository_Create(t *testing.T) {
 	if retrieved.Name != token.Name {
 		t.Errorf("Expected name %s, got %s", token.Name, retrieved.Name)
 	}
+
+	// Tokens are read-only unless created with write scope
+	if retrieved.Scope != MCPScopeRead || retrieved.CanWrite() {
+		t.Errorf("Expected a read-only token by default, got scope %q", retrieved.Scope)
+	}
+	retrieved.Scope = MCPScopeWrite
+	if err := repo.Update(retrieved); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	retrieved, _ = repo.GetByID(token.ID)
+	if !retrieved.CanWrite() {
+		t.Errorf("Expected write scope after update, got %q", retrieved.Scope)
+	}
 }
 
 func TestMCPTokenRepository_GetByToken(t *testing.T) {
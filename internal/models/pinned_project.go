@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PinnedProjectRepository stores the projects each user has pinned for
+// quick access. Pins grant nothing; callers check project access first.
+type PinnedProjectRepository struct {
+	db *sql.DB
+}
+
+func NewPinnedProjectRepository(db *sql.DB) *PinnedProjectRepository {
+	return &PinnedProjectRepository{db: db}
+}
+
+// Pin pins a project for a user; pinning it again is a no-op
+func (r *PinnedProjectRepository) Pin(userID, projectID string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO user_pinned_projects (user_id, project_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(user_id, project_id) DO NOTHING
+	`, userID, projectID, time.Now())
+	return err
+}
+
+// Unpin removes a user's pin, reporting whether there was one
+func (r *PinnedProjectRepository) Unpin(userID, projectID string) (bool, error) {
+	return rowsChanged(r.db.Exec(`DELETE FROM user_pinned_projects WHERE user_id = ? AND project_id = ?`, userID, projectID))
+}
+
+// GetProjectIDs returns the set of project IDs a user has pinned
+func (r *PinnedProjectRepository) GetProjectIDs(userID string) (map[string]bool, error) {
+	rows, err := r.db.Query(`SELECT project_id FROM user_pinned_projects WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pinned := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		pinned[id] = true
+	}
+	return pinned, rows.Err()
+}
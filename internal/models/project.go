@@ -7,6 +7,9 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,12 +21,31 @@ type Project struct {
 	Description     string           `json:"description"`
 	IconType        string           `json:"icon_type"`  // "initials", "icon", or "image"
 	IconValue       string           `json:"icon_value"` // initials text, icon name, or base64 image
+	Group           string           `json:"group"`      // Free-form label for organizing projects; empty when ungrouped
 	APIKey          string           `json:"-"`
 	APIKeyPrefix    string           `json:"api_key_prefix"`
 	IsActive        bool             `json:"is_active"`
 	RetentionConfig *RetentionConfig `json:"retention_config,omitempty"`
-	CreatedAt       time.Time        `json:"created_at"`
-	UpdatedAt       time.Time        `json:"updated_at"`
+	RedactionConfig *RedactionConfig `json:"redaction_config,omitempty"`
+	SamplingConfig  *SamplingConfig  `json:"sampling_config,omitempty"`
+	OutboundWebhook *OutboundWebhook `json:"outbound_webhook,omitempty"`
+	SigningSecret   string           `json:"-"`                        // When set, ingestion requests must carry an X-Signature
+	SigningEnabled  bool             `json:"signing_enabled"`          // Derived from SigningSecret when the project is loaded
+	MaxBodyBytes    int              `json:"max_body_bytes,omitempty"` // Raises the ingestion body limit for trusted high-volume senders; 0 uses the server's
+	// Sustained ingestion requests per minute and the burst allowed on top
+	// of a quiet period; 0 uses the server's rate_limit.api settings
+	RateLimitPerMinute int `json:"rate_limit_per_minute,omitempty"`
+	RateLimitBurst     int `json:"rate_limit_burst,omitempty"`
+	// Ingestion is refused while paused, e.g. during a compromise
+	// investigation, without rotating the key. Unlike IsActive the project
+	// stays fully usable otherwise.
+	IngestionPaused bool `json:"ingestion_paused"`
+	// Level given to logs sent without one; empty means INFO. With
+	// RequireLevel such logs are rejected instead.
+	DefaultLevel LogLevel  `json:"default_level,omitempty"`
+	RequireLevel bool      `json:"require_level"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 type RetentionConfig struct {
@@ -37,6 +59,92 @@ type LevelRetention struct {
 	MaxCount int    `json:"max_count,omitempty"`
 }
 
+// RedactionConfig replaces the server-wide redaction rules for a project
+type RedactionConfig struct {
+	Detectors []string `json:"detectors,omitempty"` // Built-in detectors: email, credit_card, ssn
+	Patterns  []string `json:"patterns,omitempty"`  // Custom regular expressions
+}
+
+// IsEmpty reports whether the config names no rules, which clears the override
+func (c *RedactionConfig) IsEmpty() bool {
+	return c == nil || (len(c.Detectors) == 0 && len(c.Patterns) == 0)
+}
+
+// SamplingConfig keeps only a fraction of a project's logs at some levels
+type SamplingConfig struct {
+	Levels map[string]float64 `json:"levels,omitempty"` // Level -> fraction kept, from 0 to 1
+}
+
+// Rate returns the fraction of logs at level to keep. Levels without a rate,
+// and projects without a config, keep everything.
+func (c *SamplingConfig) Rate(level LogLevel) float64 {
+	if c == nil {
+		return 1
+	}
+	if rate, ok := c.Levels[string(level)]; ok {
+		return rate
+	}
+	return 1
+}
+
+// Normalize checks every rate and upper-cases the level names
+func (c *SamplingConfig) Normalize() error {
+	levels := make(map[string]float64, len(c.Levels))
+	for name, rate := range c.Levels {
+		level := LogLevel(strings.ToUpper(name))
+		if level == "WARNING" {
+			level = LogLevelWarn
+		}
+		if level.Priority() < 0 {
+			return fmt.Errorf("unknown level %q", name)
+		}
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("sampling rate for %s must be between 0 and 1, got %v", level, rate)
+		}
+		levels[string(level)] = rate
+	}
+	c.Levels = levels
+	return nil
+}
+
+// OutboundWebhook posts every log a project accepts at or above MinLevel to
+// URL, for feeding the owner's own pipeline. Unlike notification channels it
+// carries the raw log and has no quiet hours or per-source rules.
+type OutboundWebhook struct {
+	URL      string   `json:"url"`
+	MinLevel LogLevel `json:"min_level,omitempty"` // Empty sends every level
+}
+
+// IsEmpty reports whether the webhook has no URL, which turns it off
+func (w *OutboundWebhook) IsEmpty() bool {
+	return w == nil || w.URL == ""
+}
+
+// Fires reports whether a log at level is sent to the webhook
+func (w *OutboundWebhook) Fires(level LogLevel) bool {
+	if w.IsEmpty() {
+		return false
+	}
+	return w.MinLevel == "" || level.Priority() >= w.MinLevel.Priority()
+}
+
+// Normalize checks the URL and upper-cases the level
+func (w *OutboundWebhook) Normalize() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("outbound_webhook url must be an http or https URL")
+	}
+	level := LogLevel(strings.ToUpper(string(w.MinLevel)))
+	if level == "WARNING" {
+		level = LogLevelWarn
+	}
+	if level != "" && level.Priority() < 0 {
+		return fmt.Errorf("unknown level %q", w.MinLevel)
+	}
+	w.MinLevel = level
+	return nil
+}
+
 type ProjectRepository struct {
 	db *sql.DB
 }
@@ -81,20 +189,15 @@ func (r *ProjectRepository) Create(project *Project) (string, error) {
 	project.APIKey = apiKeyHash
 	project.APIKeyPrefix = apiKeyPrefix
 
-	var retentionJSON *string
-	if project.RetentionConfig != nil {
-		data, err := json.Marshal(project.RetentionConfig)
-		if err != nil {
-			return "", err
-		}
-		s := string(data)
-		retentionJSON = &s
+	retentionJSON, redactionJSON, samplingJSON, webhookJSON, err := project.encodeConfigs()
+	if err != nil {
+		return "", err
 	}
 
 	_, err = r.db.Exec(`
-		INSERT INTO projects (id, name, description, icon_type, icon_value, api_key, api_key_prefix, is_active, retention_config, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, project.ID, project.Name, project.Description, project.IconType, project.IconValue, project.APIKey, project.APIKeyPrefix, project.IsActive, retentionJSON, project.CreatedAt, project.UpdatedAt)
+		INSERT INTO projects (id, name, description, icon_type, icon_value, group_name, api_key, api_key_prefix, is_active, retention_config, redaction_config, sampling_config, max_body_bytes, rate_limit_per_minute, rate_limit_burst, default_level, require_level, outbound_webhook, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, project.ID, project.Name, project.Description, project.IconType, project.IconValue, nullString(project.Group), project.APIKey, project.APIKeyPrefix, project.IsActive, retentionJSON, redactionJSON, samplingJSON, nullPositiveInt(project.MaxBodyBytes), nullPositiveInt(project.RateLimitPerMinute), nullPositiveInt(project.RateLimitBurst), nullString(string(project.DefaultLevel)), project.RequireLevel, webhookJSON, project.CreatedAt, project.UpdatedAt)
 
 	if err != nil {
 		return "", err
@@ -106,14 +209,23 @@ func (r *ProjectRepository) Create(project *Project) (string, error) {
 func (r *ProjectRepository) GetByID(id string) (*Project, error) {
 	project := &Project{}
 	var retentionJSON sql.NullString
+	var redactionJSON sql.NullString
+	var samplingJSON sql.NullString
+	var webhookJSON sql.NullString
+	var signingSecret sql.NullString
+	var maxBodyBytes sql.NullInt64
+	var rateLimit sql.NullInt64
+	var rateBurst sql.NullInt64
+	var defaultLevel sql.NullString
 	var description sql.NullString
 	var iconType sql.NullString
 	var iconValue sql.NullString
+	var group sql.NullString
 
 	err := r.db.QueryRow(`
-		SELECT id, name, description, icon_type, icon_value, api_key, api_key_prefix, is_active, retention_config, created_at, updated_at
-		FROM projects WHERE id = ?
-	`, id).Scan(&project.ID, &project.Name, &description, &iconType, &iconValue, &project.APIKey, &project.APIKeyPrefix, &project.IsActive, &retentionJSON, &project.CreatedAt, &project.UpdatedAt)
+		SELECT id, name, description, icon_type, icon_value, group_name, api_key, api_key_prefix, is_active, retention_config, redaction_config, sampling_config, signing_secret, max_body_bytes, rate_limit_per_minute, rate_limit_burst, ingestion_paused, default_level, require_level, outbound_webhook, created_at, updated_at
+		FROM projects WHERE id = ? AND deleted_at IS NULL
+	`, id).Scan(&project.ID, &project.Name, &description, &iconType, &iconValue, &group, &project.APIKey, &project.APIKeyPrefix, &project.IsActive, &retentionJSON, &redactionJSON, &samplingJSON, &signingSecret, &maxBodyBytes, &rateLimit, &rateBurst, &project.IngestionPaused, &defaultLevel, &project.RequireLevel, &webhookJSON, &project.CreatedAt, &project.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -131,11 +243,28 @@ func (r *ProjectRepository) GetByID(id string) (*Project, error) {
 	if iconValue.Valid {
 		project.IconValue = iconValue.String
 	}
+	if group.Valid {
+		project.Group = group.String
+	}
+	if signingSecret.Valid {
+		project.SigningSecret = signingSecret.String
+		project.SigningEnabled = true
+	}
+	if maxBodyBytes.Valid {
+		project.MaxBodyBytes = int(maxBodyBytes.Int64)
+	}
+	if rateLimit.Valid {
+		project.RateLimitPerMinute = int(rateLimit.Int64)
+	}
+	if rateBurst.Valid {
+		project.RateLimitBurst = int(rateBurst.Int64)
+	}
+	if defaultLevel.Valid {
+		project.DefaultLevel = LogLevel(defaultLevel.String)
+	}
 
-	if retentionJSON.Valid {
-		if err := json.Unmarshal([]byte(retentionJSON.String), &project.RetentionConfig); err != nil {
-			return nil, err
-		}
+	if err := project.decodeConfigs(retentionJSON, redactionJSON, samplingJSON, webhookJSON); err != nil {
+		return nil, err
 	}
 
 	return project, nil
@@ -146,14 +275,23 @@ func (r *ProjectRepository) GetByAPIKey(apiKey string) (*Project, error) {
 
 	project := &Project{}
 	var retentionJSON sql.NullString
+	var redactionJSON sql.NullString
+	var samplingJSON sql.NullString
+	var webhookJSON sql.NullString
+	var signingSecret sql.NullString
+	var maxBodyBytes sql.NullInt64
+	var rateLimit sql.NullInt64
+	var rateBurst sql.NullInt64
+	var defaultLevel sql.NullString
 	var description sql.NullString
 	var iconType sql.NullString
 	var iconValue sql.NullString
+	var group sql.NullString
 
 	err := r.db.QueryRow(`
-		SELECT id, name, description, icon_type, icon_value, api_key, api_key_prefix, is_active, retention_config, created_at, updated_at
-		FROM projects WHERE api_key = ? AND is_active = 1
-	`, hashedKey).Scan(&project.ID, &project.Name, &description, &iconType, &iconValue, &project.APIKey, &project.APIKeyPrefix, &project.IsActive, &retentionJSON, &project.CreatedAt, &project.UpdatedAt)
+		SELECT id, name, description, icon_type, icon_value, group_name, api_key, api_key_prefix, is_active, retention_config, redaction_config, sampling_config, signing_secret, max_body_bytes, rate_limit_per_minute, rate_limit_burst, ingestion_paused, default_level, require_level, outbound_webhook, created_at, updated_at
+		FROM projects WHERE api_key = ? AND is_active = ? AND deleted_at IS NULL
+	`, hashedKey, true).Scan(&project.ID, &project.Name, &description, &iconType, &iconValue, &group, &project.APIKey, &project.APIKeyPrefix, &project.IsActive, &retentionJSON, &redactionJSON, &samplingJSON, &signingSecret, &maxBodyBytes, &rateLimit, &rateBurst, &project.IngestionPaused, &defaultLevel, &project.RequireLevel, &webhookJSON, &project.CreatedAt, &project.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -171,11 +309,28 @@ func (r *ProjectRepository) GetByAPIKey(apiKey string) (*Project, error) {
 	if iconValue.Valid {
 		project.IconValue = iconValue.String
 	}
+	if group.Valid {
+		project.Group = group.String
+	}
+	if signingSecret.Valid {
+		project.SigningSecret = signingSecret.String
+		project.SigningEnabled = true
+	}
+	if maxBodyBytes.Valid {
+		project.MaxBodyBytes = int(maxBodyBytes.Int64)
+	}
+	if rateLimit.Valid {
+		project.RateLimitPerMinute = int(rateLimit.Int64)
+	}
+	if rateBurst.Valid {
+		project.RateLimitBurst = int(rateBurst.Int64)
+	}
+	if defaultLevel.Valid {
+		project.DefaultLevel = LogLevel(defaultLevel.String)
+	}
 
-	if retentionJSON.Valid {
-		if err := json.Unmarshal([]byte(retentionJSON.String), &project.RetentionConfig); err != nil {
-			return nil, err
-		}
+	if err := project.decodeConfigs(retentionJSON, redactionJSON, samplingJSON, webhookJSON); err != nil {
+		return nil, err
 	}
 
 	// Additional constant-time verification to prevent timing attacks
@@ -187,56 +342,149 @@ func (r *ProjectRepository) GetByAPIKey(apiKey string) (*Project, error) {
 }
 
 func (r *ProjectRepository) GetAll() ([]*Project, error) {
-	rows, err := r.db.Query(`
-		SELECT id, name, description, icon_type, icon_value, api_key, api_key_prefix, is_active, retention_config, created_at, updated_at
-		FROM projects ORDER BY created_at DESC
+	return r.queryProjects(`
+		SELECT id, name, description, icon_type, icon_value, group_name, api_key, api_key_prefix, is_active, retention_config, redaction_config, sampling_config, signing_secret, max_body_bytes, rate_limit_per_minute, rate_limit_burst, ingestion_paused, default_level, require_level, outbound_webhook, created_at, updated_at
+		FROM projects WHERE deleted_at IS NULL ORDER BY created_at DESC
 	`)
-	if err != nil {
-		return nil, err
+}
+
+// ProjectFilter narrows ProjectRepository.List. A nil IDs means every
+// project; a non-nil empty IDs matches nothing.
+type ProjectFilter struct {
+	IDs          []string
+	NameContains string // Case-insensitive substring of the name
+	ActiveOnly   bool
+	Limit        int
+	Offset       int
+}
+
+// List returns one page of projects matching the filter, newest first, along
+// with the total number of matches
+func (r *ProjectRepository) List(filter *ProjectFilter) ([]*Project, int, error) {
+	if filter.IDs != nil && len(filter.IDs) == 0 {
+		return []*Project{}, 0, nil
 	}
-	defer rows.Close()
 
-	var projects []*Project
-	for rows.Next() {
-		project := &Project{}
-		var retentionJSON sql.NullString
-		var description sql.NullString
-		var iconType sql.NullString
-		var iconValue sql.NullString
+	where := "deleted_at IS NULL"
+	args := []interface{}{}
 
-		if err := rows.Scan(&project.ID, &project.Name, &description, &iconType, &iconValue, &project.APIKey, &project.APIKeyPrefix, &project.IsActive, &retentionJSON, &project.CreatedAt, &project.UpdatedAt); err != nil {
-			return nil, err
+	if len(filter.IDs) > 0 {
+		where += " AND id IN (?" + strings.Repeat(",?", len(filter.IDs)-1) + ")"
+		for _, id := range filter.IDs {
+			args = append(args, id)
 		}
+	}
 
-		if description.Valid {
-			project.Description = description.String
-		}
-		if iconType.Valid {
-			project.IconType = iconType.String
-		}
-		if iconValue.Valid {
-			project.IconValue = iconValue.String
-		}
+	if filter.NameContains != "" {
+		where += " AND LOWER(name) LIKE ?"
+		args = append(args, "%"+strings.ToLower(filter.NameContains)+"%")
+	}
 
-		if retentionJSON.Valid {
-			if err := json.Unmarshal([]byte(retentionJSON.String), &project.RetentionConfig); err != nil {
-				return nil, err
-			}
-		}
+	if filter.ActiveOnly {
+		where += " AND is_active = ?"
+		args = append(args, true)
+	}
 
-		projects = append(projects, project)
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM projects WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
 	}
-	return projects, nil
+
+	projects, err := r.queryProjects(`
+		SELECT id, name, description, icon_type, icon_value, group_name, api_key, api_key_prefix, is_active, retention_config, redaction_config, sampling_config, signing_secret, max_body_bytes, rate_limit_per_minute, rate_limit_burst, ingestion_paused, default_level, require_level, outbound_webhook, created_at, updated_at
+		FROM projects
+		WHERE `+where+`
+		ORDER BY created_at DESC, id
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return projects, total, nil
+}
+
+// FindByName returns live projects whose name equals name or starts with it,
+// ignoring case. Exact matches come first, then the rest by name.
+func (r *ProjectRepository) FindByName(name string) ([]*Project, error) {
+	lower := strings.ToLower(name)
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(lower)
+
+	return r.queryProjects(`
+		SELECT id, name, description, icon_type, icon_value, group_name, api_key, api_key_prefix, is_active, retention_config, redaction_config, sampling_config, signing_secret, max_body_bytes, rate_limit_per_minute, rate_limit_burst, ingestion_paused, default_level, require_level, outbound_webhook, created_at, updated_at
+		FROM projects
+		WHERE deleted_at IS NULL AND LOWER(name) LIKE ? ESCAPE '\'
+		ORDER BY CASE WHEN LOWER(name) = ? THEN 0 ELSE 1 END, LOWER(name), id
+	`, escaped+"%", lower)
 }
 
 func (r *ProjectRepository) GetByUserID(userID string) ([]*Project, error) {
-	rows, err := r.db.Query(`
-		SELECT p.id, p.name, p.description, p.icon_type, p.icon_value, p.api_key, p.api_key_prefix, p.is_active, p.retention_config, p.created_at, p.updated_at
+	return r.queryProjects(`
+		SELECT p.id, p.name, p.description, p.icon_type, p.icon_value, p.group_name, p.api_key, p.api_key_prefix, p.is_active, p.retention_config, p.redaction_config, p.sampling_config, p.signing_secret, p.max_body_bytes, p.rate_limit_per_minute, p.rate_limit_burst, p.ingestion_paused, p.default_level, p.require_level, p.outbound_webhook, p.created_at, p.updated_at
 		FROM projects p
 		INNER JOIN user_projects up ON p.id = up.project_id
-		WHERE up.user_id = ?
+		WHERE up.user_id = ? AND p.deleted_at IS NULL
 		ORDER BY p.created_at DESC
 	`, userID)
+}
+
+// ProjectGroupCount is a project group and how many projects are in it
+type ProjectGroupCount struct {
+	Group string `json:"group"`
+	Count int    `json:"count"`
+}
+
+// CountByGroup lists the distinct project groups by name, skipping ungrouped
+// projects. A nil projectIDs covers all projects; an empty one matches nothing.
+func (r *ProjectRepository) CountByGroup(projectIDs []string) ([]ProjectGroupCount, error) {
+	groups := []ProjectGroupCount{}
+	if projectIDs != nil && len(projectIDs) == 0 {
+		return groups, nil
+	}
+
+	where := "deleted_at IS NULL AND group_name IS NOT NULL AND group_name != ''"
+	args := []interface{}{}
+	if len(projectIDs) > 0 {
+		where += " AND id IN (?" + strings.Repeat(",?", len(projectIDs)-1) + ")"
+		for _, id := range projectIDs {
+			args = append(args, id)
+		}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT group_name, COUNT(*)
+		FROM projects
+		WHERE `+where+`
+		GROUP BY group_name
+		ORDER BY group_name
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g ProjectGroupCount
+		if err := rows.Scan(&g.Group, &g.Count); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// queryProjects runs a query selecting the full project row and scans the results
+func (r *ProjectRepository) queryProjects(query string, args ...interface{}) ([]*Project, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -246,11 +494,20 @@ func (r *ProjectRepository) GetByUserID(userID string) ([]*Project, error) {
 	for rows.Next() {
 		project := &Project{}
 		var retentionJSON sql.NullString
+		var redactionJSON sql.NullString
+		var samplingJSON sql.NullString
+		var webhookJSON sql.NullString
+		var signingSecret sql.NullString
+		var maxBodyBytes sql.NullInt64
+		var rateLimit sql.NullInt64
+		var rateBurst sql.NullInt64
+		var defaultLevel sql.NullString
 		var description sql.NullString
 		var iconType sql.NullString
 		var iconValue sql.NullString
+		var group sql.NullString
 
-		if err := rows.Scan(&project.ID, &project.Name, &description, &iconType, &iconValue, &project.APIKey, &project.APIKeyPrefix, &project.IsActive, &retentionJSON, &project.CreatedAt, &project.UpdatedAt); err != nil {
+		if err := rows.Scan(&project.ID, &project.Name, &description, &iconType, &iconValue, &group, &project.APIKey, &project.APIKeyPrefix, &project.IsActive, &retentionJSON, &redactionJSON, &samplingJSON, &signingSecret, &maxBodyBytes, &rateLimit, &rateBurst, &project.IngestionPaused, &defaultLevel, &project.RequireLevel, &webhookJSON, &project.CreatedAt, &project.UpdatedAt); err != nil {
 			return nil, err
 		}
 
@@ -263,35 +520,139 @@ func (r *ProjectRepository) GetByUserID(userID string) ([]*Project, error) {
 		if iconValue.Valid {
 			project.IconValue = iconValue.String
 		}
+		if group.Valid {
+			project.Group = group.String
+		}
+		if signingSecret.Valid {
+			project.SigningSecret = signingSecret.String
+			project.SigningEnabled = true
+		}
+		if maxBodyBytes.Valid {
+			project.MaxBodyBytes = int(maxBodyBytes.Int64)
+		}
+		if rateLimit.Valid {
+			project.RateLimitPerMinute = int(rateLimit.Int64)
+		}
+		if rateBurst.Valid {
+			project.RateLimitBurst = int(rateBurst.Int64)
+		}
+		if defaultLevel.Valid {
+			project.DefaultLevel = LogLevel(defaultLevel.String)
+		}
 
-		if retentionJSON.Valid {
-			if err := json.Unmarshal([]byte(retentionJSON.String), &project.RetentionConfig); err != nil {
-				return nil, err
-			}
+		if err := project.decodeConfigs(retentionJSON, redactionJSON, samplingJSON, webhookJSON); err != nil {
+			return nil, err
 		}
 
 		projects = append(projects, project)
 	}
-	return projects, nil
+	return projects, rows.Err()
 }
 
 func (r *ProjectRepository) Update(project *Project) error {
 	project.UpdatedAt = time.Now()
 
-	var retentionJSON *string
-	if project.RetentionConfig != nil {
-		data, err := json.Marshal(project.RetentionConfig)
-		if err != nil {
+	retentionJSON, redactionJSON, samplingJSON, webhookJSON, err := project.encodeConfigs()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		UPDATE projects SET name = ?, description = ?, icon_type = ?, icon_value = ?, group_name = ?, is_active = ?, retention_config = ?, redaction_config = ?, sampling_config = ?, max_body_bytes = ?, rate_limit_per_minute = ?, rate_limit_burst = ?, default_level = ?, require_level = ?, outbound_webhook = ?, updated_at = ?
+		WHERE id = ?
+	`, project.Name, project.Description, project.IconType, project.IconValue, nullString(project.Group), project.IsActive, retentionJSON, redactionJSON, samplingJSON, nullPositiveInt(project.MaxBodyBytes), nullPositiveInt(project.RateLimitPerMinute), nullPositiveInt(project.RateLimitBurst), nullString(string(project.DefaultLevel)), project.RequireLevel, webhookJSON, project.UpdatedAt, project.ID)
+	return err
+}
+
+// encodeConfigs serializes the JSON config columns, leaving unset ones NULL
+func (p *Project) encodeConfigs() (retention, redaction, sampling, webhook *string, err error) {
+	if retention, err = encodeJSONColumn(p.RetentionConfig, p.RetentionConfig == nil); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if redaction, err = encodeJSONColumn(p.RedactionConfig, p.RedactionConfig.IsEmpty()); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if sampling, err = encodeJSONColumn(p.SamplingConfig, p.SamplingConfig == nil || len(p.SamplingConfig.Levels) == 0); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if webhook, err = encodeJSONColumn(p.OutboundWebhook, p.OutboundWebhook.IsEmpty()); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return retention, redaction, sampling, webhook, nil
+}
+
+func encodeJSONColumn(v interface{}, null bool) (*string, error) {
+	if null {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
+
+// decodeConfigs reads the JSON config columns scanned from a project row
+func (p *Project) decodeConfigs(retentionJSON, redactionJSON, samplingJSON, webhookJSON sql.NullString) error {
+	if retentionJSON.Valid {
+		if err := json.Unmarshal([]byte(retentionJSON.String), &p.RetentionConfig); err != nil {
+			return err
+		}
+	}
+	if redactionJSON.Valid {
+		if err := json.Unmarshal([]byte(redactionJSON.String), &p.RedactionConfig); err != nil {
+			return err
+		}
+	}
+	if samplingJSON.Valid {
+		if err := json.Unmarshal([]byte(samplingJSON.String), &p.SamplingConfig); err != nil {
+			return err
+		}
+	}
+	if webhookJSON.Valid {
+		if err := json.Unmarshal([]byte(webhookJSON.String), &p.OutboundWebhook); err != nil {
 			return err
 		}
-		s := string(data)
-		retentionJSON = &s
 	}
+	return nil
+}
+
+// GenerateSigningSecret creates a random secret for HMAC-signed ingestion
+func GenerateSigningSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "clsig_" + hex.EncodeToString(bytes), nil
+}
+
+// RotateSigningSecret sets a new signing secret, turning on signature checks
+// for the project if they were off. The raw secret is returned because,
+// unlike the API key, it is stored as-is: verifying an HMAC needs it.
+func (r *ProjectRepository) RotateSigningSecret(id string) (string, error) {
+	secret, err := GenerateSigningSecret()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.db.Exec(`
+		UPDATE projects SET signing_secret = ?, updated_at = ?
+		WHERE id = ?
+	`, secret, time.Now(), id)
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
 
+// ClearSigningSecret turns off signature checks for the project
+func (r *ProjectRepository) ClearSigningSecret(id string) error {
 	_, err := r.db.Exec(`
-		UPDATE projects SET name = ?, description = ?, icon_type = ?, icon_value = ?, is_active = ?, retention_config = ?, updated_at = ?
+		UPDATE projects SET signing_secret = NULL, updated_at = ?
 		WHERE id = ?
-	`, project.Name, project.Description, project.IconType, project.IconValue, project.IsActive, retentionJSON, project.UpdatedAt, project.ID)
+	`, time.Now(), id)
 	return err
 }
 
@@ -313,7 +674,42 @@ func (r *ProjectRepository) RotateAPIKey(id string) (string, error) {
 	return apiKey, nil
 }
 
+// SetIngestionPaused pauses or resumes ingestion for a live project. It
+// reports false if there is no live project with that ID.
+func (r *ProjectRepository) SetIngestionPaused(id string, paused bool) (bool, error) {
+	return rowsChanged(r.db.Exec(`
+		UPDATE projects SET ingestion_paused = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, paused, time.Now(), id))
+}
+
+// Delete permanently removes a project, soft-deleted or not
 func (r *ProjectRepository) Delete(id string) error {
 	_, err := r.db.Exec(`DELETE FROM projects WHERE id = ?`, id)
 	return err
 }
+
+// SoftDelete hides a project and its logs and stops its API key from
+// working. The logs are kept so a restore brings everything back. It reports
+// false if there is no live project with that ID.
+func (r *ProjectRepository) SoftDelete(id string) (bool, error) {
+	return rowsChanged(r.db.Exec(`
+		UPDATE projects SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, time.Now(), id))
+}
+
+// Restore brings back a soft-deleted project. It reports false if no
+// soft-deleted project has that ID.
+func (r *ProjectRepository) Restore(id string) (bool, error) {
+	return rowsChanged(r.db.Exec(`
+		UPDATE projects SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL
+	`, time.Now(), id))
+}
+
+// rowsChanged reports whether an UPDATE or DELETE touched any row
+func rowsChanged(result sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
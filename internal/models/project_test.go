@@ -2,6 +2,7 @@ package models_test
 
 import (
 	"database/sql"
+	"strings"
 	"testing"
 
 	"central-logs/internal/models"
@@ -25,8 +26,20 @@ func setupProjectTestDB(t *testing.T) *sql.DB {
 			api_key_prefix TEXT NOT NULL,
 			is_active INTEGER NOT NULL DEFAULT 1,
 			retention_config TEXT,
+			redaction_config TEXT,
+			sampling_config TEXT,
+			signing_secret TEXT,
+			max_body_bytes INTEGER,
+			rate_limit_per_minute INTEGER,
+			rate_limit_burst INTEGER,
+			ingestion_paused INTEGER NOT NULL DEFAULT 0,
+			default_level TEXT,
+			require_level INTEGER NOT NULL DEFAULT 0,
+			outbound_webhook TEXT,
+			group_name TEXT,
 			icon_type TEXT DEFAULT 'initials',
 			icon_value TEXT DEFAULT '',
+			deleted_at DATETIME,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -320,6 +333,52 @@ func TestProjectRepository_GetAll(t *testing.T) {
 	}
 }
 
+func TestProjectRepository_FindByName(t *testing.T) {
+	db := setupProjectTestDB(t)
+	defer db.Close()
+
+	repo := models.NewProjectRepository(db)
+
+	for _, name := range []string{"Billing Worker", "billing", "Billing API", "Search", "billing_old"} {
+		if _, err := repo.Create(&models.Project{Name: name, IsActive: true}); err != nil {
+			t.Fatalf("Failed to create project %s: %v", name, err)
+		}
+	}
+	deleted := &models.Project{Name: "Billing Legacy", IsActive: true}
+	repo.Create(deleted)
+	repo.SoftDelete(deleted.ID)
+
+	names := func(query string) []string {
+		projects, err := repo.FindByName(query)
+		if err != nil {
+			t.Fatalf("FindByName(%q) failed: %v", query, err)
+		}
+		var out []string
+		for _, p := range projects {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	// Exact match first, then the prefix matches by name; deleted projects are left out
+	got := names("BILLING")
+	want := []string{"billing", "Billing API", "Billing Worker", "billing_old"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// LIKE wildcards in the name are matched literally
+	if got := names("billing_"); len(got) != 1 || got[0] != "billing_old" {
+		t.Errorf("Expected only billing_old, got %v", got)
+	}
+	if got := names("Sea"); len(got) != 1 || got[0] != "Search" {
+		t.Errorf("Expected the prefix match Search, got %v", got)
+	}
+	if got := names("arch"); len(got) != 0 {
+		t.Errorf("Expected no match for a substring that is not a prefix, got %v", got)
+	}
+}
+
 func TestGenerateAPIKey(t *testing.T) {
 	key1, hash1, prefix1, err := models.GenerateAPIKey()
 	if err != nil {
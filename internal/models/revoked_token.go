@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RevokedTokenRepository is the denylist of session tokens revoked before
+// they expired, keyed by the token's jti. Entries are only needed until the
+// token would have expired anyway.
+type RevokedTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRevokedTokenRepository(db *sql.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+// Revoke denylists a token until expiresAt. Expired entries are pruned at
+// the same time so the table stays small.
+func (r *RevokedTokenRepository) Revoke(jti, userID string, expiresAt time.Time) error {
+	if _, err := r.DeleteExpired(); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(jti) DO NOTHING
+	`, jti, userID, expiresAt, time.Now())
+	return err
+}
+
+func (r *RevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM revoked_tokens WHERE jti = ?`, jti).Scan(&count)
+	return count > 0, err
+}
+
+// DeleteExpired removes entries whose tokens have expired on their own
+func (r *RevokedTokenRepository) DeleteExpired() (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < ?`, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
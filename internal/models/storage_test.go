@@ -0,0 +1,202 @@
+package models_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"central-logs/internal/database"
+	"central-logs/internal/database/migrations"
+	"central-logs/internal/models"
+)
+
+// openStorageTestDB opens the backend named by TEST_DATABASE_DRIVER (sqlite
+// by default) and migrates it with that driver's full schema, so the tests
+// below run the repositories' real SQL on each backend CI covers. Postgres
+// needs TEST_DATABASE_URL and is wiped first: point it at a throwaway database.
+func openStorageTestDB(t *testing.T) (*sql.DB, database.Dialect) {
+	driver := os.Getenv("TEST_DATABASE_DRIVER")
+	if driver == "" {
+		driver = database.DriverSQLite
+	}
+
+	dsn := filepath.Join(t.TempDir(), "central-logs.db")
+	if driver != database.DriverSQLite {
+		dsn = os.Getenv("TEST_DATABASE_URL")
+		if dsn == "" {
+			t.Skipf("TEST_DATABASE_URL is not set for the %s backend", driver)
+		}
+	}
+
+	db, err := database.Open(driver, dsn, database.DefaultSQLiteOptions())
+	if err != nil {
+		t.Fatalf("Failed to open %s database: %v", driver, err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if driver == database.DriverPostgres {
+		for _, stmt := range []string{"DROP SCHEMA public CASCADE", "CREATE SCHEMA public"} {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatalf("Failed to reset schema: %v", err)
+			}
+		}
+	}
+	if err := db.MigrateWithRegistry(migrations.ForDriver(driver)); err != nil {
+		t.Fatalf("Failed to migrate %s database: %v", driver, err)
+	}
+
+	return db.DB, db.Dialect
+}
+
+func TestStorageBackend_Logs(t *testing.T) {
+	db, dialect := openStorageTestDB(t)
+
+	projectRepo := models.NewProjectRepository(db)
+	logRepo := models.NewLogRepository(db)
+	logRepo.SetDialect(dialect)
+
+	project := &models.Project{Name: "Storage Project", IsActive: true}
+	if _, err := projectRepo.Create(project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	base := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	jakarta := time.FixedZone("WIB", 7*3600)
+	logs := []*models.Log{
+		{ProjectID: project.ID, Level: models.LogLevelError, Message: "Payment FAILED", Source: "api", Timestamp: base.In(jakarta),
+			Metadata: map[string]interface{}{"region": "eu-west", "status": 502}},
+		{ProjectID: project.ID, Level: models.LogLevelWarn, Message: "payment slow", Source: "api", Timestamp: base.Add(10 * time.Minute),
+			Metadata: map[string]interface{}{"region": "us-east"}},
+		{ProjectID: project.ID, Level: models.LogLevelError, Message: "queue stalled", Source: "worker", Timestamp: base.Add(20 * time.Minute)},
+	}
+	if err := logRepo.CreateBatch(logs); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+	single := &models.Log{ProjectID: project.ID, Level: models.LogLevelCritical, Message: "payment down", Timestamp: base.Add(30 * time.Minute),
+		Metadata: map[string]interface{}{"region": "eu-west"}}
+	if err := logRepo.Create(single); err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+
+	got, err := logRepo.GetByID(logs[0].ID)
+	if err != nil || got == nil {
+		t.Fatalf("Failed to get log: %v", err)
+	}
+	if !got.Timestamp.Equal(base) || got.Metadata["region"] != "eu-west" {
+		t.Errorf("Expected the log to round-trip, got %+v", got)
+	}
+
+	// Search is case-insensitive and the bound is compared as a time
+	start := base.Add(5 * time.Minute).In(jakarta)
+	listed, total, err := logRepo.List(&models.LogFilter{ProjectIDs: []string{project.ID}, Search: "payment", StartTime: &start, Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to list logs: %v", err)
+	}
+	if total != 2 || len(listed) != 2 || listed[0].ID != single.ID || listed[1].ID != logs[1].ID {
+		t.Errorf("Expected the 2 later payment logs newest first, got %d: %+v", total, listed)
+	}
+
+	count, err := logRepo.Count(&models.LogFilter{ProjectIDs: []string{project.ID}, Levels: models.LevelsAtOrAbove(models.LogLevelError)})
+	if err != nil || count != 3 {
+		t.Errorf("Expected 3 logs at ERROR or above, got %d (%v)", count, err)
+	}
+
+	facets, err := logRepo.FacetBySource(&models.LogFilter{ProjectIDs: []string{project.ID}})
+	if err != nil || len(facets) == 0 {
+		t.Errorf("Expected source facets, got %+v (%v)", facets, err)
+	}
+
+	rule := &models.AlertRule{ProjectID: project.ID, MinLevel: models.LogLevelError, MetadataKey: "region", MetadataValue: "eu-west"}
+	count, err = logRepo.CountSince(rule, time.Now().Add(-time.Minute))
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 eu-west logs at ERROR or above, got %d (%v)", count, err)
+	}
+	rule.MetadataKey, rule.MetadataValue = "status", "502"
+	if count, err = logRepo.CountSince(rule, time.Now().Add(-time.Minute)); err != nil || count != 1 {
+		t.Errorf("Expected a numeric metadata value to match as text, got %d (%v)", count, err)
+	}
+
+	keys, err := logRepo.MetadataKeys(project.ID, 100)
+	if err != nil {
+		t.Fatalf("Failed to list metadata keys: %v", err)
+	}
+	found := map[string]int{}
+	for _, key := range keys.Keys {
+		found[key.Key] = key.Count
+	}
+	if found["region"] != 3 || found["status"] != 1 {
+		t.Errorf("Expected region on 3 logs and status on 1, got %+v", keys.Keys)
+	}
+
+	updated, err := logRepo.UpdateStatus(logs[2].ID, models.LogStatusResolved, "")
+	if err != nil || !updated {
+		t.Errorf("Expected the status to be updated, got %v (%v)", updated, err)
+	}
+}
+
+func TestStorageBackend_AlertRules(t *testing.T) {
+	db, _ := openStorageTestDB(t)
+
+	projectRepo := models.NewProjectRepository(db)
+	repo := models.NewAlertRuleRepository(db)
+
+	project := &models.Project{Name: "Storage Project", IsActive: true}
+	if _, err := projectRepo.Create(project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	rule := &models.AlertRule{ProjectID: project.ID, Name: "Errors", MinLevel: models.LogLevelError, MetadataKey: "region", MetadataValue: "eu-west",
+		Threshold: 5, WindowSeconds: 60, IsActive: true}
+	if err := repo.Create(rule); err != nil {
+		t.Fatalf("Failed to create alert rule: %v", err)
+	}
+	if err := repo.MarkFired(rule.ID, time.Now()); err != nil {
+		t.Fatalf("Failed to mark rule fired: %v", err)
+	}
+
+	active, err := repo.GetActive()
+	if err != nil {
+		t.Fatalf("Failed to get active rules: %v", err)
+	}
+	if len(active) != 1 || active[0].LastFiredAt == nil || active[0].MetadataKey != "region" {
+		t.Fatalf("Expected the fired rule, got %+v", active)
+	}
+
+	rule.IsActive = false
+	if err := repo.Update(rule); err != nil {
+		t.Fatalf("Failed to update rule: %v", err)
+	}
+	if active, _ := repo.GetActive(); len(active) != 0 {
+		t.Errorf("Expected no active rules, got %d", len(active))
+	}
+}
+
+func TestStorageBackend_APIKeyUsage(t *testing.T) {
+	db, _ := openStorageTestDB(t)
+
+	projectRepo := models.NewProjectRepository(db)
+	repo := models.NewAPIKeyUsageRepository(db)
+
+	project := &models.Project{Name: "Storage Project", IsActive: true}
+	if _, err := projectRepo.Create(project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	first := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	later := first.Add(time.Minute)
+	for _, at := range []time.Time{first, later} {
+		if err := repo.Touch(project.ID, project.APIKeyPrefix, at); err != nil {
+			t.Fatalf("Failed to touch key usage: %v", err)
+		}
+	}
+
+	usages, err := repo.GetByProjectID(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get usage: %v", err)
+	}
+	if len(usages) != 1 || !usages[0].LastUsedAt.Equal(later) {
+		t.Errorf("Expected one usage row upserted to %s, got %+v", later, usages)
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Settings stored in system_settings
+const (
+	SettingMaintenanceMode = "maintenance_mode" // "true" while ingestion is turned away
+)
+
+// SystemSettingRepository stores server-wide settings that admins change at
+// runtime, as string values by key. Unlike the config file they are shared by
+// every instance using the database.
+type SystemSettingRepository struct {
+	db *sql.DB
+}
+
+func NewSystemSettingRepository(db *sql.DB) *SystemSettingRepository {
+	return &SystemSettingRepository{db: db}
+}
+
+// Get returns a setting's value, reporting false if it was never set
+func (r *SystemSettingRepository) Get(key string) (string, bool, error) {
+	var value string
+	err := r.db.QueryRow(`SELECT value FROM system_settings WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Set stores a setting, replacing any earlier value
+func (r *SystemSettingRepository) Set(key, value string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO system_settings (key, value, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value, time.Now())
+	return err
+}
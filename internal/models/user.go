@@ -2,6 +2,9 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,18 +19,31 @@ const (
 )
 
 type User struct {
-	ID               string    `json:"id"`
-	Username         string    `json:"username"`
-	Email            string    `json:"email,omitempty"`
-	Password         string    `json:"-"`
-	Name             string    `json:"name"`
-	Role             UserRole  `json:"role"`
-	IsActive         bool      `json:"is_active"`
-	TwoFactorSecret  string    `json:"-"`
-	TwoFactorEnabled bool      `json:"two_factor_enabled"`
-	BackupCodes      string    `json:"-"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               string     `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email,omitempty"`
+	Password         string     `json:"-"`
+	Name             string     `json:"name"`
+	Role             UserRole   `json:"role"`
+	IsActive         bool       `json:"is_active"`
+	TwoFactorSecret  string     `json:"-"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	BackupCodes      string     `json:"-"`
+	LastLoginAt      *time.Time `json:"last_login_at"`
+	TokenVersion     int        `json:"-"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// NormalizeEmail lowercases and trims an email so differently-cased
+// addresses map to the same account
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeUsername trims surrounding whitespace from a username
+func NormalizeUsername(username string) string {
+	return strings.TrimSpace(username)
 }
 
 type UserRepository struct {
@@ -40,6 +56,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(user *User) error {
 	user.ID = uuid.New().String()
+	user.Username = NormalizeUsername(user.Username)
+	user.Email = NormalizeEmail(user.Email)
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
@@ -59,10 +77,11 @@ func (r *UserRepository) Create(user *User) error {
 func (r *UserRepository) GetByID(id string) (*User, error) {
 	user := &User{}
 	var twoFactorSecret, backupCodes sql.NullString
+	var lastLoginAt sql.NullTime
 	err := r.db.QueryRow(`
-		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, created_at, updated_at
-		FROM users WHERE id = ?
-	`, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &user.CreatedAt, &user.UpdatedAt)
+		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, last_login_at, token_version, created_at, updated_at
+		FROM users WHERE id = ? AND deleted_at IS NULL
+	`, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &lastLoginAt, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -72,16 +91,20 @@ func (r *UserRepository) GetByID(id string) (*User, error) {
 	}
 	user.TwoFactorSecret = twoFactorSecret.String
 	user.BackupCodes = backupCodes.String
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
 	return user, nil
 }
 
 func (r *UserRepository) GetByUsername(username string) (*User, error) {
 	user := &User{}
 	var twoFactorSecret, backupCodes sql.NullString
+	var lastLoginAt sql.NullTime
 	err := r.db.QueryRow(`
-		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, created_at, updated_at
-		FROM users WHERE username = ?
-	`, username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &user.CreatedAt, &user.UpdatedAt)
+		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, last_login_at, token_version, created_at, updated_at
+		FROM users WHERE username = ? AND deleted_at IS NULL
+	`, NormalizeUsername(username)).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &lastLoginAt, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -91,16 +114,20 @@ func (r *UserRepository) GetByUsername(username string) (*User, error) {
 	}
 	user.TwoFactorSecret = twoFactorSecret.String
 	user.BackupCodes = backupCodes.String
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
 	return user, nil
 }
 
 func (r *UserRepository) GetByEmail(email string) (*User, error) {
 	user := &User{}
 	var twoFactorSecret, backupCodes sql.NullString
+	var lastLoginAt sql.NullTime
 	err := r.db.QueryRow(`
-		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, created_at, updated_at
-		FROM users WHERE email = ?
-	`, email).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &user.CreatedAt, &user.UpdatedAt)
+		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, last_login_at, token_version, created_at, updated_at
+		FROM users WHERE LOWER(email) = ? AND deleted_at IS NULL
+	`, NormalizeEmail(email)).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &lastLoginAt, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -110,34 +137,115 @@ func (r *UserRepository) GetByEmail(email string) (*User, error) {
 	}
 	user.TwoFactorSecret = twoFactorSecret.String
 	user.BackupCodes = backupCodes.String
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
 	return user, nil
 }
 
 func (r *UserRepository) GetAll() ([]*User, error) {
-	rows, err := r.db.Query(`
-		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, created_at, updated_at
-		FROM users ORDER BY created_at DESC
+	return r.queryUsers(`
+		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, last_login_at, token_version, created_at, updated_at
+		FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC
 	`)
+}
+
+// UserFilter narrows UserRepository.List. A nil Active matches both active
+// and inactive users.
+type UserFilter struct {
+	Search string // Case-insensitive substring of username, name or email
+	Role   UserRole
+	Active *bool
+	// InactiveSince keeps users who have not logged in since this time,
+	// including those who never have
+	InactiveSince time.Time
+	Limit         int
+	Offset        int
+}
+
+// List returns one page of users matching the filter, newest first, along
+// with the total number of matches
+func (r *UserRepository) List(filter *UserFilter) ([]*User, int, error) {
+	where := "deleted_at IS NULL"
+	args := []interface{}{}
+
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		where += " AND (LOWER(username) LIKE ? OR LOWER(name) LIKE ? OR LOWER(email) LIKE ?)"
+		pattern := "%" + strings.ToLower(search) + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	if filter.Role != "" {
+		where += " AND role = ?"
+		args = append(args, filter.Role)
+	}
+
+	if filter.Active != nil {
+		where += " AND is_active = ?"
+		args = append(args, *filter.Active)
+	}
+
+	if !filter.InactiveSince.IsZero() {
+		where += " AND (last_login_at IS NULL OR last_login_at < ?)"
+		args = append(args, filter.InactiveSince)
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM users WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, err := r.queryUsers(`
+		SELECT id, username, email, password, name, role, is_active, two_factor_secret, two_factor_enabled, backup_codes, last_login_at, token_version, created_at, updated_at
+		FROM users
+		WHERE `+where+`
+		ORDER BY created_at DESC, id
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *UserRepository) queryUsers(query string, args ...interface{}) ([]*User, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var users []*User
+	users := []*User{}
 	for rows.Next() {
 		user := &User{}
 		var twoFactorSecret, backupCodes sql.NullString
-		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		var lastLoginAt sql.NullTime
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Name, &user.Role, &user.IsActive, &twoFactorSecret, &user.TwoFactorEnabled, &backupCodes, &lastLoginAt, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt); err != nil {
 			return nil, err
 		}
 		user.TwoFactorSecret = twoFactorSecret.String
 		user.BackupCodes = backupCodes.String
+		if lastLoginAt.Valid {
+			user.LastLoginAt = &lastLoginAt.Time
+		}
 		users = append(users, user)
 	}
-	return users, nil
+	return users, rows.Err()
 }
 
 func (r *UserRepository) Update(user *User) error {
+	user.Username = NormalizeUsername(user.Username)
+	user.Email = NormalizeEmail(user.Email)
 	user.UpdatedAt = time.Now()
 	_, err := r.db.Exec(`
 		UPDATE users SET username = ?, email = ?, name = ?, role = ?, is_active = ?, updated_at = ?
@@ -158,6 +266,20 @@ func (r *UserRepository) UpdatePassword(id, password string) error {
 	return err
 }
 
+// UpdateLastLogin stamps the time of a successful login
+func (r *UserRepository) UpdateLastLogin(id string) error {
+	_, err := r.db.Exec(`UPDATE users SET last_login_at = ? WHERE id = ?`, time.Now(), id)
+	return err
+}
+
+// BumpTokenVersion invalidates every session token issued to the user so
+// far. It reports false when the user does not exist.
+func (r *UserRepository) BumpTokenVersion(id string) (bool, error) {
+	return rowsChanged(r.db.Exec(`
+		UPDATE users SET token_version = token_version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, time.Now(), id))
+}
+
 func (r *UserRepository) UpdateTwoFactor(id string, secret string, enabled bool, backupCodes string) error {
 	_, err := r.db.Exec(`
 		UPDATE users SET two_factor_secret = ?, two_factor_enabled = ?, backup_codes = ?, updated_at = ? WHERE id = ?
@@ -172,14 +294,96 @@ func (r *UserRepository) UpdateBackupCodes(id string, backupCodes string) error
 	return err
 }
 
+// backupCodeAttempts is how many times ConsumeBackupCode re-reads a list
+// that another request changed before giving up
+const backupCodeAttempts = 5
+
+// ConsumeBackupCode removes the backup code matching code from the user's
+// hashed codes and reports whether it was there. The list is rewritten only
+// if it is unchanged since it was read, so two requests racing with the same
+// code can't both use it: the loser re-reads the list and no longer finds it.
+func (r *UserRepository) ConsumeBackupCode(id, code string) (bool, error) {
+	for attempt := 0; attempt < backupCodeAttempts; attempt++ {
+		var stored sql.NullString
+		err := r.db.QueryRow(`SELECT backup_codes FROM users WHERE id = ?`, id).Scan(&stored)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if stored.String == "" {
+			return false, nil
+		}
+
+		var hashedCodes []string
+		if err := json.Unmarshal([]byte(stored.String), &hashedCodes); err != nil {
+			return false, err
+		}
+		match := -1
+		for i, hashedCode := range hashedCodes {
+			if bcrypt.CompareHashAndPassword([]byte(hashedCode), []byte(code)) == nil {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			return false, nil
+		}
+
+		remaining, err := json.Marshal(append(hashedCodes[:match:match], hashedCodes[match+1:]...))
+		if err != nil {
+			return false, err
+		}
+		result, err := r.db.Exec(`
+			UPDATE users SET backup_codes = ?, updated_at = ? WHERE id = ? AND backup_codes = ?
+		`, string(remaining), time.Now(), id, stored.String)
+		if err != nil {
+			return false, err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return false, err
+		} else if n == 1 {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("backup codes of user %s kept changing while consuming one", id)
+}
+
+// Delete permanently removes a user, soft-deleted or not
 func (r *UserRepository) Delete(id string) error {
 	_, err := r.db.Exec(`DELETE FROM users WHERE id = ?`, id)
 	return err
 }
 
+// SoftDelete hides a user from lookups and logins until restored. It
+// reports false if there is no live user with that ID.
+func (r *UserRepository) SoftDelete(id string) (bool, error) {
+	return rowsChanged(r.db.Exec(`
+		UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, time.Now(), id))
+}
+
+// Restore brings back a soft-deleted user. It reports false if no
+// soft-deleted user has that ID.
+func (r *UserRepository) Restore(id string) (bool, error) {
+	return rowsChanged(r.db.Exec(`
+		UPDATE users SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL
+	`, time.Now(), id))
+}
+
+// UsernameTaken reports whether any user, including soft-deleted ones, holds
+// the username, ignoring case. Soft-deleted users keep their username until
+// purged.
+func (r *UserRepository) UsernameTaken(username string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM users WHERE LOWER(username) = LOWER(?)`, NormalizeUsername(username)).Scan(&count)
+	return count > 0, err
+}
+
 func (r *UserRepository) Count() (int, error) {
 	var count int
-	err := r.db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count)
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`).Scan(&count)
 	return count, err
 }
 
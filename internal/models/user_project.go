@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,6 +28,43 @@ type UserProject struct {
 	Project *Project `json:"project,omitempty"`
 }
 
+// MemberView is the reduced shape of a project member shown to viewers
+// who are neither project owners nor admins.
+type MemberView struct {
+	Name       string      `json:"name"`
+	Role       ProjectRole `json:"role"`
+	Identifier string      `json:"identifier"`
+}
+
+// ToMemberView shapes the member for a viewer with the given project role.
+// Owners get the full record; everyone else gets a MemberView without the
+// member's email, account status or 2FA state.
+func (up *UserProject) ToMemberView(viewerRole ProjectRole) interface{} {
+	if viewerRole == ProjectRoleOwner {
+		return up
+	}
+
+	view := MemberView{Role: up.Role}
+	if up.User != nil {
+		view.Name = up.User.Name
+		view.Identifier = maskEmail(up.User.Email)
+	}
+	if view.Identifier == "" && len(up.UserID) >= 8 {
+		view.Identifier = up.UserID[:8]
+	}
+	return view
+}
+
+// maskEmail keeps the first character of the local part and the domain,
+// e.g. "jane@example.com" becomes "j***@example.com".
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return ""
+	}
+	return email[:1] + "***" + email[at:]
+}
+
 type UserProjectRepository struct {
 	db *sql.DB
 }
@@ -69,7 +107,7 @@ func (r *UserProjectRepository) GetProjectMembers(projectID string) ([]*UserProj
 		       u.id, u.email, u.name, u.role, u.is_active, u.created_at, u.updated_at
 		FROM user_projects up
 		INNER JOIN users u ON up.user_id = u.id
-		WHERE up.project_id = ?
+		WHERE up.project_id = ? AND u.deleted_at IS NULL
 		ORDER BY up.created_at ASC
 	`, projectID)
 	if err != nil {
@@ -118,7 +156,9 @@ func (r *UserProjectRepository) GetUserProjects(userID string) ([]*UserProject,
 
 func (r *UserProjectRepository) GetUserProjectIDs(userID string) ([]string, error) {
 	rows, err := r.db.Query(`
-		SELECT project_id FROM user_projects WHERE user_id = ?
+		SELECT up.project_id FROM user_projects up
+		INNER JOIN projects p ON up.project_id = p.id
+		WHERE up.user_id = ? AND p.deleted_at IS NULL
 	`, userID)
 	if err != nil {
 		return nil, err
@@ -150,21 +190,39 @@ func (r *UserProjectRepository) Delete(userID, projectID string) error {
 	return err
 }
 
+// HasAccess reports whether the user is a member of the project. Members of
+// a soft-deleted project have no access until it is restored.
 func (r *UserProjectRepository) HasAccess(userID, projectID string) (bool, error) {
 	var count int
 	err := r.db.QueryRow(`
-		SELECT COUNT(*) FROM user_projects WHERE user_id = ? AND project_id = ?
+		SELECT COUNT(*) FROM user_projects up
+		INNER JOIN projects p ON up.project_id = p.id
+		WHERE up.user_id = ? AND up.project_id = ? AND p.deleted_at IS NULL
 	`, userID, projectID).Scan(&count)
 	return count > 0, err
 }
 
+// HasRole reports whether the user holds one of roles in the project. Like
+// HasAccess it is false for a soft-deleted project.
 func (r *UserProjectRepository) HasRole(userID, projectID string, roles ...ProjectRole) (bool, error) {
+	return r.hasRole(userID, projectID, "p.deleted_at IS NULL", roles)
+}
+
+// HasRoleInDeleted is HasRole for a soft-deleted project, so its owners can
+// restore it
+func (r *UserProjectRepository) HasRoleInDeleted(userID, projectID string, roles ...ProjectRole) (bool, error) {
+	return r.hasRole(userID, projectID, "p.deleted_at IS NOT NULL", roles)
+}
+
+func (r *UserProjectRepository) hasRole(userID, projectID, projectState string, roles []ProjectRole) (bool, error) {
 	if len(roles) == 0 {
 		return false, nil
 	}
 
 	// Build query with role placeholders
-	query := `SELECT COUNT(*) FROM user_projects WHERE user_id = ? AND project_id = ? AND role IN (`
+	query := `SELECT COUNT(*) FROM user_projects up
+		INNER JOIN projects p ON up.project_id = p.id
+		WHERE up.user_id = ? AND up.project_id = ? AND ` + projectState + ` AND up.role IN (`
 	args := []interface{}{userID, projectID}
 	for i, role := range roles {
 		if i > 0 {
@@ -2,11 +2,16 @@ package models_test
 
 import (
 	"database/sql"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"central-logs/internal/models"
 
 	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func setupTestDB(t *testing.T) *sql.DB {
@@ -28,6 +33,9 @@ func setupTestDB(t *testing.T) *sql.DB {
 			two_factor_secret TEXT DEFAULT '',
 			two_factor_enabled INTEGER DEFAULT 0,
 			backup_codes TEXT DEFAULT '',
+			deleted_at DATETIME,
+			last_login_at DATETIME,
+			token_version INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
@@ -117,6 +125,47 @@ func TestUserRepository_GetByEmail_NotFound(t *testing.T) {
 	}
 }
 
+func TestUserRepository_NormalizesEmailAndUsername(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := models.NewUserRepository(db)
+
+	user := &models.User{
+		Username: "  Alice ",
+		Email:    " Alice@Example.COM ",
+		Password: "password123",
+		Name:     "Alice",
+		Role:     models.RoleUser,
+		IsActive: true,
+	}
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	if user.Username != "Alice" || user.Email != "alice@example.com" {
+		t.Errorf("Expected normalized Alice/alice@example.com, got %q/%q", user.Username, user.Email)
+	}
+
+	found, err := repo.GetByEmail("ALICE@example.com")
+	if err != nil || found == nil || found.ID != user.ID {
+		t.Errorf("Expected lookup by differently-cased email to find the user, got %v, %v", found, err)
+	}
+
+	found, err = repo.GetByUsername(" Alice")
+	if err != nil || found == nil || found.ID != user.ID {
+		t.Errorf("Expected lookup by untrimmed username to find the user, got %v, %v", found, err)
+	}
+
+	taken, err := repo.UsernameTaken("alice")
+	if err != nil {
+		t.Fatalf("Failed to check username: %v", err)
+	}
+	if !taken {
+		t.Error("Expected username check to ignore case")
+	}
+}
+
 func TestUser_CheckPassword(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -302,6 +351,95 @@ func TestUserRepository_GetAll(t *testing.T) {
 	}
 }
 
+func TestUserRepository_UpdateLastLogin(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := models.NewUserRepository(db)
+
+	dormant := &models.User{Username: "dormant", Email: "dormant@example.com", Password: "password123", Name: "Dormant", Role: models.RoleUser, IsActive: true}
+	recent := &models.User{Username: "recent", Email: "recent@example.com", Password: "password123", Name: "Recent", Role: models.RoleUser, IsActive: true}
+	repo.Create(dormant)
+	repo.Create(recent)
+
+	found, _ := repo.GetByID(recent.ID)
+	if found.LastLoginAt != nil {
+		t.Fatalf("Expected no last login before the first login, got %v", found.LastLoginAt)
+	}
+
+	before := time.Now()
+	if err := repo.UpdateLastLogin(recent.ID); err != nil {
+		t.Fatalf("Failed to update last login: %v", err)
+	}
+
+	found, _ = repo.GetByID(recent.ID)
+	if found.LastLoginAt == nil || found.LastLoginAt.Before(before.Add(-time.Second)) {
+		t.Fatalf("Expected last login to be stamped, got %v", found.LastLoginAt)
+	}
+
+	users, total, err := repo.List(&models.UserFilter{InactiveSince: before.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	if total != 1 || users[0].ID != dormant.ID {
+		t.Errorf("Expected only the never-logged-in user to be inactive, got %d users", total)
+	}
+}
+
+func TestUserRepository_ConsumeBackupCode(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	repo := models.NewUserRepository(db)
+	user := &models.User{Username: "testuser", Email: "test@example.com", Password: "password123", Name: "Test User", Role: models.RoleUser, IsActive: true}
+	repo.Create(user)
+
+	var hashed []string
+	for _, code := range []string{"AAAA1111", "BBBB2222"} {
+		h, _ := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		hashed = append(hashed, string(h))
+	}
+	codes, _ := json.Marshal(hashed)
+	if err := repo.UpdateTwoFactor(user.ID, "secret", true, string(codes)); err != nil {
+		t.Fatalf("Failed to store backup codes: %v", err)
+	}
+
+	// Comparing against a default-cost hash is slow enough that every request
+	// reads the list before the first one writes it back
+	const attempts = 10
+	var wg sync.WaitGroup
+	var consumed atomic.Int32
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.ConsumeBackupCode(user.ID, "AAAA1111")
+			if err != nil {
+				t.Errorf("ConsumeBackupCode failed: %v", err)
+			}
+			if ok {
+				consumed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := consumed.Load(); n != 1 {
+		t.Fatalf("Expected the backup code to be used exactly once, got %d", n)
+	}
+
+	if ok, _ := repo.ConsumeBackupCode(user.ID, "AAAA1111"); ok {
+		t.Error("Expected a used backup code to be rejected")
+	}
+	if ok, _ := repo.ConsumeBackupCode(user.ID, "CCCC3333"); ok {
+		t.Error("Expected an unknown code to be rejected")
+	}
+	if ok, _ := repo.ConsumeBackupCode(user.ID, "BBBB2222"); !ok {
+		t.Error("Expected the other backup code to still work")
+	}
+}
+
 func TestUserRepository_Count(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// Handler serves the generated OpenAPI document and a Swagger UI page
+type Handler struct {
+	spec []byte
+}
+
+// NewHandler builds the spec once so every request serves the same bytes
+func NewHandler(version string) (*Handler, error) {
+	spec, err := json.Marshal(Build(version))
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{spec: spec}, nil
+}
+
+// Spec handles GET /api/openapi.json
+func (h *Handler) Spec(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(h.spec)
+}
+
+// docsCSP relaxes the global Content-Security-Policy so the docs page can
+// load the Swagger UI bundle from its CDN
+const docsCSP = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' https://unpkg.com; " +
+	"style-src 'self' 'unsafe-inline' https://unpkg.com; " +
+	"img-src 'self' data: https:; " +
+	"frame-ancestors 'none'"
+
+// Docs handles GET /api/docs
+func (h *Handler) Docs(c *fiber.Ctx) error {
+	c.Set("Content-Security-Policy", docsCSP)
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Send(docsPage)
+}
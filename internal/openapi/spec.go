@@ -0,0 +1,444 @@
+// Package openapi builds an OpenAPI 3 document for the HTTP API from the
+// request and response types the handlers already use, so the spec cannot
+// drift from the structs that are actually decoded and encoded.
+package openapi
+
+import (
+	"strings"
+	"time"
+
+	"central-logs/internal/database"
+	"central-logs/internal/handlers"
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+)
+
+// Security schemes referenced by operations
+const (
+	authAPIKey = "apiKey"
+	authBearer = "bearerAuth"
+	authNone   = ""
+)
+
+// operation describes a single route in the spec
+type operation struct {
+	Method   string
+	Path     string // Fiber-style path, e.g. /api/admin/projects/:id
+	Summary  string
+	Tag      string
+	Auth     string
+	Request  interface{}
+	Response interface{}
+	Status   string // Success status code, defaults to "200"
+	Download string // Media type of a binary success body; Response is then ignored
+	Upload   string // Media type of a raw request body; Request is then ignored
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type pinResponse struct {
+	ProjectID string `json:"project_id"`
+	Pinned    bool   `json:"pinned"`
+}
+
+// operations lists the documented routes. Keep in sync with cmd/server/main.go.
+var operations = []operation{
+	// Health
+	{Method: "GET", Path: "/ready", Summary: "Readiness probe: 503 only when the database is unreachable; Redis outages report degraded", Tag: "System", Auth: authNone,
+		Response: handlers.ReadinessResponse{}},
+
+	// Reference data
+	{Method: "GET", Path: "/api/meta/levels", Summary: "List log levels in ascending priority with suggested labels and colors", Tag: "Meta", Auth: authNone,
+		Response: handlers.LevelsResponse{}},
+
+	// Log ingestion
+	{Method: "POST", Path: "/api/v1/logs", Summary: "Ingest a single log entry", Tag: "Ingestion", Auth: authAPIKey,
+		Request: handlers.CreateLogRequest{}, Response: handlers.CreateLogResponse{}, Status: "201"},
+	{Method: "POST", Path: "/api/v1/logs/batch", Summary: "Ingest a batch of log entries, all or nothing unless ?best_effort=true", Tag: "Ingestion", Auth: authAPIKey,
+		Request: handlers.BatchLogRequest{}, Response: handlers.BatchLogResponse{}, Status: "201"},
+	{Method: "POST", Path: "/api/v1/logs/text", Summary: "Ingest each non-empty line of a plain-text body as a log, at ?level= with ?source=", Tag: "Ingestion", Auth: authAPIKey,
+		Upload: "text/plain", Response: handlers.BatchLogResponse{}, Status: "201"},
+	{Method: "POST", Path: "/api/v1/logs/validate", Summary: "Show how a log entry would be stored, without storing it", Tag: "Ingestion", Auth: authAPIKey,
+		Request: handlers.CreateLogRequest{}, Response: handlers.ValidateLogResponse{}},
+
+	// Auth
+	{Method: "POST", Path: "/api/auth/login", Summary: "Log in with username and password", Tag: "Auth", Auth: authNone,
+		Request: handlers.LoginRequest{}, Response: handlers.LoginResponse{}},
+	{Method: "POST", Path: "/api/auth/2fa/verify", Summary: "Complete a login that requires 2FA", Tag: "Auth", Auth: authNone,
+		Request: handlers.VerifyLoginRequest{}, Response: handlers.LoginResponse{}},
+	{Method: "POST", Path: "/api/auth/logout", Summary: "Revoke the token used for this request", Tag: "Auth", Auth: authBearer,
+		Response: messageResponse{}},
+	{Method: "GET", Path: "/api/auth/me", Summary: "Get the current user", Tag: "Auth", Auth: authBearer,
+		Response: models.User{}},
+	{Method: "PUT", Path: "/api/auth/me", Summary: "Update the current user's profile", Tag: "Auth", Auth: authBearer,
+		Request: handlers.UpdateProfileRequest{}, Response: models.User{}},
+	{Method: "PUT", Path: "/api/auth/change-password", Summary: "Change the current user's password", Tag: "Auth", Auth: authBearer,
+		Request: handlers.ChangePasswordRequest{}, Response: messageResponse{}},
+
+	// Users
+	{Method: "GET", Path: "/api/admin/users", Summary: "List users, filterable by search, role, active status and inactive_since", Tag: "Users", Auth: authBearer,
+		Response: struct {
+			Users  []models.User `json:"users"`
+			Total  int           `json:"total"`
+			Limit  int           `json:"limit"`
+			Offset int           `json:"offset"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/users", Summary: "Create a user", Tag: "Users", Auth: authBearer,
+		Request: handlers.CreateUserRequest{}, Response: models.User{}, Status: "201"},
+	{Method: "POST", Path: "/api/admin/users/import", Summary: "Create users in bulk from a JSON array or CSV, reporting each row", Tag: "Users", Auth: authBearer,
+		Request: []handlers.CreateUserRequest{}, Response: handlers.ImportUsersResponse{}},
+	{Method: "GET", Path: "/api/admin/users/:id", Summary: "Get a user", Tag: "Users", Auth: authBearer,
+		Response: models.User{}},
+	{Method: "PUT", Path: "/api/admin/users/:id", Summary: "Update a user", Tag: "Users", Auth: authBearer,
+		Request: handlers.UpdateUserRequest{}, Response: models.User{}},
+	{Method: "DELETE", Path: "/api/admin/users/:id", Summary: "Soft-delete a user, or remove them permanently with purge=true", Tag: "Users", Auth: authBearer,
+		Response: messageResponse{}},
+	{Method: "POST", Path: "/api/admin/users/:id/restore", Summary: "Restore a soft-deleted user", Tag: "Users", Auth: authBearer,
+		Response: models.User{}},
+	{Method: "POST", Path: "/api/admin/users/:id/revoke-sessions", Summary: "Invalidate every token issued to a user", Tag: "Users", Auth: authBearer,
+		Response: messageResponse{}},
+	{Method: "PUT", Path: "/api/admin/users/:id/reset-password", Summary: "Reset a user's password", Tag: "Users", Auth: authBearer,
+		Request: handlers.ResetPasswordRequest{}, Response: messageResponse{}},
+	{Method: "POST", Path: "/api/admin/users/:id/impersonate", Summary: "Get a short-lived token that acts as a non-admin user", Tag: "Users", Auth: authBearer,
+		Response: handlers.ImpersonateResponse{}},
+
+	// Projects
+	{Method: "GET", Path: "/api/admin/projects", Summary: "List projects visible to the current user, optionally only one group or only the ones they pinned", Tag: "Projects", Auth: authBearer,
+		Response: struct {
+			Projects []handlers.ProjectListItem `json:"projects"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/projects", Summary: "Create a project", Tag: "Projects", Auth: authBearer,
+		Request: handlers.CreateProjectRequest{}, Response: handlers.CreateProjectResponse{}, Status: "201"},
+	{Method: "GET", Path: "/api/admin/projects/groups", Summary: "List project groups with the number of projects in each", Tag: "Projects", Auth: authBearer,
+		Response: struct {
+			Groups []models.ProjectGroupCount `json:"groups"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/projects/:id", Summary: "Get a project", Tag: "Projects", Auth: authBearer,
+		Response: struct {
+			Project models.Project         `json:"project"`
+			Stats   map[string]interface{} `json:"stats"`
+		}{}},
+	{Method: "PUT", Path: "/api/admin/projects/:id", Summary: "Update a project", Tag: "Projects", Auth: authBearer,
+		Request: handlers.UpdateProjectRequest{}, Response: models.Project{}},
+	{Method: "DELETE", Path: "/api/admin/projects/:id", Summary: "Soft-delete a project, or remove it and its logs permanently with purge=true (admin only)", Tag: "Projects", Auth: authBearer,
+		Response: messageResponse{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/restore", Summary: "Restore a soft-deleted project", Tag: "Projects", Auth: authBearer,
+		Response: models.Project{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/clone", Summary: "Create a project with a copy of this one's settings and channels, without its logs or members", Tag: "Projects", Auth: authBearer,
+		Request: handlers.CloneProjectRequest{}, Response: handlers.CloneProjectResponse{}, Status: "201"},
+	{Method: "POST", Path: "/api/admin/projects/:id/rotate-key", Summary: "Rotate a project's API key", Tag: "Projects", Auth: authBearer,
+		Response: struct {
+			APIKey       string `json:"api_key"`
+			APIKeyPrefix string `json:"api_key_prefix"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/rotate-signing-secret", Summary: "Enable signed ingestion or rotate the signing secret", Tag: "Projects", Auth: authBearer,
+		Response: struct {
+			SigningSecret  string `json:"signing_secret"`
+			SigningEnabled bool   `json:"signing_enabled"`
+		}{}},
+	{Method: "DELETE", Path: "/api/admin/projects/:id/signing-secret", Summary: "Disable signed ingestion", Tag: "Projects", Auth: authBearer,
+		Response: messageResponse{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/pause-ingestion", Summary: "Reject a project's ingestion without changing its API key", Tag: "Projects", Auth: authBearer,
+		Response: models.Project{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/resume-ingestion", Summary: "Accept a paused project's ingestion again", Tag: "Projects", Auth: authBearer,
+		Response: models.Project{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/pin", Summary: "Pin a project for the current user", Tag: "Projects", Auth: authBearer,
+		Response: pinResponse{}},
+	{Method: "DELETE", Path: "/api/admin/projects/:id/pin", Summary: "Unpin a project for the current user", Tag: "Projects", Auth: authBearer,
+		Response: pinResponse{}},
+	{Method: "GET", Path: "/api/admin/projects/:id/keys/usage", Summary: "Get API key usage", Tag: "Projects", Auth: authBearer,
+		Response: struct {
+			Keys []handlers.APIKeyUsageResponse `json:"keys"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/projects/:id/sources", Summary: "List the sources seen in a project, most common first", Tag: "Projects", Auth: authBearer,
+		Response: struct {
+			Sources []models.FacetCount `json:"sources"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/projects/:id/metadata-keys", Summary: "List the top-level metadata keys of a project's newest logs (?sample=, default 1000) with how often each appears", Tag: "Projects", Auth: authBearer,
+		Response: handlers.MetadataKeysResponse{}},
+	{Method: "GET", Path: "/api/admin/projects/:id/archive", Summary: "Download a project's logs between start and end as jsonl.gz or tar.gz (owners)", Tag: "Projects", Auth: authBearer,
+		Download: "application/gzip"},
+
+	// Members
+	{Method: "GET", Path: "/api/admin/projects/:id/members", Summary: "List project members", Tag: "Members", Auth: authBearer,
+		Response: struct {
+			Members []models.UserProject `json:"members"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/members", Summary: "Add a project member", Tag: "Members", Auth: authBearer,
+		Request: handlers.AddMemberRequest{}, Response: models.UserProject{}, Status: "201"},
+	{Method: "PUT", Path: "/api/admin/projects/:id/members/:uid", Summary: "Change a member's role", Tag: "Members", Auth: authBearer,
+		Request: handlers.UpdateMemberRequest{}, Response: messageResponse{}},
+	{Method: "DELETE", Path: "/api/admin/projects/:id/members/:uid", Summary: "Remove a project member", Tag: "Members", Auth: authBearer,
+		Response: messageResponse{}},
+
+	// Channels
+	{Method: "GET", Path: "/api/admin/projects/:id/channels", Summary: "List a project's notification channels", Tag: "Channels", Auth: authBearer,
+		Response: struct {
+			Channels []models.Channel `json:"channels"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/projects/:id/channels/health", Summary: "List a project's channels with recent delivery health", Tag: "Channels", Auth: authBearer,
+		Response: struct {
+			Channels    []handlers.ChannelHealthEntry `json:"channels"`
+			WindowHours int                           `json:"window_hours"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/channels", Summary: "Create a notification channel", Tag: "Channels", Auth: authBearer,
+		Request: handlers.CreateChannelRequest{}, Response: models.Channel{}, Status: "201"},
+	{Method: "POST", Path: "/api/admin/channels/validate", Summary: "Validate a channel configuration without saving it", Tag: "Channels", Auth: authBearer,
+		Request: handlers.ValidateChannelRequest{}, Response: struct {
+			Valid  bool                         `json:"valid"`
+			Errors []handlers.ChannelFieldError `json:"errors"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/channels/:id", Summary: "Get a channel", Tag: "Channels", Auth: authBearer,
+		Response: models.Channel{}},
+	{Method: "PUT", Path: "/api/admin/channels/:id", Summary: "Update a channel", Tag: "Channels", Auth: authBearer,
+		Request: handlers.UpdateChannelRequest{}, Response: models.Channel{}},
+	{Method: "DELETE", Path: "/api/admin/channels/:id", Summary: "Delete a channel", Tag: "Channels", Auth: authBearer,
+		Response: messageResponse{}},
+	{Method: "POST", Path: "/api/admin/channels/:id/test", Summary: "Send a test notification (?mode=direct|queued)", Tag: "Channels", Auth: authBearer,
+		Response: struct {
+			Message      string `json:"message"`
+			Channel      string `json:"channel"`
+			JobID        string `json:"job_id,omitempty"`
+			RemoteStatus int    `json:"remote_status,omitempty"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/channels/:id/test/:jobId", Summary: "Get the outcome of a queued test notification", Tag: "Channels", Auth: authBearer,
+		Response: struct {
+			JobID       string     `json:"job_id"`
+			Status      string     `json:"status"`
+			Error       string     `json:"error,omitempty"`
+			AttemptedAt *time.Time `json:"attempted_at,omitempty"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/channels/:id/failures", Summary: "List dead-lettered notifications", Tag: "Channels", Auth: authBearer,
+		Response: struct {
+			Failures []models.FailedNotification `json:"failures"`
+			Total    int                         `json:"total"`
+			Limit    int                         `json:"limit"`
+			Offset   int                         `json:"offset"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/channels/:id/failures/:failureId/replay", Summary: "Re-queue a dead-lettered notification", Tag: "Channels", Auth: authBearer,
+		Response: messageResponse{}},
+
+	// Alert rules
+	{Method: "GET", Path: "/api/admin/projects/:id/alert-rules", Summary: "List alert rules", Tag: "Alert Rules", Auth: authBearer,
+		Response: struct {
+			AlertRules []models.AlertRule `json:"alert_rules"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/projects/:id/alert-rules", Summary: "Create an alert rule", Tag: "Alert Rules", Auth: authBearer,
+		Request: handlers.AlertRuleRequest{}, Response: models.AlertRule{}, Status: "201"},
+	{Method: "GET", Path: "/api/admin/projects/:id/alert-rules/:ruleId", Summary: "Get an alert rule", Tag: "Alert Rules", Auth: authBearer,
+		Response: models.AlertRule{}},
+	{Method: "PUT", Path: "/api/admin/projects/:id/alert-rules/:ruleId", Summary: "Update an alert rule", Tag: "Alert Rules", Auth: authBearer,
+		Request: handlers.AlertRuleRequest{}, Response: models.AlertRule{}},
+	{Method: "DELETE", Path: "/api/admin/projects/:id/alert-rules/:ruleId", Summary: "Delete an alert rule", Tag: "Alert Rules", Auth: authBearer,
+		Response: messageResponse{}},
+
+	// Logs
+	{Method: "GET", Path: "/api/admin/logs", Summary: "List logs; ?fields= returns only the listed fields of each log", Tag: "Logs", Auth: authBearer,
+		Response: struct {
+			Logs   []models.Log `json:"logs"`
+			Total  int          `json:"total"`
+			Limit  int          `json:"limit"`
+			Offset int          `json:"offset"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/logs/search", Summary: "Search logs across all projects with facets (admin only)", Tag: "Logs", Auth: authBearer,
+		Response: struct {
+			Logs   []models.Log `json:"logs"`
+			Total  int          `json:"total"`
+			Limit  int          `json:"limit"`
+			Offset int          `json:"offset"`
+			Facets struct {
+				Projects []models.FacetCount `json:"projects"`
+				Levels   []models.FacetCount `json:"levels"`
+				Sources  []models.FacetCount `json:"sources"`
+			} `json:"facets"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/logs/count", Summary: "Count logs matching the list filters without fetching them", Tag: "Logs", Auth: authBearer,
+		Response: struct {
+			Total int `json:"total"`
+		}{}},
+	{Method: "POST", Path: "/api/admin/logs/ack", Summary: "Acknowledge every log matching a filter in the accessible projects", Tag: "Logs", Auth: authBearer,
+		Request: handlers.AckLogsRequest{}, Response: handlers.AckLogsResponse{}},
+	{Method: "GET", Path: "/api/admin/logs/recent-errors", Summary: "List the newest ERROR and CRITICAL logs across accessible projects", Tag: "Logs", Auth: authBearer,
+		Response: struct {
+			Logs []models.Log `json:"logs"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/logs/:id", Summary: "Get a log entry", Tag: "Logs", Auth: authBearer,
+		Response: models.Log{}},
+	{Method: "POST", Path: "/api/admin/logs/:id/notify", Summary: "Queue a log's notifications again for the project's channels that would send it, or one channel_id (owner/member)", Tag: "Logs", Auth: authBearer,
+		Request: handlers.ResendNotificationsRequest{}, Response: handlers.ResendNotificationsResponse{}},
+	{Method: "GET", Path: "/api/admin/sources", Summary: "List the sources seen across accessible projects, most common first", Tag: "Logs", Auth: authBearer,
+		Response: struct {
+			Sources []models.FacetCount `json:"sources"`
+		}{}},
+
+	// Stats
+	{Method: "GET", Path: "/api/admin/stats/overview", Summary: "Get overview statistics", Tag: "Stats", Auth: authBearer,
+		Response: map[string]interface{}{}},
+	{Method: "GET", Path: "/api/admin/stats/projects/:id", Summary: "Get statistics for a project", Tag: "Stats", Auth: authBearer,
+		Response: struct {
+			Project     models.Project `json:"project"`
+			TotalLogs   int            `json:"total_logs"`
+			LogsByLevel map[string]int `json:"logs_by_level"`
+		}{}},
+
+	// System
+	{Method: "GET", Path: "/api/admin/system/migrations", Summary: "List database migrations and when they were applied (admin only)", Tag: "System", Auth: authBearer,
+		Response: struct {
+			Migrations []database.MigrationState `json:"migrations"`
+			Pending    int                       `json:"pending"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/system/ingestion", Summary: "Show the post-ingestion fan-out pool load, dropped fan-out count and async buffer backlog (admin only)", Tag: "System", Auth: authBearer,
+		Response: handlers.IngestionStatusResponse{}},
+	{Method: "GET", Path: "/api/admin/system/redis", Summary: "Show whether Redis is reachable and its failed calls by operation (admin only)", Tag: "System", Auth: authBearer,
+		Response: queue.RedisHealth{}},
+	{Method: "POST", Path: "/api/admin/system/vacuum", Summary: "Compact the database and refresh its statistics now, reporting the bytes reclaimed (admin only)", Tag: "System", Auth: authBearer,
+		Response: database.MaintenanceResult{}},
+	{Method: "GET", Path: "/api/admin/system/db-stats", Summary: "Show row counts of the main tables, database and WAL file sizes, and the indexes on logs (admin only)", Tag: "System", Auth: authBearer,
+		Response: database.Stats{}},
+	{Method: "POST", Path: "/api/admin/system/maintenance", Summary: "Turn maintenance mode on or off; while on, log ingestion answers 503 with Retry-After (admin only)", Tag: "System", Auth: authBearer,
+		Request: handlers.MaintenanceModeRequest{},
+		Response: struct {
+			Enabled bool `json:"enabled"`
+		}{}},
+	{Method: "GET", Path: "/api/admin/audit", Summary: "List audited administrative actions, filterable by actor and action (admin only)", Tag: "System", Auth: authBearer,
+		Response: struct {
+			AuditLogs []models.AuditLog `json:"audit_logs"`
+			Total     int               `json:"total"`
+			Limit     int               `json:"limit"`
+			Offset    int               `json:"offset"`
+		}{}},
+}
+
+// Document is the root of an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                            `json:"openapi"`
+	Info       map[string]interface{}            `json:"info"`
+	Paths      map[string]map[string]interface{} `json:"paths"`
+	Components map[string]interface{}            `json:"components"`
+}
+
+// Build generates the OpenAPI document for the given server version
+func Build(version string) *Document {
+	registry := newSchemaRegistry()
+	errorSchema := registry.schemaOf(errorResponse{})
+
+	paths := make(map[string]map[string]interface{})
+	for _, op := range operations {
+		path, params := convertPath(op.Path)
+
+		status := op.Status
+		if status == "" {
+			status = "200"
+		}
+
+		responses := map[string]interface{}{
+			status: jsonContent("Success", registry.schemaOf(op.Response)),
+			"400":  jsonContent("Invalid request", errorSchema),
+		}
+		if op.Download != "" {
+			responses[status] = map[string]interface{}{
+				"description": "Success",
+				"content": map[string]interface{}{
+					op.Download: map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+				},
+			}
+		}
+		if op.Auth != authNone {
+			responses["401"] = jsonContent("Missing or invalid credentials", errorSchema)
+		}
+
+		item := map[string]interface{}{
+			"summary":   op.Summary,
+			"tags":      []string{op.Tag},
+			"responses": responses,
+		}
+		if len(params) > 0 {
+			item["parameters"] = params
+		}
+		if op.Upload != "" {
+			item["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					op.Upload: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				},
+			}
+		} else if op.Request != nil {
+			body := jsonContent("", registry.schemaOf(op.Request))
+			delete(body, "description")
+			body["required"] = true
+			item["requestBody"] = body
+		}
+		if op.Auth != authNone {
+			item["security"] = []map[string][]string{{op.Auth: {}}}
+		}
+
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(op.Method)] = item
+	}
+
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info: map[string]interface{}{
+			"title":       "Central Logs API",
+			"description": "Log ingestion and administration API for Central Logs",
+			"version":     version,
+		},
+		Paths: paths,
+		Components: map[string]interface{}{
+			"schemas": registry.schemas,
+			"securitySchemes": map[string]interface{}{
+				authAPIKey: map[string]interface{}{
+					"type": "apiKey",
+					"in":   "header",
+					"name": "X-API-Key",
+				},
+				authBearer: map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+// convertPath rewrites Fiber's :param segments to OpenAPI {param} templates
+// and returns the matching path parameter definitions
+func convertPath(path string) (string, []map[string]interface{}) {
+	segments := strings.Split(path, "/")
+	var params []map[string]interface{}
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimPrefix(segment, ":")
+		segments[i] = "{" + name + "}"
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func jsonContent(description string, schema map[string]interface{}) map[string]interface{} {
+	if schema == nil {
+		schema = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
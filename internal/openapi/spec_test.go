@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBuild_IncludesIngestionAndSchemas(t *testing.T) {
+	doc := Build("1.2.3")
+
+	if doc.Info["version"] != "1.2.3" {
+		t.Errorf("Expected version 1.2.3, got %v", doc.Info["version"])
+	}
+
+	ingest, ok := doc.Paths["/api/v1/logs"]["post"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected POST /api/v1/logs in spec")
+	}
+	security := ingest["security"].([]map[string][]string)
+	if _, ok := security[0][authAPIKey]; !ok {
+		t.Errorf("Expected ingestion to use API key auth, got %v", security)
+	}
+
+	if _, ok := doc.Paths["/api/admin/projects/{id}/alert-rules/{ruleId}"]; !ok {
+		t.Error("Expected Fiber params to be converted to OpenAPI templates")
+	}
+
+	schemas := doc.Components["schemas"].(map[string]interface{})
+	createLog, ok := schemas["CreateLogRequest"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected CreateLogRequest schema")
+	}
+	props := createLog["properties"].(map[string]interface{})
+	for _, field := range []string{"level", "message", "metadata"} {
+		if _, ok := props[field]; !ok {
+			t.Errorf("Expected CreateLogRequest.%s in schema", field)
+		}
+	}
+
+	user := schemas["User"].(map[string]interface{})
+	if _, ok := user["properties"].(map[string]interface{})["password"]; ok {
+		t.Error("Fields tagged json:\"-\" must not appear in the schema")
+	}
+}
+
+func TestHandler_ServesSpecAndDocs(t *testing.T) {
+	h, err := NewHandler("dev")
+	if err != nil {
+		t.Fatalf("NewHandler failed: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/api/openapi.json", h.Spec)
+	app.Get("/api/docs", h.Docs)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("Spec is not valid JSON: %v", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("Expected openapi 3.0.3, got %v", doc["openapi"])
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,170 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnavailable is returned, without contacting Redis, while the health
+// check has it marked down. Realtime streaming, notification queueing and
+// rate limiting pause until Redis answers pings again.
+var ErrUnavailable = errors.New("redis is unavailable; waiting for the health check to see it recover")
+
+// redisHealth tracks whether Redis is reachable and counts failed calls
+type redisHealth struct {
+	down atomic.Bool // Zero value is up, so a client is usable before its first check
+
+	mu          sync.Mutex
+	since       time.Time        // When Redis last went up or down
+	errors      map[string]int64 // Failed calls by operation
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// RedisHealth is a snapshot of the connection's health
+type RedisHealth struct {
+	Available   bool             `json:"available"`
+	Since       time.Time        `json:"since"`  // When Redis last went up or down
+	Errors      map[string]int64 `json:"errors"` // Failed calls by operation since start, including those refused while down
+	LastError   string           `json:"last_error,omitempty"`
+	LastErrorAt *time.Time       `json:"last_error_at,omitempty"`
+}
+
+// Available reports whether Redis answered the last health check
+func (r *RedisClient) Available() bool {
+	return !r.health.down.Load()
+}
+
+// Health returns the connection's state and error counts
+func (r *RedisClient) Health() RedisHealth {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	h := RedisHealth{
+		Available: r.Available(),
+		Since:     r.health.since,
+		Errors:    make(map[string]int64, len(r.health.errors)),
+		LastError: r.health.lastError,
+	}
+	for op, n := range r.health.errors {
+		h.Errors[op] = n
+	}
+	if !r.health.lastErrorAt.IsZero() {
+		at := r.health.lastErrorAt
+		h.LastErrorAt = &at
+	}
+	return h
+}
+
+// setAvailable records the result of a health check and reports whether it
+// changed the state
+func (r *RedisClient) setAvailable(available bool) bool {
+	if r.health.down.Swap(!available) == !available {
+		return false
+	}
+	r.health.mu.Lock()
+	r.health.since = time.Now()
+	r.health.mu.Unlock()
+	return true
+}
+
+// guard refuses op while Redis is marked down
+func (r *RedisClient) guard(op string) error {
+	if r.health.down.Load() {
+		return r.fail(op, ErrUnavailable)
+	}
+	return nil
+}
+
+// fail counts a failed op and names it in the returned error. A nil error or
+// a redis.Nil miss passes through uncounted.
+func (r *RedisClient) fail(op string, err error) error {
+	if err == nil || err == redis.Nil {
+		return err
+	}
+	err = fmt.Errorf("redis %s: %w", op, err)
+
+	r.health.mu.Lock()
+	if r.health.errors == nil {
+		r.health.errors = make(map[string]int64)
+	}
+	r.health.errors[op]++
+	r.health.lastError = err.Error()
+	r.health.lastErrorAt = time.Now()
+	r.health.mu.Unlock()
+
+	return err
+}
+
+// HealthChecker pings Redis on an interval, marking the client down when a
+// ping fails and up again once one succeeds, and logs each transition
+type HealthChecker struct {
+	client   *RedisClient
+	interval time.Duration
+	ping     func(ctx context.Context) error
+	stopChan chan struct{}
+}
+
+// NewHealthChecker creates a checker for client that pings every interval
+func NewHealthChecker(client *RedisClient, interval time.Duration) *HealthChecker {
+	return &HealthChecker{
+		client:   client,
+		interval: interval,
+		ping: func(ctx context.Context) error {
+			return client.client.Ping(ctx).Err()
+		},
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the checks in the background until Stop is called
+func (h *HealthChecker) Start() {
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.stopChan:
+				return
+			case <-ticker.C:
+				h.check()
+			}
+		}
+	}()
+}
+
+// Stop ends the checks
+func (h *HealthChecker) Stop() {
+	close(h.stopChan)
+}
+
+// check pings once and applies the result
+func (h *HealthChecker) check() {
+	timeout := h.interval
+	if timeout > 2*time.Second {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := h.ping(ctx)
+	if err != nil {
+		h.client.fail("ping", err)
+	}
+	if !h.client.setAvailable(err == nil) {
+		return
+	}
+	if err != nil {
+		log.Printf("[Redis] Connection lost: %v. Realtime streaming, notifications and rate limiting are paused until it recovers", err)
+	} else {
+		log.Println("[Redis] Connection restored; realtime streaming, notifications and rate limiting resumed")
+	}
+}
@@ -0,0 +1,67 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestHealthChecker_PausesCallsWhileRedisIsDown(t *testing.T) {
+	// Nothing listens on port 1, so every call is refused
+	client := &RedisClient{client: redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 200 * time.Millisecond,
+		MaxRetries:  -1,
+	})}
+	defer client.Close()
+
+	ctx := context.Background()
+	job := &NotificationJob{LogID: "log-1", ChannelID: "ch-1"}
+
+	// Before the first check a failed call is attempted and reported
+	err := client.EnqueueNotification(ctx, job)
+	if err == nil || errors.Is(err, ErrUnavailable) || !strings.HasPrefix(err.Error(), "redis enqueue: ") {
+		t.Fatalf("Expected the connection error named after the operation, got %v", err)
+	}
+
+	checker := NewHealthChecker(client, time.Second)
+	checker.check()
+	if client.Available() {
+		t.Fatal("Expected a failed ping to mark Redis down")
+	}
+
+	// While down, calls fail fast without touching the network
+	start := time.Now()
+	if err := client.PublishLog(ctx, "p1", map[string]string{"message": "hi"}); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected ErrUnavailable while down, got %v", err)
+	}
+	if _, err := client.RateLimiter().AllowChannel(ctx, "ch-1", 10); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected the rate limiter to pause while down, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected refused calls to return at once, took %s", elapsed)
+	}
+
+	health := client.Health()
+	if health.Available || health.Errors["enqueue"] != 1 || health.Errors["publish"] != 1 || health.Errors["rate limit"] != 1 || health.Errors["ping"] != 1 {
+		t.Errorf("Expected each failure counted by operation, got %+v", health)
+	}
+	if health.LastError == "" || health.LastErrorAt == nil {
+		t.Errorf("Expected the last error to be recorded, got %+v", health)
+	}
+
+	// The next successful ping re-enables everything
+	downSince := health.Since
+	checker.ping = func(context.Context) error { return nil }
+	checker.check()
+	if !client.Available() {
+		t.Fatal("Expected a successful ping to mark Redis up")
+	}
+	if !client.Health().Since.After(downSince) {
+		t.Error("Expected the recovery time to be recorded")
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
 type RedisClient struct {
 	client *redis.Client
+	health redisHealth
 }
 
 func NewRedisClient(url string) (*RedisClient, error) {
@@ -28,7 +32,7 @@ func NewRedisClient(url string) (*RedisClient, error) {
 		return nil, err
 	}
 
-	return &RedisClient{client: client}, nil
+	return &RedisClient{client: client, health: redisHealth{since: time.Now()}}, nil
 }
 
 func (r *RedisClient) Close() error {
@@ -47,7 +51,10 @@ func (r *RedisClient) PublishLog(ctx context.Context, projectID string, data int
 	if err != nil {
 		return err
 	}
-	return r.client.Publish(ctx, channel, jsonData).Err()
+	if err := r.guard("publish"); err != nil {
+		return err
+	}
+	return r.fail("publish", r.client.Publish(ctx, channel, jsonData).Err())
 }
 
 func (r *RedisClient) SubscribeLogs(ctx context.Context, projectIDs ...string) *redis.PubSub {
@@ -66,23 +73,39 @@ func (r *RedisClient) PSubscribeLogs(ctx context.Context) *redis.PubSub {
 
 type RateLimiter struct {
 	client *redis.Client
+	owner  *RedisClient // Set by RedisClient.RateLimiter, to share its health state
 }
 
 func NewRateLimiter(client *redis.Client) *RateLimiter {
 	return &RateLimiter{client: client}
 }
 
+// RateLimiter returns a limiter on this connection that refuses to run while
+// Redis is marked down and counts its failures with the client's
+func (r *RedisClient) RateLimiter() *RateLimiter {
+	return &RateLimiter{client: r.client, owner: r}
+}
+
 // Allow checks if the action is allowed within the rate limit
 // Returns (allowed, remaining, resetTime)
 func (rl *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Time, error) {
 	now := time.Now()
 	windowKey := fmt.Sprintf("%s:%d", key, now.Unix()/int64(window.Seconds()))
 
+	if rl.owner != nil {
+		if err := rl.owner.guard("rate limit"); err != nil {
+			return false, 0, time.Time{}, err
+		}
+	}
+
 	pipe := rl.client.Pipeline()
 	incr := pipe.Incr(ctx, windowKey)
 	pipe.Expire(ctx, windowKey, window)
 	_, err := pipe.Exec(ctx)
 	if err != nil {
+		if rl.owner != nil {
+			err = rl.owner.fail("rate limit", err)
+		}
 		return false, 0, time.Time{}, err
 	}
 
@@ -104,15 +127,19 @@ func (rl *RateLimiter) AllowChannel(ctx context.Context, channelID string, limit
 	return allowed, err
 }
 
-// AllowAPI checks rate limit for API requests per project
-func (rl *RateLimiter) AllowAPI(ctx context.Context, projectID string, limit int) (bool, int, time.Time, error) {
+// AllowAPI takes a token from the project's ingestion bucket
+func (rl *RateLimiter) AllowAPI(ctx context.Context, projectID string, bucket TokenBucket) (BucketResult, error) {
 	key := fmt.Sprintf("ratelimit:api:%s", projectID)
-	return rl.Allow(ctx, key, limit, time.Minute)
+	return rl.AllowBucket(ctx, key, bucket)
 }
 
 // Notification Queue
 
 type NotificationJob struct {
+	JobID     string `json:"job_id,omitempty"` // Set on enqueue; delivery outcomes are recorded under it
+	Test      bool   `json:"test,omitempty"`   // A channel test: sent from the job fields, no stored log
+	Alert     bool   `json:"alert,omitempty"`  // An alert rule firing: sent from the job fields, no stored log
+	Digest    bool   `json:"digest,omitempty"` // A digest summary being retried: sent from the job fields, no stored log
 	LogID     string `json:"log_id"`
 	ChannelID string `json:"channel_id"`
 	ProjectID string `json:"project_id"`
@@ -120,25 +147,47 @@ type NotificationJob struct {
 	Message   string `json:"message"`
 	Source    string `json:"source"`
 	Timestamp string `json:"timestamp"`
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+
+	Metadata map[string]interface{} `json:"metadata,omitempty"` // Only set on alert jobs
 }
 
-const notificationQueue = "notifications:queue"
+const (
+	notificationQueue      = "notifications:queue"
+	notificationRetryQueue = "notifications:retry"
+	notificationDeadLetter = "notifications:dead"
+	notificationDigestKey  = "notifications:digest"
+	notificationDigestDue  = "notifications:digest:pending"
+
+	// maxDeadLetterLength caps the Redis dead-letter list; the database keeps the full history
+	maxDeadLetterLength = 1000
+)
 
 func (r *RedisClient) EnqueueNotification(ctx context.Context, job *NotificationJob) error {
+	if job.JobID == "" {
+		job.JobID = uuid.New().String()
+	}
 	data, err := json.Marshal(job)
 	if err != nil {
 		return err
 	}
-	return r.client.LPush(ctx, notificationQueue, data).Err()
+	if err := r.guard("enqueue"); err != nil {
+		return err
+	}
+	return r.fail("enqueue", r.client.LPush(ctx, notificationQueue, data).Err())
 }
 
 func (r *RedisClient) DequeueNotification(ctx context.Context, timeout time.Duration) (*NotificationJob, error) {
+	if err := r.guard("dequeue"); err != nil {
+		return nil, err
+	}
 	result, err := r.client.BRPop(ctx, timeout, notificationQueue).Result()
 	if err == redis.Nil {
 		return nil, nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, r.fail("dequeue", err)
 	}
 
 	var job NotificationJob
@@ -150,5 +199,240 @@ func (r *RedisClient) DequeueNotification(ctx context.Context, timeout time.Dura
 }
 
 func (r *RedisClient) GetQueueLength(ctx context.Context) (int64, error) {
-	return r.client.LLen(ctx, notificationQueue).Result()
+	if err := r.guard("queue length"); err != nil {
+		return 0, err
+	}
+	n, err := r.client.LLen(ctx, notificationQueue).Result()
+	return n, r.fail("queue length", err)
+}
+
+// ScheduleNotificationRetry stores a failed job until its retry time is due
+func (r *RedisClient) ScheduleNotificationRetry(ctx context.Context, job *NotificationJob, at time.Time) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := r.guard("schedule retry"); err != nil {
+		return err
+	}
+	return r.fail("schedule retry", r.client.ZAdd(ctx, notificationRetryQueue, redis.Z{
+		Score:  float64(at.UnixMilli()),
+		Member: data,
+	}).Err())
+}
+
+// PromoteDueRetries moves retry jobs whose time has come back onto the main queue
+func (r *RedisClient) PromoteDueRetries(ctx context.Context, now time.Time) (int, error) {
+	if err := r.guard("promote retries"); err != nil {
+		return 0, err
+	}
+	due, err := r.client.ZRangeByScore(ctx, notificationRetryQueue, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", now.UnixMilli()),
+	}).Result()
+	if err != nil {
+		return 0, r.fail("promote retries", err)
+	}
+
+	promoted := 0
+	for _, data := range due {
+		// Only the caller that removes the member may requeue it, so
+		// concurrent promoters never duplicate a job
+		removed, err := r.client.ZRem(ctx, notificationRetryQueue, data).Result()
+		if err != nil {
+			return promoted, r.fail("promote retries", err)
+		}
+		if removed == 0 {
+			continue
+		}
+		if err := r.client.LPush(ctx, notificationQueue, data).Err(); err != nil {
+			return promoted, r.fail("promote retries", err)
+		}
+		promoted++
+	}
+
+	return promoted, nil
+}
+
+// DeadLetterNotification records a job that exhausted its retries
+func (r *RedisClient) DeadLetterNotification(ctx context.Context, job *NotificationJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	if err := r.guard("dead-letter"); err != nil {
+		return err
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.LPush(ctx, notificationDeadLetter, data)
+	pipe.LTrim(ctx, notificationDeadLetter, 0, maxDeadLetterLength-1)
+	_, err = pipe.Exec(ctx)
+	return r.fail("dead-letter", err)
+}
+
+// DigestBatch is the set of jobs coalesced for one channel over one window
+type DigestBatch struct {
+	ChannelID   string
+	WindowStart time.Time
+	Jobs        []*NotificationJob
+}
+
+// AddToDigest appends a job to the channel's digest for the window starting at
+// windowStart and schedules that window to be flushed at windowEnd
+func (r *RedisClient) AddToDigest(ctx context.Context, channelID string, job *NotificationJob, windowStart, windowEnd time.Time) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s:%s:%d", notificationDigestKey, channelID, windowStart.Unix())
+	if err := r.guard("add to digest"); err != nil {
+		return err
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.RPush(ctx, key, data)
+	// Keep the list around long enough to be flushed even if the flusher is briefly down
+	pipe.Expire(ctx, key, windowEnd.Sub(windowStart)+time.Hour)
+	pipe.ZAddNX(ctx, notificationDigestDue, redis.Z{
+		Score:  float64(windowEnd.UnixMilli()),
+		Member: key,
+	})
+	_, err = pipe.Exec(ctx)
+	return r.fail("add to digest", err)
+}
+
+// PopDueDigests removes and returns every digest whose window has ended
+func (r *RedisClient) PopDueDigests(ctx context.Context, now time.Time) ([]*DigestBatch, error) {
+	if err := r.guard("pop digests"); err != nil {
+		return nil, err
+	}
+	keys, err := r.client.ZRangeByScore(ctx, notificationDigestDue, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", now.UnixMilli()),
+	}).Result()
+	if err != nil {
+		return nil, r.fail("pop digests", err)
+	}
+
+	var batches []*DigestBatch
+	for _, key := range keys {
+		// Whoever removes the pending entry owns the flush
+		removed, err := r.client.ZRem(ctx, notificationDigestDue, key).Result()
+		if err != nil {
+			return batches, r.fail("pop digests", err)
+		}
+		if removed == 0 {
+			continue
+		}
+
+		var items *redis.StringSliceCmd
+		_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			items = pipe.LRange(ctx, key, 0, -1)
+			pipe.Del(ctx, key)
+			return nil
+		})
+		if err != nil {
+			return batches, r.fail("pop digests", err)
+		}
+
+		batch := &DigestBatch{}
+		parts := strings.Split(strings.TrimPrefix(key, notificationDigestKey+":"), ":")
+		if len(parts) == 2 {
+			batch.ChannelID = parts[0]
+			if start, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+				batch.WindowStart = time.Unix(start, 0)
+			}
+		}
+
+		for _, item := range items.Val() {
+			var job NotificationJob
+			if err := json.Unmarshal([]byte(item), &job); err != nil {
+				continue
+			}
+			batch.Jobs = append(batch.Jobs, &job)
+		}
+
+		if batch.ChannelID != "" && len(batch.Jobs) > 0 {
+			batches = append(batches, batch)
+		}
+	}
+
+	return batches, nil
+}
+
+// API key usage counters
+
+func apiKeyUsageKey(projectID, keyPrefix string, hour int64) string {
+	return fmt.Sprintf("apikey:usage:%s:%s:%d", projectID, keyPrefix, hour)
+}
+
+// RecordAPIKeyUsage increments the hourly request counter for a key
+func (r *RedisClient) RecordAPIKeyUsage(ctx context.Context, projectID, keyPrefix string, at time.Time) error {
+	key := apiKeyUsageKey(projectID, keyPrefix, at.Unix()/3600)
+	if err := r.guard("record key usage"); err != nil {
+		return err
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 25*time.Hour)
+	_, err := pipe.Exec(ctx)
+	return r.fail("record key usage", err)
+}
+
+// CountAPIKeyUsage24h sums a key's hourly counters over the last 24 hours
+func (r *RedisClient) CountAPIKeyUsage24h(ctx context.Context, projectID, keyPrefix string, now time.Time) (int64, error) {
+	currentHour := now.Unix() / 3600
+	keys := make([]string, 24)
+	for i := range keys {
+		keys[i] = apiKeyUsageKey(projectID, keyPrefix, currentHour-int64(i))
+	}
+
+	if err := r.guard("count key usage"); err != nil {
+		return 0, err
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return 0, r.fail("count key usage", err)
+	}
+
+	var total int64
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			total += n
+		}
+	}
+	return total, nil
+}
+
+// Stats cache
+
+// GetStatsCache returns a cached stats payload; ok is false on a miss
+func (r *RedisClient) GetStatsCache(ctx context.Context, key string) (data []byte, ok bool, err error) {
+	if err := r.guard("get stats cache"); err != nil {
+		return nil, false, err
+	}
+	data, err = r.client.Get(ctx, "stats:"+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, r.fail("get stats cache", err)
+	}
+	return data, true, nil
+}
+
+// SetStatsCache stores a stats payload that expires after ttl
+func (r *RedisClient) SetStatsCache(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	if err := r.guard("set stats cache"); err != nil {
+		return err
+	}
+	return r.fail("set stats cache", r.client.Set(ctx, "stats:"+key, data, ttl).Err())
 }
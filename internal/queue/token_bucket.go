@@ -0,0 +1,145 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenBucket is a rate limit that refills continuously at RatePerMinute and
+// holds up to Burst tokens, so a client that has been quiet can briefly go
+// faster than the sustained rate. Each request spends one token.
+type TokenBucket struct {
+	RatePerMinute int
+	Burst         int
+}
+
+// BucketResult is the outcome of taking a token
+type BucketResult struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left after this request
+	RetryAfter time.Duration // Until a token is available; zero when allowed
+	ResetAfter time.Duration // Until the bucket is full again
+}
+
+// bucketState is a bucket's stored fill level
+type bucketState struct {
+	tokens  float64
+	updated time.Time
+}
+
+// bucketTxRetries bounds how often a take is retried when another request
+// changed the same bucket mid-transaction
+const bucketTxRetries = 10
+
+// take refills state for the time elapsed until now and spends a token if
+// one is available. A zero state is a full bucket.
+func (b TokenBucket) take(state bucketState, now time.Time) (bucketState, BucketResult) {
+	burst := float64(b.Burst)
+	perSecond := float64(b.RatePerMinute) / 60
+
+	tokens := burst
+	if !state.updated.IsZero() {
+		elapsed := now.Sub(state.updated).Seconds()
+		if elapsed < 0 {
+			elapsed = 0 // Clocks on different instances disagree slightly
+		}
+		tokens = math.Min(burst, state.tokens+elapsed*perSecond)
+	}
+
+	result := BucketResult{}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else if perSecond > 0 {
+		result.RetryAfter = secondsDuration((1 - tokens) / perSecond)
+	}
+
+	result.Remaining = int(tokens)
+	if perSecond > 0 {
+		result.ResetAfter = secondsDuration((burst - tokens) / perSecond)
+	}
+	return bucketState{tokens: tokens, updated: now}, result
+}
+
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// AllowBucket takes a token from the bucket stored under key. The read and
+// write run in a WATCH transaction, so concurrent requests across instances
+// can't spend the same token.
+func (rl *RateLimiter) AllowBucket(ctx context.Context, key string, bucket TokenBucket) (BucketResult, error) {
+	if rl.owner != nil {
+		if err := rl.owner.guard("rate limit"); err != nil {
+			return BucketResult{}, err
+		}
+	}
+
+	// Keep an idle bucket around until it would have refilled anyway
+	ttl := time.Minute
+	if bucket.RatePerMinute > 0 {
+		ttl += time.Duration(float64(bucket.Burst) / float64(bucket.RatePerMinute) * float64(time.Minute))
+	}
+
+	var result BucketResult
+	txn := func(tx *redis.Tx) error {
+		fields, err := tx.HMGet(ctx, key, "tokens", "updated").Result()
+		if err != nil {
+			return err
+		}
+		state := parseBucketState(fields)
+
+		var next bucketState
+		next, result = bucket.take(state, time.Now())
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "tokens", strconv.FormatFloat(next.tokens, 'f', -1, 64), "updated", next.updated.UnixNano())
+			pipe.Expire(ctx, key, ttl)
+			return nil
+		})
+		return err
+	}
+
+	var err error
+	for i := 0; i < bucketTxRetries; i++ {
+		err = rl.client.Watch(ctx, txn, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, redis.TxFailedErr) {
+			err = fmt.Errorf("bucket %s stayed contended after %d attempts", key, bucketTxRetries)
+		}
+		if rl.owner != nil {
+			err = rl.owner.fail("rate limit", err)
+		}
+		return BucketResult{}, err
+	}
+	return result, nil
+}
+
+// parseBucketState reads HMGET tokens, updated; a missing or unreadable
+// bucket is treated as new
+func parseBucketState(fields []interface{}) bucketState {
+	if len(fields) != 2 {
+		return bucketState{}
+	}
+	tokensStr, ok1 := fields[0].(string)
+	updatedStr, ok2 := fields[1].(string)
+	if !ok1 || !ok2 {
+		return bucketState{}
+	}
+	tokens, err1 := strconv.ParseFloat(tokensStr, 64)
+	updated, err2 := strconv.ParseInt(updatedStr, 10, 64)
+	if err1 != nil || err2 != nil {
+		return bucketState{}
+	}
+	return bucketState{tokens: tokens, updated: time.Unix(0, updated)}
+}
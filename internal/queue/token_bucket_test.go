@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_AllowsBurstThenSustainedRate(t *testing.T) {
+	bucket := TokenBucket{RatePerMinute: 60, Burst: 5}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A new bucket is full, so the whole burst goes through at once
+	var state bucketState
+	var result BucketResult
+	for i := 0; i < 5; i++ {
+		state, result = bucket.take(state, now)
+		if !result.Allowed {
+			t.Fatalf("Expected request %d of the burst to be allowed", i+1)
+		}
+		if result.Remaining != 4-i {
+			t.Errorf("Expected %d tokens left, got %d", 4-i, result.Remaining)
+		}
+	}
+
+	state, result = bucket.take(state, now)
+	if result.Allowed {
+		t.Fatal("Expected the request after the burst to be refused")
+	}
+	if result.RetryAfter != time.Second {
+		t.Errorf("Expected to retry after one token's refill (1s), got %s", result.RetryAfter)
+	}
+	if result.ResetAfter != 5*time.Second {
+		t.Errorf("Expected the bucket to be full again in 5s, got %s", result.ResetAfter)
+	}
+
+	// Half a token is not enough
+	state, result = bucket.take(state, now.Add(500*time.Millisecond))
+	if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("Expected a refusal with 500ms to wait, got %+v", result)
+	}
+
+	// After that, one request per second at the sustained rate
+	for i := 1; i <= 3; i++ {
+		state, result = bucket.take(state, now.Add(time.Duration(i)*time.Second))
+		if !result.Allowed || result.Remaining != 0 {
+			t.Errorf("Expected the refilled token at %ds to be spent, got %+v", i, result)
+		}
+	}
+}
+
+func TestTokenBucket_RefillStopsAtBurst(t *testing.T) {
+	bucket := TokenBucket{RatePerMinute: 600, Burst: 3}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var state bucketState
+	for i := 0; i < 3; i++ {
+		state, _ = bucket.take(state, now)
+	}
+
+	// An hour idle refills far more than the burst, but the bucket caps it
+	later := now.Add(time.Hour)
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		var result BucketResult
+		state, result = bucket.take(state, later)
+		if result.Allowed {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("Expected only the burst of 3 after a long idle, got %d", allowed)
+	}
+
+	// A clock behind the stored time neither refills nor drains the bucket
+	if _, result := bucket.take(bucketState{tokens: 1.5, updated: later}, later.Add(-time.Minute)); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Expected a skewed clock to use the stored tokens, got %+v", result)
+	}
+}
+
+func TestParseBucketState(t *testing.T) {
+	updated := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	state := parseBucketState([]interface{}{"2.5", "1735732800000000000"})
+	if state.tokens != 2.5 || !state.updated.Equal(updated) {
+		t.Errorf("Expected 2.5 tokens at %v, got %+v", updated, state)
+	}
+
+	// Missing fields mean a new, full bucket
+	if state := parseBucketState([]interface{}{nil, nil}); !state.updated.IsZero() {
+		t.Errorf("Expected a zero state for a missing bucket, got %+v", state)
+	}
+}
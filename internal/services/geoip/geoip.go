@@ -0,0 +1,119 @@
+package geoip
+
+import "net"
+
+// Location is what a resolver knows about an IP address. Fields the database
+// does not carry are left empty.
+type Location struct {
+	Country     string // ISO 3166-1 alpha-2 code
+	CountryName string
+	City        string
+	ASN         uint64
+	ASOrg       string
+}
+
+// Empty reports whether the lookup found nothing useful
+func (l *Location) Empty() bool {
+	return l == nil || (l.Country == "" && l.CountryName == "" && l.City == "" && l.ASN == 0 && l.ASOrg == "")
+}
+
+// Resolver finds the location of an IP address. A nil Location with a nil
+// error means the address is not in the database.
+type Resolver interface {
+	Lookup(ip net.IP) (*Location, error)
+}
+
+// LookupLocation reads the GeoLite2/GeoIP2 Country, City and ASN fields of
+// the record for ip
+func (r *Reader) LookupLocation(ip net.IP) (*Location, error) {
+	record, err := r.Lookup(ip)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	loc := &Location{}
+	if country, ok := record["country"].(map[string]interface{}); ok {
+		loc.Country, _ = country["iso_code"].(string)
+		loc.CountryName = englishName(country)
+	}
+	if city, ok := record["city"].(map[string]interface{}); ok {
+		loc.City = englishName(city)
+	}
+	loc.ASN = toUint(record["autonomous_system_number"])
+	loc.ASOrg, _ = record["autonomous_system_organization"].(string)
+	return loc, nil
+}
+
+func englishName(entity map[string]interface{}) string {
+	names, _ := entity["names"].(map[string]interface{})
+	name, _ := names["en"].(string)
+	return name
+}
+
+// readerResolver adapts a Reader to the Resolver interface
+type readerResolver struct {
+	reader *Reader
+}
+
+func (r readerResolver) Lookup(ip net.IP) (*Location, error) {
+	return r.reader.LookupLocation(ip)
+}
+
+// NewResolver opens one or more MaxMind DB files, typically a Country or City
+// database plus an ASN database, and merges their answers
+func NewResolver(paths ...string) (Resolver, error) {
+	var resolvers multiResolver
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		reader, err := Open(path)
+		if err != nil {
+			return nil, err
+		}
+		resolvers = append(resolvers, readerResolver{reader})
+	}
+	if len(resolvers) == 1 {
+		return resolvers[0], nil
+	}
+	return resolvers, nil
+}
+
+// multiResolver fills each Location field from the first resolver that has it
+type multiResolver []Resolver
+
+func (m multiResolver) Lookup(ip net.IP) (*Location, error) {
+	var merged Location
+	var firstErr error
+	for _, resolver := range m {
+		loc, err := resolver.Lookup(ip)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if loc == nil {
+			continue
+		}
+		if merged.Country == "" {
+			merged.Country = loc.Country
+		}
+		if merged.CountryName == "" {
+			merged.CountryName = loc.CountryName
+		}
+		if merged.City == "" {
+			merged.City = loc.City
+		}
+		if merged.ASN == 0 {
+			merged.ASN = loc.ASN
+		}
+		if merged.ASOrg == "" {
+			merged.ASOrg = loc.ASOrg
+		}
+	}
+	if merged.Empty() {
+		return nil, firstErr
+	}
+	return &merged, nil
+}
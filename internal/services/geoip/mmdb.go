@@ -0,0 +1,364 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+)
+
+// metadataMarker precedes the metadata map at the end of a MaxMind DB file
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator is the run of zero bytes between the search tree and
+// the data section
+const dataSectionSeparator = 16
+
+// Reader looks up networks in a MaxMind DB (.mmdb) file such as GeoLite2
+// Country, City or ASN. The whole file is held in memory.
+type Reader struct {
+	DatabaseType string // From the file's metadata, e.g. "GeoLite2-City"
+
+	buf        []byte
+	data       []byte // Data section
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint // Node reached after the 96 zero bits of an IPv4-mapped address
+}
+
+// Open loads a MaxMind DB file
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReader(buf)
+}
+
+// NewReader parses a MaxMind DB held in buf
+func NewReader(buf []byte) (*Reader, error) {
+	idx := bytes.LastIndex(buf, metadataMarker)
+	if idx < 0 {
+		return nil, errors.New("not a MaxMind DB: metadata marker not found")
+	}
+
+	metaStart := idx + len(metadataMarker)
+	meta, _, err := (&decoder{buf: buf[metaStart:]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MaxMind DB metadata: %w", err)
+	}
+	metaMap, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid MaxMind DB metadata: not a map")
+	}
+
+	r := &Reader{buf: buf}
+	r.nodeCount = uint(toUint(metaMap["node_count"]))
+	r.recordSize = uint(toUint(metaMap["record_size"]))
+	r.ipVersion = uint(toUint(metaMap["ip_version"]))
+	r.DatabaseType, _ = metaMap["database_type"].(string)
+
+	switch r.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported IP version %d", r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	dataStart := treeSize + dataSectionSeparator
+	if r.nodeCount == 0 || dataStart > uint(idx) {
+		return nil, errors.New("invalid MaxMind DB: search tree overruns the file")
+	}
+	r.data = buf[dataStart:idx]
+
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node = r.readRecord(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// Lookup decodes the record for ip. It returns nil, nil when the database has
+// no network containing ip.
+func (r *Reader) Lookup(ip net.IP) (map[string]interface{}, error) {
+	node, bits, err := r.start(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(bits)*8 && node < r.nodeCount; i++ {
+		bit := uint(bits[i/8]>>(7-uint(i%8))) & 1
+		node = r.readRecord(node, bit)
+	}
+
+	switch {
+	case node == r.nodeCount:
+		return nil, nil
+	case node < r.nodeCount:
+		return nil, errors.New("invalid MaxMind DB: search tree deeper than the address")
+	}
+
+	offset := node - r.nodeCount - dataSectionSeparator
+	if offset >= uint(len(r.data)) {
+		return nil, errors.New("invalid MaxMind DB: record points past the data section")
+	}
+
+	value, _, err := (&decoder{buf: r.data}).decode(offset)
+	if err != nil {
+		return nil, err
+	}
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid MaxMind DB: record is not a map")
+	}
+	return record, nil
+}
+
+// start returns the search tree node and address bytes to walk for ip
+func (r *Reader) start(ip net.IP) (uint, []byte, error) {
+	if ip4 := ip.To4(); ip4 != nil {
+		if r.ipVersion == 6 {
+			return r.ipv4Start, ip4, nil
+		}
+		return 0, ip4, nil
+	}
+	if r.ipVersion == 4 {
+		return 0, nil, errors.New("IPv6 address in an IPv4-only database")
+	}
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return 0, nil, errors.New("invalid IP address")
+	}
+	return 0, ip16, nil
+}
+
+// readRecord returns the left (bit 0) or right (bit 1) record of node
+func (r *Reader) readRecord(node, bit uint) uint {
+	b := r.buf[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		off := bit * 3
+		return uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		off := bit * 4
+		return uint(binary.BigEndian.Uint32(b[off : off+4]))
+	}
+}
+
+// MaxMind DB data section types
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+type decoder struct {
+	buf []byte
+}
+
+var errTruncated = errors.New("invalid MaxMind DB: data section truncated")
+
+// decode reads the value at offset and returns it with the offset just past it
+func (d *decoder) decode(offset uint) (interface{}, uint, error) {
+	typeNum, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typeNum == typePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		if target < uint(len(d.buf)) && d.buf[target]>>5 == typePointer {
+			return nil, 0, errors.New("invalid MaxMind DB: pointer to a pointer")
+		}
+		value, _, err := d.decode(target)
+		return value, next, err
+	}
+
+	switch typeNum {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			var key, value interface{}
+			key, offset, err = d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, offset, err = d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("invalid MaxMind DB: map key is not a string")
+			}
+			m[k] = value
+		}
+		return m, offset, nil
+
+	case typeArray:
+		a := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			var value interface{}
+			value, offset, err = d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+		}
+		return a, offset, nil
+
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	end := offset + size
+	if end > uint(len(d.buf)) || end < offset {
+		return nil, 0, errTruncated
+	}
+	b := d.buf[offset:end]
+
+	switch typeNum {
+	case typeString:
+		return string(b), end, nil
+	case typeBytes:
+		return append([]byte(nil), b...), end, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("invalid MaxMind DB: bad double size")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), end, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("invalid MaxMind DB: bad float size")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), end, nil
+	case typeUint16, typeUint32, typeUint64:
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, end, nil
+	case typeInt32:
+		var v uint32
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+		return int64(int32(v)), end, nil
+	case typeUint128:
+		return new(big.Int).SetBytes(b), end, nil
+	default:
+		return nil, 0, fmt.Errorf("invalid MaxMind DB: unsupported data type %d", typeNum)
+	}
+}
+
+// control reads a control byte and returns the value's type, its size and
+// the offset of its payload
+func (d *decoder) control(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, errTruncated
+	}
+	ctrl := d.buf[offset]
+	offset++
+
+	typeNum := int(ctrl >> 5)
+	if typeNum == typePointer {
+		// Pointers keep their size bits in the low five bits of ctrl
+		return typeNum, uint(ctrl & 0x1F), offset, nil
+	}
+	if typeNum == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		typeNum = 7 + int(d.buf[offset])
+		offset++
+	}
+
+	size := uint(ctrl & 0x1F)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		var v uint
+		for _, c := range d.buf[offset : offset+n] {
+			v = v<<8 | uint(c)
+		}
+		offset += n
+		switch size {
+		case 29:
+			size = 29 + v
+		case 30:
+			size = 285 + v
+		default:
+			size = 65821 + v
+		}
+	}
+	return typeNum, size, offset, nil
+}
+
+// pointer resolves a pointer whose control bits are bits, returning the
+// target offset and the offset just past the pointer
+func (d *decoder) pointer(bits, offset uint) (uint, uint, error) {
+	n := (bits>>3)&0x3 + 1
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, errTruncated
+	}
+	b := d.buf[offset : offset+n]
+
+	var target uint
+	switch n {
+	case 1:
+		target = (bits&0x7)<<8 | uint(b[0])
+	case 2:
+		target = ((bits&0x7)<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+	case 3:
+		target = ((bits&0x7)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+	default:
+		target = uint(binary.BigEndian.Uint32(b))
+	}
+	return target, offset + n, nil
+}
+
+func toUint(v interface{}) uint64 {
+	switch n := v.(type) {
+	case uint64:
+		return n
+	case int64:
+		if n > 0 {
+			return uint64(n)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,242 @@
+package geoip
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testDB builds a small IPv4 MaxMind DB for the reader tests
+type testDB struct {
+	nodes [][2]int // -1 is an empty record, >= dataRef marks a data offset
+	data  bytes.Buffer
+}
+
+const dataRef = 1 << 20
+
+func newTestDB() *testDB {
+	return &testDB{nodes: [][2]int{{-1, -1}}}
+}
+
+// insert points cidr at the data section value at offset
+func (db *testDB) insert(cidr string, offset int) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	ones, _ := network.Mask.Size()
+	ip := network.IP.To4()
+
+	node := 0
+	for i := 0; i < ones; i++ {
+		bit := int(ip[i/8]>>(7-uint(i%8))) & 1
+		if i == ones-1 {
+			db.nodes[node][bit] = dataRef + offset
+			return
+		}
+		next := db.nodes[node][bit]
+		if next < 0 {
+			db.nodes = append(db.nodes, [2]int{-1, -1})
+			next = len(db.nodes) - 1
+			db.nodes[node][bit] = next
+		}
+		node = next
+	}
+}
+
+func (db *testDB) build(recordSize int) []byte {
+	var out bytes.Buffer
+	count := len(db.nodes)
+	value := func(v int) int {
+		switch {
+		case v < 0:
+			return count
+		case v >= dataRef:
+			return count + dataSectionSeparator + v - dataRef
+		default:
+			return v
+		}
+	}
+
+	for _, n := range db.nodes {
+		left, right := value(n[0]), value(n[1])
+		switch recordSize {
+		case 24:
+			out.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)})
+		case 28:
+			out.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(left>>24)<<4 | byte(right>>24)&0x0F, byte(right >> 16), byte(right >> 8), byte(right)})
+		case 32:
+			out.Write([]byte{byte(left >> 24), byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 24), byte(right >> 16), byte(right >> 8), byte(right)})
+		}
+	}
+	out.Write(make([]byte, dataSectionSeparator))
+	out.Write(db.data.Bytes())
+	out.Write(metadataMarker)
+
+	var meta bytes.Buffer
+	writeMap(&meta, 4)
+	writeString(&meta, "node_count")
+	writeUint(&meta, typeUint32, uint64(count))
+	writeString(&meta, "record_size")
+	writeUint(&meta, typeUint16, uint64(recordSize))
+	writeString(&meta, "ip_version")
+	writeUint(&meta, typeUint16, 4)
+	writeString(&meta, "database_type")
+	writeString(&meta, "Test-City")
+	out.Write(meta.Bytes())
+	return out.Bytes()
+}
+
+func writeControl(buf *bytes.Buffer, typeNum, size int) {
+	sizeBits, extra := size, []byte(nil)
+	if size >= 29 {
+		sizeBits, extra = 29, []byte{byte(size - 29)}
+	}
+	if typeNum > 7 {
+		buf.Write([]byte{byte(sizeBits), byte(typeNum - 7)})
+	} else {
+		buf.WriteByte(byte(typeNum<<5 | sizeBits))
+	}
+	buf.Write(extra)
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeControl(buf, typeString, len(s))
+	buf.WriteString(s)
+}
+
+func writeUint(buf *bytes.Buffer, typeNum int, v uint64) {
+	var b []byte
+	for ; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	writeControl(buf, typeNum, len(b))
+	buf.Write(b)
+}
+
+func writeMap(buf *bytes.Buffer, size int) {
+	writeControl(buf, typeMap, size)
+}
+
+func writeNames(buf *bytes.Buffer, en string) {
+	writeString(buf, "names")
+	writeMap(buf, 1)
+	writeString(buf, "en")
+	writeString(buf, en)
+}
+
+func buildTestDB(t *testing.T, recordSize int) *Reader {
+	t.Helper()
+	db := newTestDB()
+
+	// London record for 81.2.69.0/24
+	london := db.data.Len()
+	writeMap(&db.data, 2)
+	writeString(&db.data, "country")
+	writeMap(&db.data, 2)
+	writeString(&db.data, "iso_code")
+	writeString(&db.data, "GB")
+	writeNames(&db.data, "United Kingdom")
+	writeString(&db.data, "city")
+	writeMap(&db.data, 1)
+	writeNames(&db.data, "London")
+	db.insert("81.2.69.0/24", london)
+
+	// ASN record with an organization name long enough to need an extra size byte
+	asn := db.data.Len()
+	writeMap(&db.data, 2)
+	writeString(&db.data, "autonomous_system_number")
+	writeUint(&db.data, typeUint32, 15169)
+	writeString(&db.data, "autonomous_system_organization")
+	writeString(&db.data, "Example Networks International Holdings LLC")
+	db.insert("8.8.8.0/24", asn)
+
+	// A pointer back to the London record
+	pointer := db.data.Len()
+	db.data.Write([]byte{byte(typePointer<<5 | london>>8), byte(london)})
+	db.insert("2.0.0.0/8", pointer)
+
+	reader, err := NewReader(db.build(recordSize))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	return reader
+}
+
+func TestReader_LookupLocation(t *testing.T) {
+	for _, recordSize := range []int{24, 28, 32} {
+		reader := buildTestDB(t, recordSize)
+		if reader.DatabaseType != "Test-City" {
+			t.Errorf("record size %d: DatabaseType = %q", recordSize, reader.DatabaseType)
+		}
+
+		loc, err := reader.LookupLocation(net.ParseIP("81.2.69.160"))
+		if err != nil || loc == nil {
+			t.Fatalf("record size %d: lookup failed: %v", recordSize, err)
+		}
+		if loc.Country != "GB" || loc.CountryName != "United Kingdom" || loc.City != "London" {
+			t.Errorf("record size %d: unexpected location %+v", recordSize, loc)
+		}
+
+		loc, err = reader.LookupLocation(net.ParseIP("8.8.8.8"))
+		if err != nil || loc == nil {
+			t.Fatalf("record size %d: ASN lookup failed: %v", recordSize, err)
+		}
+		if loc.ASN != 15169 || loc.ASOrg != "Example Networks International Holdings LLC" {
+			t.Errorf("record size %d: unexpected ASN location %+v", recordSize, loc)
+		}
+
+		loc, err = reader.LookupLocation(net.ParseIP("2.3.4.5"))
+		if err != nil || loc == nil || loc.City != "London" {
+			t.Errorf("record size %d: pointer lookup = %+v, %v", recordSize, loc, err)
+		}
+
+		loc, err = reader.LookupLocation(net.ParseIP("10.0.0.1"))
+		if err != nil || loc != nil {
+			t.Errorf("record size %d: expected no record for a private address, got %+v, %v", recordSize, loc, err)
+		}
+	}
+}
+
+func TestNewReader_RejectsGarbage(t *testing.T) {
+	if _, err := NewReader([]byte("not a database")); err == nil {
+		t.Error("Expected an error for a file without metadata")
+	}
+}
+
+func TestNewResolver_MergesDatabases(t *testing.T) {
+	dir := t.TempDir()
+	cityDB := newTestDB()
+	writeMap(&cityDB.data, 1)
+	writeString(&cityDB.data, "country")
+	writeMap(&cityDB.data, 1)
+	writeString(&cityDB.data, "iso_code")
+	writeString(&cityDB.data, "US")
+	cityDB.insert("8.8.0.0/16", 0)
+
+	asnDB := newTestDB()
+	writeMap(&asnDB.data, 1)
+	writeString(&asnDB.data, "autonomous_system_number")
+	writeUint(&asnDB.data, typeUint32, 15169)
+	asnDB.insert("8.8.8.0/24", 0)
+
+	cityPath := filepath.Join(dir, "city.mmdb")
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	os.WriteFile(cityPath, cityDB.build(24), 0644)
+	os.WriteFile(asnPath, asnDB.build(24), 0644)
+
+	resolver, err := NewResolver(cityPath, asnPath)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	loc, err := resolver.Lookup(net.ParseIP("8.8.8.8"))
+	if err != nil || loc == nil || loc.Country != "US" || loc.ASN != 15169 {
+		t.Errorf("Expected a merged location, got %+v, %v", loc, err)
+	}
+
+	if _, err := NewResolver(filepath.Join(dir, "missing.mmdb")); err == nil {
+		t.Error("Expected an error for a missing database")
+	}
+}
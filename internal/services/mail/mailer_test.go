@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessage(t *testing.T) {
+	msg := &Message{
+		To:      []string{"ops@example.com", "lead@example.com"},
+		Subject: "Weekly digest: Café",
+		Body:    "line one\nline two",
+	}
+	data := string(buildMessage("Central Logs <logs@example.com>", msg, time.Date(2025, 2, 3, 8, 0, 0, 0, time.UTC)))
+
+	headers, body, ok := strings.Cut(data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("Expected a blank line between headers and body, got %q", data)
+	}
+	for _, want := range []string{
+		"From: Central Logs <logs@example.com>",
+		"To: ops@example.com, lead@example.com",
+		"Subject: =?utf-8?q?Weekly_digest:_Caf=C3=A9?=",
+		"Date: Mon, 03 Feb 2025 08:00:00 +0000",
+		"Content-Type: text/plain; charset=utf-8",
+	} {
+		if !strings.Contains(headers, want) {
+			t.Errorf("Expected header %q in:\n%s", want, headers)
+		}
+	}
+	if body != "line one\r\nline two" {
+		t.Errorf("Expected CRLF line endings in the body, got %q", body)
+	}
+}
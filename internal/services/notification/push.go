@@ -1,8 +1,10 @@
 package notification
 
 import (
+	"context"
 	"encoding/json"
 	"log"
+	"sync"
 
 	"central-logs/internal/config"
 	"central-logs/internal/models"
@@ -68,7 +70,7 @@ func (s *PushService) SendLogNotification(logEntry *models.Log, projectName stri
 	}
 
 	// Check if log level meets minimum threshold
-	if !pushChannel.ShouldNotify(logEntry.Level) {
+	if !pushChannel.ShouldNotify(logEntry.Level, logEntry.Source) {
 		return nil
 	}
 
@@ -100,11 +102,18 @@ func (s *PushService) SendLogNotification(logEntry *models.Log, projectName stri
 		return err
 	}
 
-	// Send to all subscriptions
+	// Send to all subscriptions in parallel, returning once every send is
+	// done so callers can tell when delivery has finished.
 	// Service worker will check visibility and skip if page is visible
+	var wg sync.WaitGroup
 	for _, sub := range subscriptions {
-		go s.sendPush(sub, payloadBytes)
+		wg.Add(1)
+		go func(sub *models.PushSubscription) {
+			defer wg.Done()
+			s.sendPush(sub, payloadBytes)
+		}(sub)
 	}
+	wg.Wait()
 
 	return nil
 }
@@ -120,7 +129,10 @@ func (s *PushService) sendPush(sub *models.PushSubscription, payload []byte) {
 		},
 	}
 
-	resp, err := webpush.SendNotification(payload, subscription, &webpush.Options{
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.GetNotificationHTTPTimeout())
+	defer cancel()
+
+	resp, err := webpush.SendNotificationWithContext(ctx, payload, subscription, &webpush.Options{
 		Subscriber:      s.config.VAPID.Subject,
 		VAPIDPublicKey:  s.config.VAPID.PublicKey,
 		VAPIDPrivateKey: s.config.VAPID.PrivateKey,
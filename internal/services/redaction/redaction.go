@@ -0,0 +1,145 @@
+package redaction
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Replacement is written in place of every redacted match
+const Replacement = "[REDACTED]"
+
+type detector struct {
+	pattern *regexp.Regexp
+	valid   func(match string) bool // Optional check to weed out false positives
+}
+
+// detectors are the built-in named rules
+var detectors = map[string]detector{
+	"email": {
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	},
+	"credit_card": {
+		// 13-19 digits, optionally grouped by spaces or dashes, passing the Luhn check
+		pattern: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		valid:   luhnValid,
+	},
+	"ssn": {
+		pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	},
+}
+
+// Detectors lists the names accepted by Compile
+func Detectors() []string {
+	names := make([]string, 0, len(detectors))
+	for name := range detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rules is a compiled set of redaction rules. A nil *Rules redacts nothing.
+type Rules struct {
+	rules []detector
+}
+
+// Compile builds rules from named detectors and custom regular expressions
+func Compile(detectorNames, patterns []string) (*Rules, error) {
+	r := &Rules{}
+	for _, name := range detectorNames {
+		d, ok := detectors[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction detector %q (use %s)", name, strings.Join(Detectors(), ", "))
+		}
+		r.rules = append(r.rules, d)
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		if re.MatchString("") {
+			return nil, fmt.Errorf("redaction pattern %q matches the empty string", pattern)
+		}
+		r.rules = append(r.rules, detector{pattern: re})
+	}
+	return r, nil
+}
+
+// Empty reports whether the rules redact nothing
+func (r *Rules) Empty() bool {
+	return r == nil || len(r.rules) == 0
+}
+
+// String returns s with every match replaced
+func (r *Rules) String(s string) string {
+	if r.Empty() {
+		return s
+	}
+	for _, d := range r.rules {
+		if d.valid == nil {
+			s = d.pattern.ReplaceAllString(s, Replacement)
+			continue
+		}
+		s = d.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if d.valid(match) {
+				return Replacement
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// Metadata redacts every string value in metadata, including values nested
+// in objects and arrays. Keys are left alone.
+func (r *Rules) Metadata(metadata map[string]interface{}) {
+	if r.Empty() {
+		return
+	}
+	for key, value := range metadata {
+		metadata[key] = r.value(value)
+	}
+}
+
+func (r *Rules) value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return r.String(val)
+	case map[string]interface{}:
+		r.Metadata(val)
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = r.value(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum
+func luhnValid(s string) bool {
+	sum, count := 0, 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		count++
+	}
+	return count >= 13 && count <= 19 && sum%10 == 0
+}
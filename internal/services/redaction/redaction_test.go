@@ -0,0 +1,67 @@
+package redaction
+
+import "testing"
+
+func TestRules_String(t *testing.T) {
+	rules, err := Compile([]string{"email", "credit_card", "ssn"}, []string{`tok_[a-z0-9]{8}`})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"contact alice.smith+ops@mail.example.co.uk now", "contact [REDACTED] now"},
+		{"card 4111 1111 1111 1111 declined", "card [REDACTED] declined"},
+		{"card 4111-1111-1111-1111", "card [REDACTED]"},
+		{"order 1234567890123 shipped", "order 1234567890123 shipped"}, // fails the Luhn check
+		{"ssn 123-45-6789 on file", "ssn [REDACTED] on file"},
+		{"token tok_ab12cd34 issued", "token [REDACTED] issued"},
+		{"nothing sensitive", "nothing sensitive"},
+	}
+	for _, tt := range tests {
+		if got := rules.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRules_Metadata(t *testing.T) {
+	rules, _ := Compile([]string{"email"}, nil)
+	metadata := map[string]interface{}{
+		"user":  "bob@example.com",
+		"count": float64(3),
+		"request": map[string]interface{}{
+			"cc": []interface{}{"a@example.com", "plain"},
+		},
+	}
+
+	rules.Metadata(metadata)
+
+	if metadata["user"] != Replacement || metadata["count"] != float64(3) {
+		t.Errorf("Unexpected top-level metadata %v", metadata)
+	}
+	cc := metadata["request"].(map[string]interface{})["cc"].([]interface{})
+	if cc[0] != Replacement || cc[1] != "plain" {
+		t.Errorf("Expected nested array values redacted, got %v", cc)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	if _, err := Compile([]string{"passport"}, nil); err == nil {
+		t.Error("Expected an error for an unknown detector")
+	}
+	if _, err := Compile(nil, []string{"("}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if _, err := Compile(nil, []string{"x*"}); err == nil {
+		t.Error("Expected an error for a pattern matching the empty string")
+	}
+}
+
+func TestRules_NilRedactsNothing(t *testing.T) {
+	var rules *Rules
+	if got := rules.String("bob@example.com"); got != "bob@example.com" {
+		t.Errorf("Expected nil rules to leave input alone, got %q", got)
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+// ANSI escape codes shared by the terminal tools
+const (
+	ColorReset = "\033[0m"
+	ColorRed   = "\033[31m"
+	ColorGray  = "\033[90m"
+)
+
+// LevelColor returns the ANSI color used to print a log level
+func LevelColor(level string) string {
+	switch level {
+	case "DEBUG":
+		return "\033[36m" // Cyan
+	case "INFO":
+		return "\033[32m" // Green
+	case "WARN":
+		return "\033[33m" // Yellow
+	case "ERROR":
+		return ColorRed
+	case "CRITICAL":
+		return "\033[35m" // Magenta
+	default:
+		return ColorReset
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Matches(t *testing.T) {
+	// 2025-03-02 is a Sunday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.March, day, hour, minute, 30, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"0 3 * * *", at(2, 3, 0), true},
+		{"0 3 * * *", at(2, 3, 1), false},
+		{"*/15 * * * *", at(2, 10, 45), true},
+		{"*/15 * * * *", at(2, 10, 50), false},
+		{"0 1-5/2 * * *", at(4, 3, 0), true},
+		{"0 1-5/2 * * *", at(4, 4, 0), false},
+		{"30 2 * * 0", at(2, 2, 30), true},
+		{"30 2 * * 7", at(2, 2, 30), true},
+		{"30 2 * * 1-5", at(2, 2, 30), false},
+		{"0 0 1,15 * *", at(15, 0, 0), true},
+		// Both day fields restricted: either one matching is enough
+		{"0 0 1 * 0", at(2, 0, 0), true},
+		{"0 0 1 * 1", at(2, 0, 0), false},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) failed: %v", tt.expr, err)
+		}
+		if got := schedule.Matches(tt.t); got != tt.want {
+			t.Errorf("%q at %s: got %v, want %v", tt.expr, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "0 3 * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
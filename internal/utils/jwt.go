@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -13,9 +14,13 @@ var (
 )
 
 type JWTClaims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID       string `json:"user_id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	TokenVersion int    `json:"ver,omitempty"` // Must match the user's token version; bumped to revoke all sessions
+	// ImpersonatedBy is the ID of the admin acting as this user, set only on
+	// impersonation tokens
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -30,6 +35,8 @@ type TempTokenClaims struct {
 type JWTManager struct {
 	secretKey []byte
 	expiry    time.Duration
+	issuer    string
+	audience  string
 }
 
 func NewJWTManager(secret string, expiry time.Duration) *JWTManager {
@@ -39,17 +46,74 @@ func NewJWTManager(secret string, expiry time.Duration) *JWTManager {
 	}
 }
 
-func (m *JWTManager) Generate(userID, email, role string) (string, error) {
-	claims := &JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+// SetIssuer sets the iss and aud claims put into every token. When set, a
+// token must carry the same value to validate; left empty, that claim is
+// neither added nor checked.
+func (m *JWTManager) SetIssuer(issuer, audience string) {
+	m.issuer = issuer
+	m.audience = audience
+}
+
+// registeredClaims builds the standard claims for a token expiring after ttl
+func (m *JWTManager) registeredClaims(ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    m.issuer,
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
 	}
+	if m.audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.audience}
+	}
+	return claims
+}
+
+// parserOptions returns the iss/aud checks for the configured values
+func (m *JWTManager) parserOptions() []jwt.ParserOption {
+	var opts []jwt.ParserOption
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	if m.audience != "" {
+		opts = append(opts, jwt.WithAudience(m.audience))
+	}
+	return opts
+}
+
+func (m *JWTManager) Generate(userID, email, role string) (string, error) {
+	return m.GenerateForVersion(userID, email, role, 0)
+}
+
+// GenerateForVersion creates a session token tied to the user's current token
+// version. Each token also gets a unique jti so it can be revoked on its own.
+func (m *JWTManager) GenerateForVersion(userID, email, role string, tokenVersion int) (string, error) {
+	return m.signSession(&JWTClaims{
+		UserID:           userID,
+		Email:            email,
+		Role:             role,
+		TokenVersion:     tokenVersion,
+		RegisteredClaims: m.registeredClaims(m.expiry),
+	})
+}
+
+// GenerateImpersonation creates a session token for userID that expires after
+// ttl and names the admin it was issued to. Apart from the marker it is an
+// ordinary session token, so revoking the user's sessions revokes it too.
+func (m *JWTManager) GenerateImpersonation(userID, email, role string, tokenVersion int, adminID string, ttl time.Duration) (string, error) {
+	return m.signSession(&JWTClaims{
+		UserID:           userID,
+		Email:            email,
+		Role:             role,
+		TokenVersion:     tokenVersion,
+		ImpersonatedBy:   adminID,
+		RegisteredClaims: m.registeredClaims(ttl),
+	})
+}
+
+// signSession gives claims a unique jti and signs them
+func (m *JWTManager) signSession(claims *JWTClaims) (string, error) {
+	claims.ID = uuid.New().String()
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(m.secretKey)
@@ -61,7 +125,7 @@ func (m *JWTManager) Validate(tokenString string) (*JWTClaims, error) {
 			return nil, ErrInvalidToken
 		}
 		return m.secretKey, nil
-	})
+	}, m.parserOptions()...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -81,15 +145,11 @@ func (m *JWTManager) Validate(tokenString string) (*JWTClaims, error) {
 // GenerateTempToken creates a short-lived token for 2FA verification (5 minutes)
 func (m *JWTManager) GenerateTempToken(userID, username, role string) (string, error) {
 	claims := &TempTokenClaims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
-		Purpose:  "2fa_verify",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+		UserID:           userID,
+		Username:         username,
+		Role:             role,
+		Purpose:          "2fa_verify",
+		RegisteredClaims: m.registeredClaims(5 * time.Minute),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -103,7 +163,7 @@ func (m *JWTManager) ValidateTempToken(tokenString string) (*TempTokenClaims, er
 			return nil, ErrInvalidToken
 		}
 		return m.secretKey, nil
-	})
+	}, m.parserOptions()...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJWTManager_IssuerAndAudience(t *testing.T) {
+	issuing := NewJWTManager("test-secret", time.Hour)
+	issuing.SetIssuer("central-logs", "central-logs-api")
+
+	token, err := issuing.Generate("user-1", "user@example.com", "USER")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	claims, err := issuing.Validate(token)
+	if err != nil {
+		t.Fatalf("Expected token with matching audience to validate, got %v", err)
+	}
+	if claims.Issuer != "central-logs" || len(claims.Audience) != 1 || claims.Audience[0] != "central-logs-api" {
+		t.Errorf("Expected iss/aud claims to be set, got %q %v", claims.Issuer, claims.Audience)
+	}
+
+	otherAudience := NewJWTManager("test-secret", time.Hour)
+	otherAudience.SetIssuer("central-logs", "another-api")
+	if _, err := otherAudience.Validate(token); err != ErrInvalidToken {
+		t.Errorf("Expected wrong audience to be rejected, got %v", err)
+	}
+
+	otherIssuer := NewJWTManager("test-secret", time.Hour)
+	otherIssuer.SetIssuer("someone-else", "central-logs-api")
+	if _, err := otherIssuer.Validate(token); err != ErrInvalidToken {
+		t.Errorf("Expected wrong issuer to be rejected, got %v", err)
+	}
+
+	// Without configured values the claims are not checked
+	lenient := NewJWTManager("test-secret", time.Hour)
+	if _, err := lenient.Validate(token); err != nil {
+		t.Errorf("Expected unconfigured manager to accept the token, got %v", err)
+	}
+}
+
+func TestJWTManager_AudienceRequiredOnceConfigured(t *testing.T) {
+	plain, err := NewJWTManager("test-secret", time.Hour).Generate("user-1", "user@example.com", "USER")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	strict := NewJWTManager("test-secret", time.Hour)
+	strict.SetIssuer("", "central-logs-api")
+	if _, err := strict.Validate(plain); err != ErrInvalidToken {
+		t.Errorf("Expected token without an audience to be rejected, got %v", err)
+	}
+
+	temp, err := strict.GenerateTempToken("user-1", "user", "USER")
+	if err != nil {
+		t.Fatalf("Failed to generate temp token: %v", err)
+	}
+	if _, err := strict.ValidateTempToken(temp); err != nil {
+		t.Errorf("Expected temp token with matching audience to validate, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"unicode"
+)
+
+// PasswordPolicy lists the rules a new password must satisfy
+type PasswordPolicy struct {
+	MinLength        int
+	RequireMixedCase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+}
+
+// DefaultPasswordPolicy only enforces a minimum length of 8
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8}
+
+var passwordPolicy = DefaultPasswordPolicy
+
+// SetPasswordPolicy replaces the policy used by ValidatePassword. It is meant
+// to be called once at startup.
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicy = policy
+}
+
+// ValidatePassword checks a password against the configured policy. The
+// error message names the first rule the password breaks and is safe to
+// show to the user.
+func ValidatePassword(pw string) error {
+	return passwordPolicy.Validate(pw)
+}
+
+// Validate checks a password against this policy
+func (p PasswordPolicy) Validate(pw string) error {
+	if len(pw) < p.MinLength {
+		return fmt.Errorf("Password must be at least %d characters", p.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireMixedCase && !(hasUpper && hasLower) {
+		return errors.New("Password must contain both upper and lower case letters")
+	}
+	if p.RequireDigit && !hasDigit {
+		return errors.New("Password must contain at least one digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return errors.New("Password must contain at least one symbol")
+	}
+	return nil
+}
+
+const (
+	passwordLower   = "abcdefghijkmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordDigits  = "23456789"
+	passwordSymbols = "!@#$%^&*-_=+?"
+)
+
+// GeneratePassword returns a random password that satisfies the configured
+// policy, at least 16 characters long and with every character class present
+func GeneratePassword() (string, error) {
+	length := passwordPolicy.MinLength
+	if length < 16 {
+		length = 16
+	}
+
+	classes := []string{passwordLower, passwordUpper, passwordDigits, passwordSymbols}
+	all := passwordLower + passwordUpper + passwordDigits + passwordSymbols
+
+	pw := make([]byte, length)
+	for i := range pw {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		pw[i] = c
+	}
+
+	// Shuffle so the guaranteed classes are not always at the front
+	for i := len(pw) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		pw[i], pw[j.Int64()] = pw[j.Int64()], pw[i]
+	}
+	return string(pw), nil
+}
+
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
@@ -0,0 +1,79 @@
+package utils
+
+import "testing"
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireMixedCase: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantErr  string
+	}{
+		{"default accepts any 8 chars", DefaultPasswordPolicy, "password", ""},
+		{"default rejects short", DefaultPasswordPolicy, "short", "Password must be at least 8 characters"},
+		{"custom length", PasswordPolicy{MinLength: 12}, "password1234", ""},
+		{"custom length too short", PasswordPolicy{MinLength: 12}, "password123", "Password must be at least 12 characters"},
+		{"mixed case missing upper", PasswordPolicy{MinLength: 8, RequireMixedCase: true}, "lowercase", "Password must contain both upper and lower case letters"},
+		{"mixed case missing lower", PasswordPolicy{MinLength: 8, RequireMixedCase: true}, "UPPERCASE", "Password must contain both upper and lower case letters"},
+		{"mixed case ok", PasswordPolicy{MinLength: 8, RequireMixedCase: true}, "MixedCase", ""},
+		{"digit missing", PasswordPolicy{MinLength: 8, RequireDigit: true}, "nodigits!", "Password must contain at least one digit"},
+		{"symbol missing", PasswordPolicy{MinLength: 8, RequireSymbol: true}, "nosymbol1", "Password must contain at least one symbol"},
+		{"symbol ok", PasswordPolicy{MinLength: 8, RequireSymbol: true}, "has+plus", ""},
+		{"strict reports length first", strict, "Ab1!", "Password must be at least 10 characters"},
+		{"strict missing digit", strict, "Abcdefgh!!", "Password must contain at least one digit"},
+		{"strict ok", strict, "Abcdefg1!x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected %q to pass, got %v", tt.password, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePassword_UsesConfiguredPolicy(t *testing.T) {
+	defer SetPasswordPolicy(DefaultPasswordPolicy)
+
+	if err := ValidatePassword("password"); err != nil {
+		t.Fatalf("Expected default policy to accept %q, got %v", "password", err)
+	}
+
+	SetPasswordPolicy(PasswordPolicy{MinLength: 8, RequireDigit: true})
+	if err := ValidatePassword("password"); err == nil {
+		t.Error("Expected configured policy to require a digit")
+	}
+}
+
+func TestGeneratePassword_SatisfiesPolicy(t *testing.T) {
+	defer SetPasswordPolicy(DefaultPasswordPolicy)
+
+	SetPasswordPolicy(PasswordPolicy{MinLength: 20, RequireMixedCase: true, RequireDigit: true, RequireSymbol: true})
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		pw, err := GeneratePassword()
+		if err != nil {
+			t.Fatalf("Failed to generate password: %v", err)
+		}
+		if len(pw) != 20 {
+			t.Errorf("Expected 20 characters, got %d", len(pw))
+		}
+		if err := ValidatePassword(pw); err != nil {
+			t.Errorf("Generated password %q fails policy: %v", pw, err)
+		}
+		seen[pw] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("Expected 50 distinct passwords, got %d", len(seen))
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// certCheckInterval is how often CertReloader looks at the files for a change
+const certCheckInterval = 30 * time.Second
+
+// CertReloader serves a TLS certificate from a certificate and key file and
+// reloads it once either file changes, so a renewal such as Let's Encrypt's
+// is picked up without a restart. A pair that fails to load is logged and the
+// previous certificate kept, which also covers the moment between a renewal
+// writing the new certificate and its key.
+type CertReloader struct {
+	certFile, keyFile string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	loadedMod time.Time // filesModTime when cert was loaded
+	checkedAt time.Time
+}
+
+// NewCertReloader loads the certificate and key, failing if they don't form a
+// valid pair
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate is a tls.Config.GetCertificate returning the current
+// certificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checkedAt) >= certCheckInterval {
+		r.checkedAt = time.Now()
+		r.reloadIfChanged()
+	}
+	return r.cert, nil
+}
+
+// TLSConfig returns a server config serving the reloaded certificate
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+func (r *CertReloader) reloadIfChanged() {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		log.Printf("TLS certificate check failed, keeping the current one: %v", err)
+		return
+	}
+	if !modTime.After(r.loadedMod) {
+		return
+	}
+	if err := r.load(modTime); err != nil {
+		log.Printf("TLS certificate reload failed, keeping the current one: %v", err)
+		return
+	}
+	log.Printf("TLS certificate reloaded from %s", r.certFile)
+}
+
+// load replaces the certificate; callers other than the constructor hold mu
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading %s and %s: %w", r.certFile, r.keyFile, err)
+	}
+	r.cert = &cert
+	r.loadedMod = modTime
+	return nil
+}
+
+// filesModTime returns the latest modification time of the two files
+func (r *CertReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
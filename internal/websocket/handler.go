@@ -4,6 +4,7 @@ import (
 	"log"
 	"strings"
 
+	"central-logs/internal/middleware"
 	"central-logs/internal/models"
 	"central-logs/internal/utils"
 
@@ -13,9 +14,10 @@ import (
 
 // Handler handles WebSocket connections
 type Handler struct {
-	hub        *Hub
-	jwtManager *utils.JWTManager
-	userRepo   *models.UserRepository
+	hub           *Hub
+	jwtManager    *utils.JWTManager
+	userRepo      *models.UserRepository
+	revokedTokens *models.RevokedTokenRepository
 }
 
 // NewHandler creates a new WebSocket handler
@@ -27,6 +29,11 @@ func NewHandler(hub *Hub, jwtManager *utils.JWTManager, userRepo *models.UserRep
 	}
 }
 
+// SetRevokedTokens enables checking tokens against the revocation denylist
+func (h *Handler) SetRevokedTokens(revokedTokens *models.RevokedTokenRepository) {
+	h.revokedTokens = revokedTokens
+}
+
 // AuthMiddleware validates JWT token from WebSocket upgrade request headers
 func (h *Handler) AuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -74,6 +81,17 @@ func (h *Handler) AuthMiddleware() fiber.Handler {
 			})
 		}
 
+		if revoked, err := middleware.SessionRevoked(claims, user, h.revokedTokens); err != nil || revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+		if _, err := middleware.Impersonator(claims, h.userRepo); err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+
 		// Store user in locals for WebSocket handler
 		c.Locals("user", user)
 		c.Locals("user_id", user.ID)
@@ -121,15 +139,20 @@ func (h *Handler) HandleLogs() fiber.Handler {
 
 		log.Printf("[WS] Client connected: user=%s, project=%s", user.Username, projectID)
 
-		client := &Client{
-			Conn:      c,
-			UserID:    userID,
-			ProjectID: projectID,
-		}
+		client := h.hub.NewClient(c, userID, projectID)
+
+		// The connection must not outlive this function, so wait for the
+		// write pump once the hub has let go of the client
+		written := make(chan struct{})
+		go func() {
+			client.WritePump()
+			close(written)
+		}()
 
 		h.hub.Register(client)
 		defer func() {
 			h.hub.Unregister(client)
+			<-written
 			log.Printf("[WS] Client disconnected: user=%s", user.Username)
 		}()
 
@@ -149,7 +172,7 @@ func (h *Handler) HandleLogs() fiber.Handler {
 			if messageType == websocket.TextMessage {
 				// Echo back pings
 				if string(msg) == "ping" {
-					c.WriteMessage(websocket.TextMessage, []byte(`{"type":"pong"}`))
+					client.Send([]byte(`{"type":"pong"}`))
 				}
 			}
 		}
@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// startLogsServer serves HandleLogs for a fixed user, skipping the JWT check
+func startLogsServer(t *testing.T, hub *Hub) string {
+	t.Helper()
+	handler := NewHandler(hub, nil, nil)
+	user := &models.User{ID: "user-1", Username: "user"}
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws/logs", handler.Upgrade(), func(c *fiber.Ctx) error {
+		c.Locals("user", user)
+		c.Locals("user_id", user.ID)
+		return c.Next()
+	}, handler.HandleLogs())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+	return "ws://" + ln.Addr().String() + "/ws/logs"
+}
+
+// pingPong sends a ping on a fresh connection and waits for the pong
+func pingPong(t *testing.T, url string) {
+	t.Helper()
+	conn, _, err := fastws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteMessage(fastws.TextMessage, []byte("ping")); err != nil {
+		t.Fatalf("Failed to send ping: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != `{"type":"pong"}` {
+		t.Fatalf("Expected a pong, got %q (%v)", msg, err)
+	}
+}
+
+func TestHandleLogs_PingAfterSlowClientEviction(t *testing.T) {
+	hub := NewHub()
+	hub.SetSlowClientPolicy(SlowClientDisconnect, 1)
+	go hub.Run()
+	url := startLogsServer(t, hub)
+
+	conn, _, err := fastws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	waitFor(t, "the client to register", func() bool { return hub.ClientCount() == 1 })
+	hub.mu.RLock()
+	var client *Client
+	for c := range hub.clients {
+		client = c
+	}
+	hub.mu.RUnlock()
+
+	// Evicted while its read loop is still running, as when a broadcast finds
+	// the buffer full; the ping must not write to a closed channel
+	hub.handleSlowClient(client)
+	conn.WriteMessage(fastws.TextMessage, []byte("ping"))
+	if client.Send([]byte(`{"type":"pong"}`)) {
+		t.Error("Expected Send to refuse an evicted client")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	if stats := hub.Stats(); stats.Disconnected != 1 || stats.Clients != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// The server is still up for the next connection
+	pingPong(t, url)
+}
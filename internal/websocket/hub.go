@@ -4,15 +4,120 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
 )
 
-// Client represents a WebSocket client connection
+// writeWait is how long a single write to a client may take before the
+// connection is given up on
+const writeWait = 10 * time.Second
+
+// What the hub does with a client whose send buffer is full
+const (
+	SlowClientDrop       = "drop"       // Skip the message for that client and count it as dropped
+	SlowClientDisconnect = "disconnect" // Close the connection; the client may reconnect
+)
+
+// Client represents a WebSocket client connection. Messages reach it through
+// a bounded buffer drained by WritePump, so a client that reads slowly never
+// holds up the hub.
 type Client struct {
 	Conn      *websocket.Conn
 	UserID    string
 	ProjectID string // Empty means subscribed to all projects user has access to
+
+	// send is never closed: the handler's read loop may still answer a ping
+	// after the hub has let go of the client, so done signals the end instead
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	dropped   atomic.Int64
+}
+
+// Dropped returns how many messages were skipped because the client's send
+// buffer was full
+func (c *Client) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// Send queues data for the client, reporting false if its buffer is full or
+// the hub has dropped it
+func (c *Client) Send(data []byte) bool {
+	if c.closed() {
+		return false
+	}
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// close tells WritePump to finish; it is safe to call more than once
+func (c *Client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// closed reports whether the hub has dropped the client
+func (c *Client) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// WritePump writes queued messages to the connection until the hub drops the
+// client or a write fails, then closes the connection. Messages already
+// queued when the client is dropped are still written. Run it in its own
+// goroutine for each client; it must be the only writer on Conn.
+func (c *Client) WritePump() {
+	defer c.Conn.Close()
+	for {
+		select {
+		case data := <-c.send:
+			if !c.write(data) {
+				return
+			}
+		case <-c.done:
+			for {
+				select {
+				case data := <-c.send:
+					if !c.write(data) {
+						return
+					}
+				default:
+					c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+					c.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+			}
+		}
+	}
+}
+
+// write sends one message, reporting false once the connection has failed
+func (c *Client) write(data []byte) bool {
+	c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := c.Conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		log.Printf("[WS] Error sending message to user %s: %v", c.UserID, err)
+		return false
+	}
+	return true
+}
+
+// HubStats reports the hub's connections and backpressure since start
+type HubStats struct {
+	Clients      int    `json:"clients"`
+	SendBuffer   int    `json:"send_buffer"` // Messages each client can have waiting
+	Policy       string `json:"slow_client_policy"`
+	Dropped      int64  `json:"dropped"`      // Messages skipped for clients with a full buffer
+	Disconnected int64  `json:"disconnected"` // Clients closed for falling behind
+	Overflowed   int64  `json:"overflowed"`   // Broadcasts lost because the hub itself was behind
 }
 
 // Hub manages WebSocket connections and broadcasting
@@ -22,6 +127,12 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
+
+	sendBuffer   int
+	policy       string
+	dropped      atomic.Int64
+	disconnected atomic.Int64
+	overflowed   atomic.Int64
 }
 
 // LogMessage represents a log entry to broadcast
@@ -38,6 +149,29 @@ func NewHub() *Hub {
 		broadcast:  make(chan *LogMessage, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		sendBuffer: 256,
+		policy:     SlowClientDrop,
+	}
+}
+
+// SetSlowClientPolicy sets how many messages each client can have waiting
+// and what happens to a client whose buffer is full. Call it before any
+// client connects.
+func (h *Hub) SetSlowClientPolicy(policy string, sendBuffer int) {
+	h.policy = policy
+	if sendBuffer > 0 {
+		h.sendBuffer = sendBuffer
+	}
+}
+
+// NewClient creates a client for conn with the hub's send buffer
+func (h *Hub) NewClient(conn *websocket.Conn, userID, projectID string) *Client {
+	return &Client{
+		Conn:      conn,
+		UserID:    userID,
+		ProjectID: projectID,
+		send:      make(chan []byte, h.sendBuffer),
+		done:      make(chan struct{}),
 	}
 }
 
@@ -52,37 +186,36 @@ func (h *Hub) Run() {
 			log.Printf("[WS] Client connected: user=%s, project=%s", client.UserID, client.ProjectID)
 
 		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				client.Conn.Close()
+			if h.remove(client) {
+				log.Printf("[WS] Client disconnected: user=%s", client.UserID)
 			}
-			h.mu.Unlock()
-			log.Printf("[WS] Client disconnected: user=%s", client.UserID)
 
 		case message := <-h.broadcast:
+			data, err := json.Marshal(message)
+			if err != nil {
+				log.Printf("[WS] Error marshaling message: %v", err)
+				continue
+			}
+
 			h.mu.RLock()
 			sentCount := 0
+			var behind []*Client
 			for client := range h.clients {
 				// Send to clients subscribed to this project or all projects
-				if client.ProjectID == "" || client.ProjectID == message.ProjectID {
-					data, err := json.Marshal(message)
-					if err != nil {
-						log.Printf("[WS] Error marshaling message: %v", err)
-						continue
-					}
-
-					if err := client.Conn.WriteMessage(websocket.TextMessage, data); err != nil {
-						log.Printf("[WS] Error sending message to user %s: %v", client.UserID, err)
-						h.mu.RUnlock()
-						h.unregister <- client
-						h.mu.RLock()
-					} else {
-						sentCount++
-					}
+				if client.ProjectID != "" && client.ProjectID != message.ProjectID {
+					continue
 				}
+				if client.Send(data) {
+					sentCount++
+					continue
+				}
+				behind = append(behind, client)
 			}
 			h.mu.RUnlock()
+
+			for _, client := range behind {
+				h.handleSlowClient(client)
+			}
 			if sentCount > 0 {
 				log.Printf("[WS] Broadcast log to %d clients", sentCount)
 			}
@@ -90,6 +223,36 @@ func (h *Hub) Run() {
 	}
 }
 
+// remove deletes client from the hub and ends its WritePump, reporting
+// whether it was still connected
+func (h *Hub) remove(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[client]; !ok {
+		return false
+	}
+	delete(h.clients, client)
+	client.close()
+	return true
+}
+
+// handleSlowClient applies the slow client policy to a client whose send
+// buffer was full
+func (h *Hub) handleSlowClient(client *Client) {
+	if h.policy == SlowClientDisconnect {
+		if h.remove(client) {
+			h.disconnected.Add(1)
+			log.Printf("[WS] Disconnected slow client: user=%s, %d messages waiting", client.UserID, h.sendBuffer)
+		}
+		return
+	}
+
+	client.dropped.Add(1)
+	if dropped := h.dropped.Add(1); dropped == 1 || dropped%1000 == 0 {
+		log.Printf("[WS] Send buffer full for user %s, dropped message (%d dropped so far)", client.UserID, dropped)
+	}
+}
+
 // Register adds a new client to the hub
 func (h *Hub) Register(client *Client) {
 	h.register <- client
@@ -100,13 +263,33 @@ func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
-// BroadcastLog sends a log entry to all relevant clients
+// BroadcastLog sends a log entry to all relevant clients. It never blocks:
+// if the hub has fallen behind, the entry is not broadcast.
 func (h *Hub) BroadcastLog(logData interface{}, projectID string) {
-	h.broadcast <- &LogMessage{
+	message := &LogMessage{
 		Type:      "log",
 		Data:      logData,
 		ProjectID: projectID,
 	}
+	select {
+	case h.broadcast <- message:
+	default:
+		if overflowed := h.overflowed.Add(1); overflowed == 1 || overflowed%1000 == 0 {
+			log.Printf("[WS] Broadcast queue full, skipped log (%d skipped so far)", overflowed)
+		}
+	}
+}
+
+// Stats reports the hub's connections and the messages it could not deliver
+func (h *Hub) Stats() HubStats {
+	return HubStats{
+		Clients:      h.ClientCount(),
+		SendBuffer:   h.sendBuffer,
+		Policy:       h.policy,
+		Dropped:      h.dropped.Load(),
+		Disconnected: h.disconnected.Load(),
+		Overflowed:   h.overflowed.Load(),
+	}
 }
 
 // ClientCount returns the number of connected clients
@@ -0,0 +1,108 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHub_SlowClientDoesNotBlockOthers(t *testing.T) {
+	hub := NewHub()
+	hub.SetSlowClientPolicy(SlowClientDrop, 4)
+	go hub.Run()
+
+	// The slow client never reads; the fast one has room for every message
+	slow := hub.NewClient(nil, "slow", "")
+	fast := &Client{UserID: "fast", ProjectID: "proj-1", send: make(chan []byte, 64)}
+	hub.Register(slow)
+	hub.Register(fast)
+
+	const total = 20
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < total; i++ {
+			hub.BroadcastLog(map[string]int{"n": i}, "proj-1")
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("BroadcastLog blocked on the slow client")
+	}
+
+	for i := 0; i < total; i++ {
+		select {
+		case <-fast.send:
+		case <-time.After(time.Second):
+			t.Fatalf("Fast client received only %d of %d messages", i, total)
+		}
+	}
+
+	waitFor(t, "dropped messages to be counted", func() bool { return slow.Dropped() == total-4 })
+	if fast.Dropped() != 0 {
+		t.Errorf("Expected no drops for the fast client, got %d", fast.Dropped())
+	}
+	stats := hub.Stats()
+	if stats.Clients != 2 || stats.Dropped != total-4 || stats.Disconnected != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if len(slow.send) != 4 {
+		t.Errorf("Expected the slow client's buffer to hold 4 messages, got %d", len(slow.send))
+	}
+}
+
+func TestHub_DisconnectSlowClient(t *testing.T) {
+	hub := NewHub()
+	hub.SetSlowClientPolicy(SlowClientDisconnect, 2)
+	go hub.Run()
+
+	slow := hub.NewClient(nil, "slow", "")
+	other := hub.NewClient(nil, "other", "proj-2")
+	hub.Register(slow)
+	hub.Register(other)
+
+	for i := 0; i < 3; i++ {
+		hub.BroadcastLog(map[string]int{"n": i}, "proj-1")
+	}
+
+	waitFor(t, "the slow client to be disconnected", func() bool { return hub.Stats().Disconnected == 1 })
+	// Its buffered messages can still be written out before the pump stops
+	if !slow.closed() {
+		t.Error("Expected the slow client to be told to finish")
+	}
+	if len(slow.send) != 2 {
+		t.Errorf("Expected 2 buffered messages left to write, got %d", len(slow.send))
+	}
+
+	// The handler's read loop may still answer a ping after the eviction
+	if slow.Send([]byte(`{"type":"pong"}`)) {
+		t.Error("Expected Send to refuse a client the hub has dropped")
+	}
+
+	stats := hub.Stats()
+	if stats.Clients != 1 || stats.Dropped != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if hub.HasActiveConnection("slow") || !hub.HasActiveConnection("other") {
+		t.Error("Expected only the other client to remain connected")
+	}
+
+	// The handler still unregisters the client it was serving; that must not
+	// signal it a second time
+	hub.Unregister(slow)
+	if hub.ClientCount() != 1 {
+		t.Errorf("Expected 1 client, got %d", hub.ClientCount())
+	}
+}
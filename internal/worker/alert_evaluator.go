@@ -0,0 +1,161 @@
+package worker
+
+import (
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// AlertEvaluator periodically checks alert rules against recent log volume
+// and queues a notification for the rule's channels when a threshold is
+// crossed, so alerts get the same retries, rate limits and dead-lettering as
+// log notifications
+type AlertEvaluator struct {
+	alertRuleRepo *models.AlertRuleRepository
+	logRepo       *models.LogRepository
+	channelRepo   *models.ChannelRepository
+	redisClient   *queue.RedisClient
+	interval      time.Duration
+	stopChan      chan struct{}
+}
+
+// NewAlertEvaluator creates a new alert rule evaluator
+func NewAlertEvaluator(
+	alertRuleRepo *models.AlertRuleRepository,
+	logRepo *models.LogRepository,
+	channelRepo *models.ChannelRepository,
+	redisClient *queue.RedisClient,
+	interval time.Duration,
+) *AlertEvaluator {
+	return &AlertEvaluator{
+		alertRuleRepo: alertRuleRepo,
+		logRepo:       logRepo,
+		channelRepo:   channelRepo,
+		redisClient:   redisClient,
+		interval:      interval,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+// Start runs the evaluation loop in the background
+func (e *AlertEvaluator) Start() {
+	log.Printf("Starting alert evaluator (interval: %s)", e.interval)
+
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.stopChan:
+				log.Println("Alert evaluator stopped")
+				return
+			case <-ticker.C:
+				e.Evaluate(time.Now())
+			}
+		}
+	}()
+}
+
+// Stop signals the evaluation loop to stop
+func (e *AlertEvaluator) Stop() {
+	close(e.stopChan)
+}
+
+// Evaluate checks every active rule once and returns the IDs of rules that fired
+func (e *AlertEvaluator) Evaluate(now time.Time) []string {
+	// Without the queue a firing would be lost; leave rules unfired until it is back
+	if e.redisClient == nil || !e.redisClient.Available() {
+		return nil
+	}
+
+	rules, err := e.alertRuleRepo.GetActive()
+	if err != nil {
+		log.Printf("Failed to load alert rules: %v", err)
+		return nil
+	}
+
+	var fired []string
+	for _, rule := range rules {
+		if !rule.CanFire(now) {
+			continue
+		}
+
+		count, err := e.logRepo.CountSince(rule, now.Add(-rule.Window()))
+		if err != nil {
+			log.Printf("Failed to evaluate alert rule %s: %v", rule.ID, err)
+			continue
+		}
+
+		if count < rule.Threshold {
+			continue
+		}
+
+		if err := e.alertRuleRepo.MarkFired(rule.ID, now); err != nil {
+			log.Printf("Failed to mark alert rule %s as fired: %v", rule.ID, err)
+			continue
+		}
+
+		e.fire(rule, count, now)
+		fired = append(fired, rule.ID)
+	}
+
+	return fired
+}
+
+// fire queues the alert for the rule's channel, or for every active project channel
+func (e *AlertEvaluator) fire(rule *models.AlertRule, count int, now time.Time) {
+	var channels []*models.Channel
+	if rule.ChannelID != "" {
+		channel, err := e.channelRepo.GetByID(rule.ChannelID)
+		if err != nil || channel == nil || !channel.IsActive {
+			log.Printf("Alert rule %s has no usable channel %s", rule.ID, rule.ChannelID)
+			return
+		}
+		channels = []*models.Channel{channel}
+	} else {
+		var err error
+		channels, err = e.channelRepo.GetActiveByProjectID(rule.ProjectID)
+		if err != nil {
+			log.Printf("Failed to get channels for alert rule %s: %v", rule.ID, err)
+			return
+		}
+	}
+
+	message := fmt.Sprintf("Alert %q triggered: %d logs at %s or above in the last %s", rule.Name, count, rule.MinLevel, rule.Window())
+	if rule.Source != "" {
+		message += fmt.Sprintf(" from source %s", rule.Source)
+	}
+	if rule.MetadataKey != "" {
+		message += fmt.Sprintf(" with %s=%s", rule.MetadataKey, rule.MetadataValue)
+	}
+
+	queued := 0
+	for _, channel := range channels {
+		job := &queue.NotificationJob{
+			Alert:     true,
+			ChannelID: channel.ID,
+			ProjectID: rule.ProjectID,
+			Level:     string(rule.MinLevel),
+			Message:   message,
+			Source:    rule.Source,
+			Timestamp: now.Format(time.RFC3339),
+			Metadata: map[string]interface{}{
+				"alert_rule_id":  rule.ID,
+				"count":          count,
+				"threshold":      rule.Threshold,
+				"window_seconds": rule.WindowSeconds,
+			},
+		}
+		if err := e.redisClient.EnqueueNotification(context.Background(), job); err != nil {
+			log.Printf("Failed to queue alert for rule %s to channel %s: %v", rule.ID, channel.ID, err)
+			continue
+		}
+		queued++
+	}
+
+	log.Printf("Alert rule %s fired (%d logs) to %d channel(s)", rule.ID, count, queued)
+}
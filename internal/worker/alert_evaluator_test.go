@@ -0,0 +1,254 @@
+package worker
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// setupAlertEvaluatorTestDB adds alert rules to the tables log queries and
+// channel lookups need
+func setupAlertEvaluatorTestDB(t *testing.T) *sql.DB {
+	db := setupEmailDigestTestDB(t)
+	if _, err := db.Exec(`
+		CREATE TABLE alert_rules (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			min_level TEXT NOT NULL DEFAULT 'ERROR',
+			source TEXT,
+			metadata_key TEXT,
+			metadata_value TEXT,
+			threshold INTEGER NOT NULL,
+			window_seconds INTEGER NOT NULL,
+			channel_id TEXT,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			last_fired_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO projects (id, name) VALUES ('proj-1', 'Payments');
+	`); err != nil {
+		t.Fatalf("Failed to create alert_rules table: %v", err)
+	}
+	return db
+}
+
+// takeQueuedJobs empties the notification queue and returns what was on it,
+// oldest first
+func takeQueuedJobs(t *testing.T, server *miniredis.Miniredis) []*queue.NotificationJob {
+	t.Helper()
+	if !server.Exists("notifications:queue") {
+		return nil
+	}
+	items, err := server.List("notifications:queue")
+	if err != nil {
+		t.Fatalf("Failed to read the queue: %v", err)
+	}
+	server.Del("notifications:queue")
+
+	// Jobs are pushed on the left
+	jobs := make([]*queue.NotificationJob, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		var job queue.NotificationJob
+		if err := json.Unmarshal([]byte(items[i]), &job); err != nil {
+			t.Fatalf("Failed to decode job: %v", err)
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs
+}
+
+func addLogs(t *testing.T, logRepo *models.LogRepository, level models.LogLevel, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := logRepo.Create(&models.Log{ProjectID: "proj-1", Level: level, Message: "charge failed", Source: "billing"}); err != nil {
+			t.Fatalf("Failed to create log: %v", err)
+		}
+	}
+}
+
+func TestAlertEvaluator_FiresOnceThresholdIsReached(t *testing.T) {
+	db := setupAlertEvaluatorTestDB(t)
+	defer db.Close()
+
+	server, redisClient := newTestRedis(t)
+	ruleRepo := models.NewAlertRuleRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	evaluator := NewAlertEvaluator(ruleRepo, logRepo, channelRepo, redisClient, time.Minute)
+
+	discord := &models.Channel{ProjectID: "proj-1", Type: models.ChannelTypeDiscord, Name: "Discord",
+		Config: map[string]interface{}{}, MinLevel: models.LogLevelCritical, IsActive: true}
+	muted := &models.Channel{ProjectID: "proj-1", Type: models.ChannelTypeTelegram, Name: "Muted",
+		Config: map[string]interface{}{}, MinLevel: models.LogLevelError, IsActive: false}
+	channelRepo.Create(discord)
+	channelRepo.Create(muted)
+
+	rule := &models.AlertRule{ProjectID: "proj-1", Name: "Billing errors", MinLevel: models.LogLevelError, Source: "billing",
+		Threshold: 3, WindowSeconds: 300, IsActive: true}
+	if err := ruleRepo.Create(rule); err != nil {
+		t.Fatalf("Failed to create rule: %v", err)
+	}
+
+	start := time.Now()
+	addLogs(t, logRepo, models.LogLevelError, 2)
+	addLogs(t, logRepo, models.LogLevelInfo, 5)
+
+	if fired := evaluator.Evaluate(start.Add(time.Second)); len(fired) != 0 {
+		t.Fatalf("Expected no alert below the threshold, got %v", fired)
+	}
+	if jobs := takeQueuedJobs(t, server); len(jobs) != 0 {
+		t.Fatalf("Expected nothing queued below the threshold, got %d jobs", len(jobs))
+	}
+
+	addLogs(t, logRepo, models.LogLevelCritical, 1)
+	firedAt := start.Add(2 * time.Second)
+	if fired := evaluator.Evaluate(firedAt); len(fired) != 1 || fired[0] != rule.ID {
+		t.Fatalf("Expected the rule to fire, got %v", fired)
+	}
+
+	// Only the active channel is notified, whatever its own min level: the
+	// alert itself is what is being sent
+	jobs := takeQueuedJobs(t, server)
+	if len(jobs) != 1 {
+		t.Fatalf("Expected one job for the active channel, got %d", len(jobs))
+	}
+	job := jobs[0]
+	if !job.Alert || job.ChannelID != discord.ID || job.ProjectID != "proj-1" || job.Level != "ERROR" || job.Source != "billing" || job.LogID != "" {
+		t.Errorf("Unexpected alert job: %+v", job)
+	}
+	if job.Message != `Alert "Billing errors" triggered: 3 logs at ERROR or above in the last 5m0s from source billing` {
+		t.Errorf("Unexpected alert message: %q", job.Message)
+	}
+	if job.Metadata["alert_rule_id"] != rule.ID || job.Metadata["count"] != float64(3) || job.Metadata["threshold"] != float64(3) {
+		t.Errorf("Unexpected alert metadata: %+v", job.Metadata)
+	}
+
+	stored, _ := ruleRepo.GetByID(rule.ID)
+	if stored.LastFiredAt == nil || stored.LastFiredAt.Sub(firedAt).Abs() > time.Second {
+		t.Errorf("Expected last_fired_at to be %s, got %v", firedAt, stored.LastFiredAt)
+	}
+}
+
+func TestAlertEvaluator_DeduplicatesWhileActive(t *testing.T) {
+	db := setupAlertEvaluatorTestDB(t)
+	defer db.Close()
+
+	server, redisClient := newTestRedis(t)
+	ruleRepo := models.NewAlertRuleRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	evaluator := NewAlertEvaluator(ruleRepo, logRepo, channelRepo, redisClient, 10*time.Second)
+
+	discord := &models.Channel{ProjectID: "proj-1", Type: models.ChannelTypeDiscord, Name: "Discord",
+		Config: map[string]interface{}{}, MinLevel: models.LogLevelError, IsActive: true}
+	other := &models.Channel{ProjectID: "proj-1", Type: models.ChannelTypeTelegram, Name: "Other",
+		Config: map[string]interface{}{}, MinLevel: models.LogLevelError, IsActive: true}
+	channelRepo.Create(discord)
+	channelRepo.Create(other)
+
+	// A rule bound to one channel notifies only that channel
+	rule := &models.AlertRule{ProjectID: "proj-1", Name: "Errors", MinLevel: models.LogLevelError, ChannelID: discord.ID,
+		Threshold: 2, WindowSeconds: 60, IsActive: true}
+	ruleRepo.Create(rule)
+
+	start := time.Now()
+	addLogs(t, logRepo, models.LogLevelError, 2)
+	if fired := evaluator.Evaluate(start); len(fired) != 1 {
+		t.Fatalf("Expected the rule to fire, got %v", fired)
+	}
+	if jobs := takeQueuedJobs(t, server); len(jobs) != 1 || jobs[0].ChannelID != discord.ID {
+		t.Fatalf("Expected one job for the rule's channel, got %+v", jobs)
+	}
+
+	// Consecutive cycles inside the window stay quiet while errors keep coming
+	for cycle := 1; cycle <= 5; cycle++ {
+		addLogs(t, logRepo, models.LogLevelError, 3)
+		if fired := evaluator.Evaluate(start.Add(time.Duration(cycle) * 10 * time.Second)); len(fired) != 0 {
+			t.Fatalf("Cycle %d: expected no repeat alert inside the window, got %v", cycle, fired)
+		}
+	}
+	if jobs := takeQueuedJobs(t, server); len(jobs) != 0 {
+		t.Fatalf("Expected no repeat jobs inside the window, got %d", len(jobs))
+	}
+
+	// Once the window has passed a rule that is still over threshold fires again
+	later := start.Add(70 * time.Second)
+	if _, err := db.Exec("UPDATE logs SET created_at = ?", later.UTC()); err != nil {
+		t.Fatalf("Failed to move logs: %v", err)
+	}
+	if fired := evaluator.Evaluate(later); len(fired) != 1 {
+		t.Fatalf("Expected the rule to fire again after its window, got %v", fired)
+	}
+	if jobs := takeQueuedJobs(t, server); len(jobs) != 1 {
+		t.Fatalf("Expected one job for the repeat alert, got %d", len(jobs))
+	}
+}
+
+func TestAlertEvaluator_WaitsForRedis(t *testing.T) {
+	db := setupAlertEvaluatorTestDB(t)
+	defer db.Close()
+
+	server, redisClient := newTestRedis(t)
+	ruleRepo := models.NewAlertRuleRepository(db)
+	logRepo := models.NewLogRepository(db)
+	channelRepo := models.NewChannelRepository(db)
+	channelRepo.Create(&models.Channel{ProjectID: "proj-1", Type: models.ChannelTypeDiscord, Name: "Discord",
+		Config: map[string]interface{}{}, MinLevel: models.LogLevelError, IsActive: true})
+
+	rule := &models.AlertRule{ProjectID: "proj-1", Name: "Errors", MinLevel: models.LogLevelError, Threshold: 1, WindowSeconds: 60, IsActive: true}
+	ruleRepo.Create(rule)
+	addLogs(t, logRepo, models.LogLevelError, 1)
+
+	// No queue at all: nothing to do
+	if fired := NewAlertEvaluator(ruleRepo, logRepo, channelRepo, nil, time.Minute).Evaluate(time.Now()); len(fired) != 0 {
+		t.Fatalf("Expected no alert without Redis, got %v", fired)
+	}
+
+	checker := queue.NewHealthChecker(redisClient, 20*time.Millisecond)
+	checker.Start()
+	defer checker.Stop()
+
+	server.Close()
+	waitForRedis(t, redisClient, false)
+
+	evaluator := NewAlertEvaluator(ruleRepo, logRepo, channelRepo, redisClient, time.Minute)
+	if fired := evaluator.Evaluate(time.Now()); len(fired) != 0 {
+		t.Fatalf("Expected no alert while Redis is down, got %v", fired)
+	}
+	// The firing must not be used up while it cannot be delivered
+	if stored, _ := ruleRepo.GetByID(rule.ID); stored.LastFiredAt != nil {
+		t.Fatalf("Expected the rule to stay unfired while Redis is down, got %v", stored.LastFiredAt)
+	}
+
+	if err := server.Restart(); err != nil {
+		t.Fatalf("Failed to restart Redis: %v", err)
+	}
+	waitForRedis(t, redisClient, true)
+
+	if fired := evaluator.Evaluate(time.Now()); len(fired) != 1 {
+		t.Fatalf("Expected the rule to fire once Redis is back, got %v", fired)
+	}
+	if jobs := takeQueuedJobs(t, server); len(jobs) != 1 {
+		t.Fatalf("Expected one queued alert, got %d", len(jobs))
+	}
+}
+
+// waitForRedis polls until the health checker reports Redis as available or not
+func waitForRedis(t *testing.T, client *queue.RedisClient, available bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for client.Available() != available {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for Redis available=%v", available)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
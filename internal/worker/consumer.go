@@ -4,16 +4,39 @@ import (
 	"central-logs/internal/models"
 	"central-logs/internal/queue"
 	"context"
+	"encoding/json"
+	"errors"
 	"log"
 	"time"
 )
 
+// RetryPolicy controls how failed notification jobs are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total delivery attempts before dead-lettering
+	BackoffBase time.Duration // Delay before the first retry, doubled on each further attempt
+}
+
+// Backoff returns the delay before retrying after the given number of failed attempts
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := p.BackoffBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+	}
+	return delay
+}
+
 // NotificationConsumer processes notification jobs from Redis queue
 type NotificationConsumer struct {
 	redisClient *queue.RedisClient
+	rateLimiter *queue.RateLimiter
 	notifier    *Notifier
 	channelRepo *models.ChannelRepository
 	logRepo     *models.LogRepository
+	failedRepo  *models.FailedNotificationRepository
+	retryPolicy RetryPolicy
 	stopChan    chan struct{}
 }
 
@@ -23,14 +46,22 @@ func NewNotificationConsumer(
 	notifier *Notifier,
 	channelRepo *models.ChannelRepository,
 	logRepo *models.LogRepository,
+	failedRepo *models.FailedNotificationRepository,
+	retryPolicy RetryPolicy,
 ) *NotificationConsumer {
-	return &NotificationConsumer{
+	nc := &NotificationConsumer{
 		redisClient: redisClient,
 		notifier:    notifier,
 		channelRepo: channelRepo,
 		logRepo:     logRepo,
+		failedRepo:  failedRepo,
+		retryPolicy: retryPolicy,
 		stopChan:    make(chan struct{}),
 	}
+	if redisClient != nil {
+		nc.rateLimiter = redisClient.RateLimiter()
+	}
+	return nc
 }
 
 // Start begins consuming notification jobs from the queue
@@ -40,6 +71,32 @@ func (nc *NotificationConsumer) Start(workers int) {
 	for i := 0; i < workers; i++ {
 		go nc.worker(i)
 	}
+
+	go nc.scheduler()
+}
+
+// scheduler moves retry jobs back onto the main queue once their backoff has
+// elapsed and flushes digest windows that have ended
+func (nc *NotificationConsumer) scheduler() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	ctx := context.Background()
+	for {
+		select {
+		case <-nc.stopChan:
+			return
+		case <-ticker.C:
+			if !nc.redisClient.Available() {
+				continue
+			}
+			now := time.Now()
+			if _, err := nc.redisClient.PromoteDueRetries(ctx, now); err != nil {
+				log.Printf("Failed to promote notification retries: %v", err)
+			}
+			nc.flushDigests(now)
+		}
+	}
 }
 
 // Stop signals all workers to stop
@@ -62,6 +119,11 @@ func (nc *NotificationConsumer) worker(id int) {
 		default:
 			// Dequeue with timeout to allow graceful shutdown
 			job, err := nc.redisClient.DequeueNotification(ctx, 5*time.Second)
+			if errors.Is(err, queue.ErrUnavailable) {
+				// The health check has already logged the outage
+				time.Sleep(time.Second)
+				continue
+			}
 			if err != nil {
 				log.Printf("Worker #%d: Error dequeuing notification: %v", id, err)
 				time.Sleep(time.Second)
@@ -98,27 +160,147 @@ func (nc *NotificationConsumer) processJob(job *queue.NotificationJob) {
 		return
 	}
 
-	// Get the log entry
-	logEntry, err := nc.logRepo.GetByID(job.LogID)
+	// Test jobs are sent straight away and never retried, so the caller
+	// polling the job sees the first attempt's outcome
+	if job.Test {
+		if err := nc.notifier.SendJob(channel, SampleLogEntry(job), job.JobID); err != nil {
+			log.Printf("Test notification %s to channel %s failed: %v", job.JobID, job.ChannelID, err)
+		}
+		return
+	}
+
+	// Digest channels buffer the job and send one summary per window; a
+	// retried summary is sent as is
+	if digest := channel.GetDigestConfig(); digest != nil && !job.Digest {
+		nc.addToDigest(channel, digest, job, time.Now())
+		return
+	}
+
+	// Get the log entry; alerts and digests carry their own message instead of a stored log
+	var logEntry *models.Log
+	if job.Alert || job.Digest {
+		logEntry = SampleLogEntry(job)
+	} else {
+		logEntry, err = nc.logRepo.GetByID(job.LogID)
+		if err != nil {
+			log.Printf("Failed to get log %s: %v", job.LogID, err)
+			return
+		}
+
+		if logEntry == nil {
+			log.Printf("Log %s not found", job.LogID)
+			return
+		}
+	}
+
+	// Over the channel's per-minute limit: hold the job until the next
+	// window instead of spending one of its delivery attempts
+	if !nc.allowSend(channel) {
+		retryAt := time.Now().Truncate(time.Minute).Add(time.Minute)
+		log.Printf("Channel %s is over its rate limit, delaying notification for log %s until %s",
+			job.ChannelID, job.LogID, retryAt.Format(time.RFC3339))
+		if err := nc.redisClient.ScheduleNotificationRetry(context.Background(), job, retryAt); err != nil {
+			log.Printf("Failed to delay rate limited notification: %v", err)
+		}
+		return
+	}
+
+	if err := nc.notifier.SendJob(channel, logEntry, job.JobID); err != nil {
+		nc.handleFailure(job, err)
+	}
+}
+
+// allowSend reports whether the channel is still within the messages per
+// minute configured for its type. Sends are allowed when there is no limit
+// or the limiter itself is unavailable.
+func (nc *NotificationConsumer) allowSend(channel *models.Channel) bool {
+	if nc.rateLimiter == nil {
+		return true
+	}
+	limit := nc.notifier.config.Get().GetChannelRateLimit(string(channel.Type))
+	if limit <= 0 {
+		return true
+	}
+
+	allowed, err := nc.rateLimiter.AllowChannel(context.Background(), channel.ID, limit)
 	if err != nil {
-		log.Printf("Failed to get log %s: %v", job.LogID, err)
+		log.Printf("Failed to check rate limit for channel %s: %v", channel.ID, err)
+		return true
+	}
+	return allowed
+}
+
+// SampleLogEntry builds the log a channel test, alert or digest job delivers from its fields
+func SampleLogEntry(job *queue.NotificationJob) *models.Log {
+	timestamp, err := time.Parse(time.RFC3339, job.Timestamp)
+	if err != nil {
+		timestamp = time.Now()
+	}
+	return &models.Log{
+		ID:        job.LogID,
+		ProjectID: job.ProjectID,
+		Level:     models.LogLevel(job.Level),
+		Message:   job.Message,
+		Source:    job.Source,
+		Metadata:  job.Metadata,
+		Timestamp: timestamp,
+		CreatedAt: timestamp,
+	}
+}
+
+// handleFailure schedules a retry with exponential backoff, or dead-letters
+// the job once it has used all of its attempts
+func (nc *NotificationConsumer) handleFailure(job *queue.NotificationJob, sendErr error) {
+	ctx := context.Background()
+
+	// Cut off by shutdown rather than failed: requeue it for the next start
+	// without spending an attempt
+	if errors.Is(sendErr, ErrNotifierStopped) {
+		if err := nc.redisClient.ScheduleNotificationRetry(ctx, job, time.Now()); err != nil {
+			log.Printf("Failed to requeue interrupted notification: %v", err)
+		}
+		return
+	}
+
+	job.Attempts++
+	job.LastError = sendErr.Error()
+
+	if job.Attempts < nc.retryPolicy.MaxAttempts {
+		delay := nc.retryPolicy.Backoff(job.Attempts)
+		log.Printf("Notification for log %s to channel %s failed (attempt %d/%d), retrying in %s: %v",
+			job.LogID, job.ChannelID, job.Attempts, nc.retryPolicy.MaxAttempts, delay, sendErr)
+		if err := nc.redisClient.ScheduleNotificationRetry(ctx, job, time.Now().Add(delay)); err != nil {
+			log.Printf("Failed to schedule notification retry: %v", err)
+		}
 		return
 	}
 
-	if logEntry == nil {
-		log.Printf("Log %s not found", job.LogID)
+	log.Printf("Notification for log %s to channel %s failed permanently after %d attempts: %v",
+		job.LogID, job.ChannelID, job.Attempts, sendErr)
+
+	if err := nc.redisClient.DeadLetterNotification(ctx, job); err != nil {
+		log.Printf("Failed to dead-letter notification: %v", err)
+	}
+
+	if nc.failedRepo == nil {
 		return
 	}
 
-	// Send notification based on channel type
-	switch channel.Type {
-	case models.ChannelTypeTelegram:
-		nc.notifier.sendTelegram(channel, logEntry)
-	case models.ChannelTypeDiscord:
-		nc.notifier.sendDiscord(channel, logEntry)
-	case models.ChannelTypePush:
-		nc.notifier.sendPush(channel, logEntry)
-	default:
-		log.Printf("Unknown channel type: %s", channel.Type)
+	payload, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("Failed to marshal dead-lettered job: %v", err)
+		return
+	}
+
+	failure := &models.FailedNotification{
+		ChannelID: job.ChannelID,
+		ProjectID: job.ProjectID,
+		LogID:     job.LogID,
+		Payload:   payload,
+		Attempts:  job.Attempts,
+		LastError: job.LastError,
+	}
+	if err := nc.failedRepo.Create(failure); err != nil {
+		log.Printf("Failed to record failed notification: %v", err)
 	}
 }
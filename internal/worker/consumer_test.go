@@ -0,0 +1,175 @@
+package worker
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"central-logs/internal/config"
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+
+	"github.com/alicebob/miniredis/v2"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const consumerTestSchema = `
+	CREATE TABLE channels (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		name TEXT NOT NULL,
+		config TEXT NOT NULL,
+		min_level TEXT NOT NULL,
+		is_active BOOLEAN NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE channel_deliveries (
+		id TEXT PRIMARY KEY,
+		channel_id TEXT NOT NULL,
+		job_id TEXT,
+		success BOOLEAN NOT NULL,
+		error TEXT,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+`
+
+func setupConsumerTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(consumerTestSchema); err != nil {
+		t.Fatalf("Failed to create tables: %v", err)
+	}
+	return db
+}
+
+// newTestRedis starts an in-memory Redis for the test and connects to it
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *queue.RedisClient) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client, err := queue.NewRedisClient("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("Failed to connect to test Redis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestNotificationConsumer_ProcessTestJob(t *testing.T) {
+	db := setupConsumerTestDB(t)
+	defer db.Close()
+
+	channelRepo := models.NewChannelRepository(db)
+	deliveryRepo := models.NewChannelDeliveryRepository(db)
+	notifier := NewNotifier(channelRepo, config.NewHolder(config.DefaultConfig()))
+	notifier.SetDeliveryRecorder(deliveryRepo)
+	consumer := NewNotificationConsumer(nil, notifier, channelRepo, nil, nil, RetryPolicy{})
+
+	discord := &models.Channel{ProjectID: "p1", Type: models.ChannelTypeDiscord, Name: "Discord",
+		Config: map[string]interface{}{"webhook_url": "https://discord.test/hook"}, MinLevel: models.LogLevelCritical, IsActive: true}
+	broken := &models.Channel{ProjectID: "p1", Type: "CARRIER_PIGEON", Name: "Broken",
+		Config: map[string]interface{}{}, MinLevel: models.LogLevelCritical, IsActive: true}
+	channelRepo.Create(discord)
+	channelRepo.Create(broken)
+
+	// Test jobs bypass the channel's min level and need no stored log
+	consumer.processJob(&queue.NotificationJob{JobID: "job-ok", Test: true, ChannelID: discord.ID, Level: "INFO", Message: "hello"})
+	consumer.processJob(&queue.NotificationJob{JobID: "job-bad", Test: true, ChannelID: broken.ID, Level: "INFO", Message: "hello"})
+
+	ok, err := deliveryRepo.GetLatestByJobID("job-ok")
+	if err != nil || ok == nil {
+		t.Fatalf("Expected a delivery recorded for job-ok, got %v (%v)", ok, err)
+	}
+	if !ok.Success || ok.ChannelID != discord.ID {
+		t.Errorf("Expected a successful delivery to the discord channel, got %+v", ok)
+	}
+
+	bad, err := deliveryRepo.GetLatestByJobID("job-bad")
+	if err != nil || bad == nil {
+		t.Fatalf("Expected a delivery recorded for job-bad, got %v (%v)", bad, err)
+	}
+	if bad.Success || !strings.Contains(bad.Error, "unknown channel type") {
+		t.Errorf("Expected a failed delivery with the send error, got %+v", bad)
+	}
+}
+
+func TestNotificationConsumer_ProcessAlertJob(t *testing.T) {
+	db := setupConsumerTestDB(t)
+	defer db.Close()
+
+	channelRepo := models.NewChannelRepository(db)
+	deliveryRepo := models.NewChannelDeliveryRepository(db)
+	notifier := NewNotifier(channelRepo, config.NewHolder(config.DefaultConfig()))
+	notifier.SetDeliveryRecorder(deliveryRepo)
+	// No log repository: alert jobs must not look up a stored log
+	consumer := NewNotificationConsumer(nil, notifier, channelRepo, nil, nil, RetryPolicy{})
+
+	discord := &models.Channel{ProjectID: "p1", Type: models.ChannelTypeDiscord, Name: "Discord",
+		Config: map[string]interface{}{"webhook_url": "https://discord.test/hook"}, MinLevel: models.LogLevelError, IsActive: true}
+	channelRepo.Create(discord)
+
+	consumer.processJob(&queue.NotificationJob{JobID: "job-alert", Alert: true, ChannelID: discord.ID, ProjectID: "p1",
+		Level: "ERROR", Message: `Alert "errors" triggered`, Metadata: map[string]interface{}{"alert_rule_id": "rule-1"}})
+
+	delivery, err := deliveryRepo.GetLatestByJobID("job-alert")
+	if err != nil || delivery == nil {
+		t.Fatalf("Expected a delivery recorded for the alert job, got %v (%v)", delivery, err)
+	}
+	if !delivery.Success || delivery.ChannelID != discord.ID {
+		t.Errorf("Expected a successful delivery to the discord channel, got %+v", delivery)
+	}
+}
+
+func TestSampleLogEntry(t *testing.T) {
+	job := &queue.NotificationJob{LogID: "log-1", ProjectID: "p1", Level: "WARN", Message: "disk", Source: "api", Timestamp: "2025-02-01T10:00:00Z",
+		Metadata: map[string]interface{}{"alert_rule_id": "rule-1"}}
+	entry := SampleLogEntry(job)
+
+	if entry.Level != models.LogLevelWarn || entry.Message != "disk" || entry.Source != "api" || entry.Metadata["alert_rule_id"] != "rule-1" {
+		t.Errorf("Expected the job fields to be copied, got %+v", entry)
+	}
+	if entry.Timestamp.Year() != 2025 || entry.Timestamp.Hour() != 10 {
+		t.Errorf("Expected the job timestamp to be parsed, got %v", entry.Timestamp)
+	}
+}
+
+func TestNotificationConsumer_RateLimitFollowsReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.DefaultConfig()
+	cfg.RateLimit.Channels.Apprise.MessagesPerMinute = 1
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	holder := config.NewHolder(cfg)
+
+	_, redisClient := newTestRedis(t)
+	consumer := NewNotificationConsumer(redisClient, NewNotifier(nil, holder), nil, nil, nil, RetryPolicy{})
+	channel := &models.Channel{ID: "ch-apprise", Type: models.ChannelTypeApprise}
+
+	if !consumer.allowSend(channel) {
+		t.Fatal("Expected the first message of the minute to be allowed")
+	}
+	if consumer.allowSend(channel) {
+		t.Fatal("Expected the second message to exceed a limit of 1")
+	}
+
+	edited := config.DefaultConfig()
+	edited.RateLimit.Channels.Apprise.MessagesPerMinute = 3
+	if err := config.Save(path, edited); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := holder.Reload(path); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	// The count for this minute carries over; the raised limit applies at once
+	if !consumer.allowSend(channel) {
+		t.Error("Expected the reloaded limit of 3 to allow another message")
+	}
+}
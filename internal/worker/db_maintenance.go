@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"central-logs/internal/database"
+	"central-logs/internal/utils"
+)
+
+// dbMaintenanceTick is how often the schedule is checked. It is under a
+// minute so no scheduled minute is skipped; each minute runs at most once.
+const dbMaintenanceTick = 30 * time.Second
+
+// ErrMaintenanceRunning is returned when maintenance is asked for while a run
+// is still in progress
+var ErrMaintenanceRunning = errors.New("database maintenance is already running")
+
+// DBMaintenance runs database compaction on a cron schedule and on demand,
+// never two runs at once
+type DBMaintenance struct {
+	db       *database.DB
+	schedule *utils.CronSchedule // nil when runs are only started by hand
+	running  atomic.Bool
+	stopChan chan struct{}
+
+	mu      sync.Mutex
+	lastRun time.Time // Minute of the last scheduled run
+}
+
+// NewDBMaintenance creates a maintenance job for db. With a nil schedule,
+// Start does nothing and only Run starts maintenance.
+func NewDBMaintenance(db *database.DB, schedule *utils.CronSchedule) *DBMaintenance {
+	return &DBMaintenance{
+		db:       db,
+		schedule: schedule,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs maintenance in the background whenever the schedule fires
+func (m *DBMaintenance) Start() {
+	if m.schedule == nil {
+		return
+	}
+	log.Println("Starting database maintenance scheduler")
+
+	go func() {
+		ticker := time.NewTicker(dbMaintenanceTick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stopChan:
+				log.Println("Database maintenance scheduler stopped")
+				return
+			case now := <-ticker.C:
+				m.tick(now)
+			}
+		}
+	}()
+}
+
+// Stop ends the schedule. A run in progress finishes on its own.
+func (m *DBMaintenance) Stop() {
+	close(m.stopChan)
+}
+
+// tick starts a run when the schedule fires in now's minute and it has not
+// already run in it
+func (m *DBMaintenance) tick(now time.Time) {
+	minute := now.Truncate(time.Minute)
+	if !m.schedule.Matches(minute) {
+		return
+	}
+	m.mu.Lock()
+	due := !m.lastRun.Equal(minute)
+	m.lastRun = minute
+	m.mu.Unlock()
+	if !due {
+		return
+	}
+
+	result, err := m.Run(context.Background())
+	switch {
+	case errors.Is(err, ErrMaintenanceRunning):
+		log.Println("Skipping scheduled database maintenance: a manual run is still in progress")
+	case err != nil:
+		log.Printf("Scheduled database maintenance failed: %v", err)
+	default:
+		log.Printf("Database maintenance (%s) reclaimed %d bytes in %dms", result.Method, result.BytesReclaimed, result.DurationMS)
+	}
+}
+
+// Run performs maintenance now, or returns ErrMaintenanceRunning if a run is
+// already in progress
+func (m *DBMaintenance) Run(ctx context.Context) (*database.MaintenanceResult, error) {
+	if !m.running.CompareAndSwap(false, true) {
+		return nil, ErrMaintenanceRunning
+	}
+	defer m.running.Store(false)
+
+	return m.db.Maintain(ctx)
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// digestWindow returns the aligned window containing t. Windows are half-open,
+// so a job arriving exactly at a boundary starts the next window.
+func digestWindow(t time.Time, window time.Duration) (time.Time, time.Time) {
+	start := t.Truncate(window)
+	return start, start.Add(window)
+}
+
+// buildDigestMessage summarizes a window of coalesced jobs, returning the
+// highest level seen and a message with per-level counts and a few samples
+func buildDigestMessage(projectName string, jobs []*queue.NotificationJob, window time.Duration, maxSamples int) (models.LogLevel, string) {
+	counts := make(map[models.LogLevel]int)
+	highest := models.LogLevelDebug
+	for _, job := range jobs {
+		level := models.ParseLogLevel(job.Level)
+		counts[level]++
+		if level.Priority() > highest.Priority() {
+			highest = level
+		}
+	}
+
+	var parts []string
+	for i := len(models.AllLogLevels) - 1; i >= 0; i-- {
+		level := models.AllLogLevels[i]
+		if counts[level] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[level], level))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s logs in project %s in the last %s", strings.Join(parts, ", "), projectName, window)
+
+	if maxSamples > len(jobs) {
+		maxSamples = len(jobs)
+	}
+	if maxSamples > 0 {
+		b.WriteString("\n\nSamples:")
+		for _, job := range jobs[:maxSamples] {
+			fmt.Fprintf(&b, "\n- [%s] %s", job.Level, job.Message)
+		}
+		if remaining := len(jobs) - maxSamples; remaining > 0 {
+			fmt.Fprintf(&b, "\n...and %d more", remaining)
+		}
+	}
+
+	return highest, b.String()
+}
+
+// addToDigest buffers a job in the channel's current digest window
+func (nc *NotificationConsumer) addToDigest(channel *models.Channel, digest *models.DigestConfig, job *queue.NotificationJob, now time.Time) {
+	start, end := digestWindow(now, digest.Window())
+	if err := nc.redisClient.AddToDigest(context.Background(), channel.ID, job, start, end); err != nil {
+		log.Printf("Failed to add log %s to digest for channel %s: %v", job.LogID, channel.ID, err)
+	}
+}
+
+// flushDigests sends one summary message for every digest window that has
+// ended. A summary that fails to send is retried and dead-lettered like any
+// other notification.
+func (nc *NotificationConsumer) flushDigests(now time.Time) {
+	batches, err := nc.redisClient.PopDueDigests(context.Background(), now)
+	if err != nil {
+		log.Printf("Failed to load due digests: %v", err)
+	}
+
+	for _, batch := range batches {
+		channel, err := nc.channelRepo.GetByID(batch.ChannelID)
+		if err != nil || channel == nil || !channel.IsActive {
+			continue
+		}
+
+		digest := channel.GetDigestConfig()
+		if digest == nil {
+			// Digest mode was turned off mid-window; still summarize what was buffered
+			digest = &models.DigestConfig{WindowSeconds: 60, MaxSamples: 3}
+		}
+
+		projectName := batch.Jobs[0].ProjectID
+		if entry, err := nc.logRepo.GetByID(batch.Jobs[0].LogID); err == nil && entry != nil {
+			projectName = entry.ProjectName
+		}
+
+		level, message := buildDigestMessage(projectName, batch.Jobs, digest.Window(), digest.MaxSamples)
+		summary := &models.Log{
+			ProjectID:   batch.Jobs[0].ProjectID,
+			ProjectName: projectName,
+			Level:       level,
+			Message:     message,
+			Source:      "digest",
+			Timestamp:   now,
+			CreatedAt:   now,
+		}
+
+		if err := nc.notifier.Send(channel, summary); err != nil {
+			nc.handleFailure(digestJob(channel, summary), err)
+		}
+	}
+}
+
+// digestJob is the queued form of a digest summary, so a failed send can be
+// retried without the jobs it summarized
+func digestJob(channel *models.Channel, summary *models.Log) *queue.NotificationJob {
+	return &queue.NotificationJob{
+		Digest:    true,
+		ChannelID: channel.ID,
+		ProjectID: summary.ProjectID,
+		Level:     string(summary.Level),
+		Message:   summary.Message,
+		Source:    summary.Source,
+		Timestamp: summary.Timestamp.Format(time.RFC3339),
+	}
+}
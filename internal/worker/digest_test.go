@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"central-logs/internal/config"
+	"central-logs/internal/models"
+	"central-logs/internal/queue"
+)
+
+func TestDigestWindow_Boundaries(t *testing.T) {
+	window := time.Minute
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		wantStart time.Time
+	}{
+		{"exactly at window start", base, base},
+		{"inside the window", base.Add(30 * time.Second), base},
+		{"last instant of the window", base.Add(time.Minute - time.Nanosecond), base},
+		{"exactly at window end starts the next window", base.Add(time.Minute), base.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := digestWindow(tt.at, window)
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("Expected window start %s, got %s", tt.wantStart, start)
+			}
+			if !end.Equal(tt.wantStart.Add(window)) {
+				t.Errorf("Expected window end %s, got %s", tt.wantStart.Add(window), end)
+			}
+		})
+	}
+
+	// Jobs on either side of a boundary must never share a window
+	s1, _ := digestWindow(base.Add(59*time.Second), window)
+	s2, _ := digestWindow(base.Add(60*time.Second), window)
+	if s1.Equal(s2) {
+		t.Error("Jobs across a window boundary should land in different windows")
+	}
+}
+
+func TestBuildDigestMessage(t *testing.T) {
+	var jobs []*queue.NotificationJob
+	for i := 0; i < 40; i++ {
+		jobs = append(jobs, &queue.NotificationJob{Level: "ERROR", Message: "db timeout"})
+	}
+	jobs = append(jobs, &queue.NotificationJob{Level: "CRITICAL", Message: "db down"})
+	jobs = append(jobs, &queue.NotificationJob{Level: "CRITICAL", Message: "db down"})
+
+	level, message := buildDigestMessage("Payments", jobs, time.Minute, 3)
+
+	if level != models.LogLevelCritical {
+		t.Errorf("Expected highest level CRITICAL, got %s", level)
+	}
+	if !strings.HasPrefix(message, "2 CRITICAL, 40 ERROR logs in project Payments in the last 1m0s") {
+		t.Errorf("Unexpected summary: %q", message)
+	}
+	if strings.Count(message, "\n- ") != 3 {
+		t.Errorf("Expected 3 samples, got message %q", message)
+	}
+	if !strings.Contains(message, "...and 39 more") {
+		t.Errorf("Expected remaining count, got %q", message)
+	}
+}
+
+func TestChannel_GetDigestConfig(t *testing.T) {
+	plain := &models.Channel{Config: map[string]interface{}{"chat_id": "1"}}
+	if plain.GetDigestConfig() != nil {
+		t.Error("Channels without digest config should send per log")
+	}
+
+	digest := &models.Channel{Config: map[string]interface{}{
+		"digest": map[string]interface{}{"enabled": true},
+	}}
+	cfg := digest.GetDigestConfig()
+	if cfg == nil || cfg.WindowSeconds != 60 || cfg.MaxSamples != 3 {
+		t.Errorf("Expected default digest settings, got %+v", cfg)
+	}
+}
+
+func TestNotificationConsumer_RetriedDigestIsSentAsIs(t *testing.T) {
+	db := setupConsumerTestDB(t)
+	defer db.Close()
+
+	channelRepo := models.NewChannelRepository(db)
+	deliveryRepo := models.NewChannelDeliveryRepository(db)
+	notifier := NewNotifier(channelRepo, config.NewHolder(config.DefaultConfig()))
+	notifier.SetDeliveryRecorder(deliveryRepo)
+	// No Redis: buffering the summary into another digest would fail
+	consumer := NewNotificationConsumer(nil, notifier, channelRepo, nil, nil, RetryPolicy{})
+
+	channel := &models.Channel{ProjectID: "p1", Type: models.ChannelTypeDiscord, Name: "Discord", MinLevel: models.LogLevelError, IsActive: true,
+		Config: map[string]interface{}{"webhook_url": "https://discord.test/hook", "digest": map[string]interface{}{"enabled": true}}}
+	channelRepo.Create(channel)
+
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	summary := &models.Log{ProjectID: "p1", Level: models.LogLevelError, Message: "3 ERROR logs in project p1", Source: "digest", Timestamp: now}
+	job := digestJob(channel, summary)
+	if !job.Digest || job.ChannelID != channel.ID || job.Message != summary.Message || job.Timestamp != "2025-02-01T10:00:00Z" {
+		t.Fatalf("Expected the summary to be carried by the job, got %+v", job)
+	}
+
+	job.JobID = "job-digest"
+	consumer.processJob(job)
+
+	delivery, err := deliveryRepo.GetLatestByJobID("job-digest")
+	if err != nil || delivery == nil {
+		t.Fatalf("Expected the retried digest to be delivered, got %v (%v)", delivery, err)
+	}
+	if !delivery.Success {
+		t.Errorf("Expected a successful delivery, got %+v", delivery)
+	}
+}
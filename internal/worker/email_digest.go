@@ -0,0 +1,257 @@
+package worker
+
+import (
+	"central-logs/internal/models"
+	"central-logs/internal/services/mail"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// emailDigestTick is how often schedules are checked, which bounds how
+	// late after its scheduled time a digest goes out
+	emailDigestTick = time.Minute
+	// emailDigestRetryDelay spaces out attempts after a failed send
+	emailDigestRetryDelay = 15 * time.Minute
+	// Entries listed per section of a digest
+	emailDigestTopSources = 5
+	emailDigestTopErrors  = 5
+)
+
+// EmailDigest summarizes a project's logs over one digest period
+type EmailDigest struct {
+	ProjectName   string
+	Schedule      string
+	From          time.Time
+	To            time.Time
+	Total         int
+	Levels        map[models.LogLevel]int
+	TopSources    []models.FacetCount
+	NotableErrors []models.FacetCount // Most repeated ERROR and CRITICAL messages
+}
+
+// BuildEmailDigest compiles the digest of a project's logs between from and to
+func BuildEmailDigest(logRepo *models.LogRepository, projectID, projectName, schedule string, from, to time.Time) (*EmailDigest, error) {
+	filter := &models.LogFilter{ProjectIDs: []string{projectID}, StartTime: &from, EndTime: &to}
+
+	levels, err := logRepo.FacetByLevel(filter)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := logRepo.FacetBySource(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) > emailDigestTopSources {
+		sources = sources[:emailDigestTopSources]
+	}
+
+	errorFilter := *filter
+	errorFilter.Levels = []models.LogLevel{models.LogLevelError, models.LogLevelCritical}
+	notable, err := logRepo.FacetByMessage(&errorFilter, emailDigestTopErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &EmailDigest{
+		ProjectName:   projectName,
+		Schedule:      schedule,
+		From:          from,
+		To:            to,
+		Levels:        make(map[models.LogLevel]int),
+		TopSources:    sources,
+		NotableErrors: notable,
+	}
+	for _, facet := range levels {
+		digest.Levels[models.LogLevel(facet.Value)] = facet.Count
+		digest.Total += facet.Count
+	}
+	return digest, nil
+}
+
+// Subject returns the email subject line for the digest
+func (d *EmailDigest) Subject() string {
+	title := "Daily"
+	if d.Schedule == "weekly" {
+		title = "Weekly"
+	}
+	subject := fmt.Sprintf("[Central Logs] %s digest for %s: %d logs", title, d.ProjectName, d.Total)
+	if problems := d.Levels[models.LogLevelError] + d.Levels[models.LogLevelCritical]; problems > 0 {
+		subject += fmt.Sprintf(", %d errors", problems)
+	}
+	return subject
+}
+
+// Body renders the digest as plain text
+func (d *EmailDigest) Body() string {
+	var b strings.Builder
+	const layout = "2006-01-02 15:04 MST"
+	fmt.Fprintf(&b, "Log activity for project %s\n%s to %s\n\n", d.ProjectName, d.From.Format(layout), d.To.Format(layout))
+
+	if d.Total == 0 {
+		b.WriteString("No logs were received in this period.\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Total logs: %d\n\nBy level:\n", d.Total)
+	for i := len(models.AllLogLevels) - 1; i >= 0; i-- {
+		level := models.AllLogLevels[i]
+		fmt.Fprintf(&b, "  %-9s %d\n", level, d.Levels[level])
+	}
+
+	if len(d.TopSources) > 0 {
+		b.WriteString("\nTop sources:\n")
+		for _, source := range d.TopSources {
+			name := source.Value
+			if name == "" {
+				name = "(no source)"
+			}
+			fmt.Fprintf(&b, "  %-24s %d\n", name, source.Count)
+		}
+	}
+
+	if len(d.NotableErrors) > 0 {
+		b.WriteString("\nMost frequent errors:\n")
+		for _, e := range d.NotableErrors {
+			fmt.Fprintf(&b, "  %5dx  %s\n", e.Count, truncateLine(e.Value, 160))
+		}
+	}
+
+	return b.String()
+}
+
+// truncateLine keeps the first line of s, cut to max runes
+func truncateLine(s string, max int) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " ..."
+	}
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max]) + "..."
+	}
+	return s
+}
+
+// EmailDigestScheduler sends each active EMAIL channel its daily or weekly
+// digest once the period has ended. Sends are recorded as channel deliveries
+// under a job ID per channel and period, so a digest goes out only once even
+// across restarts.
+type EmailDigestScheduler struct {
+	channelRepo  *models.ChannelRepository
+	logRepo      *models.LogRepository
+	projectRepo  *models.ProjectRepository
+	deliveryRepo *models.ChannelDeliveryRepository
+	mailer       mail.Mailer
+	stopChan     chan struct{}
+}
+
+// NewEmailDigestScheduler creates a new digest scheduler
+func NewEmailDigestScheduler(
+	channelRepo *models.ChannelRepository,
+	logRepo *models.LogRepository,
+	projectRepo *models.ProjectRepository,
+	deliveryRepo *models.ChannelDeliveryRepository,
+	mailer mail.Mailer,
+) *EmailDigestScheduler {
+	return &EmailDigestScheduler{
+		channelRepo:  channelRepo,
+		logRepo:      logRepo,
+		projectRepo:  projectRepo,
+		deliveryRepo: deliveryRepo,
+		mailer:       mailer,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start runs the schedule check in the background
+func (s *EmailDigestScheduler) Start() {
+	log.Println("Starting email digest scheduler")
+
+	go func() {
+		ticker := time.NewTicker(emailDigestTick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopChan:
+				log.Println("Email digest scheduler stopped")
+				return
+			case <-ticker.C:
+				s.Run(time.Now())
+			}
+		}
+	}()
+}
+
+// Stop signals the schedule check to stop
+func (s *EmailDigestScheduler) Stop() {
+	close(s.stopChan)
+}
+
+// Run sends every digest that is due at now and returns the IDs of the
+// channels that were sent one
+func (s *EmailDigestScheduler) Run(now time.Time) []string {
+	channels, err := s.channelRepo.GetActiveByType(models.ChannelTypeEmail)
+	if err != nil {
+		log.Printf("Failed to load email channels: %v", err)
+		return nil
+	}
+
+	var sent []string
+	for _, channel := range channels {
+		cfg := channel.GetEmailDigestConfig()
+		if cfg == nil {
+			log.Printf("Email channel %s has an unreadable config", channel.ID)
+			continue
+		}
+		if field, err := cfg.Validate(); err != nil {
+			log.Printf("Email channel %s has an invalid %s: %v", channel.ID, field, err)
+			continue
+		}
+
+		from, to, err := cfg.Period(now)
+		if err != nil || to.Before(channel.CreatedAt) {
+			continue
+		}
+
+		jobID := fmt.Sprintf("digest:%s:%s", channel.ID, to.UTC().Format(time.RFC3339))
+		previous, err := s.deliveryRepo.GetLatestByJobID(jobID)
+		if err != nil {
+			log.Printf("Failed to check digest %s: %v", jobID, err)
+			continue
+		}
+		if previous != nil && (previous.Success || time.Since(previous.CreatedAt) < emailDigestRetryDelay) {
+			continue
+		}
+
+		sendErr := s.send(channel, cfg, from, to)
+		if err := s.deliveryRepo.Record(channel.ID, jobID, sendErr); err != nil {
+			log.Printf("Failed to record digest %s: %v", jobID, err)
+		}
+		if sendErr != nil {
+			log.Printf("Failed to send digest to email channel %s: %v", channel.ID, sendErr)
+			continue
+		}
+		sent = append(sent, channel.ID)
+	}
+	return sent
+}
+
+func (s *EmailDigestScheduler) send(channel *models.Channel, cfg *models.EmailDigestConfig, from, to time.Time) error {
+	projectName := channel.ProjectID
+	if project, err := s.projectRepo.GetByID(channel.ProjectID); err == nil && project != nil {
+		projectName = project.Name
+	}
+
+	digest, err := BuildEmailDigest(s.logRepo, channel.ProjectID, projectName, cfg.Schedule, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to build digest: %w", err)
+	}
+
+	return s.mailer.Send(&mail.Message{
+		To:      cfg.Recipients,
+		Subject: digest.Subject(),
+		Body:    digest.Body(),
+	})
+}
@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+	"central-logs/internal/services/mail"
+)
+
+type fakeMailer struct {
+	sent []*mail.Message
+	err  error
+}
+
+func (m *fakeMailer) Send(msg *mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// setupEmailDigestTestDB adds the tables log queries and the scheduler need
+// to the logs schema
+func setupEmailDigestTestDB(t *testing.T) *sql.DB {
+	db := setupLogBufferTestDB(t)
+	if _, err := db.Exec(consumerTestSchema + `
+		CREATE TABLE projects (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			deleted_at DATETIME
+		);
+	`); err != nil {
+		t.Fatalf("Failed to create tables: %v", err)
+	}
+	return db
+}
+
+func seedDigestLogs(t *testing.T, logRepo *models.LogRepository, projectID string, at time.Time) {
+	seed := []struct {
+		level   models.LogLevel
+		source  string
+		message string
+		count   int
+	}{
+		{models.LogLevelInfo, "api", "request served", 6},
+		{models.LogLevelWarn, "api", "slow query", 2},
+		{models.LogLevelError, "billing", "payment gateway timeout", 3},
+		{models.LogLevelError, "api", "upstream returned 502", 1},
+		{models.LogLevelCritical, "billing", "payment gateway timeout", 1},
+	}
+	for _, s := range seed {
+		for i := 0; i < s.count; i++ {
+			err := logRepo.Create(&models.Log{ProjectID: projectID, Level: s.level, Source: s.source, Message: s.message, Timestamp: at})
+			if err != nil {
+				t.Fatalf("Failed to seed log: %v", err)
+			}
+		}
+	}
+}
+
+func TestEmailDigest_RendersSeededLogs(t *testing.T) {
+	db := setupEmailDigestTestDB(t)
+	defer db.Close()
+
+	logRepo := models.NewLogRepository(db)
+	to := time.Date(2025, 2, 3, 8, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -1)
+
+	seedDigestLogs(t, logRepo, "proj-1", to.Add(-2*time.Hour))
+	// Outside the period or in another project, so not counted
+	logRepo.Create(&models.Log{ProjectID: "proj-1", Level: models.LogLevelError, Message: "old", Timestamp: from.Add(-time.Hour)})
+	logRepo.Create(&models.Log{ProjectID: "proj-2", Level: models.LogLevelError, Message: "elsewhere", Timestamp: to.Add(-time.Hour)})
+
+	digest, err := BuildEmailDigest(logRepo, "proj-1", "Shop", "daily", from, to)
+	if err != nil {
+		t.Fatalf("Failed to build digest: %v", err)
+	}
+
+	if digest.Total != 13 {
+		t.Errorf("Expected 13 logs in the period, got %d", digest.Total)
+	}
+	if got := digest.Subject(); got != "[Central Logs] Daily digest for Shop: 13 logs, 5 errors" {
+		t.Errorf("Unexpected subject %q", got)
+	}
+
+	body := digest.Body()
+	for _, want := range []string{
+		"Log activity for project Shop\n2025-02-02 08:00 UTC to 2025-02-03 08:00 UTC",
+		"Total logs: 13",
+		"  CRITICAL  1\n  ERROR     4\n  WARN      2\n  INFO      6\n  DEBUG     0\n",
+		"  api                      9\n  billing                  4\n",
+		"      4x  payment gateway timeout\n      1x  upstream returned 502\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected digest body to contain %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "old") || strings.Contains(body, "elsewhere") {
+		t.Errorf("Expected logs outside the period or project to be left out, got:\n%s", body)
+	}
+
+	empty, _ := BuildEmailDigest(logRepo, "proj-3", "Quiet", "weekly", from, to)
+	if !strings.Contains(empty.Body(), "No logs were received in this period.") {
+		t.Errorf("Expected an empty digest to say so, got:\n%s", empty.Body())
+	}
+}
+
+func TestEmailDigestScheduler_SendsOncePerPeriod(t *testing.T) {
+	db := setupEmailDigestTestDB(t)
+	defer db.Close()
+
+	channelRepo := models.NewChannelRepository(db)
+	logRepo := models.NewLogRepository(db)
+	mailer := &fakeMailer{}
+	scheduler := NewEmailDigestScheduler(channelRepo, logRepo, models.NewProjectRepository(db), models.NewChannelDeliveryRepository(db), mailer)
+
+	channel := &models.Channel{ProjectID: "proj-1", Type: models.ChannelTypeEmail, Name: "Managers", MinLevel: models.LogLevelError, IsActive: true,
+		Config: map[string]interface{}{"recipients": []interface{}{"lead@example.com"}, "schedule": "daily", "at": "08:00"}}
+	if err := channelRepo.Create(channel); err != nil {
+		t.Fatalf("Failed to create channel: %v", err)
+	}
+	// Backdate the channel so the period ending this morning counts
+	db.Exec(`UPDATE channels SET created_at = ? WHERE id = ?`, time.Now().AddDate(0, 0, -3), channel.ID)
+
+	now := time.Now().UTC()
+	if sent := scheduler.Run(now); len(sent) != 1 || sent[0] != channel.ID {
+		t.Fatalf("Expected the digest to be sent to %s, got %v", channel.ID, sent)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].To[0] != "lead@example.com" {
+		t.Fatalf("Expected one email to the recipient, got %+v", mailer.sent)
+	}
+	if sent := scheduler.Run(now.Add(time.Minute)); len(sent) != 0 {
+		t.Errorf("Expected the same period not to be sent twice, got %v", sent)
+	}
+
+	// A failed send is retried, but not on every tick
+	mailer.err = fmt.Errorf("connection refused")
+	tomorrow := now.AddDate(0, 0, 1)
+	scheduler.Run(tomorrow)
+	mailer.err = nil
+	if sent := scheduler.Run(tomorrow.Add(time.Minute)); len(sent) != 0 {
+		t.Errorf("Expected no retry right after a failure, got %v", sent)
+	}
+	db.Exec(`UPDATE channel_deliveries SET created_at = ? WHERE success = 0`, time.Now().Add(-emailDigestRetryDelay-time.Minute))
+	if sent := scheduler.Run(tomorrow.Add(2 * time.Minute)); len(sent) != 1 {
+		t.Errorf("Expected a retry once the delay passed, got %v", sent)
+	}
+}
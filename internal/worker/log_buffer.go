@@ -0,0 +1,204 @@
+package worker
+
+import (
+	"central-logs/internal/models"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrBufferFull is returned by Add when maxPending logs are already waiting
+// to be written, usually because the database has been failing for a while
+var ErrBufferFull = errors.New("ingestion buffer is full")
+
+// BufferedLog is a log waiting to be written along with the project it was ingested for
+type BufferedLog struct {
+	Log     *models.Log
+	Project *models.Project
+}
+
+// LogBuffer collects ingested logs in memory and writes them with CreateBatch
+// once flushSize logs are pending or flushInterval has passed. Logs get their
+// ID when they are added so callers can respond before the write happens.
+type LogBuffer struct {
+	logRepo       *models.LogRepository
+	flushSize     int
+	flushInterval time.Duration
+	maxPending    int
+	onFlush       func([]BufferedLog)
+
+	mu       sync.Mutex
+	pending  []BufferedLog
+	inFlight int // Logs taken by the running flush; counted against maxPending since a failed write puts them back
+
+	flushMu  sync.Mutex // Serializes writes so logs land in arrival order
+	flushNow chan struct{}
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// NewLogBuffer creates a new ingestion buffer holding at most maxPending logs
+func NewLogBuffer(logRepo *models.LogRepository, flushSize int, flushInterval time.Duration, maxPending int) *LogBuffer {
+	return &LogBuffer{
+		logRepo:       logRepo,
+		flushSize:     flushSize,
+		flushInterval: flushInterval,
+		maxPending:    maxPending,
+		flushNow:      make(chan struct{}, 1),
+		stopChan:      make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// OnFlush registers a callback run with every batch after it is written
+func (b *LogBuffer) OnFlush(fn func([]BufferedLog)) {
+	b.onFlush = fn
+}
+
+// Add queues a log and returns its ID
+func (b *LogBuffer) Add(entry *models.Log, project *models.Project) (string, error) {
+	ids, err := b.AddAll([]*models.Log{entry}, project)
+	if err != nil {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// AddAll queues a batch of logs and returns their IDs. The batch is queued
+// whole or, when it would take the buffer past maxPending, not at all.
+func (b *LogBuffer) AddAll(entries []*models.Log, project *models.Project) ([]string, error) {
+	now := time.Now().UTC()
+
+	b.mu.Lock()
+	if len(b.pending)+b.inFlight+len(entries) > b.maxPending {
+		b.mu.Unlock()
+		return nil, ErrBufferFull
+	}
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		entry.ID = uuid.New().String()
+		entry.CreatedAt = now
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = entry.CreatedAt
+		}
+		entry.Timestamp = entry.Timestamp.UTC()
+		ids[i] = entry.ID
+		b.pending = append(b.pending, BufferedLog{Log: entry, Project: project})
+	}
+	full := len(b.pending) >= b.flushSize
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.flushNow <- struct{}{}:
+		default:
+		}
+	}
+
+	return ids, nil
+}
+
+// Pending returns the number of logs not yet written
+func (b *LogBuffer) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Start runs the flush loop in the background
+func (b *LogBuffer) Start() {
+	log.Printf("Starting async ingestion buffer (flush size: %d, interval: %s)", b.flushSize, b.flushInterval)
+
+	go func() {
+		defer close(b.done)
+
+		ticker := time.NewTicker(b.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.stopChan:
+				return
+			case <-ticker.C:
+				b.Flush()
+			case <-b.flushNow:
+				b.Flush()
+			}
+		}
+	}()
+}
+
+// Stop ends the flush loop and writes everything still pending
+func (b *LogBuffer) Stop() {
+	close(b.stopChan)
+	<-b.done
+
+	if err := b.Flush(); err != nil {
+		log.Printf("Async ingestion buffer: %d logs could not be written on shutdown: %v", b.Pending(), err)
+		return
+	}
+	log.Println("Async ingestion buffer stopped")
+}
+
+// Flush writes all pending logs. When the write fails as a whole the logs are
+// put back at the front of the queue so the next flush retries them. A log the
+// database rejects on its own, such as one whose project has been deleted,
+// would fail every retry, so it is dropped instead of holding up the rest.
+func (b *LogBuffer) Flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	b.inFlight = len(batch)
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	logs := make([]*models.Log, len(batch))
+	for i, entry := range batch {
+		logs[i] = entry.Log
+	}
+
+	insertErrors, err := b.logRepo.CreateBatchBestEffort(logs)
+	if err != nil {
+		log.Printf("Async ingestion buffer: failed to write %d logs: %v", len(batch), err)
+		b.mu.Lock()
+		b.pending = append(batch, b.pending...)
+		b.inFlight = 0
+		b.mu.Unlock()
+		return err
+	}
+
+	b.mu.Lock()
+	b.inFlight = 0
+	b.mu.Unlock()
+
+	if len(insertErrors) > 0 {
+		skipped := make(map[int]bool, len(insertErrors))
+		for _, insertErr := range insertErrors {
+			skipped[insertErr.Index] = true
+			log.Printf("Async ingestion buffer: dropping log %s for project %s: %v",
+				batch[insertErr.Index].Log.ID, batch[insertErr.Index].Log.ProjectID, insertErr.Err)
+		}
+		stored := make([]BufferedLog, 0, len(batch)-len(insertErrors))
+		for i, entry := range batch {
+			if !skipped[i] {
+				stored = append(stored, entry)
+			}
+		}
+		batch = stored
+	}
+
+	if b.onFlush != nil && len(batch) > 0 {
+		b.onFlush(batch)
+	}
+
+	return nil
+}
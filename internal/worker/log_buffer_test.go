@@ -0,0 +1,272 @@
+package worker
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const logBufferTestSchema = `
+	CREATE TABLE logs (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL,
+		level TEXT NOT NULL,
+		message TEXT NOT NULL,
+		metadata TEXT,
+		source TEXT,
+		timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		status TEXT NOT NULL DEFAULT 'new',
+		acknowledged_by TEXT,
+		acknowledged_at DATETIME
+	);
+`
+
+func setupLogBufferTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(logBufferTestSchema); err != nil {
+		t.Fatalf("Failed to create logs table: %v", err)
+	}
+	return db
+}
+
+func countLogs(t *testing.T, db *sql.DB) int {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM logs").Scan(&count); err != nil {
+		t.Fatalf("Failed to count logs: %v", err)
+	}
+	return count
+}
+
+func TestLogBuffer_StopFlushesPendingLogs(t *testing.T) {
+	db := setupLogBufferTestDB(t)
+	defer db.Close()
+
+	// A long interval means only the size threshold and Stop can trigger writes
+	buffer := NewLogBuffer(models.NewLogRepository(db), 100, time.Hour, 10000)
+
+	var flushedMu sync.Mutex
+	flushed := 0
+	buffer.OnFlush(func(batch []BufferedLog) {
+		flushedMu.Lock()
+		flushed += len(batch)
+		flushedMu.Unlock()
+	})
+	buffer.Start()
+
+	project := &models.Project{ID: "proj-1", Name: "Test Project"}
+
+	const writers, perWriter = 5, 53
+	ids := make(chan string, writers*perWriter)
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				id, err := buffer.Add(&models.Log{
+					ProjectID: project.ID,
+					Level:     models.LogLevelInfo,
+					Message:   fmt.Sprintf("writer %d log %d", w, i),
+				}, project)
+				if err != nil {
+					t.Errorf("Failed to add log: %v", err)
+				}
+				ids <- id
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(ids)
+
+	// Simulated shutdown: whatever has not hit the size threshold is still in memory
+	buffer.Stop()
+
+	total := writers * perWriter
+	if got := countLogs(t, db); got != total {
+		t.Fatalf("Expected %d logs after shutdown, got %d", total, got)
+	}
+	if buffer.Pending() != 0 {
+		t.Errorf("Expected empty buffer after shutdown, got %d pending", buffer.Pending())
+	}
+	if flushed != total {
+		t.Errorf("Expected OnFlush to see %d logs, got %d", total, flushed)
+	}
+
+	// The IDs handed back at Add time must be the stored IDs
+	for id := range ids {
+		var exists int
+		if err := db.QueryRow("SELECT COUNT(*) FROM logs WHERE id = ?", id).Scan(&exists); err != nil {
+			t.Fatalf("Failed to look up log %s: %v", id, err)
+		}
+		if exists != 1 {
+			t.Errorf("Log %s was acknowledged but not stored", id)
+		}
+	}
+}
+
+func TestLogBuffer_FailedFlushKeepsLogs(t *testing.T) {
+	db := setupLogBufferTestDB(t)
+	defer db.Close()
+
+	buffer := NewLogBuffer(models.NewLogRepository(db), 100, time.Hour, 10000)
+	project := &models.Project{ID: "proj-1", Name: "Test Project"}
+
+	for i := 0; i < 3; i++ {
+		buffer.Add(&models.Log{ProjectID: project.ID, Level: models.LogLevelError, Message: "boom"}, project)
+	}
+
+	if _, err := db.Exec("DROP TABLE logs"); err != nil {
+		t.Fatalf("Failed to drop logs table: %v", err)
+	}
+	if err := buffer.Flush(); err == nil {
+		t.Fatal("Expected flush to fail without a logs table")
+	}
+	if buffer.Pending() != 3 {
+		t.Fatalf("Expected failed logs to be requeued, got %d pending", buffer.Pending())
+	}
+
+	if _, err := db.Exec(logBufferTestSchema); err != nil {
+		t.Fatalf("Failed to recreate logs table: %v", err)
+	}
+	if err := buffer.Flush(); err != nil {
+		t.Fatalf("Retry flush failed: %v", err)
+	}
+	if got := countLogs(t, db); got != 3 {
+		t.Errorf("Expected 3 logs after retry, got %d", got)
+	}
+}
+
+func TestLogBuffer_StoresTimestampsInUTC(t *testing.T) {
+	db := setupLogBufferTestDB(t)
+	defer db.Close()
+
+	buffer := NewLogBuffer(models.NewLogRepository(db), 100, time.Hour, 10000)
+	project := &models.Project{ID: "proj-1", Name: "Test Project"}
+
+	sent := time.Date(2025, 2, 1, 10, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	buffer.Add(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "offset", Timestamp: sent}, project)
+	if err := buffer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRow("SELECT CAST(timestamp AS TEXT) FROM logs").Scan(&stored); err != nil {
+		t.Fatalf("Failed to read timestamp: %v", err)
+	}
+	if stored != "2025-02-01 03:00:00+00:00" {
+		t.Errorf("Expected the timestamp stored in UTC, got %q", stored)
+	}
+}
+
+func TestLogBuffer_DropsLogsTheDatabaseRejects(t *testing.T) {
+	// Foreign keys on, as in production, so a log for a missing project fails
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "logs.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE projects (id TEXT PRIMARY KEY)`); err != nil {
+		t.Fatalf("Failed to create projects table: %v", err)
+	}
+	schema := strings.Replace(logBufferTestSchema, "project_id TEXT NOT NULL,",
+		"project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,", 1)
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to create logs table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO projects (id) VALUES ('proj-1')`); err != nil {
+		t.Fatalf("Failed to insert project: %v", err)
+	}
+
+	buffer := NewLogBuffer(models.NewLogRepository(db), 100, time.Hour, 10000)
+	var flushed []BufferedLog
+	buffer.OnFlush(func(batch []BufferedLog) { flushed = append(flushed, batch...) })
+
+	project := &models.Project{ID: "proj-1", Name: "Test Project"}
+	deleted := &models.Project{ID: "proj-deleted", Name: "Deleted Project"}
+	buffer.Add(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "before"}, project)
+	orphanID, _ := buffer.Add(&models.Log{ProjectID: deleted.ID, Level: models.LogLevelInfo, Message: "orphan"}, deleted)
+	buffer.Add(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "after"}, project)
+
+	if err := buffer.Flush(); err != nil {
+		t.Fatalf("Expected the flush to succeed without the rejected log, got %v", err)
+	}
+	if buffer.Pending() != 0 {
+		t.Errorf("Expected the rejected log to be dropped, got %d pending", buffer.Pending())
+	}
+	if got := countLogs(t, db); got != 2 {
+		t.Errorf("Expected the 2 valid logs to be stored, got %d", got)
+	}
+	if len(flushed) != 2 {
+		t.Fatalf("Expected OnFlush to see only the stored logs, got %d", len(flushed))
+	}
+	for _, entry := range flushed {
+		if entry.Log.ID == orphanID {
+			t.Error("Expected OnFlush not to see the rejected log")
+		}
+	}
+}
+
+func TestLogBuffer_RefusesLogsWhenFull(t *testing.T) {
+	db := setupLogBufferTestDB(t)
+	defer db.Close()
+
+	buffer := NewLogBuffer(models.NewLogRepository(db), 100, time.Hour, 3)
+	project := &models.Project{ID: "proj-1", Name: "Test Project"}
+
+	if _, err := buffer.AddAll([]*models.Log{
+		{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "one"},
+		{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "two"},
+	}, project); err != nil {
+		t.Fatalf("Failed to add logs: %v", err)
+	}
+
+	// A batch that does not fit is refused whole
+	_, err := buffer.AddAll([]*models.Log{
+		{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "three"},
+		{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "four"},
+	}, project)
+	if !errors.Is(err, ErrBufferFull) {
+		t.Fatalf("Expected ErrBufferFull, got %v", err)
+	}
+	if buffer.Pending() != 2 {
+		t.Fatalf("Expected the refused batch not to be queued, got %d pending", buffer.Pending())
+	}
+
+	// Logs put back by a failed flush still count against the limit
+	if _, err := db.Exec("DROP TABLE logs"); err != nil {
+		t.Fatalf("Failed to drop logs table: %v", err)
+	}
+	buffer.Flush()
+	if _, err := buffer.Add(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "three"}, project); err != nil {
+		t.Fatalf("Expected room for one more log, got %v", err)
+	}
+	if _, err := buffer.Add(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "four"}, project); !errors.Is(err, ErrBufferFull) {
+		t.Fatalf("Expected ErrBufferFull, got %v", err)
+	}
+
+	if _, err := db.Exec(logBufferTestSchema); err != nil {
+		t.Fatalf("Failed to recreate logs table: %v", err)
+	}
+	if err := buffer.Flush(); err != nil {
+		t.Fatalf("Retry flush failed: %v", err)
+	}
+	if _, err := buffer.Add(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "four"}, project); err != nil {
+		t.Errorf("Expected room once the backlog was written, got %v", err)
+	}
+}
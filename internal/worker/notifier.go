@@ -4,29 +4,115 @@ import (
 	"bytes"
 	"central-logs/internal/config"
 	"central-logs/internal/models"
+	"central-logs/internal/services/mail"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
 // Notifier handles sending notifications to various channels
 type Notifier struct {
-	channelRepo *models.ChannelRepository
-	client      *http.Client
-	config      *config.Config
+	channelRepo  *models.ChannelRepository
+	deliveryRepo *models.ChannelDeliveryRepository
+	projectRepo  *models.ProjectRepository
+	mailer       mail.Mailer
+	client       *http.Client
+	config       *config.Holder // Read through Get each time so reloaded rate limits apply
+
+	// Parent of every outbound request; cancelled by Stop
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
+var (
+	// ErrDeliveryTimeout marks a send whose request took longer than
+	// notifications.http_timeout. It counts as a failed attempt and is retried.
+	ErrDeliveryTimeout = errors.New("notification request timed out")
+	// ErrNotifierStopped marks a send cut off because the server is shutting down
+	ErrNotifierStopped = errors.New("notifier stopped")
+)
+
 // NewNotifier creates a new notification worker
-func NewNotifier(channelRepo *models.ChannelRepository, cfg *config.Config) *Notifier {
+func NewNotifier(channelRepo *models.ChannelRepository, cfg *config.Holder) *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Notifier{
 		channelRepo: channelRepo,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		config: cfg,
+		client:      &http.Client{},
+		config:      cfg,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Stop cancels in-flight requests; sends made afterwards fail with
+// ErrNotifierStopped
+func (n *Notifier) Stop() {
+	n.cancel()
+}
+
+// postJSON POSTs body to url under the configured request timeout and
+// returns the response status and up to 64KB of its body
+func (n *Notifier) postJSON(url string, body []byte) (int, []byte, error) {
+	timeout := n.config.Get().GetNotificationHTTPTimeout()
+	ctx, cancel := context.WithTimeout(n.ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err == nil {
+		defer resp.Body.Close()
+		var respBody []byte
+		respBody, err = io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if err == nil {
+			return resp.StatusCode, respBody, nil
+		}
+	}
+
+	switch {
+	case n.ctx.Err() != nil:
+		return 0, nil, fmt.Errorf("%w: %v", ErrNotifierStopped, err)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return 0, nil, fmt.Errorf("%w after %s: %v", ErrDeliveryTimeout, timeout, err)
+	}
+	return 0, nil, err
+}
+
+// SetDeliveryRecorder records the outcome of every Send for channel health
+func (n *Notifier) SetDeliveryRecorder(deliveryRepo *models.ChannelDeliveryRepository) {
+	n.deliveryRepo = deliveryRepo
+}
+
+// SetProjectRepository lets messages name the project instead of its ID
+func (n *Notifier) SetProjectRepository(projectRepo *models.ProjectRepository) {
+	n.projectRepo = projectRepo
+}
+
+// SetMailer enables EMAIL channels
+func (n *Notifier) SetMailer(mailer mail.Mailer) {
+	n.mailer = mailer
+}
+
+// projectName returns the name of the log's project, falling back to its ID
+func (n *Notifier) projectName(projectID string) string {
+	if n.projectRepo == nil || projectID == "" {
+		return projectID
 	}
+	project, err := n.projectRepo.GetByID(projectID)
+	if err != nil || project == nil {
+		return projectID
+	}
+	return project.Name
 }
 
 // ProcessLog processes a log entry and sends notifications if needed
@@ -45,39 +131,82 @@ func (n *Notifier) ProcessLog(logEntry *models.Log) {
 		}
 
 		// Check if log level meets minimum level
-		if !meetsMinLevel(logEntry.Level, channel.MinLevel) {
+		if !channel.ShouldNotifyAt(logEntry.Level, logEntry.Source, time.Now()) {
 			continue
 		}
 
 		// Send notification based on channel type
-		switch channel.Type {
-		case models.ChannelTypeTelegram:
-			go n.sendTelegram(channel, logEntry)
-		case models.ChannelTypeDiscord:
-			go n.sendDiscord(channel, logEntry)
-		case models.ChannelTypePush:
-			go n.sendPush(channel, logEntry)
+		go func(channel *models.Channel) {
+			if err := n.Send(channel, logEntry); err != nil {
+				log.Printf("Failed to notify channel %s: %v", channel.ID, err)
+			}
+		}(channel)
+	}
+}
+
+// Send delivers a log entry to a single channel based on its type
+func (n *Notifier) Send(channel *models.Channel, logEntry *models.Log) error {
+	return n.SendJob(channel, logEntry, "")
+}
+
+// SendJob is Send for a queued job, recording the outcome under its ID
+func (n *Notifier) SendJob(channel *models.Channel, logEntry *models.Log, jobID string) error {
+	_, err := n.send(channel, logEntry, jobID)
+	return err
+}
+
+// SendStatus is Send that also returns the HTTP status the remote service
+// answered with, or 0 when no request was made or it failed before a reply
+func (n *Notifier) SendStatus(channel *models.Channel, logEntry *models.Log) (int, error) {
+	return n.send(channel, logEntry, "")
+}
+
+func (n *Notifier) send(channel *models.Channel, logEntry *models.Log, jobID string) (int, error) {
+	status, err := n.deliver(channel, logEntry)
+	if n.deliveryRepo != nil {
+		if recErr := n.deliveryRepo.Record(channel.ID, jobID, n.RedactError(channel, err)); recErr != nil {
+			log.Printf("Failed to record delivery for channel %s: %v", channel.ID, recErr)
 		}
 	}
+	return status, err
+}
+
+func (n *Notifier) deliver(channel *models.Channel, logEntry *models.Log) (int, error) {
+	switch channel.Type {
+	case models.ChannelTypeTelegram:
+		return n.sendTelegram(channel, logEntry)
+	case models.ChannelTypeDiscord:
+		return n.sendDiscord(channel, logEntry)
+	case models.ChannelTypePush:
+		return n.sendPush(channel, logEntry)
+	case models.ChannelTypeApprise:
+		return n.sendApprise(channel, logEntry)
+	case models.ChannelTypeTeams:
+		return n.sendTeams(channel, logEntry)
+	case models.ChannelTypeEmail:
+		return n.sendEmail(channel, logEntry)
+	case models.ChannelTypePagerDuty:
+		return n.sendPagerDuty(channel, logEntry)
+	default:
+		return 0, fmt.Errorf("unknown channel type: %s", channel.Type)
+	}
 }
 
 // sendTelegram sends a notification to Telegram
-func (n *Notifier) sendTelegram(channel *models.Channel, logEntry *models.Log) {
+func (n *Notifier) sendTelegram(channel *models.Channel, logEntry *models.Log) (int, error) {
 	// Get bot token - use channel's token or fallback to global config
 	botToken, ok := channel.Config["bot_token"].(string)
 	if !ok || botToken == "" {
 		// Use global bot token from config
-		botToken = n.config.Telegram.BotToken
+		botToken = n.config.Get().Telegram.BotToken
 		if botToken == "" {
-			log.Printf("No bot_token configured for channel %s and no global bot_token in config", channel.ID)
-			return
+			return 0, fmt.Errorf("no bot_token configured for channel %s and no global bot_token in config", channel.ID)
 		}
 	}
 
 	chatID, ok := channel.Config["chat_id"].(string)
 	if !ok || chatID == "" {
-		log.Printf("Invalid chat_id for channel %s", channel.ID)
-		return
+		return 0, fmt.Errorf("invalid chat_id for channel %s", channel.ID)
 	}
 
 	// Format message with emoji based on level
@@ -106,58 +235,340 @@ func (n *Notifier) sendTelegram(channel *models.Channel, logEntry *models.Log) {
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Failed to marshal Telegram payload: %v", err)
-		return
+		return 0, fmt.Errorf("failed to marshal Telegram payload: %w", err)
 	}
 
-	resp, err := n.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	status, _, err := n.postJSON(url, jsonData)
 	if err != nil {
-		log.Printf("Failed to send Telegram notification: %v", err)
-		return
+		return 0, fmt.Errorf("failed to send Telegram notification: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Telegram API returned status %d for channel %s", resp.StatusCode, channel.ID)
-		return
+	if status != http.StatusOK {
+		return status, fmt.Errorf("Telegram API returned status %d for channel %s", status, channel.ID)
 	}
 
 	log.Printf("Sent Telegram notification for log %s to channel %s", logEntry.ID, channel.Name)
+	return status, nil
 }
 
 // sendDiscord sends a notification to Discord (placeholder)
-func (n *Notifier) sendDiscord(channel *models.Channel, logEntry *models.Log) {
+func (n *Notifier) sendDiscord(channel *models.Channel, logEntry *models.Log) (int, error) {
 	// TODO: Implement Discord webhook notification
 	log.Printf("Discord notifications not yet implemented")
+	return 0, nil
+}
+
+// sendApprise posts the notification to an Apprise API server's stateless
+// /notify endpoint, which forwards it to each of the channel's target URLs
+func (n *Notifier) sendApprise(channel *models.Channel, logEntry *models.Log) (int, error) {
+	cfg := channel.GetAppriseConfig()
+	if cfg == nil || len(cfg.URLs) == 0 {
+		return 0, fmt.Errorf("no target urls configured for channel %s", channel.ID)
+	}
+
+	serverURL := cfg.ServerURL
+	if serverURL == "" {
+		serverURL = n.config.Get().Apprise.ServerURL
+		if serverURL == "" {
+			return 0, fmt.Errorf("no server_url configured for channel %s and no global apprise server_url in config", channel.ID)
+		}
+	}
+
+	body := fmt.Sprintf("**Message:** %s\n**Source:** %s\n**Time:** %s",
+		logEntry.Message,
+		logEntry.Source,
+		logEntry.Timestamp.Format("2006-01-02 15:04:05"),
+	)
+	if len(logEntry.Metadata) > 0 {
+		metadataStr, _ := json.MarshalIndent(logEntry.Metadata, "", "  ")
+		body += fmt.Sprintf("\n\n**Metadata:**\n```\n%s\n```", string(metadataStr))
+	}
+
+	payload := map[string]interface{}{
+		"urls":   strings.Join(cfg.URLs, ","),
+		"title":  fmt.Sprintf("%s %s", getLogEmoji(logEntry.Level), logEntry.Level),
+		"body":   body,
+		"type":   appriseType(logEntry.Level),
+		"format": "markdown",
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal Apprise payload: %w", err)
+	}
+
+	endpoint := strings.TrimRight(serverURL, "/") + "/notify/"
+	status, _, err := n.postJSON(endpoint, jsonData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send Apprise notification: %w", err)
+	}
+
+	// Apprise answers 424 when some or all of the targets failed
+	if status < 200 || status >= 300 {
+		return status, fmt.Errorf("Apprise API returned status %d for channel %s", status, channel.ID)
+	}
+
+	log.Printf("Sent Apprise notification for log %s to channel %s", logEntry.ID, channel.Name)
+	return status, nil
+}
+
+// appriseType maps a log level to Apprise's notification type
+func appriseType(level models.LogLevel) string {
+	switch level {
+	case models.LogLevelWarn:
+		return "warning"
+	case models.LogLevelError, models.LogLevelCritical:
+		return "failure"
+	default:
+		return "info"
+	}
+}
+
+// sendTeams posts an Adaptive Card to a Microsoft Teams incoming webhook
+func (n *Notifier) sendTeams(channel *models.Channel, logEntry *models.Log) (int, error) {
+	webhookURL, ok := channel.Config["webhook_url"].(string)
+	if !ok || webhookURL == "" {
+		return 0, fmt.Errorf("invalid webhook_url for channel %s", channel.ID)
+	}
+
+	jsonData, err := json.Marshal(teamsMessage(logEntry, n.projectName(logEntry.ProjectID)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal Teams payload: %w", err)
+	}
+
+	status, _, err := n.postJSON(webhookURL, jsonData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send Teams notification: %w", err)
+	}
+
+	// Classic connectors answer 200, Workflows webhooks 202
+	if status < 200 || status >= 300 {
+		return status, fmt.Errorf("Teams webhook returned status %d for channel %s", status, channel.ID)
+	}
+
+	log.Printf("Sent Teams notification for log %s to channel %s", logEntry.ID, channel.Name)
+	return status, nil
+}
+
+// teamsMessage wraps an Adaptive Card describing the log in the message
+// envelope Teams webhooks expect
+func teamsMessage(logEntry *models.Log, projectName string) map[string]interface{} {
+	body := []interface{}{
+		map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   fmt.Sprintf("%s %s", getLogEmoji(logEntry.Level), logEntry.Level),
+			"size":   "Large",
+			"weight": "Bolder",
+			"color":  teamsColor(logEntry.Level),
+		},
+		map[string]interface{}{
+			"type": "TextBlock",
+			"text": logEntry.Message,
+			"wrap": true,
+		},
+		map[string]interface{}{
+			"type": "FactSet",
+			"facts": []interface{}{
+				map[string]interface{}{"title": "Project", "value": projectName},
+				map[string]interface{}{"title": "Source", "value": logEntry.Source},
+				map[string]interface{}{"title": "Time", "value": logEntry.Timestamp.Format("2006-01-02 15:04:05")},
+			},
+		},
+	}
+
+	if len(logEntry.Metadata) > 0 {
+		metadataStr, _ := json.MarshalIndent(logEntry.Metadata, "", "  ")
+		body = append(body, map[string]interface{}{
+			"type":     "TextBlock",
+			"text":     string(metadataStr),
+			"fontType": "Monospace",
+			"wrap":     true,
+			"isSubtle": true,
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
+
+// teamsColor maps a log level to an Adaptive Card text color
+func teamsColor(level models.LogLevel) string {
+	switch level {
+	case models.LogLevelInfo:
+		return "Accent"
+	case models.LogLevelWarn:
+		return "Warning"
+	case models.LogLevelError, models.LogLevelCritical:
+		return "Attention"
+	default:
+		return "Default"
+	}
+}
+
+// sendPagerDuty triggers a PagerDuty incident through the Events API v2. The
+// dedup key is the log fingerprint, so repeats of the same error are grouped
+// into the open incident instead of paging again.
+func (n *Notifier) sendPagerDuty(channel *models.Channel, logEntry *models.Log) (int, error) {
+	routingKey, ok := channel.Config["routing_key"].(string)
+	if !ok || routingKey == "" {
+		return 0, fmt.Errorf("invalid routing_key for channel %s", channel.ID)
+	}
+
+	jsonData, err := json.Marshal(pagerDutyEvent(routingKey, logEntry, n.projectName(logEntry.ProjectID)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+
+	status, respBody, err := n.postJSON(n.config.Get().GetPagerDutyEventsURL(), jsonData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send PagerDuty event: %w", err)
+	}
+
+	if status != http.StatusAccepted {
+		// Rejected events come back as {"status", "message", "errors": [...]}
+		var result struct {
+			Message string   `json:"message"`
+			Errors  []string `json:"errors"`
+		}
+		json.Unmarshal(respBody, &result)
+		err := fmt.Errorf("PagerDuty returned status %d for channel %s", status, channel.ID)
+		if result.Message != "" {
+			err = fmt.Errorf("%w: %s", err, result.Message)
+		}
+		if len(result.Errors) > 0 {
+			err = fmt.Errorf("%w (%s)", err, strings.Join(result.Errors, "; "))
+		}
+		return status, err
+	}
+
+	log.Printf("Sent PagerDuty event for log %s to channel %s", logEntry.ID, channel.Name)
+	return status, nil
+}
+
+// pagerDutyEvent builds the Events API v2 trigger event for a log
+func pagerDutyEvent(routingKey string, logEntry *models.Log, projectName string) map[string]interface{} {
+	summary := fmt.Sprintf("[%s] %s: %s", logEntry.Level, projectName, truncateLine(logEntry.Message, 900))
+
+	source := logEntry.Source
+	if source == "" {
+		source = projectName
+	}
+
+	details := map[string]interface{}{"log_id": logEntry.ID}
+	for k, v := range logEntry.Metadata {
+		details[k] = v
+	}
+	if strings.Contains(logEntry.Message, "\n") {
+		details["message"] = logEntry.Message
+	}
+
+	return map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": "trigger",
+		"dedup_key":    logEntry.Fingerprint(),
+		"client":       "Central Logs",
+		"payload": map[string]interface{}{
+			"summary":        summary,
+			"source":         source,
+			"severity":       pagerDutySeverity(logEntry.Level),
+			"timestamp":      logEntry.Timestamp.UTC().Format(time.RFC3339),
+			"group":          projectName,
+			"custom_details": details,
+		},
+	}
+}
+
+// pagerDutySeverity maps a log level to a PagerDuty event severity
+func pagerDutySeverity(level models.LogLevel) string {
+	switch level {
+	case models.LogLevelCritical:
+		return "critical"
+	case models.LogLevelError:
+		return "error"
+	case models.LogLevelWarn:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// sendEmail mails a single log to the channel's digest recipients. Regular
+// logs never reach email channels; this carries alerts and channel tests.
+func (n *Notifier) sendEmail(channel *models.Channel, logEntry *models.Log) (int, error) {
+	if n.mailer == nil {
+		return 0, fmt.Errorf("email is not configured on this server (smtp.host)")
+	}
+	cfg := channel.GetEmailDigestConfig()
+	if cfg == nil || len(cfg.Recipients) == 0 {
+		return 0, fmt.Errorf("no recipients configured for channel %s", channel.ID)
+	}
+
+	projectName := n.projectName(logEntry.ProjectID)
+	body := fmt.Sprintf("Project: %s\nLevel: %s\nSource: %s\nTime: %s\n\n%s\n",
+		projectName,
+		logEntry.Level,
+		logEntry.Source,
+		logEntry.Timestamp.Format("2006-01-02 15:04:05 MST"),
+		logEntry.Message,
+	)
+	if len(logEntry.Metadata) > 0 {
+		metadataStr, _ := json.MarshalIndent(logEntry.Metadata, "", "  ")
+		body += fmt.Sprintf("\nMetadata:\n%s\n", string(metadataStr))
+	}
+
+	err := n.mailer.Send(&mail.Message{
+		To:      cfg.Recipients,
+		Subject: fmt.Sprintf("[Central Logs] %s in %s: %s", logEntry.Level, projectName, truncateLine(logEntry.Message, 80)),
+		Body:    body,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Sent email notification for log %s to channel %s", logEntry.ID, channel.Name)
+	return 0, nil
 }
 
 // sendPush sends a push notification (placeholder)
-func (n *Notifier) sendPush(channel *models.Channel, logEntry *models.Log) {
+func (n *Notifier) sendPush(channel *models.Channel, logEntry *models.Log) (int, error) {
 	// TODO: Implement Web Push notification
 	log.Printf("Push notifications not yet implemented")
+	return 0, nil
 }
 
-// Helper functions
-
-func meetsMinLevel(logLevel, minLevel models.LogLevel) bool {
-	levels := map[models.LogLevel]int{
-		models.LogLevelDebug:    0,
-		models.LogLevelInfo:     1,
-		models.LogLevelWarn:     2,
-		models.LogLevelError:    3,
-		models.LogLevelCritical: 4,
+// RedactError strips channel secrets from a delivery error before it is
+// stored. HTTP client errors include the request URL, which for Telegram
+// embeds the bot token.
+func (n *Notifier) RedactError(channel *models.Channel, err error) error {
+	if err == nil {
+		return nil
 	}
 
-	logLevelInt, ok1 := levels[logLevel]
-	minLevelInt, ok2 := levels[minLevel]
+	secrets := append([]string{n.config.Get().Telegram.BotToken}, channel.SecretValues()...)
 
-	if !ok1 || !ok2 {
-		return false
+	msg := err.Error()
+	for _, secret := range secrets {
+		if secret != "" {
+			msg = strings.ReplaceAll(msg, secret, "[REDACTED]")
+		}
 	}
-
-	return logLevelInt >= minLevelInt
+	return errors.New(msg)
 }
 
+// Helper functions
+
 func getLogEmoji(level models.LogLevel) string {
 	switch level {
 	case models.LogLevelDebug:
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"central-logs/internal/models"
+)
+
+// ProjectWebhookPayload is the body posted to a project's outbound webhook
+type ProjectWebhookPayload struct {
+	Event       string      `json:"event"` // Always "log.created"
+	ProjectID   string      `json:"project_id"`
+	ProjectName string      `json:"project_name"`
+	Log         *models.Log `json:"log"`
+}
+
+// ProjectWebhook posts accepted logs to their project's outbound webhook.
+// Attempts run on the fan-out pool; a failed one is resubmitted to it after
+// the retry policy's backoff, so a slow or dead endpoint never parks a
+// worker while it waits.
+type ProjectWebhook struct {
+	pool    *TaskPool
+	client  *http.Client
+	policy  RetryPolicy
+	timeout time.Duration
+
+	// Parent of every request; cancelled by Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewProjectWebhook creates a sender whose retries go through pool. Each
+// request may take up to timeout.
+func NewProjectWebhook(pool *TaskPool, policy RetryPolicy, timeout time.Duration) *ProjectWebhook {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ProjectWebhook{
+		pool:    pool,
+		client:  &http.Client{},
+		policy:  policy,
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Stop cancels in-flight requests and drops pending retries
+func (w *ProjectWebhook) Stop() {
+	w.cancel()
+}
+
+// Fire sends entry to project's webhook if it has one and entry's level
+// meets its threshold. It makes the first attempt on the calling goroutine,
+// which is expected to be a fan-out worker already.
+func (w *ProjectWebhook) Fire(project *models.Project, entry *models.Log) {
+	webhook := project.OutboundWebhook
+	if !webhook.Fires(entry.Level) {
+		return
+	}
+
+	body, err := json.Marshal(ProjectWebhookPayload{
+		Event:       "log.created",
+		ProjectID:   project.ID,
+		ProjectName: project.Name,
+		Log:         entry,
+	})
+	if err != nil {
+		log.Printf("Failed to encode outbound webhook for log %s: %v", entry.ID, err)
+		return
+	}
+	w.attempt(webhook.URL, body, entry.ID, 1)
+}
+
+// attempt makes delivery attempt n and schedules the next one on failure
+func (w *ProjectWebhook) attempt(url string, body []byte, logID string, n int) {
+	err := w.post(url, body)
+	if err == nil || w.ctx.Err() != nil {
+		return
+	}
+	if n >= w.policy.MaxAttempts {
+		log.Printf("Outbound webhook for log %s failed after %d attempts: %v", logID, n, err)
+		return
+	}
+
+	time.AfterFunc(w.policy.Backoff(n), func() {
+		if w.ctx.Err() != nil {
+			return
+		}
+		if !w.pool.Submit(func() { w.attempt(url, body, logID, n+1) }) {
+			log.Printf("Outbound webhook retry for log %s dropped: fan-out queue full", logID)
+		}
+	})
+}
+
+func (w *ProjectWebhook) post(url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(w.ctx, w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "central-logs-webhook")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"central-logs/internal/models"
+)
+
+func TestProjectWebhook_FiresAtOrAboveMinLevel(t *testing.T) {
+	received := make(chan ProjectWebhookPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload ProjectWebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	webhook := NewProjectWebhook(NewTaskPool(2, 10), RetryPolicy{MaxAttempts: 1}, time.Second)
+	defer webhook.Stop()
+
+	project := &models.Project{
+		ID:              "proj-1",
+		Name:            "Payments",
+		OutboundWebhook: &models.OutboundWebhook{URL: server.URL, MinLevel: models.LogLevelWarn},
+	}
+	for _, level := range []models.LogLevel{models.LogLevelDebug, models.LogLevelInfo, models.LogLevelWarn, models.LogLevelCritical} {
+		webhook.Fire(project, &models.Log{ID: string(level), ProjectID: project.ID, Level: level, Message: "event"})
+	}
+
+	// Fire posts synchronously, so everything that was sent has arrived
+	close(received)
+	var levels []models.LogLevel
+	for payload := range received {
+		if payload.Event != "log.created" || payload.ProjectName != "Payments" || payload.Log == nil {
+			t.Fatalf("Unexpected payload %+v", payload)
+		}
+		levels = append(levels, payload.Log.Level)
+	}
+	if len(levels) != 2 || levels[0] != models.LogLevelWarn || levels[1] != models.LogLevelCritical {
+		t.Errorf("Expected WARN and CRITICAL to be sent, got %v", levels)
+	}
+
+	// Without a webhook nothing is sent, and Fire must not fail
+	webhook.Fire(&models.Project{ID: "proj-2"}, &models.Log{Level: models.LogLevelCritical})
+}
+
+func TestProjectWebhook_RetriesFailedDelivery(t *testing.T) {
+	var attempts atomic.Int32
+	delivered := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		close(delivered)
+	}))
+	defer server.Close()
+
+	webhook := NewProjectWebhook(NewTaskPool(2, 10), RetryPolicy{MaxAttempts: 3, BackoffBase: 10 * time.Millisecond}, time.Second)
+	defer webhook.Stop()
+
+	project := &models.Project{ID: "proj-1", OutboundWebhook: &models.OutboundWebhook{URL: server.URL}}
+	webhook.Fire(project, &models.Log{ID: "log-1", Level: models.LogLevelDebug, Message: "event"})
+
+	select {
+	case <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected delivery on the third attempt, got %d attempts", attempts.Load())
+	}
+}
+
+func TestProjectWebhook_GivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := NewProjectWebhook(NewTaskPool(2, 10), RetryPolicy{MaxAttempts: 2, BackoffBase: 5 * time.Millisecond}, time.Second)
+	defer webhook.Stop()
+
+	project := &models.Project{ID: "proj-1", OutboundWebhook: &models.OutboundWebhook{URL: server.URL}}
+	webhook.Fire(project, &models.Log{ID: "log-1", Level: models.LogLevelError})
+
+	time.Sleep(100 * time.Millisecond)
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
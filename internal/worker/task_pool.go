@@ -0,0 +1,145 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TaskPool runs background tasks on at most a fixed number of goroutines.
+// Tasks wait in a bounded queue; when it is full Submit drops the task
+// instead of blocking, so callers on a request path never wait on it.
+// Workers are started on demand and exit once the queue is empty.
+type TaskPool struct {
+	size  int
+	tasks chan func()
+
+	mu      sync.Mutex
+	running int
+	closed  bool
+
+	pending sync.WaitGroup // Tasks queued or running
+	active  atomic.Int64
+	dropped atomic.Int64
+}
+
+// TaskPoolStats is a snapshot of a pool's load
+type TaskPoolStats struct {
+	Workers   int   `json:"workers"`    // Most goroutines the pool runs at once
+	Running   int   `json:"running"`    // Goroutines currently started
+	Queued    int   `json:"queued"`     // Tasks waiting for a worker
+	QueueSize int   `json:"queue_size"` // Tasks that can wait before new ones are dropped
+	Dropped   int64 `json:"dropped"`    // Tasks dropped because the queue was full, since start
+}
+
+// NewTaskPool creates a pool of up to workers goroutines with room for
+// queueSize waiting tasks
+func NewTaskPool(workers, queueSize int) *TaskPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	return &TaskPool{
+		size:  workers,
+		tasks: make(chan func(), queueSize),
+	}
+}
+
+// Submit queues fn and reports whether it was accepted. It never blocks:
+// when the queue is full, or the pool has been shut down, fn is dropped.
+func (p *TaskPool) Submit(fn func()) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		p.dropped.Add(1)
+		return false
+	}
+
+	p.pending.Add(1)
+	select {
+	case p.tasks <- fn:
+	default:
+		if p.running < p.size {
+			// A queue of zero still accepts work while a worker is free
+			p.running++
+			p.active.Add(1)
+			go p.work(fn)
+			return true
+		}
+		p.pending.Done()
+		p.dropped.Add(1)
+		return false
+	}
+	p.active.Add(1)
+
+	if p.running < p.size {
+		p.running++
+		go p.work(nil)
+	}
+	return true
+}
+
+func (p *TaskPool) work(first func()) {
+	if first != nil {
+		p.run(first)
+	}
+	for {
+		select {
+		case fn := <-p.tasks:
+			p.run(fn)
+		default:
+			// Submit enqueues under the lock, so an empty queue seen here
+			// cannot hide a task that no worker will pick up
+			p.mu.Lock()
+			if len(p.tasks) == 0 {
+				p.running--
+				p.mu.Unlock()
+				return
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *TaskPool) run(fn func()) {
+	defer p.pending.Done()
+	defer p.active.Add(-1)
+	fn()
+}
+
+// Stats returns the pool's current load
+func (p *TaskPool) Stats() TaskPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return TaskPoolStats{
+		Workers:   p.size,
+		Running:   p.running,
+		Queued:    len(p.tasks),
+		QueueSize: cap(p.tasks),
+		Dropped:   p.dropped.Load(),
+	}
+}
+
+// Shutdown stops accepting tasks and waits up to timeout for queued and
+// running ones to finish. It returns how many were left unfinished.
+func (p *TaskPool) Shutdown(timeout time.Duration) int {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return 0
+	case <-time.After(timeout):
+		return int(p.active.Load())
+	}
+}
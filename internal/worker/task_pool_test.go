@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskPool_BoundsGoroutinesUnderBurst(t *testing.T) {
+	const workers, queueSize, burst = 8, 500, 20000
+
+	pool := NewTaskPool(workers, queueSize)
+	release := make(chan struct{})
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	defer unblock()
+
+	var completed atomic.Int64
+	baseline := runtime.NumGoroutine()
+
+	// Submit from many goroutines at once, like concurrent ingestion
+	// requests, while every task blocks so nothing drains the queue
+	var accepted atomic.Int64
+	var submitters sync.WaitGroup
+	for s := 0; s < 50; s++ {
+		submitters.Add(1)
+		go func() {
+			defer submitters.Done()
+			for i := 0; i < burst/50; i++ {
+				if pool.Submit(func() {
+					<-release
+					completed.Add(1)
+				}) {
+					accepted.Add(1)
+				}
+			}
+		}()
+	}
+	submitters.Wait()
+
+	// Submitters may take a moment to exit after Done
+	extra := runtime.NumGoroutine() - baseline
+	for deadline := time.Now().Add(time.Second); extra > workers && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		extra = runtime.NumGoroutine() - baseline
+	}
+	if extra > workers {
+		t.Errorf("Expected at most %d pool goroutines during the burst, got %d", workers, extra)
+	}
+
+	stats := pool.Stats()
+	if stats.Running != workers {
+		t.Errorf("Expected all %d workers busy, got %d", workers, stats.Running)
+	}
+	if got := accepted.Load(); got > queueSize+workers {
+		t.Errorf("Expected at most %d accepted tasks, got %d", queueSize+workers, got)
+	}
+	if stats.Dropped != burst-accepted.Load() {
+		t.Errorf("Expected %d dropped tasks, got %d", burst-accepted.Load(), stats.Dropped)
+	}
+
+	unblock()
+	if remaining := pool.Shutdown(5 * time.Second); remaining != 0 {
+		t.Fatalf("Expected the queue to drain, %d tasks left", remaining)
+	}
+	if completed.Load() != accepted.Load() {
+		t.Errorf("Expected every accepted task to run, %d of %d did", completed.Load(), accepted.Load())
+	}
+	if pool.Submit(func() {}) {
+		t.Error("Expected a shut down pool to refuse tasks")
+	}
+}
+
+func TestTaskPool_WorkersExitWhenIdle(t *testing.T) {
+	pool := NewTaskPool(4, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		pool.Submit(wg.Done)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Running > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if running := pool.Stats().Running; running != 0 {
+		t.Errorf("Expected idle workers to exit, %d still running", running)
+	}
+
+	// A later task starts a worker again
+	done := make(chan struct{})
+	if !pool.Submit(func() { close(done) }) {
+		t.Fatal("Expected the task to be accepted")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the task to run after workers went idle")
+	}
+}
@@ -52,6 +52,9 @@ func setupTestApp(t *testing.T) *TestApp {
 			two_factor_secret TEXT DEFAULT '',
 			two_factor_enabled INTEGER DEFAULT 0,
 			backup_codes TEXT DEFAULT '',
+			deleted_at DATETIME,
+			last_login_at DATETIME,
+			token_version INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
@@ -64,8 +67,20 @@ func setupTestApp(t *testing.T) *TestApp {
 			api_key_prefix TEXT NOT NULL,
 			is_active INTEGER NOT NULL DEFAULT 1,
 			retention_config TEXT,
+			redaction_config TEXT,
+			sampling_config TEXT,
+			signing_secret TEXT,
+			max_body_bytes INTEGER,
+			rate_limit_per_minute INTEGER,
+			rate_limit_burst INTEGER,
+			ingestion_paused INTEGER NOT NULL DEFAULT 0,
+			default_level TEXT,
+			require_level INTEGER NOT NULL DEFAULT 0,
+			outbound_webhook TEXT,
+			group_name TEXT,
 			icon_type TEXT DEFAULT 'initials',
 			icon_value TEXT DEFAULT '',
+			deleted_at DATETIME,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
@@ -89,6 +104,9 @@ func setupTestApp(t *testing.T) *TestApp {
 			source TEXT,
 			timestamp DATETIME NOT NULL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			status TEXT NOT NULL DEFAULT 'new',
+			acknowledged_by TEXT,
+			acknowledged_at DATETIME,
 			FOREIGN KEY (project_id) REFERENCES projects(id)
 		);
 
@@ -105,6 +123,12 @@ func setupTestApp(t *testing.T) *TestApp {
 			FOREIGN KEY (project_id) REFERENCES projects(id)
 		);
 
+		CREATE TABLE IF NOT EXISTS system_settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
 		CREATE INDEX IF NOT EXISTS idx_logs_project_id ON logs(project_id);
 		CREATE INDEX IF NOT EXISTS idx_logs_level ON logs(level);
 		CREATE INDEX IF NOT EXISTS idx_logs_created_at ON logs(created_at);
@@ -152,6 +176,7 @@ func setupTestApp(t *testing.T) *TestApp {
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
 	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(projectRepo)
 	rbacMiddleware := middleware.NewRBACMiddleware(userProjectRepo)
+	maintenanceMode := middleware.NewMaintenanceMode(models.NewSystemSettingRepository(db))
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userRepo, jwtManager)
@@ -160,6 +185,8 @@ func setupTestApp(t *testing.T) *TestApp {
 	memberHandler := handlers.NewMemberHandler(userRepo, userProjectRepo)
 	logHandler := handlers.NewLogHandler(logRepo, channelRepo, userProjectRepo, nil, nil, nil)
 	statsHandler := handlers.NewStatsHandler(logRepo, projectRepo, userProjectRepo, userRepo)
+	systemHandler := handlers.NewSystemHandler(nil, nil)
+	systemHandler.SetMaintenanceMode(maintenanceMode)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -184,7 +211,7 @@ func setupTestApp(t *testing.T) *TestApp {
 
 	// Public log ingestion
 	v1 := api.Group("/v1")
-	logIngestion := v1.Group("/logs", apiKeyMiddleware.RequireAPIKey())
+	logIngestion := v1.Group("/logs", maintenanceMode.RejectIngestion(), apiKeyMiddleware.RequireAPIKey())
 	logIngestion.Post("", logHandler.CreateLog)
 	logIngestion.Post("/batch", logHandler.CreateBatchLogs)
 
@@ -207,6 +234,7 @@ func setupTestApp(t *testing.T) *TestApp {
 	projects.Put("/:id", rbacMiddleware.RequireOwner(), projectHandler.UpdateProject)
 	projects.Delete("/:id", rbacMiddleware.RequireOwner(), projectHandler.DeleteProject)
 	projects.Post("/:id/rotate-key", rbacMiddleware.RequireOwner(), projectHandler.RotateAPIKey)
+	projects.Get("/:id/metadata-keys", rbacMiddleware.RequireProjectAccess(), logHandler.ListProjectMetadataKeys)
 
 	// Members
 	projects.Get("/:id/members", rbacMiddleware.RequireProjectAccess(), memberHandler.ListMembers)
@@ -223,6 +251,10 @@ func setupTestApp(t *testing.T) *TestApp {
 	stats := admin.Group("/stats")
 	stats.Get("/overview", statsHandler.GetOverview)
 
+	// System (admin only)
+	system := admin.Group("/system", authMiddleware.RequireAdmin())
+	system.Post("/maintenance", systemHandler.SetMaintenanceModeState)
+
 	return &TestApp{
 		App:             app,
 		DB:              db,
@@ -559,6 +591,115 @@ func TestCreateBatchLogs_Success(t *testing.T) {
 	}
 }
 
+func TestMaintenanceMode_RejectsIngestion(t *testing.T) {
+	ta := setupTestApp(t)
+	defer ta.Close()
+
+	project := &models.Project{Name: "Maintenance Test", IsActive: true}
+	apiKey, _ := ta.ProjectRepo.Create(project)
+
+	setMaintenance := func(token string, enabled bool) int {
+		body, _ := json.Marshal(map[string]bool{"enabled": enabled})
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/system/maintenance", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := ta.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp.StatusCode
+	}
+	ingest := func() *http.Response {
+		body, _ := json.Marshal(map[string]string{"level": "INFO", "message": "during maintenance"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/logs", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", apiKey)
+		resp, err := ta.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	if status := setMaintenance(ta.UserToken, true); status != http.StatusForbidden {
+		t.Fatalf("Expected a regular user to get 403, got %d", status)
+	}
+	if status := setMaintenance(ta.AdminToken, true); status != http.StatusOK {
+		t.Fatalf("Expected 200 turning maintenance on, got %d", status)
+	}
+
+	resp := ingest()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 during maintenance, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+
+	// The admin API keeps working
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/projects/"+project.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+ta.AdminToken)
+	adminResp, err := ta.App.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	if adminResp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the admin API to answer 200 during maintenance, got %d", adminResp.StatusCode)
+	}
+
+	if status := setMaintenance(ta.AdminToken, false); status != http.StatusOK {
+		t.Fatalf("Expected 200 turning maintenance off, got %d", status)
+	}
+	if resp := ingest(); resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected ingestion to resume with 201, got %d", resp.StatusCode)
+	}
+}
+
+func TestProjectMetadataKeys_AccessChecked(t *testing.T) {
+	ta := setupTestApp(t)
+	defer ta.Close()
+
+	project := &models.Project{Name: "Metadata Keys Test", IsActive: true}
+	ta.ProjectRepo.Create(project)
+	for _, metadata := range []map[string]interface{}{
+		{"user_id": "u1", "region": "eu"},
+		{"user_id": "u2"},
+	} {
+		ta.LogRepo.Create(&models.Log{ProjectID: project.ID, Level: models.LogLevelInfo, Message: "msg", Metadata: metadata})
+	}
+
+	get := func(token, query string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/projects/"+project.ID+"/metadata-keys"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := ta.App.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		return resp
+	}
+
+	if resp := get(ta.UserToken, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for a user without access, got %d", resp.StatusCode)
+	}
+	if resp := get(ta.AdminToken, "?sample=0"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an empty sample, got %d", resp.StatusCode)
+	}
+
+	ta.UserProjectRepo.Create(&models.UserProject{UserID: ta.RegularUser.ID, ProjectID: project.ID, Role: models.ProjectRoleViewer})
+	resp := get(ta.UserToken, "?sample=50")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 for a viewer, got %d", resp.StatusCode)
+	}
+	var result handlers.MetadataKeysResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.SampleSize != 50 || result.Sampled != 2 || len(result.Keys) != 2 {
+		t.Fatalf("Expected 2 keys over 2 sampled logs, got %+v", result)
+	}
+	if result.Keys[0].Key != "user_id" || result.Keys[0].Frequency != 1 || result.Keys[1].Key != "region" || result.Keys[1].Frequency != 0.5 {
+		t.Errorf("Expected user_id in every log and region in half, got %+v", result.Keys)
+	}
+}
+
 // ==================== Stats Tests ====================
 
 func TestGetStats_Success(t *testing.T) {